@@ -29,6 +29,7 @@ var (
 	errBaseFeeNil             = errors.New("base fee is nil")
 	errExtDataGasUsedNil      = errors.New("extDataGasUsed is nil")
 	errExtDataGasUsedTooLarge = errors.New("extDataGasUsed is not uint64")
+	errInsufficientBlockFee   = errors.New("insufficient block fee")
 )
 
 type Mode uint
@@ -85,6 +86,20 @@ func NewFullFaker() *DummyEngine {
 	}
 }
 
+// GasLimit returns the static gas limit that headers built under [rules] must
+// have and the fork that set it, or 0 prior to ApricotPhase1, when the gas
+// limit instead moves within bounds of the gas limit of the parent.
+func GasLimit(rules params.Rules) (uint64, string) {
+	switch {
+	case rules.IsCortina:
+		return params.CortinaGasLimit, "Cortina"
+	case rules.IsApricotPhase1:
+		return params.ApricotPhase1GasLimit, "ApricotPhase1"
+	default:
+		return 0, ""
+	}
+}
+
 func (self *DummyEngine) verifyHeaderGasFields(config *params.ChainConfig, header *types.Header, parent *types.Header) error {
 	// Verify that the gas limit is <= 2^63-1
 	if header.GasLimit > params.MaxGasLimit {
@@ -94,13 +109,9 @@ func (self *DummyEngine) verifyHeaderGasFields(config *params.ChainConfig, heade
 	if header.GasUsed > header.GasLimit {
 		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
 	}
-	if config.IsCortina(header.Time) {
-		if header.GasLimit != params.CortinaGasLimit {
-			return fmt.Errorf("expected gas limit to be %d in Cortina, but found %d", params.CortinaGasLimit, header.GasLimit)
-		}
-	} else if config.IsApricotPhase1(header.Time) {
-		if header.GasLimit != params.ApricotPhase1GasLimit {
-			return fmt.Errorf("expected gas limit to be %d in ApricotPhase1, but found %d", params.ApricotPhase1GasLimit, header.GasLimit)
+	if gasLimit, fork := GasLimit(config.ForkFlags(header.Number, header.Time)); gasLimit != 0 {
+		if header.GasLimit != gasLimit {
+			return fmt.Errorf("expected gas limit to be %d in %s, but found %d", gasLimit, fork, header.GasLimit)
 		}
 	} else {
 		// Verify that the gas limit remains within allowed bounds
@@ -127,8 +138,8 @@ func (self *DummyEngine) verifyHeaderGasFields(config *params.ChainConfig, heade
 		if err != nil {
 			return fmt.Errorf("failed to calculate base fee: %w", err)
 		}
-		if !bytes.Equal(expectedRollupWindowBytes, header.Extra) {
-			return fmt.Errorf("expected rollup window bytes: %x, found %x", expectedRollupWindowBytes, header.Extra)
+		if rollupWindowBytes := RollupWindowBytes(header); !bytes.Equal(expectedRollupWindowBytes, rollupWindowBytes) {
+			return fmt.Errorf("expected rollup window bytes: %x, found %x", expectedRollupWindowBytes, rollupWindowBytes)
 		}
 		if header.BaseFee == nil {
 			return errors.New("expected baseFee to be non-nil")
@@ -153,18 +164,7 @@ func (self *DummyEngine) verifyHeaderGasFields(config *params.ChainConfig, heade
 	}
 
 	// Enforce BlockGasCost constraints
-	blockGasCostStep := ApricotPhase4BlockGasCostStep
-	if config.IsApricotPhase5(header.Time) {
-		blockGasCostStep = ApricotPhase5BlockGasCostStep
-	}
-	expectedBlockGasCost := calcBlockGasCost(
-		ApricotPhase4TargetBlockRate,
-		ApricotPhase4MinBlockGasCost,
-		ApricotPhase4MaxBlockGasCost,
-		blockGasCostStep,
-		parent.BlockGasCost,
-		parent.Time, header.Time,
-	)
+	expectedBlockGasCost := CalcBlockGasCost(config, parent, header.Time)
 	if header.BlockGasCost == nil {
 		return errBlockGasCostNil
 	}
@@ -200,8 +200,10 @@ func (self *DummyEngine) verifyHeader(chain consensus.ChainHeaderReader, header
 			return fmt.Errorf("extra-data too long: %d > %d", len(header.Extra), params.MaximumExtraDataSize)
 		}
 	} else {
-		if uint64(len(header.Extra)) != params.ApricotPhase3ExtraDataSize {
-			return fmt.Errorf("expected extra-data field to be: %d, but found %d", params.ApricotPhase3ExtraDataSize, len(header.Extra))
+		// From OdyPhaseOrionSnapshot, the orion snapshot of the block follows
+		// the rollup window. It is verified by the VM when the block is processed.
+		if size, _ := ExpectedExtraDataSize(config.ForkFlags(header.Number, header.Time)); uint64(len(header.Extra)) != size {
+			return fmt.Errorf("expected extra-data field to be: %d, but found %d", size, len(header.Extra))
 		}
 	}
 	// Ensure gas-related header fields are correct
@@ -325,9 +327,11 @@ func (self *DummyEngine) verifyBlockFee(
 	// NOTE: To determine the [requiredBlockFee], multiply [requiredBlockGasCost]
 	// by [baseFee].
 	if blockGas.Cmp(requiredBlockGasCost) < 0 {
+		requiredBlockFee := new(big.Int).Mul(requiredBlockGasCost, baseFee)
 		return fmt.Errorf(
-			"insufficient gas (%d) to cover the block cost (%d) at base fee (%d) (total block fee: %d)",
+			"insufficient gas (%d) to cover the block cost (%d) at base fee (%d) (total block fee: %d, short by %d wei): %w",
 			blockGas, requiredBlockGasCost, baseFee, totalBlockFee,
+			new(big.Int).Sub(requiredBlockFee, totalBlockFee), errInsufficientBlockFee,
 		)
 	}
 	return nil
@@ -356,20 +360,9 @@ func (self *DummyEngine) Finalize(chain consensus.ChainHeaderReader, block *type
 		if blockExtDataGasUsed := block.ExtDataGasUsed(); blockExtDataGasUsed == nil || !blockExtDataGasUsed.IsUint64() || blockExtDataGasUsed.Cmp(extDataGasUsed) != 0 {
 			return fmt.Errorf("invalid extDataGasUsed: have %d, want %d", blockExtDataGasUsed, extDataGasUsed)
 		}
-		blockGasCostStep := ApricotPhase4BlockGasCostStep
-		if chain.Config().IsApricotPhase5(block.Time()) {
-			blockGasCostStep = ApricotPhase5BlockGasCostStep
-		}
 		// Calculate the expected blockGasCost for this block.
 		// Note: this is a deterministic transtion that defines an exact block fee for this block.
-		blockGasCost := calcBlockGasCost(
-			ApricotPhase4TargetBlockRate,
-			ApricotPhase4MinBlockGasCost,
-			ApricotPhase4MaxBlockGasCost,
-			blockGasCostStep,
-			parent.BlockGasCost,
-			parent.Time, block.Time(),
-		)
+		blockGasCost := CalcBlockGasCost(chain.Config(), parent, block.Time())
 		// Verify the BlockGasCost set in the header matches the calculated value.
 		if blockBlockGasCost := block.BlockGasCost(); blockBlockGasCost == nil || !blockBlockGasCost.IsUint64() || blockBlockGasCost.Cmp(blockGasCost) != 0 {
 			return fmt.Errorf("invalid blockGasCost: have %d, want %d", blockBlockGasCost, blockGasCost)
@@ -407,19 +400,8 @@ func (self *DummyEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader,
 		if header.ExtDataGasUsed == nil {
 			header.ExtDataGasUsed = new(big.Int).Set(common.Big0)
 		}
-		blockGasCostStep := ApricotPhase4BlockGasCostStep
-		if chain.Config().IsApricotPhase5(header.Time) {
-			blockGasCostStep = ApricotPhase5BlockGasCostStep
-		}
 		// Calculate the required block gas cost for this block.
-		header.BlockGasCost = calcBlockGasCost(
-			ApricotPhase4TargetBlockRate,
-			ApricotPhase4MinBlockGasCost,
-			ApricotPhase4MaxBlockGasCost,
-			blockGasCostStep,
-			parent.BlockGasCost,
-			parent.Time, header.Time,
-		)
+		header.BlockGasCost = CalcBlockGasCost(chain.Config(), parent, header.Time)
 		// Verify that this block covers the block fee.
 		if err := self.verifyBlockFee(
 			header.BaseFee,
@@ -4,11 +4,15 @@
 package dummy
 
 import (
+	"errors"
 	"math"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/utils"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -199,3 +203,247 @@ func TestVerifyBlockFee(t *testing.T) {
 		})
 	}
 }
+
+// apricotPhase4TransitionConfig activates ApricotPhase4 at timestamp 10 and
+// ApricotPhase5 at timestamp 20.
+func apricotPhase4TransitionConfig() *params.ChainConfig {
+	config := *params.TestApricotPhase3Config
+	config.ApricotPhase4BlockTimestamp = utils.NewUint64(10)
+	config.ApricotPhase5BlockTimestamp = utils.NewUint64(20)
+	return &config
+}
+
+func TestCalcBlockGasCostForks(t *testing.T) {
+	config := apricotPhase4TransitionConfig()
+	tests := map[string]struct {
+		parentBlockGasCost   *big.Int
+		parentTime, time     uint64
+		expectedBlockGasCost *big.Int
+	}{
+		"before ApricotPhase4": {
+			parentTime: 5,
+			time:       7,
+		},
+		"ApricotPhase3 to ApricotPhase4": {
+			parentTime:           9,
+			time:                 10,
+			expectedBlockGasCost: big.NewInt(0),
+		},
+		"ApricotPhase4 fast block": {
+			parentBlockGasCost:   big.NewInt(100_000),
+			parentTime:           12,
+			time:                 12,
+			expectedBlockGasCost: big.NewInt(200_000),
+		},
+		"ApricotPhase4 slow block": {
+			parentBlockGasCost:   big.NewInt(100_000),
+			parentTime:           12,
+			time:                 16,
+			expectedBlockGasCost: big.NewInt(0),
+		},
+		"ApricotPhase4 max block gas cost": {
+			parentBlockGasCost:   big.NewInt(1_000_000),
+			parentTime:           12,
+			time:                 12,
+			expectedBlockGasCost: big.NewInt(1_000_000),
+		},
+		"ApricotPhase4 to ApricotPhase5": {
+			parentBlockGasCost:   big.NewInt(100_000),
+			parentTime:           19,
+			time:                 20,
+			expectedBlockGasCost: big.NewInt(300_000),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent := &types.Header{BlockGasCost: test.parentBlockGasCost, Time: test.parentTime}
+			blockGasCost := CalcBlockGasCost(config, parent, test.time)
+			if test.expectedBlockGasCost == nil {
+				if blockGasCost != nil {
+					t.Fatalf("expected nil block gas cost, found %d", blockGasCost)
+				}
+				return
+			}
+			if blockGasCost == nil || blockGasCost.Cmp(test.expectedBlockGasCost) != 0 {
+				t.Fatalf("expected block gas cost %d, found %d", test.expectedBlockGasCost, blockGasCost)
+			}
+		})
+	}
+}
+
+func TestVerifyHeaderBlockGasCost(t *testing.T) {
+	config := apricotPhase4TransitionConfig()
+	ap3Parent := &types.Header{
+		Number:   big.NewInt(1),
+		Time:     9,
+		GasLimit: params.ApricotPhase1GasLimit,
+		BaseFee:  big.NewInt(params.ApricotPhase3MinBaseFee),
+		Extra:    make([]byte, params.ApricotPhase3ExtraDataSize),
+	}
+	ap4Parent := &types.Header{
+		Number:         big.NewInt(1),
+		Time:           12,
+		GasLimit:       params.ApricotPhase1GasLimit,
+		BaseFee:        big.NewInt(params.ApricotPhase4MinBaseFee),
+		Extra:          make([]byte, params.ApricotPhase3ExtraDataSize),
+		BlockGasCost:   big.NewInt(100_000),
+		ExtDataGasUsed: big.NewInt(0),
+	}
+
+	tests := map[string]struct {
+		parent       *types.Header
+		time         uint64
+		blockGasCost *big.Int
+		expectedErr  bool
+	}{
+		"ApricotPhase3 block without block gas cost": {
+			parent: ap3Parent,
+			time:   9,
+		},
+		"ApricotPhase3 block with block gas cost": {
+			parent:       ap3Parent,
+			time:         9,
+			blockGasCost: big.NewInt(0),
+			expectedErr:  true,
+		},
+		"first ApricotPhase4 block": {
+			parent:       ap3Parent,
+			time:         10,
+			blockGasCost: big.NewInt(0),
+		},
+		"first ApricotPhase4 block without block gas cost": {
+			parent:      ap3Parent,
+			time:        10,
+			expectedErr: true,
+		},
+		"first ApricotPhase4 block overpaid block gas cost": {
+			parent:       ap3Parent,
+			time:         10,
+			blockGasCost: big.NewInt(1),
+			expectedErr:  true,
+		},
+		"ApricotPhase4 block": {
+			parent:       ap4Parent,
+			time:         13,
+			blockGasCost: big.NewInt(150_000),
+		},
+		"ApricotPhase4 underpaid block gas cost": {
+			parent:       ap4Parent,
+			time:         13,
+			blockGasCost: big.NewInt(149_999),
+			expectedErr:  true,
+		},
+		"ApricotPhase4 overpaid block gas cost": {
+			parent:       ap4Parent,
+			time:         13,
+			blockGasCost: big.NewInt(150_001),
+			expectedErr:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			extra, baseFee, err := CalcBaseFee(config, test.parent, test.time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			header := &types.Header{
+				Number:       big.NewInt(2),
+				Time:         test.time,
+				GasLimit:     params.ApricotPhase1GasLimit,
+				BaseFee:      baseFee,
+				Extra:        extra,
+				BlockGasCost: test.blockGasCost,
+			}
+			if config.IsApricotPhase4(test.time) {
+				header.ExtDataGasUsed = big.NewInt(0)
+			}
+			err = NewFaker().verifyHeaderGasFields(config, header, test.parent)
+			if test.expectedErr && err == nil {
+				t.Fatal("should have failed verification")
+			}
+			if !test.expectedErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestVerifyBlockFeeShortfall(t *testing.T) {
+	engine := NewFaker()
+	err := engine.verifyBlockFee(big.NewInt(100), big.NewInt(100_000), nil, nil, big.NewInt(9_999_900))
+	if !errors.Is(err, errInsufficientBlockFee) {
+		t.Fatalf("expected %s, found %v", errInsufficientBlockFee, err)
+	}
+	if !strings.Contains(err.Error(), "short by 100 wei") {
+		t.Fatalf("expected error to name the shortfall, found %s", err)
+	}
+}
+
+func TestGasLimit(t *testing.T) {
+	tests := map[string]struct {
+		config           *params.ChainConfig
+		expectedGasLimit uint64
+		expectedFork     string
+	}{
+		"launch": {
+			config: params.TestLaunchConfig,
+		},
+		"ApricotPhase1": {
+			config:           params.TestApricotPhase1Config,
+			expectedGasLimit: params.ApricotPhase1GasLimit,
+			expectedFork:     "ApricotPhase1",
+		},
+		"Banff": {
+			config:           params.TestBanffChainConfig,
+			expectedGasLimit: params.ApricotPhase1GasLimit,
+			expectedFork:     "ApricotPhase1",
+		},
+		"Cortina": {
+			config:           params.TestCortinaChainConfig,
+			expectedGasLimit: params.CortinaGasLimit,
+			expectedFork:     "Cortina",
+		},
+		"latest": {
+			config:           params.TestChainConfig,
+			expectedGasLimit: params.CortinaGasLimit,
+			expectedFork:     "Cortina",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rules := test.config.ForkFlags(common.Big0, 0)
+			gasLimit, fork := GasLimit(rules)
+			if gasLimit != test.expectedGasLimit {
+				t.Fatalf("expected gas limit %d, found %d", test.expectedGasLimit, gasLimit)
+			}
+			if fork != test.expectedFork {
+				t.Fatalf("expected fork %q, found %q", test.expectedFork, fork)
+			}
+		})
+	}
+}
+
+func TestVerifyHeaderGasLimit(t *testing.T) {
+	engine := NewFaker()
+	parent := &types.Header{
+		Number:   common.Big0,
+		GasLimit: params.ApricotPhase1GasLimit,
+	}
+	for _, gasLimit := range []uint64{params.ApricotPhase1GasLimit, params.CortinaGasLimit} {
+		header := &types.Header{
+			Number:   common.Big1,
+			Time:     1,
+			GasLimit: gasLimit,
+		}
+		err := engine.verifyHeaderGasFields(params.TestApricotPhase2Config, header, parent)
+		if gasLimit == params.ApricotPhase1GasLimit {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), "expected gas limit to be 8000000 in ApricotPhase1") {
+			t.Fatalf("expected gas limit error, found %v", err)
+		}
+	}
+}
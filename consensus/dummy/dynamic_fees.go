@@ -4,7 +4,10 @@
 package dummy
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -28,9 +31,11 @@ var (
 	ApricotPhase4MinBlockGasCost         = new(big.Int).Set(common.Big0)
 	ApricotPhase4MaxBlockGasCost         = big.NewInt(1_000_000)
 	ApricotPhase4BlockGasCostStep        = big.NewInt(50_000)
-	ApricotPhase4TargetBlockRate  uint64 = 2 // in seconds
+	ApricotPhase4TargetBlockRate         = params.ApricotPhase4TargetBlockRate // in seconds, unless overridden by the chain config
 	ApricotPhase5BlockGasCostStep        = big.NewInt(200_000)
 	rollupWindow                  uint64 = 10
+
+	errInvalidRollupWindowChecksum = errors.New("invalid rollup window checksum")
 )
 
 // CalcBaseFee takes the previous header and the timestamp of its child block
@@ -38,6 +43,12 @@ var (
 // pricing information for the child block.
 // CalcBaseFee should only be called if [timestamp] >= [config.ApricotPhase3Timestamp]
 func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uint64) ([]byte, *big.Int, error) {
+	return calcBaseFee(config, parent, timestamp, 0)
+}
+
+// calcBaseFee is CalcBaseFee with [pendingGas] added to the most recent slot
+// of the rollup window, as if it had been consumed at [timestamp].
+func calcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uint64, pendingGas uint64) ([]byte, *big.Int, error) {
 	// If the current block is the first EIP-1559 block, or it is the genesis block
 	// return the initial slice and initial base fee.
 	var (
@@ -45,13 +56,20 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 		isApricotPhase4 = config.IsApricotPhase4(parent.Time)
 		isApricotPhase5 = config.IsApricotPhase5(parent.Time)
 	)
+	// From OdyPhaseExtraChecksum, the last slot of the rollup window holds a
+	// checksum of the other slots rather than gas.
+	isExtraChecksum := config.IsOdyPhaseExtraChecksum(timestamp)
+	gasSlots := rollupGasSlots(isExtraChecksum)
 	if !isApricotPhase3 || parent.Number.Cmp(common.Big0) == 0 {
 		initialSlice := make([]byte, params.ApricotPhase3ExtraDataSize)
+		if isExtraChecksum {
+			putRollupWindowChecksum(initialSlice)
+		}
 		initialBaseFee := big.NewInt(params.ApricotPhase3InitialBaseFee)
 		return initialSlice, initialBaseFee, nil
 	}
-	if uint64(len(parent.Extra)) != params.ApricotPhase3ExtraDataSize {
-		return nil, nil, fmt.Errorf("expected length of parent extra data to be %d, but found %d", params.ApricotPhase3ExtraDataSize, len(parent.Extra))
+	if size, _ := ExpectedExtraDataSize(config.ForkFlags(parent.Number, parent.Time)); uint64(len(parent.Extra)) != size {
+		return nil, nil, fmt.Errorf("expected length of parent extra data to be %d, but found %d", size, len(parent.Extra))
 	}
 
 	if timestamp < parent.Time {
@@ -59,9 +77,14 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 	}
 	roll := timestamp - parent.Time
 
+	parentWindow, err := parentRollupWindow(config, parent, isExtraChecksum)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// roll the window over by the difference between the timestamps to generate
 	// the new rollup window.
-	newRollupWindow, err := rollLongWindow(parent.Extra, int(roll))
+	newRollupWindow, err := rollLongWindow(parentWindow, int(roll))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -77,12 +100,17 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 		baseFeeChangeDenominator = ApricotPhase5BaseFeeChangeDenominator
 		parentGasTarget = params.ApricotPhase5TargetGas
 	}
+	// The targets are set for a window of [rollupWindow] gas slots, so they are
+	// scaled down to the gas slots left next to the checksum.
+	if isExtraChecksum {
+		parentGasTarget = parentGasTarget * gasSlots / rollupWindow
+	}
 	parentGasTargetBig := new(big.Int).SetUint64(parentGasTarget)
 
 	// Add in the gas used by the parent block in the correct place
 	// If the parent consumed gas within the rollup window, add the consumed
 	// gas in.
-	if roll < rollupWindow {
+	if roll < gasSlots {
 		var blockGasCost, parentExtraStateGasUsed uint64
 		switch {
 		case isApricotPhase5:
@@ -97,7 +125,7 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 			// The [blockGasCost] is paid by the effective tips in the block using
 			// the block's value of [baseFee].
 			blockGasCost = calcBlockGasCost(
-				ApricotPhase4TargetBlockRate,
+				config.GetTargetBlockRate(timestamp),
 				ApricotPhase4MinBlockGasCost,
 				ApricotPhase4MaxBlockGasCost,
 				ApricotPhase4BlockGasCostStep,
@@ -128,13 +156,20 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 			}
 		}
 
-		slot := rollupWindow - 1 - roll
+		slot := gasSlots - 1 - roll
 		start := slot * wrappers.LongLen
 		updateLongWindow(newRollupWindow, start, addedGas)
 	}
+	if pendingGas > 0 {
+		updateLongWindow(newRollupWindow, (gasSlots-1)*wrappers.LongLen, pendingGas)
+	}
 
 	// Calculate the amount of gas consumed within the rollup window.
-	totalGas := sumLongWindow(newRollupWindow, int(rollupWindow))
+	totalGas := sumLongWindow(newRollupWindow, int(gasSlots))
+	if isExtraChecksum {
+		newRollupWindow = append(newRollupWindow, make([]byte, wrappers.LongLen)...)
+		putRollupWindowChecksum(newRollupWindow)
+	}
 
 	if totalGas == parentGasTarget {
 		return newRollupWindow, baseFee, nil
@@ -142,6 +177,13 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 
 	if totalGas > parentGasTarget {
 		// If the parent block used more gas than its target, the baseFee should increase.
+		//
+		// Unlike the decrease below, the increase is never scaled by the
+		// number of elapsed windows: if [roll] >= [gasSlots], the window
+		// has been rolled over completely and the parent's gas is not added,
+		// so [totalGas] is 0 and this branch cannot be reached. Gas can only
+		// push the base fee up while it is inside the window, whereas an
+		// empty interval keeps pulling it down for as long as it lasts.
 		gasUsedDelta := new(big.Int).SetUint64(totalGas - parentGasTarget)
 		x := new(big.Int).Mul(parent.BaseFee, gasUsedDelta)
 		y := x.Div(x, parentGasTargetBig)
@@ -161,28 +203,146 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 			common.Big1,
 		)
 
-		// If [roll] is greater than [rollupWindow], apply the state transition to the base fee to account
+		// If [roll] is greater than [gasSlots], apply the state transition to the base fee to account
 		// for the interval during which no blocks were produced.
-		// We use roll/rollupWindow, so that the transition is applied for every [rollupWindow] seconds
-		// that has elapsed between the parent and this block.
-		if roll > rollupWindow {
-			// Note: roll/rollupWindow must be greater than 1 since we've checked that roll > rollupWindow
-			baseFeeDelta = baseFeeDelta.Mul(baseFeeDelta, new(big.Int).SetUint64(roll/rollupWindow))
+		// We use roll/gasSlots, so that the transition is applied for every [gasSlots] seconds
+		// that has elapsed between the parent and this block. The transition is applied linearly with
+		// the delta of a single empty window rather than compounded, and a trailing partial window is
+		// not applied, so that the result is exactly reproducible for any [roll].
+		if roll > gasSlots {
+			// Note: roll/gasSlots must be greater than 1 since we've checked that roll > gasSlots
+			baseFeeDelta = baseFeeDelta.Mul(baseFeeDelta, new(big.Int).SetUint64(roll/gasSlots))
 		}
 		baseFee.Sub(baseFee, baseFeeDelta)
 	}
 
 	// Ensure that the base fee does not increase/decrease outside of the bounds
+	lowerBound, upperBound := BaseFeeBounds(config, parent.Time)
+	baseFee = selectBigWithinBounds(lowerBound, baseFee, upperBound)
+
+	return newRollupWindow, baseFee, nil
+}
+
+// BaseFeeBounds returns the bounds that CalcBaseFee clamps the base fee to when
+// the parent block has [timestamp]. A nil bound means the base fee is unbounded
+// in that direction.
+// BaseFeeBounds should only be called if [timestamp] >= [config.ApricotPhase3Timestamp]
+func BaseFeeBounds(config *params.ChainConfig, timestamp uint64) (*big.Int, *big.Int) {
 	switch {
-	case isApricotPhase5:
-		baseFee = selectBigWithinBounds(ApricotPhase4MinBaseFee, baseFee, nil)
-	case isApricotPhase4:
-		baseFee = selectBigWithinBounds(ApricotPhase4MinBaseFee, baseFee, ApricotPhase4MaxBaseFee)
+	case config.IsApricotPhase5(timestamp):
+		return ApricotPhase4MinBaseFee, nil
+	case config.IsApricotPhase4(timestamp):
+		return ApricotPhase4MinBaseFee, ApricotPhase4MaxBaseFee
 	default:
-		baseFee = selectBigWithinBounds(ApricotPhase3MinBaseFee, baseFee, ApricotPhase3MaxBaseFee)
+		return ApricotPhase3MinBaseFee, ApricotPhase3MaxBaseFee
 	}
+}
 
-	return newRollupWindow, baseFee, nil
+// ExpectedExtraDataSize returns the size of the Extra field of a header built
+// under [rules]. If [exact] is true, the field must be exactly [size] bytes
+// long. Otherwise, it must be at most [size] bytes long.
+func ExpectedExtraDataSize(rules params.Rules) (size uint64, exact bool) {
+	switch {
+	case rules.IsOdyPhaseOrionSnapshot:
+		// The orion snapshot of the block follows the rollup window.
+		return params.ApricotPhase3ExtraDataSize + params.OrionSnapshotExtraDataSize, true
+	case rules.IsApricotPhase3:
+		// The Extra field holds the rollup window of the dynamic fees.
+		return params.ApricotPhase3ExtraDataSize, true
+	case rules.IsApricotPhase1:
+		return 0, true
+	default:
+		return params.MaximumExtraDataSize, false
+	}
+}
+
+// DecodeRollupWindow returns the gas consumed in each second of the rollup
+// window encoded in the Extra field of [header], oldest first. The rollup window
+// is only encoded from ApricotPhase3. From OdyPhaseExtraChecksum, the checksum
+// of the window is verified and is not returned.
+func DecodeRollupWindow(config *params.ChainConfig, header *types.Header) ([]uint64, error) {
+	if !config.IsApricotPhase3(header.Time) {
+		return nil, fmt.Errorf("block %d has no rollup window prior to ApricotPhase3", header.Number)
+	}
+	if size, _ := ExpectedExtraDataSize(config.ForkFlags(header.Number, header.Time)); uint64(len(header.Extra)) != size {
+		return nil, fmt.Errorf("expected length of extra data to be %d, but found %d", size, len(header.Extra))
+	}
+	rollupWindowBytes := RollupWindowBytes(header)
+	isExtraChecksum := config.IsOdyPhaseExtraChecksum(header.Time)
+	if isExtraChecksum {
+		if err := verifyRollupWindowChecksum(rollupWindowBytes); err != nil {
+			return nil, fmt.Errorf("block %d: %w", header.Number, err)
+		}
+	}
+	window := make([]uint64, rollupGasSlots(isExtraChecksum))
+	for i := range window {
+		window[i] = binary.BigEndian.Uint64(rollupWindowBytes[i*wrappers.LongLen:])
+	}
+	return window, nil
+}
+
+// RollupWindowBytes returns the rollup window encoded in the Extra field of
+// [header], without the orion snapshot that follows it from
+// OdyPhaseOrionSnapshot. Assumes that [header] is from ApricotPhase3.
+func RollupWindowBytes(header *types.Header) []byte {
+	if uint64(len(header.Extra)) > params.ApricotPhase3ExtraDataSize {
+		return header.Extra[:params.ApricotPhase3ExtraDataSize]
+	}
+	return header.Extra
+}
+
+// rollupGasSlots returns the number of slots of the rollup window that hold
+// gas, which excludes the checksum slot if [isExtraChecksum].
+func rollupGasSlots(isExtraChecksum bool) uint64 {
+	if isExtraChecksum {
+		return rollupWindow - 1
+	}
+	return rollupWindow
+}
+
+// rollupWindowChecksum returns the last [wrappers.LongLen] bytes of the
+// SHA-256 hash of the gas slots of [window].
+func rollupWindowChecksum(window []byte) []byte {
+	gasLen := (rollupWindow - 1) * wrappers.LongLen
+	hash := sha256.Sum256(window[:gasLen])
+	return hash[len(hash)-wrappers.LongLen:]
+}
+
+// putRollupWindowChecksum writes the checksum of [window] into its last slot.
+// Assumes that [window] is [params.ApricotPhase3ExtraDataSize] bytes long.
+func putRollupWindowChecksum(window []byte) {
+	copy(window[(rollupWindow-1)*wrappers.LongLen:], rollupWindowChecksum(window))
+}
+
+// verifyRollupWindowChecksum returns an error if the last slot of [window]
+// does not hold the checksum of its other slots.
+// Assumes that [window] is [params.ApricotPhase3ExtraDataSize] bytes long.
+func verifyRollupWindowChecksum(window []byte) error {
+	expected := rollupWindowChecksum(window)
+	if found := window[(rollupWindow-1)*wrappers.LongLen:]; !bytes.Equal(expected, found) {
+		return fmt.Errorf("%w: expected %x, found %x", errInvalidRollupWindowChecksum, expected, found)
+	}
+	return nil
+}
+
+// parentRollupWindow returns the gas slots of the rollup window of [parent]
+// in the format of a child built with [isExtraChecksum]. If [parent] was built
+// from OdyPhaseExtraChecksum, its checksum is verified and removed. If [parent]
+// predates OdyPhaseExtraChecksum but its child does not, the oldest slot of
+// its window is dropped to make room for the checksum of the child.
+func parentRollupWindow(config *params.ChainConfig, parent *types.Header, isExtraChecksum bool) ([]byte, error) {
+	switch {
+	case config.IsOdyPhaseExtraChecksum(parent.Time):
+		parentWindow := RollupWindowBytes(parent)
+		if err := verifyRollupWindowChecksum(parentWindow); err != nil {
+			return nil, fmt.Errorf("parent %d: %w", parent.Number, err)
+		}
+		return parentWindow[:(rollupWindow-1)*wrappers.LongLen], nil
+	case isExtraChecksum:
+		return RollupWindowBytes(parent)[wrappers.LongLen:], nil
+	default:
+		return RollupWindowBytes(parent), nil
+	}
 }
 
 // EstiamteNextBaseFee attempts to estimate the next base fee based on a block with [parent] being built at
@@ -197,6 +357,64 @@ func EstimateNextBaseFee(config *params.ChainConfig, parent *types.Header, times
 	return CalcBaseFee(config, parent, timestamp)
 }
 
+// EstimateNextBaseFeeWithPendingGas is EstimateNextBaseFee with [pendingGas],
+// the gas expected to be consumed by the block built at [timestamp], added
+// into the rollup window before the base fee is computed.
+// Warning: This function should only be used in estimation and should not be used when calculating the canonical
+// base fee for a subsequent block.
+func EstimateNextBaseFeeWithPendingGas(config *params.ChainConfig, parent *types.Header, timestamp uint64, pendingGas uint64) ([]byte, *big.Int, error) {
+	if timestamp < parent.Time {
+		timestamp = parent.Time
+	}
+	return calcBaseFee(config, parent, timestamp, pendingGas)
+}
+
+// SimulatedBlock is a block of a base fee simulation, which consumes [GasUsed]
+// and [ExtDataGasUsed] and is produced at [Timestamp].
+type SimulatedBlock = struct {
+	GasUsed        uint64
+	ExtDataGasUsed uint64
+	Timestamp      uint64
+}
+
+// SimulateBaseFees returns the base fee of each of [blocks] when they are built
+// in sequence on top of [genesis]. Each block is assigned the rollup window,
+// base fee and block gas cost it would be given by the consensus engine, so
+// that its gas usage is accounted for by the base fee of the blocks after it.
+// The base fee of a block produced prior to ApricotPhase3 is nil.
+func SimulateBaseFees(config *params.ChainConfig, genesis *types.Header, blocks []SimulatedBlock) ([]*big.Int, error) {
+	var (
+		parent   = genesis
+		baseFees = make([]*big.Int, len(blocks))
+	)
+	for i, block := range blocks {
+		if block.Timestamp < parent.Time {
+			return nil, fmt.Errorf("block %d has timestamp (%d) prior to its parent timestamp (%d)", i, block.Timestamp, parent.Time)
+		}
+		header := &types.Header{
+			Number:   new(big.Int).Add(parent.Number, common.Big1),
+			GasLimit: parent.GasLimit,
+			GasUsed:  block.GasUsed,
+			Time:     block.Timestamp,
+		}
+		if config.IsApricotPhase3(block.Timestamp) {
+			extra, baseFee, err := CalcBaseFee(config, parent, block.Timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate base fee of block %d: %w", i, err)
+			}
+			header.Extra = extra
+			header.BaseFee = baseFee
+			baseFees[i] = baseFee
+		}
+		if config.IsApricotPhase4(block.Timestamp) {
+			header.ExtDataGasUsed = new(big.Int).SetUint64(block.ExtDataGasUsed)
+			header.BlockGasCost = CalcBlockGasCost(config, parent, header.Time)
+		}
+		parent = header
+	}
+	return baseFees, nil
+}
+
 // selectBigWithinBounds returns [value] if it is within the bounds:
 // lowerBound <= value <= upperBound or the bound at either end if [value]
 // is outside of the defined boundaries.
@@ -281,6 +499,28 @@ func updateLongWindow(window []byte, start uint64, gasConsumed uint64) {
 	binary.BigEndian.PutUint64(window[start:], totalGasConsumed)
 }
 
+// CalcBlockGasCost returns the block gas cost that the effective tips of a
+// block built on [parent] at [timestamp] must pay for, or nil before
+// ApricotPhase4. The first block of ApricotPhase4 pays the minimum block gas
+// cost.
+func CalcBlockGasCost(config *params.ChainConfig, parent *types.Header, timestamp uint64) *big.Int {
+	if !config.IsApricotPhase4(timestamp) {
+		return nil
+	}
+	blockGasCostStep := ApricotPhase4BlockGasCostStep
+	if config.IsApricotPhase5(timestamp) {
+		blockGasCostStep = ApricotPhase5BlockGasCostStep
+	}
+	return calcBlockGasCost(
+		config.GetTargetBlockRate(timestamp),
+		ApricotPhase4MinBlockGasCost,
+		ApricotPhase4MaxBlockGasCost,
+		blockGasCostStep,
+		parent.BlockGasCost,
+		parent.Time, timestamp,
+	)
+}
+
 // calcBlockGasCost calculates the required block gas cost. If [parentTime]
 // > [currentTime], the timeElapsed will be treated as 0.
 func calcBlockGasCost(
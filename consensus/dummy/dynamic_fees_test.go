@@ -4,12 +4,16 @@
 package dummy
 
 import (
+	"bytes"
 	"encoding/binary"
 	"math/big"
 	"testing"
 
 	"github.com/DioneProtocol/coreth/core/types"
 	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/utils"
+	"github.com/DioneProtocol/odysseygo/utils/wrappers"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
@@ -435,6 +439,306 @@ func TestCalcBaseFeeAP4(t *testing.T) {
 	}
 }
 
+// TestCalcBaseFeeLongGap confirms that after a gap of at least [rollupWindow]
+// seconds, the base fee decreases by the delta of an empty window for every
+// full window that elapsed, regardless of the gas consumed before the gap.
+func TestCalcBaseFeeLongGap(t *testing.T) {
+	parentBaseFee := new(big.Int).Mul(ApricotPhase4MinBaseFee, big.NewInt(100))
+	// The base fee decrease of a single empty window.
+	windowDelta := new(big.Int).Div(parentBaseFee, ApricotPhase5BaseFeeChangeDenominator)
+
+	// The window of the parent is full of gas, far above the target.
+	window := make([]byte, params.ApricotPhase3ExtraDataSize)
+	for i := uint64(0); i < rollupWindow; i++ {
+		updateLongWindow(window, i*8, params.ApricotPhase5TargetGas)
+	}
+	parent := &types.Header{
+		Time:    1000,
+		GasUsed: params.ApricotPhase5TargetGas,
+		Number:  big.NewInt(1),
+		BaseFee: parentBaseFee,
+		Extra:   window,
+	}
+
+	tests := []struct {
+		roll     uint64
+		expected *big.Int
+	}{
+		{roll: 10, expected: new(big.Int).Sub(parentBaseFee, windowDelta)},
+		{roll: 100, expected: new(big.Int).Sub(parentBaseFee, new(big.Int).Mul(windowDelta, big.NewInt(10)))},
+		// The trailing partial window is not applied.
+		{roll: 199, expected: new(big.Int).Sub(parentBaseFee, new(big.Int).Mul(windowDelta, big.NewInt(19)))},
+		{roll: 300, expected: new(big.Int).Sub(parentBaseFee, new(big.Int).Mul(windowDelta, big.NewInt(30)))},
+		// The decrease is bounded by the minimum base fee.
+		{roll: 600, expected: ApricotPhase4MinBaseFee},
+		{roll: 1_000_000, expected: ApricotPhase4MinBaseFee},
+	}
+	for _, test := range tests {
+		extra, baseFee, err := CalcBaseFee(params.TestApricotPhase5Config, parent, parent.Time+test.roll)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, baseFee, "roll %d", test.roll)
+		assert.Zero(t, sumLongWindow(extra, int(rollupWindow)), "roll %d", test.roll)
+	}
+
+	// Within the window, the same parent increases the base fee.
+	_, baseFee, err := CalcBaseFee(params.TestApricotPhase5Config, parent, parent.Time+rollupWindow-1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, baseFee.Cmp(parentBaseFee))
+}
+
+// TestCalcBaseFeeTargetBlockRate confirms that the block gas cost added to the
+// rollup window targets the block rate of the chain config.
+func TestCalcBaseFeeTargetBlockRate(t *testing.T) {
+	parent := &types.Header{
+		Time:           1000,
+		GasUsed:        1_000_000,
+		Number:         big.NewInt(1),
+		BaseFee:        big.NewInt(225 * params.GWei),
+		Extra:          make([]byte, params.ApricotPhase3ExtraDataSize),
+		ExtDataGasUsed: common.Big0,
+		BlockGasCost:   big.NewInt(500_000),
+	}
+	slowConfig := *params.TestApricotPhase4Config
+	slowConfig.TargetBlockRate = utils.NewUint64(5)
+	slowConfig.TargetBlockRateTimestamp = utils.NewUint64(0)
+	scheduledConfig := slowConfig
+	scheduledConfig.TargetBlockRateTimestamp = utils.NewUint64(parent.Time + 10)
+
+	tests := []struct {
+		config               *params.ChainConfig
+		expectedBlockGasCost uint64
+	}{
+		// 1 second slower than the default target of 2 seconds.
+		{config: params.TestApricotPhase4Config, expectedBlockGasCost: 450_000},
+		// 2 seconds faster than the target of 5 seconds.
+		{config: &slowConfig, expectedBlockGasCost: 600_000},
+		// The target of 5 seconds is not enabled yet.
+		{config: &scheduledConfig, expectedBlockGasCost: 450_000},
+	}
+	for _, test := range tests {
+		roll := uint64(3)
+		extra, _, err := CalcBaseFee(test.config, parent, parent.Time+roll)
+		assert.NoError(t, err)
+		slot := rollupWindow - 1 - roll
+		assert.Equal(t, parent.GasUsed+test.expectedBlockGasCost, binary.BigEndian.Uint64(extra[slot*8:]), "target block rate %d", test.config.GetTargetBlockRate(parent.Time+roll))
+	}
+}
+
+func TestEstimateNextBaseFeeWithPendingGas(t *testing.T) {
+	parent := &types.Header{
+		Time:           1000,
+		GasUsed:        1_000_000,
+		Number:         big.NewInt(1),
+		BaseFee:        big.NewInt(2 * params.ApricotPhase4MinBaseFee),
+		Extra:          make([]byte, params.ApricotPhase3ExtraDataSize),
+		ExtDataGasUsed: common.Big0,
+	}
+	config := params.TestApricotPhase5Config
+
+	for _, roll := range []uint64{0, 2, rollupWindow, 3 * rollupWindow} {
+		timestamp := parent.Time + roll
+		expectedExtra, expectedBaseFee, err := EstimateNextBaseFee(config, parent, timestamp)
+		assert.NoError(t, err)
+
+		// Without pending gas, the estimate is unchanged.
+		extra, baseFee, err := EstimateNextBaseFeeWithPendingGas(config, parent, timestamp, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedExtra, extra, "roll %d", roll)
+		assert.Equal(t, expectedBaseFee, baseFee, "roll %d", roll)
+
+		// Pending gas is added to the most recent slot, even if the parent
+		// has fallen out of the window.
+		pendingGas := 2 * params.ApricotPhase5TargetGas
+		extra, baseFee, err = EstimateNextBaseFeeWithPendingGas(config, parent, timestamp, pendingGas)
+		assert.NoError(t, err)
+		assert.Equal(t, sumLongWindow(expectedExtra, int(rollupWindow))+pendingGas, sumLongWindow(extra, int(rollupWindow)), "roll %d", roll)
+		assert.Greater(t, baseFee.Cmp(expectedBaseFee), 0, "roll %d", roll)
+		assert.Greater(t, baseFee.Cmp(parent.BaseFee), 0, "roll %d", roll)
+	}
+}
+
+func TestSimulateBaseFees(t *testing.T) {
+	genesis := &types.Header{
+		Number:   big.NewInt(0),
+		Time:     1000,
+		GasLimit: params.CortinaGasLimit,
+	}
+	config := params.TestApricotPhase5Config
+
+	// At the default target block rate, the rollup window of a block holds
+	// the gas of its 4 most recent ancestors.
+	gasPerBlockAtTarget := params.ApricotPhase5TargetGas / 4
+	sustained := func(numBlocks int, gasUsed uint64) []SimulatedBlock {
+		blocks := make([]SimulatedBlock, numBlocks)
+		for i := range blocks {
+			blocks[i] = SimulatedBlock{
+				GasUsed:   gasUsed,
+				Timestamp: genesis.Time + 2*uint64(i+1),
+			}
+		}
+		return blocks
+	}
+
+	t.Run("matches chained CalcBaseFee", func(t *testing.T) {
+		blocks := []SimulatedBlock{
+			{GasUsed: 5_000_000, ExtDataGasUsed: 50_000, Timestamp: 1001},
+			{GasUsed: 8_000_000, Timestamp: 1002},
+			{GasUsed: 0, Timestamp: 1002},
+			{GasUsed: 15_000_000, ExtDataGasUsed: 100_000, Timestamp: 1005},
+			{GasUsed: 1_000_000, Timestamp: 1030},
+		}
+		baseFees, err := SimulateBaseFees(config, genesis, blocks)
+		assert.NoError(t, err)
+		assert.Len(t, baseFees, len(blocks))
+
+		parent := genesis
+		for i, block := range blocks {
+			extra, baseFee, err := CalcBaseFee(config, parent, block.Timestamp)
+			assert.NoError(t, err)
+			assert.Equal(t, baseFee, baseFees[i], "block %d", i)
+			parent = &types.Header{
+				Number:         new(big.Int).Add(parent.Number, common.Big1),
+				GasUsed:        block.GasUsed,
+				ExtDataGasUsed: new(big.Int).SetUint64(block.ExtDataGasUsed),
+				Time:           block.Timestamp,
+				Extra:          extra,
+				BaseFee:        baseFee,
+			}
+		}
+	})
+
+	t.Run("sustained 80% capacity settles at the minimum", func(t *testing.T) {
+		baseFees, err := SimulateBaseFees(config, genesis, sustained(200, gasPerBlockAtTarget*8/10))
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(params.ApricotPhase3InitialBaseFee), baseFees[0])
+		for i := 1; i < len(baseFees); i++ {
+			assert.LessOrEqual(t, baseFees[i].Cmp(baseFees[i-1]), 0, "block %d", i)
+		}
+		assert.Equal(t, ApricotPhase4MinBaseFee, baseFees[len(baseFees)-1])
+	})
+
+	t.Run("sustained 120% capacity keeps increasing", func(t *testing.T) {
+		baseFees, err := SimulateBaseFees(config, genesis, sustained(200, gasPerBlockAtTarget*12/10))
+		assert.NoError(t, err)
+		// Once the window has filled up, every block increases the base fee.
+		for i := 10; i < len(baseFees); i++ {
+			assert.Positive(t, baseFees[i].Cmp(baseFees[i-1]), "block %d", i)
+		}
+	})
+
+	t.Run("timestamp prior to parent", func(t *testing.T) {
+		_, err := SimulateBaseFees(config, genesis, []SimulatedBlock{
+			{Timestamp: 1002},
+			{Timestamp: 1001},
+		})
+		assert.ErrorContains(t, err, "block 1")
+	})
+
+	t.Run("blocks prior to ApricotPhase3", func(t *testing.T) {
+		baseFees, err := SimulateBaseFees(params.TestApricotPhase2Config, genesis, sustained(3, gasPerBlockAtTarget))
+		assert.NoError(t, err)
+		assert.Equal(t, []*big.Int{nil, nil, nil}, baseFees)
+	})
+}
+
+func TestExpectedExtraDataSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         params.Rules
+		expectedSize  uint64
+		expectedExact bool
+	}{
+		{
+			name:         "launch",
+			rules:        params.Rules{},
+			expectedSize: params.MaximumExtraDataSize,
+		},
+		{
+			name:          "apricot phase 1",
+			rules:         params.TestApricotPhase1Config.ForkFlags(common.Big0, 0),
+			expectedExact: true,
+		},
+		{
+			name:          "apricot phase 3",
+			rules:         params.TestApricotPhase3Config.ForkFlags(common.Big0, 0),
+			expectedSize:  params.ApricotPhase3ExtraDataSize,
+			expectedExact: true,
+		},
+		{
+			name:          "latest",
+			rules:         params.TestChainConfig.ForkFlags(common.Big0, 0),
+			expectedSize:  params.ApricotPhase3ExtraDataSize,
+			expectedExact: true,
+		},
+		{
+			name:          "ody phase orion snapshot",
+			rules:         params.Rules{IsApricotPhase3: true, IsOdyPhaseOrionSnapshot: true},
+			expectedSize:  params.ApricotPhase3ExtraDataSize + params.OrionSnapshotExtraDataSize,
+			expectedExact: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			size, exact := ExpectedExtraDataSize(test.rules)
+			assert.Equal(t, test.expectedSize, size)
+			assert.Equal(t, test.expectedExact, exact)
+		})
+	}
+}
+
+func TestDecodeRollupWindow(t *testing.T) {
+	extra := make([]byte, params.ApricotPhase3ExtraDataSize)
+	for i := uint64(0); i < rollupWindow; i++ {
+		updateLongWindow(extra, i*wrappers.LongLen, 1000*(i+1))
+	}
+	window, err := DecodeRollupWindow(params.TestApricotPhase3Config, &types.Header{
+		Number: common.Big1,
+		Extra:  extra,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1000, 2000, 3000, 4000, 5000, 6000, 7000, 8000, 9000, 10000}, window)
+	assert.Equal(t, sumLongWindow(extra, int(rollupWindow)), sumUint64s(window))
+
+	// The gas of the parent is recorded in the slot of the second before the
+	// child.
+	parent := &types.Header{
+		Number:  common.Big1,
+		Time:    1,
+		GasUsed: 1_000_000,
+		Extra:   make([]byte, params.ApricotPhase3ExtraDataSize),
+		BaseFee: big.NewInt(params.ApricotPhase3MinBaseFee),
+	}
+	extra, _, err = CalcBaseFee(params.TestChainConfig, parent, 2)
+	assert.NoError(t, err)
+	window, err = DecodeRollupWindow(params.TestChainConfig, &types.Header{
+		Number: common.Big2,
+		Time:   2,
+		Extra:  extra,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, parent.GasUsed, window[rollupWindow-2])
+	assert.Equal(t, parent.GasUsed, sumUint64s(window))
+
+	_, err = DecodeRollupWindow(params.TestApricotPhase2Config, &types.Header{
+		Number: common.Big1,
+		Extra:  extra,
+	})
+	assert.Error(t, err)
+	_, err = DecodeRollupWindow(params.TestApricotPhase3Config, &types.Header{
+		Number: common.Big1,
+		Extra:  extra[1:],
+	})
+	assert.Error(t, err)
+}
+
+func sumUint64s(values []uint64) uint64 {
+	var sum uint64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
 func TestCalcBlockGasCost(t *testing.T) {
 	tests := map[string]struct {
 		parentBlockGasCost      *big.Int
@@ -536,3 +840,176 @@ func TestCalcBlockGasCost(t *testing.T) {
 		})
 	}
 }
+
+func TestCalcBaseFeeExtraChecksum(t *testing.T) {
+	config := *params.TestApricotPhase5Config
+	config.OdyPhaseExtraChecksumTimestamp = utils.NewUint64(1000)
+
+	// The parent predates the checksum, so its window is migrated by dropping
+	// its oldest slot.
+	extra := make([]byte, params.ApricotPhase3ExtraDataSize)
+	for i := uint64(0); i < rollupWindow; i++ {
+		updateLongWindow(extra, i*wrappers.LongLen, 1000*(i+1))
+	}
+	parent := &types.Header{
+		Number:         big.NewInt(1),
+		Time:           999,
+		GasUsed:        1_000_000,
+		Extra:          extra,
+		BaseFee:        big.NewInt(params.ApricotPhase4MinBaseFee),
+		ExtDataGasUsed: common.Big0,
+	}
+	extra, _, err := CalcBaseFee(&config, parent, 1000)
+	assert.NoError(t, err)
+	assert.Len(t, extra, int(params.ApricotPhase3ExtraDataSize))
+	assert.NoError(t, verifyRollupWindowChecksum(extra))
+	window, err := DecodeRollupWindow(&config, &types.Header{
+		Number: big.NewInt(2),
+		Time:   1000,
+		Extra:  extra,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3000, 4000, 5000, 6000, 7000, 8000, 9000, 10000 + parent.GasUsed, 0}, window)
+
+	// The gas of the parent is recorded in the slot of the second before the
+	// child, and the checksum follows the gas slots.
+	parent = &types.Header{
+		Number:         big.NewInt(2),
+		Time:           1000,
+		GasUsed:        2_000_000,
+		Extra:          extra,
+		BaseFee:        big.NewInt(params.ApricotPhase4MinBaseFee),
+		ExtDataGasUsed: common.Big0,
+	}
+	extra, _, err = CalcBaseFee(&config, parent, 1001)
+	assert.NoError(t, err)
+	assert.NoError(t, verifyRollupWindowChecksum(extra))
+	window, err = DecodeRollupWindow(&config, &types.Header{
+		Number: big.NewInt(3),
+		Time:   1001,
+		Extra:  extra,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{4000, 5000, 6000, 7000, 8000, 9000, 1_010_000, 2_000_000, 0}, window)
+
+	// Pending gas is added to the last gas slot, not to the checksum.
+	pendingExtra, _, err := EstimateNextBaseFeeWithPendingGas(&config, parent, 1001, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, verifyRollupWindowChecksum(pendingExtra))
+	assert.Equal(t, sumLongWindow(extra, int(rollupWindow-1))+1, sumLongWindow(pendingExtra, int(rollupWindow-1)))
+
+	// A corrupted window is rejected.
+	corrupted := *parent
+	corrupted.Extra = common.CopyBytes(parent.Extra)
+	corrupted.Extra[0] ^= 1
+	_, _, err = CalcBaseFee(&config, &corrupted, 1001)
+	assert.ErrorIs(t, err, errInvalidRollupWindowChecksum)
+	_, err = DecodeRollupWindow(&config, &corrupted)
+	assert.ErrorIs(t, err, errInvalidRollupWindowChecksum)
+
+	// The first block of a network is given a checksummed window.
+	extra, _, err = CalcBaseFee(&config, &types.Header{Number: common.Big0, Time: 1000}, 1001)
+	assert.NoError(t, err)
+	assert.NoError(t, verifyRollupWindowChecksum(extra))
+}
+
+// TestCalcBaseFeeOrionSnapshot confirms that from OdyPhaseOrionSnapshot, the
+// orion snapshot following the rollup window in the Extra field of a header is
+// not part of the window.
+func TestCalcBaseFeeOrionSnapshot(t *testing.T) {
+	config := *params.TestChainConfig
+	config.OdyPhaseOrionSnapshotTimestamp = utils.NewUint64(1000)
+
+	window := make([]byte, params.ApricotPhase3ExtraDataSize)
+	for i := uint64(0); i < rollupWindow; i++ {
+		updateLongWindow(window, i*wrappers.LongLen, 1000*(i+1))
+	}
+	snapshot := bytes.Repeat([]byte{0xff}, int(params.OrionSnapshotExtraDataSize))
+	parent := &types.Header{
+		Number:         big.NewInt(1),
+		Time:           1000,
+		GasUsed:        1_000_000,
+		Extra:          append(common.CopyBytes(window), snapshot...),
+		BaseFee:        big.NewInt(params.ApricotPhase4MinBaseFee),
+		ExtDataGasUsed: common.Big0,
+		BlockGasCost:   common.Big0,
+	}
+	decoded, err := DecodeRollupWindow(&config, parent)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1000, 2000, 3000, 4000, 5000, 6000, 7000, 8000, 9000, 10000}, decoded)
+
+	// The window of the child is rolled from the window of the parent alone.
+	extra, baseFee, err := CalcBaseFee(&config, parent, 1001)
+	assert.NoError(t, err)
+	assert.Len(t, extra, int(params.ApricotPhase3ExtraDataSize))
+	withoutSnapshot := *parent
+	withoutSnapshot.Extra = window
+	expectedExtra, expectedBaseFee, err := CalcBaseFee(params.TestChainConfig, &withoutSnapshot, 1001)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedExtra, extra)
+	assert.Equal(t, expectedBaseFee, baseFee)
+
+	// A header from the fork without a snapshot is rejected.
+	_, err = DecodeRollupWindow(&config, &types.Header{
+		Number: big.NewInt(2),
+		Time:   1001,
+		Extra:  extra,
+	})
+	assert.Error(t, err)
+	_, _, err = CalcBaseFee(&config, &types.Header{
+		Number:  big.NewInt(2),
+		Time:    1001,
+		Extra:   extra,
+		BaseFee: baseFee,
+	}, 1002)
+	assert.Error(t, err)
+}
+
+// TestCalcBaseFeeExtraChecksumTarget confirms that from OdyPhaseExtraChecksum,
+// the gas target is scaled to the gas slots of the rollup window, so that a
+// window consuming the target per slot keeps the base fee unchanged.
+func TestCalcBaseFeeExtraChecksumTarget(t *testing.T) {
+	config := *params.TestApricotPhase5Config
+	config.OdyPhaseExtraChecksumTimestamp = utils.NewUint64(0)
+
+	gasSlots := rollupGasSlots(true)
+	slotGas := params.ApricotPhase5TargetGas / rollupWindow
+	parentBaseFee := new(big.Int).Mul(ApricotPhase4MinBaseFee, big.NewInt(100))
+	newParent := func(gasUsed uint64) *types.Header {
+		// The child rolls the window by one second, dropping its oldest slot
+		// and adding [gasUsed] to the last one.
+		extra := make([]byte, params.ApricotPhase3ExtraDataSize)
+		for i := uint64(1); i < gasSlots; i++ {
+			updateLongWindow(extra, i*wrappers.LongLen, slotGas)
+		}
+		putRollupWindowChecksum(extra)
+		return &types.Header{
+			Number:         big.NewInt(2),
+			Time:           1000,
+			GasUsed:        gasUsed,
+			Extra:          extra,
+			BaseFee:        parentBaseFee,
+			ExtDataGasUsed: common.Big0,
+		}
+	}
+
+	// The window holds exactly the scaled target.
+	extra, baseFee, err := CalcBaseFee(&config, newParent(slotGas), 1001)
+	assert.NoError(t, err)
+	assert.Equal(t, params.ApricotPhase5TargetGas*gasSlots/rollupWindow, sumLongWindow(extra, int(gasSlots)))
+	assert.Equal(t, parentBaseFee, baseFee)
+
+	// One unit of gas above or below the target moves the base fee.
+	_, baseFee, err = CalcBaseFee(&config, newParent(slotGas+1), 1001)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, baseFee.Cmp(parentBaseFee))
+	_, baseFee, err = CalcBaseFee(&config, newParent(slotGas-1), 1001)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, baseFee.Cmp(parentBaseFee))
+
+	// A gap is counted in windows of [gasSlots] seconds.
+	windowDelta := new(big.Int).Div(parentBaseFee, ApricotPhase5BaseFeeChangeDenominator)
+	_, baseFee, err = CalcBaseFee(&config, newParent(slotGas), 1000+2*gasSlots)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Sub(parentBaseFee, new(big.Int).Mul(windowDelta, big.NewInt(2))), baseFee)
+}
@@ -160,6 +160,9 @@ type CacheConfig struct {
 	Preimages                       bool          // Whether to store preimage of trie key to the disk
 	AcceptedCacheSize               int           // Depth of accepted headers cache and accepted logs cache at the accepted tip
 	TxLookupLimit                   uint64        // Number of recent blocks for which to maintain transaction lookup indices
+	OpcodeStatsBlocks               int           // Number of recently processed blocks for which to retain opcode stats (0 = disabled)
+
+	UpgradeCheckOverride *params.UpgradeCheckOverride // If non-nil, allows changes to the upgrades scheduled at or after its thresholds
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
@@ -224,6 +227,7 @@ type BlockChain struct {
 	blockCache    *lru.Cache[common.Hash, *types.Block]               // Cache for the most recent entire blocks
 	txLookupCache *lru.Cache[common.Hash, *rawdb.LegacyTxLookupEntry] // Cache for the most recent transaction lookup data.
 	badBlocks     *lru.Cache[common.Hash, *badBlock]                  // Cache for bad blocks
+	opcodeStats   *lru.Cache[common.Hash, *vm.OpcodeStats]            // Opcode stats of the most recently processed blocks, if enabled
 
 	stopping atomic.Bool // false if chain is running, true when stopped
 
@@ -301,7 +305,7 @@ func NewBlockChain(
 	// Note: In go-ethereum, the code rewinds the chain on an incompatible config upgrade.
 	// We don't do this and expect the node operator to always update their node's configuration
 	// before network upgrades take effect.
-	chainConfig, _, err := SetupGenesisBlock(db, triedb, genesis, lastAcceptedHash, skipChainConfigCheckCompatible)
+	chainConfig, _, err := SetupGenesisBlock(db, triedb, genesis, lastAcceptedHash, skipChainConfigCheckCompatible, cacheConfig.UpgradeCheckOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -330,6 +334,9 @@ func NewBlockChain(
 		quit:              make(chan struct{}),
 		acceptedLogsCache: NewFIFOCache[common.Hash, [][]*types.Log](cacheConfig.AcceptedCacheSize),
 	}
+	if cacheConfig.OpcodeStatsBlocks > 0 {
+		bc.opcodeStats = lru.NewCache[common.Hash, *vm.OpcodeStats](cacheConfig.OpcodeStatsBlocks)
+	}
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
@@ -1316,7 +1323,11 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	// transactions and probabilistically some of the account/storage trie nodes.
 	// Process block using the parent state as reference point
 	pstart := time.Now()
-	receipts, logs, usedGas, err := bc.processor.Process(block, parent, statedb, bc.vmConfig)
+	vmConfig := bc.vmConfig
+	if bc.opcodeStats != nil {
+		vmConfig.OpcodeStats = new(vm.OpcodeStats)
+	}
+	receipts, logs, usedGas, err := bc.processor.Process(block, parent, statedb, vmConfig)
 	if serr := statedb.Error(); serr != nil {
 		log.Error("statedb error encountered", "err", serr, "number", block.Number(), "hash", block.Hash())
 	}
@@ -1333,6 +1344,9 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 		return err
 	}
 	vtime := time.Since(vstart)
+	if bc.opcodeStats != nil {
+		bc.opcodeStats.Add(block.Hash(), vmConfig.OpcodeStats)
+	}
 
 	// Update the metrics touched during block processing and validation
 	accountReadTimer.Inc(statedb.AccountReads.Milliseconds())                  // Account reads are complete(in processing)
@@ -287,6 +287,28 @@ func (bc *BlockChain) GetVMConfig() *vm.Config {
 	return &bc.vmConfig
 }
 
+// GetOpcodeStats returns the opcode stats recorded while processing the block
+// with [hash], if opcode stats are enabled and the block was processed recently.
+func (bc *BlockChain) GetOpcodeStats(hash common.Hash) (*vm.OpcodeStats, bool) {
+	if bc.opcodeStats == nil {
+		return nil, false
+	}
+	return bc.opcodeStats.Get(hash)
+}
+
+// ResetOpcodeStats discards the opcode stats recorded so far.
+func (bc *BlockChain) ResetOpcodeStats() {
+	if bc.opcodeStats != nil {
+		bc.opcodeStats.Purge()
+	}
+}
+
+// OpcodeStatsEnabled returns whether opcode stats are recorded for the blocks
+// processed by [bc].
+func (bc *BlockChain) OpcodeStatsEnabled() bool {
+	return bc.opcodeStats != nil
+}
+
 // TrieDB retrieves the low level trie database used for data storage.
 func (bc *BlockChain) TrieDB() *trie.Database {
 	return bc.triedb
@@ -1166,3 +1166,58 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+func TestOpcodeStatsBlockChain(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		engine  = dummy.NewFaker()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))}},
+		}
+		// Deploys an empty contract.
+		initCode = []byte{
+			byte(vm.PUSH1), 0x0, // size
+			byte(vm.PUSH1), 0x0, // offset
+			byte(vm.RETURN),
+		}
+	)
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, engine, 2, 10, func(i int, b *BlockGen) {
+		tx, err := types.SignNewTx(key, types.HomesteadSigner{}, &types.LegacyTx{
+			Nonce:    uint64(i),
+			GasPrice: b.header.BaseFee,
+			Gas:      100000,
+			Data:     initCode,
+		})
+		require.NoError(err)
+		b.AddTx(tx)
+	})
+	require.NoError(err)
+
+	cacheConfig := *DefaultCacheConfig
+	cacheConfig.OpcodeStatsBlocks = 1
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &cacheConfig, gspec, engine, vm.Config{}, common.Hash{}, false)
+	require.NoError(err)
+	defer chain.Stop()
+	require.True(chain.OpcodeStatsEnabled())
+
+	_, err = chain.InsertChain(blocks)
+	require.NoError(err)
+
+	// Only the stats of the most recently processed block are retained.
+	_, ok := chain.GetOpcodeStats(blocks[0].Hash())
+	require.False(ok)
+	stats, ok := chain.GetOpcodeStats(blocks[1].Hash())
+	require.True(ok)
+	require.Equal(uint64(2), stats.Counts[vm.PUSH1])
+	require.Equal(2*vm.GasFastestStep, stats.Gas[vm.PUSH1])
+	require.Equal(uint64(1), stats.Counts[vm.RETURN])
+	require.Zero(stats.Counts[vm.STOP])
+
+	chain.ResetOpcodeStats()
+	_, ok = chain.GetOpcodeStats(blocks[1].Hash())
+	require.False(ok)
+}
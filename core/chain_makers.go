@@ -309,12 +309,8 @@ func makeHeader(chain consensus.ChainReader, config *params.ChainConfig, parent
 		time = parent.Time() + gap
 	}
 
-	var gasLimit uint64
-	if config.IsCortina(time) {
-		gasLimit = params.CortinaGasLimit
-	} else if config.IsApricotPhase1(time) {
-		gasLimit = params.ApricotPhase1GasLimit
-	} else {
+	gasLimit, _ := dummy.GasLimit(config.ForkFlags(new(big.Int).Add(parent.Number(), common.Big1), time))
+	if gasLimit == 0 {
 		gasLimit = CalcGasLimit(parent.GasUsed(), parent.GasLimit(), parent.GasLimit(), parent.GasLimit())
 	}
 
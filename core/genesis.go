@@ -37,6 +37,7 @@ import (
 	"github.com/DioneProtocol/coreth/core/rawdb"
 	"github.com/DioneProtocol/coreth/core/state"
 	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/core/vm"
 	"github.com/DioneProtocol/coreth/ethdb"
 	"github.com/DioneProtocol/coreth/params"
 	"github.com/DioneProtocol/coreth/trie"
@@ -49,7 +50,10 @@ import (
 //go:generate go run github.com/fjl/gencodec -type Genesis -field-override genesisSpecMarshaling -out gen_genesis.go
 //go:generate go run github.com/fjl/gencodec -type GenesisAccount -field-override genesisAccountMarshaling -out gen_genesis_account.go
 
-var errGenesisNoConfig = errors.New("genesis has no chain configuration")
+var (
+	errGenesisNoConfig     = errors.New("genesis has no chain configuration")
+	errUnsupportedExtraEIP = errors.New("extra eip is not supported by the DELTA")
+)
 
 // Genesis specifies the header fields, state of a genesis block. It also defines hard
 // fork switch-over blocks through the chain configuration.
@@ -161,8 +165,11 @@ func (e *GenesisMismatchError) Error() string {
 // The stored chain configuration will be updated if it is compatible (i.e. does not
 // specify a fork block below the local head block). In case of a conflict, the
 // error is a *params.ConfigCompatError and the new, unwritten config is returned.
+// If [upgradeCheckOverride] is non-nil, changes to the forks it allows are
+// accepted regardless of the local head block.
 func SetupGenesisBlock(
 	db ethdb.Database, triedb *trie.Database, genesis *Genesis, lastAcceptedHash common.Hash, skipChainConfigCheckCompatible bool,
+	upgradeCheckOverride *params.UpgradeCheckOverride,
 ) (*params.ChainConfig, common.Hash, error) {
 	if genesis == nil {
 		return nil, common.Hash{}, ErrNoGenesis
@@ -201,6 +208,9 @@ func SetupGenesisBlock(
 	if err := newcfg.CheckConfigForkOrder(); err != nil {
 		return newcfg, common.Hash{}, err
 	}
+	if err := checkExtraEIPs(newcfg); err != nil {
+		return newcfg, common.Hash{}, err
+	}
 	storedcfg := rawdb.ReadChainConfig(db, stored)
 	if storedcfg == nil {
 		log.Warn("Found genesis block without chain config")
@@ -225,10 +235,15 @@ func SetupGenesisBlock(
 	if skipChainConfigCheckCompatible {
 		log.Info("skipping verifying activated network upgrades on chain config")
 	} else {
-		compatErr := storedcfg.CheckCompatible(newcfg, height, timestamp)
-		if compatErr != nil && ((height != 0 && compatErr.RewindToBlock != 0) || (timestamp != 0 && compatErr.RewindToTime != 0)) {
+		if compatErr := CheckChainConfigCompatible(storedcfg, newcfg, height, timestamp, upgradeCheckOverride); compatErr != nil {
 			return newcfg, stored, compatErr
 		}
+		if checkHeight, checkTimestamp := upgradeCheckOverride.Head(height, timestamp); checkHeight != height || checkTimestamp != timestamp {
+			if compatErr := storedcfg.CheckCompatible(newcfg, height, timestamp); compatErr != nil {
+				log.Warn("accepting incompatible chain config change due to the upgrade check override",
+					"err", compatErr, "height", height, "timestamp", timestamp, "checkHeight", checkHeight, "checkTimestamp", checkTimestamp)
+			}
+		}
 	}
 	// Don't overwrite if the old is identical to the new
 	if newData, _ := json.Marshal(newcfg); !bytes.Equal(storedData, newData) {
@@ -237,6 +252,20 @@ func SetupGenesisBlock(
 	return newcfg, stored, nil
 }
 
+// CheckChainConfigCompatible returns the error SetupGenesisBlock fails with if
+// [storedcfg] is replaced by [newcfg] while the last accepted block is at
+// [height] and [timestamp], or nil if the change is accepted.
+func CheckChainConfigCompatible(
+	storedcfg, newcfg *params.ChainConfig, height uint64, timestamp uint64, upgradeCheckOverride *params.UpgradeCheckOverride,
+) *params.ConfigCompatError {
+	checkHeight, checkTimestamp := upgradeCheckOverride.Head(height, timestamp)
+	compatErr := storedcfg.CheckCompatible(newcfg, checkHeight, checkTimestamp)
+	if compatErr != nil && ((checkHeight != 0 && compatErr.RewindToBlock != 0) || (checkTimestamp != 0 && compatErr.RewindToTime != 0)) {
+		return compatErr
+	}
+	return nil
+}
+
 // ToBlock creates the genesis block and writes state of a genesis specification
 // to the given database (or discards it if nil).
 func (g *Genesis) ToBlock() *types.Block {
@@ -266,7 +295,9 @@ func (g *Genesis) toBlock(db ethdb.Database, triedb *trie.Database) *types.Block
 	}
 
 	// Configure any stateful precompiles that should be enabled in the genesis.
-	g.Config.CheckConfigurePrecompiles(nil, types.NewBlockWithHeader(head), statedb)
+	if err := g.Config.CheckConfigurePrecompiles(nil, types.NewBlockWithHeader(head), statedb); err != nil {
+		panic(fmt.Sprintf("unable to configure precompiles in genesis: %v", err))
+	}
 
 	for addr, account := range g.Alloc {
 		statedb.AddBalance(addr, account.Balance)
@@ -321,6 +352,9 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *trie.Database) (*types.Block
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, err
 	}
+	if err := checkExtraEIPs(config); err != nil {
+		return nil, err
+	}
 	rawdb.WriteBlock(db, block)
 	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
 	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
@@ -360,3 +394,14 @@ func ReadBlockByHash(db ethdb.Reader, hash common.Hash) *types.Block {
 	}
 	return rawdb.ReadBlock(db, hash, *blockNumber)
 }
+
+// checkExtraEIPs returns an error if [config] schedules an EIP that the DELTA
+// can not enable.
+func checkExtraEIPs(config *params.ChainConfig) error {
+	for _, extra := range config.ExtraEIPs {
+		if !vm.ValidEip(extra.EIP) {
+			return fmt.Errorf("%w: EIP-%d", errUnsupportedExtraEIP, extra.EIP)
+		}
+	}
+	return nil
+}
@@ -45,7 +45,7 @@ import (
 )
 
 func setupGenesisBlock(db ethdb.Database, triedb *trie.Database, genesis *Genesis, lastAcceptedHash common.Hash) (*params.ChainConfig, common.Hash, error) {
-	return SetupGenesisBlock(db, triedb, genesis, lastAcceptedHash, false)
+	return SetupGenesisBlock(db, triedb, genesis, lastAcceptedHash, false, nil)
 }
 
 func TestGenesisBlockForTesting(t *testing.T) {
@@ -172,6 +172,62 @@ func TestSetupGenesis(t *testing.T) {
 	}
 }
 
+func TestSetupGenesisUpgradeCheckOverride(t *testing.T) {
+	storedConfig := *params.TestApricotPhase1Config
+	storedConfig.ApricotPhase1BlockTimestamp = utils.NewUint64(90)
+	storedg := Genesis{
+		Config: &storedConfig,
+		Alloc: GenesisAlloc{
+			{1}: {Balance: big.NewInt(1), Storage: map[common.Hash]common.Hash{{1}: {1}}},
+		},
+	}
+	newConfig := storedConfig
+	newConfig.ApricotPhase1BlockTimestamp = utils.NewUint64(100)
+	newg := storedg
+	newg.Config = &newConfig
+
+	// setup accepts 4 blocks, up to timestamp 100, with [storedg] and sets up
+	// [newg] with [override].
+	setup := func(t *testing.T, override *params.UpgradeCheckOverride) (*params.ChainConfig, error) {
+		db := rawdb.NewMemoryDatabase()
+		genesis := storedg.MustCommit(db)
+		bc, err := NewBlockChain(db, DefaultCacheConfig, &storedg, dummy.NewFullFaker(), vm.Config{}, genesis.Hash(), false)
+		require.NoError(t, err)
+		defer bc.Stop()
+
+		blocks, _, err := GenerateChain(storedg.Config, genesis, dummy.NewFullFaker(), db, 4, 25, nil)
+		require.NoError(t, err)
+		_, err = bc.InsertChain(blocks)
+		require.NoError(t, err)
+		for _, block := range blocks {
+			require.NoError(t, bc.Accept(block))
+		}
+		bc.DrainAcceptorQueue()
+
+		config, _, err := SetupGenesisBlock(db, trie.NewDatabase(db), &newg, bc.lastAccepted.Hash(), false, override)
+		if err == nil {
+			// The new config replaces the stored one.
+			require.Equal(t, config.ApricotPhase1BlockTimestamp, rawdb.ReadChainConfig(db, genesis.Hash()).ApricotPhase1BlockTimestamp)
+		}
+		return config, err
+	}
+
+	t.Run("upgrade at the override", func(t *testing.T) {
+		config, err := setup(t, &params.UpgradeCheckOverride{Timestamp: utils.NewUint64(90)})
+		require.NoError(t, err)
+		require.Equal(t, &newConfig, config)
+	})
+	t.Run("upgrade before the override", func(t *testing.T) {
+		_, err := setup(t, &params.UpgradeCheckOverride{Timestamp: utils.NewUint64(95)})
+		require.Equal(t, &params.ConfigCompatError{
+			What:         "ApricotPhase1 fork block timestamp",
+			StoredTime:   u64(90),
+			NewTime:      u64(100),
+			RewindToTime: 89,
+		}, err)
+	})
+}
+
 // regression test for precompile activation after header block
 func TestNetworkUpgradeBetweenHeadAndAcceptedBlock(t *testing.T) {
 	db := rawdb.NewMemoryDatabase()
@@ -221,3 +277,19 @@ func TestNetworkUpgradeBetweenHeadAndAcceptedBlock(t *testing.T) {
 		t.Errorf("returned %v\nwant     %v", config, activatedGenesis.Config)
 	}
 }
+
+func TestGenesisUnsupportedExtraEIP(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ExtraEIPs = []params.ExtraEIP{{EIP: 1153, Timestamp: 10}, {EIP: 1, Timestamp: 20}}
+	g := Genesis{Config: &config}
+
+	db := rawdb.NewMemoryDatabase()
+	_, err := g.Commit(db, trie.NewDatabase(db))
+	require.ErrorIs(t, err, errUnsupportedExtraEIP)
+
+	config.ExtraEIPs = config.ExtraEIPs[:1]
+	genesis := g.MustCommit(db)
+	config.ExtraEIPs = append(config.ExtraEIPs, params.ExtraEIP{EIP: 1, Timestamp: 20})
+	_, _, err = SetupGenesisBlock(db, trie.NewDatabase(db), &g, genesis.Hash(), false, nil)
+	require.ErrorIs(t, err, errUnsupportedExtraEIP)
+}
@@ -77,7 +77,9 @@ func (p *StateProcessor) Process(block *types.Block, parent *types.Header, state
 	)
 
 	// Configure any stateful precompiles that should go into effect during this block.
-	p.config.CheckConfigurePrecompiles(&parent.Time, block, statedb)
+	if err := p.config.CheckConfigurePrecompiles(&parent.Time, block, statedb); err != nil {
+		return nil, nil, 0, fmt.Errorf("could not configure precompiles for block %d: %w", block.NumberU64(), err)
+	}
 
 	var (
 		context = NewDELTABlockContext(header, p.bc, nil)
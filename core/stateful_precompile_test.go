@@ -4,11 +4,18 @@
 package core
 
 import (
+	"errors"
 	"math/big"
+	"testing"
 
+	"github.com/DioneProtocol/coreth/core/rawdb"
 	"github.com/DioneProtocol/coreth/core/state"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
 	"github.com/DioneProtocol/coreth/precompile"
+	"github.com/DioneProtocol/coreth/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -41,3 +48,59 @@ func (m *mockAccessibleState) GetBlockContext() precompile.BlockContext { return
 func (m *mockAccessibleState) NativeAssetCall(common.Address, []byte, uint64, uint64, bool) ([]byte, uint64, error) {
 	return m.ret, m.remainingGas, m.err
 }
+
+var errConfigureFailed = errors.New("configure failed")
+
+// mockPrecompileConfig is a stateful precompile config whose Configure returns
+// [configureErr].
+type mockPrecompileConfig struct {
+	addr         common.Address
+	timestamp    *uint64
+	configureErr error
+}
+
+func (c *mockPrecompileConfig) Address() common.Address { return c.addr }
+func (c *mockPrecompileConfig) Timestamp() *uint64      { return c.timestamp }
+func (c *mockPrecompileConfig) Configure(precompile.ChainConfig, precompile.StateDB, precompile.BlockContext) error {
+	return c.configureErr
+}
+func (c *mockPrecompileConfig) Contract() precompile.StatefulPrecompiledContract { return c }
+func (*mockPrecompileConfig) Run(precompile.PrecompileAccessibleState, common.Address, common.Address, []byte, uint64, bool) ([]byte, uint64, error) {
+	return nil, 0, nil
+}
+
+func TestCheckConfigurePrecompilesError(t *testing.T) {
+	require := require.New(t)
+
+	config := *params.TestChainConfig
+	require.NoError(config.RegisterPrecompile(&mockPrecompileConfig{
+		addr:      common.HexToAddress("0x0100000000000000000000000000000000000010"),
+		timestamp: utils.NewUint64(10),
+	}))
+	require.NoError(config.RegisterPrecompile(&mockPrecompileConfig{
+		addr:         common.HexToAddress("0x0100000000000000000000000000000000000011"),
+		timestamp:    utils.NewUint64(20),
+		configureErr: errConfigureFailed,
+	}))
+
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+
+	// Only the precompile which configures successfully is activated.
+	blockContext := &mockBlockContext{blockNumber: big.NewInt(1), timestamp: 10}
+	require.NoError(config.CheckConfigurePrecompiles(utils.NewUint64(0), blockContext, statedb))
+
+	blockContext = &mockBlockContext{blockNumber: big.NewInt(2), timestamp: 20}
+	err = config.CheckConfigurePrecompiles(utils.NewUint64(10), blockContext, statedb)
+	require.ErrorIs(err, errConfigureFailed)
+
+	// A precompile that fails to configure at genesis halts genesis setup.
+	config = *params.TestChainConfig
+	require.NoError(config.RegisterPrecompile(&mockPrecompileConfig{
+		addr:         common.HexToAddress("0x0100000000000000000000000000000000000010"),
+		timestamp:    utils.NewUint64(0),
+		configureErr: errConfigureFailed,
+	}))
+	genesis := &Genesis{Config: &config}
+	require.Panics(func() { genesis.ToBlock() })
+}
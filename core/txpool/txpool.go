@@ -109,6 +109,10 @@ var (
 	// ErrOverdraft is returned if a transaction would cause the senders balance to go negative
 	// thus invalidating a potential large number of transactions.
 	ErrOverdraft = errors.New("transaction would cause overdraft")
+
+	// ErrNonceReserved is returned if the nonce of a transaction has already been
+	// claimed by a transaction living outside of the pool (e.g. an atomic export).
+	ErrNonceReserved = errors.New("nonce reserved")
 )
 
 var (
@@ -282,8 +286,9 @@ type TxPool struct {
 	// and balances during reorgs and gossip handling.
 	currentStateLock sync.Mutex
 
-	pendingNonces *noncer       // Pending state tracking virtual nonces
-	currentMaxGas atomic.Uint64 // Current gas limit for transaction caps
+	pendingNonces *noncer                                // Pending state tracking virtual nonces
+	reserved      map[common.Address]map[uint64]struct{} // Nonces claimed by transactions outside of the pool
+	currentMaxGas atomic.Uint64                          // Current gas limit for transaction caps
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *journal    // Journal of local transaction to back up to disk
@@ -328,6 +333,7 @@ func NewTxPool(config Config, chainconfig *params.ChainConfig, chain blockChain)
 		pending:             make(map[common.Address]*list),
 		queue:               make(map[common.Address]*list),
 		beats:               make(map[common.Address]time.Time),
+		reserved:            make(map[common.Address]map[uint64]struct{}),
 		all:                 newLookup(),
 		chainHeadCh:         make(chan core.ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:          make(chan *txpoolResetRequest),
@@ -524,12 +530,65 @@ func (pool *TxPool) SetMinFee(minFee *big.Int) {
 }
 
 // Nonce returns the next nonce of an account, with all transactions executable
-// by the pool already applied on top.
+// by the pool and all contiguous reserved nonces already applied on top.
 func (pool *TxPool) Nonce(addr common.Address) uint64 {
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
 
-	return pool.pendingNonces.get(addr)
+	nonce := pool.pendingNonces.get(addr)
+	for pool.isReserved(addr, nonce) {
+		nonce++
+	}
+	return nonce
+}
+
+// ReserveNonce marks [nonce] of [addr] as consumed by a transaction that is
+// tracked outside of the pool. Any pooled transaction using the same nonce is
+// evicted and new ones are rejected until the reservation is released.
+func (pool *TxPool) ReserveNonce(addr common.Address, nonce uint64) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	nonces, ok := pool.reserved[addr]
+	if !ok {
+		nonces = make(map[uint64]struct{})
+		pool.reserved[addr] = nonces
+	}
+	nonces[nonce] = struct{}{}
+
+	var evicted *types.Transaction
+	if list := pool.pending[addr]; list != nil {
+		evicted = list.txs.Get(nonce)
+	}
+	if list := pool.queue[addr]; evicted == nil && list != nil {
+		evicted = list.txs.Get(nonce)
+	}
+	if evicted != nil {
+		log.Debug("Evicting transaction with reserved nonce", "hash", evicted.Hash(), "from", addr, "nonce", nonce)
+		pool.removeTx(evicted.Hash(), true)
+	}
+}
+
+// ReleaseNonce removes the reservation of [nonce] for [addr], if any.
+func (pool *TxPool) ReleaseNonce(addr common.Address, nonce uint64) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	nonces, ok := pool.reserved[addr]
+	if !ok {
+		return
+	}
+	delete(nonces, nonce)
+	if len(nonces) == 0 {
+		delete(pool.reserved, addr)
+	}
+}
+
+// isReserved reports whether [nonce] of [addr] has been reserved.
+// Assumes the lock is held.
+func (pool *TxPool) isReserved(addr common.Address, nonce uint64) bool {
+	_, ok := pool.reserved[addr][nonce]
+	return ok
 }
 
 // Stats retrieves the current pool stats, namely the number of pending and the
@@ -783,6 +842,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.minimumFee != nil && tx.GasFeeCapIntCmp(pool.minimumFee) < 0 {
 		return fmt.Errorf("%w: address %s have gas fee cap (%d) < pool minimum fee cap (%d)", ErrUnderpriced, from.Hex(), tx.GasFeeCap(), pool.minimumFee)
 	}
+	// Drop the transaction if its nonce is claimed outside of the pool
+	if pool.isReserved(from, tx.Nonce()) {
+		return fmt.Errorf("%w: address %s nonce (%d)", ErrNonceReserved, from.Hex(), tx.Nonce())
+	}
 
 	// Ensure the transaction adheres to nonce ordering
 	// Transactor should have enough funds to cover the costs
@@ -359,6 +359,55 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that reserved nonces evict and reject pooled transactions and are
+// skipped when reporting the next nonce of an account.
+func TestReservedNonces(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000))
+
+	tx0, tx1 := transaction(0, 100000, key), transaction(1, 100000, key)
+	if err := pool.addRemoteSync(tx0); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(tx1); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	// Reserving a pending nonce evicts the transaction and demotes the rest
+	pool.ReserveNonce(from, 0)
+	if pool.Has(tx0.Hash()) {
+		t.Errorf("transaction with reserved nonce not evicted")
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 1 {
+		t.Errorf("pool stats mismatch: have %d/%d, want 0/1", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	if nonce := pool.Nonce(from); nonce != 1 {
+		t.Errorf("next nonce mismatch: have %d, want 1", nonce)
+	}
+	// Transactions using the reserved nonce are rejected
+	if err := pool.addRemoteSync(tx0); !errors.Is(err, ErrNonceReserved) {
+		t.Errorf("want %v have %v", ErrNonceReserved, err)
+	}
+	// Releasing the nonce allows it to be used again
+	pool.ReleaseNonce(from, 0)
+	if err := pool.addRemoteSync(tx0); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Errorf("pool stats mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestQueue(t *testing.T) {
 	t.Parallel()
 
@@ -27,11 +27,13 @@
 package vm
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/DioneProtocol/coreth/constants"
 	"github.com/DioneProtocol/coreth/params"
@@ -206,6 +208,19 @@ func init() {
 		PrecompileAllNativeAddresses[k] = struct{}{}
 	}
 
+	// Ensure that the native precompiles within the reserved ranges are declared, so that they cannot
+	// be registered as a stateful precompile.
+	nativeAddresses := make(map[common.Address]struct{}, len(precompile.NativeAddresses))
+	for _, k := range precompile.NativeAddresses {
+		nativeAddresses[k] = struct{}{}
+	}
+	for k := range PrecompileAllNativeAddresses {
+		if _, ok := nativeAddresses[k]; ok || !IsProhibited(k) {
+			continue
+		}
+		panic(fmt.Errorf("native precompile address %s in a reserved range is not declared in precompile.NativeAddresses", k))
+	}
+
 	// Ensure that this package will panic during init if there is a conflict present with the declared
 	// precompile addresses.
 	for _, k := range precompile.UsedAddresses {
@@ -232,18 +247,35 @@ func init() {
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	var addrs []common.Address
 	switch {
 	case rules.IsBanff:
-		return PrecompiledAddressesBanff
+		addrs = PrecompiledAddressesBanff
 	case rules.IsApricotPhase2:
-		return PrecompiledAddressesApricotPhase2
+		addrs = PrecompiledAddressesApricotPhase2
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		addrs = PrecompiledAddressesIstanbul
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		addrs = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		addrs = PrecompiledAddressesHomestead
+	}
+	if len(rules.Precompiles) == 0 {
+		return addrs
+	}
+
+	// Include the stateful precompiles registered with the chain config, in a
+	// deterministic order.
+	configured := make([]common.Address, 0, len(rules.Precompiles))
+	for addr := range rules.Precompiles {
+		configured = append(configured, addr)
 	}
+	sort.Slice(configured, func(i, j int) bool {
+		return bytes.Compare(configured[i][:], configured[j][:]) < 0
+	})
+	active := make([]common.Address, 0, len(addrs)+len(configured))
+	active = append(active, addrs...)
+	return append(active, configured...)
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -29,6 +29,8 @@ package vm
 import (
 	"errors"
 	"fmt"
+
+	"github.com/DioneProtocol/coreth/vmerrs"
 )
 
 // List delta execution errors
@@ -60,6 +62,19 @@ func (e *ErrStackOverflow) Error() string {
 	return fmt.Sprintf("stack limit reached %d (%d)", e.stackLen, e.limit)
 }
 
+// ErrReturnDataLimit wraps vmerrs.ErrReturnDataLimitExceeded when a call
+// returns more data than Config.ReturnDataLimit allows.
+type ErrReturnDataLimit struct {
+	Size  uint64
+	Limit uint64
+}
+
+func (e *ErrReturnDataLimit) Error() string {
+	return fmt.Sprintf("%v: %d > %d", vmerrs.ErrReturnDataLimitExceeded, e.Size, e.Limit)
+}
+
+func (e *ErrReturnDataLimit) Unwrap() error { return vmerrs.ErrReturnDataLimitExceeded }
+
 // ErrInvalidOpCode wraps an delta error when an invalid opcode is encountered.
 type ErrInvalidOpCode struct {
 	opcode OpCode
@@ -237,6 +237,17 @@ func (delta *DELTA) SetBlockContext(blockCtx BlockContext) {
 	delta.chainRules = delta.chainConfig.OdysseyRules(num, blockCtx.Time)
 }
 
+// limitReturnData replaces the result of the outermost call with an
+// ErrReturnDataLimit error if [ret] exceeds the configured ReturnDataLimit.
+// Nested calls are not limited, so that contracts observe the same return data
+// as without a limit.
+func (delta *DELTA) limitReturnData(ret []byte, err error) ([]byte, error) {
+	if limit := delta.Config.ReturnDataLimit; limit != 0 && delta.depth == 0 && uint64(len(ret)) > limit {
+		return nil, &ErrReturnDataLimit{Size: uint64(len(ret)), Limit: limit}
+	}
+	return ret, err
+}
+
 // Call executes the contract associated with the addr with the given input as
 // parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
@@ -309,6 +320,7 @@ func (delta *DELTA) Call(caller ContractRef, addr common.Address, input []byte,
 			gas = contract.Gas
 		}
 	}
+	ret, err = delta.limitReturnData(ret, err)
 	// When an error was returned by the DELTA or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in homestead this also counts for code storage gas errors.
@@ -387,6 +399,7 @@ func (delta *DELTA) CallExpert(caller ContractRef, addr common.Address, input []
 		gas = contract.Gas
 	}
 	//}
+	ret, err = delta.limitReturnData(ret, err)
 	// When an error was returned by the DELTA or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in homestead this also counts for code storage gas errors.
@@ -446,6 +459,7 @@ func (delta *DELTA) CallCode(caller ContractRef, addr common.Address, input []by
 		ret, err = delta.interpreter.Run(contract, input, false)
 		gas = contract.Gas
 	}
+	ret, err = delta.limitReturnData(ret, err)
 	if err != nil {
 		delta.StateDB.RevertToSnapshot(snapshot)
 		if err != vmerrs.ErrExecutionReverted {
@@ -490,6 +504,7 @@ func (delta *DELTA) DelegateCall(caller ContractRef, addr common.Address, input
 		ret, err = delta.interpreter.Run(contract, input, false)
 		gas = contract.Gas
 	}
+	ret, err = delta.limitReturnData(ret, err)
 	if err != nil {
 		delta.StateDB.RevertToSnapshot(snapshot)
 		if err != vmerrs.ErrExecutionReverted {
@@ -546,6 +561,7 @@ func (delta *DELTA) StaticCall(caller ContractRef, addr common.Address, input []
 		ret, err = delta.interpreter.Run(contract, input, true)
 		gas = contract.Gas
 	}
+	ret, err = delta.limitReturnData(ret, err)
 	if err != nil {
 		delta.StateDB.RevertToSnapshot(snapshot)
 		if err != vmerrs.ErrExecutionReverted {
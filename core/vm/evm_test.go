@@ -6,6 +6,8 @@ package vm
 import (
 	"testing"
 
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/precompile"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,3 +24,21 @@ func TestIsProhibited(t *testing.T) {
 	assert.False(t, IsProhibited(common.HexToAddress("0x0100000000000000000000000000000000000100")))
 	assert.False(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000000")))
 }
+
+func TestActivePrecompilesIncludesConfigured(t *testing.T) {
+	rules := params.TestChainConfig.OdysseyRules(common.Big0, 0)
+	native := ActivePrecompiles(rules)
+	assert.Equal(t, PrecompiledAddressesBanff, native)
+
+	addrs := []common.Address{
+		common.HexToAddress("0x0100000000000000000000000000000000000011"),
+		common.HexToAddress("0x0100000000000000000000000000000000000010"),
+	}
+	rules.Precompiles = map[common.Address]precompile.StatefulPrecompiledContract{
+		addrs[0]: nil,
+		addrs[1]: nil,
+	}
+	active := ActivePrecompiles(rules)
+	assert.Equal(t, native, active[:len(native)])
+	assert.Equal(t, []common.Address{addrs[1], addrs[0]}, active[len(native):])
+}
@@ -50,6 +50,18 @@ type Config struct {
 
 	// AllowUnfinalizedQueries allow unfinalized queries
 	AllowUnfinalizedQueries bool
+
+	// StepLimit caps the number of opcodes a single Run call may execute.
+	// Nested calls count their opcodes separately. Zero means no limit.
+	StepLimit uint64
+
+	// ReturnDataLimit caps the size of the data returned by each call, so that
+	// read-only RPC calls cannot exhaust the memory of the node. Zero means no
+	// limit, which must be used for consensus execution.
+	ReturnDataLimit uint64
+
+	// OpcodeStats, if non-nil, accumulates the executions of each opcode.
+	OpcodeStats *OpcodeStats
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -101,10 +113,17 @@ func NewDELTAInterpreter(delta *DELTA) *DELTAInterpreter {
 		table = &frontierInstructionSet
 	}
 	var extraEips []int
-	if len(delta.Config.ExtraEips) > 0 {
+	if len(delta.chainRules.ExtraEIPs) > 0 || len(delta.Config.ExtraEips) > 0 {
 		// Deep-copy jumptable to prevent modification of opcodes in other tables
 		table = copyJumpTable(table)
 	}
+	// The EIPs scheduled by the chain config are enabled before the EIPs
+	// enabled by the node config.
+	for _, eip := range delta.chainRules.ExtraEIPs {
+		if err := EnableEIP(eip, table); err != nil {
+			log.Error("EIP activation failed", "eip", eip, "error", err)
+		}
+	}
 	for _, eip := range delta.Config.ExtraEips {
 		if err := EnableEIP(eip, table); err != nil {
 			// Disable it, so caller can check if it's activated or not
@@ -178,6 +197,8 @@ func (in *DELTAInterpreter) Run(contract *Contract, input []byte, readOnly bool)
 		logged  bool   // deferred DELTALogger should ignore already logged steps
 		res     []byte // result of the opcode execution function
 		debug   = in.delta.Config.Tracer != nil
+		steps   uint64 // number of opcodes executed by this call
+		stats   = in.delta.Config.OpcodeStats
 	)
 
 	// Don't move this deferred function, it's placed before the capturestate-deferred method,
@@ -208,6 +229,12 @@ func (in *DELTAInterpreter) Run(contract *Contract, input []byte, readOnly bool)
 			// Capture pre-execution values for tracing.
 			logged, pcCopy, gasCopy = false, pc, contract.Gas
 		}
+		if limit := in.delta.Config.StepLimit; limit != 0 {
+			if steps >= limit {
+				return nil, vmerrs.ErrStepLimitExceeded
+			}
+			steps++
+		}
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(pc)
@@ -262,6 +289,10 @@ func (in *DELTAInterpreter) Run(contract *Contract, input []byte, readOnly bool)
 			logged = true
 		}
 
+		if stats != nil {
+			stats.record(op, cost)
+		}
+
 		// execute the operation
 		res, err = operation.execute(&pc, in, callContext)
 		if err != nil {
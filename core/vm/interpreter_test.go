@@ -27,6 +27,7 @@
 package vm
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -35,6 +36,7 @@ import (
 	"github.com/DioneProtocol/coreth/core/state"
 	"github.com/DioneProtocol/coreth/core/types"
 	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 )
@@ -85,3 +87,215 @@ func TestLoopInterrupt(t *testing.T) {
 		}
 	}
 }
+
+func TestStepLimit(t *testing.T) {
+	address := common.BytesToAddress([]byte("contract"))
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}
+
+	for i, tt := range loopInterruptTests {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, common.Hex2Bytes(tt))
+		statedb.Finalise(true)
+
+		delta := NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{StepLimit: 1000})
+		_, _, err := delta.Call(AccountRef(common.Address{}), address, nil, math.MaxUint64, new(big.Int))
+		if !errors.Is(err, vmerrs.ErrStepLimitExceeded) {
+			t.Errorf("test %d: expected %v, got %v", i, vmerrs.ErrStepLimitExceeded, err)
+		}
+
+		delta = NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{})
+		_, _, err = delta.Call(AccountRef(common.Address{}), address, nil, 1_000_000, new(big.Int))
+		if !errors.Is(err, vmerrs.ErrOutOfGas) {
+			t.Errorf("test %d: expected %v without step limit, got %v", i, vmerrs.ErrOutOfGas, err)
+		}
+	}
+}
+
+func TestStepLimitPerCall(t *testing.T) {
+	var (
+		outer = common.BytesToAddress([]byte("outer"))
+		inner = common.BytesToAddress([]byte("inner"))
+		vmctx = BlockContext{
+			BlockNumber: big.NewInt(0),
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	// 15 x JUMPDEST, STOP: 16 steps
+	statedb.SetCode(inner, common.Hex2Bytes("5b5b5b5b5b5b5b5b5b5b5b5b5b5b5b00"))
+	// CALL(gas, inner, 0, 0, 0, 0, 0) and return the success flag: 13 steps
+	statedb.SetCode(outer, append(append(
+		common.Hex2Bytes("60006000600060006000"+"73"),
+		inner.Bytes()...),
+		common.Hex2Bytes("5af160005260206000f3")...,
+	))
+	statedb.Finalise(true)
+
+	// Each frame stays within the limit even though together they exceed it.
+	delta := NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{StepLimit: 16})
+	ret, _, err := delta.Call(AccountRef(common.Address{}), outer, nil, 1_000_000, new(big.Int))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if new(big.Int).SetBytes(ret).Uint64() != 1 {
+		t.Fatalf("expected inner call to succeed, returned %x", ret)
+	}
+
+	// The inner frame exceeds the limit, so the call reports failure.
+	delta = NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{StepLimit: 14})
+	ret, _, err = delta.Call(AccountRef(common.Address{}), outer, nil, 1_000_000, new(big.Int))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if new(big.Int).SetBytes(ret).Uint64() != 0 {
+		t.Fatalf("expected inner call to fail, returned %x", ret)
+	}
+
+	// The outer frame exceeds the limit.
+	delta = NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{StepLimit: 10})
+	_, _, err = delta.Call(AccountRef(common.Address{}), outer, nil, 1_000_000, new(big.Int))
+	if !errors.Is(err, vmerrs.ErrStepLimitExceeded) {
+		t.Fatalf("expected %v, got %v", vmerrs.ErrStepLimitExceeded, err)
+	}
+}
+
+func TestReturnDataLimit(t *testing.T) {
+	var (
+		address = common.BytesToAddress([]byte("contract"))
+		caller  = AccountRef(common.Address{})
+		vmctx   = BlockContext{
+			BlockNumber: big.NewInt(0),
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	// RETURN(0, CALLDATALOAD(0))
+	statedb.SetCode(address, common.Hex2Bytes("6000356000f3"))
+	statedb.Finalise(true)
+
+	calls := map[string]func(delta *DELTA, input []byte) ([]byte, error){
+		"call": func(delta *DELTA, input []byte) ([]byte, error) {
+			ret, _, err := delta.Call(caller, address, input, 1_000_000, new(big.Int))
+			return ret, err
+		},
+		"callcode": func(delta *DELTA, input []byte) ([]byte, error) {
+			ret, _, err := delta.CallCode(caller, address, input, 1_000_000, new(big.Int))
+			return ret, err
+		},
+		"delegatecall": func(delta *DELTA, input []byte) ([]byte, error) {
+			ret, _, err := delta.DelegateCall(NewContract(caller, caller, new(big.Int), 0), address, input, 1_000_000)
+			return ret, err
+		},
+		"staticcall": func(delta *DELTA, input []byte) ([]byte, error) {
+			ret, _, err := delta.StaticCall(caller, address, input, 1_000_000)
+			return ret, err
+		},
+	}
+	for name, call := range calls {
+		delta := NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{ReturnDataLimit: 64})
+		ret, err := call(delta, common.BigToHash(big.NewInt(64)).Bytes())
+		if err != nil || len(ret) != 64 {
+			t.Errorf("%s: expected 64 bytes at the limit, got %d bytes and %v", name, len(ret), err)
+		}
+		ret, err = call(delta, common.BigToHash(big.NewInt(65)).Bytes())
+		var limitErr *ErrReturnDataLimit
+		if !errors.Is(err, vmerrs.ErrReturnDataLimitExceeded) || !errors.As(err, &limitErr) || limitErr.Size != 65 || limitErr.Limit != 64 || ret != nil {
+			t.Errorf("%s: expected %v above the limit, got %x and %v", name, vmerrs.ErrReturnDataLimitExceeded, ret, err)
+		}
+
+		delta = NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{})
+		ret, err = call(delta, common.BigToHash(big.NewInt(65)).Bytes())
+		if err != nil || len(ret) != 65 {
+			t.Errorf("%s: expected 65 bytes without limit, got %d bytes and %v", name, len(ret), err)
+		}
+	}
+}
+
+func TestReturnDataLimitNested(t *testing.T) {
+	var (
+		inner  = common.BytesToAddress([]byte("inner"))
+		outer  = common.BytesToAddress([]byte("outer"))
+		caller = AccountRef(common.Address{})
+		vmctx  = BlockContext{
+			BlockNumber: big.NewInt(0),
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	// RETURN(0, CALLDATALOAD(0))
+	statedb.SetCode(inner, common.Hex2Bytes("6000356000f3"))
+	// MSTORE(0, 65), CALL(GAS, inner, 0, 0, 32, 0, 0), MSTORE(0, RETURNDATASIZE), RETURN(0, 32)
+	code := []byte{
+		byte(PUSH1), 65, byte(PUSH1), 0, byte(MSTORE),
+		byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 32, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH20),
+	}
+	code = append(code, inner.Bytes()...)
+	code = append(code,
+		byte(GAS), byte(CALL), byte(POP),
+		byte(RETURNDATASIZE), byte(PUSH1), 0, byte(MSTORE),
+		byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN),
+	)
+	statedb.SetCode(outer, code)
+	statedb.Finalise(true)
+
+	// The inner call returns more than the limit to the outer contract, which
+	// only returns its size.
+	delta := NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{ReturnDataLimit: 64})
+	ret, _, err := delta.Call(caller, outer, nil, 1_000_000, new(big.Int))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size := new(big.Int).SetBytes(ret); size.Cmp(big.NewInt(65)) != 0 {
+		t.Fatalf("expected the nested call to return 65 bytes, got %v", size)
+	}
+}
+
+func TestChainConfigExtraEIPs(t *testing.T) {
+	address := common.BytesToAddress([]byte("contract"))
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(address)
+	// PUSH0 STOP
+	statedb.SetCode(address, []byte{byte(PUSH0), byte(STOP)})
+	statedb.Finalise(true)
+
+	// PUSH0 is only enabled by DUpgrade, so it is scheduled separately here.
+	chainConfig := *params.TestCortinaChainConfig
+	chainConfig.ExtraEIPs = []params.ExtraEIP{{EIP: 3855, Timestamp: 10}}
+
+	call := func(timestamp uint64, config Config) error {
+		vmctx := BlockContext{
+			BlockNumber: common.Big1,
+			Time:        timestamp,
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+		delta := NewDELTA(vmctx, TxContext{}, statedb, &chainConfig, config)
+		_, _, err := delta.Call(AccountRef(common.Address{}), address, nil, 100_000, new(big.Int))
+		return err
+	}
+
+	var invalidOpCode *ErrInvalidOpCode
+	if err := call(9, Config{}); !errors.As(err, &invalidOpCode) || invalidOpCode.opcode != PUSH0 {
+		t.Fatalf("expected invalid opcode PUSH0 before activation, got %v", err)
+	}
+	if err := call(10, Config{}); err != nil {
+		t.Fatalf("unexpected error at activation: %v", err)
+	}
+	// The EIPs enabled by the node config are applied on top of the EIPs
+	// scheduled by the chain config.
+	if err := call(10, Config{ExtraEips: []int{3855}}); err != nil {
+		t.Fatalf("unexpected error with the eip also enabled by the node config: %v", err)
+	}
+
+	// The jump table of the fork is not modified by the activation.
+	if call(9, Config{}) == nil {
+		t.Fatal("expected PUSH0 to remain invalid before activation")
+	}
+}
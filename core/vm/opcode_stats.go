@@ -0,0 +1,29 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+// OpcodeStats accumulates the number of times each opcode is executed and the
+// gas charged for it, indexed by opcode. The gas of an opcode includes its
+// dynamic gas, so the gas of CALL-like opcodes includes the gas they forward
+// to the callee, which is also charged to the opcodes of the callee.
+//
+// OpcodeStats is not safe for concurrent use.
+type OpcodeStats struct {
+	Counts [256]uint64
+	Gas    [256]uint64
+}
+
+// record adds an execution of [op] charged [gas] to [s].
+func (s *OpcodeStats) record(op OpCode, gas uint64) {
+	s.Counts[op]++
+	s.Gas[op] += gas
+}
+
+// Add adds the executions recorded by [other] to [s].
+func (s *OpcodeStats) Add(other *OpcodeStats) {
+	for i := range s.Counts {
+		s.Counts[i] += other.Counts[i]
+		s.Gas[i] += other.Gas[i]
+	}
+}
@@ -0,0 +1,126 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/coreth/core/rawdb"
+	"github.com/DioneProtocol/coreth/core/state"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newOpcodeStatsDELTA returns a DELTA with [code] deployed at the returned
+// address, recording opcode executions into [stats].
+func newOpcodeStatsDELTA(t testing.TB, code string, stats *OpcodeStats) (*DELTA, common.Address) {
+	address := common.BytesToAddress([]byte("contract"))
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, common.Hex2Bytes(code))
+	statedb.Finalise(true)
+
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}
+	return NewDELTA(vmctx, TxContext{}, statedb, params.TestChainConfig, Config{OpcodeStats: stats}), address
+}
+
+func TestOpcodeStats(t *testing.T) {
+	stats := &OpcodeStats{}
+	// push1 1 push1 2 add stop
+	delta, address := newOpcodeStatsDELTA(t, "600160020100", stats)
+	for i := 0; i < 2; i++ {
+		if _, _, err := delta.Call(AccountRef(common.Address{}), address, nil, 100_000, new(big.Int)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[OpCode][2]uint64{
+		PUSH1: {4, 4 * GasFastestStep},
+		ADD:   {2, 2 * GasFastestStep},
+		STOP:  {2, 0},
+	}
+	for op := range stats.Counts {
+		want := expected[OpCode(op)]
+		if have := [2]uint64{stats.Counts[op], stats.Gas[op]}; have != want {
+			t.Errorf("%s: expected (count, gas) %v, have %v", OpCode(op), want, have)
+		}
+	}
+}
+
+// An opcode that runs out of gas is not recorded.
+func TestOpcodeStatsOutOfGas(t *testing.T) {
+	stats := &OpcodeStats{}
+	delta, address := newOpcodeStatsDELTA(t, "600160020100", stats)
+	if _, _, err := delta.Call(AccountRef(common.Address{}), address, nil, 2*GasFastestStep+1, new(big.Int)); err == nil {
+		t.Fatal("expected out of gas")
+	}
+	if stats.Counts[PUSH1] != 2 || stats.Counts[ADD] != 0 {
+		t.Errorf("expected 2 PUSH1 and no ADD, have %d and %d", stats.Counts[PUSH1], stats.Counts[ADD])
+	}
+}
+
+func TestOpcodeStatsAdd(t *testing.T) {
+	a, b := &OpcodeStats{}, &OpcodeStats{}
+	a.record(PUSH1, 3)
+	a.record(SSTORE, 20_000)
+	b.record(PUSH1, 3)
+	b.record(CALL, 700)
+
+	a.Add(b)
+	if a.Counts[PUSH1] != 2 || a.Gas[PUSH1] != 6 {
+		t.Errorf("PUSH1: expected (2, 6), have (%d, %d)", a.Counts[PUSH1], a.Gas[PUSH1])
+	}
+	if a.Counts[SSTORE] != 1 || a.Gas[SSTORE] != 20_000 {
+		t.Errorf("SSTORE: expected (1, 20000), have (%d, %d)", a.Counts[SSTORE], a.Gas[SSTORE])
+	}
+	if a.Counts[CALL] != 1 || a.Gas[CALL] != 700 {
+		t.Errorf("CALL: expected (1, 700), have (%d, %d)", a.Counts[CALL], a.Gas[CALL])
+	}
+	// [b] is unchanged.
+	if b.Counts[PUSH1] != 1 || b.Counts[SSTORE] != 0 {
+		t.Errorf("expected the added stats to be unchanged, have %v", b.Counts)
+	}
+}
+
+// Recording opcode stats must not allocate.
+func TestOpcodeStatsAllocs(t *testing.T) {
+	run := func(stats *OpcodeStats) float64 {
+		delta, address := newOpcodeStatsDELTA(t, loopInterruptTests[0], stats)
+		return testing.AllocsPerRun(10, func() {
+			_, _, _ = delta.Call(AccountRef(common.Address{}), address, nil, 1_000_000, new(big.Int))
+		})
+	}
+	if disabled, enabled := run(nil), run(&OpcodeStats{}); enabled > disabled {
+		t.Errorf("expected no allocations from recording opcode stats, have %v with and %v without", enabled, disabled)
+	}
+}
+
+// BenchmarkOpcodeStats compares the interpreter loop with and without opcode
+// stats, running an infinite loop until it runs out of gas.
+func BenchmarkOpcodeStats(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		stats *OpcodeStats
+	}{
+		{name: "disabled"},
+		{name: "enabled", stats: &OpcodeStats{}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			delta, address := newOpcodeStatsDELTA(b, loopInterruptTests[0], bench.stats)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _, _ = delta.Call(AccountRef(common.Address{}), address, nil, 1_000_000, new(big.Int))
+			}
+		})
+	}
+}
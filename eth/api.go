@@ -40,6 +40,7 @@ import (
 	"github.com/DioneProtocol/coreth/core/rawdb"
 	"github.com/DioneProtocol/coreth/core/state"
 	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/core/vm"
 	"github.com/DioneProtocol/coreth/internal/ethapi"
 	"github.com/DioneProtocol/coreth/rpc"
 	"github.com/DioneProtocol/coreth/trie"
@@ -475,3 +476,76 @@ func (api *DebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error
 	}
 	return 0, errors.New("no state found")
 }
+
+// OpcodeStat is the number of times an opcode was executed and the gas charged
+// for it.
+type OpcodeStat struct {
+	Count hexutil.Uint64 `json:"count"`
+	Gas   hexutil.Uint64 `json:"gas"`
+}
+
+// OpcodeStatsResult is the result of a debug_opcodeStats call.
+type OpcodeStatsResult struct {
+	// Blocks is the number of blocks in the range with recorded stats.
+	Blocks  hexutil.Uint64        `json:"blocks"`
+	Opcodes map[string]OpcodeStat `json:"opcodes"`
+}
+
+// OpcodeStats returns the per-opcode execution counts and gas summed over the
+// canonical blocks in the inclusive range [from, to] that were processed by
+// this node since the stats were last reset. Opcode stats must be enabled in
+// the node config.
+func (api *DebugAPI) OpcodeStats(from, to rpc.BlockNumber) (*OpcodeStatsResult, error) {
+	chain := api.eth.BlockChain()
+	if !chain.OpcodeStatsEnabled() {
+		return nil, errors.New("opcode stats are not enabled")
+	}
+	var resolveNum = func(num rpc.BlockNumber) uint64 {
+		if num.Int64() < 0 {
+			return chain.CurrentBlock().Number.Uint64()
+		}
+		return uint64(num.Int64())
+	}
+	start, end := resolveNum(from), resolveNum(to)
+	if start > end {
+		return nil, fmt.Errorf("from block %d is after to block %d", start, end)
+	}
+	if limit := uint64(api.eth.config.OpcodeStatsBlocks); end-start >= limit {
+		return nil, fmt.Errorf("block range %d-%d exceeds the %d blocks with retained opcode stats", start, end, limit)
+	}
+
+	var (
+		total  vm.OpcodeStats
+		blocks uint64
+	)
+	for i := start; i <= end; i++ {
+		h := chain.GetHeaderByNumber(i)
+		if h == nil {
+			break
+		}
+		if stats, ok := chain.GetOpcodeStats(h.Hash()); ok {
+			total.Add(stats)
+			blocks++
+		}
+	}
+
+	result := &OpcodeStatsResult{
+		Blocks:  hexutil.Uint64(blocks),
+		Opcodes: make(map[string]OpcodeStat),
+	}
+	for op, count := range total.Counts {
+		if count == 0 {
+			continue
+		}
+		result.Opcodes[vm.OpCode(op).String()] = OpcodeStat{
+			Count: hexutil.Uint64(count),
+			Gas:   hexutil.Uint64(total.Gas[op]),
+		}
+	}
+	return result, nil
+}
+
+// ResetOpcodeStats discards the opcode stats recorded so far.
+func (api *DebugAPI) ResetOpcodeStats() {
+	api.eth.BlockChain().ResetOpcodeStats()
+}
@@ -206,6 +206,8 @@ func New(
 			Preimages:                       config.Preimages,
 			AcceptedCacheSize:               config.AcceptedCacheSize,
 			TxLookupLimit:                   config.TxLookupLimit,
+			OpcodeStatsBlocks:               config.OpcodeStatsBlocks,
+			UpgradeCheckOverride:            config.UpgradeCheckOverride,
 		}
 	)
 
@@ -33,6 +33,7 @@ import (
 	"github.com/DioneProtocol/coreth/core/txpool"
 	"github.com/DioneProtocol/coreth/eth/gasprice"
 	"github.com/DioneProtocol/coreth/miner"
+	"github.com/DioneProtocol/coreth/params"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -65,6 +66,7 @@ func NewDefaultConfig() Config {
 		RPCDELTATimeout:       5 * time.Second,
 		GPO:                   DefaultFullGPOConfig,
 		RPCTxFeeCap:           1, // 1 DIONE
+		RPCReturnDataLimit:    25 * 1024 * 1024,
 	}
 }
 
@@ -128,6 +130,10 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64 `toml:",omitempty"`
 
+	// RPCReturnDataLimit is the global cap on the data returned by each call
+	// of eth-call variants, and on the return data captured by tracers.
+	RPCReturnDataLimit uint64 `toml:",omitempty"`
+
 	// AllowUnfinalizedQueries allow unfinalized queries
 	AllowUnfinalizedQueries bool
 
@@ -151,9 +157,17 @@ type Config struct {
 	// identical state with the pre-upgrade ruleset.
 	SkipUpgradeCheck bool
 
+	// UpgradeCheckOverride, if non-nil, allows changes to the upgrades scheduled
+	// at or after its thresholds even if the last accepted block is past them.
+	UpgradeCheckOverride *params.UpgradeCheckOverride
+
 	// TxLookupLimit is the maximum number of blocks from head whose tx indices
 	// are reserved:
 	//  * 0:   means no limit
 	//  * N:   means N block limit [HEAD-N+1, HEAD] and delete extra indexes
 	TxLookupLimit uint64
+
+	// OpcodeStatsBlocks is the number of recently processed blocks for which
+	// per-opcode execution counts and gas are retained. Zero disables them.
+	OpcodeStatsBlocks int
 }
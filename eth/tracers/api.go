@@ -93,6 +93,7 @@ type Backend interface {
 	BadBlocks() ([]*types.Block, []*core.BadBlockReason)
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error)
 	RPCGasCap() uint64
+	RPCReturnDataLimit() uint64
 	ChainConfig() *params.ChainConfig
 	Engine() consensus.Engine
 	ChainDb() ethdb.Database
@@ -308,7 +309,7 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 						TxIndex:     i,
 						TxHash:      tx.Hash(),
 					}
-					res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
+					res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config, false)
 					if err != nil {
 						task.results[i] = &txTraceResult{TxHash: tx.Hash(), Error: err.Error()}
 						log.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(), "err", err)
@@ -659,7 +660,7 @@ func (api *baseAPI) traceBlock(ctx context.Context, block *types.Block, config *
 			TxIndex:     i,
 			TxHash:      tx.Hash(),
 		}
-		res, err := api.traceTx(ctx, msg, txctx, blockCtx, statedb, config)
+		res, err := api.traceTx(ctx, msg, txctx, blockCtx, statedb, config, false)
 		if err != nil {
 			return nil, err
 		}
@@ -702,7 +703,7 @@ func (api *baseAPI) traceBlockParallel(ctx context.Context, block *types.Block,
 					TxIndex:     task.index,
 					TxHash:      txs[task.index].Hash(),
 				}
-				res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
+				res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config, false)
 				if err != nil {
 					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
 					continue
@@ -905,7 +906,7 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 		TxIndex:     int(index),
 		TxHash:      hash,
 	}
-	return api.traceTx(ctx, msg, txctx, vmctx, statedb, config)
+	return api.traceTx(ctx, msg, txctx, vmctx, statedb, config, false)
 }
 
 // TraceCall lets you trace a given eth_call. It collects the structured logs
@@ -964,31 +965,46 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	if config != nil {
 		traceConfig = &config.TraceConfig
 	}
-	return api.traceTx(ctx, msg, new(Context), vmctx, statedb, traceConfig)
+	return api.traceTx(ctx, msg, new(Context), vmctx, statedb, traceConfig, true)
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
-// be tracer dependent.
-func (api *baseAPI) traceTx(ctx context.Context, message *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+// be tracer dependent. The tracer truncates the return data it captures to the
+// RPC return data limit. If [limitReturnData] is set, execution also fails
+// once a call returns more data than the limit, which must only be used for
+// calls that are not part of the chain.
+func (api *baseAPI) traceTx(ctx context.Context, message *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig, limitReturnData bool) (interface{}, error) {
 	var (
-		tracer    Tracer
-		err       error
-		timeout   = defaultTraceTimeout
-		txContext = core.NewDELTATxContext(message)
+		tracer          Tracer
+		err             error
+		timeout         = defaultTraceTimeout
+		txContext       = core.NewDELTATxContext(message)
+		returnDataLimit = api.backend.RPCReturnDataLimit()
+		vmConfig        = vm.Config{NoBaseFee: true}
 	)
 	if config == nil {
 		config = &TraceConfig{}
 	}
+	txctx.ReturnDataLimit = returnDataLimit
+	if limitReturnData {
+		vmConfig.ReturnDataLimit = returnDataLimit
+	}
 	// Default tracer is the struct logger
-	tracer = logger.NewStructLogger(config.Config)
+	var logConfig logger.Config
+	if config.Config != nil {
+		logConfig = *config.Config
+	}
+	logConfig.ReturnDataLimit = returnDataLimit
+	tracer = logger.NewStructLogger(&logConfig)
 	if config.Tracer != nil {
 		tracer, err = DefaultDirectory.New(*config.Tracer, txctx, config.TracerConfig)
 		if err != nil {
 			return nil, err
 		}
 	}
-	vmenv := vm.NewDELTA(vmctx, txContext, statedb, api.backend.ChainConfig(), vm.Config{Tracer: tracer, NoBaseFee: true})
+	vmConfig.Tracer = tracer
+	vmenv := vm.NewDELTA(vmctx, txContext, statedb, api.backend.ChainConfig(), vmConfig)
 
 	// Define a meaningful timeout of a single transaction trace
 	if config.Timeout != nil {
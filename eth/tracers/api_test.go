@@ -67,6 +67,8 @@ type testBackend struct {
 	chaindb     ethdb.Database
 	chain       *core.BlockChain
 
+	returnDataLimit uint64
+
 	refHook func() // Hook is invoked when the requested state is referenced
 	relHook func() // Hook is invoked when the requested state is released
 }
@@ -142,6 +144,10 @@ func (b *testBackend) RPCGasCap() uint64 {
 	return 25000000
 }
 
+func (b *testBackend) RPCReturnDataLimit() uint64 {
+	return b.returnDataLimit
+}
+
 func (b *testBackend) ChainConfig() *params.ChainConfig {
 	return b.chainConfig
 }
@@ -388,6 +394,97 @@ func TestTraceTransaction(t *testing.T) {
 	}
 }
 
+// returnDataCode returns as many zero bytes as the first word of its calldata.
+var returnDataCode = []byte{
+	byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD),
+	byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+}
+
+func TestTraceCallReturnDataLimit(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(1)
+	contract := common.Address{0xc0, 0xde}
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			contract:         {Code: returnDataCode, Balance: new(big.Int)},
+		},
+	}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {})
+	defer backend.teardown()
+	backend.returnDataLimit = 64
+	api := NewAPI(backend)
+
+	for _, test := range []struct {
+		size    int64
+		failed  bool
+		outSize int
+	}{
+		{size: 64, outSize: 64},
+		// Exceeding the limit fails the call instead of truncating its output.
+		{size: 65, failed: true},
+	} {
+		input := hexutil.Bytes(common.BigToHash(big.NewInt(test.size)).Bytes())
+		latest := rpc.LatestBlockNumber
+		result, err := api.TraceCall(context.Background(), ethapi.TransactionArgs{
+			From:  &accounts[0].addr,
+			To:    &contract,
+			Input: &input,
+		}, rpc.BlockNumberOrHash{BlockNumber: &latest}, nil)
+		if err != nil {
+			t.Fatalf("size %d: failed to trace call: %v", test.size, err)
+		}
+		var have logger.ExecutionResult
+		if err := json.Unmarshal(result.(json.RawMessage), &have); err != nil {
+			t.Fatalf("size %d: failed to unmarshal result: %v", test.size, err)
+		}
+		if have.Failed != test.failed || len(have.ReturnValue) != 2*test.outSize || have.ReturnValueTruncated {
+			t.Errorf("size %d: unexpected result %s", test.size, result)
+		}
+	}
+}
+
+// Transactions of the chain are not limited when they are replayed, but the
+// return data captured by the tracer is truncated.
+func TestTraceTransactionReturnDataLimit(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(1)
+	contract := common.Address{0xc0, 0xde}
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))},
+			contract:         {Code: returnDataCode, Balance: new(big.Int)},
+		},
+	}
+	target := common.Hash{}
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		input := common.BigToHash(big.NewInt(65)).Bytes()
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), contract, big.NewInt(0), 100000, b.BaseFee(), input), signer, accounts[0].key)
+		b.AddTx(tx)
+		target = tx.Hash()
+	})
+	defer backend.teardown()
+	backend.returnDataLimit = 64
+	api := NewAPI(backend)
+
+	result, err := api.TraceTransaction(context.Background(), target, nil)
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	var have logger.ExecutionResult
+	if err := json.Unmarshal(result.(json.RawMessage), &have); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	if have.Failed || len(have.ReturnValue) != 2*64 || !have.ReturnValueTruncated {
+		t.Errorf("unexpected result %s", result)
+	}
+}
+
 func TestTraceBlock(t *testing.T) {
 	t.Parallel()
 
@@ -387,3 +387,83 @@ func TestInternals(t *testing.T) {
 		}
 	}
 }
+
+// TestCallTracerReturnDataLimit tests that the call tracer truncates the output
+// of each frame to the return data limit of its context.
+func TestCallTracerReturnDataLimit(t *testing.T) {
+	var (
+		to     = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		callee = common.HexToAddress("0x00000000000000000000000000000000cafebabe")
+		origin = common.HexToAddress("0x00000000000000000000000000000000feed")
+		// Returns 32 bytes.
+		calleeCode = []byte{
+			byte(vm.PUSH1), 0x20,
+			byte(vm.PUSH1), 0x0,
+			byte(vm.RETURN),
+		}
+		// Calls [callee] and returns its output.
+		code = []byte{
+			byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x0, // out size and offset
+			byte(vm.PUSH1), 0x0, byte(vm.DUP1), // in size and offset
+			byte(vm.PUSH1), 0x0, // value
+			byte(vm.PUSH4), 0xca, 0xfe, 0xba, 0xbe, byte(vm.GAS),
+			byte(vm.CALL),
+			byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x0,
+			byte(vm.RETURN),
+		}
+		context = vm.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			BlockNumber: big.NewInt(1),
+			Time:        5,
+			Difficulty:  big.NewInt(0x30000),
+			GasLimit:    uint64(6000000),
+			BaseFee:     big.NewInt(0),
+		}
+	)
+	tracer, err := tracers.DefaultDirectory.New("callTracer", &tracers.Context{ReturnDataLimit: 4}, nil)
+	if err != nil {
+		t.Fatalf("failed to create call tracer: %v", err)
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(),
+		core.GenesisAlloc{
+			to:     core.GenesisAccount{Code: code},
+			callee: core.GenesisAccount{Code: calleeCode},
+			origin: core.GenesisAccount{Balance: big.NewInt(500000000000000)},
+		}, false)
+	delta := vm.NewDELTA(context, vm.TxContext{Origin: origin, GasPrice: big.NewInt(0)}, statedb, params.TestChainConfig, vm.Config{Tracer: tracer})
+	msg := &core.Message{
+		To:        &to,
+		From:      origin,
+		Value:     big.NewInt(0),
+		GasLimit:  50000,
+		GasPrice:  big.NewInt(0),
+		GasFeeCap: big.NewInt(0),
+		GasTipCap: big.NewInt(0),
+	}
+	st := core.NewStateTransition(delta, msg, new(core.GasPool).AddGas(msg.GasLimit))
+	if _, err := st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	var frame struct {
+		Output          hexutil.Bytes `json:"output"`
+		OutputTruncated bool          `json:"outputTruncated"`
+		Calls           []struct {
+			Output          hexutil.Bytes `json:"output"`
+			OutputTruncated bool          `json:"outputTruncated"`
+		} `json:"calls"`
+	}
+	if err := json.Unmarshal(res, &frame); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if len(frame.Output) != 4 || !frame.OutputTruncated {
+		t.Errorf("top call output not truncated: %s", res)
+	}
+	if len(frame.Calls) != 1 || len(frame.Calls[0].Output) != 4 || !frame.Calls[0].OutputTruncated {
+		t.Errorf("inner call output not truncated: %s", res)
+	}
+}
@@ -55,6 +55,9 @@ type Config struct {
 	EnableReturnData bool // enable return data capture
 	Debug            bool // print output during capture end
 	Limit            int  // maximum length of output, but zero means unlimited
+	// ReturnDataLimit truncates the captured return data to the given size,
+	// zero means unlimited. It is set by the node rather than the caller.
+	ReturnDataLimit uint64 `json:"-"`
 	// Chain overrides, can be used to execute a trace using future fork rules
 	Overrides *params.ChainConfig `json:"overrides,omitempty"`
 }
@@ -110,12 +113,13 @@ type StructLogger struct {
 	cfg Config
 	env *vm.DELTA
 
-	storage  map[common.Address]Storage
-	logs     []StructLog
-	output   []byte
-	err      error
-	gasLimit uint64
-	usedGas  uint64
+	storage   map[common.Address]Storage
+	logs      []StructLog
+	output    []byte
+	truncated bool // whether [output] was truncated to cfg.ReturnDataLimit
+	err       error
+	gasLimit  uint64
+	usedGas   uint64
 
 	interrupt atomic.Bool // Atomic flag to signal execution interruption
 	reason    error       // Textual reason for the interruption
@@ -136,6 +140,7 @@ func NewStructLogger(cfg *Config) *StructLogger {
 func (l *StructLogger) Reset() {
 	l.storage = make(map[common.Address]Storage)
 	l.output = make([]byte, 0)
+	l.truncated = false
 	l.logs = l.logs[:0]
 	l.err = nil
 }
@@ -204,6 +209,9 @@ func (l *StructLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, s
 	}
 	var rdata []byte
 	if l.cfg.EnableReturnData {
+		if limit := l.cfg.ReturnDataLimit; limit != 0 && uint64(len(rData)) > limit {
+			rData = rData[:limit]
+		}
 		rdata = make([]byte, len(rData))
 		copy(rdata, rData)
 	}
@@ -219,6 +227,10 @@ func (l *StructLogger) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, s
 
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if limit := l.cfg.ReturnDataLimit; limit != 0 && uint64(len(output)) > limit {
+		output = output[:limit]
+		l.truncated = true
+	}
 	l.output = output
 	l.err = err
 	if l.cfg.Debug {
@@ -248,10 +260,11 @@ func (l *StructLogger) GetResult() (json.RawMessage, error) {
 		returnVal = ""
 	}
 	return json.Marshal(&ExecutionResult{
-		Gas:         l.usedGas,
-		Failed:      failed,
-		ReturnValue: returnVal,
-		StructLogs:  formatLogs(l.StructLogs()),
+		Gas:                  l.usedGas,
+		Failed:               failed,
+		ReturnValue:          returnVal,
+		ReturnValueTruncated: l.truncated && returnVal != "",
+		StructLogs:           formatLogs(l.StructLogs()),
 	})
 }
 
@@ -402,10 +415,11 @@ func (*mdLogger) CaptureTxEnd(restGas uint64) {}
 // while replaying a transaction in debug mode as well as transaction
 // execution status, the amount of gas used and the return value
 type ExecutionResult struct {
-	Gas         uint64         `json:"gas"`
-	Failed      bool           `json:"failed"`
-	ReturnValue string         `json:"returnValue"`
-	StructLogs  []StructLogRes `json:"structLogs"`
+	Gas                  uint64         `json:"gas"`
+	Failed               bool           `json:"failed"`
+	ReturnValue          string         `json:"returnValue"`
+	ReturnValueTruncated bool           `json:"returnValueTruncated,omitempty"`
+	StructLogs           []StructLogRes `json:"structLogs"`
 }
 
 // StructLogRes stores a structured log emitted by the DELTA while replaying a
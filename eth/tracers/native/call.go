@@ -62,8 +62,11 @@ type callFrame struct {
 	Output       []byte          `json:"output,omitempty" rlp:"optional"`
 	Error        string          `json:"error,omitempty" rlp:"optional"`
 	RevertReason string          `json:"revertReason,omitempty"`
-	Calls        []callFrame     `json:"calls,omitempty" rlp:"optional"`
-	Logs         []callLog       `json:"logs,omitempty" rlp:"optional"`
+	// OutputTruncated is set if Output was truncated to the return data
+	// limit of the tracer.
+	OutputTruncated bool        `json:"outputTruncated,omitempty" rlp:"-"`
+	Calls           []callFrame `json:"calls,omitempty" rlp:"optional"`
+	Logs            []callLog   `json:"logs,omitempty" rlp:"optional"`
 	// Placed at end on purpose. The RLP will be decoded to 0 instead of
 	// nil if there are non-empty elements after in the struct.
 	Value *big.Int `json:"value,omitempty" rlp:"optional"`
@@ -77,7 +80,11 @@ func (f callFrame) failed() bool {
 	return len(f.Error) > 0
 }
 
-func (f *callFrame) processOutput(output []byte, err error) {
+func (f *callFrame) processOutput(output []byte, err error, limit uint64) {
+	if limit != 0 && uint64(len(output)) > limit {
+		output = output[:limit]
+		f.OutputTruncated = true
+	}
 	output = common.CopyBytes(output)
 	if err == nil {
 		f.Output = output
@@ -88,6 +95,7 @@ func (f *callFrame) processOutput(output []byte, err error) {
 		f.To = nil
 	}
 	if !errors.Is(err, vmerrs.ErrExecutionReverted) || len(output) == 0 {
+		f.OutputTruncated = false
 		return
 	}
 	f.Output = output
@@ -113,8 +121,10 @@ type callTracer struct {
 	callstack []callFrame
 	config    callTracerConfig
 	gasLimit  uint64
-	interrupt atomic.Bool // Atomic flag to signal execution interruption
-	reason    error       // Textual reason for the interruption
+	// returnDataLimit is the maximum size of the output of each frame.
+	returnDataLimit uint64
+	interrupt       atomic.Bool // Atomic flag to signal execution interruption
+	reason          error       // Textual reason for the interruption
 }
 
 type callTracerConfig struct {
@@ -133,7 +143,11 @@ func newCallTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, e
 	}
 	// First callframe contains tx context info
 	// and is populated on start and end.
-	return &callTracer{callstack: make([]callFrame, 1), config: config}, nil
+	t := &callTracer{callstack: make([]callFrame, 1), config: config}
+	if ctx != nil {
+		t.returnDataLimit = ctx.ReturnDataLimit
+	}
+	return t, nil
 }
 
 // CaptureStart implements the DELTALogger interface to initialize the tracing operation.
@@ -154,7 +168,7 @@ func (t *callTracer) CaptureStart(env *vm.DELTA, from common.Address, to common.
 
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
-	t.callstack[0].processOutput(output, err)
+	t.callstack[0].processOutput(output, err, t.returnDataLimit)
 }
 
 // CaptureState implements the DELTALogger interface to trace a single step of VM execution.
@@ -240,7 +254,7 @@ func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	size -= 1
 
 	call.GasUsed = gasUsed
-	call.processOutput(output, err)
+	call.processOutput(output, err, t.returnDataLimit)
 	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
 }
 
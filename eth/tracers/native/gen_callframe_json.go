@@ -16,19 +16,20 @@ var _ = (*callFrameMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (c callFrame) MarshalJSON() ([]byte, error) {
 	type callFrame0 struct {
-		Type         vm.OpCode       `json:"-"`
-		From         common.Address  `json:"from"`
-		Gas          hexutil.Uint64  `json:"gas"`
-		GasUsed      hexutil.Uint64  `json:"gasUsed"`
-		To           *common.Address `json:"to,omitempty" rlp:"optional"`
-		Input        hexutil.Bytes   `json:"input" rlp:"optional"`
-		Output       hexutil.Bytes   `json:"output,omitempty" rlp:"optional"`
-		Error        string          `json:"error,omitempty" rlp:"optional"`
-		RevertReason string          `json:"revertReason,omitempty"`
-		Calls        []callFrame     `json:"calls,omitempty" rlp:"optional"`
-		Logs         []callLog       `json:"logs,omitempty" rlp:"optional"`
-		Value        *hexutil.Big    `json:"value,omitempty" rlp:"optional"`
-		TypeString   string          `json:"type"`
+		Type            vm.OpCode       `json:"-"`
+		From            common.Address  `json:"from"`
+		Gas             hexutil.Uint64  `json:"gas"`
+		GasUsed         hexutil.Uint64  `json:"gasUsed"`
+		To              *common.Address `json:"to,omitempty" rlp:"optional"`
+		Input           hexutil.Bytes   `json:"input" rlp:"optional"`
+		Output          hexutil.Bytes   `json:"output,omitempty" rlp:"optional"`
+		Error           string          `json:"error,omitempty" rlp:"optional"`
+		RevertReason    string          `json:"revertReason,omitempty"`
+		OutputTruncated bool            `json:"outputTruncated,omitempty" rlp:"-"`
+		Calls           []callFrame     `json:"calls,omitempty" rlp:"optional"`
+		Logs            []callLog       `json:"logs,omitempty" rlp:"optional"`
+		Value           *hexutil.Big    `json:"value,omitempty" rlp:"optional"`
+		TypeString      string          `json:"type"`
 	}
 	var enc callFrame0
 	enc.Type = c.Type
@@ -40,6 +41,7 @@ func (c callFrame) MarshalJSON() ([]byte, error) {
 	enc.Output = c.Output
 	enc.Error = c.Error
 	enc.RevertReason = c.RevertReason
+	enc.OutputTruncated = c.OutputTruncated
 	enc.Calls = c.Calls
 	enc.Logs = c.Logs
 	enc.Value = (*hexutil.Big)(c.Value)
@@ -50,18 +52,19 @@ func (c callFrame) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON unmarshals from JSON.
 func (c *callFrame) UnmarshalJSON(input []byte) error {
 	type callFrame0 struct {
-		Type         *vm.OpCode      `json:"-"`
-		From         *common.Address `json:"from"`
-		Gas          *hexutil.Uint64 `json:"gas"`
-		GasUsed      *hexutil.Uint64 `json:"gasUsed"`
-		To           *common.Address `json:"to,omitempty" rlp:"optional"`
-		Input        *hexutil.Bytes  `json:"input" rlp:"optional"`
-		Output       *hexutil.Bytes  `json:"output,omitempty" rlp:"optional"`
-		Error        *string         `json:"error,omitempty" rlp:"optional"`
-		RevertReason *string         `json:"revertReason,omitempty"`
-		Calls        []callFrame     `json:"calls,omitempty" rlp:"optional"`
-		Logs         []callLog       `json:"logs,omitempty" rlp:"optional"`
-		Value        *hexutil.Big    `json:"value,omitempty" rlp:"optional"`
+		Type            *vm.OpCode      `json:"-"`
+		From            *common.Address `json:"from"`
+		Gas             *hexutil.Uint64 `json:"gas"`
+		GasUsed         *hexutil.Uint64 `json:"gasUsed"`
+		To              *common.Address `json:"to,omitempty" rlp:"optional"`
+		Input           *hexutil.Bytes  `json:"input" rlp:"optional"`
+		Output          *hexutil.Bytes  `json:"output,omitempty" rlp:"optional"`
+		Error           *string         `json:"error,omitempty" rlp:"optional"`
+		RevertReason    *string         `json:"revertReason,omitempty"`
+		OutputTruncated *bool           `json:"outputTruncated,omitempty" rlp:"-"`
+		Calls           []callFrame     `json:"calls,omitempty" rlp:"optional"`
+		Logs            []callLog       `json:"logs,omitempty" rlp:"optional"`
+		Value           *hexutil.Big    `json:"value,omitempty" rlp:"optional"`
 	}
 	var dec callFrame0
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -94,6 +97,9 @@ func (c *callFrame) UnmarshalJSON(input []byte) error {
 	if dec.RevertReason != nil {
 		c.RevertReason = *dec.RevertReason
 	}
+	if dec.OutputTruncated != nil {
+		c.OutputTruncated = *dec.OutputTruncated
+	}
 	if dec.Calls != nil {
 		c.Calls = dec.Calls
 	}
@@ -34,6 +34,8 @@ type Context struct {
 	BlockNumber *big.Int    // Number of the block the tx is contained within (zero if dangling tx or call)
 	TxIndex     int         // Index of the transaction within a block (zero if dangling tx or call)
 	TxHash      common.Hash // Hash of the transaction being traced (zero if dangling call)
+
+	ReturnDataLimit uint64 // Maximum size of the return data captured by the tracer (zero if unlimited)
 }
 
 // Tracer interface extends vm.DELTALogger and additionally
@@ -1045,7 +1045,7 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 	if blockOverrides != nil {
 		blockOverrides.Apply(&blockCtx)
 	}
-	delta, vmError := b.GetDELTA(ctx, msg, state, header, &vm.Config{NoBaseFee: true}, &blockCtx)
+	delta, vmError := b.GetDELTA(ctx, msg, state, header, &vm.Config{NoBaseFee: true, ReturnDataLimit: b.RPCReturnDataLimit()}, &blockCtx)
 
 	// Wait for the context to be done and cancel the delta. Even if the
 	// DELTA has finished, cancelling may be done (repeatedly)
@@ -1564,7 +1564,7 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 
 		// Apply the transaction with the access list tracer
 		tracer := logger.NewAccessListTracer(accessList, args.from(), to, precompiles)
-		config := vm.Config{Tracer: tracer, NoBaseFee: true}
+		config := vm.Config{Tracer: tracer, NoBaseFee: true, ReturnDataLimit: b.RPCReturnDataLimit()}
 		vmenv, _ := b.GetDELTA(ctx, msg, statedb, header, &config, nil)
 		res, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit))
 		if err != nil {
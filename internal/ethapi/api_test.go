@@ -51,6 +51,7 @@ import (
 	"github.com/DioneProtocol/coreth/ethdb"
 	"github.com/DioneProtocol/coreth/params"
 	"github.com/DioneProtocol/coreth/rpc"
+	"github.com/DioneProtocol/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -193,6 +194,8 @@ func allTransactionTypes(addr common.Address, config *params.ChainConfig) []type
 type testBackend struct {
 	db    ethdb.Database
 	chain *core.BlockChain
+
+	returnDataLimit uint64
 }
 
 func newTestBackend(t *testing.T, n int, gspec *core.Genesis, generator func(i int, b *core.BlockGen)) *testBackend {
@@ -234,6 +237,7 @@ func (b testBackend) ExtRPCEnabled() bool                        { return false
 func (b testBackend) RPCGasCap() uint64                          { return 10000000 }
 func (b testBackend) RPCDELTATimeout() time.Duration             { return time.Second }
 func (b testBackend) RPCTxFeeCap() float64                       { return 0 }
+func (b testBackend) RPCReturnDataLimit() uint64                 { return b.returnDataLimit }
 func (b testBackend) UnprotectedAllowed(*types.Transaction) bool { return false }
 func (b testBackend) SetHead(number uint64)                      {}
 func (b testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
@@ -613,6 +617,52 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestCallReturnDataLimit(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(1)
+		contract = common.Address{0xc0, 0xde}
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				// Returns as many zero bytes as the first word of the calldata.
+				contract: {Code: common.FromHex("0x6000356000f3"), Balance: new(big.Int)},
+			},
+		}
+	)
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {})
+	backend.returnDataLimit = 64
+	api := NewBlockChainAPI(backend)
+
+	latest := rpc.LatestBlockNumber
+	call := func(size int64) (hexutil.Bytes, error) {
+		input := hexutil.Bytes(common.BigToHash(big.NewInt(size)).Bytes())
+		return api.Call(context.Background(), TransactionArgs{
+			From:  &accounts[0].addr,
+			To:    &contract,
+			Input: &input,
+		}, rpc.BlockNumberOrHash{BlockNumber: &latest}, nil, nil)
+	}
+
+	result, err := call(64)
+	if err != nil {
+		t.Fatalf("call at the limit failed: %v", err)
+	}
+	if len(result) != 64 {
+		t.Errorf("want 64 bytes of return data, have %d", len(result))
+	}
+
+	_, err = call(65)
+	if !errors.Is(err, vmerrs.ErrReturnDataLimitExceeded) {
+		t.Fatalf("want error %v, have %v", vmerrs.ErrReturnDataLimitExceeded, err)
+	}
+	var limitErr *vm.ErrReturnDataLimit
+	if !errors.As(err, &limitErr) || limitErr.Size != 65 || limitErr.Limit != 64 {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
 type Account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
@@ -28,6 +28,8 @@
 package miner
 
 import (
+	"time"
+
 	"github.com/DioneProtocol/coreth/consensus"
 	"github.com/DioneProtocol/coreth/core"
 	"github.com/DioneProtocol/coreth/core/txpool"
@@ -65,7 +67,15 @@ func (miner *Miner) SetEtherbase(addr common.Address) {
 }
 
 func (miner *Miner) GenerateBlock() (*types.Block, error) {
-	return miner.worker.commitNewWork()
+	block, _, err := miner.worker.commitNewWork(time.Time{})
+	return block, err
+}
+
+// GenerateBlockWithDeadline generates a block, packing no further transactions
+// once [deadline] has passed. The returned bool reports whether transactions
+// were left out because of the deadline.
+func (miner *Miner) GenerateBlockWithDeadline(deadline time.Time) (*types.Block, bool, error) {
+	return miner.worker.commitNewWork(deadline)
 }
 
 // SubscribePendingLogs starts delivering logs from pending transactions
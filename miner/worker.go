@@ -55,7 +55,7 @@ const (
 	// This should suffice for atomic txs, proposervm header, and serialization overhead.
 	targetTxsSize = 1792 * units.KiB
 
-	x2cRateInt64 int64 = 1_000_000_000
+	x2cRateInt64 int64 = params.X2CRate
 )
 
 var (
@@ -77,6 +77,20 @@ type environment struct {
 	size     uint64
 
 	start time.Time // Time that block building began
+
+	deadline  time.Time // Soft deadline after which no further transactions are packed (zero = none)
+	truncated bool      // Whether packing stopped because [deadline] passed
+}
+
+// deadlineExceeded reports whether [env.deadline] has passed and marks [env]
+// as truncated if so. The deadline never applies before the first transaction
+// has been packed, so that the block is not left empty.
+func (env *environment) deadlineExceeded() bool {
+	if env.deadline.IsZero() || env.tcount == 0 || time.Now().Before(env.deadline) {
+		return false
+	}
+	env.truncated = true
+	return true
 }
 
 // worker is the main object which takes care of submitting new work to consensus engine
@@ -125,7 +139,9 @@ func (w *worker) setEtherbase(addr common.Address) {
 }
 
 // commitNewWork generates several new sealing tasks based on the parent block.
-func (w *worker) commitNewWork() (*types.Block, error) {
+// If [deadline] is non-zero, no further transactions are packed once it has
+// passed, and the returned bool reports whether that happened.
+func (w *worker) commitNewWork(deadline time.Time) (*types.Block, bool, error) {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
@@ -139,12 +155,8 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 		timestamp = parent.Time
 	}
 
-	var gasLimit uint64
-	if w.chainConfig.IsCortina(timestamp) {
-		gasLimit = params.CortinaGasLimit
-	} else if w.chainConfig.IsApricotPhase1(timestamp) {
-		gasLimit = params.ApricotPhase1GasLimit
-	} else {
+	gasLimit, _ := dummy.GasLimit(w.chainConfig.ForkFlags(new(big.Int).Add(parent.Number, common.Big1), timestamp))
+	if gasLimit == 0 {
 		// The gas limit is set in phase1 to ApricotPhase1GasLimit because the ceiling and floor were set to the same value
 		// such that the gas limit converged to it. Since this is hardbaked now, we remove the ability to configure it.
 		gasLimit = core.CalcGasLimit(parent.GasUsed, parent.GasLimit, params.ApricotPhase1GasLimit, params.ApricotPhase1GasLimit)
@@ -169,23 +181,26 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 		var err error
 		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(w.chainConfig, parent, timestamp)
 		if err != nil {
-			return nil, fmt.Errorf("failed to calculate new base fee: %w", err)
+			return nil, false, fmt.Errorf("failed to calculate new base fee: %w", err)
 		}
 	}
 	if w.coinbase == (common.Address{}) {
-		return nil, errors.New("cannot mine without etherbase")
+		return nil, false, errors.New("cannot mine without etherbase")
 	}
 	header.Coinbase = w.coinbase
 	if err := w.engine.Prepare(w.chain, header); err != nil {
-		return nil, fmt.Errorf("failed to prepare header for mining: %w", err)
+		return nil, false, fmt.Errorf("failed to prepare header for mining: %w", err)
 	}
 
 	env, err := w.createCurrentEnvironment(parent, header, tstart)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new current environment: %w", err)
+		return nil, false, fmt.Errorf("failed to create new current environment: %w", err)
 	}
+	env.deadline = deadline
 	// Configure any stateful precompiles that should go into effect during this block.
-	w.chainConfig.CheckConfigurePrecompiles(&parent.Time, types.NewBlockWithHeader(header), env.state)
+	if err := w.chainConfig.CheckConfigurePrecompiles(&parent.Time, types.NewBlockWithHeader(header), env.state); err != nil {
+		return nil, false, fmt.Errorf("failed to configure precompiles: %w", err)
+	}
 
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(true)
@@ -208,7 +223,8 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 		w.commitTransactions(env, txs, w.coinbase)
 	}
 
-	return w.commit(env)
+	block, err := w.commit(env)
+	return block, env.truncated, err
 }
 
 func (w *worker) createCurrentEnvironment(parent *types.Header, header *types.Header, tstart time.Time) (*environment, error) {
@@ -253,6 +269,12 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
 			break
 		}
+		// Stop packing once the deadline has passed. This is only checked
+		// between transactions so that state application is never cut short.
+		if env.deadlineExceeded() {
+			log.Debug("Block building deadline exceeded", "txs", env.tcount)
+			break
+		}
 		// Retrieve the next transaction and abort if all done.
 		tx := txs.Peek()
 		if tx == nil {
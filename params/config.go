@@ -27,9 +27,13 @@
 package params
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
+	"sort"
 
 	"github.com/DioneProtocol/coreth/precompile"
 	"github.com/DioneProtocol/coreth/utils"
@@ -46,6 +50,21 @@ var (
 	OdysseyLocalChainID = big.NewInt(131312)
 
 	errNonGenesisForkByHeight = errors.New("coreth only supports forking by height at the genesis block")
+
+	errNilPrecompileConfig          = errors.New("nil stateful precompile config")
+	errPrecompileNeverEnabled       = errors.New("stateful precompile has no activation timestamp")
+	errPrecompileAddressNotReserved = errors.New("stateful precompile address is not in a reserved range")
+	errPrecompileAddressConflict    = errors.New("stateful precompile address is already in use")
+
+	errDuplicateExtraEIP  = errors.New("extra eip is scheduled more than once")
+	errUnorderedExtraEIPs = errors.New("extra eips are not ordered by activation timestamp")
+	errInvalidExtraEIP    = errors.New("extra eip number must be positive")
+
+	errInvalidAllocation              = errors.New("fee allocation must be a non-negative integer that fits in 64 bits")
+	errInvalidAllocationDenominator   = errors.New("fee allocation denominator must be a positive power of 10")
+	errMissingFeeAllocationsTimestamp = errors.New("fee allocations are set without an activation timestamp")
+
+	errMissingTargetBlockRateTimestamp = errors.New("target block rate is set without an activation timestamp")
 )
 
 var (
@@ -74,6 +93,13 @@ var (
 		BanffBlockTimestamp:             utils.NewUint64(0),
 		CortinaBlockTimestamp:           utils.NewUint64(0),
 		DUpgradeBlockTimestamp:          utils.NewUint64(0),
+		LpAllocation:                    LpAllocation,
+		GovernanceAllocation:            GovernanceAllocation,
+		OrionAllocation:                 OrionAllocation,
+		MaxOrionAllocation:              MaxOrionAllocation,
+		PriorityFeeOrionAllocation:      PriorityFeeOrionAllocation,
+		AllocationDenominator:           AllocationDenominator,
+		FeeAllocationsTimestamp:         utils.NewUint64(0),
 	}
 
 	// OdysseyTestnetChainConfig is the configuration for the Testnet Test Network
@@ -101,6 +127,13 @@ var (
 		BanffBlockTimestamp:             utils.NewUint64(0),
 		CortinaBlockTimestamp:           utils.NewUint64(0),
 		DUpgradeBlockTimestamp:          utils.NewUint64(0),
+		LpAllocation:                    LpAllocation,
+		GovernanceAllocation:            GovernanceAllocation,
+		OrionAllocation:                 OrionAllocation,
+		MaxOrionAllocation:              MaxOrionAllocation,
+		PriorityFeeOrionAllocation:      PriorityFeeOrionAllocation,
+		AllocationDenominator:           AllocationDenominator,
+		FeeAllocationsTimestamp:         utils.NewUint64(0),
 	}
 
 	// OdysseyLocalChainConfig is the configuration for the Odyssey Local Network
@@ -128,6 +161,13 @@ var (
 		BanffBlockTimestamp:             utils.NewUint64(0),
 		CortinaBlockTimestamp:           utils.NewUint64(0),
 		DUpgradeBlockTimestamp:          utils.NewUint64(0),
+		LpAllocation:                    LpAllocation,
+		GovernanceAllocation:            GovernanceAllocation,
+		OrionAllocation:                 OrionAllocation,
+		MaxOrionAllocation:              MaxOrionAllocation,
+		PriorityFeeOrionAllocation:      PriorityFeeOrionAllocation,
+		AllocationDenominator:           AllocationDenominator,
+		FeeAllocationsTimestamp:         utils.NewUint64(0),
 	}
 
 	TestChainConfig = &ChainConfig{
@@ -532,8 +572,53 @@ type ChainConfig struct {
 	CortinaBlockTimestamp *uint64 `json:"cortinaBlockTimestamp,omitempty"`
 	// DUpgrade activates the Shanghai upgrade from Ethereum. (nil = no fork, 0 = already activated)
 	DUpgradeBlockTimestamp *uint64 `json:"dUpgradeBlockTimestamp,omitempty"`
+	// EUpgrade raises the atomic gas limit to EUpgradeAtomicGasLimit. (nil = no fork, 0 = already activated)
+	EUpgradeBlockTimestamp *uint64 `json:"eUpgradeBlockTimestamp,omitempty"`
+	// OdyPhaseExtraChecksum stores a checksum of the rollup window in the last slot of the
+	// header extra data. (nil = no fork, 0 = already activated)
+	OdyPhaseExtraChecksumTimestamp *uint64 `json:"odyPhaseExtraChecksumTimestamp,omitempty"`
+	// OdyPhaseOrionSnapshot distributes the fees of a block to the orion nodes registered in the
+	// state of its parent rather than in the state after its txs. (nil = no fork, 0 = already activated)
+	OdyPhaseOrionSnapshotTimestamp *uint64 `json:"odyPhaseOrionSnapshotTimestamp,omitempty"`
 	// Cancun activates the Cancun upgrade from Ethereum. (nil = no fork, 0 = already activated)
 	CancunTime *uint64 `json:"cancunTime,omitempty"`
+
+	// Fee distribution parameters. Each allocation is the share of the fees,
+	// out of AllocationDenominator, paid to its recipient as of
+	// FeeAllocationsTimestamp. Before it, and if it is nil, each allocation is
+	// the network default.
+	LpAllocation               *big.Int `json:"lpAllocation,omitempty"`
+	GovernanceAllocation       *big.Int `json:"governanceAllocation,omitempty"`
+	OrionAllocation            *big.Int `json:"orionAllocation,omitempty"`
+	MaxOrionAllocation         *big.Int `json:"maxOrionAllocation,omitempty"`
+	PriorityFeeOrionAllocation *big.Int `json:"priorityFeeOrionAllocation,omitempty"`
+	AllocationDenominator      *big.Int `json:"allocationDenominator,omitempty"`
+	// FeeAllocationsTimestamp is the timestamp the fee allocations are enabled
+	// at. It is required if any allocation is set. (0 = already activated)
+	FeeAllocationsTimestamp *uint64 `json:"feeAllocationsTimestamp,omitempty"`
+
+	// TargetBlockRate is the block rate, in seconds, targeted by the block gas
+	// cost as of TargetBlockRateTimestamp. Before it, and if it is nil or 0, the
+	// target is ApricotPhase4TargetBlockRate.
+	TargetBlockRate *uint64 `json:"targetBlockRate,omitempty"`
+	// TargetBlockRateTimestamp is the timestamp TargetBlockRate is enabled at.
+	// It is required if TargetBlockRate is set. (0 = already activated)
+	TargetBlockRateTimestamp *uint64 `json:"targetBlockRateTimestamp,omitempty"`
+
+	// ExtraEIPs schedules EIPs that are not part of a network upgrade, in
+	// activation order. Each EIP is enabled from its timestamp on, on top of
+	// the instruction set of the active network upgrade.
+	ExtraEIPs []ExtraEIP `json:"extraEips,omitempty"`
+
+	// statefulPrecompiles holds the precompiles added with RegisterPrecompile,
+	// ordered by the timestamp they are enabled at. Not serialized.
+	statefulPrecompiles []precompile.StatefulPrecompileConfig
+}
+
+// ExtraEIP schedules an EIP to be enabled by the DELTA at and after Timestamp.
+type ExtraEIP struct {
+	EIP       int    `json:"eip"`
+	Timestamp uint64 `json:"timestamp"`
 }
 
 // OdysseyContext provides Odyssey specific context directly into the DELTA.
@@ -541,6 +626,58 @@ type OdysseyContext struct {
 	BlockchainID common.Hash
 }
 
+// fork describes a network upgrade configured in a ChainConfig.
+type fork struct {
+	name      string   // json name of the fork field
+	desc      string   // human-readable name of the fork
+	url       string   // specification or release of the fork
+	block     *big.Int // some go-ethereum forks use block numbers
+	timestamp *uint64  // Odyssey forks use timestamps
+	optional  bool     // if true, the fork may be nil and next fork is still allowed
+}
+
+// blockForks returns the go-ethereum forks activated by block number, in the
+// order they must be activated.
+func (c *ChainConfig) blockForks() []fork {
+	const specs = "https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/"
+	return []fork{
+		{name: "homesteadBlock", desc: "Homestead", url: specs + "homestead.md", block: c.HomesteadBlock},
+		{name: "daoForkBlock", desc: "DAO Fork", url: specs + "dao-fork.md", block: c.DAOForkBlock, optional: true},
+		{name: "eip150Block", desc: "Tangerine Whistle (EIP 150)", url: specs + "tangerine-whistle.md", block: c.EIP150Block},
+		{name: "eip155Block", desc: "Spurious Dragon/1 (EIP 155)", url: specs + "spurious-dragon.md", block: c.EIP155Block},
+		{name: "eip158Block", desc: "Spurious Dragon/2 (EIP 158)", url: specs + "spurious-dragon.md", block: c.EIP158Block},
+		{name: "byzantiumBlock", desc: "Byzantium", url: specs + "byzantium.md", block: c.ByzantiumBlock},
+		{name: "constantinopleBlock", desc: "Constantinople", url: specs + "constantinople.md", block: c.ConstantinopleBlock},
+		{name: "petersburgBlock", desc: "Petersburg", url: specs + "petersburg.md", block: c.PetersburgBlock},
+		{name: "istanbulBlock", desc: "Istanbul", url: specs + "istanbul.md", block: c.IstanbulBlock},
+		{name: "muirGlacierBlock", desc: "Muir Glacier", url: specs + "muir-glacier.md", block: c.MuirGlacierBlock, optional: true},
+	}
+}
+
+// timestampForks returns the Odyssey forks activated by block timestamp, in
+// the order they must be activated.
+func (c *ChainConfig) timestampForks() []fork {
+	const releases = "https://github.com/DioneProtocol/odysseygo/releases/tag/"
+	return []fork{
+		{name: "apricotPhase1BlockTimestamp", desc: "Apricot Phase 1", url: releases + "v1.3.0", timestamp: c.ApricotPhase1BlockTimestamp},
+		{name: "apricotPhase2BlockTimestamp", desc: "Apricot Phase 2", url: releases + "v1.4.0", timestamp: c.ApricotPhase2BlockTimestamp},
+		{name: "apricotPhase3BlockTimestamp", desc: "Apricot Phase 3", url: releases + "v1.5.0", timestamp: c.ApricotPhase3BlockTimestamp},
+		{name: "apricotPhase4BlockTimestamp", desc: "Apricot Phase 4", url: releases + "v1.6.0", timestamp: c.ApricotPhase4BlockTimestamp},
+		{name: "apricotPhase5BlockTimestamp", desc: "Apricot Phase 5", url: releases + "v1.7.0", timestamp: c.ApricotPhase5BlockTimestamp},
+		{name: "apricotPhasePre6BlockTimestamp", desc: "Apricot Phase Pre-6", url: releases + "v1.8.0", timestamp: c.ApricotPhasePre6BlockTimestamp},
+		{name: "apricotPhase6BlockTimestamp", desc: "Apricot Phase 6", url: releases + "v1.8.0", timestamp: c.ApricotPhase6BlockTimestamp},
+		{name: "apricotPhasePost6BlockTimestamp", desc: "Apricot Phase Post-6", url: releases + "v1.8.0", timestamp: c.ApricotPhasePost6BlockTimestamp},
+		{name: "banffBlockTimestamp", desc: "Banff", url: releases + "v1.9.0", timestamp: c.BanffBlockTimestamp},
+		{name: "cortinaBlockTimestamp", desc: "Cortina", url: releases + "v1.10.0", timestamp: c.CortinaBlockTimestamp},
+		{name: "dUpgradeBlockTimestamp", desc: "DUpgrade", url: releases + "v1.11.0", timestamp: c.DUpgradeBlockTimestamp},
+		// The following forks have not been released yet, so they have no URL.
+		{name: "eUpgradeBlockTimestamp", desc: "EUpgrade", timestamp: c.EUpgradeBlockTimestamp, optional: true},
+		{name: "odyPhaseExtraChecksumTimestamp", desc: "OdyPhase Extra Checksum", timestamp: c.OdyPhaseExtraChecksumTimestamp, optional: true},
+		{name: "odyPhaseOrionSnapshotTimestamp", desc: "OdyPhase Orion Snapshot", timestamp: c.OdyPhaseOrionSnapshotTimestamp, optional: true},
+		{name: "cancunTime", desc: "Cancun", url: releases + "v1.11.0", timestamp: c.CancunTime},
+	}
+}
+
 // Description returns a human-readable description of ChainConfig.
 func (c *ChainConfig) Description() string {
 	var banner string
@@ -552,36 +689,134 @@ func (c *ChainConfig) Description() string {
 	// makes sense for mainnet should be optional at printing to avoid bloating
 	// the output for testnets and private networks.
 	banner += "Hard Forks:\n"
-	banner += fmt.Sprintf(" - Homestead:                   #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/homestead.md)\n", c.HomesteadBlock)
-	if c.DAOForkBlock != nil {
-		banner += fmt.Sprintf(" - DAO Fork:                    #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/dao-fork.md)\n", c.DAOForkBlock)
-	}
-	banner += fmt.Sprintf(" - Tangerine Whistle (EIP 150): #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/tangerine-whistle.md)\n", c.EIP150Block)
-	banner += fmt.Sprintf(" - Spurious Dragon/1 (EIP 155): #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/spurious-dragon.md)\n", c.EIP155Block)
-	banner += fmt.Sprintf(" - Spurious Dragon/2 (EIP 158): #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/spurious-dragon.md)\n", c.EIP155Block)
-	banner += fmt.Sprintf(" - Byzantium:                   #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/byzantium.md)\n", c.ByzantiumBlock)
-	banner += fmt.Sprintf(" - Constantinople:              #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/constantinople.md)\n", c.ConstantinopleBlock)
-	banner += fmt.Sprintf(" - Petersburg:                  #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/petersburg.md)\n", c.PetersburgBlock)
-	banner += fmt.Sprintf(" - Istanbul:                    #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/istanbul.md)\n", c.IstanbulBlock)
-	if c.MuirGlacierBlock != nil {
-		banner += fmt.Sprintf(" - Muir Glacier:                #%-8v (https://github.com/ethereum/execution-specs/blob/master/network-upgrades/mainnet-upgrades/muir-glacier.md)\n", c.MuirGlacierBlock)
-	}
-	banner += fmt.Sprintf(" - Apricot Phase 1 Timestamp:        #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.3.0)\n", c.ApricotPhase1BlockTimestamp)
-	banner += fmt.Sprintf(" - Apricot Phase 2 Timestamp:        #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.4.0)\n", c.ApricotPhase2BlockTimestamp)
-	banner += fmt.Sprintf(" - Apricot Phase 3 Timestamp:        #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.5.0)\n", c.ApricotPhase3BlockTimestamp)
-	banner += fmt.Sprintf(" - Apricot Phase 4 Timestamp:        #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.6.0)\n", c.ApricotPhase4BlockTimestamp)
-	banner += fmt.Sprintf(" - Apricot Phase 5 Timestamp:        #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.7.0)\n", c.ApricotPhase5BlockTimestamp)
-	banner += fmt.Sprintf(" - Apricot Phase P6 Timestamp        #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.8.0)\n", c.ApricotPhasePre6BlockTimestamp)
-	banner += fmt.Sprintf(" - Apricot Phase 6 Timestamp:        #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.8.0)\n", c.ApricotPhase6BlockTimestamp)
-	banner += fmt.Sprintf(" - Apricot Phase Post-6 Timestamp:   #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.8.0\n", c.ApricotPhasePost6BlockTimestamp)
-	banner += fmt.Sprintf(" - Banff Timestamp:                  #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.9.0)\n", c.BanffBlockTimestamp)
-	banner += fmt.Sprintf(" - Cortina Timestamp:                #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.10.0)\n", c.CortinaBlockTimestamp)
-	banner += fmt.Sprintf(" - DUpgrade Timestamp:               #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.11.0)\n", c.DUpgradeBlockTimestamp)
-	banner += fmt.Sprintf(" - Cancun Timestamp:                 #%-8v (https://github.com/DioneProtocol/odysseygo/releases/tag/v1.11.0)\n", c.DUpgradeBlockTimestamp)
+	var (
+		blockForks     = c.blockForks()
+		timestampForks = c.timestampForks()
+		width          int
+	)
+	for _, f := range blockForks {
+		if n := len(f.desc + ":"); n > width {
+			width = n
+		}
+	}
+	for _, f := range timestampForks {
+		if n := len(f.desc + " Timestamp:"); n > width {
+			width = n
+		}
+	}
+	for _, f := range blockForks {
+		if f.optional && f.block == nil {
+			continue
+		}
+		banner += forkLine(fmt.Sprintf(" - %-*s #%-8v", width, f.desc+":", f.block), f.url)
+	}
+	for _, f := range timestampForks {
+		timestamp := "nil"
+		if f.timestamp != nil {
+			timestamp = fmt.Sprint(*f.timestamp)
+		}
+		banner += forkLine(fmt.Sprintf(" - %-*s @%-10s", width, f.desc+" Timestamp:", timestamp), f.url)
+	}
 	banner += "\n"
 	return banner
 }
 
+// forkLine terminates the banner line [line] of a fork with [url], if the fork
+// has one.
+func forkLine(line string, url string) string {
+	if url == "" {
+		return line + "\n"
+	}
+	return line + " (" + url + ")\n"
+}
+
+// NetworkUpgrade is a network upgrade activated by block timestamp.
+type NetworkUpgrade struct {
+	// Name is the human-readable name of the upgrade.
+	Name string
+	// Timestamp is the activation timestamp of the upgrade, or nil if it is
+	// not scheduled.
+	Timestamp *uint64
+}
+
+// NetworkUpgrades returns the network upgrades of [c] activated by block
+// timestamp, in the order they must be activated.
+func (c *ChainConfig) NetworkUpgrades() []NetworkUpgrade {
+	forks := c.timestampForks()
+	upgrades := make([]NetworkUpgrade, len(forks))
+	for i, f := range forks {
+		upgrades[i] = NetworkUpgrade{Name: f.desc, Timestamp: f.timestamp}
+	}
+	return upgrades
+}
+
+// chainConfigJSON has the fields of ChainConfig without its methods, so that
+// it is encoded as a JSON object rather than through MarshalText.
+type chainConfigJSON ChainConfig
+
+// MarshalJSON encodes the config as a JSON object.
+func (c *ChainConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*chainConfigJSON)(c))
+}
+
+// UnmarshalJSON decodes a JSON object into the config.
+func (c *ChainConfig) UnmarshalJSON(input []byte) error {
+	return json.Unmarshal(input, (*chainConfigJSON)(c))
+}
+
+// MarshalText encodes the config as compact JSON. Fields left at their zero
+// value are omitted.
+func (c *ChainConfig) MarshalText() ([]byte, error) {
+	return c.MarshalJSON()
+}
+
+// UnmarshalText decodes a JSON encoded config into [c]. The current values of
+// [c] are used as defaults for any fields missing from [text]. The resulting
+// config must have a valid fork order and, if [c] already held a config, must
+// be compatible with it at genesis.
+func (c *ChainConfig) UnmarshalText(text []byte) error {
+	// Copy the defaults through JSON so decoding into the copy does not
+	// mutate values shared with the current config.
+	defaults, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	parsed := &ChainConfig{}
+	if err := json.Unmarshal(defaults, parsed); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(text, parsed); err != nil {
+		return err
+	}
+	if err := parsed.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(*c, ChainConfig{}) {
+		if compatErr := c.CheckCompatible(parsed, 0, 0); compatErr != nil {
+			return compatErr
+		}
+	}
+	parsed.OdysseyContext = c.OdysseyContext
+	parsed.statefulPrecompiles = c.statefulPrecompiles
+	*c = *parsed
+	return nil
+}
+
+// String returns the compact JSON encoding of the config.
+func (c *ChainConfig) String() string {
+	text, err := c.MarshalText()
+	if err != nil {
+		return err.Error()
+	}
+	return string(text)
+}
+
+// Set implements flag.Value by decoding [s] with UnmarshalText, so a
+// ChainConfig can be passed as a flag such as --chain-config '{"chainId":153}'.
+func (c *ChainConfig) Set(s string) error {
+	return c.UnmarshalText([]byte(s))
+}
+
 // IsHomestead returns whether num is either equal to the homestead block or greater.
 func (c *ChainConfig) IsHomestead(num *big.Int) bool {
 	return utils.IsBlockForked(c.HomesteadBlock, num)
@@ -702,6 +937,24 @@ func (c *ChainConfig) IsDUpgrade(time uint64) bool {
 	return utils.IsTimestampForked(c.DUpgradeBlockTimestamp, time)
 }
 
+// IsEUpgrade returns whether [time] represents a block
+// with a timestamp after the EUpgrade upgrade time.
+func (c *ChainConfig) IsEUpgrade(time uint64) bool {
+	return utils.IsTimestampForked(c.EUpgradeBlockTimestamp, time)
+}
+
+// IsOdyPhaseExtraChecksum returns whether [time] represents a block
+// with a timestamp after the OdyPhaseExtraChecksum upgrade time.
+func (c *ChainConfig) IsOdyPhaseExtraChecksum(time uint64) bool {
+	return utils.IsTimestampForked(c.OdyPhaseExtraChecksumTimestamp, time)
+}
+
+// IsOdyPhaseOrionSnapshot returns whether [time] represents a block
+// with a timestamp after the OdyPhaseOrionSnapshot upgrade time.
+func (c *ChainConfig) IsOdyPhaseOrionSnapshot(time uint64) bool {
+	return utils.IsTimestampForked(c.OdyPhaseOrionSnapshotTimestamp, time)
+}
+
 // IsCancun returns whether [time] represents a block
 // with a timestamp after the Cancun upgrade time.
 func (c *ChainConfig) IsCancun(time uint64) bool {
@@ -733,66 +986,69 @@ func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, time u
 	return lasterr
 }
 
-func (c *ChainConfig) LpAddress(time uint64) common.Address {
-	return common.HexToAddress(LpAddress)
+// UpgradeCheckOverride relaxes the check of a new chain config against the
+// stored one for networks resurrected from a snapshot of another network's
+// database. Changes to the upgrades scheduled at or after [Height] or
+// [Timestamp] are accepted even if the last accepted block is past them, while
+// changes to the upgrades scheduled before them are still rejected.
+type UpgradeCheckOverride struct {
+	Height    *uint64 `json:"height,omitempty"`
+	Timestamp *uint64 `json:"timestamp,omitempty"`
 }
 
-func (c *ChainConfig) GovernanceAddress(time uint64) common.Address {
-	return common.HexToAddress(GovernanceAddress)
+// Head returns the head to check the compatibility of a new chain config at in
+// place of [height] and [time], so that the upgrades scheduled at or after the
+// override thresholds are treated as not yet activated.
+func (o *UpgradeCheckOverride) Head(height uint64, time uint64) (uint64, uint64) {
+	if o == nil {
+		return height, time
+	}
+	if o.Height != nil && *o.Height > 0 && *o.Height <= height {
+		height = *o.Height - 1
+	}
+	if o.Timestamp != nil && *o.Timestamp > 0 && *o.Timestamp <= time {
+		time = *o.Timestamp - 1
+	}
+	return height, time
 }
 
-func (c *ChainConfig) LpAllocation(time uint64) *big.Int {
-	return LpAllocation
+// GetTargetBlockRate returns the block rate, in seconds, targeted by the
+// block gas cost of a block at [time].
+func (c *ChainConfig) GetTargetBlockRate(time uint64) uint64 {
+	if c.TargetBlockRate == nil || *c.TargetBlockRate == 0 || !utils.IsTimestampForked(c.TargetBlockRateTimestamp, time) {
+		return ApricotPhase4TargetBlockRate
+	}
+	return *c.TargetBlockRate
 }
 
-func (c *ChainConfig) GovernanceAllocation(time uint64) *big.Int {
-	return GovernanceAllocation
+// lpAddress and governanceAddress are parsed once, so that computing the rules
+// of a block does not parse them again.
+var (
+	lpAddress         = common.HexToAddress(LpAddress)
+	governanceAddress = common.HexToAddress(GovernanceAddress)
+)
+
+func (c *ChainConfig) LpAddress(time uint64) common.Address {
+	return lpAddress
 }
 
-func (c *ChainConfig) AllocationDenominator(time uint64) *big.Int {
-	return AllocationDenominator
+func (c *ChainConfig) GovernanceAddress(time uint64) common.Address {
+	return governanceAddress
 }
 
 func (c *ChainConfig) OrionNodesGetter(time uint64) OrionNodesGetter {
 	return OrionGetter
 }
 
-func (c *ChainConfig) OrionAllocation(time uint64) *big.Int {
-	return OrionAllocation
-}
-
-func (c *ChainConfig) MaxOrionAllocation(time uint64) *big.Int {
-	return MaxOrionAllocation
-}
-
-func (c *ChainConfig) PriorityFeeOrionAllocation(time uint64) *big.Int {
-	return PriorityFeeOrionAllocation
-}
-
 // CheckConfigForkOrder checks that we don't "skip" any forks, geth isn't pluggable enough
 // to guarantee that forks can be implemented in a different order than on official networks
 func (c *ChainConfig) CheckConfigForkOrder() error {
-	type fork struct {
-		name      string
-		block     *big.Int // some go-ethereum forks use block numbers
-		timestamp *uint64  // Odyssey forks use timestamps
-		optional  bool     // if true, the fork may be nil and next fork is still allowed
-	}
 	var lastFork fork
-	for _, cur := range []fork{
-		{name: "homesteadBlock", block: c.HomesteadBlock},
-		{name: "daoForkBlock", block: c.DAOForkBlock, optional: true},
-		{name: "eip150Block", block: c.EIP150Block},
-		{name: "eip155Block", block: c.EIP155Block},
-		{name: "eip158Block", block: c.EIP158Block},
-		{name: "byzantiumBlock", block: c.ByzantiumBlock},
-		{name: "constantinopleBlock", block: c.ConstantinopleBlock},
-		{name: "petersburgBlock", block: c.PetersburgBlock},
-		{name: "istanbulBlock", block: c.IstanbulBlock},
-		{name: "muirGlacierBlock", block: c.MuirGlacierBlock, optional: true},
-	} {
+	for _, cur := range c.blockForks() {
+		// Block number forks must be enabled at genesis or not at all, since
+		// upgrades after genesis are scheduled by block timestamp.
 		if cur.block != nil && common.Big0.Cmp(cur.block) != 0 {
-			return errNonGenesisForkByHeight
+			return fmt.Errorf("%w: %v enabled at %v", errNonGenesisForkByHeight, cur.name, cur.block)
 		}
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -818,32 +1074,29 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 	// of block numbers since blocks are produced asynchronously. Therefore, we do not
 	// check that the block timestamps for Apricot Phase1 and Phase2 in the same way as for
 	// the block number forks since it would not be a meaningful comparison.
-	// Instead, we check only that Apricot Phases are enabled in order.
+	// Instead, we check only that Apricot Phases are enabled in order, and that
+	// they are only enabled on top of every required block number fork.
+	timestampForks := c.timestampForks()
+	if first := timestampForks[0]; first.timestamp != nil {
+		for _, blockFork := range c.blockForks() {
+			if !blockFork.optional && blockFork.block == nil {
+				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v",
+					blockFork.name, first.name, *first.timestamp)
+			}
+		}
+	}
 	lastFork = fork{}
-	for _, cur := range []fork{
-		{name: "apricotPhase1BlockTimestamp", timestamp: c.ApricotPhase1BlockTimestamp},
-		{name: "apricotPhase2BlockTimestamp", timestamp: c.ApricotPhase2BlockTimestamp},
-		{name: "apricotPhase3BlockTimestamp", timestamp: c.ApricotPhase3BlockTimestamp},
-		{name: "apricotPhase4BlockTimestamp", timestamp: c.ApricotPhase4BlockTimestamp},
-		{name: "apricotPhase5BlockTimestamp", timestamp: c.ApricotPhase5BlockTimestamp},
-		{name: "apricotPhasePre6BlockTimestamp", timestamp: c.ApricotPhasePre6BlockTimestamp},
-		{name: "apricotPhase6BlockTimestamp", timestamp: c.ApricotPhase6BlockTimestamp},
-		{name: "apricotPhasePost6BlockTimestamp", timestamp: c.ApricotPhasePost6BlockTimestamp},
-		{name: "banffBlockTimestamp", timestamp: c.BanffBlockTimestamp},
-		{name: "cortinaBlockTimestamp", timestamp: c.CortinaBlockTimestamp},
-		{name: "dUpgradeBlockTimestamp", timestamp: c.DUpgradeBlockTimestamp},
-		{name: "cancunTime", timestamp: c.CancunTime},
-	} {
+	for _, cur := range timestampForks {
 		if lastFork.name != "" {
-			// Next one must be higher number
+			// Each fork requires the previous one to be enabled at or before it
 			if lastFork.timestamp == nil && cur.timestamp != nil {
 				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v",
-					lastFork.name, cur.name, cur.timestamp)
+					lastFork.name, cur.name, *cur.timestamp)
 			}
 			if lastFork.timestamp != nil && cur.timestamp != nil {
 				if *lastFork.timestamp > *cur.timestamp {
 					return fmt.Errorf("unsupported fork ordering: %v enabled at %v, but %v enabled at %v",
-						lastFork.name, lastFork.timestamp, cur.name, cur.timestamp)
+						lastFork.name, *lastFork.timestamp, cur.name, *cur.timestamp)
 				}
 			}
 		}
@@ -852,13 +1105,122 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
-	// TODO(aaronbuchwald) check that odyssey block timestamps are at least possible with the other rule set changes
-	// additional change: require that block number hard forks are either 0 or nil since they should not
-	// be enabled at a specific block number.
 
+	if err := c.checkExtraEIPs(); err != nil {
+		return err
+	}
+	if c.TargetBlockRate != nil && c.TargetBlockRateTimestamp == nil {
+		return fmt.Errorf("%w: targetBlockRate is %d", errMissingTargetBlockRateTimestamp, *c.TargetBlockRate)
+	}
+	return c.checkAllocations()
+}
+
+// checkAllocations checks that each configured fee allocation fits in the
+// uint64 it is converted to by OdysseyRules, that the denominator is a power of
+// 10 and that the allocations are scheduled if any is set.
+func (c *ChainConfig) checkAllocations() error {
+	allocations := []struct {
+		name       string
+		allocation *big.Int
+	}{
+		{"lpAllocation", c.LpAllocation},
+		{"governanceAllocation", c.GovernanceAllocation},
+		{"orionAllocation", c.OrionAllocation},
+		{"maxOrionAllocation", c.MaxOrionAllocation},
+		{"priorityFeeOrionAllocation", c.PriorityFeeOrionAllocation},
+		{"allocationDenominator", c.AllocationDenominator},
+	}
+	for _, a := range allocations {
+		if a.allocation == nil {
+			continue
+		}
+		if !a.allocation.IsUint64() {
+			return fmt.Errorf("%w: %s is %v", errInvalidAllocation, a.name, a.allocation)
+		}
+		if c.FeeAllocationsTimestamp == nil {
+			return fmt.Errorf("%w: %s is %v", errMissingFeeAllocationsTimestamp, a.name, a.allocation)
+		}
+	}
+	if c.AllocationDenominator != nil && !isPowerOfTen(c.AllocationDenominator.Uint64()) {
+		return fmt.Errorf("%w: allocationDenominator is %v", errInvalidAllocationDenominator, c.AllocationDenominator)
+	}
 	return nil
 }
 
+// isPowerOfTen returns true if [x] is 10^n for some n >= 0.
+func isPowerOfTen(x uint64) bool {
+	for x >= 10 && x%10 == 0 {
+		x /= 10
+	}
+	return x == 1
+}
+
+// checkExtraEIPs checks that each extra EIP is scheduled once and that they are
+// scheduled in activation order, so that they are enabled in the same order at
+// every block.
+func (c *ChainConfig) checkExtraEIPs() error {
+	scheduled := make(map[int]struct{}, len(c.ExtraEIPs))
+	for i, eip := range c.ExtraEIPs {
+		if eip.EIP <= 0 {
+			return fmt.Errorf("%w: %d", errInvalidExtraEIP, eip.EIP)
+		}
+		if _, ok := scheduled[eip.EIP]; ok {
+			return fmt.Errorf("%w: EIP-%d", errDuplicateExtraEIP, eip.EIP)
+		}
+		scheduled[eip.EIP] = struct{}{}
+		if i > 0 && c.ExtraEIPs[i-1].Timestamp > eip.Timestamp {
+			prev := c.ExtraEIPs[i-1]
+			return fmt.Errorf("%w: EIP-%d enabled at %d, but EIP-%d enabled at %d",
+				errUnorderedExtraEIPs, prev.EIP, prev.Timestamp, eip.EIP, eip.Timestamp)
+		}
+	}
+	return nil
+}
+
+// extraEIPTimestamp returns the activation timestamp of [eip] in [c], or nil if
+// it is not scheduled.
+func (c *ChainConfig) extraEIPTimestamp(eip int) *uint64 {
+	for _, extra := range c.ExtraEIPs {
+		if extra.EIP == eip {
+			timestamp := extra.Timestamp
+			return &timestamp
+		}
+	}
+	return nil
+}
+
+// ActivatedForks returns the human-readable names of the forks, in the order
+// checked by CheckConfigForkOrder, that are active at the block with
+// [number] and [time] but not at its parent with [parentNumber] and
+// [parentTime].
+func (c *ChainConfig) ActivatedForks(parentNumber *big.Int, parentTime uint64, number *big.Int, time uint64) []string {
+	var activated []string
+	for _, f := range c.blockForks() {
+		if !utils.IsBlockForked(f.block, parentNumber) && utils.IsBlockForked(f.block, number) {
+			activated = append(activated, f.desc)
+		}
+	}
+	for _, f := range c.timestampForks() {
+		if !utils.IsTimestampForked(f.timestamp, parentTime) && utils.IsTimestampForked(f.timestamp, time) {
+			activated = append(activated, f.desc)
+		}
+	}
+	return activated
+}
+
+// LastActivatedTimestamp returns the activation timestamp and the
+// human-readable name of the last fork, in the order checked by
+// CheckConfigForkOrder, activated by block timestamp that is active at
+// [beforeTime]. [ok] is false if no such fork is active.
+func (c *ChainConfig) LastActivatedTimestamp(beforeTime uint64) (timestamp *uint64, upgradeName string, ok bool) {
+	for _, f := range c.timestampForks() {
+		if utils.IsTimestampForked(f.timestamp, beforeTime) {
+			timestamp, upgradeName, ok = f.timestamp, f.desc, true
+		}
+	}
+	return timestamp, upgradeName, ok
+}
+
 func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, height *big.Int, time uint64) *ConfigCompatError {
 	if isForkBlockIncompatible(c.HomesteadBlock, newcfg.HomesteadBlock, height) {
 		return newBlockCompatError("Homestead fork block", c.HomesteadBlock, newcfg.HomesteadBlock)
@@ -933,8 +1295,37 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, height *big.Int, time
 	if isForkTimestampIncompatible(c.DUpgradeBlockTimestamp, newcfg.DUpgradeBlockTimestamp, time) {
 		return newTimestampCompatError("DUpgrade fork block timestamp", c.DUpgradeBlockTimestamp, newcfg.DUpgradeBlockTimestamp)
 	}
+	if isForkTimestampIncompatible(c.EUpgradeBlockTimestamp, newcfg.EUpgradeBlockTimestamp, time) {
+		return newTimestampCompatError("EUpgrade fork block timestamp", c.EUpgradeBlockTimestamp, newcfg.EUpgradeBlockTimestamp)
+	}
+	if isForkTimestampIncompatible(c.OdyPhaseExtraChecksumTimestamp, newcfg.OdyPhaseExtraChecksumTimestamp, time) {
+		return newTimestampCompatError("OdyPhaseExtraChecksum fork block timestamp", c.OdyPhaseExtraChecksumTimestamp, newcfg.OdyPhaseExtraChecksumTimestamp)
+	}
+	if isForkTimestampIncompatible(c.OdyPhaseOrionSnapshotTimestamp, newcfg.OdyPhaseOrionSnapshotTimestamp, time) {
+		return newTimestampCompatError("OdyPhaseOrionSnapshot fork block timestamp", c.OdyPhaseOrionSnapshotTimestamp, newcfg.OdyPhaseOrionSnapshotTimestamp)
+	}
 	if isForkTimestampIncompatible(c.CancunTime, newcfg.CancunTime, time) {
-		return newTimestampCompatError("Cancun fork block timestamp", c.DUpgradeBlockTimestamp, newcfg.DUpgradeBlockTimestamp)
+		return newTimestampCompatError("Cancun fork block timestamp", c.CancunTime, newcfg.CancunTime)
+	}
+	if isForkTimestampIncompatible(c.TargetBlockRateTimestamp, newcfg.TargetBlockRateTimestamp, time) {
+		return newTimestampCompatError("TargetBlockRate activation timestamp", c.TargetBlockRateTimestamp, newcfg.TargetBlockRateTimestamp)
+	}
+	if c.GetTargetBlockRate(time) != newcfg.GetTargetBlockRate(time) {
+		return newTimestampCompatError("TargetBlockRate", c.TargetBlockRateTimestamp, newcfg.TargetBlockRateTimestamp)
+	}
+	if isForkTimestampIncompatible(c.FeeAllocationsTimestamp, newcfg.FeeAllocationsTimestamp, time) {
+		return newTimestampCompatError("FeeAllocations activation timestamp", c.FeeAllocationsTimestamp, newcfg.FeeAllocationsTimestamp)
+	}
+	if c.feeAllocations(time) != newcfg.feeAllocations(time) {
+		return newTimestampCompatError("FeeAllocations", c.FeeAllocationsTimestamp, newcfg.FeeAllocationsTimestamp)
+	}
+	for _, extras := range [][]ExtraEIP{c.ExtraEIPs, newcfg.ExtraEIPs} {
+		for _, extra := range extras {
+			stored, updated := c.extraEIPTimestamp(extra.EIP), newcfg.extraEIPTimestamp(extra.EIP)
+			if isForkTimestampIncompatible(stored, updated, time) {
+				return newTimestampCompatError(fmt.Sprintf("EIP-%d activation timestamp", extra.EIP), stored, updated)
+			}
+		}
 	}
 
 	return nil
@@ -1041,13 +1432,36 @@ func (err *ConfigCompatError) Error() string {
 	return fmt.Sprintf("mismatching %s in database (have timestamp %d, want timestamp %d, rewindto timestamp %d)", err.What, err.StoredTime, err.NewTime, err.RewindToTime)
 }
 
+// Suggestion returns a human-readable instruction describing how to rewind the
+// local chain to resolve the configuration mismatch reported by [err].
+func (err *ConfigCompatError) Suggestion() string {
+	isBlock, height, time := err.Rewind()
+	if isBlock {
+		return fmt.Sprintf("Run 'coreth --rewind-to-block %d' to fix this configuration mismatch", height)
+	}
+	return fmt.Sprintf("Run 'coreth --rewind-to-time %d' to fix this configuration mismatch", time)
+}
+
+// Rewind reports how the local chain must be rewound to resolve [err]. If the
+// mismatching fork is block based, isBlock is true and the chain must be
+// rewound to [height]. Otherwise the chain must be rewound to the last block
+// with a timestamp at or before [time]. The unused target is always zero.
+func (err *ConfigCompatError) Rewind() (isBlock bool, height uint64, time uint64) {
+	if err.StoredBlock != nil || err.NewBlock != nil {
+		return true, err.RewindToBlock, 0
+	}
+	return false, 0, err.RewindToTime
+}
+
 // Rules wraps ChainConfig and is merely syntactic sugar or can be used for functions
 // that do not have or require information about the block.
 //
 // Rules is a one time interface meaning that it shouldn't be used in between transition
 // phases.
 type Rules struct {
-	ChainID                                                 *big.Int
+	// chainID is shared with the ChainConfig, so that computing the rules of
+	// a block does not allocate. It is only exposed as a copy by ChainID.
+	chainID                                                 *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsCancun                                                bool
@@ -1058,13 +1472,23 @@ type Rules struct {
 	IsBanff                                                                             bool
 	IsCortina                                                                           bool
 	IsDUpgrade                                                                          bool
-
-	LpAllocation, GovernanceAllocation, AllocationDenominator *big.Int
-	OrionAllocation, MaxOrionAllocation                       *big.Int
-	PriorityFeeOrionAllocation                                *big.Int
+	IsEUpgrade                                                                          bool
+	IsOdyPhaseExtraChecksum                                                             bool
+	IsOdyPhaseOrionSnapshot                                                             bool
+
+	// Fee allocations are fixed-point shares of the fees, scaled by
+	// AllocationDenominator, which is a power of 10 (100_000 by default, so
+	// that each share has a precision of 0.001%).
+	LpAllocation, GovernanceAllocation, AllocationDenominator uint64
+	OrionAllocation, MaxOrionAllocation                       uint64
+	PriorityFeeOrionAllocation                                uint64
 	LpAddress, GovernanceAddress                              common.Address
 	OrionNodes                                                OrionNodesGetter
 
+	// ExtraEIPs are the EIPs scheduled by the chain config that are enabled for
+	// this rule set, in activation order.
+	ExtraEIPs []int
+
 	// Precompiles maps addresses to stateful precompiled contracts that are enabled
 	// for this rule set.
 	// Note: none of these addresses should conflict with the address space used by
@@ -1079,7 +1503,7 @@ func (c *ChainConfig) rules(num *big.Int, timestamp uint64) Rules {
 		chainID = new(big.Int)
 	}
 	return Rules{
-		ChainID:          new(big.Int).Set(chainID),
+		chainID:          chainID,
 		IsHomestead:      c.IsHomestead(num),
 		IsEIP150:         c.IsEIP150(num),
 		IsEIP155:         c.IsEIP155(num),
@@ -1092,9 +1516,12 @@ func (c *ChainConfig) rules(num *big.Int, timestamp uint64) Rules {
 	}
 }
 
-// OdysseyRules returns the Odyssey modified rules to support Odyssey
-// network upgrades
-func (c *ChainConfig) OdysseyRules(blockNum *big.Int, timestamp uint64) Rules {
+// ForkFlags returns the Rules at [blockNum] and [timestamp] with only ChainID
+// and the fork flags (IsHomestead through IsOdyPhaseOrionSnapshot) populated. Allocations,
+// addresses and OrionNodes are left zero and Precompiles is nil, so callers
+// that need any of those must use OdysseyRules instead. It is intended for hot
+// paths that only check which forks are active.
+func (c *ChainConfig) ForkFlags(blockNum *big.Int, timestamp uint64) Rules {
 	rules := c.rules(blockNum, timestamp)
 
 	rules.IsApricotPhase1 = c.IsApricotPhase1(timestamp)
@@ -1108,20 +1535,42 @@ func (c *ChainConfig) OdysseyRules(blockNum *big.Int, timestamp uint64) Rules {
 	rules.IsBanff = c.IsBanff(timestamp)
 	rules.IsCortina = c.IsCortina(timestamp)
 	rules.IsDUpgrade = c.IsDUpgrade(timestamp)
+	rules.IsEUpgrade = c.IsEUpgrade(timestamp)
+	rules.IsOdyPhaseExtraChecksum = c.IsOdyPhaseExtraChecksum(timestamp)
+	rules.IsOdyPhaseOrionSnapshot = c.IsOdyPhaseOrionSnapshot(timestamp)
+	return rules
+}
+
+// OdysseyRules returns the Odyssey modified rules to support Odyssey
+// network upgrades
+func (c *ChainConfig) OdysseyRules(blockNum *big.Int, timestamp uint64) Rules {
+	rules := c.ForkFlags(blockNum, timestamp)
+
 	rules.LpAddress = c.LpAddress(timestamp)
 	rules.GovernanceAddress = c.GovernanceAddress(timestamp)
-	rules.LpAllocation = c.LpAllocation(timestamp)
-	rules.GovernanceAllocation = c.GovernanceAllocation(timestamp)
-	rules.AllocationDenominator = c.AllocationDenominator(timestamp)
+	allocations := c.feeAllocations(timestamp)
+	rules.LpAllocation = allocations.lp
+	rules.GovernanceAllocation = allocations.governance
+	rules.AllocationDenominator = allocations.denominator
 	rules.OrionNodes = c.OrionNodesGetter(timestamp)
-	rules.OrionAllocation = c.OrionAllocation(timestamp)
-	rules.MaxOrionAllocation = c.MaxOrionAllocation(timestamp)
-	rules.PriorityFeeOrionAllocation = c.PriorityFeeOrionAllocation(timestamp)
+	rules.OrionAllocation = allocations.orion
+	rules.MaxOrionAllocation = allocations.maxOrion
+	rules.PriorityFeeOrionAllocation = allocations.priorityFeeOrion
+
+	for _, extra := range c.ExtraEIPs {
+		if extra.Timestamp <= timestamp {
+			rules.ExtraEIPs = append(rules.ExtraEIPs, extra.EIP)
+		}
+	}
 
 	// Initialize the stateful precompiles that should be enabled at [blockTimestamp].
-	rules.Precompiles = make(map[common.Address]precompile.StatefulPrecompiledContract)
-	for _, config := range c.enabledStatefulPrecompiles() {
+	// Precompiles is left nil if none are enabled, so that the rules of a chain
+	// without stateful precompiles are computed without heap allocations.
+	for _, config := range c.statefulPrecompiles {
 		if utils.IsTimestampForked(config.Timestamp(), timestamp) {
+			if rules.Precompiles == nil {
+				rules.Precompiles = make(map[common.Address]precompile.StatefulPrecompiledContract)
+			}
 			rules.Precompiles[config.Address()] = config.Contract()
 		}
 	}
@@ -1129,25 +1578,307 @@ func (c *ChainConfig) OdysseyRules(blockNum *big.Int, timestamp uint64) Rules {
 	return rules
 }
 
+// AtomicGasLimit returns the maximum cumulative gas the atomic txs of a block
+// may consume under [r]. The limit is only enforced as of ApricotPhase5.
+func (r *Rules) AtomicGasLimit() *big.Int {
+	if r.IsEUpgrade {
+		return EUpgradeAtomicGasLimit
+	}
+	return AtomicGasLimit
+}
+
+// ChainID returns a copy of the chain ID of [r].
+func (r *Rules) ChainID() *big.Int {
+	if r.chainID == nil {
+		return nil
+	}
+	return new(big.Int).Set(r.chainID)
+}
+
+// X2CRate returns the number of wei per nDIONE under [r]. A fork that
+// re-denominates DIONE must change the rate here, so that atomic txs convert
+// amounts at the rate of the block they are included in.
+func (r *Rules) X2CRate() *big.Int {
+	return new(big.Int).Set(x2cRateBig)
+}
+
+// Equal returns true if [r] and [other] enable the same forks, allocations,
+// addresses and precompile addresses. See Diff.
+func (r Rules) Equal(other Rules) bool {
+	return len(r.Diff(other)) == 0
+}
+
+// Diff returns a description of every field of [r] that differs from
+// [other], in field order, followed by the precompile addresses enabled in
+// only one of them in address order. Precompiles are compared by address
+// only, OrionNodes by the contract slots they read, and any other field by
+// deep equality.
+func (r Rules) Diff(other Rules) []string {
+	var (
+		diffs  []string
+		rv     = reflect.ValueOf(r)
+		ov     = reflect.ValueOf(other)
+		rulesT = rv.Type()
+	)
+	if a, b := r.chainID, other.chainID; (a == nil) != (b == nil) || (a != nil && a.Cmp(b) != 0) {
+		diffs = append(diffs, fmt.Sprintf("ChainID: %v != %v", a, b))
+	}
+	for i := 0; i < rulesT.NumField(); i++ {
+		if !rulesT.Field(i).IsExported() {
+			continue
+		}
+		name := rulesT.Field(i).Name
+		a, b := rv.Field(i).Interface(), ov.Field(i).Interface()
+		if rulesT.Field(i).Type == orionNodesGetterType {
+			a, _ := a.(OrionNodesGetter)
+			b, _ := b.(OrionNodesGetter)
+			if !equalOrionNodesGetters(a, b) {
+				diffs = append(diffs, fmt.Sprintf("%s: %v != %v", name, a, b))
+			}
+			continue
+		}
+		switch a := a.(type) {
+		case *big.Int:
+			b := b.(*big.Int)
+			if (a == nil) != (b == nil) || (a != nil && a.Cmp(b) != 0) {
+				diffs = append(diffs, fmt.Sprintf("%s: %v != %v", name, a, b))
+			}
+		case map[common.Address]precompile.StatefulPrecompiledContract:
+			diffs = append(diffs, diffPrecompiles(a, b.(map[common.Address]precompile.StatefulPrecompiledContract))...)
+		case []int:
+			if b := b.([]int); !equalInts(a, b) {
+				diffs = append(diffs, fmt.Sprintf("%s: %v != %v", name, a, b))
+			}
+		default:
+			if !reflect.DeepEqual(a, b) {
+				diffs = append(diffs, fmt.Sprintf("%s: %v != %v", name, a, b))
+			}
+		}
+	}
+	return diffs
+}
+
+// DiffFrom returns a description of every fork flag that changes from [other]
+// to [r], in field order, such as "IsBanff: false → true". It is intended for
+// logging the rules that change between consecutive blocks. Unlike Diff, only
+// the bool fields are compared.
+func (r Rules) DiffFrom(other Rules) []string {
+	var (
+		diffs  []string
+		rv     = reflect.ValueOf(r)
+		ov     = reflect.ValueOf(other)
+		rulesT = rv.Type()
+	)
+	for i := 0; i < rulesT.NumField(); i++ {
+		field := rulesT.Field(i)
+		if field.Type.Kind() != reflect.Bool {
+			continue
+		}
+		from, to := ov.Field(i).Bool(), rv.Field(i).Bool()
+		if from != to {
+			diffs = append(diffs, fmt.Sprintf("%s: %t → %t", field.Name, from, to))
+		}
+	}
+	return diffs
+}
+
+// ForkFlagsMap returns every bool field of [r] by field name, such as
+// "IsBanff".
+func (r Rules) ForkFlagsMap() map[string]bool {
+	var (
+		flags  = make(map[string]bool)
+		rv     = reflect.ValueOf(r)
+		rulesT = rv.Type()
+	)
+	for i := 0; i < rulesT.NumField(); i++ {
+		field := rulesT.Field(i)
+		if field.Type.Kind() == reflect.Bool {
+			flags[field.Name] = rv.Field(i).Bool()
+		}
+	}
+	return flags
+}
+
+// PrecompileAddresses returns the addresses of the stateful precompiles
+// enabled by [r] in address order.
+func (r Rules) PrecompileAddresses() []common.Address {
+	addrs := make([]common.Address, 0, len(r.Precompiles))
+	for addr := range r.Precompiles {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs
+}
+
+var orionNodesGetterType = reflect.TypeOf((*OrionNodesGetter)(nil)).Elem()
+
+// equalOrionNodesGetters returns whether [a] and [b] read the orion nodes list
+// from the same contract slots. Their caches are not compared.
+func equalOrionNodesGetters(a, b OrionNodesGetter) bool {
+	ag, aok := a.(*orionNodesGetter)
+	bg, bok := b.(*orionNodesGetter)
+	if !aok || !bok || ag == nil || bg == nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return ag.contract == bg.contract && ag.lastUpdateSlot == bg.lastUpdateSlot && ag.sizeSlot == bg.sizeSlot
+}
+
+// equalInts returns whether [a] and [b] hold the same values in the same order.
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// diffPrecompiles describes the addresses enabled in only one of [a] and [b].
+func diffPrecompiles(a, b map[common.Address]precompile.StatefulPrecompiledContract) []string {
+	var addrs []common.Address
+	for addr := range a {
+		if _, ok := b[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	for addr := range b {
+		if _, ok := a[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+
+	diffs := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		_, enabled := a[addr]
+		diffs = append(diffs, fmt.Sprintf("Precompiles[%s]: %t != %t", addr, enabled, !enabled))
+	}
+	return diffs
+}
+
+// feeAllocations are the fee allocations of the rules of a block.
+type feeAllocations struct {
+	lp, governance, denominator       uint64
+	orion, maxOrion, priorityFeeOrion uint64
+}
+
+// feeAllocations returns the fee allocations in effect at [time].
+func (c *ChainConfig) feeAllocations(time uint64) feeAllocations {
+	active := utils.IsTimestampForked(c.FeeAllocationsTimestamp, time)
+	return feeAllocations{
+		lp:               allocationOrDefault(active, c.LpAllocation, LpAllocation),
+		governance:       allocationOrDefault(active, c.GovernanceAllocation, GovernanceAllocation),
+		denominator:      allocationOrDefault(active, c.AllocationDenominator, AllocationDenominator),
+		orion:            allocationOrDefault(active, c.OrionAllocation, OrionAllocation),
+		maxOrion:         allocationOrDefault(active, c.MaxOrionAllocation, MaxOrionAllocation),
+		priorityFeeOrion: allocationOrDefault(active, c.PriorityFeeOrionAllocation, PriorityFeeOrionAllocation),
+	}
+}
+
+// allocationOrDefault returns [allocation] as a uint64, or [defaultAllocation]
+// if the allocations of the config are not [active] yet or [allocation] is not
+// set. The allocations of a config are checked to fit in a uint64 by
+// CheckConfigForkOrder.
+func allocationOrDefault(active bool, allocation, defaultAllocation *big.Int) uint64 {
+	if !active || allocation == nil {
+		return defaultAllocation.Uint64()
+	}
+	return allocation.Uint64()
+}
+
+// AllocationBig returns [allocation] as a *big.Int, for computing the share
+// of a fee amount in wei.
+func AllocationBig(allocation uint64) *big.Int {
+	return new(big.Int).SetUint64(allocation)
+}
+
 // enabledStatefulPrecompiles returns a list of stateful precompile configs in the order that they are enabled
 // by block timestamp.
 // Note: the return value does not include the native precompiles [nativeAssetCall] and [nativeAssetBalance].
 // These are handled in [delta.precompile] directly.
 func (c *ChainConfig) enabledStatefulPrecompiles() []precompile.StatefulPrecompileConfig {
-	statefulPrecompileConfigs := make([]precompile.StatefulPrecompileConfig, 0)
+	statefulPrecompileConfigs := make([]precompile.StatefulPrecompileConfig, 0, len(c.statefulPrecompiles))
+	statefulPrecompileConfigs = append(statefulPrecompileConfigs, c.statefulPrecompiles...)
 
 	return statefulPrecompileConfigs
 }
 
+// RegisterPrecompile enables the stateful precompile [config] at its timestamp.
+// The address of [config] must be within [precompile.ReservedRanges] and must
+// not be used by a native precompile or by any other registered precompile.
+// RegisterPrecompile must be called before the chain config is used to process
+// blocks, and the same precompiles must be registered on every node of the
+// network.
+func (c *ChainConfig) RegisterPrecompile(config precompile.StatefulPrecompileConfig) error {
+	if config == nil {
+		return errNilPrecompileConfig
+	}
+	addr := config.Address()
+	timestamp := config.Timestamp()
+	if timestamp == nil {
+		return fmt.Errorf("%w: %s", errPrecompileNeverEnabled, addr)
+	}
+
+	reserved := false
+	for _, reservedRange := range precompile.ReservedRanges {
+		if reservedRange.Contains(addr) {
+			reserved = true
+			break
+		}
+	}
+	if !reserved {
+		return fmt.Errorf("%w: %s", errPrecompileAddressNotReserved, addr)
+	}
+	for _, used := range precompile.NativeAddresses {
+		if used == addr {
+			return fmt.Errorf("%w: %s is a native precompile", errPrecompileAddressConflict, addr)
+		}
+	}
+	for _, used := range precompile.UsedAddresses {
+		if used == addr {
+			return fmt.Errorf("%w: %s", errPrecompileAddressConflict, addr)
+		}
+	}
+	for _, registered := range c.statefulPrecompiles {
+		if registered.Address() == addr {
+			return fmt.Errorf("%w: %s", errPrecompileAddressConflict, addr)
+		}
+	}
+
+	// Keep the precompiles ordered by the timestamp they are enabled at, so
+	// that precompiles enabled in the same block are configured in the order
+	// they were registered.
+	i := len(c.statefulPrecompiles)
+	for i > 0 && *c.statefulPrecompiles[i-1].Timestamp() > *timestamp {
+		i--
+	}
+	c.statefulPrecompiles = append(c.statefulPrecompiles, nil)
+	copy(c.statefulPrecompiles[i+1:], c.statefulPrecompiles[i:])
+	c.statefulPrecompiles[i] = config
+	return nil
+}
+
 // CheckConfigurePrecompiles checks if any of the precompiles specified in the chain config are enabled by the block
 // transition from [parentTimestamp] to the timestamp set in [blockContext]. If this is the case, it calls [Configure]
 // to apply the necessary state transitions for the upgrade.
 // This function is called:
 // - within genesis setup to configure the starting state for precompiles enabled at genesis,
 // - during block processing to update the state before processing the given block.
-func (c *ChainConfig) CheckConfigurePrecompiles(parentTimestamp *uint64, blockContext precompile.BlockContext, statedb precompile.StateDB) {
+// Returns the first error encountered configuring a precompile, in which case [statedb]
+// may be partially configured and must be discarded.
+func (c *ChainConfig) CheckConfigurePrecompiles(parentTimestamp *uint64, blockContext precompile.BlockContext, statedb precompile.StateDB) error {
 	// Iterate the enabled stateful precompiles and configure them if needed
 	for _, config := range c.enabledStatefulPrecompiles() {
-		precompile.CheckConfigure(c, parentTimestamp, blockContext, config, statedb)
+		if err := precompile.CheckConfigure(c, parentTimestamp, blockContext, config, statedb); err != nil {
+			return err
+		}
 	}
+	return nil
 }
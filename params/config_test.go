@@ -27,13 +27,20 @@
 package params
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"math"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/DioneProtocol/coreth/precompile"
 	"github.com/DioneProtocol/coreth/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -143,6 +150,101 @@ func TestCheckCompatible(t *testing.T) {
 	}
 }
 
+func TestCheckCompatibleTimestampForks(t *testing.T) {
+	tests := []struct {
+		what string
+		set  func(c *ChainConfig, timestamp *uint64)
+	}{
+		{"ApricotPhase1 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhase1BlockTimestamp = ts }},
+		{"ApricotPhase2 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhase2BlockTimestamp = ts }},
+		{"ApricotPhase3 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhase3BlockTimestamp = ts }},
+		{"ApricotPhase4 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhase4BlockTimestamp = ts }},
+		{"ApricotPhase5 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhase5BlockTimestamp = ts }},
+		{"ApricotPhasePre6 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhasePre6BlockTimestamp = ts }},
+		{"ApricotPhase6 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhase6BlockTimestamp = ts }},
+		{"ApricotPhasePost6 fork block timestamp", func(c *ChainConfig, ts *uint64) { c.ApricotPhasePost6BlockTimestamp = ts }},
+		{"Banff fork block timestamp", func(c *ChainConfig, ts *uint64) { c.BanffBlockTimestamp = ts }},
+		{"Cortina fork block timestamp", func(c *ChainConfig, ts *uint64) { c.CortinaBlockTimestamp = ts }},
+		{"DUpgrade fork block timestamp", func(c *ChainConfig, ts *uint64) { c.DUpgradeBlockTimestamp = ts }},
+		{"EUpgrade fork block timestamp", func(c *ChainConfig, ts *uint64) { c.EUpgradeBlockTimestamp = ts }},
+		{"OdyPhaseExtraChecksum fork block timestamp", func(c *ChainConfig, ts *uint64) { c.OdyPhaseExtraChecksumTimestamp = ts }},
+		{"OdyPhaseOrionSnapshot fork block timestamp", func(c *ChainConfig, ts *uint64) { c.OdyPhaseOrionSnapshotTimestamp = ts }},
+		{"Cancun fork block timestamp", func(c *ChainConfig, ts *uint64) { c.CancunTime = ts }},
+	}
+
+	for _, test := range tests {
+		stored, new := &ChainConfig{}, &ChainConfig{}
+		test.set(stored, utils.NewUint64(10))
+		test.set(new, utils.NewUint64(20))
+		wantErr := &ConfigCompatError{
+			What:         test.what,
+			StoredTime:   utils.NewUint64(10),
+			NewTime:      utils.NewUint64(20),
+			RewindToTime: 9,
+		}
+
+		err := stored.CheckCompatible(new, 0, 15)
+		if !reflect.DeepEqual(err, wantErr) {
+			t.Errorf("%s: error mismatch:\nerr: %v\nwant: %v", test.what, err, wantErr)
+		}
+	}
+}
+
+func TestConfigCompatErrorSuggestion(t *testing.T) {
+	stored, new := &ChainConfig{}, &ChainConfig{}
+	stored.IstanbulBlock, new.IstanbulBlock = big.NewInt(30), big.NewInt(20)
+	err := stored.CheckCompatible(new, 25, 0)
+	if err == nil {
+		t.Fatal("expected block based compat error")
+	}
+	if want := "Run 'coreth --rewind-to-block 19' to fix this configuration mismatch"; err.Suggestion() != want {
+		t.Errorf("block suggestion mismatch:\nhave: %s\nwant: %s", err.Suggestion(), want)
+	}
+
+	stored, new = &ChainConfig{}, &ChainConfig{}
+	stored.CortinaBlockTimestamp, new.CortinaBlockTimestamp = utils.NewUint64(10), utils.NewUint64(20)
+	err = stored.CheckCompatible(new, 0, 15)
+	if err == nil {
+		t.Fatal("expected timestamp based compat error")
+	}
+	if want := "Run 'coreth --rewind-to-time 9' to fix this configuration mismatch"; err.Suggestion() != want {
+		t.Errorf("timestamp suggestion mismatch:\nhave: %s\nwant: %s", err.Suggestion(), want)
+	}
+}
+
+func TestConfigCompatErrorRewind(t *testing.T) {
+	stored, new := &ChainConfig{}, &ChainConfig{}
+	stored.IstanbulBlock, new.IstanbulBlock = big.NewInt(30), big.NewInt(20)
+	err := stored.CheckCompatible(new, 25, 100)
+	if err == nil {
+		t.Fatal("expected block based compat error")
+	}
+	if isBlock, height, time := err.Rewind(); !isBlock || height != 19 || time != 0 {
+		t.Errorf("block rewind mismatch: have (%t, %d, %d), want (true, 19, 0)", isBlock, height, time)
+	}
+
+	// A block based fork activated at genesis is rewound to block 0.
+	stored, new = &ChainConfig{}, &ChainConfig{}
+	stored.IstanbulBlock = big.NewInt(0)
+	err = stored.CheckCompatible(new, 25, 0)
+	if err == nil {
+		t.Fatal("expected block based compat error")
+	}
+	if isBlock, height, time := err.Rewind(); !isBlock || height != 0 || time != 0 {
+		t.Errorf("genesis block rewind mismatch: have (%t, %d, %d), want (true, 0, 0)", isBlock, height, time)
+	}
+
+	stored, new = &ChainConfig{}, &ChainConfig{}
+	stored.CortinaBlockTimestamp, new.CortinaBlockTimestamp = utils.NewUint64(10), utils.NewUint64(20)
+	err = stored.CheckCompatible(new, 0, 15)
+	if err == nil {
+		t.Fatal("expected timestamp based compat error")
+	}
+	if isBlock, height, time := err.Rewind(); isBlock || height != 0 || time != 9 {
+		t.Errorf("timestamp rewind mismatch: have (%t, %d, %d), want (false, 0, 9)", isBlock, height, time)
+	}
+}
+
 func TestConfigRules(t *testing.T) {
 	c := &ChainConfig{
 		CortinaBlockTimestamp: utils.NewUint64(500),
@@ -160,3 +262,1088 @@ func TestConfigRules(t *testing.T) {
 		t.Errorf("expected %v to be cortina", stamp)
 	}
 }
+
+func TestForkFlags(t *testing.T) {
+	c := *TestChainConfig
+	c.EUpgradeBlockTimestamp = utils.NewUint64(500)
+	for _, stamp := range []uint64{0, 499, 500, math.MaxInt64} {
+		flags := c.ForkFlags(big.NewInt(0), stamp)
+		if flags.Precompiles != nil {
+			t.Errorf("expected nil precompiles at %d, have %v", stamp, flags.Precompiles)
+		}
+
+		// ForkFlags must agree with OdysseyRules on everything it populates.
+		want := c.OdysseyRules(big.NewInt(0), stamp)
+		want.LpAllocation, want.GovernanceAllocation, want.AllocationDenominator = 0, 0, 0
+		want.OrionAllocation, want.MaxOrionAllocation, want.PriorityFeeOrionAllocation = 0, 0, 0
+		want.LpAddress, want.GovernanceAddress = common.Address{}, common.Address{}
+		want.OrionNodes = nil
+		want.Precompiles = nil
+		if !reflect.DeepEqual(flags, want) {
+			t.Errorf("fork flags mismatch at %d:\nhave: %+v\nwant: %+v", stamp, flags, want)
+		}
+	}
+}
+
+func TestDescriptionListsEveryFork(t *testing.T) {
+	var (
+		config   = &ChainConfig{ChainID: big.NewInt(1)}
+		value    = reflect.ValueOf(config).Elem()
+		markers  = make(map[string]string)
+		tableSet = make(map[string]bool)
+	)
+	// Set every fork field to a distinct value, so that each one can be found in
+	// the banner.
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "chainId" || name == "allocationDenominator" || strings.HasSuffix(name, "Allocation") || name == "feeAllocationsTimestamp" || strings.HasPrefix(name, "targetBlockRate") {
+			continue
+		}
+		forkValue := uint64(1000 + i)
+		switch field.Type {
+		case reflect.TypeOf((*big.Int)(nil)):
+			value.Field(i).Set(reflect.ValueOf(new(big.Int).SetUint64(forkValue)))
+			markers[name] = fmt.Sprintf("#%d ", forkValue)
+		case reflect.TypeOf((*uint64)(nil)):
+			value.Field(i).Set(reflect.ValueOf(utils.NewUint64(forkValue)))
+			markers[name] = fmt.Sprintf("@%d ", forkValue)
+		}
+	}
+
+	banner := config.Description()
+	for name, marker := range markers {
+		if count := strings.Count(banner, marker); count != 1 {
+			t.Errorf("expected fork %s to appear once in the banner, found %d times:\n%s", name, count, banner)
+		}
+	}
+
+	// Every fork must also be covered by the fork ordering checks.
+	for _, f := range append(config.blockForks(), config.timestampForks()...) {
+		tableSet[f.name] = true
+	}
+	for name := range markers {
+		if !tableSet[name] {
+			t.Errorf("fork %s is missing from the fork table", name)
+		}
+	}
+	if len(tableSet) != len(markers) {
+		t.Errorf("fork table has %d forks, but ChainConfig has %d fork fields", len(tableSet), len(markers))
+	}
+}
+
+func TestDescriptionAlignsForks(t *testing.T) {
+	column := -1
+	for _, line := range strings.Split(TestChainConfig.Description(), "\n") {
+		if !strings.HasPrefix(line, " - ") {
+			continue
+		}
+		// The activation of every fork starts in the same column, however long
+		// the description of the fork is.
+		i := strings.IndexAny(line, "#@")
+		if column == -1 {
+			column = i
+		}
+		if i != column || line[i-1] != ' ' {
+			t.Errorf("expected activation at column %d after the description, have %q", column, line)
+		}
+		if strings.HasSuffix(line, "/)") {
+			t.Errorf("expected no bare release URL, have %q", line)
+		}
+	}
+}
+
+func TestChainConfigUnmarshalText(t *testing.T) {
+	t.Run("empty config", func(t *testing.T) {
+		text, err := TestChainConfig.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &ChainConfig{}
+		if err := c.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if c.String() != TestChainConfig.String() {
+			t.Errorf("config mismatch:\nhave: %v\nwant: %v", c, TestChainConfig)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		c := *TestCortinaChainConfig
+		if err := c.UnmarshalText([]byte(`{"dUpgradeBlockTimestamp":100}`)); err != nil {
+			t.Fatal(err)
+		}
+		if c.DUpgradeBlockTimestamp == nil || *c.DUpgradeBlockTimestamp != 100 {
+			t.Errorf("expected DUpgrade at 100, have %v", c.DUpgradeBlockTimestamp)
+		}
+		if c.ChainID.Cmp(TestCortinaChainConfig.ChainID) != 0 || !c.IsCortina(0) {
+			t.Errorf("expected missing fields to keep their defaults, have %v", &c)
+		}
+		if c.OdysseyContext != TestCortinaChainConfig.OdysseyContext {
+			t.Errorf("expected odyssey context to be kept, have %v", c.OdysseyContext)
+		}
+		if TestCortinaChainConfig.DUpgradeBlockTimestamp != nil {
+			t.Error("defaults were modified")
+		}
+	})
+
+	t.Run("incompatible with defaults", func(t *testing.T) {
+		c := *TestCortinaChainConfig
+		err := c.UnmarshalText([]byte(`{"cortinaBlockTimestamp":100}`))
+		var compatErr *ConfigCompatError
+		if !errors.As(err, &compatErr) || compatErr.What != "Cortina fork block timestamp" {
+			t.Errorf("expected Cortina compatibility error, have %v", err)
+		}
+		if !c.IsCortina(0) {
+			t.Error("config was modified on error")
+		}
+	})
+
+	t.Run("invalid fork order", func(t *testing.T) {
+		c := &ChainConfig{}
+		if err := c.UnmarshalText([]byte(`{"chainId":1,"banffBlockTimestamp":0}`)); err == nil {
+			t.Error("expected fork ordering error")
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		c := &ChainConfig{}
+		if err := c.UnmarshalText([]byte(`{"chainId":`)); err == nil {
+			t.Error("expected decoding error")
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		c := &ChainConfig{}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Var(c, "chain-config", "chain config")
+		if err := fs.Parse([]string{"--chain-config", `{"chainId":153}`}); err != nil {
+			t.Fatal(err)
+		}
+		if c.ChainID == nil || c.ChainID.Int64() != 153 {
+			t.Errorf("expected chain ID 153, have %v", c.ChainID)
+		}
+	})
+}
+
+func FuzzChainConfigTextRoundTrip(f *testing.F) {
+	for _, c := range []*ChainConfig{TestChainConfig, TestLaunchConfig, TestCortinaChainConfig, TestDUpgradeChainConfig} {
+		text, err := c.MarshalText()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(text)
+	}
+	f.Add([]byte(`{"chainId":153,"apricotPhase1BlockTimestamp":10,"apricotPhase2BlockTimestamp":20}`))
+
+	f.Fuzz(func(t *testing.T, text []byte) {
+		c := &ChainConfig{}
+		if err := c.UnmarshalText(text); err != nil {
+			return
+		}
+		encoded, err := c.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := &ChainConfig{}
+		if err := decoded.UnmarshalText(encoded); err != nil {
+			t.Fatalf("failed to decode %s: %v", encoded, err)
+		}
+		reencoded, err := decoded.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != string(reencoded) {
+			t.Errorf("round trip mismatch:\nhave: %s\nwant: %s", reencoded, encoded)
+		}
+	})
+}
+
+func TestCheckConfigForkOrder(t *testing.T) {
+	withForks := func(base *ChainConfig, set func(c *ChainConfig)) *ChainConfig {
+		c := *base
+		set(&c)
+		return &c
+	}
+	tests := []struct {
+		name    string
+		config  *ChainConfig
+		wantErr string
+	}{
+		{
+			name:   "all forks at genesis",
+			config: TestChainConfig,
+		},
+		{
+			name:   "no forks",
+			config: &ChainConfig{},
+		},
+		{
+			name: "block fork after genesis",
+			config: withForks(TestChainConfig, func(c *ChainConfig) {
+				c.IstanbulBlock = big.NewInt(5)
+			}),
+			wantErr: "istanbulBlock enabled at 5",
+		},
+		{
+			name: "timestamp fork without block forks",
+			config: withForks(TestChainConfig, func(c *ChainConfig) {
+				c.PetersburgBlock = nil
+				c.IstanbulBlock = nil
+				c.MuirGlacierBlock = nil
+			}),
+			wantErr: "petersburgBlock not enabled, but apricotPhase1BlockTimestamp enabled at 0",
+		},
+		{
+			name: "cancun without dupgrade",
+			config: withForks(TestCortinaChainConfig, func(c *ChainConfig) {
+				c.CancunTime = utils.NewUint64(10)
+			}),
+			wantErr: "dUpgradeBlockTimestamp not enabled, but cancunTime enabled at 10",
+		},
+		{
+			name: "cancun before dupgrade",
+			config: withForks(TestCortinaChainConfig, func(c *ChainConfig) {
+				c.DUpgradeBlockTimestamp = utils.NewUint64(20)
+				c.CancunTime = utils.NewUint64(10)
+			}),
+			wantErr: "dUpgradeBlockTimestamp enabled at 20, but cancunTime enabled at 10",
+		},
+		{
+			name: "dupgrade without cortina",
+			config: withForks(TestCortinaChainConfig, func(c *ChainConfig) {
+				c.CortinaBlockTimestamp = nil
+				c.DUpgradeBlockTimestamp = utils.NewUint64(10)
+			}),
+			wantErr: "cortinaBlockTimestamp not enabled, but dUpgradeBlockTimestamp enabled at 10",
+		},
+		{
+			name: "cancun at dupgrade",
+			config: withForks(TestCortinaChainConfig, func(c *ChainConfig) {
+				c.DUpgradeBlockTimestamp = utils.NewUint64(10)
+				c.CancunTime = utils.NewUint64(10)
+			}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.CheckConfigForkOrder()
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("expected error containing %q, have %v", test.wantErr, err)
+			}
+		})
+	}
+
+	err := withForks(TestChainConfig, func(c *ChainConfig) {
+		c.HomesteadBlock = big.NewInt(1)
+	}).CheckConfigForkOrder()
+	if !errors.Is(err, errNonGenesisForkByHeight) {
+		t.Errorf("expected %v, have %v", errNonGenesisForkByHeight, err)
+	}
+}
+
+func TestOdysseyRulesAllocations(t *testing.T) {
+	allocations := []struct {
+		name       string
+		set        func(c *ChainConfig, allocation *big.Int)
+		get        func(r Rules) uint64
+		defaultVal *big.Int
+	}{
+		{"LpAllocation", func(c *ChainConfig, a *big.Int) { c.LpAllocation = a }, func(r Rules) uint64 { return r.LpAllocation }, LpAllocation},
+		{"GovernanceAllocation", func(c *ChainConfig, a *big.Int) { c.GovernanceAllocation = a }, func(r Rules) uint64 { return r.GovernanceAllocation }, GovernanceAllocation},
+		{"OrionAllocation", func(c *ChainConfig, a *big.Int) { c.OrionAllocation = a }, func(r Rules) uint64 { return r.OrionAllocation }, OrionAllocation},
+		{"MaxOrionAllocation", func(c *ChainConfig, a *big.Int) { c.MaxOrionAllocation = a }, func(r Rules) uint64 { return r.MaxOrionAllocation }, MaxOrionAllocation},
+		{"PriorityFeeOrionAllocation", func(c *ChainConfig, a *big.Int) { c.PriorityFeeOrionAllocation = a }, func(r Rules) uint64 { return r.PriorityFeeOrionAllocation }, PriorityFeeOrionAllocation},
+		{"AllocationDenominator", func(c *ChainConfig, a *big.Int) { c.AllocationDenominator = a }, func(r Rules) uint64 { return r.AllocationDenominator }, AllocationDenominator},
+	}
+	for i, allocation := range allocations {
+		t.Run(allocation.name, func(t *testing.T) {
+			c := *TestChainConfig
+			if got := allocation.get(c.OdysseyRules(big.NewInt(0), 0)); got != allocation.defaultVal.Uint64() {
+				t.Errorf("expected default %v, have %v", allocation.defaultVal, got)
+			}
+
+			want := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(3+i)), nil)
+			allocation.set(&c, want)
+			if err := c.CheckConfigForkOrder(); !errors.Is(err, errMissingFeeAllocationsTimestamp) {
+				t.Errorf("expected %v, have %v", errMissingFeeAllocationsTimestamp, err)
+			}
+			c.FeeAllocationsTimestamp = utils.NewUint64(100)
+			if err := c.CheckConfigForkOrder(); err != nil {
+				t.Errorf("expected valid allocations, have %v", err)
+			}
+			if got := allocation.get(c.OdysseyRules(big.NewInt(0), 99)); got != allocation.defaultVal.Uint64() {
+				t.Errorf("expected default %v before activation, have %v", allocation.defaultVal, got)
+			}
+			if got := allocation.get(c.OdysseyRules(big.NewInt(0), 100)); got != want.Uint64() {
+				t.Errorf("expected %v, have %v", want, got)
+			}
+
+			// Allocations that do not fit in the rules are rejected.
+			allocation.set(&c, new(big.Int).Lsh(common.Big1, 64))
+			if err := c.CheckConfigForkOrder(); !errors.Is(err, errInvalidAllocation) {
+				t.Errorf("expected %v, have %v", errInvalidAllocation, err)
+			}
+			allocation.set(&c, big.NewInt(-1))
+			if err := c.CheckConfigForkOrder(); !errors.Is(err, errInvalidAllocation) {
+				t.Errorf("expected %v, have %v", errInvalidAllocation, err)
+			}
+		})
+	}
+
+	// The denominator must be a power of 10.
+	for _, denominator := range []int64{0, 2, 99, 1_001, 100_100} {
+		c := *TestChainConfig
+		c.AllocationDenominator = big.NewInt(denominator)
+		c.FeeAllocationsTimestamp = utils.NewUint64(0)
+		if err := c.CheckConfigForkOrder(); !errors.Is(err, errInvalidAllocationDenominator) {
+			t.Errorf("expected %v for denominator %d, have %v", errInvalidAllocationDenominator, denominator, err)
+		}
+	}
+	for _, denominator := range []int64{1, 10, 100_000, 1_000_000_000} {
+		c := *TestChainConfig
+		c.AllocationDenominator = big.NewInt(denominator)
+		c.FeeAllocationsTimestamp = utils.NewUint64(0)
+		if err := c.CheckConfigForkOrder(); err != nil {
+			t.Errorf("expected denominator %d to be valid, have %v", denominator, err)
+		}
+	}
+}
+
+func TestCheckCompatibleFeeAllocations(t *testing.T) {
+	stored := *TestChainConfig
+	stored.LpAllocation = big.NewInt(20_000)
+	stored.FeeAllocationsTimestamp = utils.NewUint64(100)
+
+	tests := []struct {
+		name     string
+		lp       int64
+		activate uint64
+		head     uint64
+		wantErr  string
+	}{
+		{name: "scheduled allocation changed", lp: 10_000, activate: 100, head: 99},
+		{name: "scheduled activation moved", lp: 20_000, activate: 200, head: 99},
+		{name: "active allocation changed", lp: 10_000, activate: 100, head: 100, wantErr: "FeeAllocations"},
+		{name: "active activation moved", lp: 20_000, activate: 200, head: 150, wantErr: "FeeAllocations activation timestamp"},
+		{name: "unchanged", lp: 20_000, activate: 100, head: 150},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newcfg := stored
+			newcfg.LpAllocation = big.NewInt(test.lp)
+			newcfg.FeeAllocationsTimestamp = utils.NewUint64(test.activate)
+			err := stored.CheckCompatible(&newcfg, 0, test.head)
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Errorf("expected compatible configs, have %v", err)
+			case test.wantErr != "" && (err == nil || err.What != test.wantErr):
+				t.Errorf("expected %q incompatibility, have %v", test.wantErr, err)
+			case test.wantErr != "" && err.RewindToTime != 99:
+				t.Errorf("expected rewind to 99, have %d", err.RewindToTime)
+			}
+		})
+	}
+}
+
+// The rules of a chain without stateful precompiles or extra EIPs are computed
+// without any heap allocation.
+func TestOdysseyRulesAllocs(t *testing.T) {
+	c := *TestChainConfig
+	if allocs := testing.AllocsPerRun(100, func() {
+		c.OdysseyRules(common.Big0, 0)
+	}); allocs != 0 {
+		t.Errorf("expected no allocations, have %v", allocs)
+	}
+}
+
+func TestRulesChainID(t *testing.T) {
+	c := *TestChainConfig
+	c.ChainID = big.NewInt(1)
+	rules := c.OdysseyRules(common.Big0, 0)
+	chainID := rules.ChainID()
+	if chainID.Cmp(c.ChainID) != 0 {
+		t.Fatalf("expected chain ID %d, have %d", c.ChainID, chainID)
+	}
+	// Modifying the returned chain ID modifies neither the rules nor the config.
+	chainID.SetInt64(2)
+	if rules.ChainID().Int64() != 1 || c.ChainID.Int64() != 1 {
+		t.Fatalf("expected chain ID 1, have %d in rules and %d in config", rules.ChainID(), c.ChainID)
+	}
+}
+
+func BenchmarkOdysseyRules(b *testing.B) {
+	c := *TestChainConfig
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.OdysseyRules(common.Big0, 0)
+	}
+}
+
+func TestChainConfigAllocationsJSON(t *testing.T) {
+	c := *TestChainConfig
+	c.LpAllocation = big.NewInt(1)
+	c.GovernanceAllocation = big.NewInt(2)
+	c.OrionAllocation = big.NewInt(3)
+	c.MaxOrionAllocation = big.NewInt(4)
+	c.PriorityFeeOrionAllocation = big.NewInt(5)
+	c.AllocationDenominator = big.NewInt(6)
+	c.FeeAllocationsTimestamp = utils.NewUint64(7)
+
+	encoded, err := json.Marshal(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"lpAllocation":1`, `"governanceAllocation":2`, `"orionAllocation":3`, `"maxOrionAllocation":4`, `"priorityFeeOrionAllocation":5`, `"allocationDenominator":6`, `"feeAllocationsTimestamp":7`} {
+		if !strings.Contains(string(encoded), field) {
+			t.Errorf("expected %s in %s", field, encoded)
+		}
+	}
+
+	decoded := &ChainConfig{}
+	if err := json.Unmarshal(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	decoded.OdysseyContext = c.OdysseyContext
+	if !reflect.DeepEqual(decoded, &c) {
+		t.Errorf("round trip mismatch:\nhave: %v\nwant: %v", decoded, &c)
+	}
+
+	encoded, err = json.Marshal(TestChainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encoded), "Allocation") {
+		t.Errorf("expected unset allocations to be omitted, have %s", encoded)
+	}
+}
+
+// testPrecompileConfig is a stateful precompile config that can be registered
+// with a chain config.
+type testPrecompileConfig struct {
+	addr      common.Address
+	timestamp *uint64
+}
+
+func (c *testPrecompileConfig) Address() common.Address { return c.addr }
+func (c *testPrecompileConfig) Timestamp() *uint64      { return c.timestamp }
+func (*testPrecompileConfig) Configure(precompile.ChainConfig, precompile.StateDB, precompile.BlockContext) error {
+	return nil
+}
+func (c *testPrecompileConfig) Contract() precompile.StatefulPrecompiledContract { return c }
+func (*testPrecompileConfig) Run(precompile.PrecompileAccessibleState, common.Address, common.Address, []byte, uint64, bool) ([]byte, uint64, error) {
+	return nil, 0, nil
+}
+
+func TestRegisterPrecompile(t *testing.T) {
+	c := *TestChainConfig
+	first := &testPrecompileConfig{addr: common.HexToAddress("0x0100000000000000000000000000000000000010"), timestamp: utils.NewUint64(20)}
+	second := &testPrecompileConfig{addr: common.HexToAddress("0x0100000000000000000000000000000000000011"), timestamp: utils.NewUint64(10)}
+	third := &testPrecompileConfig{addr: common.HexToAddress("0x0100000000000000000000000000000000000012"), timestamp: utils.NewUint64(20)}
+	for _, config := range []*testPrecompileConfig{first, second, third} {
+		if err := c.RegisterPrecompile(config); err != nil {
+			t.Fatalf("failed to register precompile at %s: %v", config.addr, err)
+		}
+	}
+	if len(TestChainConfig.enabledStatefulPrecompiles()) != 0 {
+		t.Fatal("registering a precompile modified the copied config")
+	}
+
+	// The precompiles are ordered by activation timestamp, then registration.
+	enabled := c.enabledStatefulPrecompiles()
+	want := []precompile.StatefulPrecompileConfig{second, first, third}
+	if !reflect.DeepEqual(enabled, want) {
+		t.Fatalf("expected precompiles %v, have %v", want, enabled)
+	}
+
+	for _, test := range []struct {
+		timestamp uint64
+		active    []common.Address
+	}{
+		{timestamp: 9},
+		{timestamp: 10, active: []common.Address{second.addr}},
+		{timestamp: 20, active: []common.Address{first.addr, second.addr, third.addr}},
+	} {
+		rules := c.OdysseyRules(big.NewInt(0), test.timestamp)
+		if len(rules.Precompiles) != len(test.active) {
+			t.Errorf("at %d: expected %d active precompiles, have %d", test.timestamp, len(test.active), len(rules.Precompiles))
+		}
+		for _, addr := range test.active {
+			if _, ok := rules.Precompiles[addr]; !ok {
+				t.Errorf("at %d: expected precompile at %s to be active", test.timestamp, addr)
+			}
+		}
+	}
+
+	// The registered precompiles survive re-parsing the config.
+	text, err := c.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.enabledStatefulPrecompiles(), want) {
+		t.Fatal("registered precompiles were dropped by UnmarshalText")
+	}
+}
+
+func TestRegisterPrecompileErrors(t *testing.T) {
+	c := *TestChainConfig
+	registered := &testPrecompileConfig{addr: common.HexToAddress("0x0100000000000000000000000000000000000010"), timestamp: utils.NewUint64(0)}
+	if err := c.RegisterPrecompile(registered); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name   string
+		config precompile.StatefulPrecompileConfig
+		err    error
+	}{
+		{
+			name: "nil config",
+			err:  errNilPrecompileConfig,
+		},
+		{
+			name:   "never enabled",
+			config: &testPrecompileConfig{addr: common.HexToAddress("0x0100000000000000000000000000000000000020")},
+			err:    errPrecompileNeverEnabled,
+		},
+		{
+			name:   "outside reserved range",
+			config: &testPrecompileConfig{addr: common.HexToAddress("0x0200000000000000000000000000000000000000"), timestamp: utils.NewUint64(0)},
+			err:    errPrecompileAddressNotReserved,
+		},
+		{
+			name:   "native precompile",
+			config: &testPrecompileConfig{addr: common.HexToAddress("0x0100000000000000000000000000000000000002"), timestamp: utils.NewUint64(0)},
+			err:    errPrecompileAddressConflict,
+		},
+		{
+			name:   "already registered",
+			config: &testPrecompileConfig{addr: registered.addr, timestamp: utils.NewUint64(5)},
+			err:    errPrecompileAddressConflict,
+		},
+	} {
+		if err := c.RegisterPrecompile(test.config); !errors.Is(err, test.err) {
+			t.Errorf("%s: expected error %v, have %v", test.name, test.err, err)
+		}
+	}
+	if len(c.enabledStatefulPrecompiles()) != 1 {
+		t.Fatalf("expected 1 registered precompile, have %d", len(c.enabledStatefulPrecompiles()))
+	}
+}
+
+func TestRulesEqualAndDiff(t *testing.T) {
+	c := *TestChainConfig
+	precompileAddr := common.HexToAddress("0x0100000000000000000000000000000000000010")
+	if err := c.RegisterPrecompile(&testPrecompileConfig{addr: precompileAddr, timestamp: utils.NewUint64(10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := c.OdysseyRules(big.NewInt(0), 0)
+	if diff := golden.Diff(c.OdysseyRules(big.NewInt(0), 0)); len(diff) != 0 {
+		t.Fatalf("expected no diff between rules computed twice, have %v", diff)
+	}
+	if !golden.Equal(c.OdysseyRules(big.NewInt(0), 0)) {
+		t.Fatal("expected rules computed twice to be equal")
+	}
+
+	// A flipped fork flag and a changed allocation are reported by name.
+	drifted := c.OdysseyRules(big.NewInt(0), 0)
+	drifted.IsDUpgrade = !drifted.IsDUpgrade
+	drifted.LpAllocation++
+	want := []string{
+		fmt.Sprintf("IsDUpgrade: %t != %t", golden.IsDUpgrade, drifted.IsDUpgrade),
+		fmt.Sprintf("LpAllocation: %v != %v", golden.LpAllocation, drifted.LpAllocation),
+	}
+	if diff := golden.Diff(drifted); !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected diff %q, have %q", want, diff)
+	}
+	if golden.Equal(drifted) {
+		t.Error("expected drifted rules to differ")
+	}
+
+	// A precompile enabled in only one of the rules is reported by address.
+	activated := c.OdysseyRules(big.NewInt(0), 10)
+	want = []string{fmt.Sprintf("Precompiles[%s]: false != true", precompileAddr)}
+	if diff := golden.Diff(activated); !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected diff %q, have %q", want, diff)
+	}
+	want = []string{fmt.Sprintf("Precompiles[%s]: true != false", precompileAddr)}
+	if diff := activated.Diff(golden); !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected diff %q, have %q", want, diff)
+	}
+
+	// Nil and non-nil values differ.
+	drifted = c.OdysseyRules(big.NewInt(0), 0)
+	drifted.chainID = nil
+	if diff := golden.Diff(drifted); len(diff) != 1 || !strings.HasPrefix(diff[0], "ChainID: ") {
+		t.Errorf("expected only ChainID to differ, have %q", diff)
+	}
+
+	// Orion nodes getters reading the same slots are equal, whether or not they
+	// are the same getter.
+	drifted = c.OdysseyRules(big.NewInt(0), 0)
+	drifted.OrionNodes = NewOrionGetter(orionContractAddress, orionLastUpdateTimestampSlot, orionNodesSlot)
+	if diff := golden.Diff(drifted); len(diff) != 0 {
+		t.Errorf("expected no diff for an equivalent orion nodes getter, have %q", diff)
+	}
+	for _, getter := range []OrionNodesGetter{
+		NewOrionGetter(common.Address{1}, orionLastUpdateTimestampSlot, orionNodesSlot),
+		nil,
+	} {
+		drifted.OrionNodes = getter
+		if diff := golden.Diff(drifted); len(diff) != 1 || !strings.HasPrefix(diff[0], "OrionNodes: ") {
+			t.Errorf("expected only OrionNodes to differ, have %q", diff)
+		}
+	}
+}
+
+func TestRulesDiffFrom(t *testing.T) {
+	c := *TestChainConfig
+	c.EUpgradeBlockTimestamp = utils.NewUint64(10)
+
+	before := c.OdysseyRules(big.NewInt(0), 9)
+	if diff := before.DiffFrom(c.OdysseyRules(big.NewInt(0), 9)); len(diff) != 0 {
+		t.Fatalf("expected no diff between rules computed twice, have %q", diff)
+	}
+	// Only fork flags are compared.
+	drifted := c.OdysseyRules(big.NewInt(0), 9)
+	drifted.LpAllocation++
+	drifted.chainID = nil
+	if diff := drifted.DiffFrom(before); len(diff) != 0 {
+		t.Errorf("expected no diff for non-flag fields, have %q", diff)
+	}
+
+	// Exactly the fork activated at the boundary is reported.
+	at := c.OdysseyRules(big.NewInt(0), 10)
+	want := []string{"IsEUpgrade: false → true"}
+	if diff := at.DiffFrom(before); !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected diff %q, have %q", want, diff)
+	}
+	want = []string{"IsEUpgrade: true → false"}
+	if diff := before.DiffFrom(at); !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected diff %q, have %q", want, diff)
+	}
+}
+
+func TestRulesForkFlagsMap(t *testing.T) {
+	c := *TestChainConfig
+	c.EUpgradeBlockTimestamp = utils.NewUint64(10)
+	precompileAddr := common.HexToAddress("0x0100000000000000000000000000000000000010")
+	if err := c.RegisterPrecompile(&testPrecompileConfig{addr: precompileAddr, timestamp: utils.NewUint64(10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	before, at := c.OdysseyRules(big.NewInt(0), 9), c.OdysseyRules(big.NewInt(0), 10)
+	beforeFlags, atFlags := before.ForkFlagsMap(), at.ForkFlagsMap()
+	if beforeFlags["IsEUpgrade"] || !atFlags["IsEUpgrade"] {
+		t.Errorf("expected IsEUpgrade to be activated at 10, have %t before and %t at", beforeFlags["IsEUpgrade"], atFlags["IsEUpgrade"])
+	}
+	if !beforeFlags["IsBanff"] || !atFlags["IsBanff"] {
+		t.Error("expected IsBanff to be activated before 10")
+	}
+	// Only the fork flags are included.
+	if _, ok := atFlags["LpAllocation"]; ok {
+		t.Error("expected only bool fields")
+	}
+
+	if addrs := before.PrecompileAddresses(); len(addrs) != 0 {
+		t.Errorf("expected no precompiles before 10, have %v", addrs)
+	}
+	if addrs, want := at.PrecompileAddresses(), []common.Address{precompileAddr}; !reflect.DeepEqual(addrs, want) {
+		t.Errorf("expected precompiles %v, have %v", want, addrs)
+	}
+}
+
+func TestRulesAtomicGasLimit(t *testing.T) {
+	c := *TestChainConfig
+	c.EUpgradeBlockTimestamp = utils.NewUint64(10)
+
+	before, at := c.OdysseyRules(big.NewInt(0), 9), c.OdysseyRules(big.NewInt(0), 10)
+	if have := before.AtomicGasLimit(); have.Cmp(AtomicGasLimit) != 0 {
+		t.Errorf("expected atomic gas limit %v before EUpgrade, have %v", AtomicGasLimit, have)
+	}
+	if have := at.AtomicGasLimit(); have.Cmp(EUpgradeAtomicGasLimit) != 0 {
+		t.Errorf("expected atomic gas limit %v at EUpgrade, have %v", EUpgradeAtomicGasLimit, have)
+	}
+}
+
+// The x2c rate is part of consensus, so it must not change for any existing
+// fork.
+func TestRulesX2CRate(t *testing.T) {
+	configs := map[string]*ChainConfig{
+		"Launch":          TestLaunchConfig,
+		"ApricotPhase1":   TestApricotPhase1Config,
+		"ApricotPhase2":   TestApricotPhase2Config,
+		"ApricotPhase3":   TestApricotPhase3Config,
+		"ApricotPhase4":   TestApricotPhase4Config,
+		"ApricotPhase5":   TestApricotPhase5Config,
+		"ApricotPhase6":   TestApricotPhasePost6Config,
+		"Banff":           TestBanffChainConfig,
+		"Cortina":         TestCortinaChainConfig,
+		"DUpgrade":        TestDUpgradeChainConfig,
+		"TestChainConfig": TestChainConfig,
+	}
+	for name, config := range configs {
+		rules := config.OdysseyRules(big.NewInt(0), 0)
+		if have := rules.X2CRate(); have.Cmp(big.NewInt(1_000_000_000)) != 0 {
+			t.Errorf("%s: expected x2c rate 1000000000, have %v", name, have)
+		}
+		if have := NdioneToWei(rules, 3); have.Cmp(big.NewInt(3_000_000_000)) != 0 {
+			t.Errorf("%s: expected 3 nDIONE to be 3000000000 wei, have %v", name, have)
+		}
+	}
+}
+
+func TestWeiToNdione(t *testing.T) {
+	rules := TestChainConfig.OdysseyRules(big.NewInt(0), 0)
+	maxWei := NdioneToWei(rules, math.MaxUint64)
+	tests := map[string]struct {
+		wei               *big.Int
+		expectedNdione    uint64
+		expectedRemainder *big.Int
+		expectedErr       error
+	}{
+		"zero": {
+			wei:               big.NewInt(0),
+			expectedRemainder: big.NewInt(0),
+		},
+		"with remainder": {
+			wei:               big.NewInt(5_000_000_007),
+			expectedNdione:    5,
+			expectedRemainder: big.NewInt(7),
+		},
+		"max": {
+			wei:               maxWei,
+			expectedNdione:    math.MaxUint64,
+			expectedRemainder: big.NewInt(0),
+		},
+		"overflow": {
+			wei:         new(big.Int).Add(maxWei, big.NewInt(X2CRate)),
+			expectedErr: ErrWeiOverflowsNdione,
+		},
+		"negative": {
+			wei:         big.NewInt(-1),
+			expectedErr: ErrNegativeWei,
+		},
+	}
+	for name, test := range tests {
+		nDione, remainder, err := WeiToNdione(rules, test.wei)
+		if !errors.Is(err, test.expectedErr) {
+			t.Errorf("%s: expected error %v, have %v", name, test.expectedErr, err)
+			continue
+		}
+		if test.expectedErr != nil {
+			continue
+		}
+		if nDione != test.expectedNdione || remainder.Cmp(test.expectedRemainder) != 0 {
+			t.Errorf("%s: expected %d nDIONE and %v wei, have %d nDIONE and %v wei", name, test.expectedNdione, test.expectedRemainder, nDione, remainder)
+		}
+	}
+}
+
+func TestActivatedForks(t *testing.T) {
+	c := &ChainConfig{
+		HomesteadBlock:              big.NewInt(0),
+		ApricotPhase1BlockTimestamp: utils.NewUint64(0),
+		CortinaBlockTimestamp:       utils.NewUint64(100),
+		DUpgradeBlockTimestamp:      utils.NewUint64(100),
+		EUpgradeBlockTimestamp:      utils.NewUint64(200),
+	}
+	tests := []struct {
+		parentTime, time uint64
+		want             []string
+	}{
+		{parentTime: 0, time: 99, want: nil},
+		{parentTime: 99, time: 100, want: []string{"Cortina", "DUpgrade"}},
+		{parentTime: 100, time: 199, want: nil},
+		{parentTime: 150, time: 250, want: []string{"EUpgrade"}},
+		{parentTime: 200, time: 200, want: nil},
+	}
+	for _, test := range tests {
+		have := c.ActivatedForks(big.NewInt(1), test.parentTime, big.NewInt(2), test.time)
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("parent time %d, time %d: expected %v, have %v", test.parentTime, test.time, test.want, have)
+		}
+	}
+	if have, want := c.ActivatedForks(big.NewInt(-1), 0, big.NewInt(0), 0), []string{"Homestead"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("expected %v at genesis, have %v", want, have)
+	}
+}
+
+func TestNetworkUpgrades(t *testing.T) {
+	c := &ChainConfig{
+		ApricotPhase1BlockTimestamp: utils.NewUint64(0),
+		CortinaBlockTimestamp:       utils.NewUint64(100),
+	}
+	upgrades := c.NetworkUpgrades()
+	forks := c.timestampForks()
+	if len(upgrades) != len(forks) {
+		t.Fatalf("expected %d upgrades, have %d", len(forks), len(upgrades))
+	}
+	for i, upgrade := range upgrades {
+		if upgrade.Name != forks[i].desc || upgrade.Timestamp != forks[i].timestamp {
+			t.Errorf("upgrade %d: expected %s at %v, have %s at %v", i, forks[i].desc, forks[i].timestamp, upgrade.Name, upgrade.Timestamp)
+		}
+	}
+	if have, want := upgrades[9], (NetworkUpgrade{Name: "Cortina", Timestamp: c.CortinaBlockTimestamp}); !reflect.DeepEqual(have, want) {
+		t.Errorf("expected %v, have %v", want, have)
+	}
+}
+
+func TestLastActivatedTimestamp(t *testing.T) {
+	c := &ChainConfig{
+		ApricotPhase1BlockTimestamp: utils.NewUint64(10),
+		ApricotPhase2BlockTimestamp: utils.NewUint64(10),
+		BanffBlockTimestamp:         utils.NewUint64(100),
+		CortinaBlockTimestamp:       utils.NewUint64(200),
+	}
+	tests := []struct {
+		name              string
+		time              uint64
+		expectedTimestamp *uint64
+		expectedName      string
+		expectedOk        bool
+	}{
+		{name: "before any upgrade", time: 9},
+		{name: "at an upgrade", time: 100, expectedTimestamp: c.BanffBlockTimestamp, expectedName: "Banff", expectedOk: true},
+		{name: "between two upgrades", time: 199, expectedTimestamp: c.BanffBlockTimestamp, expectedName: "Banff", expectedOk: true},
+		{name: "upgrades at the same timestamp", time: 10, expectedTimestamp: c.ApricotPhase2BlockTimestamp, expectedName: "Apricot Phase 2", expectedOk: true},
+		{name: "after the last upgrade", time: 1000, expectedTimestamp: c.CortinaBlockTimestamp, expectedName: "Cortina", expectedOk: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timestamp, name, ok := c.LastActivatedTimestamp(test.time)
+			if timestamp != test.expectedTimestamp || name != test.expectedName || ok != test.expectedOk {
+				t.Errorf("expected (%v, %q, %t), have (%v, %q, %t)", test.expectedTimestamp, test.expectedName, test.expectedOk, timestamp, name, ok)
+			}
+		})
+	}
+}
+
+func TestUpgradeCheckOverride(t *testing.T) {
+	stored := *TestChainConfig
+	stored.DUpgradeBlockTimestamp = utils.NewUint64(100)
+	stored.EUpgradeBlockTimestamp = utils.NewUint64(200)
+
+	override := &UpgradeCheckOverride{Timestamp: utils.NewUint64(150)}
+	if height, time := override.Head(10, 300); height != 10 || time != 149 {
+		t.Errorf("expected head (10, 149), have (%d, %d)", height, time)
+	}
+	if height, time := override.Head(10, 120); height != 10 || time != 120 {
+		t.Errorf("expected head before the threshold to be unchanged, have (%d, %d)", height, time)
+	}
+	if height, time := (*UpgradeCheckOverride)(nil).Head(10, 300); height != 10 || time != 300 {
+		t.Errorf("expected nil override to leave the head unchanged, have (%d, %d)", height, time)
+	}
+
+	// Rescheduling an upgrade at or after the threshold is accepted.
+	future := stored
+	future.EUpgradeBlockTimestamp = utils.NewUint64(250)
+	if err := stored.CheckCompatible(&future, 10, 300); err == nil {
+		t.Fatal("expected rescheduling an activated upgrade to be incompatible")
+	}
+	if height, time := override.Head(10, 300); stored.CheckCompatible(&future, height, time) != nil {
+		t.Error("expected rescheduling an upgrade after the override to be compatible")
+	}
+
+	// Rescheduling an upgrade before the threshold is still rejected.
+	past := stored
+	past.DUpgradeBlockTimestamp = utils.NewUint64(110)
+	height, time := override.Head(10, 300)
+	err := stored.CheckCompatible(&past, height, time)
+	if err == nil || err.What != "DUpgrade fork block timestamp" {
+		t.Errorf("expected rescheduling an upgrade before the override to be incompatible, have %v", err)
+	}
+}
+
+func TestGetTargetBlockRate(t *testing.T) {
+	c := *TestChainConfig
+	if have := c.GetTargetBlockRate(0); have != ApricotPhase4TargetBlockRate {
+		t.Errorf("expected default target block rate %d, have %d", ApricotPhase4TargetBlockRate, have)
+	}
+	c.TargetBlockRate = utils.NewUint64(0)
+	c.TargetBlockRateTimestamp = utils.NewUint64(0)
+	if have := c.GetTargetBlockRate(0); have != ApricotPhase4TargetBlockRate {
+		t.Errorf("expected default target block rate %d for 0, have %d", ApricotPhase4TargetBlockRate, have)
+	}
+
+	var parsed ChainConfig
+	if err := json.Unmarshal([]byte(`{"chainId": 1, "targetBlockRate": 5, "targetBlockRateTimestamp": 100}`), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if have := parsed.GetTargetBlockRate(99); have != ApricotPhase4TargetBlockRate {
+		t.Errorf("expected default target block rate %d before activation, have %d", ApricotPhase4TargetBlockRate, have)
+	}
+	if have := parsed.GetTargetBlockRate(100); have != 5 {
+		t.Errorf("expected target block rate 5, have %d", have)
+	}
+
+	parsed.TargetBlockRateTimestamp = nil
+	if err := parsed.CheckConfigForkOrder(); !errors.Is(err, errMissingTargetBlockRateTimestamp) {
+		t.Errorf("expected %v, have %v", errMissingTargetBlockRateTimestamp, err)
+	}
+}
+
+func TestCheckCompatibleTargetBlockRate(t *testing.T) {
+	stored := *TestChainConfig
+	stored.TargetBlockRate = utils.NewUint64(5)
+	stored.TargetBlockRateTimestamp = utils.NewUint64(100)
+
+	tests := []struct {
+		name     string
+		rate     uint64
+		activate uint64
+		head     uint64
+		wantErr  string
+	}{
+		{name: "scheduled rate changed", rate: 4, activate: 100, head: 99},
+		{name: "scheduled activation moved", rate: 5, activate: 200, head: 99},
+		{name: "active rate changed", rate: 4, activate: 100, head: 100, wantErr: "TargetBlockRate"},
+		{name: "active activation moved", rate: 5, activate: 200, head: 150, wantErr: "TargetBlockRate activation timestamp"},
+		{name: "unchanged", rate: 5, activate: 100, head: 150},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newcfg := stored
+			newcfg.TargetBlockRate = utils.NewUint64(test.rate)
+			newcfg.TargetBlockRateTimestamp = utils.NewUint64(test.activate)
+			err := stored.CheckCompatible(&newcfg, 0, test.head)
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Errorf("expected compatible configs, have %v", err)
+			case test.wantErr != "" && (err == nil || err.What != test.wantErr):
+				t.Errorf("expected %q incompatibility, have %v", test.wantErr, err)
+			case test.wantErr != "" && err.RewindToTime != 99:
+				t.Errorf("expected rewind to 99, have %d", err.RewindToTime)
+			}
+		})
+	}
+}
+
+func TestCheckConfigForkOrderExtraEIPs(t *testing.T) {
+	tests := []struct {
+		name      string
+		extraEIPs []ExtraEIP
+		wantErr   error
+	}{
+		{name: "none"},
+		{name: "ordered", extraEIPs: []ExtraEIP{{EIP: 1153, Timestamp: 10}, {EIP: 3855, Timestamp: 10}, {EIP: 3860, Timestamp: 20}}},
+		{name: "duplicate", extraEIPs: []ExtraEIP{{EIP: 1153, Timestamp: 10}, {EIP: 1153, Timestamp: 20}}, wantErr: errDuplicateExtraEIP},
+		{name: "unordered", extraEIPs: []ExtraEIP{{EIP: 1153, Timestamp: 20}, {EIP: 3855, Timestamp: 10}}, wantErr: errUnorderedExtraEIPs},
+		{name: "invalid", extraEIPs: []ExtraEIP{{EIP: 0}}, wantErr: errInvalidExtraEIP},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := *TestChainConfig
+			c.ExtraEIPs = test.extraEIPs
+			if err := c.CheckConfigForkOrder(); !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected %v, have %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOdysseyRulesExtraEIPs(t *testing.T) {
+	c := *TestChainConfig
+	c.ExtraEIPs = []ExtraEIP{{EIP: 1153, Timestamp: 10}, {EIP: 3855, Timestamp: 20}}
+
+	for _, test := range []struct {
+		timestamp uint64
+		expected  []int
+	}{
+		{timestamp: 9},
+		{timestamp: 10, expected: []int{1153}},
+		{timestamp: 25, expected: []int{1153, 3855}},
+	} {
+		rules := c.OdysseyRules(common.Big0, test.timestamp)
+		if !reflect.DeepEqual(rules.ExtraEIPs, test.expected) {
+			t.Errorf("timestamp %d: expected extra eips %v, have %v", test.timestamp, test.expected, rules.ExtraEIPs)
+		}
+	}
+
+	// Rules that differ only by their extra eips are reported as different.
+	before, after := c.OdysseyRules(common.Big0, 9), c.OdysseyRules(common.Big0, 10)
+	want := []string{"ExtraEIPs: [] != [1153]"}
+	if diff := before.Diff(after); !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected diff %q, have %q", want, diff)
+	}
+}
+
+func TestCheckCompatibleExtraEIPs(t *testing.T) {
+	tests := []struct {
+		name          string
+		stored, new   []ExtraEIP
+		headTimestamp uint64
+		wantErr       *ConfigCompatError
+	}{
+		{
+			name:          "unchanged",
+			stored:        []ExtraEIP{{EIP: 1153, Timestamp: 10}},
+			new:           []ExtraEIP{{EIP: 1153, Timestamp: 10}},
+			headTimestamp: 20,
+		},
+		{
+			name:          "future eip rescheduled",
+			stored:        []ExtraEIP{{EIP: 1153, Timestamp: 30}},
+			new:           []ExtraEIP{{EIP: 1153, Timestamp: 40}},
+			headTimestamp: 20,
+		},
+		{
+			name:          "future eip added",
+			new:           []ExtraEIP{{EIP: 1153, Timestamp: 30}},
+			headTimestamp: 20,
+		},
+		{
+			name:          "activated eip rescheduled",
+			stored:        []ExtraEIP{{EIP: 1153, Timestamp: 10}},
+			new:           []ExtraEIP{{EIP: 1153, Timestamp: 30}},
+			headTimestamp: 20,
+			wantErr: &ConfigCompatError{
+				What:         "EIP-1153 activation timestamp",
+				StoredTime:   utils.NewUint64(10),
+				NewTime:      utils.NewUint64(30),
+				RewindToTime: 9,
+			},
+		},
+		{
+			name:          "activated eip removed",
+			stored:        []ExtraEIP{{EIP: 3855, Timestamp: 0}, {EIP: 1153, Timestamp: 10}},
+			new:           []ExtraEIP{{EIP: 3855, Timestamp: 0}},
+			headTimestamp: 20,
+			wantErr: &ConfigCompatError{
+				What:         "EIP-1153 activation timestamp",
+				StoredTime:   utils.NewUint64(10),
+				RewindToTime: 9,
+			},
+		},
+		{
+			name:          "eip added in the past",
+			new:           []ExtraEIP{{EIP: 1153, Timestamp: 10}},
+			headTimestamp: 20,
+			wantErr: &ConfigCompatError{
+				What:         "EIP-1153 activation timestamp",
+				NewTime:      utils.NewUint64(10),
+				RewindToTime: 9,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stored, new := *TestChainConfig, *TestChainConfig
+			stored.ExtraEIPs, new.ExtraEIPs = test.stored, test.new
+			err := stored.CheckCompatible(&new, 0, test.headTimestamp)
+			if !reflect.DeepEqual(err, test.wantErr) {
+				t.Errorf("error mismatch:\nerr: %v\nwant: %v", err, test.wantErr)
+			}
+		})
+	}
+}
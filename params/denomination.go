@@ -26,6 +26,11 @@
 
 package params
 
+import (
+	"errors"
+	"math/big"
+)
+
 // These are the multipliers for ether denominations.
 // Example: To get the wei value of an amount in 'gwei', use
 //
@@ -35,3 +40,36 @@ const (
 	GWei  = 1e9
 	Ether = 1e18
 )
+
+// X2CRate is the number of wei per nDIONE, the smallest denomination of DIONE
+// on the A and O chains. It has not changed since genesis, so that every fork
+// to date uses it. See Rules.X2CRate.
+const X2CRate = GWei
+
+var (
+	// x2cRateBig is X2CRate as a *big.Int. Rules.X2CRate returns a copy of it.
+	x2cRateBig = big.NewInt(X2CRate)
+
+	ErrNegativeWei        = errors.New("wei amount must not be negative")
+	ErrWeiOverflowsNdione = errors.New("wei amount overflows uint64 nDIONE")
+)
+
+// NdioneToWei converts an amount of nDIONE to wei, the denomination used on
+// the D-Chain, at the conversion rate of [rules].
+func NdioneToWei(rules Rules, nDione uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(nDione), rules.X2CRate())
+}
+
+// WeiToNdione converts an amount of wei to nDIONE at the conversion rate of
+// [rules]. It returns the whole nDIONE amount and the remaining wei that is too
+// small to be represented in nDIONE.
+func WeiToNdione(rules Rules, wei *big.Int) (uint64, *big.Int, error) {
+	if wei.Sign() < 0 {
+		return 0, nil, ErrNegativeWei
+	}
+	nDione, remainder := new(big.Int).QuoRem(wei, rules.X2CRate(), new(big.Int))
+	if !nDione.IsUint64() {
+		return 0, nil, ErrWeiOverflowsNdione
+	}
+	return nDione.Uint64(), remainder, nil
+}
@@ -23,6 +23,7 @@ const (
 	CortinaGasLimit       uint64 = 15_000_000
 
 	ApricotPhase3ExtraDataSize            uint64 = 80
+	OrionSnapshotExtraDataSize            uint64 = 40 // node count and list hash following the rollup window
 	ApricotPhase3MinBaseFee               int64  = 2_380_952_380_952_38
 	ApricotPhase3MaxBaseFee               int64  = 7_142_857_142_857_14
 	ApricotPhase3InitialBaseFee           int64  = 2_380_952_380_952_38
@@ -32,6 +33,7 @@ const (
 	ApricotPhase4BaseFeeChangeDenominator uint64 = 12
 	ApricotPhase5TargetGas                uint64 = 15_000_000
 	ApricotPhase5BaseFeeChangeDenominator uint64 = 36
+	ApricotPhase4TargetBlockRate          uint64 = 2 // in seconds
 
 	LpAddress         string = "0x0000000000000000000000000000000000000001"
 	GovernanceAddress string = "0x0000000000000000000000000000000000000002"
@@ -54,6 +56,10 @@ var (
 	//
 	// This value must always remain <= MaxUint64.
 	AtomicGasLimit *big.Int = big.NewInt(100_000)
+	// EUpgradeAtomicGasLimit replaces AtomicGasLimit as of the EUpgrade.
+	//
+	// This value must always remain <= MaxUint64.
+	EUpgradeAtomicGasLimit *big.Int = big.NewInt(200_000)
 
 	LpAllocation               *big.Int = big.NewInt(25_000)  // 25%
 	GovernanceAllocation       *big.Int = big.NewInt(50_000)  // 50%
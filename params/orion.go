@@ -28,22 +28,61 @@ package params
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/DioneProtocol/odysseygo/cache"
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
-var _ OrionNodesGetter = &orionNodesGetter{}
+// orionNodesCacheSize is the number of orion nodes lists cached by an orion
+// nodes getter.
+const orionNodesCacheSize = 8
+
+var (
+	_ OrionNodesGetter = &orionNodesGetter{}
+
+	// ErrOrionListStale is returned when the orion contract was updated after
+	// the block the nodes list was requested at.
+	ErrOrionListStale = errors.New("orion nodes list is stale")
+
+	errOrionNodesCountOverflow = errors.New("orion nodes count overflows uint64")
+)
 
 type stateGetter interface {
 	GetState(addr common.Address, hash common.Hash) common.Hash
 }
 
+// timedBlock is implemented by *types.Block, which can not be referenced here
+// without an import cycle.
+type timedBlock interface {
+	Time() uint64
+}
+
 type OrionNodesGetter interface {
 	GetLastUpdateTimestamp(stateGetter) uint64
-	GetNodesList(stateGetter) []ids.NodeID
+	GetNodesList(stateGetter) ([]ids.NodeID, error)
+	GetNodesListAtRoot(common.Hash, stateGetter) ([]ids.NodeID, error)
+	GetNodesCount(stateGetter) (uint64, error)
+	GetNodesListAtBlock(timedBlock, stateGetter) ([]ids.NodeID, error)
+}
+
+// OrionListStaleError is returned by GetNodesListAtBlock when the orion
+// contract in the provided state was last updated after the block.
+type OrionListStaleError struct {
+	LastUpdateTimestamp uint64
+	BlockTimestamp      uint64
+}
+
+func (e *OrionListStaleError) Error() string {
+	return fmt.Sprintf("%s: last updated at %d after block timestamp %d", ErrOrionListStale, e.LastUpdateTimestamp, e.BlockTimestamp)
+}
+
+func (e *OrionListStaleError) Unwrap() error {
+	return ErrOrionListStale
 }
 
 type orionNodesGetter struct {
@@ -51,6 +90,15 @@ type orionNodesGetter struct {
 	lastUpdateSlot common.Hash
 	sizeSlot       common.Hash
 	listStartSlot  *big.Int
+
+	// nodesCache maps a state root and the last update timestamp of the
+	// contract in that state to the nodes list read from it.
+	nodesCache *cache.LRU[orionNodesCacheKey, []ids.NodeID]
+}
+
+type orionNodesCacheKey struct {
+	root       common.Hash
+	lastUpdate uint64
 }
 
 func NewOrionGetter(contract common.Address, lastUpdateSlot, orionsListSlot common.Hash) OrionNodesGetter {
@@ -60,6 +108,7 @@ func NewOrionGetter(contract common.Address, lastUpdateSlot, orionsListSlot comm
 		lastUpdateSlot: lastUpdateSlot,
 		sizeSlot:       orionsListSlot,
 		listStartSlot:  listStartSlot.Big(),
+		nodesCache:     &cache.LRU[orionNodesCacheKey, []ids.NodeID]{Size: orionNodesCacheSize},
 	}
 }
 
@@ -72,8 +121,23 @@ func (o *orionNodesGetter) GetLastUpdateTimestamp(state stateGetter) uint64 {
 	return o.getUint64(state, o.lastUpdateSlot)
 }
 
-func (o *orionNodesGetter) GetNodesList(state stateGetter) []ids.NodeID {
-	size := o.getUint64(state, o.sizeSlot)
+// getNodesCount decodes the size of the nodes list, which is stored as a
+// 256-bit word.
+func (o *orionNodesGetter) getNodesCount(state stateGetter) (uint64, error) {
+	hash := state.GetState(o.contract, o.sizeSlot)
+	for _, b := range hash[:24] {
+		if b != 0 {
+			return 0, fmt.Errorf("%w: %s", errOrionNodesCountOverflow, hash)
+		}
+	}
+	return binary.BigEndian.Uint64(hash[24:]), nil
+}
+
+func (o *orionNodesGetter) GetNodesList(state stateGetter) ([]ids.NodeID, error) {
+	size, err := o.getNodesCount(state)
+	if err != nil {
+		return nil, err
+	}
 	nodeIDs := make([]ids.NodeID, 0, size)
 
 	for i := uint64(0); i < size; i++ {
@@ -83,5 +147,51 @@ func (o *orionNodesGetter) GetNodesList(state stateGetter) []ids.NodeID {
 		nodeIDs = append(nodeIDs, nodeID)
 	}
 
-	return nodeIDs
+	return nodeIDs, nil
+}
+
+// GetNodesListAtRoot returns the orion nodes list from [state], which must hold
+// the same list as the committed state with [root]. The list is read from
+// storage only if no list was cached for [root] at the last update timestamp of
+// the contract in [state].
+func (o *orionNodesGetter) GetNodesListAtRoot(root common.Hash, state stateGetter) ([]ids.NodeID, error) {
+	key := orionNodesCacheKey{
+		root:       root,
+		lastUpdate: o.GetLastUpdateTimestamp(state),
+	}
+	nodeIDs, ok := o.nodesCache.Get(key)
+	if !ok {
+		var err error
+		nodeIDs, err = o.GetNodesList(state)
+		if err != nil {
+			return nil, err
+		}
+		o.nodesCache.Put(key, nodeIDs)
+	}
+	// Callers may modify the returned list, so the cached list is copied.
+	return append(make([]ids.NodeID, 0, len(nodeIDs)), nodeIDs...), nil
+}
+
+// GetNodesCount returns the number of orion nodes in [state] by reading only
+// the size of the list, or 0 if [state] is nil.
+func (o *orionNodesGetter) GetNodesCount(state stateGetter) (uint64, error) {
+	if state == nil {
+		return 0, nil
+	}
+	return o.getNodesCount(state)
+}
+
+// GetNodesListAtBlock returns the orion nodes list from [state], or an
+// *OrionListStaleError if the contract in [state] was last updated after the
+// timestamp of [block]. In that case, the caller should retry with the
+// historical state at [block].
+func (o *orionNodesGetter) GetNodesListAtBlock(block timedBlock, state stateGetter) ([]ids.NodeID, error) {
+	lastUpdate := o.GetLastUpdateTimestamp(state)
+	if lastUpdate > block.Time() {
+		return nil, &OrionListStaleError{
+			LastUpdateTimestamp: lastUpdate,
+			BlockTimestamp:      block.Time(),
+		}
+	}
+	return o.GetNodesList(state)
 }
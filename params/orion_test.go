@@ -0,0 +1,275 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type testState map[common.Address]map[common.Hash]common.Hash
+
+func (s testState) GetState(addr common.Address, hash common.Hash) common.Hash {
+	return s[addr][hash]
+}
+
+// countingState counts the storage reads of the wrapped state.
+type countingState struct {
+	testState
+	reads int
+}
+
+func (s *countingState) GetState(addr common.Address, hash common.Hash) common.Hash {
+	s.reads++
+	return s.testState.GetState(addr, hash)
+}
+
+type testBlock uint64
+
+func (b testBlock) Time() uint64 { return uint64(b) }
+
+func newOrionTestState(lastUpdate uint64, nodeIDs []ids.NodeID) testState {
+	storage := map[common.Hash]common.Hash{
+		orionLastUpdateTimestampSlot: common.BigToHash(new(big.Int).SetUint64(lastUpdate)),
+		orionNodesSlot:               common.BigToHash(big.NewInt(int64(len(nodeIDs)))),
+	}
+	listStartSlot := crypto.Keccak256Hash(orionNodesSlot[:]).Big()
+	for i, nodeID := range nodeIDs {
+		slot := common.BigToHash(new(big.Int).Add(listStartSlot, big.NewInt(int64(i))))
+		var value common.Hash
+		copy(value[:], nodeID[:])
+		storage[slot] = value
+	}
+	return testState{orionContractAddress: storage}
+}
+
+func TestGetNodesListAtBlock(t *testing.T) {
+	nodeIDs := []ids.NodeID{ids.GenerateTestNodeID(), ids.GenerateTestNodeID()}
+
+	tests := []struct {
+		name       string
+		lastUpdate uint64
+		blockTime  uint64
+		expected   []ids.NodeID
+		stale      bool
+	}{
+		{name: "never updated", lastUpdate: 0, blockTime: 0, expected: nodeIDs},
+		{name: "updated before block", lastUpdate: 10, blockTime: 20, expected: nodeIDs},
+		{name: "updated at block", lastUpdate: 20, blockTime: 20, expected: nodeIDs},
+		{name: "updated after block", lastUpdate: 21, blockTime: 20, stale: true},
+		{name: "updated after genesis", lastUpdate: 1, blockTime: 0, stale: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := newOrionTestState(test.lastUpdate, nodeIDs)
+			got, err := OrionGetter.GetNodesListAtBlock(testBlock(test.blockTime), state)
+			if !test.stale {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(got, test.expected) {
+					t.Fatalf("expected nodes %v, got %v", test.expected, got)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrOrionListStale) {
+				t.Fatalf("expected %v, got %v", ErrOrionListStale, err)
+			}
+			var staleErr *OrionListStaleError
+			if !errors.As(err, &staleErr) {
+				t.Fatalf("expected *OrionListStaleError, got %T", err)
+			}
+			if staleErr.LastUpdateTimestamp != test.lastUpdate {
+				t.Errorf("expected last update timestamp %d, got %d", test.lastUpdate, staleErr.LastUpdateTimestamp)
+			}
+			if staleErr.BlockTimestamp != test.blockTime {
+				t.Errorf("expected block timestamp %d, got %d", test.blockTime, staleErr.BlockTimestamp)
+			}
+			if got != nil {
+				t.Errorf("expected no nodes, got %v", got)
+			}
+		})
+	}
+}
+
+func newTestNodeIDs(n int) []ids.NodeID {
+	nodeIDs := make([]ids.NodeID, n)
+	for i := range nodeIDs {
+		nodeIDs[i] = ids.GenerateTestNodeID()
+	}
+	return nodeIDs
+}
+
+func TestGetNodesList(t *testing.T) {
+	getter := NewOrionGetter(orionContractAddress, orionLastUpdateTimestampSlot, orionNodesSlot)
+	nodeIDs := newTestNodeIDs(3)
+
+	// The size of the list and each node are read from storage.
+	state := &countingState{testState: newOrionTestState(10, nodeIDs)}
+	if got, err := getter.GetNodesList(state); err != nil || !reflect.DeepEqual(got, nodeIDs) {
+		t.Fatalf("expected nodes %v, got %v (err: %v)", nodeIDs, got, err)
+	}
+	if expected := len(nodeIDs) + 1; state.reads != expected {
+		t.Fatalf("expected %d storage reads, got %d", expected, state.reads)
+	}
+
+	// Updating the contract in the same state returns the new list.
+	updated := newTestNodeIDs(2)
+	state.testState = newOrionTestState(10, updated)
+	if got, err := getter.GetNodesList(state); err != nil || !reflect.DeepEqual(got, updated) {
+		t.Fatalf("expected nodes %v, got %v (err: %v)", updated, got, err)
+	}
+}
+
+func TestGetNodesListAtRoot(t *testing.T) {
+	getter := NewOrionGetter(orionContractAddress, orionLastUpdateTimestampSlot, orionNodesSlot)
+	nodeIDs := newTestNodeIDs(3)
+	root := common.Hash{1}
+
+	state := &countingState{testState: newOrionTestState(10, nodeIDs)}
+	if got, err := getter.GetNodesListAtRoot(root, state); err != nil || !reflect.DeepEqual(got, nodeIDs) {
+		t.Fatalf("expected nodes %v, got %v (err: %v)", nodeIDs, got, err)
+	}
+	if expected := len(nodeIDs) + 2; state.reads != expected {
+		t.Fatalf("expected %d storage reads, got %d", expected, state.reads)
+	}
+
+	// Repeated calls with the same root and timestamp are served from the
+	// cache, with only the timestamp read from storage.
+	state.reads = 0
+	got, err := getter.GetNodesListAtRoot(root, state)
+	if err != nil || !reflect.DeepEqual(got, nodeIDs) {
+		t.Fatalf("expected nodes %v, got %v (err: %v)", nodeIDs, got, err)
+	}
+	if state.reads != 1 {
+		t.Fatalf("expected 1 storage read, got %d", state.reads)
+	}
+	// Modifying the returned list does not modify the cached list.
+	got[0] = ids.EmptyNodeID
+	if got, err := getter.GetNodesListAtRoot(root, state); err != nil || !reflect.DeepEqual(got, nodeIDs) {
+		t.Fatalf("expected nodes %v, got %v (err: %v)", nodeIDs, got, err)
+	}
+
+	// A state with another timestamp or root is read from storage.
+	updated := newTestNodeIDs(2)
+	for _, test := range []struct {
+		root       common.Hash
+		lastUpdate uint64
+	}{
+		{root: root, lastUpdate: 11},
+		{root: common.Hash{2}, lastUpdate: 10},
+	} {
+		state := &countingState{testState: newOrionTestState(test.lastUpdate, updated)}
+		if got, err := getter.GetNodesListAtRoot(test.root, state); err != nil || !reflect.DeepEqual(got, updated) {
+			t.Fatalf("expected nodes %v, got %v (err: %v)", updated, got, err)
+		}
+		if expected := len(updated) + 2; state.reads != expected {
+			t.Fatalf("expected %d storage reads, got %d", expected, state.reads)
+		}
+	}
+
+	// The least recently used list is evicted once the cache is full.
+	for i := 0; i < orionNodesCacheSize; i++ {
+		if _, err := getter.GetNodesListAtRoot(common.Hash{3, byte(i)}, newOrionTestState(10, updated)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	state.reads = 0
+	if _, err := getter.GetNodesListAtRoot(root, state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := len(nodeIDs) + 2; state.reads != expected {
+		t.Fatalf("expected %d storage reads after eviction, got %d", expected, state.reads)
+	}
+}
+
+func BenchmarkGetNodesList(b *testing.B) {
+	getter := NewOrionGetter(orionContractAddress, orionLastUpdateTimestampSlot, orionNodesSlot)
+	state := newOrionTestState(10, newTestNodeIDs(100))
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := getter.GetNodesListAtRoot(common.Hash{1}, state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := getter.GetNodesList(state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestGetNodesCount(t *testing.T) {
+	getter := NewOrionGetter(orionContractAddress, orionLastUpdateTimestampSlot, orionNodesSlot)
+
+	for _, n := range []int{0, 1, 3} {
+		state := &countingState{testState: newOrionTestState(10, newTestNodeIDs(n))}
+		count, err := getter.GetNodesCount(state)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != uint64(n) {
+			t.Fatalf("expected %d nodes, got %d", n, count)
+		}
+		// Only the size of the list is read.
+		if state.reads != 1 {
+			t.Fatalf("expected 1 storage read, got %d", state.reads)
+		}
+	}
+
+	for _, state := range []stateGetter{nil, testState{}} {
+		count, err := getter.GetNodesCount(state)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 0 {
+			t.Fatalf("expected no nodes in empty state, got %d", count)
+		}
+	}
+
+	state := newOrionTestState(10, nil)
+	state[orionContractAddress][orionNodesSlot] = common.BigToHash(new(big.Int).Lsh(common.Big1, 64))
+	if _, err := getter.GetNodesCount(state); !errors.Is(err, errOrionNodesCountOverflow) {
+		t.Fatalf("expected %v, got %v", errOrionNodesCountOverflow, err)
+	}
+	// The list is decoded with the same size as the count.
+	if _, err := getter.GetNodesList(state); !errors.Is(err, errOrionNodesCountOverflow) {
+		t.Fatalf("expected %v, got %v", errOrionNodesCountOverflow, err)
+	}
+}
+
+func BenchmarkGetNodesCount(b *testing.B) {
+	getter := NewOrionGetter(orionContractAddress, orionLastUpdateTimestampSlot, orionNodesSlot).(*orionNodesGetter)
+	state := &countingState{testState: newOrionTestState(10, newTestNodeIDs(1000))}
+
+	b.Run("count", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := getter.GetNodesCount(state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("list", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := getter.GetNodesList(state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/set"
 
 	"github.com/DioneProtocol/odysseygo/version"
 )
@@ -36,6 +37,9 @@ type NetworkClient interface {
 	// Gossip sends given gossip message to peers
 	Gossip(gossip []byte) error
 
+	// GossipSpecific sends given gossip message to [nodeIDs]
+	GossipSpecific(nodeIDs set.Set[ids.NodeID], gossip []byte) error
+
 	// TrackBandwidth should be called for each valid request with the bandwidth
 	// (length of response divided by request time), and with 0 if the response is invalid.
 	TrackBandwidth(nodeID ids.NodeID, bandwidth float64)
@@ -104,6 +108,10 @@ func (c *client) Gossip(gossip []byte) error {
 	return c.network.Gossip(gossip)
 }
 
+func (c *client) GossipSpecific(nodeIDs set.Set[ids.NodeID], gossip []byte) error {
+	return c.network.GossipSpecific(nodeIDs, gossip)
+}
+
 func (c *client) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 	c.network.TrackBandwidth(nodeID, bandwidth)
 }
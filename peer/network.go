@@ -54,6 +54,9 @@ type Network interface {
 	// Gossip sends given gossip message to peers
 	Gossip(gossip []byte) error
 
+	// GossipSpecific sends given gossip message to [nodeIDs]
+	GossipSpecific(nodeIDs set.Set[ids.NodeID], gossip []byte) error
+
 	// SendCrossChainRequest sends a message to given chainID notifying handler when there's a response or timeout
 	SendCrossChainRequest(chainID ids.ID, message []byte, handler message.ResponseHandler) error
 
@@ -441,6 +444,15 @@ func (n *network) Gossip(gossip []byte) error {
 	return n.appSender.SendAppGossip(context.TODO(), gossip)
 }
 
+// GossipSpecific sends given gossip message to [nodeIDs]
+func (n *network) GossipSpecific(nodeIDs set.Set[ids.NodeID], gossip []byte) error {
+	if n.closed.Get() {
+		return nil
+	}
+
+	return n.appSender.SendAppGossipSpecific(context.TODO(), nodeIDs, gossip)
+}
+
 // AppGossip is called by odysseygo -> VM when there is an incoming AppGossip from a peer
 // error returned by this function is expected to be treated as fatal by the engine
 // returns error if request could not be parsed as message.Request or when the requestHandler returns an error
@@ -0,0 +1,218 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package atomicstate provides read-only access to the accepted atomic
+// transactions stored in a coreth database, without starting a VM.
+package atomicstate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/codec"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/hashing"
+	"github.com/DioneProtocol/odysseygo/utils/wrappers"
+
+	"github.com/DioneProtocol/coreth/ethdb"
+	"github.com/DioneProtocol/coreth/plugin/delta"
+)
+
+var (
+	// ErrNotFound is returned when a requested atomic tx is not accepted.
+	ErrNotFound = errors.New("not found")
+
+	errNotIndexed = errors.New("atomic repository has not been indexed")
+)
+
+// AtomicStateView reads the accepted atomic txs indexed by the atomic
+// repository of a coreth database.
+type AtomicStateView struct {
+	// [txIDTable] maps [txID] => [height]+[atomic tx]
+	txIDTable table
+	// [heightTable] maps [height] => [atomic txs]
+	heightTable table
+	codec       codec.Manager
+	indexHeight uint64
+}
+
+// OpenAtomicStateView opens a read-only view of the atomic state stored in
+// [db], which must be the database the VM was initialized with. Returns an
+// error if the atomic repository in [db] has not been fully indexed.
+func OpenAtomicStateView(db ethdb.KeyValueStore) (*AtomicStateView, error) {
+	metadataTable := newTable(db, delta.AtomicRepoMetadataDBPrefix)
+	indexHeightBytes, err := metadataTable.get(delta.MaxIndexedHeightKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNotIndexed, err)
+	}
+	if len(indexHeightBytes) != wrappers.LongLen {
+		return nil, fmt.Errorf("%w: unexpected length for index height %d", errNotIndexed, len(indexHeightBytes))
+	}
+	return &AtomicStateView{
+		txIDTable:   newTable(db, delta.AtomicTxIDDBPrefix),
+		heightTable: newTable(db, delta.AtomicHeightTxDBPrefix),
+		codec:       delta.Codec,
+		indexHeight: binary.BigEndian.Uint64(indexHeightBytes),
+	}, nil
+}
+
+// IndexHeight returns the height up to which the atomic txs were indexed when
+// the view was opened.
+func (v *AtomicStateView) IndexHeight() uint64 {
+	return v.indexHeight
+}
+
+// GetTx returns the accepted atomic tx [txID] along with the height of the
+// block it was accepted in. Returns ErrNotFound if [txID] was not accepted.
+func (v *AtomicStateView) GetTx(txID ids.ID) (*delta.Tx, uint64, error) {
+	indexedTxBytes, err := v.txIDTable.get(txID[:])
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(indexedTxBytes) < wrappers.LongLen {
+		return nil, 0, fmt.Errorf("atomic tx entry too short: %d", len(indexedTxBytes))
+	}
+
+	// value is stored as [height]+[tx bytes], decompose with a packer.
+	packer := wrappers.Packer{Bytes: indexedTxBytes}
+	height := packer.UnpackLong()
+	txBytes := packer.UnpackBytes()
+	if packer.Err != nil {
+		return nil, 0, packer.Err
+	}
+	tx, err := delta.ExtractAtomicTx(txBytes, v.codec)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tx, height, nil
+}
+
+// GetTxsAtHeight returns the atomic txs accepted in the block at [height].
+// Returns ErrNotFound if the block does not contain atomic txs.
+func (v *AtomicStateView) GetTxsAtHeight(height uint64) ([]*delta.Tx, error) {
+	txsBytes, err := v.heightTable.get(heightKey(height))
+	if err != nil {
+		return nil, err
+	}
+	return delta.ExtractAtomicTxsBatch(txsBytes, v.codec)
+}
+
+// NewIterator returns an iterator over the heights in [start, end] containing
+// atomic txs, in increasing order.
+// The iterator must be released after use.
+func (v *AtomicStateView) NewIterator(start, end uint64) *Iterator {
+	return &Iterator{
+		it:    v.heightTable.newIterator(heightKey(start)),
+		end:   end,
+		codec: v.codec,
+	}
+}
+
+// Iterator iterates the atomic txs accepted on a range of heights.
+type Iterator struct {
+	it    ethdb.Iterator
+	end   uint64
+	codec codec.Manager
+
+	height uint64
+	txs    []*delta.Tx
+	err    error
+}
+
+// Next moves the iterator to the next height containing atomic txs, returning
+// false once the range is exhausted or an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil || !it.it.Next() {
+		return false
+	}
+	key := it.it.Key()
+	if len(key) != wrappers.LongLen {
+		it.err = fmt.Errorf("unexpected length for height key %d", len(key))
+		return false
+	}
+	height := binary.BigEndian.Uint64(key)
+	if height > it.end {
+		return false
+	}
+	txs, err := delta.ExtractAtomicTxsBatch(it.it.Value(), it.codec)
+	if err != nil {
+		it.err = fmt.Errorf("failed to parse atomic txs at height %d: %w", height, err)
+		return false
+	}
+	it.height, it.txs = height, txs
+	return true
+}
+
+// Height returns the height of the current block.
+func (it *Iterator) Height() uint64 { return it.height }
+
+// Txs returns the atomic txs accepted in the current block.
+func (it *Iterator) Txs() []*delta.Tx { return it.txs }
+
+// Error returns any error encountered while iterating.
+func (it *Iterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Error()
+}
+
+// Release releases the resources held by the iterator.
+func (it *Iterator) Release() { it.it.Release() }
+
+func heightKey(height uint64) []byte {
+	key := make([]byte, wrappers.LongLen)
+	binary.BigEndian.PutUint64(key, height)
+	return key
+}
+
+// table reads the keys written to [db] through a prefixdb with the same
+// prefix.
+type table struct {
+	db     ethdb.KeyValueStore
+	prefix []byte
+}
+
+func newTable(db ethdb.KeyValueStore, prefix []byte) table {
+	return table{
+		db:     db,
+		prefix: hashing.ComputeHash256(prefix),
+	}
+}
+
+func (t table) key(key []byte) []byte {
+	prefixedKey := make([]byte, len(t.prefix)+len(key))
+	copy(prefixedKey, t.prefix)
+	copy(prefixedKey[len(t.prefix):], key)
+	return prefixedKey
+}
+
+func (t table) get(key []byte) ([]byte, error) {
+	prefixedKey := t.key(key)
+	has, err := t.db.Has(prefixedKey)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrNotFound
+	}
+	return t.db.Get(prefixedKey)
+}
+
+func (t table) newIterator(start []byte) ethdb.Iterator {
+	return &tableIterator{
+		Iterator: t.db.NewIterator(t.prefix, start),
+		prefix:   t.prefix,
+	}
+}
+
+// tableIterator strips the prefix of its table from the keys it returns.
+type tableIterator struct {
+	ethdb.Iterator
+	prefix []byte
+}
+
+func (it *tableIterator) Key() []byte {
+	return it.Iterator.Key()[len(it.prefix):]
+}
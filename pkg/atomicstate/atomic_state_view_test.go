@@ -0,0 +1,141 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomicstate
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/database/leveldb"
+	"github.com/DioneProtocol/odysseygo/database/versiondb"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/logging"
+
+	"github.com/DioneProtocol/coreth/plugin/delta"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testLastAcceptedHeight = 10
+
+func newTestExportTx(t *testing.T, nonce uint64) *delta.Tx {
+	tx := &delta.Tx{UnsignedAtomicTx: &delta.UnsignedExportTx{
+		NetworkID:        1,
+		BlockchainID:     ids.GenerateTestID(),
+		DestinationChain: ids.GenerateTestID(),
+		Ins: []delta.DELTAInput{{
+			Address: common.Address{1},
+			Amount:  nonce + 1,
+			AssetID: ids.GenerateTestID(),
+			Nonce:   nonce,
+		}},
+	}}
+	require.NoError(t, tx.Sign(delta.Codec, nil))
+	return tx
+}
+
+// newTestDB returns a LevelDB instance populated by an atomic repository with
+// two atomic txs accepted on every third height up to
+// [testLastAcceptedHeight], along with the txs indexed by height.
+func newTestDB(t *testing.T) (database.Database, map[uint64][]*delta.Tx) {
+	db, err := leveldb.New(t.TempDir(), nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	vdb := versiondb.New(db)
+	repo, err := delta.NewAtomicTxRepository(vdb, delta.Codec, 0, nil, nil, nil)
+	require.NoError(t, err)
+
+	txs := make(map[uint64][]*delta.Tx)
+	for height := uint64(1); height <= testLastAcceptedHeight; height++ {
+		if height%3 == 0 {
+			txs[height] = []*delta.Tx{newTestExportTx(t, 2*height), newTestExportTx(t, 2*height+1)}
+		}
+		require.NoError(t, repo.Write(height, txs[height]))
+	}
+	require.NoError(t, vdb.Commit())
+	return db, txs
+}
+
+func txIDs(txs []*delta.Tx) []ids.ID {
+	txIDs := make([]ids.ID, len(txs))
+	for i, tx := range txs {
+		txIDs[i] = tx.ID()
+	}
+	return txIDs
+}
+
+func TestOpenAtomicStateViewNotIndexed(t *testing.T) {
+	db, err := leveldb.New(t.TempDir(), nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = OpenAtomicStateView(delta.Database{Database: db})
+	require.ErrorIs(t, err, errNotIndexed)
+}
+
+func TestAtomicStateViewGetTx(t *testing.T) {
+	require := require.New(t)
+
+	db, txs := newTestDB(t)
+	view, err := OpenAtomicStateView(delta.Database{Database: db})
+	require.NoError(err)
+	require.EqualValues(testLastAcceptedHeight, view.IndexHeight())
+
+	for height, heightTxs := range txs {
+		for _, expectedTx := range heightTxs {
+			tx, txHeight, err := view.GetTx(expectedTx.ID())
+			require.NoError(err)
+			require.Equal(height, txHeight)
+			require.Equal(expectedTx.ID(), tx.ID())
+			require.Equal(expectedTx.SignedBytes(), tx.SignedBytes())
+		}
+
+		heightTxs, err := view.GetTxsAtHeight(height)
+		require.NoError(err)
+		require.ElementsMatch(txIDs(txs[height]), txIDs(heightTxs))
+	}
+
+	_, _, err = view.GetTx(ids.GenerateTestID())
+	require.ErrorIs(err, ErrNotFound)
+	_, err = view.GetTxsAtHeight(1)
+	require.ErrorIs(err, ErrNotFound)
+}
+
+func TestAtomicStateViewIterator(t *testing.T) {
+	db, txs := newTestDB(t)
+	view, err := OpenAtomicStateView(delta.Database{Database: db})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name            string
+		start, end      uint64
+		expectedHeights []uint64
+	}{
+		{name: "all", start: 0, end: testLastAcceptedHeight, expectedHeights: []uint64{3, 6, 9}},
+		{name: "inclusive bounds", start: 3, end: 6, expectedHeights: []uint64{3, 6}},
+		{name: "exclusive of neighbours", start: 4, end: 8, expectedHeights: []uint64{6}},
+		{name: "past index height", start: 10, end: 20},
+		{name: "empty range", start: 7, end: 5},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			it := view.NewIterator(test.start, test.end)
+			defer it.Release()
+
+			var heights []uint64
+			for it.Next() {
+				heights = append(heights, it.Height())
+				require.ElementsMatch(txIDs(txs[it.Height()]), txIDs(it.Txs()))
+			}
+			require.NoError(it.Error())
+			require.Equal(test.expectedHeights, heights)
+		})
+	}
+}
@@ -4,53 +4,88 @@
 package delta
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/DioneProtocol/odysseygo/api"
-	"github.com/DioneProtocol/odysseygo/utils/profiler"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/json"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/params"
 )
 
+var errMissingChainConfig = errors.New("missing chain config")
+
 // Admin is the API service for admin API calls
 type Admin struct {
 	vm       *VM
-	profiler profiler.Profiler
+	profiler *adminProfiler
 }
 
 func NewAdminService(vm *VM, performanceDir string) *Admin {
 	return &Admin{
 		vm:       vm,
-		profiler: profiler.New(performanceDir),
+		profiler: newAdminProfiler(performanceDir, &vm.clock),
 	}
 }
 
-// StartCPUProfiler starts a cpu profile writing to the specified file
-func (p *Admin) StartCPUProfiler(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+// ProfileReply is the file a profile is written to
+type ProfileReply struct {
+	Path string `json:"path"`
+}
+
+// StartCPUProfiler starts a cpu profile writing to a new file in the
+// performance directory
+func (p *Admin) StartCPUProfiler(_ *http.Request, _ *struct{}, reply *ProfileReply) error {
 	log.Info("Admin: StartCPUProfiler called")
 
-	return p.profiler.StartCPUProfiler()
+	path, err := p.profiler.StartCPUProfiler()
+	reply.Path = path
+	return err
 }
 
 // StopCPUProfiler stops the cpu profile
-func (p *Admin) StopCPUProfiler(r *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+func (p *Admin) StopCPUProfiler(_ *http.Request, _ *struct{}, reply *ProfileReply) error {
 	log.Info("Admin: StopCPUProfiler called")
 
-	return p.profiler.StopCPUProfiler()
+	path, err := p.profiler.StopCPUProfiler()
+	reply.Path = path
+	return err
 }
 
-// MemoryProfile runs a memory profile writing to the specified file
-func (p *Admin) MemoryProfile(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+// MemoryProfile runs a memory profile writing to a new file in the performance
+// directory
+func (p *Admin) MemoryProfile(_ *http.Request, _ *struct{}, reply *ProfileReply) error {
 	log.Info("Admin: MemoryProfile called")
 
-	return p.profiler.MemoryProfile()
+	path, err := p.profiler.MemoryProfile()
+	reply.Path = path
+	return err
 }
 
-// LockProfile runs a mutex profile writing to the specified file
-func (p *Admin) LockProfile(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+// LockProfile runs a mutex profile writing to a new file in the performance
+// directory
+func (p *Admin) LockProfile(_ *http.Request, _ *struct{}, reply *ProfileReply) error {
 	log.Info("Admin: LockProfile called")
 
-	return p.profiler.LockProfile()
+	path, err := p.profiler.LockProfile()
+	reply.Path = path
+	return err
+}
+
+// ProfileStatus returns whether a cpu profile is running and the files of the
+// last profiles
+func (p *Admin) ProfileStatus(_ *http.Request, _ *struct{}, reply *ProfileStatus) error {
+	log.Info("Admin: ProfileStatus called")
+
+	*reply = p.profiler.Status()
+	return nil
 }
 
 type SetLogLevelArgs struct {
@@ -65,6 +100,17 @@ func (p *Admin) SetLogLevel(_ *http.Request, args *SetLogLevelArgs, reply *api.E
 	return nil
 }
 
+type SetLogFormatArgs struct {
+	Format string `json:"format"`
+}
+
+// SetLogFormat switches the format of the logs of the VM, without a restart,
+// to either "terminal" or "json".
+func (p *Admin) SetLogFormat(_ *http.Request, args *SetLogFormatArgs, reply *api.EmptyReply) error {
+	log.Info("DELTA: SetLogFormat called", "logFormat", args.Format)
+	return p.vm.logger.SetLogFormat(args.Format)
+}
+
 type ConfigReply struct {
 	Config *Config `json:"config"`
 }
@@ -73,3 +119,112 @@ func (p *Admin) GetVMConfig(_ *http.Request, _ *struct{}, reply *ConfigReply) er
 	reply.Config = &p.vm.config
 	return nil
 }
+
+// GetBonusBlocks returns the bonus blocks whose atomic txs this node skips
+func (p *Admin) GetBonusBlocks(_ *http.Request, _ *struct{}, reply *BonusBlockSet) error {
+	log.Info("DELTA: GetBonusBlocks called")
+
+	*reply = *p.vm.bonusBlocks
+	return nil
+}
+
+type GetConfigCompatibilityArgs struct {
+	Config *params.ChainConfig `json:"config"`
+}
+
+type GetConfigCompatibilityReply struct {
+	Compatible bool `json:"compatible"`
+	// Incompatibility describes why [Config] would be rejected on startup, if it
+	// is not compatible.
+	Incompatibility *ConfigCompatibilityError `json:"incompatibility,omitempty"`
+}
+
+// GetConfigCompatibility checks whether the candidate chain config in [args]
+// would be accepted on startup at the current last accepted block, so that
+// operators can dry-run an upgrade. The check takes the upgrade check override
+// into account, but is performed even if skip-upgrade-check is enabled.
+func (p *Admin) GetConfigCompatibility(_ *http.Request, args *GetConfigCompatibilityArgs, reply *GetConfigCompatibilityReply) error {
+	log.Info("DELTA: GetConfigCompatibility called")
+
+	if args.Config == nil {
+		return errMissingChainConfig
+	}
+	if err := args.Config.CheckConfigForkOrder(); err != nil {
+		return fmt.Errorf("invalid chain config: %w", err)
+	}
+	lastAccepted := p.vm.blockChain.LastConsensusAcceptedBlock()
+	compatErr := core.CheckChainConfigCompatible(
+		p.vm.chainConfig,
+		args.Config,
+		lastAccepted.NumberU64(),
+		lastAccepted.Time(),
+		p.vm.ethConfig.UpgradeCheckOverride,
+	)
+	reply.Compatible = compatErr == nil
+	if compatErr != nil {
+		reply.Incompatibility = newConfigCompatibilityError(compatErr)
+	}
+	return nil
+}
+
+type GetNetworkUpgradeScheduleReply struct {
+	Upgrades []UpgradeEvent `json:"upgrades"`
+}
+
+// GetNetworkUpgradeSchedule returns the past and scheduled network upgrades
+// of the chain and whether they have been activated
+func (p *Admin) GetNetworkUpgradeSchedule(_ *http.Request, _ *struct{}, reply *GetNetworkUpgradeScheduleReply) error {
+	log.Info("DELTA: GetNetworkUpgradeSchedule called")
+
+	upgrades, err := p.vm.GetNetworkUpgradeSchedule()
+	if err != nil {
+		return err
+	}
+	reply.Upgrades = upgrades
+	return nil
+}
+
+type GetStateAtBlockArgs struct {
+	BlockID ids.ID `json:"blockID"`
+	// Addresses are the accounts to report from the state of the block.
+	Addresses []common.Address `json:"addresses"`
+}
+
+// StateAccount is an account in the state of a block returned by
+// GetStateAtBlock.
+type StateAccount struct {
+	Address common.Address `json:"address"`
+	Balance *hexutil.Big   `json:"balance"`
+	Nonce   json.Uint64    `json:"nonce"`
+}
+
+type GetStateAtBlockReply struct {
+	BlockID  ids.ID         `json:"blockID"`
+	Height   json.Uint64    `json:"height"`
+	Root     common.Hash    `json:"root"`
+	Accounts []StateAccount `json:"accounts"`
+}
+
+// GetStateAtBlock checks that the state after block [args.BlockID] is
+// available and returns the accounts [args.Addresses] in that state
+func (p *Admin) GetStateAtBlock(_ *http.Request, args *GetStateAtBlockArgs, reply *GetStateAtBlockReply) error {
+	log.Info("DELTA: GetStateAtBlock called", "blockID", args.BlockID)
+
+	statedb, err := p.vm.GetStateAtBlock(context.Background(), args.BlockID)
+	if err != nil {
+		return err
+	}
+	header := p.vm.blockChain.GetHeaderByHash(common.Hash(args.BlockID))
+	reply.BlockID = args.BlockID
+	reply.Height = json.Uint64(header.Number.Uint64())
+	reply.Root = header.Root
+	reply.Accounts = make([]StateAccount, len(args.Addresses))
+	for i, addr := range args.Addresses {
+		reply.Accounts[i] = StateAccount{
+			Address: addr,
+			Balance: (*hexutil.Big)(statedb.GetBalance(addr)),
+			Nonce:   json.Uint64(statedb.GetNonce(addr)),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/DioneProtocol/odysseygo/utils/timer/mockable"
+)
+
+const (
+	// minCPUProfilerInterval is the minimum time between the starts of two
+	// CPU profiles requested through the admin API.
+	minCPUProfilerInterval = time.Minute
+
+	// profileTimeFormat is the format of the timestamp suffix of the name of
+	// each profile written by the admin API.
+	profileTimeFormat = "20060102T150405.000000000Z"
+)
+
+var (
+	errCPUProfilerRunning     = errors.New("cpu profiler is already running")
+	errCPUProfilerNotRunning  = errors.New("cpu profiler is not running")
+	errCPUProfilerRateLimited = errors.New("cpu profiler was started too recently")
+)
+
+// ProfileStatus describes the profiler of the admin API.
+type ProfileStatus struct {
+	CPUProfilerRunning bool `json:"cpuProfilerRunning"`
+	// CPUProfile is the file the running CPU profile is written to.
+	CPUProfile string `json:"cpuProfile,omitempty"`
+	// CPUProfileStarted is the time the last CPU profile was started.
+	CPUProfileStarted *time.Time `json:"cpuProfileStarted,omitempty"`
+	// LastCPUProfile, LastMemoryProfile and LastLockProfile are the files of
+	// the last completed profile of each kind.
+	LastCPUProfile    string `json:"lastCPUProfile,omitempty"`
+	LastMemoryProfile string `json:"lastMemoryProfile,omitempty"`
+	LastLockProfile   string `json:"lastLockProfile,omitempty"`
+}
+
+// adminProfiler writes the profiles requested through the admin API to [dir].
+// Unlike profiler.Profiler, each profile is written to a new file whose name
+// is suffixed with the time it was requested, so that previous profiles are
+// never overwritten, and CPU profiles cannot be started more often than every
+// [minCPUInterval].
+type adminProfiler struct {
+	lock sync.Mutex
+
+	dir            string
+	clock          *mockable.Clock
+	minCPUInterval time.Duration
+
+	cpuProfileFile *os.File
+	status         ProfileStatus
+}
+
+func newAdminProfiler(dir string, clock *mockable.Clock) *adminProfiler {
+	return &adminProfiler{
+		dir:            dir,
+		clock:          clock,
+		minCPUInterval: minCPUProfilerInterval,
+	}
+}
+
+// StartCPUProfiler starts a CPU profile and returns the file it is written to.
+func (p *adminProfiler) StartCPUProfiler() (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cpuProfileFile != nil {
+		return "", fmt.Errorf("%w: writing to %s", errCPUProfilerRunning, p.status.CPUProfile)
+	}
+	now := p.clock.Time()
+	if started := p.status.CPUProfileStarted; started != nil {
+		if next := started.Add(p.minCPUInterval); now.Before(next) {
+			return "", fmt.Errorf("%w: next start allowed at %s", errCPUProfilerRateLimited, next.UTC().Format(time.RFC3339))
+		}
+	}
+
+	file, err := p.create("cpu", now)
+	if err != nil {
+		return "", err
+	}
+	if err := pprof.StartCPUProfile(file); err != nil {
+		_ = file.Close() // Return the original error
+		return "", err
+	}
+	runtime.SetMutexProfileFraction(1)
+
+	p.cpuProfileFile = file
+	p.status.CPUProfilerRunning = true
+	p.status.CPUProfile = file.Name()
+	p.status.CPUProfileStarted = &now
+	return file.Name(), nil
+}
+
+// StopCPUProfiler stops the running CPU profile and returns the file it was
+// written to.
+func (p *adminProfiler) StopCPUProfiler() (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cpuProfileFile == nil {
+		return "", errCPUProfilerNotRunning
+	}
+
+	pprof.StopCPUProfile()
+	err := p.cpuProfileFile.Close()
+	p.cpuProfileFile = nil
+	p.status.CPUProfilerRunning = false
+	p.status.LastCPUProfile = p.status.CPUProfile
+	p.status.CPUProfile = ""
+	return p.status.LastCPUProfile, err
+}
+
+// MemoryProfile writes a heap profile and returns the file it was written to.
+func (p *adminProfiler) MemoryProfile() (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	file, err := p.create("mem", p.clock.Time())
+	if err != nil {
+		return "", err
+	}
+	runtime.GC() // get up-to-date statistics
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		_ = file.Close() // Return the original error
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	p.status.LastMemoryProfile = file.Name()
+	return file.Name(), nil
+}
+
+// LockProfile writes a mutex profile and returns the file it was written to.
+func (p *adminProfiler) LockProfile() (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	file, err := p.create("lock", p.clock.Time())
+	if err != nil {
+		return "", err
+	}
+	if err := pprof.Lookup("mutex").WriteTo(file, 1); err != nil {
+		_ = file.Close() // Return the original error
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	p.status.LastLockProfile = file.Name()
+	return file.Name(), nil
+}
+
+// Status returns the state of the running CPU profile and the last profile of
+// each kind.
+func (p *adminProfiler) Status() ProfileStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.status
+}
+
+// create creates the file of a [kind] profile requested at [now]. It fails
+// rather than overwriting an existing profile.
+// Assumes the lock is held.
+func (p *adminProfiler) create(kind string, now time.Time) (*os.File, error) {
+	if err := os.MkdirAll(p.dir, perms.ReadWriteExecute); err != nil {
+		return nil, err
+	}
+	name := filepath.Join(p.dir, fmt.Sprintf("%s.%s.profile", kind, now.UTC().Format(profileTimeFormat)))
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perms.ReadWrite)
+}
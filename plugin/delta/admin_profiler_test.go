@@ -0,0 +1,130 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/utils/timer/mockable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminProfilerCPUProfile(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		dir   = t.TempDir()
+		clock = &mockable.Clock{}
+		start = time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+	)
+	clock.Set(start)
+	p := newAdminProfiler(dir, clock)
+
+	_, err := p.StopCPUProfiler()
+	require.ErrorIs(err, errCPUProfilerNotRunning)
+	require.Equal(ProfileStatus{}, p.Status())
+
+	path, err := p.StartCPUProfiler()
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "cpu.20240301T123045.123456789Z.profile"), path)
+	require.Equal(ProfileStatus{
+		CPUProfilerRunning: true,
+		CPUProfile:         path,
+		CPUProfileStarted:  &start,
+	}, p.Status())
+
+	// A running profile is not clobbered by a second start.
+	_, err = p.StartCPUProfiler()
+	require.ErrorIs(err, errCPUProfilerRunning)
+
+	stopped, err := p.StopCPUProfiler()
+	require.NoError(err)
+	require.Equal(path, stopped)
+	require.FileExists(path)
+	status := p.Status()
+	require.False(status.CPUProfilerRunning)
+	require.Empty(status.CPUProfile)
+	require.Equal(path, status.LastCPUProfile)
+
+	// Profiles cannot be started more often than the minimum interval.
+	clock.Set(start.Add(minCPUProfilerInterval - time.Second))
+	_, err = p.StartCPUProfiler()
+	require.ErrorIs(err, errCPUProfilerRateLimited)
+
+	clock.Set(start.Add(minCPUProfilerInterval))
+	next, err := p.StartCPUProfiler()
+	require.NoError(err)
+	require.NotEqual(path, next)
+	_, err = p.StopCPUProfiler()
+	require.NoError(err)
+	require.FileExists(path)
+	require.Equal(next, p.Status().LastCPUProfile)
+}
+
+func TestAdminProfilerSnapshots(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		dir   = t.TempDir()
+		clock = &mockable.Clock{}
+		now   = time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	)
+	clock.Set(now)
+	p := newAdminProfiler(dir, clock)
+
+	memPath, err := p.MemoryProfile()
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "mem.20240301T123045.000000000Z.profile"), memPath)
+	lockPath, err := p.LockProfile()
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "lock.20240301T123045.000000000Z.profile"), lockPath)
+
+	// A profile requested at the same time fails rather than overwriting the
+	// previous one.
+	memBytes, err := os.ReadFile(memPath)
+	require.NoError(err)
+	_, err = p.MemoryProfile()
+	require.ErrorIs(err, os.ErrExist)
+	overwritten, err := os.ReadFile(memPath)
+	require.NoError(err)
+	require.Equal(memBytes, overwritten)
+
+	clock.Set(now.Add(time.Second))
+	nextMemPath, err := p.MemoryProfile()
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "mem.20240301T123046.000000000Z.profile"), nextMemPath)
+	require.FileExists(memPath)
+
+	status := p.Status()
+	require.Equal(nextMemPath, status.LastMemoryProfile)
+	require.Equal(lockPath, status.LastLockProfile)
+	require.Empty(status.LastCPUProfile)
+}
+
+func TestAdminProfileStatus(t *testing.T) {
+	require := require.New(t)
+
+	admin := NewAdminService(&VM{}, t.TempDir())
+	start := &ProfileReply{}
+	require.NoError(admin.StartCPUProfiler(nil, nil, start))
+	require.FileExists(start.Path)
+
+	status := &ProfileStatus{}
+	require.NoError(admin.ProfileStatus(nil, nil, status))
+	require.True(status.CPUProfilerRunning)
+	require.Equal(start.Path, status.CPUProfile)
+
+	stop := &ProfileReply{}
+	require.NoError(admin.StopCPUProfiler(nil, nil, stop))
+	require.Equal(start.Path, stop.Path)
+	require.ErrorIs(admin.StopCPUProfiler(nil, nil, &ProfileReply{}), errCPUProfilerNotRunning)
+
+	status = &ProfileStatus{}
+	require.NoError(admin.ProfileStatus(nil, nil, status))
+	require.False(status.CPUProfilerRunning)
+	require.Equal(start.Path, status.LastCPUProfile)
+}
@@ -4,8 +4,10 @@
 package delta
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sort"
 	"time"
 
 	syncclient "github.com/DioneProtocol/coreth/sync/client"
@@ -64,6 +66,33 @@ type AtomicBackend interface {
 
 	// IsBonus returns true if the block for atomicState is a bonus block
 	IsBonus(blockHeight uint64, blockHash common.Hash) bool
+
+	// Status returns a snapshot of the atomic trie and of the atomic states
+	// that have been verified but not accepted or rejected yet.
+	Status() AtomicBackendStatus
+}
+
+// AtomicBackendStatus is a snapshot of the state of an AtomicBackend.
+type AtomicBackendStatus struct {
+	LastCommittedRoot   common.Hash
+	LastCommittedHeight uint64
+	// CommitInterval is the interval of heights at which the atomic trie is
+	// committed.
+	CommitInterval   uint64
+	LastAcceptedHash common.Hash
+	LastAcceptedRoot common.Hash
+	// Verified are the atomic states of the blocks that have been verified
+	// but not accepted or rejected yet, ordered by height and then hash.
+	Verified []VerifiedAtomicState
+}
+
+// VerifiedAtomicState describes the atomic state of a block that has been
+// verified but not accepted or rejected yet.
+type VerifiedAtomicState struct {
+	BlockHash   common.Hash
+	BlockHeight uint64
+	Root        common.Hash
+	NumTxs      int
 }
 
 // atomicBackend implements the AtomicBackend interface using
@@ -80,6 +109,7 @@ type atomicBackend struct {
 
 	lastAcceptedHash common.Hash
 	verifiedRoots    map[common.Hash]AtomicState
+	commitInterval   uint64
 }
 
 // NewAtomicBackend creates an AtomicBackend from the specified dependencies
@@ -106,6 +136,7 @@ func NewAtomicBackend(
 		atomicTrie:       atomicTrie,
 		lastAcceptedHash: lastAcceptedHash,
 		verifiedRoots:    make(map[common.Hash]AtomicState),
+		commitInterval:   commitInterval,
 	}
 
 	// We call ApplyToSharedMemory here to ensure that if the node was shut down in the middle
@@ -415,3 +446,33 @@ func (a *atomicBackend) IsBonus(blockHeight uint64, blockHash common.Hash) bool
 func (a *atomicBackend) AtomicTrie() AtomicTrie {
 	return a.atomicTrie
 }
+
+func (a *atomicBackend) Status() AtomicBackendStatus {
+	lastCommittedRoot, lastCommittedHeight := a.atomicTrie.LastCommitted()
+	status := AtomicBackendStatus{
+		LastCommittedRoot:   lastCommittedRoot,
+		LastCommittedHeight: lastCommittedHeight,
+		CommitInterval:      a.commitInterval,
+		LastAcceptedHash:    a.lastAcceptedHash,
+		LastAcceptedRoot:    a.atomicTrie.LastAcceptedRoot(),
+		Verified:            make([]VerifiedAtomicState, 0, len(a.verifiedRoots)),
+	}
+	for blockHash, state := range a.verifiedRoots {
+		verified := VerifiedAtomicState{
+			BlockHash: blockHash,
+			Root:      state.Root(),
+		}
+		if state, ok := state.(*atomicState); ok {
+			verified.BlockHeight = state.blockHeight
+			verified.NumTxs = len(state.txs)
+		}
+		status.Verified = append(status.Verified, verified)
+	}
+	sort.Slice(status.Verified, func(i, j int) bool {
+		if status.Verified[i].BlockHeight != status.Verified[j].BlockHeight {
+			return status.Verified[i].BlockHeight < status.Verified[j].BlockHeight
+		}
+		return bytes.Compare(status.Verified[i].BlockHash[:], status.Verified[j].BlockHash[:]) < 0
+	})
+	return status
+}
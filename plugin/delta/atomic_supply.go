@@ -0,0 +1,103 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DioneProtocol/coreth/core/state"
+	"github.com/DioneProtocol/odysseygo/ids"
+	safemath "github.com/DioneProtocol/odysseygo/utils/math"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var errAtomicSupplyMismatch = errors.New("atomic tx changed DIONE supply by an unexpected amount")
+
+// atomicSupplyCheck verifies that the DIONE balance changes made by the state
+// transfer of an atomic tx match the DIONE the tx imports or exports.
+type atomicSupplyCheck struct {
+	txID ids.ID
+	// expected is the change of the DIONE supply on this chain, in wei, that
+	// the tx should cause.
+	expected *big.Int
+	// addrs are the addresses whose DIONE balance the tx modifies.
+	addrs []common.Address
+	// before is the total DIONE balance of [addrs] before the state transfer.
+	before *big.Int
+}
+
+// newAtomicSupplyCheck records the DIONE balances of the addresses modified by
+// [tx] in [state]. It must be called before the state transfer of [tx].
+func newAtomicSupplyCheck(tx *Tx, dioneAssetID ids.ID, state *state.StateDB) (*atomicSupplyCheck, error) {
+	var (
+		amount uint64
+		addrs  []common.Address
+		minted bool
+		err    error
+	)
+	switch utx := tx.UnsignedAtomicTx.(type) {
+	case *UnsignedImportTx:
+		minted = true
+		for _, out := range utx.Outs {
+			if out.AssetID != dioneAssetID {
+				continue
+			}
+			if amount, err = safemath.Add64(amount, out.Amount); err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, out.Address)
+		}
+	case *UnsignedExportTx:
+		for _, in := range utx.Ins {
+			if in.AssetID != dioneAssetID {
+				continue
+			}
+			if amount, err = safemath.Add64(amount, in.Amount); err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, in.Address)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected atomic tx type %T", utx)
+	}
+
+	expected := new(big.Int).Mul(new(big.Int).SetUint64(amount), x2cRate)
+	if !minted {
+		expected.Neg(expected)
+	}
+	return &atomicSupplyCheck{
+		txID:     tx.ID(),
+		expected: expected,
+		addrs:    addrs,
+		before:   totalBalance(state, addrs),
+	}, nil
+}
+
+// Verify returns an error if the DIONE balances recorded by
+// newAtomicSupplyCheck changed by anything other than the expected amount. It
+// must be called after the state transfer of the tx.
+func (c *atomicSupplyCheck) Verify(state *state.StateDB) error {
+	actual := new(big.Int).Sub(totalBalance(state, c.addrs), c.before)
+	if actual.Cmp(c.expected) != 0 {
+		return fmt.Errorf("%w: tx %s changed DIONE balances by %d wei, expected %d wei", errAtomicSupplyMismatch, c.txID, actual, c.expected)
+	}
+	return nil
+}
+
+// totalBalance returns the sum of the DIONE balances of the distinct addresses
+// in [addrs].
+func totalBalance(state *state.StateDB, addrs []common.Address) *big.Int {
+	total := new(big.Int)
+	seen := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		total.Add(total, state.GetBalance(addr))
+	}
+	return total
+}
@@ -0,0 +1,97 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/coreth/core/rawdb"
+	"github.com/DioneProtocol/coreth/core/state"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/snow"
+	safemath "github.com/DioneProtocol/odysseygo/utils/math"
+	"github.com/DioneProtocol/odysseygo/utils/units"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicSupplyCheck(t *testing.T) {
+	ctx := snow.DefaultContextTest()
+	ctx.DIONEAssetID = ids.GenerateTestID()
+	otherAssetID := ids.GenerateTestID()
+
+	importTx := &Tx{UnsignedAtomicTx: &UnsignedImportTx{
+		Outs: []DELTAOutput{
+			{Address: testEthAddrs[0], Amount: units.Dione, AssetID: ctx.DIONEAssetID},
+			{Address: testEthAddrs[0], Amount: units.Dione, AssetID: otherAssetID},
+			{Address: testEthAddrs[1], Amount: 2 * units.Dione, AssetID: ctx.DIONEAssetID},
+		},
+	}}
+	exportTx := &Tx{UnsignedAtomicTx: &UnsignedExportTx{
+		Ins: []DELTAInput{
+			{Address: testEthAddrs[0], Amount: units.Dione, AssetID: ctx.DIONEAssetID},
+			{Address: testEthAddrs[0], Amount: units.Dione, AssetID: otherAssetID},
+		},
+	}}
+
+	tests := map[string]struct {
+		tx          *Tx
+		tamper      func(*state.StateDB)
+		expectedErr error
+	}{
+		"import": {
+			tx: importTx,
+		},
+		"export": {
+			tx: exportTx,
+		},
+		"import minting extra DIONE": {
+			tx:          importTx,
+			tamper:      func(sdb *state.StateDB) { sdb.AddBalance(testEthAddrs[1], big.NewInt(1)) },
+			expectedErr: errAtomicSupplyMismatch,
+		},
+		"export burning extra DIONE": {
+			tx:          exportTx,
+			tamper:      func(sdb *state.StateDB) { sdb.SubBalance(testEthAddrs[0], big.NewInt(1)) },
+			expectedErr: errAtomicSupplyMismatch,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			sdb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			sdb.AddBalance(testEthAddrs[0], new(big.Int).Mul(new(big.Int).SetUint64(10*units.Dione), x2cRate))
+			sdb.AddBalanceMultiCoin(testEthAddrs[0], common.Hash(otherAssetID), new(big.Int).SetUint64(10*units.Dione))
+
+			check, err := newAtomicSupplyCheck(test.tx, ctx.DIONEAssetID, sdb)
+			require.NoError(err)
+			require.NoError(test.tx.UnsignedAtomicTx.DELTAStateTransfer(ctx, sdb, params.TestChainConfig.OdysseyRules(common.Big0, 0)))
+			if test.tamper != nil {
+				test.tamper(sdb)
+			}
+			require.ErrorIs(check.Verify(sdb), test.expectedErr)
+		})
+	}
+}
+
+func TestAtomicSupplyCheckOverflow(t *testing.T) {
+	dioneAssetID := ids.GenerateTestID()
+	tx := &Tx{UnsignedAtomicTx: &UnsignedImportTx{
+		Outs: []DELTAOutput{
+			{Address: testEthAddrs[0], Amount: math.MaxUint64, AssetID: dioneAssetID},
+			{Address: testEthAddrs[1], Amount: 1, AssetID: dioneAssetID},
+		},
+	}}
+
+	sdb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	_, err = newAtomicSupplyCheck(tx, dioneAssetID, sdb)
+	require.ErrorIs(t, err, safemath.ErrOverflow)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/DioneProtocol/coreth/core"
 	"github.com/DioneProtocol/coreth/core/types"
 	"github.com/DioneProtocol/coreth/ethdb"
+	"github.com/DioneProtocol/coreth/metrics"
 	"github.com/DioneProtocol/coreth/trie"
 	"github.com/DioneProtocol/coreth/trie/trienode"
 	"github.com/ethereum/go-ethereum/common"
@@ -64,7 +65,8 @@ type AtomicTrie interface {
 
 	// Root returns hash if it exists at specified height
 	// if trie was not committed at provided height, it returns
-	// common.Hash{} instead
+	// common.Hash{} instead. Returns ErrPruned if the root at
+	// height has been pruned.
 	Root(height uint64) (common.Hash, error)
 
 	// LastAcceptedRoot returns the most recent accepted root of the atomic trie,
@@ -83,6 +85,10 @@ type AtomicTrie interface {
 
 	// RejectTrie dereferences root from the trieDB, freeing memory.
 	RejectTrie(root common.Hash) error
+
+	// Prune deletes the trie nodes that are only referenced by roots committed
+	// below beforeHeight, along with those roots.
+	Prune(beforeHeight uint64) error
 }
 
 // AtomicTrieIterator is a stateful iterator that iterates the leafs of an AtomicTrie
@@ -113,13 +119,19 @@ type AtomicTrieIterator interface {
 type atomicTrie struct {
 	commitInterval      uint64            // commit interval, same as commitHeightInterval by default
 	metadataDB          database.Database // Underlying database containing the atomic trie metadata
+	trieDiskDB          database.Database // Underlying database containing the atomic trie nodes
 	trieDB              *trie.Database    // Trie database
 	lastCommittedRoot   common.Hash       // trie root of the most recent commit
 	lastCommittedHeight uint64            // index height of the most recent commit
 	lastAcceptedRoot    common.Hash       // most recent trie root passed to accept trie or the root of the atomic trie on intialization.
+	prunedHeight        uint64            // roots committed below this height have been pruned
 	codec               codec.Manager
 	memoryCap           common.StorageSize
 	tipBuffer           *core.BoundedBuffer[common.Hash]
+
+	prunedNodes       metrics.Counter
+	prunedBytes       metrics.Counter
+	prunedHeightGauge metrics.Gauge
 }
 
 // newAtomicTrie returns a new instance of a atomicTrie with a configurable commitHeightInterval, used in testing.
@@ -136,10 +148,17 @@ func newAtomicTrie(
 	if root == (common.Hash{}) {
 		root = types.EmptyRootHash
 	}
+	prunedHeight, err := prunedHeightIfExists(metadataDB)
+	if err != nil {
+		return nil, err
+	}
 	// If the last committed height is above the last accepted height, then we fall back to
 	// the last commit below the last accepted height.
 	if height > lastAcceptedHeight {
 		height = nearestCommitHeight(lastAcceptedHeight, commitHeightInterval)
+		if height != 0 && height < prunedHeight {
+			return nil, fmt.Errorf("%w: cannot fall back to root at height %d", ErrPruned, height)
+		}
 		root, err = getRoot(metadataDB, height)
 		if err != nil {
 			return nil, err
@@ -156,10 +175,12 @@ func newAtomicTrie(
 	return &atomicTrie{
 		commitInterval:      commitHeightInterval,
 		metadataDB:          metadataDB,
+		trieDiskDB:          atomicTrieDB,
 		trieDB:              trieDB,
 		codec:               codec,
 		lastCommittedRoot:   root,
 		lastCommittedHeight: height,
+		prunedHeight:        prunedHeight,
 		tipBuffer:           core.NewBoundedBuffer(atomicTrieTipBufferSize, trieDB.Dereference),
 		memoryCap:           atomicTrieMemoryCap,
 		// Initialize lastAcceptedRoot to the last committed root.
@@ -167,6 +188,10 @@ func newAtomicTrie(
 		// AtomicBackend will call InsertTrie/AcceptTrie on atomic ops
 		// for those blocks.
 		lastAcceptedRoot: root,
+
+		prunedNodes:       metrics.GetOrRegisterCounter("atomic_trie_pruned_nodes", nil),
+		prunedBytes:       metrics.GetOrRegisterCounter("atomic_trie_pruned_bytes", nil),
+		prunedHeightGauge: metrics.GetOrRegisterGauge("atomic_trie_pruned_height", nil),
 	}, nil
 }
 
@@ -278,6 +303,9 @@ func (a *atomicTrie) TrieDB() *trie.Database {
 // if trie was not committed at provided height, it returns
 // common.Hash{} instead
 func (a *atomicTrie) Root(height uint64) (common.Hash, error) {
+	if height != 0 && height < a.prunedHeight {
+		return common.Hash{}, fmt.Errorf("%w: height %d is below pruned height %d", ErrPruned, height, a.prunedHeight)
+	}
 	return getRoot(a.metadataDB, height)
 }
 
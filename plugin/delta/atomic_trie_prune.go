@@ -0,0 +1,196 @@
+// (c) 2020-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/utils/wrappers"
+
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	prunedHeightKey = []byte("atomicTriePrunedHeight")
+
+	// ErrPruned is returned when querying an atomic trie root that has been
+	// pruned.
+	ErrPruned = errors.New("atomic trie root has been pruned")
+
+	errPruneAboveLastCommitted = errors.New("cannot prune the atomic trie above the last committed height")
+)
+
+// prunedHeightIfExists returns the height below which the atomic trie roots
+// have been pruned, or 0 if the trie has never been pruned.
+func prunedHeightIfExists(db database.Database) (uint64, error) {
+	prunedHeightBytes, err := db.Get(prunedHeightKey)
+	switch {
+	case err == database.ErrNotFound:
+		return 0, nil
+	case err != nil:
+		return 0, err
+	case len(prunedHeightBytes) != wrappers.LongLen:
+		return 0, fmt.Errorf("expected value of prunedHeightKey to be %d but was %d", wrappers.LongLen, len(prunedHeightBytes))
+	}
+	return binary.BigEndian.Uint64(prunedHeightBytes), nil
+}
+
+// Prune deletes the roots committed below [beforeHeight] and the trie nodes
+// that are not referenced by any root committed at or above [beforeHeight].
+// The pruned height is persisted so that Root keeps returning ErrPruned for
+// the pruned heights after a restart.
+// Note: changes are written to the underlying database, which must be
+// committed by the caller.
+func (a *atomicTrie) Prune(beforeHeight uint64) error {
+	if beforeHeight > a.lastCommittedHeight {
+		return fmt.Errorf("%w: %d > %d", errPruneAboveLastCommitted, beforeHeight, a.lastCommittedHeight)
+	}
+	if beforeHeight <= a.prunedHeight {
+		return nil
+	}
+	start := time.Now()
+
+	prunedHeights, prunedRoots, retainedRoots, err := a.partitionRoots(beforeHeight)
+	if err != nil {
+		return err
+	}
+
+	// Mark the nodes of the retained roots, newest first, since older roots
+	// mostly share their nodes with newer ones.
+	live := make(map[common.Hash]struct{})
+	for i := len(retainedRoots) - 1; i >= 0; i-- {
+		if _, ok := live[retainedRoots[i]]; ok {
+			continue
+		}
+		err := a.walkTrie(retainedRoots[i], func(hash common.Hash, _ []byte) (bool, error) {
+			if _, ok := live[hash]; ok {
+				return false, nil
+			}
+			live[hash] = struct{}{}
+			return true, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark atomic trie at root %s: %w", retainedRoots[i], err)
+		}
+	}
+
+	// Delete the nodes of the pruned roots that are not marked.
+	var (
+		deleted     = make(map[common.Hash]struct{})
+		prunedBytes int
+	)
+	for _, root := range prunedRoots {
+		if _, ok := live[root]; ok {
+			continue
+		}
+		if _, ok := deleted[root]; ok {
+			continue
+		}
+		err := a.walkTrie(root, func(hash common.Hash, blob []byte) (bool, error) {
+			if _, ok := live[hash]; ok {
+				return false, nil
+			}
+			if _, ok := deleted[hash]; ok {
+				return false, nil
+			}
+			if err := a.trieDiskDB.Delete(hash[:]); err != nil {
+				return false, err
+			}
+			deleted[hash] = struct{}{}
+			prunedBytes += len(blob)
+			return true, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to prune atomic trie at root %s: %w", root, err)
+		}
+	}
+
+	heightBytes := make([]byte, wrappers.LongLen)
+	for _, height := range prunedHeights {
+		binary.BigEndian.PutUint64(heightBytes, height)
+		if err := a.metadataDB.Delete(heightBytes); err != nil {
+			return err
+		}
+	}
+	binary.BigEndian.PutUint64(heightBytes, beforeHeight)
+	if err := a.metadataDB.Put(prunedHeightKey, heightBytes); err != nil {
+		return err
+	}
+	a.prunedHeight = beforeHeight
+
+	a.prunedNodes.Inc(int64(len(deleted)))
+	a.prunedBytes.Inc(int64(prunedBytes))
+	a.prunedHeightGauge.Update(int64(beforeHeight))
+	log.Info(
+		"pruned atomic trie",
+		"beforeHeight", beforeHeight,
+		"roots", len(prunedHeights),
+		"nodes", len(deleted),
+		"size", common.StorageSize(prunedBytes),
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+// partitionRoots returns the committed roots below [beforeHeight] along with
+// their heights, and the committed roots at or above [beforeHeight], ordered
+// by height.
+func (a *atomicTrie) partitionRoots(beforeHeight uint64) ([]uint64, []common.Hash, []common.Hash, error) {
+	var (
+		prunedHeights []uint64
+		prunedRoots   []common.Hash
+		retainedRoots []common.Hash
+	)
+	it := a.metadataDB.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		// Skip the metadata entries which are not indexed by height.
+		if len(it.Key()) != wrappers.LongLen {
+			continue
+		}
+		height := binary.BigEndian.Uint64(it.Key())
+		root := common.BytesToHash(it.Value())
+		if height < beforeHeight {
+			prunedHeights = append(prunedHeights, height)
+			prunedRoots = append(prunedRoots, root)
+		} else {
+			retainedRoots = append(retainedRoots, root)
+		}
+	}
+	return prunedHeights, prunedRoots, retainedRoots, it.Error()
+}
+
+// walkTrie calls [visit] on every node of the trie at [root] which is stored
+// by hash, skipping the children of nodes for which [visit] returns false.
+func (a *atomicTrie) walkTrie(root common.Hash, visit func(hash common.Hash, blob []byte) (bool, error)) error {
+	if root == types.EmptyRootHash || root == (common.Hash{}) {
+		return nil
+	}
+	t, err := trie.New(trie.TrieID(root), a.trieDB)
+	if err != nil {
+		return err
+	}
+	it := t.NodeIterator(nil)
+	descend := true
+	for it.Next(descend) {
+		hash := it.Hash()
+		if hash == (common.Hash{}) {
+			// Embedded nodes are stored as part of their parent.
+			descend = true
+			continue
+		}
+		descend, err = visit(hash, it.NodeBlob())
+		if err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
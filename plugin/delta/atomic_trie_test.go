@@ -15,6 +15,7 @@ import (
 	"github.com/DioneProtocol/odysseygo/database"
 	"github.com/DioneProtocol/odysseygo/database/leveldb"
 	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/database/prefixdb"
 	"github.com/DioneProtocol/odysseygo/database/versiondb"
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/utils/logging"
@@ -304,6 +305,81 @@ func TestIndexerWriteAndRead(t *testing.T) {
 	}
 }
 
+func countKeys(t *testing.T, db database.Database) int {
+	t.Helper()
+	it := db.NewIterator()
+	defer it.Release()
+	count := 0
+	for it.Next() {
+		count++
+	}
+	assert.NoError(t, it.Error())
+	return count
+}
+
+func TestAtomicTriePrune(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	atomicTrieDB := prefixdb.New(atomicTrieDBPrefix, db)
+	metadataDB := prefixdb.New(atomicTrieMetaDBPrefix, db)
+	codec := testTxCodec()
+	atomicTrie, err := newAtomicTrie(atomicTrieDB, metadataDB, codec, 0, testCommitInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// process 305 blocks so that we get three commits (100, 200, 300)
+	operationsMap := make(map[uint64]map[ids.ID]*atomic.Requests)
+	roots := make(map[uint64]common.Hash)
+	for height := uint64(1); height <= testCommitInterval*3+5; height++ {
+		atomicOps := testDataImportTx().mustAtomicOps()
+		assert.NoError(t, indexAtomicTxs(atomicTrie, height, atomicOps))
+		operationsMap[height] = atomicOps
+		if height%testCommitInterval == 0 {
+			roots[height], _ = atomicTrie.LastCommitted()
+		}
+	}
+	numNodes := countKeys(t, atomicTrieDB)
+	prunedNodes := atomicTrie.prunedNodes.Count()
+
+	// pruning above the last committed height is not allowed
+	err = atomicTrie.Prune(testCommitInterval*3 + 1)
+	assert.ErrorIs(t, err, errPruneAboveLastCommitted)
+
+	assert.NoError(t, atomicTrie.Prune(testCommitInterval*3))
+	assert.Less(t, countKeys(t, atomicTrieDB), numNodes)
+	assert.Equal(t, int64(numNodes-countKeys(t, atomicTrieDB)), atomicTrie.prunedNodes.Count()-prunedNodes)
+
+	verifyPruned := func(tr AtomicTrie) {
+		for _, height := range []uint64{testCommitInterval, testCommitInterval * 2} {
+			_, err := tr.Root(height)
+			assert.ErrorIs(t, err, ErrPruned)
+		}
+		root, err := tr.Root(testCommitInterval * 3)
+		assert.NoError(t, err)
+		assert.Equal(t, roots[testCommitInterval*3], root)
+		verifyOperations(t, tr, codec, root, 1, testCommitInterval*3, operationsMap)
+
+		lastCommittedRoot, lastCommittedHeight := tr.LastCommitted()
+		assert.Equal(t, roots[testCommitInterval*3], lastCommittedRoot)
+		assert.EqualValues(t, testCommitInterval*3, lastCommittedHeight)
+	}
+	verifyPruned(atomicTrie)
+
+	// pruning at or below the pruned height is a no-op
+	numNodes = countKeys(t, atomicTrieDB)
+	assert.NoError(t, atomicTrie.Prune(testCommitInterval))
+	assert.Equal(t, numNodes, countKeys(t, atomicTrieDB))
+
+	// the pruned height should be persisted across restarts
+	assert.NoError(t, db.Commit())
+	atomicTrie, err = newAtomicTrie(atomicTrieDB, metadataDB, codec, testCommitInterval*3+5, testCommitInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.EqualValues(t, testCommitInterval*3, atomicTrie.prunedHeight)
+	verifyPruned(atomicTrie)
+}
+
 func TestAtomicOpsAreNotTxOrderDependent(t *testing.T) {
 	atomicTrie1 := newTestAtomicTrie(t)
 	atomicTrie2 := newTestAtomicTrie(t)
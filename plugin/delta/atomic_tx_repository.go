@@ -27,11 +27,13 @@ const (
 	repoCommitSizeCap = 10 * units.MiB
 )
 
+// The database layout of the atomic repository is exported so that it can be
+// read without a VM, see pkg/atomicstate.
 var (
-	atomicTxIDDBPrefix         = []byte("atomicTxDB")
-	atomicHeightTxDBPrefix     = []byte("atomicHeightTxDB")
-	atomicRepoMetadataDBPrefix = []byte("atomicRepoMetadataDB")
-	maxIndexedHeightKey        = []byte("maxIndexedAtomicTxHeight")
+	AtomicTxIDDBPrefix         = []byte("atomicTxDB")
+	AtomicHeightTxDBPrefix     = []byte("atomicHeightTxDB")
+	AtomicRepoMetadataDBPrefix = []byte("atomicRepoMetadataDB")
+	MaxIndexedHeightKey        = []byte("maxIndexedAtomicTxHeight")
 	bonusBlocksRepairedKey     = []byte("bonusBlocksRepaired")
 )
 
@@ -73,9 +75,9 @@ func NewAtomicTxRepository(
 	getAtomicTxFromBlockByHeight func(height uint64) (*Tx, error),
 ) (*atomicTxRepository, error) {
 	repo := &atomicTxRepository{
-		acceptedAtomicTxDB:         prefixdb.New(atomicTxIDDBPrefix, db),
-		acceptedAtomicTxByHeightDB: prefixdb.New(atomicHeightTxDBPrefix, db),
-		atomicRepoMetadataDB:       prefixdb.New(atomicRepoMetadataDBPrefix, db),
+		acceptedAtomicTxDB:         prefixdb.New(AtomicTxIDDBPrefix, db),
+		acceptedAtomicTxByHeightDB: prefixdb.New(AtomicHeightTxDBPrefix, db),
+		atomicRepoMetadataDB:       prefixdb.New(AtomicRepoMetadataDBPrefix, db),
 		codec:                      codec,
 		db:                         db,
 	}
@@ -101,7 +103,7 @@ func (a *atomicTxRepository) initializeHeightIndex(lastAcceptedHeight uint64) er
 	// [lastTxID] will be initialized to the last transaction that we indexed
 	// if we are part way through a migration.
 	var lastTxID ids.ID
-	indexHeightBytes, err := a.atomicRepoMetadataDB.Get(maxIndexedHeightKey)
+	indexHeightBytes, err := a.atomicRepoMetadataDB.Get(MaxIndexedHeightKey)
 	switch err {
 	case nil:
 		break
@@ -162,7 +164,7 @@ func (a *atomicTxRepository) initializeHeightIndex(lastAcceptedHeight uint64) er
 		// call commitFn to write to underlying DB if we have reached
 		// [commitSizeCap]
 		if pendingBytesApproximation > repoCommitSizeCap {
-			if err := a.atomicRepoMetadataDB.Put(maxIndexedHeightKey, lastTxID[:]); err != nil {
+			if err := a.atomicRepoMetadataDB.Put(MaxIndexedHeightKey, lastTxID[:]); err != nil {
 				return err
 			}
 			if err := a.db.Commit(); err != nil {
@@ -182,10 +184,10 @@ func (a *atomicTxRepository) initializeHeightIndex(lastAcceptedHeight uint64) er
 		return fmt.Errorf("atomic tx DB iterator errored while initializing atomic trie: %w", err)
 	}
 
-	// Updated the value stored [maxIndexedHeightKey] to be the lastAcceptedHeight
+	// Updated the value stored [MaxIndexedHeightKey] to be the lastAcceptedHeight
 	indexedHeight := make([]byte, wrappers.LongLen)
 	binary.BigEndian.PutUint64(indexedHeight, lastAcceptedHeight)
-	if err := a.atomicRepoMetadataDB.Put(maxIndexedHeightKey, indexedHeight); err != nil {
+	if err := a.atomicRepoMetadataDB.Put(MaxIndexedHeightKey, indexedHeight); err != nil {
 		return err
 	}
 
@@ -195,7 +197,7 @@ func (a *atomicTxRepository) initializeHeightIndex(lastAcceptedHeight uint64) er
 
 // GetIndexHeight returns the last height that was indexed by the atomic repository
 func (a *atomicTxRepository) GetIndexHeight() (uint64, error) {
-	indexHeightBytes, err := a.atomicRepoMetadataDB.Get(maxIndexedHeightKey)
+	indexHeightBytes, err := a.atomicRepoMetadataDB.Get(MaxIndexedHeightKey)
 	if err != nil {
 		return 0, err
 	}
@@ -306,7 +308,7 @@ func (a *atomicTxRepository) write(height uint64, txs []*Tx, bonus bool) error {
 
 	// Update the index height regardless of if any atomic transactions
 	// were present at [height].
-	return a.atomicRepoMetadataDB.Put(maxIndexedHeightKey, heightBytes)
+	return a.atomicRepoMetadataDB.Put(MaxIndexedHeightKey, heightBytes)
 }
 
 // indexTxByID writes [tx] into the [acceptedAtomicTxDB] stored as
@@ -214,7 +214,7 @@ func TestAtomicRepositoryPreAP5Migration(t *testing.T) {
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
 
-	acceptedAtomicTxDB := prefixdb.New(atomicTxIDDBPrefix, db)
+	acceptedAtomicTxDB := prefixdb.New(AtomicTxIDDBPrefix, db)
 	txMap := make(map[uint64][]*Tx)
 	addTxs(t, codec, acceptedAtomicTxDB, 1, 100, 1, txMap, nil)
 	if err := db.Commit(); err != nil {
@@ -239,7 +239,7 @@ func TestAtomicRepositoryPostAP5Migration(t *testing.T) {
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
 
-	acceptedAtomicTxDB := prefixdb.New(atomicTxIDDBPrefix, db)
+	acceptedAtomicTxDB := prefixdb.New(AtomicTxIDDBPrefix, db)
 	txMap := make(map[uint64][]*Tx)
 	addTxs(t, codec, acceptedAtomicTxDB, 1, 100, 1, txMap, nil)
 	addTxs(t, codec, acceptedAtomicTxDB, 100, 200, 10, txMap, nil)
@@ -264,7 +264,7 @@ func benchAtomicRepositoryIndex10_000(b *testing.B, maxHeight uint64, txsPerHeig
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
 
-	acceptedAtomicTxDB := prefixdb.New(atomicTxIDDBPrefix, db)
+	acceptedAtomicTxDB := prefixdb.New(AtomicTxIDDBPrefix, db)
 	txMap := make(map[uint64][]*Tx)
 
 	addTxs(b, codec, acceptedAtomicTxDB, 0, maxHeight, txsPerHeight, txMap, nil)
@@ -0,0 +1,124 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DioneProtocol/coreth/consensus/dummy"
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/vms/components/dione"
+)
+
+// AtomicTxErrorKind classifies the reason an atomic transaction failed validation.
+type AtomicTxErrorKind string
+
+const (
+	// AtomicTxErrInvalidFormat is reported for transactions that are malformed
+	// or incorrectly signed, and can never become valid.
+	AtomicTxErrInvalidFormat AtomicTxErrorKind = "invalidFormat"
+	// AtomicTxErrFunding is reported for well-formed transactions whose inputs
+	// are not currently spendable, such as missing UTXOs or insufficient balances.
+	AtomicTxErrFunding AtomicTxErrorKind = "funding"
+	// AtomicTxErrNetwork is reported when the node could not fetch the chain
+	// state required to validate the transaction.
+	AtomicTxErrNetwork AtomicTxErrorKind = "network"
+)
+
+// AtomicTxValidationError is returned by ValidateAtomicTx when [tx] fails
+// validation.
+type AtomicTxValidationError struct {
+	Kind AtomicTxErrorKind
+	Err  error
+}
+
+func (e *AtomicTxValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+}
+
+func (e *AtomicTxValidationError) Unwrap() error {
+	return e.Err
+}
+
+// fundingErrs are the errors reported by semantic verification and state
+// transfer when the inputs of an atomic transaction are not spendable.
+var fundingErrs = []error{
+	database.ErrNotFound,
+	dione.ErrInsufficientFunds,
+	errInsufficientFunds,
+	errInsufficientFundsForFee,
+	errConflictingAtomicInputs,
+	errInvalidNonce,
+	errNonceTooHigh,
+}
+
+// classifyAtomicTxErr wraps [err] returned by semantic verification or state
+// transfer in an *AtomicTxValidationError of the appropriate kind.
+func classifyAtomicTxErr(err error) error {
+	for _, fundingErr := range fundingErrs {
+		if errors.Is(err, fundingErr) {
+			return &AtomicTxValidationError{Kind: AtomicTxErrFunding, Err: err}
+		}
+	}
+	if errors.Is(err, errFetchImportUTXOs) {
+		return &AtomicTxValidationError{Kind: AtomicTxErrNetwork, Err: err}
+	}
+	return &AtomicTxValidationError{Kind: AtomicTxErrInvalidFormat, Err: err}
+}
+
+// ValidateAtomicTx runs full verification of [tx] on top of the last accepted
+// block without modifying any state. If [tx] is invalid, the returned error is
+// an *AtomicTxValidationError describing the kind of failure.
+func (vm *VM) ValidateAtomicTx(ctx context.Context, tx *Tx) error {
+	parent, ok := vm.LastAcceptedBlockInternal().(*Block)
+	if !ok {
+		return &AtomicTxValidationError{
+			Kind: AtomicTxErrNetwork,
+			Err:  fmt.Errorf("last accepted block had unexpected type %T", vm.LastAcceptedBlockInternal()),
+		}
+	}
+	parentHeader := parent.ethBlock.Header()
+	timestamp := uint64(vm.clock.Time().Unix())
+	rules := vm.chainConfig.OdysseyRules(new(big.Int).Add(parentHeader.Number, big.NewInt(1)), timestamp)
+
+	if err := tx.UnsignedAtomicTx.Verify(vm.ctx, rules); err != nil {
+		return &AtomicTxValidationError{Kind: AtomicTxErrInvalidFormat, Err: err}
+	}
+
+	var baseFee *big.Int
+	if rules.IsApricotPhase3 {
+		var err error
+		_, baseFee, err = dummy.EstimateNextBaseFee(vm.chainConfig, parentHeader, timestamp)
+		if err != nil {
+			return &AtomicTxValidationError{
+				Kind: AtomicTxErrNetwork,
+				Err:  fmt.Errorf("failed to calculate base fee on top of block %s: %w", parent.ID(), err),
+			}
+		}
+	}
+	if err := vm.verifyAtomicTxAssetsAllowed(tx); err != nil {
+		return &AtomicTxValidationError{Kind: AtomicTxErrInvalidFormat, Err: err}
+	}
+	if err := tx.UnsignedAtomicTx.SemanticVerify(vm, tx, parent, baseFee, rules); err != nil {
+		return classifyAtomicTxErr(err)
+	}
+
+	// Note: [state] is a fresh copy of the last accepted state, so the state
+	// transfer below does not affect the chain.
+	state, err := vm.blockChain.StateAt(parentHeader.Root)
+	if err != nil {
+		return &AtomicTxValidationError{
+			Kind: AtomicTxErrNetwork,
+			Err:  fmt.Errorf("failed to retrieve state of block %s: %w", parent.ID(), err),
+		}
+	}
+	vm.advancePendingNonces(tx, state)
+	if err := tx.UnsignedAtomicTx.DELTAStateTransfer(vm.ctx, state, rules); err != nil {
+		return classifyAtomicTxErr(err)
+	}
+	return nil
+}
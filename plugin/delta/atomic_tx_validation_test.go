@@ -0,0 +1,129 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/DioneProtocol/odysseygo/utils/units"
+	"github.com/DioneProtocol/odysseygo/vms/components/dione"
+	"github.com/DioneProtocol/odysseygo/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func requireAtomicTxErrorKind(t *testing.T, err error, kind AtomicTxErrorKind) {
+	var validationErr *AtomicTxValidationError
+	require.True(t, errors.As(err, &validationErr), "unexpected error type %T", err)
+	require.Equal(t, kind, validationErr.Kind)
+}
+
+func TestValidateAtomicTxValid(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	stateBefore, err := vm.blockChain.State()
+	require.NoError(err)
+	balance := stateBefore.GetBalance(testEthAddrs[0])
+
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.ValidateAtomicTx(context.Background(), exportTx))
+
+	// Validation must not have modified the chain state.
+	stateAfter, err := vm.blockChain.State()
+	require.NoError(err)
+	require.Zero(stateAfter.GetNonce(testEthAddrs[0]))
+	require.Equal(balance, stateAfter.GetBalance(testEthAddrs[0]))
+}
+
+func TestValidateAtomicTxInvalidFormat(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	exportTx.UnsignedAtomicTx.(*UnsignedExportTx).NetworkID++
+	exportTx.Creds = nil
+	require.NoError(exportTx.Sign(vm.codec, [][]*secp256k1.PrivateKey{{testKeys[0]}}))
+
+	err = vm.ValidateAtomicTx(context.Background(), exportTx)
+	requireAtomicTxErrorKind(t, err, AtomicTxErrInvalidFormat)
+	require.ErrorIs(err, errWrongNetworkID)
+}
+
+func TestValidateAtomicTxInsufficientBalance(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// Spend more than the genesis balance of the exporting account.
+	amount := 2 * units.MegaDione
+	exportTx := &Tx{UnsignedAtomicTx: &UnsignedExportTx{
+		NetworkID:        vm.ctx.NetworkID,
+		BlockchainID:     vm.ctx.ChainID,
+		DestinationChain: vm.ctx.AChainID,
+		Ins: []DELTAInput{{
+			Address: testEthAddrs[0],
+			Amount:  amount,
+			AssetID: vm.ctx.DIONEAssetID,
+			Nonce:   0,
+		}},
+		ExportedOutputs: []*dione.TransferableOutput{{
+			Asset: dione.Asset{ID: vm.ctx.DIONEAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount / 2,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{testShortIDAddrs[0]},
+				},
+			},
+		}},
+	}}
+	require.NoError(exportTx.Sign(vm.codec, [][]*secp256k1.PrivateKey{{testKeys[0]}}))
+
+	err := vm.ValidateAtomicTx(context.Background(), exportTx)
+	requireAtomicTxErrorKind(t, err, AtomicTxErrFunding)
+	require.ErrorIs(err, errInsufficientFunds)
+}
+
+func TestValidateAtomicTxMissingUTXO(t *testing.T) {
+	require := require.New(t)
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// The UTXO is never added to shared memory.
+	utxo := &dione.UTXO{
+		UTXOID: dione.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  dione.Asset{ID: vm.ctx.DIONEAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 100 * units.Dione,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{testShortIDAddrs[0]},
+			},
+		},
+	}
+	importTx, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], big.NewInt(params.ApricotPhase4MinBaseFee), secp256k1fx.NewKeychain(testKeys[0]), []*dione.UTXO{utxo}, false)
+	require.NoError(err)
+
+	err = vm.ValidateAtomicTx(context.Background(), importTx)
+	requireAtomicTxErrorKind(t, err, AtomicTxErrFunding)
+	require.ErrorIs(err, errFetchImportUTXOs)
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -18,91 +19,58 @@ import (
 
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/snow/choices"
+	safemath "github.com/DioneProtocol/odysseygo/utils/math"
 )
 
-var (
-	bonusBlockMainnetHeights = make(map[uint64]ids.ID)
-	// first height that processed a TX included on a
-	// bonus block is the canonical height for that TX.
-	canonicalBlockMainnetHeights = []uint64{
-		102928, 103035, 103038, 103114, 103193,
-		103234, 103338, 103444, 103480, 103491,
-		103513, 103533, 103535, 103538, 103541,
-		103546, 103571, 103572, 103619,
-		103287, 103624, 103591,
-	}
-
-	errMissingUTXOs = errors.New("missing UTXOs")
+var errMissingUTXOs = errors.New("missing UTXOs")
+
+// VerifyErrorKind classifies the stage at which a block failed verification.
+type VerifyErrorKind int
+
+const (
+	// SyntacticError is reported for blocks that are malformed or whose atomic
+	// txs do not apply on top of their ancestors.
+	SyntacticError VerifyErrorKind = iota
+	// MissingUTXOError is reported for blocks whose atomic txs spend UTXOs
+	// that are not present in shared memory.
+	MissingUTXOError
+	// ChainProcessingError is reported for blocks that the blockchain failed to
+	// insert, such as blocks with an invalid state transition.
+	ChainProcessingError
 )
 
-func init() {
-	mainnetBonusBlocks := map[uint64]string{
-		102972: "Njm9TcLUXRojZk8YhEM6ksvfiPdC1TME4zJvGaDXgzMCyB6oB",
-		103105: "BYqLB6xpqy7HsAgP2XNfGE8Ubg1uEzse5mBPTSJH9z5s8pvMa",
-		103143: "AfWvJH3rB2fdHuPWQp6qYNCFVT29MooQPRigD88rKKwUDEDhq",
-		103183: "2KPW9G5tiNF14tZNfG4SqHuQrtUYVZyxuof37aZ7AnTKrQdsHn",
-		103197: "pE93VXY3N5QKfwsEFcM9i59UpPFgeZ8nxpJNaGaDQyDgsscNf",
-		103203: "2czmtnBS44VCWNRFUM89h4Fe9m3ZeZVYyh7Pe3FhNqjRNgPXhZ",
-		103208: "esx5J962LtYm2aSrskpLai5e4CMMsaS1dsu9iuLGJ3KWgSu2M",
-		103209: "DK9NqAJGry1wAo767uuYc1dYXAjUhzwka6vi8d9tNheqzGUTd",
-		103259: "i1HoerJ1axognkUKKL58FvF9aLrbZKtv7TdKLkT5kgzoeU1vB",
-		103261: "2DpCuBaH94zKKFNY2XTs4GeJcwsEv6qT2DHc59S8tdg97GZpcJ",
-		103266: "2ez4CA7w4HHr8SSobHQUAwFgj2giRNjNFUZK9JvrZFa1AuRj6X",
-		103287: "2QBNMMFJmhVHaGF45GAPszKyj1gK6ToBERRxYvXtM7yfrdUGPK",
-		103339: "2pSjfo7rkFCfZ2CqAxqfw8vqM2CU2nVLHrFZe3rwxz43gkVuGo",
-		103346: "2SiSziHHqPjb1qkw7CdGYupokiYpd2b7mMqRiyszurctcA5AKr",
-		103350: "2F5tSQbdTfhZxvkxZqdFp7KR3FrJPKEsDLQK7KtPhNXj1EZAh4",
-		103358: "2tCe88ur6MLQcVgwE5XxoaHiTGtSrthwKN3SdbHE4kWiQ7MSTV",
-		103437: "21o2fVTnzzmtgXqkV1yuQeze7YEQhR5JB31jVVD9oVUnaaV8qm",
-		103472: "2nG4exd9eUoAGzELfksmBR8XDCKhohY1uDKRFzEXJG4M8p3qA7",
-		103478: "63YLdYXfXc5tY3mwWLaDsbXzQHYmwWVxMP7HKbRh4Du3C2iM1",
-		103493: "soPweZ8DGaoUMjrnzjH3V2bypa7ZvvfqBan4UCsMUxMP759gw",
-		103514: "2dNkpQF4mooveyUDfBYQTBfsGDV4wkncQPpEw4kHKfSTSTo5x",
-		103536: "PJTkRrHvKZ1m4AQdPND1MBpUXpCrGN4DDmXmJQAiUrsxPoLQX",
-		103545: "22ck2Z7cC38hmBfX2v3jMWxun8eD8psNaicfYeokS67DxwmPTx",
-		103547: "pTf7gfk1ksj7bqMrLyMCij8FBKth1uRqQrtfykMFeXhx5xnrL",
-		103554: "9oZh4qyBCcVwSGyDoUzRAuausvPJN3xH6nopKS6bwYzMfLoQ2",
-		103555: "MjExz2z1qhwugc1tAyiGxRsCq4GvJwKfyyS29nr4tRVB8ooic",
-		103559: "cwJusfmn98TW3DjAbfLRN9utYR24KAQ82qpAXmVSvjHyJZuM2",
-		103561: "2YgxGHns7Z2hMMHJsPCgVXuJaL7x1b3gnHbmSCfCdyAcYGr6mx",
-		103563: "2AXxT3PSEnaYHNtBTnYrVTf24TtKDWjky9sqoFEhydrGXE9iKH",
-		103564: "Ry2sfjFfGEnJxRkUGFSyZNn7GR3m4aKAf1scDW2uXSNQB568Y",
-		103569: "21Jys8UNURmtckKSV89S2hntEWymJszrLQbdLaNcbXcxDAsQSa",
-		103570: "sg6wAwFBsPQiS5Yfyh41cVkCRQbrrXsxXmeNyQ1xkunf2sdyv",
-		103575: "z3BgePPpCXq1mRBRvUi28rYYxnEtJizkUEHnDBrcZeVA7MFVk",
-		103577: "uK5Ff9iBfDtREpVv9NgCQ1STD1nzLJG3yrfibHG4mGvmybw6f",
-		103578: "Qv5v5Ru8ArfnWKB1w6s4G5EYPh7TybHJtF6UsVwAkfvZFoqmj",
-		103582: "7KCZKBpxovtX9opb7rMRie9WmW5YbZ8A4HwBBokJ9eSHpZPqx",
-		103587: "2AfTQ2FXNj9bkSUQnud9pFXULx6EbF7cbbw6i3ayvc2QNhgxfF",
-		103590: "2gTygYckZgFZfN5QQWPaPBD3nabqjidV55mwy1x1Nd4JmJAwaM",
-		103591: "2cUPPHy1hspr2nAKpQrrAEisLKkaWSS9iF2wjNFyFRs8vnSkKK",
-		103594: "5MptSdP6dBMPSwk9GJjeVe39deZJTRh9i82cgNibjeDffrrTf",
-		103597: "2J8z7HNv4nwh82wqRGyEHqQeuw4wJ6mCDCSvUgusBu35asnshK",
-		103598: "2i2FP6nJyvhX9FR15qN2D9AVoK5XKgBD2i2AQ7FoSpfowxvQDX",
-		103603: "2v3smb35s4GLACsK4Zkd2RcLBLdWA4huqrvq8Y3VP4CVe8kfTM",
-		103604: "b7XfDDLgwB12DfL7UTWZoxwBpkLPL5mdHtXngD94Y2RoeWXSh",
-		103607: "PgaRk1UAoUvRybhnXsrLq5t6imWhEa6ksNjbN6hWgs4qPrSzm",
-		103612: "2oueNTj4dUE2FFtGyPpawnmCCsy6EUQeVHVLZy8NHeQmkAciP4",
-		103614: "2YHZ1KymFjiBhpXzgt6HXJhLSt5SV9UQ4tJuUNjfN1nQQdm5zz",
-		103617: "amgH2C1s9H3Av7vSW4y7n7TXb9tKyKHENvrDXutgNN6nsejgc",
-		103618: "fV8k1U8oQDmfVwK66kAwN73aSsWiWhm8quNpVnKmSznBycV2W",
-		103621: "Nzs93kFTvcXanFUp9Y8VQkKYnzmH8xykxVNFJTkdyAEeuxWbP",
-		103623: "2rAsBj3emqQa13CV8r5fTtHogs4sXnjvbbXVzcKPi3WmzhpK9D",
-		103624: "2JbuExUGKW5mYz5KfXATwq1ibRDimgks9wEdYGNSC6Ttey1R4U",
-		103627: "tLLijh7oKfvWT1yk9zRv4FQvuQ5DAiuvb5kHCNN9zh4mqkFMG",
-		103628: "dWBsRYRwFrcyi3DPdLoHsL67QkZ5h86hwtVfP94ZBaY18EkmF",
-		103629: "XMoEsew2DhSgQaydcJFJUQAQYP8BTNTYbEJZvtbrV2QsX7iE3",
-		103630: "2db2wMbVAoCc5EUJrsBYWvNZDekqyY8uNpaaVapdBAQZ5oRaou",
-		103633: "2QiHZwLhQ3xLuyyfcdo5yCUfoSqWDvRZox5ECU19HiswfroCGp",
+func (k VerifyErrorKind) String() string {
+	switch k {
+	case SyntacticError:
+		return "syntactic"
+	case MissingUTXOError:
+		return "missingUTXO"
+	case ChainProcessingError:
+		return "chainProcessing"
+	default:
+		return fmt.Sprintf("VerifyErrorKind(%d)", int(k))
 	}
+}
 
-	for height, blkIDStr := range mainnetBonusBlocks {
-		blkID, err := ids.FromString(blkIDStr)
-		if err != nil {
-			panic(err)
-		}
-		bonusBlockMainnetHeights[height] = blkID
-	}
+// VerifyError is returned by Block.Verify when the block fails verification.
+type VerifyError struct {
+	Kind VerifyErrorKind
+	Err  error
+}
+
+func (e *VerifyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// AsVerifyError returns the *VerifyError in the chain of [err], if any.
+func AsVerifyError(err error) (*VerifyError, bool) {
+	var verifyErr *VerifyError
+	ok := errors.As(err, &verifyErr)
+	return verifyErr, ok
 }
 
 // Block implements the snowman.Block interface
@@ -112,6 +80,18 @@ type Block struct {
 	vm        *VM
 	status    choices.Status
 	atomicTxs []*Tx
+
+	// allowEmpty exempts the block from the empty block check. It is only set
+	// by BuildEmptyBlock.
+	allowEmpty bool
+
+	atomicGasUsedOnce sync.Once
+	atomicGasUsed     uint64
+	atomicGasUsedErr  error
+
+	// orionNodes is the orion nodes list the fees of the block were
+	// distributed to. It is set once the block is verified.
+	orionNodes *orionNodes
 }
 
 // newBlock returns a new Block wrapping the ethBlock type and implementing the snowman.Block interface
@@ -133,6 +113,45 @@ func (vm *VM) newBlock(ethBlock *types.Block) (*Block, error) {
 // ID implements the snowman.Block interface
 func (b *Block) ID() ids.ID { return b.id }
 
+// AtomicGasUsed returns the cumulative gas used by the atomic transactions
+// included in this block. The result is computed on the first call and cached.
+func (b *Block) AtomicGasUsed() (uint64, error) {
+	b.atomicGasUsedOnce.Do(func() {
+		// Charge the atomic tx fixed fee as of ApricotPhase5
+		fixedFee := b.vm.chainConfig.IsApricotPhase5(b.ethBlock.Time())
+		for _, atomicTx := range b.atomicTxs {
+			gasUsed, err := atomicTx.GasUsed(fixedFee)
+			if err != nil {
+				b.atomicGasUsed, b.atomicGasUsedErr = 0, err
+				return
+			}
+			b.atomicGasUsed, err = safemath.Add64(b.atomicGasUsed, gasUsed)
+			if err != nil {
+				b.atomicGasUsed, b.atomicGasUsedErr = 0, err
+				return
+			}
+		}
+	})
+	return b.atomicGasUsed, b.atomicGasUsedErr
+}
+
+// TotalBurned returns the cumulative amount of [assetID] burned by the atomic
+// transactions included in this block.
+func (b *Block) TotalBurned(assetID ids.ID) (uint64, error) {
+	var totalBurned uint64
+	for _, atomicTx := range b.atomicTxs {
+		burned, err := atomicTx.Burned(assetID)
+		if err != nil {
+			return 0, err
+		}
+		totalBurned, err = safemath.Add64(totalBurned, burned)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return totalBurned, nil
+}
+
 // Accept implements the snowman.Block interface
 func (b *Block) Accept(context.Context) error {
 	vm := b.vm
@@ -146,9 +165,13 @@ func (b *Block) Accept(context.Context) error {
 	if err := vm.blockChain.Accept(b.ethBlock); err != nil {
 		return fmt.Errorf("chain could not accept %s: %w", b.ID(), err)
 	}
+	b.logActivatedForks()
 	if err := vm.acceptedBlockDB.Put(lastAcceptedKey, b.id[:]); err != nil {
 		return fmt.Errorf("failed to put %s as the last accepted block: %w", b.ID(), err)
 	}
+	if err := vm.indexBurnedFees(b); err != nil {
+		return fmt.Errorf("failed to index burned fees of %s: %w", b.ID(), err)
+	}
 
 	for _, tx := range b.atomicTxs {
 		// Remove the accepted transaction from the mempool
@@ -169,8 +192,19 @@ func (b *Block) Accept(context.Context) error {
 	orionFee := b.ethBlock.OrionNodeFee()
 	orionFee.Div(orionFee, x2cRate)
 	if orionFee.Sign() > 0 {
-		if err := b.vm.ctx.FeeCollector.AddOrionsValue(vm.orionNodes, orionFee.Uint64()); err != nil {
-			return nil
+		// The orion fee is paid to the nodes the fee was calculated with when
+		// the block was verified. If the block was verified before a restart,
+		// they are read again from the state they were read from.
+		orions := b.orionNodes
+		if orions == nil {
+			var err error
+			orions, err = b.vm.readOrionNodes(b.ethBlock.Header())
+			if err != nil {
+				return fmt.Errorf("failed to read orion nodes of block %s: %w", b.ID(), err)
+			}
+		}
+		if err := b.vm.ctx.FeeCollector.AddOrionsValue(orions.nodes, orionFee.Uint64()); err != nil {
+			return fmt.Errorf("failed to collect orion fee: %w", err)
 		}
 	}
 
@@ -196,6 +230,18 @@ func (b *Block) Accept(context.Context) error {
 	return atomicState.Accept(commitBatch)
 }
 
+// logActivatedForks logs each fork that is active at [b] but was not active at
+// its parent.
+func (b *Block) logActivatedForks() {
+	parent := b.vm.blockChain.GetHeader(b.ethBlock.ParentHash(), b.Height()-1)
+	if parent == nil {
+		return
+	}
+	for _, fork := range b.vm.chainConfig.ActivatedForks(parent.Number, parent.Time, b.ethBlock.Number(), b.ethBlock.Time()) {
+		log.Info(fmt.Sprintf("Activated %s at block %d, time %d", fork, b.Height(), b.ethBlock.Time()))
+	}
+}
+
 // Reject implements the snowman.Block interface
 // If [b] contains an atomic transaction, attempt to re-issue it
 func (b *Block) Reject(context.Context) error {
@@ -203,7 +249,13 @@ func (b *Block) Reject(context.Context) error {
 	log.Debug(fmt.Sprintf("Rejecting block %s (%s) at height %d", b.ID().Hex(), b.ID(), b.Height()))
 	for _, tx := range b.atomicTxs {
 		b.vm.mempool.RemoveTx(tx)
-		if err := b.vm.issueTx(tx, false /* set local to false when re-issuing */); err != nil {
+		var err error
+		if b.vm.config.ReissueAtomicTxsWithoutGossip {
+			err = b.vm.issueTxWithoutGossip(tx)
+		} else {
+			err = b.vm.issueTx(tx, false /* set local to false when re-issuing */)
+		}
+		if err != nil {
 			log.Debug("Failed to re-issue transaction in rejected block", "txID", tx.ID(), "err", err)
 		}
 	}
@@ -253,19 +305,47 @@ func (b *Block) syntacticVerify() error {
 	return b.vm.syntacticBlockValidator.SyntacticVerify(b, rules)
 }
 
+// verifyBlockSequence checks [b] against its parent block
+func (b *Block) verifyBlockSequence() error {
+	parentIntf, err := b.vm.GetBlockInternal(context.TODO(), b.Parent())
+	if err != nil {
+		return fmt.Errorf("failed to get parent block %s: %w", b.Parent(), err)
+	}
+	parent, ok := parentIntf.(*Block)
+	if !ok {
+		return fmt.Errorf("parent block %s had unexpected type %T", parentIntf.ID(), parentIntf)
+	}
+
+	header := b.ethBlock.Header()
+	rules := b.vm.chainConfig.OdysseyRules(header.Number, header.Time)
+	return b.vm.syntacticBlockValidator.VerifyBlockSequence(parent, b, rules)
+}
+
 // Verify implements the snowman.Block interface
 func (b *Block) Verify(context.Context) error {
 	return b.verify(true)
 }
 
+// verify verifies the block and inserts it into the blockchain. If the block is
+// invalid, the returned error is a *VerifyError describing the failed stage.
 func (b *Block) verify(writes bool) error {
 	if err := b.syntacticVerify(); err != nil {
-		return fmt.Errorf("syntactic block verification failed: %w", err)
+		return &VerifyError{
+			Kind: SyntacticError,
+			Err:  fmt.Errorf("syntactic block verification failed: %w", err),
+		}
+	}
+
+	if err := b.verifyBlockSequence(); err != nil {
+		return &VerifyError{
+			Kind: SyntacticError,
+			Err:  fmt.Errorf("block sequence verification failed: %w", err),
+		}
 	}
 
 	// verify UTXOs named in import txs are present in shared memory.
 	if err := b.verifyUTXOsPresent(); err != nil {
-		return err
+		return &VerifyError{Kind: MissingUTXOError, Err: err}
 	}
 
 	err := b.vm.blockChain.InsertBlockManual(b.ethBlock, writes)
@@ -277,7 +357,17 @@ func (b *Block) verify(writes bool) error {
 			_ = atomicState.Reject() // ignore this error so we can return the original error instead.
 		}
 	}
-	return err
+	if err != nil {
+		return &VerifyError{Kind: ChainProcessingError, Err: err}
+	}
+	// Keep the orion nodes the fees of the block were distributed to during its
+	// processing, so that Accept pays them without reading them from the state
+	// again.
+	if orions, ok := b.vm.verifiedOrionNodes.Get(b.ethBlock.Hash()); ok {
+		b.orionNodes = orions
+		b.vm.verifiedOrionNodes.Evict(b.ethBlock.Hash())
+	}
+	return nil
 }
 
 // verifyUTXOsPresent returns an error if any of the atomic transactions name UTXOs that
@@ -0,0 +1,248 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/trie"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	safemath "github.com/DioneProtocol/odysseygo/utils/math"
+	"github.com/DioneProtocol/odysseygo/vms/components/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockAtomicGasUsed(t *testing.T) {
+	tests := map[string]struct {
+		gasUsed     []uint64
+		expected    uint64
+		expectedErr error
+	}{
+		"no atomic txs": {
+			expected: 0,
+		},
+		"one atomic tx": {
+			gasUsed:  []uint64{11_000},
+			expected: 11_000,
+		},
+		"multiple atomic txs": {
+			gasUsed:  []uint64{11_000, 12_000, 13_000},
+			expected: 36_000,
+		},
+		"overflow": {
+			gasUsed:     []uint64{math.MaxUint64, 1},
+			expectedErr: safemath.ErrOverflow,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			atomicTxs := make([]*Tx, 0, len(test.gasUsed))
+			for _, gasUsed := range test.gasUsed {
+				atomicTxs = append(atomicTxs, &Tx{UnsignedAtomicTx: &TestUnsignedTx{GasUsedV: gasUsed}})
+			}
+			blk := &Block{
+				ethBlock:  types.NewBlockWithHeader(&types.Header{}),
+				vm:        &VM{chainConfig: params.TestChainConfig},
+				atomicTxs: atomicTxs,
+			}
+
+			gasUsed, err := blk.AtomicGasUsed()
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, gasUsed)
+
+			// The result is cached after the first call.
+			blk.atomicTxs = nil
+			gasUsed, err = blk.AtomicGasUsed()
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, gasUsed)
+		})
+	}
+}
+
+func TestBlockTotalBurned(t *testing.T) {
+	tests := map[string]struct {
+		burned      []uint64
+		expected    uint64
+		expectedErr error
+	}{
+		"no atomic txs": {
+			expected: 0,
+		},
+		"one atomic tx": {
+			burned:   []uint64{1_000},
+			expected: 1_000,
+		},
+		"multiple atomic txs": {
+			burned:   []uint64{1_000, 2_000, 3_000},
+			expected: 6_000,
+		},
+		"overflow": {
+			burned:      []uint64{math.MaxUint64, 1},
+			expectedErr: safemath.ErrOverflow,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			atomicTxs := make([]*Tx, 0, len(test.burned))
+			for _, burned := range test.burned {
+				atomicTxs = append(atomicTxs, &Tx{UnsignedAtomicTx: &TestUnsignedTx{BurnedV: burned}})
+			}
+			blk := &Block{
+				ethBlock:  types.NewBlockWithHeader(&types.Header{}),
+				vm:        &VM{chainConfig: params.TestChainConfig},
+				atomicTxs: atomicTxs,
+			}
+
+			burned, err := blk.TotalBurned(ids.GenerateTestID())
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, burned)
+		})
+	}
+}
+
+func TestVerifyBlockSequence(t *testing.T) {
+	parentHeader := &types.Header{
+		Number: big.NewInt(10),
+		Time:   1_000,
+	}
+	parent := &Block{ethBlock: types.NewBlockWithHeader(parentHeader)}
+	vm := &VM{chainConfig: params.TestChainConfig}
+	vm.clock.Set(time.Unix(1_000, 0))
+	validChildHeader := func() *types.Header {
+		return &types.Header{
+			ParentHash: parent.ethBlock.Hash(),
+			Number:     big.NewInt(11),
+			Time:       1_000,
+		}
+	}
+
+	tests := map[string]struct {
+		modify      func(*types.Header)
+		expectedErr error
+	}{
+		"valid": {
+			modify: func(*types.Header) {},
+		},
+		"later timestamp": {
+			modify: func(h *types.Header) { h.Time = 1_001 },
+		},
+		"same height as parent": {
+			modify:      func(h *types.Header) { h.Number = big.NewInt(10) },
+			expectedErr: errInvalidBlockHeight,
+		},
+		"skipped height": {
+			modify:      func(h *types.Header) { h.Number = big.NewInt(12) },
+			expectedErr: errInvalidBlockHeight,
+		},
+		"wrong parent hash": {
+			modify:      func(h *types.Header) { h.ParentHash = common.Hash{1} },
+			expectedErr: errInvalidParentHash,
+		},
+		"timestamp before parent": {
+			modify:      func(h *types.Header) { h.Time = 999 },
+			expectedErr: errBlockTimestampBeforeParent,
+		},
+		"at maximum future skew": {
+			modify: func(h *types.Header) { h.Time = 1_000 + uint64(maxFutureBlockTime.Seconds()) },
+		},
+		"timestamp too far in the future": {
+			modify:      func(h *types.Header) { h.Time = 1_001 + uint64(maxFutureBlockTime.Seconds()) },
+			expectedErr: errBlockTimestampTooFarInFuture,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := validChildHeader()
+			test.modify(header)
+			child := &Block{
+				ethBlock: types.NewBlockWithHeader(header),
+				vm:       vm,
+			}
+
+			err := NewBlockValidator(nil).VerifyBlockSequence(parent, child, params.TestRules)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestBlockVerifyErrorKind(t *testing.T) {
+	// newImportBlock returns a VM and a block built by it that imports a UTXO
+	// from shared memory.
+	newImportBlock := func(t *testing.T) (*VM, *types.Block) {
+		issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, "", "", map[ids.ShortID]uint64{
+			testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+		})
+		t.Cleanup(func() {
+			require.NoError(t, vm.Shutdown(context.Background()))
+		})
+
+		importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, true)
+		require.NoError(t, err)
+		require.NoError(t, vm.issueTx(importTx, true /*=local*/))
+		<-issuer
+
+		blk, err := vm.BuildBlock(context.Background())
+		require.NoError(t, err)
+		return vm, blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	}
+
+	requireKind := func(t *testing.T, err error, kind VerifyErrorKind) {
+		verifyErr, ok := AsVerifyError(err)
+		require.True(t, ok, "expected a *VerifyError, got %v", err)
+		require.Equal(t, kind, verifyErr.Kind, "unexpected kind for %v", err)
+	}
+
+	t.Run("syntactic", func(t *testing.T) {
+		vm, ethBlock := newImportBlock(t)
+		emptyBlock, err := vm.newBlock(types.NewBlock(types.CopyHeader(ethBlock.Header()), nil, nil, nil, new(trie.Trie), nil, false))
+		require.NoError(t, err)
+
+		requireKind(t, emptyBlock.Verify(context.Background()), SyntacticError)
+	})
+
+	t.Run("missing utxo", func(t *testing.T) {
+		_, ethBlock := newImportBlock(t)
+
+		// A VM missing the UTXO in shared memory.
+		_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+		defer func() {
+			require.NoError(t, vm.Shutdown(context.Background()))
+		}()
+		blk, err := vm.newBlock(ethBlock)
+		require.NoError(t, err)
+
+		err = blk.Verify(context.Background())
+		requireKind(t, err, MissingUTXOError)
+		require.ErrorIs(t, err, errMissingUTXOs)
+	})
+
+	t.Run("chain processing", func(t *testing.T) {
+		vm, ethBlock := newImportBlock(t)
+		// The state root does not match the state transition of the block.
+		header := types.CopyHeader(ethBlock.Header())
+		header.Root = common.Hash{1}
+		blk, err := vm.newBlock(types.NewBlock(header, nil, nil, nil, new(trie.Trie), ethBlock.ExtData(), false))
+		require.NoError(t, err)
+
+		requireKind(t, blk.Verify(context.Background()), ChainProcessingError)
+	})
+
+	t.Run("not a verify error", func(t *testing.T) {
+		_, ok := AsVerifyError(errors.New("other"))
+		require.False(t, ok)
+	})
+}
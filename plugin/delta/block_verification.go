@@ -10,8 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
-	safemath "github.com/DioneProtocol/odysseygo/utils/math"
-
+	"github.com/DioneProtocol/coreth/consensus/dummy"
 	"github.com/DioneProtocol/coreth/constants"
 	"github.com/DioneProtocol/coreth/core/types"
 	"github.com/DioneProtocol/coreth/params"
@@ -25,6 +24,7 @@ var (
 
 type BlockValidator interface {
 	SyntacticVerify(b *Block, rules params.Rules) error
+	VerifyBlockSequence(parent, child *Block, rules params.Rules) error
 }
 
 type blockValidator struct {
@@ -99,46 +99,27 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 	}
 
 	// Enforce static gas limit after ApricotPhase1 (prior to ApricotPhase1 it's handled in processing).
-	if rules.IsCortina {
-		if ethHeader.GasLimit != params.CortinaGasLimit {
-			return fmt.Errorf(
-				"expected gas limit to be %d after cortina but got %d",
-				params.CortinaGasLimit, ethHeader.GasLimit,
-			)
-		}
-	} else if rules.IsApricotPhase1 {
-		if ethHeader.GasLimit != params.ApricotPhase1GasLimit {
-			return fmt.Errorf(
-				"expected gas limit to be %d after apricot phase 1 but got %d",
-				params.ApricotPhase1GasLimit, ethHeader.GasLimit,
-			)
-		}
+	if gasLimit, fork := dummy.GasLimit(rules); gasLimit != 0 && ethHeader.GasLimit != gasLimit {
+		return fmt.Errorf(
+			"expected gas limit to be %d after %s but got %d",
+			gasLimit, fork, ethHeader.GasLimit,
+		)
 	}
 
 	// Check that the size of the header's Extra data field is correct for [rules].
 	headerExtraDataSize := uint64(len(ethHeader.Extra))
-	switch {
-	case rules.IsApricotPhase3:
-		if headerExtraDataSize != params.ApricotPhase3ExtraDataSize {
+	if size, exact := dummy.ExpectedExtraDataSize(rules); exact {
+		if headerExtraDataSize != size {
 			return fmt.Errorf(
 				"expected header ExtraData to be %d but got %d",
-				params.ApricotPhase3ExtraDataSize, headerExtraDataSize,
-			)
-		}
-	case rules.IsApricotPhase1:
-		if headerExtraDataSize != 0 {
-			return fmt.Errorf(
-				"expected header ExtraData to be 0 but got %d",
-				headerExtraDataSize,
-			)
-		}
-	default:
-		if headerExtraDataSize > params.MaximumExtraDataSize {
-			return fmt.Errorf(
-				"expected header ExtraData to be <= %d but got %d",
-				params.MaximumExtraDataSize, headerExtraDataSize,
+				size, headerExtraDataSize,
 			)
 		}
+	} else if headerExtraDataSize > size {
+		return fmt.Errorf(
+			"expected header ExtraData to be <= %d but got %d",
+			size, headerExtraDataSize,
+		)
 	}
 
 	if b.ethBlock.Version() != 0 {
@@ -166,7 +147,7 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 
 	// Block must not be empty
 	txs := b.ethBlock.Transactions()
-	if len(txs) == 0 && len(b.atomicTxs) == 0 {
+	if len(txs) == 0 && len(b.atomicTxs) == 0 && !b.allowEmpty {
 		return errEmptyBlock
 	}
 
@@ -188,21 +169,17 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 		}
 	}
 
-	// Make sure the block isn't too far in the future
-	// TODO: move this to only be part of semantic verification.
-	blockTimestamp := b.ethBlock.Time()
-	if maxBlockTime := uint64(b.vm.clock.Time().Add(maxFutureBlockTime).Unix()); blockTimestamp > maxBlockTime {
-		return fmt.Errorf("block timestamp is too far in the future: %d > allowed %d", blockTimestamp, maxBlockTime)
-	}
-
 	// Ensure BaseFee is non-nil as of ApricotPhase3.
 	if rules.IsApricotPhase3 {
 		if ethHeader.BaseFee == nil {
 			return errNilBaseFeeApricotPhase3
 		}
-		// TODO: this should be removed as 256 is the maximum possible bit length of a big int
-		if bfLen := ethHeader.BaseFee.BitLen(); bfLen > 256 {
-			return fmt.Errorf("too large base fee: bitlen %d", bfLen)
+		// The bounds of each phase contain the bounds of the phase before it,
+		// so the bounds at this block also hold for the parent-derived clamp.
+		lowerBound, upperBound := dummy.BaseFeeBounds(b.vm.chainConfig, ethHeader.Time)
+		baseFee := ethHeader.BaseFee
+		if baseFee.Sign() < 0 || (lowerBound != nil && baseFee.Cmp(lowerBound) < 0) || (upperBound != nil && baseFee.Cmp(upperBound) > 0) {
+			return fmt.Errorf("%w: %d not in [%d, %d]", errBaseFeeOutOfBounds, baseFee, lowerBound, upperBound)
 		}
 	}
 
@@ -213,7 +190,7 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 			return errNilExtDataGasUsedApricotPhase4
 		}
 		if rules.IsApricotPhase5 {
-			if ethHeader.ExtDataGasUsed.Cmp(params.AtomicGasLimit) == 1 {
+			if ethHeader.ExtDataGasUsed.Cmp(rules.AtomicGasLimit()) == 1 {
 				return fmt.Errorf("too large extDataGasUsed: %d", ethHeader.ExtDataGasUsed)
 			}
 		} else {
@@ -221,19 +198,11 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 				return fmt.Errorf("too large extDataGasUsed: %d", ethHeader.ExtDataGasUsed)
 			}
 		}
-		var totalGasUsed uint64
-		for _, atomicTx := range b.atomicTxs {
-			// We perform this check manually here to avoid the overhead of having to
-			// reparse the atomicTx in `CalcExtDataGasUsed`.
-			fixedFee := rules.IsApricotPhase5 // Charge the atomic tx fixed fee as of ApricotPhase5
-			gasUsed, err := atomicTx.GasUsed(fixedFee)
-			if err != nil {
-				return err
-			}
-			totalGasUsed, err = safemath.Add64(totalGasUsed, gasUsed)
-			if err != nil {
-				return err
-			}
+		// We perform this check manually here to avoid the overhead of having to
+		// reparse the atomicTx in `CalcExtDataGasUsed`.
+		totalGasUsed, err := b.AtomicGasUsed()
+		if err != nil {
+			return err
 		}
 
 		switch {
@@ -259,3 +228,27 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 
 	return nil
 }
+
+// VerifyBlockSequence checks the invariants that must hold between [child] and
+// its [parent], and that [child] is not too far ahead of the wall clock at the
+// time of verification. SyntacticVerify cannot check either on a block in
+// isolation.
+func (v blockValidator) VerifyBlockSequence(parent, child *Block, rules params.Rules) error {
+	if parent == nil || parent.ethBlock == nil || child == nil || child.ethBlock == nil {
+		return errInvalidBlock
+	}
+
+	if expectedHeight := parent.Height() + 1; child.Height() != expectedHeight {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidBlockHeight, child.Height(), expectedHeight)
+	}
+	if parentHash := parent.ethBlock.Hash(); child.ethBlock.ParentHash() != parentHash {
+		return fmt.Errorf("%w: have %s, want %s", errInvalidParentHash, child.ethBlock.ParentHash(), parentHash)
+	}
+	if childTime, parentTime := child.ethBlock.Time(), parent.ethBlock.Time(); childTime < parentTime {
+		return fmt.Errorf("%w: %d < parent timestamp %d", errBlockTimestampBeforeParent, childTime, parentTime)
+	}
+	if maxBlockTime := uint64(child.vm.clock.Time().Add(maxFutureBlockTime).Unix()); child.ethBlock.Time() > maxBlockTime {
+		return fmt.Errorf("%w: %d > allowed %d", errBlockTimestampTooFarInFuture, child.ethBlock.Time(), maxBlockTime)
+	}
+	return nil
+}
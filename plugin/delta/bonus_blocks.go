@@ -0,0 +1,107 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/odysseygo/ids"
+)
+
+var (
+	//go:embed mainnet_bonus_blocks.json
+	rawMainnetBonusBlocks []byte
+	mainnetBonusBlocks    *BonusBlockSet
+
+	//go:embed testnet_bonus_blocks.json
+	rawTestnetBonusBlocks []byte
+	testnetBonusBlocks    *BonusBlockSet
+
+	errBonusBlocksNotIncreasing     = errors.New("bonus block heights must be strictly increasing")
+	errCanonicalBlocksNotIncreasing = errors.New("canonical block heights must be strictly increasing")
+	errEmptyBonusBlockID            = errors.New("bonus block ID must not be empty")
+)
+
+func init() {
+	var err error
+	if mainnetBonusBlocks, err = parseBonusBlockSet(rawMainnetBonusBlocks); err != nil {
+		panic(fmt.Errorf("invalid mainnet bonus blocks: %w", err))
+	}
+	rawMainnetBonusBlocks = nil
+	if testnetBonusBlocks, err = parseBonusBlockSet(rawTestnetBonusBlocks); err != nil {
+		panic(fmt.Errorf("invalid testnet bonus blocks: %w", err))
+	}
+	rawTestnetBonusBlocks = nil
+}
+
+// BonusBlock is an accepted block whose atomic txs are not applied, because
+// they were already applied by the block at a canonical height.
+type BonusBlock struct {
+	Height  uint64 `json:"height"`
+	BlockID ids.ID `json:"blockID"`
+}
+
+// BonusBlockSet is the set of bonus blocks of a network, and the canonical
+// heights at which the txs included in those bonus blocks were first applied.
+type BonusBlockSet struct {
+	BonusBlocks     []BonusBlock `json:"bonusBlocks"`
+	CanonicalBlocks []uint64     `json:"canonicalBlocks"`
+}
+
+// parseBonusBlockSet parses and validates the JSON encoded [b].
+func parseBonusBlockSet(b []byte) (*BonusBlockSet, error) {
+	set := &BonusBlockSet{}
+	if err := json.Unmarshal(b, set); err != nil {
+		return nil, err
+	}
+	return set, set.Validate()
+}
+
+// Validate returns an error if the bonus or canonical heights are not strictly
+// increasing, or if a bonus block has an empty ID.
+func (s *BonusBlockSet) Validate() error {
+	for i, bonus := range s.BonusBlocks {
+		if bonus.BlockID == ids.Empty {
+			return fmt.Errorf("%w: height %d", errEmptyBonusBlockID, bonus.Height)
+		}
+		if i > 0 && bonus.Height <= s.BonusBlocks[i-1].Height {
+			return fmt.Errorf("%w: %d follows %d", errBonusBlocksNotIncreasing, bonus.Height, s.BonusBlocks[i-1].Height)
+		}
+	}
+	for i, height := range s.CanonicalBlocks {
+		if i > 0 && height <= s.CanonicalBlocks[i-1] {
+			return fmt.Errorf("%w: %d follows %d", errCanonicalBlocksNotIncreasing, height, s.CanonicalBlocks[i-1])
+		}
+	}
+	return nil
+}
+
+// heights returns a map of height to blockID for the bonus blocks in [s].
+func (s *BonusBlockSet) heights() map[uint64]ids.ID {
+	heights := make(map[uint64]ids.ID, len(s.BonusBlocks))
+	for _, bonus := range s.BonusBlocks {
+		heights[bonus.Height] = bonus.BlockID
+	}
+	return heights
+}
+
+// bonusBlockSet returns the bonus blocks used by the VM. The set configured
+// with [Config.BonusBlocks] takes precedence over the set embedded for the
+// network, and networks without an embedded set have no bonus blocks.
+func (vm *VM) bonusBlockSet() *BonusBlockSet {
+	switch {
+	case vm.config.BonusBlocks != nil:
+		return vm.config.BonusBlocks
+	case vm.chainID.Cmp(params.OdysseyMainnetChainID) == 0:
+		return mainnetBonusBlocks
+	case vm.chainID.Cmp(params.OdysseyTestnetChainID) == 0:
+		return testnetBonusBlocks
+	default:
+		return &BonusBlockSet{}
+	}
+}
@@ -0,0 +1,142 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedBonusBlocks(t *testing.T) {
+	require := require.New(t)
+
+	require.Len(mainnetBonusBlocks.BonusBlocks, 57)
+	require.Len(mainnetBonusBlocks.CanonicalBlocks, 22)
+	blkID, err := ids.FromString("Njm9TcLUXRojZk8YhEM6ksvfiPdC1TME4zJvGaDXgzMCyB6oB")
+	require.NoError(err)
+	require.Equal(blkID, mainnetBonusBlocks.heights()[102972])
+
+	require.Empty(testnetBonusBlocks.BonusBlocks)
+	require.Empty(testnetBonusBlocks.CanonicalBlocks)
+}
+
+func TestParseBonusBlockSet(t *testing.T) {
+	blkID := ids.GenerateTestID()
+	tests := map[string]struct {
+		json        string
+		expectedErr error
+	}{
+		"valid": {
+			json: fmt.Sprintf(`{"bonusBlocks": [{"height": 1, "blockID": "%s"}, {"height": 3, "blockID": "%s"}], "canonicalBlocks": [2, 4]}`, blkID, blkID),
+		},
+		"empty": {
+			json: `{}`,
+		},
+		"bonus heights decreasing": {
+			json:        fmt.Sprintf(`{"bonusBlocks": [{"height": 3, "blockID": "%s"}, {"height": 1, "blockID": "%s"}]}`, blkID, blkID),
+			expectedErr: errBonusBlocksNotIncreasing,
+		},
+		"bonus heights repeated": {
+			json:        fmt.Sprintf(`{"bonusBlocks": [{"height": 1, "blockID": "%s"}, {"height": 1, "blockID": "%s"}]}`, blkID, blkID),
+			expectedErr: errBonusBlocksNotIncreasing,
+		},
+		"empty block ID": {
+			json:        fmt.Sprintf(`{"bonusBlocks": [{"height": 1, "blockID": "%s"}]}`, ids.Empty),
+			expectedErr: errEmptyBonusBlockID,
+		},
+		"canonical heights decreasing": {
+			json:        `{"canonicalBlocks": [4, 2]}`,
+			expectedErr: errCanonicalBlocksNotIncreasing,
+		},
+	}
+	for name, test := range tests {
+		_, err := parseBonusBlockSet([]byte(test.json))
+		require.ErrorIs(t, err, test.expectedErr, name)
+	}
+
+	_, err := parseBonusBlockSet([]byte(`{"bonusBlocks": [{"height": 1, "blockID": "not an ID"}]}`))
+	require.Error(t, err)
+}
+
+func TestBonusBlocksConfigOverride(t *testing.T) {
+	require := require.New(t)
+
+	blkID := ids.GenerateTestID()
+	configJSON := fmt.Sprintf(`{"bonus-blocks": {"bonusBlocks": [{"height": 5, "blockID": "%s"}], "canonicalBlocks": [4]}}`, blkID)
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, configJSON, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	reply := &BonusBlockSet{}
+	require.NoError(NewAdminService(vm, "").GetBonusBlocks(nil, nil, reply))
+	require.Equal(BonusBlockSet{
+		BonusBlocks:     []BonusBlock{{Height: 5, BlockID: blkID}},
+		CanonicalBlocks: []uint64{4},
+	}, *reply)
+	require.True(vm.atomicBackend.IsBonus(5, [32]byte(blkID)))
+	require.False(vm.atomicBackend.IsBonus(4, [32]byte(blkID)))
+
+	// Unordered overrides are rejected.
+	config := Config{}
+	config.SetDefaults()
+	config.BonusBlocks = &BonusBlockSet{CanonicalBlocks: []uint64{2, 1}}
+	require.ErrorIs(config.Validate(), errCanonicalBlocksNotIncreasing)
+}
+
+func TestBonusBlockSkipsMissingUTXOs(t *testing.T) {
+	require := require.New(t)
+
+	importAmount := 10 * params.OdysseyAtomicTxFee
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONApricotPhase2, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+
+	// Without the UTXOs in shared memory, the block fails verification...
+	_, otherVM, _, _, _ := GenesisVM(t, true, genesisJSONApricotPhase2, "", "")
+	defer func() {
+		require.NoError(otherVM.Shutdown(context.Background()))
+	}()
+	otherBlk, err := otherVM.ParseBlock(context.Background(), blk.Bytes())
+	require.NoError(err)
+	require.ErrorIs(otherBlk.Verify(context.Background()), errMissingUTXOs)
+
+	// ...unless it is configured as a bonus block.
+	configJSON := fmt.Sprintf(`{"bonus-blocks": {"bonusBlocks": [{"height": %d, "blockID": "%s"}]}}`, blk.Height(), blk.ID())
+	_, bonusVM, _, _, _ := GenesisVM(t, true, genesisJSONApricotPhase2, configJSON, "")
+	defer func() {
+		require.NoError(bonusVM.Shutdown(context.Background()))
+	}()
+	bonusBlk, err := bonusVM.ParseBlock(context.Background(), blk.Bytes())
+	require.NoError(err)
+	require.NoError(bonusBlk.Verify(context.Background()))
+	require.NoError(bonusVM.SetPreference(context.Background(), bonusBlk.ID()))
+	require.NoError(bonusBlk.Accept(context.Background()))
+
+	lastAcceptedID, err := bonusVM.LastAccepted(context.Background())
+	require.NoError(err)
+	require.Equal(blk.ID(), lastAcceptedID)
+	state, err := bonusVM.blockChain.State()
+	require.NoError(err)
+	expectedBalance := new(big.Int).Mul(new(big.Int).SetUint64(importAmount-params.OdysseyAtomicTxFee), x2cRate)
+	require.Equal(expectedBalance, state.GetBalance(testEthAddrs[0]))
+}
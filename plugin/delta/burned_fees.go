@@ -0,0 +1,179 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/utils/json"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxBurnedFeesRange is the maximum number of heights that can be
+	// requested from GetBurnedFees at once.
+	maxBurnedFeesRange = 1024
+	// burnedFeesBackfillBatchSize is the number of historical heights indexed
+	// before the backfill flushes them to disk.
+	burnedFeesBackfillBatchSize = 128
+
+	// atomicBurnedLen is the length of the encoded atomic burned amount that
+	// prefixes each burned fees index entry.
+	atomicBurnedLen = 8
+)
+
+var (
+	errInvalidBurnedFeesRange = errors.New("invalid burned fees range")
+	errInvalidBurnedFees      = errors.New("invalid burned fees index entry")
+)
+
+// BurnedFees are the fees burned by an accepted block, and by every block in
+// the requested range up to and including it. All amounts are in wei.
+type BurnedFees struct {
+	Height json.Uint64 `json:"height"`
+	// AtomicBurned is the DIONE burned by the atomic txs of the block.
+	AtomicBurned *hexutil.Big `json:"atomicBurned"`
+	// BaseFeeBurned is the base fee of the block times the gas used by its
+	// EVM txs.
+	BaseFeeBurned           *hexutil.Big `json:"baseFeeBurned"`
+	CumulativeAtomicBurned  *hexutil.Big `json:"cumulativeAtomicBurned"`
+	CumulativeBaseFeeBurned *hexutil.Big `json:"cumulativeBaseFeeBurned"`
+}
+
+// blockBurnedFees are the fees burned by a single block as stored in the
+// burned fees index. [atomicBurned] is in nDIONE and [baseFeeBurned] in wei.
+type blockBurnedFees struct {
+	atomicBurned  uint64
+	baseFeeBurned *big.Int
+}
+
+func (f *blockBurnedFees) Bytes() []byte {
+	return append(database.PackUInt64(f.atomicBurned), f.baseFeeBurned.Bytes()...)
+}
+
+func parseBlockBurnedFees(b []byte) (*blockBurnedFees, error) {
+	if len(b) < atomicBurnedLen {
+		return nil, fmt.Errorf("%w: length %d", errInvalidBurnedFees, len(b))
+	}
+	atomicBurned, err := database.ParseUInt64(b[:atomicBurnedLen])
+	if err != nil {
+		return nil, err
+	}
+	return &blockBurnedFees{
+		atomicBurned:  atomicBurned,
+		baseFeeBurned: new(big.Int).SetBytes(b[atomicBurnedLen:]),
+	}, nil
+}
+
+// computeBurnedFees returns the fees burned by [b].
+func (b *Block) computeBurnedFees() (*blockBurnedFees, error) {
+	atomicBurned, err := b.TotalBurned(b.vm.ctx.DIONEAssetID)
+	if err != nil {
+		return nil, err
+	}
+	baseFeeBurned := new(big.Int)
+	if baseFee := b.ethBlock.BaseFee(); baseFee != nil {
+		baseFeeBurned.Mul(baseFee, new(big.Int).SetUint64(b.ethBlock.GasUsed()))
+	}
+	return &blockBurnedFees{
+		atomicBurned:  atomicBurned,
+		baseFeeBurned: baseFeeBurned,
+	}, nil
+}
+
+// indexBurnedFees adds the fees burned by the accepted block [b] to the burned
+// fees index.
+func (vm *VM) indexBurnedFees(b *Block) error {
+	fees, err := b.computeBurnedFees()
+	if err != nil {
+		return err
+	}
+	return vm.burnedFeesDB.Put(database.PackUInt64(b.Height()), fees.Bytes())
+}
+
+// GetBurnedFees returns the fees burned by each accepted block in
+// [start, end], along with the totals burned from [start] to each block.
+// Heights missing from the index, such as those accepted before the index was
+// introduced, are indexed on demand.
+func (vm *VM) GetBurnedFees(start, end uint64) ([]BurnedFees, error) {
+	lastAccepted := vm.LastAcceptedBlockInternal().Height()
+	switch {
+	case start > end:
+		return nil, fmt.Errorf("%w: start %d is after end %d", errInvalidBurnedFeesRange, start, end)
+	case end-start >= maxBurnedFeesRange:
+		return nil, fmt.Errorf("%w: cannot request more than %d heights", errInvalidBurnedFeesRange, maxBurnedFeesRange)
+	case end > lastAccepted:
+		return nil, fmt.Errorf("%w: end %d is after the last accepted height %d", errInvalidBurnedFeesRange, end, lastAccepted)
+	}
+
+	var (
+		result           = make([]BurnedFees, 0, end-start+1)
+		cumulativeAtomic = new(big.Int)
+		cumulativeEVM    = new(big.Int)
+		batch            = vm.burnedFeesDB.NewBatch()
+		backfilled       int
+	)
+	for height := start; height <= end; height++ {
+		fees, indexed, err := vm.getBurnedFees(batch, height)
+		if err != nil {
+			return nil, err
+		}
+		if !indexed {
+			backfilled++
+		}
+		if backfilled == burnedFeesBackfillBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+			backfilled = 0
+		}
+
+		atomicBurned := new(big.Int).Mul(new(big.Int).SetUint64(fees.atomicBurned), x2cRate)
+		cumulativeAtomic.Add(cumulativeAtomic, atomicBurned)
+		cumulativeEVM.Add(cumulativeEVM, fees.baseFeeBurned)
+		result = append(result, BurnedFees{
+			Height:                  json.Uint64(height),
+			AtomicBurned:            (*hexutil.Big)(atomicBurned),
+			BaseFeeBurned:           (*hexutil.Big)(fees.baseFeeBurned),
+			CumulativeAtomicBurned:  (*hexutil.Big)(new(big.Int).Set(cumulativeAtomic)),
+			CumulativeBaseFeeBurned: (*hexutil.Big)(new(big.Int).Set(cumulativeEVM)),
+		})
+	}
+	return result, batch.Write()
+}
+
+// getBurnedFees returns the fees burned by the accepted block at [height] and
+// whether they were already indexed. If the height is not indexed yet, the
+// fees are computed from the block and written to [batch].
+func (vm *VM) getBurnedFees(batch database.Batch, height uint64) (*blockBurnedFees, bool, error) {
+	key := database.PackUInt64(height)
+	feesBytes, err := vm.burnedFeesDB.Get(key)
+	if err == nil {
+		fees, err := parseBlockBurnedFees(feesBytes)
+		return fees, true, err
+	}
+	if err != database.ErrNotFound {
+		return nil, false, err
+	}
+
+	ethBlock := vm.blockChain.GetBlockByNumber(height)
+	if ethBlock == nil {
+		return nil, false, fmt.Errorf("accepted block at height %d is not available", height)
+	}
+	blk, err := vm.newBlock(ethBlock)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse block at height %d: %w", height, err)
+	}
+	fees, err := blk.computeBurnedFees()
+	if err != nil {
+		return nil, false, err
+	}
+	log.Debug("backfilling burned fees index", "height", height)
+	return fees, false, batch.Put(key, fees.Bytes())
+}
@@ -0,0 +1,183 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	odysseyJSON "github.com/DioneProtocol/odysseygo/utils/json"
+	"github.com/DioneProtocol/odysseygo/utils/units"
+	"github.com/DioneProtocol/odysseygo/vms/components/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBurnedFees(t *testing.T) {
+	require := require.New(t)
+	issuer, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// Start in the past so that blocks can be spaced out without being built
+	// in the future.
+	vm.clock.Set(time.Now().Add(-time.Hour))
+
+	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
+	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
+
+	var nonce uint64
+	issueEthTx := func() {
+		errs := vm.txPool.AddRemotesSync([]*types.Transaction{newTestEthTx(t, vm, nonce)})
+		require.Len(errs, 1)
+		require.NoError(errs[0])
+		nonce++
+		<-issuer
+	}
+	issueExportTx := func() {
+		tx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+		require.NoError(err)
+		require.NoError(vm.issueTx(tx, true /*=local*/))
+		nonce++
+	}
+	acceptBlock := func() *Block {
+		// Leave enough time since the parent for the block gas cost to be
+		// covered by atomic txs alone.
+		vm.clock.Set(vm.clock.Time().Add(time.Minute))
+		blk, err := vm.BuildBlock(context.Background())
+		require.NoError(err)
+		require.NoError(blk.Verify(context.Background()))
+		require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+		require.NoError(blk.Accept(context.Background()))
+		vm.blockChain.DrainAcceptorQueue()
+		newHead := <-newTxPoolHeadChan
+		require.Equal(common.Hash(blk.ID()), newHead.Head.Hash())
+		return blk.(*chain.BlockWrapper).Block.(*Block)
+	}
+
+	// Block 1 only contains an EVM tx, block 2 only an atomic tx and block 3
+	// contains both.
+	issueEthTx()
+	blks := []*Block{vm.LastAcceptedBlockInternal().(*Block), acceptBlock()}
+	issueExportTx()
+	blks = append(blks, acceptBlock())
+	issueEthTx()
+	issueExportTx()
+	blks = append(blks, acceptBlock())
+
+	var (
+		expected         = make([]BurnedFees, 0, len(blks))
+		cumulativeAtomic = new(big.Int)
+		cumulativeEVM    = new(big.Int)
+	)
+	for _, blk := range blks {
+		atomicBurned := new(big.Int)
+		for _, tx := range blk.atomicTxs {
+			burned, err := tx.Burned(vm.ctx.DIONEAssetID)
+			require.NoError(err)
+			atomicBurned.Add(atomicBurned, new(big.Int).Mul(new(big.Int).SetUint64(burned), x2cRate))
+		}
+		evmBurned := new(big.Int)
+		if blk.Height() > 0 {
+			evmBurned.Mul(blk.ethBlock.BaseFee(), new(big.Int).SetUint64(blk.ethBlock.GasUsed()))
+		}
+		cumulativeAtomic.Add(cumulativeAtomic, atomicBurned)
+		cumulativeEVM.Add(cumulativeEVM, evmBurned)
+		expected = append(expected, BurnedFees{
+			Height:                  odysseyJSON.Uint64(blk.Height()),
+			AtomicBurned:            (*hexutil.Big)(atomicBurned),
+			BaseFeeBurned:           (*hexutil.Big)(evmBurned),
+			CumulativeAtomicBurned:  (*hexutil.Big)(new(big.Int).Set(cumulativeAtomic)),
+			CumulativeBaseFeeBurned: (*hexutil.Big)(new(big.Int).Set(cumulativeEVM)),
+		})
+	}
+	require.Zero(expected[1].AtomicBurned.ToInt().Sign())
+	require.Positive(expected[1].BaseFeeBurned.ToInt().Sign())
+	require.Positive(expected[2].AtomicBurned.ToInt().Sign())
+	require.Zero(expected[2].BaseFeeBurned.ToInt().Sign())
+	require.Positive(expected[3].AtomicBurned.ToInt().Sign())
+	require.Positive(expected[3].BaseFeeBurned.ToInt().Sign())
+
+	// The genesis block is not accepted through Accept, so it is backfilled.
+	fees, err := vm.GetBurnedFees(0, 3)
+	require.NoError(err)
+	require.Equal(expected, fees)
+
+	// Drop the index entries of the first blocks to emulate history accepted
+	// before the index existed.
+	for _, height := range []uint64{1, 2} {
+		require.NoError(vm.burnedFeesDB.Delete(database.PackUInt64(height)))
+	}
+	fees, err = vm.GetBurnedFees(2, 3)
+	require.NoError(err)
+	require.Len(fees, 2)
+	require.Equal(expected[2].AtomicBurned, fees[0].AtomicBurned)
+	require.Equal(expected[3].BaseFeeBurned, fees[1].BaseFeeBurned)
+	require.Equal(expected[2].AtomicBurned, fees[0].CumulativeAtomicBurned)
+	require.Equal(
+		new(big.Int).Add(expected[2].AtomicBurned.ToInt(), expected[3].AtomicBurned.ToInt()),
+		fees[1].CumulativeAtomicBurned.ToInt(),
+	)
+	has, err := vm.burnedFeesDB.Has(database.PackUInt64(2))
+	require.NoError(err)
+	require.True(has)
+	has, err = vm.burnedFeesDB.Has(database.PackUInt64(1))
+	require.NoError(err)
+	require.False(has)
+
+	// A single height is returned when no end height is given.
+	service := &DioneAPI{vm: vm}
+	reply := &GetBurnedFeesReply{}
+	require.NoError(service.GetBurnedFees(nil, &GetBurnedFeesArgs{StartHeight: 1}, reply))
+	require.Equal([]BurnedFees{{
+		Height:                  1,
+		AtomicBurned:            expected[1].AtomicBurned,
+		BaseFeeBurned:           expected[1].BaseFeeBurned,
+		CumulativeAtomicBurned:  expected[1].AtomicBurned,
+		CumulativeBaseFeeBurned: expected[1].BaseFeeBurned,
+	}}, reply.Fees)
+}
+
+func TestGetBurnedFeesInvalidRange(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	tests := map[string]struct {
+		start, end uint64
+	}{
+		"start after end":           {start: 1, end: 0},
+		"after last accepted block": {start: 0, end: 1},
+		"too many heights":          {start: 0, end: maxBurnedFeesRange},
+	}
+	for name, test := range tests {
+		_, err := vm.GetBurnedFees(test.start, test.end)
+		require.ErrorIs(err, errInvalidBurnedFeesRange, name)
+	}
+}
+
+func TestBlockBurnedFeesBytes(t *testing.T) {
+	require := require.New(t)
+
+	fees := &blockBurnedFees{
+		atomicBurned:  units.Dione,
+		baseFeeBurned: new(big.Int).Lsh(big.NewInt(1), 100),
+	}
+	parsed, err := parseBlockBurnedFees(fees.Bytes())
+	require.NoError(err)
+	require.Equal(fees, parsed)
+
+	_, err = parseBlockBurnedFees([]byte{1})
+	require.ErrorIs(err, errInvalidBurnedFees)
+}
@@ -5,9 +5,13 @@ package delta
 
 import (
 	"context"
+	stdjson "encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/DioneProtocol/odysseygo/api"
@@ -15,36 +19,91 @@ import (
 	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
 	"github.com/DioneProtocol/odysseygo/utils/formatting"
 	"github.com/DioneProtocol/odysseygo/utils/formatting/address"
+	"github.com/DioneProtocol/odysseygo/utils/hashing"
 	"github.com/DioneProtocol/odysseygo/utils/json"
 	"github.com/DioneProtocol/odysseygo/utils/rpc"
+	"github.com/DioneProtocol/odysseygo/utils/set"
 )
 
 // Interface compliance
 var _ Client = (*client)(nil)
 
+var (
+	errInvalidChain         = errors.New("invalid chain")
+	errCodecVersionMismatch = errors.New("codec version mismatch")
+)
+
+// primaryChainAliases are the default aliases of the primary network chains.
+var primaryChainAliases = set.Of("O", "omega", "A", "alpha", "D", "delta")
+
+// validateChain returns an error if [chain] is neither the alias of a primary
+// network chain nor a CB58 blockchain ID, so that a malformed chain is reported
+// before a request is sent.
+func validateChain(chain string) error {
+	if primaryChainAliases.Contains(chain) {
+		return nil
+	}
+	if _, err := ids.FromString(chain); err != nil {
+		return fmt.Errorf("%w %q: expected a chain alias (O, A or D) or a CB58 blockchain ID", errInvalidChain, chain)
+	}
+	return nil
+}
+
 // Client interface for interacting with DELTA [chain]
 type Client interface {
 	IssueTx(ctx context.Context, txBytes []byte, options ...rpc.Option) (ids.ID, error)
+	ValidateAtomicTx(ctx context.Context, txBytes []byte, options ...rpc.Option) error
 	GetAtomicTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (Status, error)
+	GetAtomicTxStatuses(ctx context.Context, txIDs []ids.ID, options ...rpc.Option) (map[ids.ID]Status, error)
 	GetAtomicTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error)
+	GetAtomicTxJSON(ctx context.Context, txID ids.ID, options ...rpc.Option) (*GetAtomicTxJSONReply, error)
+	GetPendingAtomicTxs(ctx context.Context, addrs []common.Address, limit uint32, options ...rpc.Option) ([]PendingAtomicTx, error)
+	AtomicHealth(ctx context.Context, options ...rpc.Option) (*AtomicHealthReply, error)
+	GetGasPriceStatus(ctx context.Context, options ...rpc.Option) (price, minFee *big.Int, err error)
+	EstimateBaseFee(ctx context.Context, includeMempool bool, options ...rpc.Option) (baseFee, mempoolBaseFee *big.Int, err error)
+	GetAssetConversionRate(ctx context.Context, options ...rpc.Option) (*big.Int, error)
+	GetBaseFeeWindow(ctx context.Context, blockID ids.ID, options ...rpc.Option) ([]uint64, *big.Int, error)
+	GetChainConfig(ctx context.Context, options ...rpc.Option) (*GetChainConfigReply, error)
+	GetCodecVersion(ctx context.Context, options ...rpc.Option) (uint16, error)
+	GetOrionNodes(ctx context.Context, timestamp uint64, options ...rpc.Option) ([]ids.NodeID, error)
+	GetBurnedFees(ctx context.Context, startHeight, endHeight uint64, options ...rpc.Option) ([]BurnedFees, error)
+	FeeHistory(ctx context.Context, blockCount uint64, newestBlock string, rewardPercentiles []float64, options ...rpc.Option) (*FeeHistoryResult, error)
+	FeeRecipientByBlock(ctx context.Context, blockID ids.ID, options ...rpc.Option) (map[common.Address]*big.Int, error)
+	GetFeeDistribution(ctx context.Context, blockID ids.ID, options ...rpc.Option) (*GetFeeDistributionReply, error)
 	GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error)
+	IterateAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, f func([]byte) error, options ...rpc.Option) error
 	ExportKey(ctx context.Context, userPass api.UserPass, addr common.Address, options ...rpc.Option) (*secp256k1.PrivateKey, string, error)
 	ImportKey(ctx context.Context, userPass api.UserPass, privateKey *secp256k1.PrivateKey, options ...rpc.Option) (common.Address, error)
 	Import(ctx context.Context, userPass api.UserPass, to common.Address, sourceChain string, options ...rpc.Option) (ids.ID, error)
+	ImportWithFees(ctx context.Context, userPass api.UserPass, to common.Address, sourceChain string, fees AtomicTxFees, options ...rpc.Option) (ids.ID, error)
 	ExportDIONE(ctx context.Context, userPass api.UserPass, amount uint64, to ids.ShortID, targetChain string, options ...rpc.Option) (ids.ID, error)
+	ExportDIONEWithFees(ctx context.Context, userPass api.UserPass, amount uint64, to ids.ShortID, targetChain string, fees AtomicTxFees, options ...rpc.Option) (ids.ID, error)
 	Export(ctx context.Context, userPass api.UserPass, amount uint64, to ids.ShortID, targetChain string, assetID string, options ...rpc.Option) (ids.ID, error)
-	StartCPUProfiler(ctx context.Context, options ...rpc.Option) error
-	StopCPUProfiler(ctx context.Context, options ...rpc.Option) error
-	MemoryProfile(ctx context.Context, options ...rpc.Option) error
-	LockProfile(ctx context.Context, options ...rpc.Option) error
+	ExportWithFees(ctx context.Context, userPass api.UserPass, amount uint64, to ids.ShortID, targetChain string, assetID string, fees AtomicTxFees, options ...rpc.Option) (ids.ID, error)
+	BuildExportTx(ctx context.Context, from common.Address, amount uint64, to ids.ShortID, targetChain string, assetID string, fees AtomicTxFees, options ...rpc.Option) (*BuildExportTxReply, error)
+	StartCPUProfiler(ctx context.Context, options ...rpc.Option) (string, error)
+	StopCPUProfiler(ctx context.Context, options ...rpc.Option) (string, error)
+	MemoryProfile(ctx context.Context, options ...rpc.Option) (string, error)
+	LockProfile(ctx context.Context, options ...rpc.Option) (string, error)
+	ProfileStatus(ctx context.Context, options ...rpc.Option) (*ProfileStatus, error)
 	SetLogLevel(ctx context.Context, level log.Lvl, options ...rpc.Option) error
+	SetLogFormat(ctx context.Context, format string, options ...rpc.Option) error
 	GetVMConfig(ctx context.Context, options ...rpc.Option) (*Config, error)
+	GetBonusBlocks(ctx context.Context, options ...rpc.Option) (*BonusBlockSet, error)
+	GetNetworkUpgradeSchedule(ctx context.Context, options ...rpc.Option) ([]UpgradeEvent, error)
+	StreamBlocks(ctx context.Context, fromHeight uint64) (<-chan BlockSummary, error)
 }
 
 // Client implementation for interacting with DELTA [chain]
 type client struct {
 	requester      rpc.EndpointRequester
 	adminRequester rpc.EndpointRequester
+	rpcURI         string
+	wsURI          string
+	streamConfig   StreamConfig
+	// checkCodecVersion makes IssueTx check that the node uses the codec
+	// version of the client before sending a tx.
+	checkCodecVersion bool
 }
 
 // NewClient returns a Client for interacting with DELTA [chain]
@@ -52,6 +111,9 @@ func NewClient(uri, chain string) Client {
 	return &client{
 		requester:      rpc.NewEndpointRequester(fmt.Sprintf("%s/ext/bc/%s/dione", uri, chain)),
 		adminRequester: rpc.NewEndpointRequester(fmt.Sprintf("%s/ext/bc/%s/admin", uri, chain)),
+		rpcURI:         fmt.Sprintf("%s/ext/bc/%s%s", uri, chain, ethRPCEndpoint),
+		wsURI:          fmt.Sprintf("%s/ext/bc/%s%s", wsURI(uri), chain, ethWSEndpoint),
+		streamConfig:   DefaultStreamConfig,
 	}
 }
 
@@ -60,9 +122,28 @@ func NewDChainClient(uri string) Client {
 	return NewClient(uri, "D")
 }
 
+// NewClientWithCodecVersionCheck returns a Client for interacting with DELTA
+// [chain] whose IssueTx fails with a codec version mismatch, without sending
+// the tx, if the node does not decode atomic txs with the codec version of the
+// client.
+func NewClientWithCodecVersionCheck(uri, chain string) Client {
+	c := NewClient(uri, chain).(*client)
+	c.checkCodecVersion = true
+	return c
+}
+
 // IssueTx issues a transaction to a node and returns the TxID
 func (c *client) IssueTx(ctx context.Context, txBytes []byte, options ...rpc.Option) (ids.ID, error) {
 	res := &api.JSONTxID{}
+	if c.checkCodecVersion {
+		nodeVersion, err := c.GetCodecVersion(ctx, options...)
+		if err != nil {
+			return res.TxID, fmt.Errorf("failed to get codec version of node: %w", err)
+		}
+		if nodeVersion != codecVersion {
+			return res.TxID, fmt.Errorf("%w: client encodes txs with version %d, node decodes version %d", errCodecVersionMismatch, codecVersion, nodeVersion)
+		}
+	}
 	txStr, err := formatting.Encode(formatting.Hex, txBytes)
 	if err != nil {
 		return res.TxID, fmt.Errorf("problem hex encoding bytes: %w", err)
@@ -74,6 +155,28 @@ func (c *client) IssueTx(ctx context.Context, txBytes []byte, options ...rpc.Opt
 	return res.TxID, err
 }
 
+// ValidateAtomicTx verifies [txBytes] against the last accepted block of the
+// node without issuing it. If the transaction is invalid, the returned error is
+// an *AtomicTxValidationError describing the kind of failure.
+func (c *client) ValidateAtomicTx(ctx context.Context, txBytes []byte, options ...rpc.Option) error {
+	res := &ValidateAtomicTxReply{}
+	txStr, err := formatting.Encode(formatting.Hex, txBytes)
+	if err != nil {
+		return fmt.Errorf("problem hex encoding bytes: %w", err)
+	}
+	err = c.requester.SendRequest(ctx, "dione.validateAtomicTx", &api.FormattedTx{
+		Tx:       txStr,
+		Encoding: formatting.Hex,
+	}, res, options...)
+	if err != nil {
+		return err
+	}
+	if !res.Valid {
+		return &AtomicTxValidationError{Kind: res.Kind, Err: errors.New(res.Error)}
+	}
+	return nil
+}
+
 // GetAtomicTxStatus returns the status of [txID]
 func (c *client) GetAtomicTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (Status, error) {
 	res := &GetAtomicTxStatusReply{}
@@ -83,6 +186,23 @@ func (c *client) GetAtomicTxStatus(ctx context.Context, txID ids.ID, options ...
 	return res.Status, err
 }
 
+// GetAtomicTxStatuses returns the status of each of [txIDs] in a single
+// request
+func (c *client) GetAtomicTxStatuses(ctx context.Context, txIDs []ids.ID, options ...rpc.Option) (map[ids.ID]Status, error) {
+	res := &GetAtomicTxStatusesReply{}
+	err := c.requester.SendRequest(ctx, "dione.getAtomicTxStatuses", &GetAtomicTxStatusesArgs{
+		TxIDs: txIDs,
+	}, res, options...)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[ids.ID]Status, len(res.Statuses))
+	for _, status := range res.Statuses {
+		statuses[status.TxID] = status.Status
+	}
+	return statuses, nil
+}
+
 // GetAtomicTx returns the byte representation of [txID]
 func (c *client) GetAtomicTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error) {
 	res := &api.FormattedTx{}
@@ -97,9 +217,171 @@ func (c *client) GetAtomicTx(ctx context.Context, txID ids.ID, options ...rpc.Op
 	return formatting.Decode(formatting.Hex, res.Tx)
 }
 
+// GetAtomicTxJSONReply is the JSON view of an atomic tx returned by
+// GetAtomicTxJSON. BlockHeight is nil if the tx has not been accepted.
+type GetAtomicTxJSONReply struct {
+	Tx          stdjson.RawMessage  `json:"tx"`
+	Encoding    formatting.Encoding `json:"encoding"`
+	BlockHeight *json.Uint64        `json:"blockHeight,omitempty"`
+}
+
+// GetAtomicTxJSON returns the decoded JSON representation of [txID]
+func (c *client) GetAtomicTxJSON(ctx context.Context, txID ids.ID, options ...rpc.Option) (*GetAtomicTxJSONReply, error) {
+	res := &GetAtomicTxJSONReply{}
+	err := c.requester.SendRequest(ctx, "dione.getAtomicTx", &api.GetTxArgs{
+		TxID:     txID,
+		Encoding: formatting.JSON,
+	}, res, options...)
+	return res, err
+}
+
+// GetPendingAtomicTxs returns up to [limit] atomic txs waiting in the mempool
+// that import to or export from any of [addrs]. If [addrs] is empty, txs are not
+// filtered by address, and if [limit] is 0 every pending tx is returned.
+func (c *client) GetPendingAtomicTxs(ctx context.Context, addrs []common.Address, limit uint32, options ...rpc.Option) ([]PendingAtomicTx, error) {
+	res := &GetPendingAtomicTxsReply{}
+	err := c.requester.SendRequest(ctx, "dione.getPendingAtomicTxs", &GetPendingAtomicTxsArgs{
+		Addresses: addrs,
+		Limit:     json.Uint32(limit),
+	}, res, options...)
+	return res.Txs, err
+}
+
+// AtomicHealth returns the state of the atomic trie of the node and whether it
+// is consistent with the last accepted block
+func (c *client) AtomicHealth(ctx context.Context, options ...rpc.Option) (*AtomicHealthReply, error) {
+	res := &AtomicHealthReply{}
+	err := c.requester.SendRequest(ctx, "dione.atomicHealth", struct{}{}, res, options...)
+	return res, err
+}
+
+// GetGasPriceStatus returns the gas price and minimum fee currently enforced by
+// the node's tx pool. Either value is nil if it has not been set yet.
+func (c *client) GetGasPriceStatus(ctx context.Context, options ...rpc.Option) (price, minFee *big.Int, err error) {
+	res := &GasPriceStatusReply{}
+	err = c.requester.SendRequest(ctx, "dione.getGasPriceStatus", struct{}{}, res, options...)
+	return res.GasPrice.ToInt(), res.MinFee.ToInt(), err
+}
+
+// EstimateBaseFee returns the estimated base fee of the next block from the gas
+// consumed by the preferred block only. If [includeMempool] is true, it also
+// returns the estimate including the gas of the txs pending on the node.
+func (c *client) EstimateBaseFee(ctx context.Context, includeMempool bool, options ...rpc.Option) (baseFee, mempoolBaseFee *big.Int, err error) {
+	res := &EstimateBaseFeeReply{}
+	err = c.requester.SendRequest(ctx, "dione.estimateBaseFee", &EstimateBaseFeeArgs{
+		IncludeMempool: includeMempool,
+	}, res, options...)
+	return res.BaseFee.ToInt(), res.MempoolBaseFee.ToInt(), err
+}
+
+// GetAssetConversionRate returns the number of wei per nDIONE under the rules
+// of the node's current block
+func (c *client) GetAssetConversionRate(ctx context.Context, options ...rpc.Option) (*big.Int, error) {
+	res := &GetAssetConversionRateReply{}
+	err := c.requester.SendRequest(ctx, "dione.getAssetConversionRate", struct{}{}, res, options...)
+	return res.Rate.ToInt(), err
+}
+
+// GetBaseFeeWindow returns the gas consumed in each second of the rollup window
+// encoded in [blockID], oldest first, and the base fee of the block
+func (c *client) GetBaseFeeWindow(ctx context.Context, blockID ids.ID, options ...rpc.Option) ([]uint64, *big.Int, error) {
+	res := &GetBaseFeeWindowReply{}
+	err := c.requester.SendRequest(ctx, "dione.getBaseFeeWindow", &GetBaseFeeWindowArgs{
+		BlockID: blockID,
+	}, res, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	window := make([]uint64, len(res.Window))
+	for i, gas := range res.Window {
+		window[i] = uint64(gas)
+	}
+	return window, res.BaseFee.ToInt(), nil
+}
+
+// GetChainConfig returns the chain config of the node and the rules that its
+// preferred block was produced under
+func (c *client) GetChainConfig(ctx context.Context, options ...rpc.Option) (*GetChainConfigReply, error) {
+	res := &GetChainConfigReply{}
+	err := c.requester.SendRequest(ctx, "dione.getChainConfig", struct{}{}, res, options...)
+	return res, err
+}
+
+// GetCodecVersion returns the version of the codec that the node encodes and
+// decodes atomic txs with
+func (c *client) GetCodecVersion(ctx context.Context, options ...rpc.Option) (uint16, error) {
+	res := &GetCodecVersionReply{}
+	err := c.requester.SendRequest(ctx, "dione.getCodecVersion", struct{}{}, res, options...)
+	return uint16(res.CodecVersion), err
+}
+
+// GetOrionNodes returns the orion nodes registered in the state of the last
+// accepted block at or before [timestamp]
+func (c *client) GetOrionNodes(ctx context.Context, timestamp uint64, options ...rpc.Option) ([]ids.NodeID, error) {
+	res := &GetOrionNodesReply{}
+	err := c.requester.SendRequest(ctx, "dione.getOrionNodes", &GetOrionNodesArgs{
+		Timestamp: json.Uint64(timestamp),
+	}, res, options...)
+	return res.NodeIDs, err
+}
+
+// GetBurnedFees returns the fees burned by each accepted block in
+// [startHeight, endHeight] and the cumulative totals over the range
+func (c *client) GetBurnedFees(ctx context.Context, startHeight, endHeight uint64, options ...rpc.Option) ([]BurnedFees, error) {
+	res := &GetBurnedFeesReply{}
+	end := json.Uint64(endHeight)
+	err := c.requester.SendRequest(ctx, "dione.getBurnedFees", &GetBurnedFeesArgs{
+		StartHeight: json.Uint64(startHeight),
+		EndHeight:   &end,
+	}, res, options...)
+	return res.Fees, err
+}
+
+// FeeRecipientByBlock returns the amount of the EVM tx fees of [blockID]
+// credited to each address by the fee distribution
+func (c *client) FeeRecipientByBlock(ctx context.Context, blockID ids.ID, options ...rpc.Option) (map[common.Address]*big.Int, error) {
+	res := &FeeRecipientByBlockReply{}
+	err := c.requester.SendRequest(ctx, "dione.feeRecipientByBlock", &FeeRecipientByBlockArgs{
+		BlockID: blockID,
+	}, res, options...)
+	if err != nil {
+		return nil, err
+	}
+	recipients := make(map[common.Address]*big.Int, len(res.Recipients))
+	for addr, amount := range res.Recipients {
+		recipients[addr] = amount.ToInt()
+	}
+	return recipients, nil
+}
+
+// GetFeeDistribution returns the distribution of the EVM tx fees of [blockID]
+// and the orion nodes list it was calculated with
+func (c *client) GetFeeDistribution(ctx context.Context, blockID ids.ID, options ...rpc.Option) (*GetFeeDistributionReply, error) {
+	res := &GetFeeDistributionReply{}
+	err := c.requester.SendRequest(ctx, "dione.getFeeDistribution", &GetFeeDistributionArgs{
+		BlockID: blockID,
+	}, res, options...)
+	return res, err
+}
+
+// FeeHistory returns the fee market history of the [blockCount] blocks ending
+// at [newestBlock], along with the distribution of the base fee of each block
+func (c *client) FeeHistory(ctx context.Context, blockCount uint64, newestBlock string, rewardPercentiles []float64, options ...rpc.Option) (*FeeHistoryResult, error) {
+	res := &FeeHistoryResult{}
+	err := c.requester.SendRequest(ctx, "dione.feeHistory", &FeeHistoryArgs{
+		BlockCount:        json.Uint64(blockCount),
+		NewestBlock:       newestBlock,
+		RewardPercentiles: rewardPercentiles,
+	}, res, options...)
+	return res, err
+}
+
 // GetAtomicUTXOs returns the byte representation of the atomic UTXOs controlled by [addresses]
 // from [sourceChain]
 func (c *client) GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error) {
+	if err := validateChain(sourceChain); err != nil {
+		return nil, ids.ShortID{}, ids.Empty, err
+	}
 	res := &api.GetUTXOsReply{}
 	err := c.requester.SendRequest(ctx, "dione.getUTXOs", &api.GetUTXOsArgs{
 		Addresses:   ids.ShortIDsToStrings(addrs),
@@ -131,6 +413,50 @@ func (c *client) GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, source
 	return utxos, endAddr, endUTXOID, err
 }
 
+// IterateAtomicUTXOs calls [f] with the byte representation of each atomic
+// UTXO controlled by [addrs] from [sourceChain], following the pagination of
+// GetAtomicUTXOs until every UTXO has been fetched. [addrs] are requested in
+// batches of at most maxGetUTXOsAddrs, and a UTXO controlled by addresses in
+// different batches is only passed to [f] once. Iteration stops at the first
+// error returned by [f] or by a request, or once [ctx] is cancelled.
+func (c *client) IterateAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, f func([]byte) error, options ...rpc.Option) error {
+	seen := set.Set[ids.ID]{}
+	for len(addrs) > 0 {
+		batch := addrs
+		if len(batch) > maxGetUTXOsAddrs {
+			batch = batch[:maxGetUTXOsAddrs]
+		}
+		addrs = addrs[len(batch):]
+
+		startAddr, startUTXOID := ids.ShortEmpty, ids.Empty
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			utxos, endAddr, endUTXOID, err := c.GetAtomicUTXOs(ctx, batch, sourceChain, maxUTXOsToFetch, startAddr, startUTXOID, options...)
+			if err != nil {
+				return err
+			}
+			for _, utxo := range utxos {
+				utxoHash := ids.ID(hashing.ComputeHash256Array(utxo))
+				if seen.Contains(utxoHash) {
+					continue
+				}
+				seen.Add(utxoHash)
+				if err := f(utxo); err != nil {
+					return err
+				}
+			}
+			// A short page means the node has no more UTXOs for [batch].
+			if len(utxos) < maxUTXOsToFetch {
+				break
+			}
+			startAddr, startUTXOID = endAddr, endUTXOID
+		}
+	}
+	return nil
+}
+
 // ExportKey returns the private key corresponding to [addr] controlled by [user]
 // in both Odyssey standard format and hex format
 func (c *client) ExportKey(ctx context.Context, user api.UserPass, addr common.Address, options ...rpc.Option) (*secp256k1.PrivateKey, string, error) {
@@ -155,14 +481,39 @@ func (c *client) ImportKey(ctx context.Context, user api.UserPass, privateKey *s
 	return ParseEthAddress(res.Address)
 }
 
+// AtomicTxFees are the optional fee parameters of the atomic txs created by
+// the client. The zero value lets the node choose the fee.
+type AtomicTxFees struct {
+	// Maximum base fee the tx may pay. Optional. The tx is dropped from the
+	// mempool if the base fee rises above it before the tx is included.
+	MaxBaseFee *big.Int
+
+	// Fee paid on top of the base fee. Optional.
+	PriorityFee *big.Int
+
+	// Skip the atomic tx fee limits configured for the node. Optional.
+	AllowHighFee bool
+}
+
 // Import sends an import transaction to import funds from [sourceChain] and
 // returns the ID of the newly created transaction
 func (c *client) Import(ctx context.Context, user api.UserPass, to common.Address, sourceChain string, options ...rpc.Option) (ids.ID, error) {
+	return c.ImportWithFees(ctx, user, to, sourceChain, AtomicTxFees{}, options...)
+}
+
+// ImportWithFees is Import paying [fees].
+func (c *client) ImportWithFees(ctx context.Context, user api.UserPass, to common.Address, sourceChain string, fees AtomicTxFees, options ...rpc.Option) (ids.ID, error) {
+	if err := validateChain(sourceChain); err != nil {
+		return ids.Empty, err
+	}
 	res := &api.JSONTxID{}
 	err := c.requester.SendRequest(ctx, "dione.import", &ImportArgs{
-		UserPass:    user,
-		To:          to,
-		SourceChain: sourceChain,
+		UserPass:     user,
+		MaxBaseFee:   (*hexutil.Big)(fees.MaxBaseFee),
+		PriorityFee:  (*hexutil.Big)(fees.PriorityFee),
+		AllowHighFee: fees.AllowHighFee,
+		To:           to,
+		SourceChain:  sourceChain,
 	}, res, options...)
 	return res.TxID, err
 }
@@ -180,6 +531,19 @@ func (c *client) ExportDIONE(
 	return c.Export(ctx, user, amount, to, targetChain, "DIONE", options...)
 }
 
+// ExportDIONEWithFees is ExportDIONE paying [fees].
+func (c *client) ExportDIONEWithFees(
+	ctx context.Context,
+	user api.UserPass,
+	amount uint64,
+	to ids.ShortID,
+	targetChain string,
+	fees AtomicTxFees,
+	options ...rpc.Option,
+) (ids.ID, error) {
+	return c.ExportWithFees(ctx, user, amount, to, targetChain, "DIONE", fees, options...)
+}
+
 // Export sends an asset from this chain to the O/D-Chain.
 // After this tx is accepted, the DIONE must be imported to the O/D-chain with an importTx.
 // Returns the ID of the newly created atomic transaction
@@ -192,33 +556,105 @@ func (c *client) Export(
 	assetID string,
 	options ...rpc.Option,
 ) (ids.ID, error) {
+	return c.ExportWithFees(ctx, user, amount, to, targetChain, assetID, AtomicTxFees{}, options...)
+}
+
+// ExportWithFees is Export paying [fees].
+func (c *client) ExportWithFees(
+	ctx context.Context,
+	user api.UserPass,
+	amount uint64,
+	to ids.ShortID,
+	targetChain string,
+	assetID string,
+	fees AtomicTxFees,
+	options ...rpc.Option,
+) (ids.ID, error) {
+	if err := validateChain(targetChain); err != nil {
+		return ids.Empty, err
+	}
 	res := &api.JSONTxID{}
 	err := c.requester.SendRequest(ctx, "dione.export", &ExportArgs{
 		ExportDIONEArgs: ExportDIONEArgs{
-			UserPass:    user,
-			Amount:      json.Uint64(amount),
-			TargetChain: targetChain,
-			To:          to.String(),
+			UserPass:     user,
+			MaxBaseFee:   (*hexutil.Big)(fees.MaxBaseFee),
+			PriorityFee:  (*hexutil.Big)(fees.PriorityFee),
+			AllowHighFee: fees.AllowHighFee,
+			Amount:       json.Uint64(amount),
+			TargetChain:  targetChain,
+			To:           to.String(),
 		},
 		AssetID: assetID,
 	}, res, options...)
 	return res.TxID, err
 }
 
-func (c *client) StartCPUProfiler(ctx context.Context, options ...rpc.Option) error {
-	return c.adminRequester.SendRequest(ctx, "admin.startCPUProfiler", struct{}{}, &api.EmptyReply{}, options...)
+// BuildExportTx returns an unsigned tx that sends an asset owned by [from]
+// from this chain to the O/D-Chain, along with the digest each input must be
+// signed over. The signed tx can be issued with IssueTx.
+func (c *client) BuildExportTx(
+	ctx context.Context,
+	from common.Address,
+	amount uint64,
+	to ids.ShortID,
+	targetChain string,
+	assetID string,
+	fees AtomicTxFees,
+	options ...rpc.Option,
+) (*BuildExportTxReply, error) {
+	if err := validateChain(targetChain); err != nil {
+		return nil, err
+	}
+	res := &BuildExportTxReply{}
+	err := c.requester.SendRequest(ctx, "dione.buildExportTx", &BuildExportTxArgs{
+		MaxBaseFee:   (*hexutil.Big)(fees.MaxBaseFee),
+		PriorityFee:  (*hexutil.Big)(fees.PriorityFee),
+		AllowHighFee: fees.AllowHighFee,
+		AssetID:      assetID,
+		Amount:       json.Uint64(amount),
+		TargetChain:  targetChain,
+		To:           to.String(),
+		From:         from,
+		Encoding:     formatting.Hex,
+	}, res, options...)
+	return res, err
+}
+
+// StartCPUProfiler starts a CPU profile and returns the file it is written to
+func (c *client) StartCPUProfiler(ctx context.Context, options ...rpc.Option) (string, error) {
+	res := &ProfileReply{}
+	err := c.adminRequester.SendRequest(ctx, "admin.startCPUProfiler", struct{}{}, res, options...)
+	return res.Path, err
+}
+
+// StopCPUProfiler stops the running CPU profile and returns the file it was
+// written to
+func (c *client) StopCPUProfiler(ctx context.Context, options ...rpc.Option) (string, error) {
+	res := &ProfileReply{}
+	err := c.adminRequester.SendRequest(ctx, "admin.stopCPUProfiler", struct{}{}, res, options...)
+	return res.Path, err
 }
 
-func (c *client) StopCPUProfiler(ctx context.Context, options ...rpc.Option) error {
-	return c.adminRequester.SendRequest(ctx, "admin.stopCPUProfiler", struct{}{}, &api.EmptyReply{}, options...)
+// MemoryProfile writes a memory profile and returns the file it was written to
+func (c *client) MemoryProfile(ctx context.Context, options ...rpc.Option) (string, error) {
+	res := &ProfileReply{}
+	err := c.adminRequester.SendRequest(ctx, "admin.memoryProfile", struct{}{}, res, options...)
+	return res.Path, err
 }
 
-func (c *client) MemoryProfile(ctx context.Context, options ...rpc.Option) error {
-	return c.adminRequester.SendRequest(ctx, "admin.memoryProfile", struct{}{}, &api.EmptyReply{}, options...)
+// LockProfile writes a mutex profile and returns the file it was written to
+func (c *client) LockProfile(ctx context.Context, options ...rpc.Option) (string, error) {
+	res := &ProfileReply{}
+	err := c.adminRequester.SendRequest(ctx, "admin.lockProfile", struct{}{}, res, options...)
+	return res.Path, err
 }
 
-func (c *client) LockProfile(ctx context.Context, options ...rpc.Option) error {
-	return c.adminRequester.SendRequest(ctx, "admin.lockProfile", struct{}{}, &api.EmptyReply{}, options...)
+// ProfileStatus returns whether a CPU profile is running and the files of the
+// last profiles
+func (c *client) ProfileStatus(ctx context.Context, options ...rpc.Option) (*ProfileStatus, error) {
+	res := &ProfileStatus{}
+	err := c.adminRequester.SendRequest(ctx, "admin.profileStatus", struct{}{}, res, options...)
+	return res, err
 }
 
 // SetLogLevel dynamically sets the log level for the D Chain
@@ -228,9 +664,32 @@ func (c *client) SetLogLevel(ctx context.Context, level log.Lvl, options ...rpc.
 	}, &api.EmptyReply{}, options...)
 }
 
+// SetLogFormat dynamically switches the format of the logs of the D Chain to
+// either LogFormatTerminal or LogFormatJSON
+func (c *client) SetLogFormat(ctx context.Context, format string, options ...rpc.Option) error {
+	return c.adminRequester.SendRequest(ctx, "admin.setLogFormat", &SetLogFormatArgs{
+		Format: format,
+	}, &api.EmptyReply{}, options...)
+}
+
 // GetVMConfig returns the current config of the VM
 func (c *client) GetVMConfig(ctx context.Context, options ...rpc.Option) (*Config, error) {
 	res := &ConfigReply{}
 	err := c.adminRequester.SendRequest(ctx, "admin.getVMConfig", struct{}{}, res, options...)
 	return res.Config, err
 }
+
+// GetBonusBlocks returns the bonus blocks whose atomic txs the node skips
+func (c *client) GetBonusBlocks(ctx context.Context, options ...rpc.Option) (*BonusBlockSet, error) {
+	res := &BonusBlockSet{}
+	err := c.adminRequester.SendRequest(ctx, "admin.getBonusBlocks", struct{}{}, res, options...)
+	return res, err
+}
+
+// GetNetworkUpgradeSchedule returns the past and scheduled network upgrades of
+// the D Chain and whether they have been activated
+func (c *client) GetNetworkUpgradeSchedule(ctx context.Context, options ...rpc.Option) ([]UpgradeEvent, error) {
+	res := &GetNetworkUpgradeScheduleReply{}
+	err := c.adminRequester.SendRequest(ctx, "admin.getNetworkUpgradeSchedule", struct{}{}, res, options...)
+	return res.Upgrades, err
+}
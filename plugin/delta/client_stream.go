@@ -0,0 +1,255 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/ethclient"
+	"github.com/DioneProtocol/coreth/interfaces"
+)
+
+var errSubscriptionClosed = errors.New("new head subscription closed")
+
+// DefaultStreamConfig is the StreamConfig used by clients created with
+// NewClient.
+var DefaultStreamConfig = StreamConfig{
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	PollInterval:   2 * time.Second,
+}
+
+// StreamConfig configures how StreamBlocks follows the chain.
+type StreamConfig struct {
+	// InitialBackoff is the delay before reconnecting after the connection
+	// drops. It doubles after each failed attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// PollInterval is the delay between polls for the last accepted block
+	// when the WebSocket endpoint is unavailable.
+	PollInterval time.Duration
+}
+
+// BlockSummary describes an accepted block emitted by StreamBlocks.
+type BlockSummary struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	ParentHash common.Hash `json:"parentHash"`
+	Timestamp  uint64      `json:"timestamp"`
+	GasUsed    uint64      `json:"gasUsed"`
+	BaseFee    *big.Int    `json:"baseFee"`
+}
+
+func newBlockSummary(header *types.Header) BlockSummary {
+	return BlockSummary{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Timestamp:  header.Time,
+		GasUsed:    header.GasUsed,
+		BaseFee:    header.BaseFee,
+	}
+}
+
+// NewClientWithStreamConfig returns a Client for interacting with DELTA
+// [chain] whose StreamBlocks follows the chain according to [config].
+func NewClientWithStreamConfig(uri, chain string, config StreamConfig) Client {
+	c := NewClient(uri, chain).(*client)
+	c.streamConfig = config
+	return c
+}
+
+// wsURI returns the WebSocket equivalent of the HTTP [uri].
+func wsURI(uri string) string {
+	switch {
+	case strings.HasPrefix(uri, "https://"):
+		return "wss://" + strings.TrimPrefix(uri, "https://")
+	case strings.HasPrefix(uri, "http://"):
+		return "ws://" + strings.TrimPrefix(uri, "http://")
+	default:
+		return uri
+	}
+}
+
+// StreamBlocks emits a summary of every accepted block starting at
+// [fromHeight] on the returned channel, in order. New blocks are received
+// over a WebSocket subscription, or by polling the last accepted block if the
+// WebSocket endpoint is unavailable. If the connection drops, StreamBlocks
+// reconnects with back-off and resumes after the last emitted block. The
+// channel is closed once [ctx] is cancelled.
+func (c *client) StreamBlocks(ctx context.Context, fromHeight uint64) (<-chan BlockSummary, error) {
+	s := &blockStream{
+		wsURI:  c.wsURI,
+		rpcURI: c.rpcURI,
+		config: c.streamConfig,
+		next:   fromHeight,
+		out:    make(chan BlockSummary),
+	}
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go s.run(ctx, conn)
+	return s.out, nil
+}
+
+// blockStream follows the chain for StreamBlocks.
+type blockStream struct {
+	wsURI, rpcURI string
+	config        StreamConfig
+
+	next uint64 // height of the next block to emit
+	out  chan BlockSummary
+}
+
+// streamConn is a connection to the node. [sub] is nil if the connection
+// polls for new blocks.
+type streamConn struct {
+	eth   ethclient.Client
+	sub   interfaces.Subscription
+	heads chan *types.Header
+}
+
+func (conn *streamConn) close() {
+	if conn.sub != nil {
+		conn.sub.Unsubscribe()
+	}
+	conn.eth.Close()
+}
+
+// connect subscribes to new heads over WebSocket, falling back to polling
+// over HTTP if the subscription cannot be made.
+func (s *blockStream) connect(ctx context.Context) (*streamConn, error) {
+	if eth, err := ethclient.DialContext(ctx, s.wsURI); err == nil {
+		heads := make(chan *types.Header, 1)
+		sub, err := eth.SubscribeNewHead(ctx, heads)
+		if err == nil {
+			return &streamConn{eth: eth, sub: sub, heads: heads}, nil
+		}
+		eth.Close()
+		log.Debug("failed to subscribe to new heads, falling back to polling", "err", err)
+	} else {
+		log.Debug("failed to dial websocket endpoint, falling back to polling", "err", err)
+	}
+
+	eth, err := ethclient.DialContext(ctx, s.rpcURI)
+	if err != nil {
+		return nil, err
+	}
+	// Dialing over HTTP does not contact the node, so make a request to
+	// ensure it is reachable.
+	if _, err := eth.BlockNumber(ctx); err != nil {
+		eth.Close()
+		return nil, err
+	}
+	return &streamConn{eth: eth}, nil
+}
+
+// run follows the chain over [conn] and reconnects whenever the connection
+// drops, until [ctx] is cancelled.
+func (s *blockStream) run(ctx context.Context, conn *streamConn) {
+	defer close(s.out)
+
+	backoff := s.config.InitialBackoff
+	for {
+		if conn != nil {
+			err := s.follow(ctx, conn)
+			conn.close()
+			if ctx.Err() != nil {
+				return
+			}
+			log.Debug("block stream disconnected", "next", s.next, "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		var err error
+		conn, err = s.connect(ctx)
+		if err != nil {
+			log.Debug("failed to reconnect block stream", "backoff", backoff, "err", err)
+			backoff *= 2
+			if backoff > s.config.MaxBackoff {
+				backoff = s.config.MaxBackoff
+			}
+			continue
+		}
+		backoff = s.config.InitialBackoff
+	}
+}
+
+// follow emits every block up to the last accepted block and then each new
+// block as it is accepted, until [ctx] is cancelled or [conn] fails.
+func (s *blockStream) follow(ctx context.Context, conn *streamConn) error {
+	lastAccepted, err := conn.eth.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.emitThrough(ctx, conn.eth, lastAccepted); err != nil {
+		return err
+	}
+
+	if conn.sub == nil {
+		ticker := time.NewTicker(s.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				lastAccepted, err := conn.eth.BlockNumber(ctx)
+				if err != nil {
+					return err
+				}
+				if err := s.emitThrough(ctx, conn.eth, lastAccepted); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-conn.sub.Err():
+			if err == nil {
+				err = errSubscriptionClosed
+			}
+			return err
+		case head := <-conn.heads:
+			if err := s.emitThrough(ctx, conn.eth, head.Number.Uint64()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// emitThrough emits the blocks from [s.next] to [height] inclusive.
+func (s *blockStream) emitThrough(ctx context.Context, eth ethclient.Client, height uint64) error {
+	for ; s.next <= height; s.next++ {
+		header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(s.next))
+		if err != nil {
+			return fmt.Errorf("failed to fetch block %d: %w", s.next, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s.out <- newBlockSummary(header):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/rpc"
+)
+
+var testStreamConfig = StreamConfig{
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     50 * time.Millisecond,
+	PollInterval:   10 * time.Millisecond,
+}
+
+// mockChainService serves the subset of the eth API used by StreamBlocks.
+type mockChainService struct {
+	lock    sync.Mutex
+	headers []*types.Header
+	subs    map[rpc.ID]*rpc.Notifier
+}
+
+func newMockChainService(numBlocks int) *mockChainService {
+	s := &mockChainService{subs: make(map[rpc.ID]*rpc.Notifier)}
+	for i := 0; i < numBlocks; i++ {
+		s.accept()
+	}
+	return s
+}
+
+// accept appends a new block to the chain and notifies the subscribers.
+func (s *mockChainService) accept() *types.Header {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	header := &types.Header{
+		Number:     big.NewInt(int64(len(s.headers))),
+		Time:       uint64(len(s.headers)),
+		Difficulty: common.Big1,
+		BaseFee:    big.NewInt(25 * params.GWei),
+	}
+	if len(s.headers) > 0 {
+		header.ParentHash = s.headers[len(s.headers)-1].Hash()
+	}
+	s.headers = append(s.headers, header)
+	for id, notifier := range s.subs {
+		_ = notifier.Notify(id, header)
+	}
+	return header
+}
+
+func (s *mockChainService) BlockNumber() hexutil.Uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return hexutil.Uint64(len(s.headers) - 1)
+}
+
+func (s *mockChainService) GetBlockByNumber(number rpc.BlockNumber, _ bool) (*types.Header, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if number < 0 || int(number) >= len(s.headers) {
+		return nil, nil
+	}
+	return s.headers[number], nil
+}
+
+func (s *mockChainService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+
+	s.lock.Lock()
+	s.subs[sub.ID] = notifier
+	s.lock.Unlock()
+	go func() {
+		select {
+		case <-sub.Err():
+		case <-notifier.Closed():
+		}
+		s.lock.Lock()
+		delete(s.subs, sub.ID)
+		s.lock.Unlock()
+	}()
+	return sub, nil
+}
+
+// connTracker records the connections hijacked by WebSocket upgrades so that
+// tests can drop them.
+type connTracker struct {
+	lock  sync.Mutex
+	conns []net.Conn
+}
+
+func (t *connTracker) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(&trackedResponseWriter{ResponseWriter: w, tracker: t}, r)
+	})
+}
+
+func (t *connTracker) dropAll() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, conn := range t.conns {
+		_ = conn.Close()
+	}
+	t.conns = nil
+}
+
+type trackedResponseWriter struct {
+	http.ResponseWriter
+	tracker *connTracker
+}
+
+func (w *trackedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		w.tracker.lock.Lock()
+		w.tracker.conns = append(w.tracker.conns, conn)
+		w.tracker.lock.Unlock()
+	}
+	return conn, rw, err
+}
+
+// newMockChainServer serves [service] over HTTP and, if [websocket] is true,
+// over WebSocket at the D-Chain endpoints.
+func newMockChainServer(t *testing.T, service *mockChainService, websocket bool) (*httptest.Server, *connTracker) {
+	server := rpc.NewServer(0)
+	require.NoError(t, server.RegisterName("eth", service))
+
+	tracker := &connTracker{}
+	mux := http.NewServeMux()
+	mux.Handle("/ext/bc/D"+ethRPCEndpoint, server)
+	if websocket {
+		mux.Handle("/ext/bc/D"+ethWSEndpoint, tracker.wrap(server.WebsocketHandler([]string{"*"})))
+	}
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		tracker.dropAll()
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer, tracker
+}
+
+// requireNextBlocks reads the next blocks from [blocks] and checks that they
+// match [expected].
+func requireNextBlocks(t *testing.T, blocks <-chan BlockSummary, expected ...*types.Header) {
+	t.Helper()
+	for _, header := range expected {
+		select {
+		case summary, ok := <-blocks:
+			require.True(t, ok, "stream closed before block %d", header.Number)
+			require.Equal(t, newBlockSummary(header), summary)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for block %d", header.Number)
+		}
+	}
+}
+
+func requireClosed(t *testing.T, blocks <-chan BlockSummary) {
+	t.Helper()
+	select {
+	case _, ok := <-blocks:
+		require.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the stream to close")
+	}
+}
+
+func TestStreamBlocksWebSocket(t *testing.T) {
+	require := require.New(t)
+
+	service := newMockChainService(5)
+	server, _ := newMockChainServer(t, service, true)
+	c := NewClientWithStreamConfig(server.URL, "D", testStreamConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks, err := c.StreamBlocks(ctx, 2)
+	require.NoError(err)
+
+	// Blocks accepted before the stream started are emitted first.
+	requireNextBlocks(t, blocks, service.headers[2:]...)
+
+	// Wait for the subscription to be registered before accepting a block.
+	require.Eventually(func() bool {
+		service.lock.Lock()
+		defer service.lock.Unlock()
+		return len(service.subs) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	requireNextBlocks(t, blocks, service.accept(), service.accept())
+
+	cancel()
+	requireClosed(t, blocks)
+}
+
+func TestStreamBlocksPollingFallback(t *testing.T) {
+	require := require.New(t)
+
+	service := newMockChainService(3)
+	server, _ := newMockChainServer(t, service, false)
+	c := NewClientWithStreamConfig(server.URL, "D", testStreamConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks, err := c.StreamBlocks(ctx, 0)
+	require.NoError(err)
+
+	requireNextBlocks(t, blocks, service.headers...)
+	requireNextBlocks(t, blocks, service.accept(), service.accept())
+
+	cancel()
+	requireClosed(t, blocks)
+}
+
+func TestStreamBlocksReconnect(t *testing.T) {
+	require := require.New(t)
+
+	service := newMockChainService(2)
+	server, tracker := newMockChainServer(t, service, true)
+	c := NewClientWithStreamConfig(server.URL, "D", testStreamConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks, err := c.StreamBlocks(ctx, 0)
+	require.NoError(err)
+	requireNextBlocks(t, blocks, service.headers...)
+
+	// Blocks accepted while the connection is down are emitted once the
+	// stream reconnects, without repeating blocks already emitted.
+	tracker.dropAll()
+	missed := []*types.Header{service.accept(), service.accept()}
+	requireNextBlocks(t, blocks, missed...)
+
+	require.Eventually(func() bool {
+		service.lock.Lock()
+		defer service.lock.Unlock()
+		return len(service.subs) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	requireNextBlocks(t, blocks, service.accept())
+
+	cancel()
+	requireClosed(t, blocks)
+}
+
+func TestStreamBlocksUnavailable(t *testing.T) {
+	service := newMockChainService(1)
+	server, _ := newMockChainServer(t, service, true)
+	server.Close()
+
+	c := NewClientWithStreamConfig(server.URL, "D", testStreamConfig)
+	_, err := c.StreamBlocks(context.Background(), 0)
+	require.Error(t, err)
+}
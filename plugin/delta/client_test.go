@@ -0,0 +1,417 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/api"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/constants"
+	"github.com/DioneProtocol/odysseygo/utils/formatting"
+	"github.com/DioneProtocol/odysseygo/utils/formatting/address"
+	"github.com/DioneProtocol/odysseygo/utils/json"
+	"github.com/DioneProtocol/odysseygo/utils/rpc"
+	"github.com/DioneProtocol/odysseygo/utils/set"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// mockUTXO is an atomic UTXO served by mockUTXORequester, indexed by [addr].
+type mockUTXO struct {
+	addr  ids.ShortID
+	id    ids.ID
+	bytes []byte
+}
+
+// mockUTXORequester serves dione.getUTXOs from [utxos], which are ordered by
+// address and then by UTXO ID, paginating like the shared memory index.
+type mockUTXORequester struct {
+	utxos    []mockUTXO
+	requests int
+}
+
+func (r *mockUTXORequester) SendRequest(_ context.Context, method string, params interface{}, reply interface{}, _ ...rpc.Option) error {
+	if method != "dione.getUTXOs" {
+		return fmt.Errorf("unexpected method %q", method)
+	}
+	r.requests++
+	args := params.(*api.GetUTXOsArgs)
+	res := reply.(*api.GetUTXOsReply)
+
+	addrs := set.Set[string]{}
+	addrs.Add(args.Addresses...)
+	startAddr, err := ids.ShortFromString(args.StartIndex.Address)
+	if err != nil {
+		return err
+	}
+	startUTXOID, err := ids.FromString(args.StartIndex.UTXO)
+	if err != nil {
+		return err
+	}
+	started := startAddr == ids.ShortEmpty && startUTXOID == ids.Empty
+
+	endAddr, endUTXOID := startAddr, startUTXOID
+	for _, utxo := range r.utxos {
+		if !started {
+			started = utxo.addr == startAddr && utxo.id == startUTXOID
+			continue
+		}
+		if !addrs.Contains(utxo.addr.String()) {
+			continue
+		}
+		if len(res.UTXOs) == int(args.Limit) {
+			break
+		}
+		str, err := formatting.Encode(args.Encoding, utxo.bytes)
+		if err != nil {
+			return err
+		}
+		res.UTXOs = append(res.UTXOs, str)
+		endAddr, endUTXOID = utxo.addr, utxo.id
+	}
+
+	res.EndIndex.Address, err = address.Format("D", constants.UnitTestHRP, endAddr[:])
+	if err != nil {
+		return err
+	}
+	res.EndIndex.UTXO = endUTXOID.String()
+	res.Encoding = args.Encoding
+	return nil
+}
+
+// newMockUTXOs returns [numUTXOs] UTXOs for each of [addrs]. The UTXOs of
+// [shared] are indexed under both of its addresses.
+func newMockUTXOs(addrs []ids.ShortID, numUTXOs int, shared ...[2]ids.ShortID) []mockUTXO {
+	var utxos []mockUTXO
+	for i, addr := range addrs {
+		for j := 0; j < numUTXOs; j++ {
+			utxoID := ids.ID{byte(j), byte(j >> 8)}
+			utxos = append(utxos, mockUTXO{
+				addr:  addr,
+				id:    utxoID,
+				bytes: []byte(fmt.Sprintf("utxo-%d-%d", i, j)),
+			})
+		}
+	}
+	for i, owners := range shared {
+		for _, addr := range owners {
+			utxos = append(utxos, mockUTXO{
+				addr:  addr,
+				id:    ids.ID{0xff, byte(i)},
+				bytes: []byte(fmt.Sprintf("shared-%d", i)),
+			})
+		}
+	}
+	// Order by address and then by UTXO ID, like the shared memory index.
+	sort.Slice(utxos, func(i, j int) bool {
+		if c := bytes.Compare(utxos[i].addr[:], utxos[j].addr[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(utxos[i].id[:], utxos[j].id[:]) < 0
+	})
+	return utxos
+}
+
+func TestIterateAtomicUTXOs(t *testing.T) {
+	addrs := make([]ids.ShortID, maxGetUTXOsAddrs+2)
+	for i := range addrs {
+		addrs[i] = ids.ShortID{byte(i), byte(i >> 8), 1}
+	}
+
+	tests := []struct {
+		name             string
+		addrs            []ids.ShortID
+		utxos            []mockUTXO
+		expectedUTXOs    int
+		expectedRequests int
+	}{
+		{
+			name:             "no utxos",
+			addrs:            addrs[:1],
+			expectedRequests: 1,
+		},
+		{
+			name:             "single page",
+			addrs:            addrs[:2],
+			utxos:            newMockUTXOs(addrs[:2], 3),
+			expectedUTXOs:    6,
+			expectedRequests: 1,
+		},
+		{
+			name:          "exact page",
+			addrs:         addrs[:1],
+			utxos:         newMockUTXOs(addrs[:1], maxUTXOsToFetch),
+			expectedUTXOs: maxUTXOsToFetch,
+			// A full page is followed by an empty page.
+			expectedRequests: 2,
+		},
+		{
+			name:             "multiple pages",
+			addrs:            addrs[:3],
+			utxos:            newMockUTXOs(addrs[:3], maxUTXOsToFetch-1),
+			expectedUTXOs:    3 * (maxUTXOsToFetch - 1),
+			expectedRequests: 3,
+		},
+		{
+			name:  "address batches",
+			addrs: addrs,
+			// The UTXO owned by the first and the last address is returned
+			// by both batches.
+			utxos:            newMockUTXOs(addrs, 1, [2]ids.ShortID{addrs[0], addrs[len(addrs)-1]}),
+			expectedUTXOs:    len(addrs) + 1,
+			expectedRequests: 3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			requester := &mockUTXORequester{utxos: test.utxos}
+			c := &client{requester: requester}
+
+			seen := set.Set[string]{}
+			err := c.IterateAtomicUTXOs(context.Background(), test.addrs, "A", func(utxo []byte) error {
+				require.False(seen.Contains(string(utxo)), "utxo %s returned twice", utxo)
+				seen.Add(string(utxo))
+				return nil
+			})
+			require.NoError(err)
+			require.Equal(test.expectedUTXOs, seen.Len())
+			require.Equal(test.expectedRequests, requester.requests)
+		})
+	}
+}
+
+func TestIterateAtomicUTXOsErrors(t *testing.T) {
+	addrs := []ids.ShortID{{1}}
+	utxos := newMockUTXOs(addrs, maxUTXOsToFetch+1)
+
+	t.Run("callback error", func(t *testing.T) {
+		require := require.New(t)
+
+		errStop := errors.New("stop")
+		requester := &mockUTXORequester{utxos: utxos}
+		c := &client{requester: requester}
+
+		calls := 0
+		err := c.IterateAtomicUTXOs(context.Background(), addrs, "A", func([]byte) error {
+			calls++
+			return errStop
+		})
+		require.ErrorIs(err, errStop)
+		require.Equal(1, calls)
+		require.Equal(1, requester.requests)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		require := require.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		requester := &mockUTXORequester{utxos: utxos}
+		c := &client{requester: requester}
+
+		calls := 0
+		err := c.IterateAtomicUTXOs(ctx, addrs, "A", func([]byte) error {
+			calls++
+			cancel()
+			return nil
+		})
+		require.ErrorIs(err, context.Canceled)
+		// The page being processed is finished, but no further page is
+		// requested.
+		require.Equal(maxUTXOsToFetch, calls)
+		require.Equal(1, requester.requests)
+	})
+
+	t.Run("request error", func(t *testing.T) {
+		require := require.New(t)
+
+		c := &client{requester: rpc.NewEndpointRequester("http://127.0.0.1:0/ext/bc/D/dione")}
+		err := c.IterateAtomicUTXOs(context.Background(), addrs, "A", func([]byte) error {
+			return nil
+		})
+		require.Error(err)
+	})
+}
+
+// unreachableRequester fails the test if a request is sent.
+type unreachableRequester struct {
+	t *testing.T
+}
+
+func (r unreachableRequester) SendRequest(_ context.Context, method string, _ interface{}, _ interface{}, _ ...rpc.Option) error {
+	r.t.Fatalf("unexpected request %q", method)
+	return nil
+}
+
+func TestValidateChain(t *testing.T) {
+	tests := []struct {
+		chain string
+		valid bool
+	}{
+		{chain: "A", valid: true},
+		{chain: "O", valid: true},
+		{chain: "D", valid: true},
+		{chain: "alpha", valid: true},
+		{chain: ids.GenerateTestID().String(), valid: true},
+		{chain: ""},
+		{chain: "X"},
+		{chain: "a"},
+		{chain: "bc/A"},
+		{chain: "not a chain id"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%q", test.chain), func(t *testing.T) {
+			err := validateChain(test.chain)
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, errInvalidChain)
+			}
+		})
+	}
+}
+
+func TestClientRejectsInvalidChain(t *testing.T) {
+	require := require.New(t)
+
+	c := &client{requester: unreachableRequester{t: t}}
+	ctx := context.Background()
+
+	_, _, _, err := c.GetAtomicUTXOs(ctx, []ids.ShortID{{1}}, "X", 0, ids.ShortEmpty, ids.Empty)
+	require.ErrorIs(err, errInvalidChain)
+	err = c.IterateAtomicUTXOs(ctx, []ids.ShortID{{1}}, "X", func([]byte) error { return nil })
+	require.ErrorIs(err, errInvalidChain)
+	_, err = c.Import(ctx, api.UserPass{}, common.Address{}, "X")
+	require.ErrorIs(err, errInvalidChain)
+	_, err = c.ExportDIONE(ctx, api.UserPass{}, 1, ids.ShortID{}, "X")
+	require.ErrorIs(err, errInvalidChain)
+	_, err = c.Export(ctx, api.UserPass{}, 1, ids.ShortID{}, "X", "DIONE")
+	require.ErrorIs(err, errInvalidChain)
+	_, err = c.BuildExportTx(ctx, common.Address{}, 1, ids.ShortID{}, "X", "DIONE", AtomicTxFees{})
+	require.ErrorIs(err, errInvalidChain)
+}
+
+// mockAtomicTxRequester records the args of the atomic txs it is sent.
+type mockAtomicTxRequester struct {
+	args []interface{}
+}
+
+func (r *mockAtomicTxRequester) SendRequest(_ context.Context, _ string, params interface{}, _ interface{}, _ ...rpc.Option) error {
+	r.args = append(r.args, params)
+	return nil
+}
+
+func TestClientAtomicTxFees(t *testing.T) {
+	require := require.New(t)
+
+	requester := &mockAtomicTxRequester{}
+	c := &client{requester: requester}
+	ctx := context.Background()
+	fees := AtomicTxFees{
+		MaxBaseFee:   big.NewInt(2),
+		PriorityFee:  big.NewInt(1),
+		AllowHighFee: true,
+	}
+
+	// The fees are left to the node by default.
+	_, err := c.Import(ctx, api.UserPass{}, common.Address{}, "O")
+	require.NoError(err)
+	_, err = c.ExportDIONE(ctx, api.UserPass{}, 1, ids.ShortID{}, "O")
+	require.NoError(err)
+	_, err = c.Export(ctx, api.UserPass{}, 1, ids.ShortID{}, "O", "DIONE")
+	require.NoError(err)
+	_, err = c.ImportWithFees(ctx, api.UserPass{}, common.Address{}, "O", fees)
+	require.NoError(err)
+	_, err = c.ExportDIONEWithFees(ctx, api.UserPass{}, 1, ids.ShortID{}, "O", fees)
+	require.NoError(err)
+	_, err = c.ExportWithFees(ctx, api.UserPass{}, 1, ids.ShortID{}, "O", "DIONE", fees)
+	require.NoError(err)
+	require.Len(requester.args, 6)
+
+	for i, args := range requester.args {
+		var (
+			maxBaseFee, priorityFee *big.Int
+			allowHighFee            bool
+		)
+		switch args := args.(type) {
+		case *ImportArgs:
+			maxBaseFee, priorityFee, allowHighFee = args.MaxBaseFee.ToInt(), args.PriorityFee.ToInt(), args.AllowHighFee
+		case *ExportArgs:
+			maxBaseFee, priorityFee, allowHighFee = args.MaxBaseFee.ToInt(), args.PriorityFee.ToInt(), args.AllowHighFee
+		default:
+			require.FailNow("unexpected args", "%T", args)
+		}
+		if i < 3 {
+			require.Nil(maxBaseFee)
+			require.Nil(priorityFee)
+			require.False(allowHighFee)
+		} else {
+			require.Equal(fees.MaxBaseFee, maxBaseFee)
+			require.Equal(fees.PriorityFee, priorityFee)
+			require.True(allowHighFee)
+		}
+	}
+}
+
+// mockCodecVersionRequester serves dione.getCodecVersion with [version] and
+// records the methods it is sent.
+type mockCodecVersionRequester struct {
+	version uint16
+	methods []string
+}
+
+func (r *mockCodecVersionRequester) SendRequest(_ context.Context, method string, _ interface{}, reply interface{}, _ ...rpc.Option) error {
+	r.methods = append(r.methods, method)
+	switch method {
+	case "dione.getCodecVersion":
+		reply.(*GetCodecVersionReply).CodecVersion = json.Uint16(r.version)
+	case "dione.issueTx":
+		reply.(*api.JSONTxID).TxID = ids.ID{1}
+	default:
+		return fmt.Errorf("unexpected method %q", method)
+	}
+	return nil
+}
+
+func TestIssueTxCodecVersionCheck(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	reply := &GetCodecVersionReply{}
+	require.NoError((&DioneAPI{}).GetCodecVersion(nil, nil, reply))
+	require.Equal(codecVersion, uint16(reply.CodecVersion))
+
+	requester := &mockCodecVersionRequester{version: codecVersion}
+	c := NewClientWithCodecVersionCheck("http://localhost:9650", "D").(*client)
+	c.requester = requester
+
+	// The tx is sent once the node is found to use the codec version of the
+	// client.
+	txID, err := c.IssueTx(ctx, []byte{1})
+	require.NoError(err)
+	require.Equal(ids.ID{1}, txID)
+	require.Equal([]string{"dione.getCodecVersion", "dione.issueTx"}, requester.methods)
+
+	// A mismatch is reported without sending the tx.
+	requester.version, requester.methods = codecVersion+1, nil
+	_, err = c.IssueTx(ctx, []byte{1})
+	require.ErrorIs(err, errCodecVersionMismatch)
+	require.Equal([]string{"dione.getCodecVersion"}, requester.methods)
+
+	// The version is not checked by default.
+	requester.methods = nil
+	c = NewClient("http://localhost:9650", "D").(*client)
+	c.requester = requester
+	_, err = c.IssueTx(ctx, []byte{1})
+	require.NoError(err)
+	require.Equal([]string{"dione.issueTx"}, requester.methods)
+}
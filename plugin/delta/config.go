@@ -10,6 +10,8 @@ import (
 
 	"github.com/DioneProtocol/coreth/core/txpool"
 	"github.com/DioneProtocol/coreth/eth"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/units"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cast"
 )
@@ -24,8 +26,9 @@ const (
 	defaultSnapshotCache                              = 256
 	defaultSyncableCommitInterval                     = defaultCommitInterval * 4
 	defaultSnapshotWait                               = false
-	defaultRpcGasCap                                  = 50_000_000 // Default to 50M Gas Limit
-	defaultRpcTxFeeCap                                = 1_058_200  // 1058200 DIONE
+	defaultRpcGasCap                                  = 50_000_000       // Default to 50M Gas Limit
+	defaultRpcTxFeeCap                                = 1_058_200        // 1058200 DIONE
+	defaultRpcReturnDataLimit                         = 25 * 1024 * 1024 // Default to 25 MB of return data per call
 	defaultMetricsExpensiveEnabled                    = true
 	defaultApiMaxDuration                             = 0 // Default to no maximum API call duration
 	defaultWsCpuRefillRate                            = 0 // Default to no maximum WS CPU usage
@@ -35,14 +38,23 @@ const (
 	defaultContinuousProfilerMaxFiles                 = 5
 	defaultTxRegossipFrequency                        = 1 * time.Minute
 	defaultTxRegossipMaxSize                          = 15
+	defaultAtomicTxGossipFraction                     = 0.1
+	defaultAtomicTxGossipMaxPeers                     = 50
+	defaultAtomicTxRegossipFrequency                  = 30 * time.Second
 	defaultOfflinePruningBloomFilterSize       uint64 = 512 // Default size (MB) for the offline pruner to use
 	defaultLogLevel                                   = "info"
 	defaultLogJSONFormat                              = false
+	defaultLogFileMaxSize                             = 100 // 100 MB
+	defaultLogFileMaxBackups                          = 5
 	defaultPopulateMissingTriesParallelism            = 1024
 	defaultMaxOutboundActiveRequests                  = 16
 	defaultMaxOutboundActiveCrossChainRequests        = 64
 	defaultStateSyncServerTrieCache                   = 64 // MB
 	defaultAcceptedCacheSize                          = 32 // blocks
+	defaultAtomicTxMaxFee                             = 10 * units.Dione
+	defaultAtomicMempoolPersistenceEnabled            = true
+	defaultAtomicMempoolPersistenceMaxBytes           = 16 * units.MiB
+	defaultAtomicMempoolMaxTxsPerAddress              = 16
 
 	// defaultStateSyncMinBlocks is the minimum number of blocks the blockchain
 	// should be ahead of local last accepted to perform state sync.
@@ -95,6 +107,10 @@ type Config struct {
 	// Coreth API Gas/Price Caps
 	RPCGasCap   uint64  `json:"rpc-gas-cap"`
 	RPCTxFeeCap float64 `json:"rpc-tx-fee-cap"`
+	// RPCReturnDataLimit caps the data returned by each call made by eth_call
+	// and debug_traceCall, and the return data captured by tracers. 0 means
+	// no limit.
+	RPCReturnDataLimit uint64 `json:"rpc-return-data-limit"`
 
 	// Cache settings
 	TrieCleanCache        int      `json:"trie-clean-cache"`         // Size of the trie clean cache (MB)
@@ -132,6 +148,59 @@ type Config struct {
 	TxPoolAccountQueue uint64   `json:"tx-pool-account-queue"`
 	TxPoolGlobalQueue  uint64   `json:"tx-pool-global-queue"`
 
+	// AtomicMempoolMaxSize is the maximum number of atomic txs kept in the
+	// mempool. Once full, the lowest priced txs are evicted.
+	AtomicMempoolMaxSize int `json:"atomic-mempool-max-size"`
+	// AtomicMempoolMaxTxsPerAddress is the maximum number of atomic txs kept
+	// in the mempool per DELTA address, which is the spending address of an
+	// export tx and the receiving address of an import tx. Once reached, a tx
+	// of the address replaces its lowest priced one only if it pays a higher
+	// gas price. (0 = no limit)
+	AtomicMempoolMaxTxsPerAddress int `json:"atomic-mempool-max-txs-per-address"`
+	// AtomicMempoolPersistenceEnabled persists the pending atomic txs on
+	// shutdown and re-admits the ones that are still valid on startup.
+	AtomicMempoolPersistenceEnabled bool `json:"atomic-mempool-persistence-enabled"`
+	// AtomicMempoolPersistenceMaxBytes is the maximum total size of the atomic
+	// txs persisted on shutdown. The highest priced txs are persisted first.
+	AtomicMempoolPersistenceMaxBytes uint64 `json:"atomic-mempool-persistence-max-bytes"`
+	// MemPoolMaxAge is the maximum time an atomic tx is kept pending in the
+	// mempool before it is discarded, such as when its inputs were consumed by
+	// a conflicting tx on the source chain. (0 = no limit)
+	MemPoolMaxAge Duration `json:"atomic-mempool-max-age"`
+
+	// AtomicAssetAllowlist restricts the assets that can be imported or
+	// exported to DIONE and the listed asset IDs. If empty, every asset
+	// permitted by the current rules is accepted. The allowlist only applies
+	// to txs issued to this node and to blocks built by it, so blocks of peers
+	// containing other assets are still accepted.
+	AtomicAssetAllowlist []ids.ID `json:"atomic-asset-allowlist"`
+
+	// AtomicTxMaxFee is the maximum fee, in nDIONE, that import and export txs
+	// created by this node may pay unless allowHighFee is set. (0 = no limit)
+	AtomicTxMaxFee uint64 `json:"atomic-tx-max-fee"`
+	// AtomicTxMaxFeeFraction is the maximum fee, as a fraction of the DIONE
+	// moved, that import and export txs created by this node may pay unless
+	// allowHighFee is set. (0 = no limit)
+	AtomicTxMaxFeeFraction float64 `json:"atomic-tx-max-fee-fraction"`
+
+	// AtomicSupplyCheckEnabled checks that the DIONE balance changes made by
+	// every processed import and export tx match the DIONE it moves, and logs
+	// any divergence.
+	AtomicSupplyCheckEnabled bool `json:"atomic-supply-check-enabled"`
+	// AtomicSupplyCheckFatal rejects blocks failing the atomic supply check
+	// instead of only logging the divergence.
+	AtomicSupplyCheckFatal bool `json:"atomic-supply-check-fatal"`
+
+	// BuildBlockDeadline is the soft deadline for packing txs into a block
+	// being built. Once it passes, the block is finalized with the txs packed
+	// so far. A deadline set on the BuildBlock context applies as well.
+	// (0 = no deadline)
+	BuildBlockDeadline Duration `json:"build-block-deadline"`
+
+	// BonusBlocks overrides the bonus blocks embedded for the network. Bonus
+	// blocks are accepted without applying their atomic txs.
+	BonusBlocks *BonusBlockSet `json:"bonus-blocks,omitempty"`
+
 	APIMaxDuration           Duration      `json:"api-max-duration"`
 	WSCPURefillRate          Duration      `json:"ws-cpu-refill-rate"`
 	WSCPUMaxStored           Duration      `json:"ws-cpu-max-stored"`
@@ -149,13 +218,31 @@ type Config struct {
 	RemoteGossipOnlyEnabled   bool     `json:"remote-gossip-only-enabled"`
 	RegossipFrequency         Duration `json:"regossip-frequency"`
 	RegossipMaxTxs            int      `json:"regossip-max-txs"`
+	DisableAtomicTxGossip     bool     `json:"disable-atomic-tx-gossip"`
 	RemoteTxGossipOnlyEnabled bool     `json:"remote-tx-gossip-only-enabled"` // Deprecated: use RemoteGossipOnlyEnabled instead
 	TxRegossipFrequency       Duration `json:"tx-regossip-frequency"`         // Deprecated: use RegossipFrequency instead
 	TxRegossipMaxSize         int      `json:"tx-regossip-max-size"`          // Deprecated: use RegossipMaxTxs instead
 
+	// AtomicTxGossipFraction is the fraction of the validators, sampled by
+	// stake, that each atomic tx is gossiped to, up to AtomicTxGossipMaxPeers.
+	AtomicTxGossipFraction float64 `json:"atomic-tx-gossip-fraction"`
+	AtomicTxGossipMaxPeers int     `json:"atomic-tx-gossip-max-peers"`
+	// AtomicTxRegossipFrequency is how often the pending atomic txs are
+	// gossiped again to a fresh sample of validators.
+	AtomicTxRegossipFrequency Duration `json:"atomic-tx-regossip-frequency"`
+	// ReissueAtomicTxsWithoutGossip re-issues the atomic txs of rejected
+	// blocks to the local mempool only, without ever gossiping them.
+	ReissueAtomicTxsWithoutGossip bool `json:"reissue-atomic-txs-without-gossip"`
+
 	// Log
 	LogLevel      string `json:"log-level"`
 	LogJSONFormat bool   `json:"log-json-format"`
+	// LogFile is a file the logs are also written to, if set. It is rotated
+	// once it reaches LogFileMaxSize megabytes, keeping at most
+	// LogFileMaxBackups of the rotated files, or all of them if 0.
+	LogFile           string `json:"log-file"`
+	LogFileMaxSize    int    `json:"log-file-max-size"`
+	LogFileMaxBackups int    `json:"log-file-max-backups"`
 
 	// Offline Pruning Settings
 	OfflinePruning                bool   `json:"offline-pruning-enabled"`
@@ -184,6 +271,14 @@ type Config struct {
 	// identical state with the pre-upgrade ruleset.
 	SkipUpgradeCheck bool `json:"skip-upgrade-check"`
 
+	// SkipUpgradeCheckHeight and SkipUpgradeCheckTimestamp allow changes to the
+	// upgrades scheduled at or after the given height and timestamp even if the
+	// last accepted block is past them, while changes to earlier upgrades are
+	// still rejected. This is useful when a network is resurrected from a
+	// snapshot of another network's database with a different upgrade schedule.
+	SkipUpgradeCheckHeight    *uint64 `json:"skip-upgrade-check-height"`
+	SkipUpgradeCheckTimestamp *uint64 `json:"skip-upgrade-check-timestamp"`
+
 	// AcceptedCacheSize is the depth to keep in the accepted headers cache and the
 	// accepted logs cache at the accepted tip.
 	//
@@ -196,6 +291,11 @@ type Config struct {
 	//  * 0:   means no limit
 	//  * N:   means N block limit [HEAD-N+1, HEAD] and delete extra indexes
 	TxLookupLimit uint64 `json:"tx-lookup-limit"`
+
+	// OpcodeStatsBlocks is the number of recently processed blocks for which
+	// per-opcode execution counts and gas are retained and served by
+	// debug_opcodeStats. Zero disables them.
+	OpcodeStatsBlocks int `json:"opcode-stats-blocks"`
 }
 
 // EthAPIs returns an array of strings representing the Eth APIs that should be enabled
@@ -211,6 +311,7 @@ func (c *Config) SetDefaults() {
 	c.EnabledEthAPIs = defaultEnabledAPIs
 	c.RPCGasCap = defaultRpcGasCap
 	c.RPCTxFeeCap = defaultRpcTxFeeCap
+	c.RPCReturnDataLimit = defaultRpcReturnDataLimit
 	c.MetricsExpensiveEnabled = defaultMetricsExpensiveEnabled
 
 	c.TxPoolJournal = txpool.DefaultConfig.Journal
@@ -221,6 +322,11 @@ func (c *Config) SetDefaults() {
 	c.TxPoolGlobalSlots = txpool.DefaultConfig.GlobalSlots
 	c.TxPoolAccountQueue = txpool.DefaultConfig.AccountQueue
 	c.TxPoolGlobalQueue = txpool.DefaultConfig.GlobalQueue
+	c.AtomicMempoolMaxSize = defaultMempoolSize
+	c.AtomicMempoolMaxTxsPerAddress = defaultAtomicMempoolMaxTxsPerAddress
+	c.AtomicMempoolPersistenceEnabled = defaultAtomicMempoolPersistenceEnabled
+	c.AtomicMempoolPersistenceMaxBytes = defaultAtomicMempoolPersistenceMaxBytes
+	c.AtomicTxMaxFee = defaultAtomicTxMaxFee
 
 	c.APIMaxDuration.Duration = defaultApiMaxDuration
 	c.WSCPURefillRate.Duration = defaultWsCpuRefillRate
@@ -237,10 +343,15 @@ func (c *Config) SetDefaults() {
 	c.SnapshotWait = defaultSnapshotWait
 	c.RegossipFrequency.Duration = defaultTxRegossipFrequency
 	c.RegossipMaxTxs = defaultTxRegossipMaxSize
+	c.AtomicTxGossipFraction = defaultAtomicTxGossipFraction
+	c.AtomicTxGossipMaxPeers = defaultAtomicTxGossipMaxPeers
+	c.AtomicTxRegossipFrequency.Duration = defaultAtomicTxRegossipFrequency
 	c.OfflinePruningBloomFilterSize = defaultOfflinePruningBloomFilterSize
 	c.LogLevel = defaultLogLevel
 	c.PopulateMissingTriesParallelism = defaultPopulateMissingTriesParallelism
 	c.LogJSONFormat = defaultLogJSONFormat
+	c.LogFileMaxSize = defaultLogFileMaxSize
+	c.LogFileMaxBackups = defaultLogFileMaxBackups
 	c.MaxOutboundActiveRequests = defaultMaxOutboundActiveRequests
 	c.MaxOutboundActiveCrossChainRequests = defaultMaxOutboundActiveCrossChainRequests
 	c.StateSyncServerTrieCache = defaultStateSyncServerTrieCache
@@ -288,6 +399,57 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("cannot use commit interval of 0 with pruning enabled")
 	}
 
+	if c.SkipUpgradeCheckHeight != nil && *c.SkipUpgradeCheckHeight == 0 {
+		return fmt.Errorf("skip-upgrade-check-height must be positive, use skip-upgrade-check to skip the check entirely")
+	}
+	if c.SkipUpgradeCheckTimestamp != nil && *c.SkipUpgradeCheckTimestamp == 0 {
+		return fmt.Errorf("skip-upgrade-check-timestamp must be positive, use skip-upgrade-check to skip the check entirely")
+	}
+
+	if c.OpcodeStatsBlocks < 0 {
+		return fmt.Errorf("opcode stats blocks cannot be negative (blocks: %d)", c.OpcodeStatsBlocks)
+	}
+
+	if c.LogFile != "" && c.LogFileMaxSize < 1 {
+		return fmt.Errorf("log file max size must be at least 1 MB (size: %d)", c.LogFileMaxSize)
+	}
+	if c.LogFileMaxBackups < 0 {
+		return fmt.Errorf("log file max backups cannot be negative (backups: %d)", c.LogFileMaxBackups)
+	}
+
+	if c.AtomicMempoolMaxSize < 1 {
+		return fmt.Errorf("atomic mempool max size must be at least 1 (size: %d)", c.AtomicMempoolMaxSize)
+	}
+	if c.AtomicMempoolMaxTxsPerAddress < 0 {
+		return fmt.Errorf("atomic mempool max txs per address cannot be negative (txs: %d)", c.AtomicMempoolMaxTxsPerAddress)
+	}
+	if c.AtomicMempoolPersistenceEnabled && c.AtomicMempoolPersistenceMaxBytes == 0 {
+		return fmt.Errorf("atomic mempool persistence max bytes must be positive when persistence is enabled")
+	}
+	if c.MemPoolMaxAge.Duration < 0 {
+		return fmt.Errorf("atomic mempool max age cannot be negative (age: %s)", c.MemPoolMaxAge)
+	}
+	if c.AtomicTxMaxFeeFraction < 0 {
+		return fmt.Errorf("atomic tx max fee fraction cannot be negative (fraction: %v)", c.AtomicTxMaxFeeFraction)
+	}
+	if c.AtomicTxGossipFraction <= 0 || c.AtomicTxGossipFraction > 1 {
+		return fmt.Errorf("atomic tx gossip fraction must be in (0, 1] (fraction: %v)", c.AtomicTxGossipFraction)
+	}
+	if c.AtomicTxGossipMaxPeers < 1 {
+		return fmt.Errorf("atomic tx gossip max peers must be at least 1 (peers: %d)", c.AtomicTxGossipMaxPeers)
+	}
+	if c.AtomicTxRegossipFrequency.Duration <= 0 {
+		return fmt.Errorf("atomic tx regossip frequency must be positive (frequency: %s)", c.AtomicTxRegossipFrequency)
+	}
+	if c.AtomicSupplyCheckFatal && !c.AtomicSupplyCheckEnabled {
+		return fmt.Errorf("cannot enable atomic-supply-check-fatal while atomic-supply-check-enabled is disabled")
+	}
+	if c.BonusBlocks != nil {
+		if err := c.BonusBlocks.Validate(); err != nil {
+			return fmt.Errorf("invalid bonus-blocks: %w", err)
+		}
+	}
+
 	return nil
 }
 
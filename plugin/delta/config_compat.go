@@ -0,0 +1,62 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/DioneProtocol/coreth/params"
+)
+
+// ConfigCompatibilityError describes a chain config that would alter the past
+// of the local chain, and how far the chain must be rewound to accept it. It is
+// returned by Initialize if the chain config is incompatible with the one stored
+// in the database, and can be marshalled to JSON.
+type ConfigCompatibilityError struct {
+	// What is the upgrade whose schedule was changed.
+	What string `json:"what"`
+	// StoredBlock and NewBlock are set if the upgrade is scheduled by height.
+	StoredBlock *big.Int `json:"storedBlock,omitempty"`
+	NewBlock    *big.Int `json:"newBlock,omitempty"`
+	// StoredTime and NewTime are set if the upgrade is scheduled by timestamp.
+	StoredTime *uint64 `json:"storedTime,omitempty"`
+	NewTime    *uint64 `json:"newTime,omitempty"`
+	// RewindToBlock and RewindToTime are the height and timestamp the local
+	// chain must be rewound to.
+	RewindToBlock uint64 `json:"rewindToBlock"`
+	RewindToTime  uint64 `json:"rewindToTime"`
+	// Suggestion is a human-readable instruction to resolve the mismatch.
+	Suggestion string `json:"suggestion"`
+}
+
+func newConfigCompatibilityError(err *params.ConfigCompatError) *ConfigCompatibilityError {
+	return &ConfigCompatibilityError{
+		What:          err.What,
+		StoredBlock:   err.StoredBlock,
+		NewBlock:      err.NewBlock,
+		StoredTime:    err.StoredTime,
+		NewTime:       err.NewTime,
+		RewindToBlock: err.RewindToBlock,
+		RewindToTime:  err.RewindToTime,
+		Suggestion:    err.Suggestion(),
+	}
+}
+
+func (e *ConfigCompatibilityError) Error() string {
+	return fmt.Sprintf("incompatible chain config: %s. %s", e.Unwrap(), e.Suggestion)
+}
+
+// Unwrap returns the *params.ConfigCompatError described by [e].
+func (e *ConfigCompatibilityError) Unwrap() error {
+	return &params.ConfigCompatError{
+		What:          e.What,
+		StoredBlock:   e.StoredBlock,
+		NewBlock:      e.NewBlock,
+		StoredTime:    e.StoredTime,
+		NewTime:       e.NewTime,
+		RewindToBlock: e.RewindToBlock,
+		RewindToTime:  e.RewindToTime,
+	}
+}
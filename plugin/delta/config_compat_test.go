@@ -0,0 +1,141 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/manager"
+	"github.com/DioneProtocol/odysseygo/ids"
+	engCommon "github.com/DioneProtocol/odysseygo/snow/engine/common"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/utils"
+)
+
+// genesisJSONCortinaAt returns [genesisJSONLatest] with Cortina scheduled at
+// [timestamp].
+func genesisJSONCortinaAt(timestamp string) string {
+	return strings.Replace(genesisJSONLatest, `"cortinaBlockTimestamp":0`, `"cortinaBlockTimestamp":`+timestamp, 1)
+}
+
+// newConfigCompatTestVM returns a VM with Cortina scheduled at timestamp 10,
+// that has accepted a block past it.
+func newConfigCompatTestVM(t *testing.T) (*VM, manager.Manager, *engCommon.SenderTest) {
+	require := require.New(t)
+
+	issuer, vm, dbManager, _, sender := GenesisVMWithUTXOs(t, true, genesisJSONCortinaAt("10"), "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+	})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, true)
+	require.NoError(err)
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+	return vm, dbManager, sender
+}
+
+func TestInitializeConfigCompatibilityError(t *testing.T) {
+	require := require.New(t)
+
+	vm, dbManager, sender := newConfigCompatTestVM(t)
+	require.NoError(vm.Shutdown(context.Background()))
+
+	// The metrics of [vm] remain registered with its context.
+	ctx := NewContext()
+	ctx.SharedMemory = vm.ctx.SharedMemory
+	ctx.FeeCollector = vm.ctx.FeeCollector
+	err := (&VM{}).Initialize(
+		context.Background(),
+		ctx,
+		dbManager,
+		BuildGenesisTest(t, genesisJSONCortinaAt("20")),
+		[]byte(""),
+		[]byte(""),
+		make(chan engCommon.Message, 1),
+		[]*engCommon.Fx{},
+		sender,
+	)
+
+	var compatErr *ConfigCompatibilityError
+	require.ErrorAs(err, &compatErr)
+	require.Equal(&ConfigCompatibilityError{
+		What:         "Cortina fork block timestamp",
+		StoredTime:   utils.NewUint64(10),
+		NewTime:      utils.NewUint64(20),
+		RewindToTime: 9,
+		Suggestion:   "Run 'coreth --rewind-to-time 9' to fix this configuration mismatch",
+	}, compatErr)
+	var paramsErr *params.ConfigCompatError
+	require.True(errors.As(err, &paramsErr))
+	require.Equal(uint64(9), paramsErr.RewindToTime)
+
+	b, err := json.Marshal(compatErr)
+	require.NoError(err)
+	require.JSONEq(`{
+		"what": "Cortina fork block timestamp",
+		"storedTime": 10,
+		"newTime": 20,
+		"rewindToBlock": 0,
+		"rewindToTime": 9,
+		"suggestion": "Run 'coreth --rewind-to-time 9' to fix this configuration mismatch"
+	}`, string(b))
+}
+
+func TestGetConfigCompatibility(t *testing.T) {
+	vm, _, _ := newConfigCompatTestVM(t)
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+	admin := NewAdminService(vm, "")
+
+	parseConfig := func(t *testing.T, genesisJSON string) *params.ChainConfig {
+		genesis := &core.Genesis{}
+		require.NoError(t, json.Unmarshal([]byte(genesisJSON), genesis))
+		return genesis.Config
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		reply := &GetConfigCompatibilityReply{}
+		require.NoError(t, admin.GetConfigCompatibility(nil, &GetConfigCompatibilityArgs{Config: parseConfig(t, genesisJSONCortinaAt("10"))}, reply))
+		require.True(t, reply.Compatible)
+		require.Nil(t, reply.Incompatibility)
+	})
+	t.Run("future upgrade", func(t *testing.T) {
+		config := parseConfig(t, genesisJSONCortinaAt("10"))
+		config.DUpgradeBlockTimestamp = utils.NewUint64(vm.blockChain.LastConsensusAcceptedBlock().Time() + 1000)
+		reply := &GetConfigCompatibilityReply{}
+		require.NoError(t, admin.GetConfigCompatibility(nil, &GetConfigCompatibilityArgs{Config: config}, reply))
+		require.True(t, reply.Compatible)
+	})
+	t.Run("rescheduled activated upgrade", func(t *testing.T) {
+		reply := &GetConfigCompatibilityReply{}
+		require.NoError(t, admin.GetConfigCompatibility(nil, &GetConfigCompatibilityArgs{Config: parseConfig(t, genesisJSONCortinaAt("20"))}, reply))
+		require.False(t, reply.Compatible)
+		require.NotNil(t, reply.Incompatibility)
+		require.Equal(t, "Cortina fork block timestamp", reply.Incompatibility.What)
+		require.Equal(t, uint64(9), reply.Incompatibility.RewindToTime)
+	})
+	t.Run("invalid config", func(t *testing.T) {
+		config := parseConfig(t, genesisJSONCortinaAt("10"))
+		config.BanffBlockTimestamp = nil
+		err := admin.GetConfigCompatibility(nil, &GetConfigCompatibilityArgs{Config: config}, &GetConfigCompatibilityReply{})
+		require.ErrorContains(t, err, "unsupported fork ordering")
+
+		err = admin.GetConfigCompatibility(nil, &GetConfigCompatibilityArgs{}, &GetConfigCompatibilityReply{})
+		require.ErrorIs(t, err, errMissingChainConfig)
+	})
+}
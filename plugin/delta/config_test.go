@@ -9,8 +9,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/coreth/utils"
 )
 
 // newTrue returns a pointer to a bool that is true
@@ -65,6 +68,54 @@ func TestUnmarshalConfig(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"atomic mempool max size",
+			[]byte(`{"atomic-mempool-max-size": 10}`),
+			Config{AtomicMempoolMaxSize: 10},
+			false,
+		},
+		{
+			"atomic tx fee limits",
+			[]byte(`{"atomic-tx-max-fee": 1000, "atomic-tx-max-fee-fraction": 0.5}`),
+			Config{AtomicTxMaxFee: 1000, AtomicTxMaxFeeFraction: 0.5},
+			false,
+		},
+		{
+			"atomic tx gossip",
+			[]byte(`{"atomic-tx-gossip-fraction": 0.25, "atomic-tx-gossip-max-peers": 8, "atomic-tx-regossip-frequency": "10s"}`),
+			Config{AtomicTxGossipFraction: 0.25, AtomicTxGossipMaxPeers: 8, AtomicTxRegossipFrequency: Duration{10 * time.Second}},
+			false,
+		},
+		{
+			"atomic mempool persistence",
+			[]byte(`{"atomic-mempool-persistence-enabled": true, "atomic-mempool-persistence-max-bytes": 1024}`),
+			Config{AtomicMempoolPersistenceEnabled: true, AtomicMempoolPersistenceMaxBytes: 1024},
+			false,
+		},
+		{
+			"atomic mempool max age",
+			[]byte(`{"atomic-mempool-max-age": "10m"}`),
+			Config{MemPoolMaxAge: Duration{10 * time.Minute}},
+			false,
+		},
+		{
+			"skip upgrade check height and timestamp",
+			[]byte(`{"skip-upgrade-check-height": 100, "skip-upgrade-check-timestamp": 1700000000}`),
+			Config{SkipUpgradeCheckHeight: utils.NewUint64(100), SkipUpgradeCheckTimestamp: utils.NewUint64(1700000000)},
+			false,
+		},
+		{
+			"atomic supply check",
+			[]byte(`{"atomic-supply-check-enabled": true, "atomic-supply-check-fatal": true}`),
+			Config{AtomicSupplyCheckEnabled: true, AtomicSupplyCheckFatal: true},
+			false,
+		},
+		{
+			"atomic asset allowlist",
+			[]byte(`{"atomic-asset-allowlist": ["SkB92YpWm4Q2ijQHH34cqbKkCZWszsiQgHVjtNeFF2HdvDQU"]}`),
+			Config{AtomicAssetAllowlist: []ids.ID{{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32}}},
+			false,
+		},
 
 		{
 			"state sync enabled",
@@ -0,0 +1,26 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"math/big"
+
+	"github.com/DioneProtocol/coreth/params"
+)
+
+// DIONEToWei converts an amount of nDIONE, the denomination used on the A and
+// O chains, to wei, the denomination used within the DELTA, at the rate every
+// fork to date uses. See params.NdioneToWei.
+func DIONEToWei(nDione uint64) *big.Int {
+	return params.NdioneToWei(params.Rules{}, nDione)
+}
+
+// WeiToDIONE converts an amount of wei to nDIONE at the rate every fork to date
+// uses, returning the whole nDIONE amount and the remaining wei. See
+// params.WeiToNdione. The remainder is dropped when DIONE is exported from the
+// D-Chain, so callers should surface it to users rather than silently
+// discarding it.
+func WeiToDIONE(wei *big.Int) (uint64, *big.Int, error) {
+	return params.WeiToNdione(params.Rules{}, wei)
+}
@@ -0,0 +1,75 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/params"
+)
+
+func TestDIONEToWei(t *testing.T) {
+	require := require.New(t)
+
+	require.Zero(DIONEToWei(0).Sign())
+	require.Equal(big.NewInt(x2cRateInt64), DIONEToWei(1))
+
+	expected := new(big.Int).Mul(new(big.Int).SetUint64(math.MaxUint64), big.NewInt(x2cRateInt64))
+	require.Equal(expected, DIONEToWei(math.MaxUint64))
+}
+
+func TestWeiToDIONE(t *testing.T) {
+	maxWei := DIONEToWei(math.MaxUint64)
+	tests := map[string]struct {
+		wei               *big.Int
+		expectedNDIONE    uint64
+		expectedRemainder *big.Int
+		expectedErr       error
+	}{
+		"zero": {
+			wei:               big.NewInt(0),
+			expectedRemainder: big.NewInt(0),
+		},
+		"sub nDIONE": {
+			wei:               big.NewInt(x2cRateMinus1Int64),
+			expectedRemainder: big.NewInt(x2cRateMinus1Int64),
+		},
+		"exact": {
+			wei:               big.NewInt(5 * x2cRateInt64),
+			expectedNDIONE:    5,
+			expectedRemainder: big.NewInt(0),
+		},
+		"with remainder": {
+			wei:               big.NewInt(5*x2cRateInt64 + 7),
+			expectedNDIONE:    5,
+			expectedRemainder: big.NewInt(7),
+		},
+		"max with remainder": {
+			wei:               new(big.Int).Add(maxWei, big.NewInt(x2cRateMinus1Int64)),
+			expectedNDIONE:    math.MaxUint64,
+			expectedRemainder: big.NewInt(x2cRateMinus1Int64),
+		},
+		"overflow": {
+			wei:         new(big.Int).Add(maxWei, big.NewInt(x2cRateInt64)),
+			expectedErr: params.ErrWeiOverflowsNdione,
+		},
+		"negative": {
+			wei:         big.NewInt(-1),
+			expectedErr: params.ErrNegativeWei,
+		},
+	}
+	for name, test := range tests {
+		nDione, remainder, err := WeiToDIONE(test.wei)
+		require.ErrorIs(t, err, test.expectedErr, name)
+		if test.expectedErr != nil {
+			continue
+		}
+		require.Equal(t, test.expectedNDIONE, nDione, name)
+		require.Zero(t, test.expectedRemainder.Cmp(remainder), name)
+	}
+}
@@ -0,0 +1,97 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !production
+
+package delta
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/DioneProtocol/coreth/consensus/dummy"
+	"github.com/DioneProtocol/coreth/constants"
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/trie"
+)
+
+// BuildEmptyBlock builds a block on top of [parent] that contains no txs,
+// at the current time of the VM clock or the time of [parent], whichever is
+// later. Unlike BuildBlock, the mempool and the tx pool are not consulted, so
+// it is intended for tests and benchmarks that need empty blocks.
+//
+// The returned block is exempt from the empty block check, but the exemption
+// does not survive parsing the block from its bytes. From ApricotPhase4, an
+// empty block cannot pay a non-zero block gas cost, so the VM clock must have
+// advanced at least [TargetBlockRate] past [parent] since the block gas cost
+// of the chain was last zero.
+func (vm *VM) BuildEmptyBlock(parent *Block) (*Block, error) {
+	var (
+		parentHeader = parent.ethBlock.Header()
+		number       = new(big.Int).Add(parentHeader.Number, common.Big1)
+		timestamp    = uint64(vm.clock.Unix())
+	)
+	if timestamp < parentHeader.Time {
+		timestamp = parentHeader.Time
+	}
+
+	gasLimit, _ := dummy.GasLimit(vm.chainConfig.ForkFlags(number, timestamp))
+	if gasLimit == 0 {
+		gasLimit = core.CalcGasLimit(parentHeader.GasUsed, parentHeader.GasLimit, params.ApricotPhase1GasLimit, params.ApricotPhase1GasLimit)
+	}
+	header := &types.Header{
+		ParentHash: parentHeader.Hash(),
+		Coinbase:   constants.BlackholeAddr,
+		Difficulty: big.NewInt(1),
+		Number:     number,
+		GasLimit:   gasLimit,
+		Time:       timestamp,
+	}
+	if vm.chainConfig.IsApricotPhase3(timestamp) {
+		var err error
+		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(vm.chainConfig, parentHeader, timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate new base fee: %w", err)
+		}
+	}
+	if vm.chainConfig.IsApricotPhase4(timestamp) {
+		header.ExtDataGasUsed = new(big.Int)
+		header.BlockGasCost = dummy.CalcBlockGasCost(vm.chainConfig, parentHeader, timestamp)
+	}
+
+	// Apply the state changes of an empty block, as they are applied when the
+	// block is processed, to compute its state root.
+	state, err := vm.blockChain.StateAt(parentHeader.Root)
+	if err != nil {
+		return nil, err
+	}
+	if err := vm.chainConfig.CheckConfigurePrecompiles(&parentHeader.Time, types.NewBlockWithHeader(header), state); err != nil {
+		return nil, fmt.Errorf("failed to configure precompiles: %w", err)
+	}
+	if rules := vm.chainConfig.OdysseyRules(number, timestamp); rules.IsOdyPhaseOrionSnapshot {
+		orions, err := vm.processOrionNodes(header, state, &rules)
+		if err != nil {
+			return nil, err
+		}
+		putOrionSnapshot(header, orions.snapshot)
+	}
+	if err := vm.blockChain.Engine().Finalize(vm.blockChain, types.NewBlockWithHeader(header), parentHeader, state, nil); err != nil {
+		return nil, fmt.Errorf("failed to finalize empty block: %w", err)
+	}
+	header.Root = state.IntermediateRoot(vm.chainConfig.IsEIP158(number))
+
+	ethBlock := types.NewBlock(
+		header, nil, nil, nil, trie.NewStackTrie(nil), nil,
+		vm.chainConfig.IsApricotPhase1(timestamp),
+	)
+	blk, err := vm.newBlock(ethBlock)
+	if err != nil {
+		return nil, err
+	}
+	blk.allowEmpty = true
+	return blk, nil
+}
@@ -0,0 +1,131 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !production
+
+package delta
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/snow"
+	engCommon "github.com/DioneProtocol/odysseygo/snow/engine/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/consensus/dummy"
+)
+
+// lastAcceptedBlock returns the last accepted block of the blockchain of
+// [vm]. Unlike LastAccepted, it includes blocks accepted without being wrapped
+// by the chain state, such as those built by BuildEmptyBlock.
+func lastAcceptedBlock(t testing.TB, vm *VM) *Block {
+	blk, err := vm.newBlock(vm.blockChain.LastConsensusAcceptedBlock())
+	require.NoError(t, err)
+	return blk
+}
+
+// buildEmptyBlocks builds, verifies and accepts [numBlocks] empty blocks on
+// top of the last accepted block of [vm], advancing the VM clock by the target
+// block rate before each block, and returns the last of them.
+func buildEmptyBlocks(t testing.TB, vm *VM, numBlocks int) *Block {
+	require := require.New(t)
+
+	parent := lastAcceptedBlock(t, vm)
+	for i := 0; i < numBlocks; i++ {
+		targetBlockRate := time.Duration(vm.chainConfig.GetTargetBlockRate(parent.ethBlock.Time())) * time.Second
+		vm.clock.Set(parent.Timestamp().Add(targetBlockRate))
+		blk, err := vm.BuildEmptyBlock(parent)
+		require.NoError(err)
+		require.NoError(blk.Verify(context.Background()))
+		require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+		require.NoError(blk.Accept(context.Background()))
+		parent = blk
+	}
+	return parent
+}
+
+func TestBuildEmptyBlock(t *testing.T) {
+	require := require.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	parent := lastAcceptedBlock(t, vm)
+	vm.clock.Set(parent.Timestamp().Add(time.Duration(vm.chainConfig.GetTargetBlockRate(parent.ethBlock.Time())) * time.Second))
+	blk, err := vm.BuildEmptyBlock(parent)
+	require.NoError(err)
+	require.Equal(parent.ID(), blk.Parent())
+	require.Equal(parent.Height()+1, blk.Height())
+	require.Empty(blk.ethBlock.Transactions())
+	require.Empty(blk.atomicTxs)
+
+	header, parentHeader := blk.ethBlock.Header(), parent.ethBlock.Header()
+	_, expectedBaseFee, err := dummy.CalcBaseFee(vm.chainConfig, parentHeader, header.Time)
+	require.NoError(err)
+	require.Equal(expectedBaseFee, header.BaseFee)
+	require.Zero(header.ExtDataGasUsed.Sign())
+	require.Equal(dummy.CalcBlockGasCost(vm.chainConfig, parentHeader, header.Time), header.BlockGasCost)
+
+	// The exemption from the empty block check does not survive parsing.
+	_, err = vm.ParseBlock(context.Background(), blk.Bytes())
+	require.ErrorIs(err, errEmptyBlock)
+
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+	require.Equal(blk.ethBlock.Hash(), vm.blockChain.LastConsensusAcceptedBlock().Hash())
+
+	// An empty block cannot pay the block gas cost of a block built before the
+	// target block rate has elapsed.
+	_, err = vm.BuildEmptyBlock(blk)
+	require.Error(err)
+}
+
+func TestBuildEmptyBlocks(t *testing.T) {
+	require := require.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	blk := buildEmptyBlocks(t, vm, 10)
+	require.Equal(uint64(10), blk.Height())
+	require.Equal(blk.ethBlock.Hash(), vm.blockChain.LastConsensusAcceptedBlock().Hash())
+}
+
+// BenchmarkBuildEmptyBlocks measures building, verifying and accepting 100
+// sequential empty blocks.
+func BenchmarkBuildEmptyBlocks(b *testing.B) {
+	require := require.New(b)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ctx, dbManager, genesisBytes, issuer, _ := setupGenesis(b, genesisJSONLatest)
+		vm := &VM{}
+		require.NoError(vm.Initialize(
+			context.Background(),
+			ctx,
+			dbManager,
+			genesisBytes,
+			[]byte(""),
+			[]byte(""),
+			issuer,
+			[]*engCommon.Fx{},
+			&engCommon.SenderTest{},
+		))
+		require.NoError(vm.SetState(context.Background(), snow.Bootstrapping))
+		require.NoError(vm.SetState(context.Background(), snow.NormalOp))
+		b.StartTimer()
+
+		buildEmptyBlocks(b, vm, 100)
+
+		b.StopTimer()
+		require.NoError(vm.Shutdown(context.Background()))
+		b.StartTimer()
+	}
+}
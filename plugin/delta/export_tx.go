@@ -33,6 +33,7 @@ var (
 	_                            secp256k1fx.UnsignedTx = &UnsignedExportTx{}
 	errExportNonDIONEInputBanff                         = errors.New("export input cannot contain non-DIONE in Banff")
 	errExportNonDIONEOutputBanff                        = errors.New("export output cannot contain non-DIONE in Banff")
+	errExportNotIncludable                              = errors.New("export cannot be included in the current phase")
 )
 
 // UnsignedExportTx is an unsigned ExportTx
@@ -81,6 +82,34 @@ func (utx *UnsignedExportTx) Verify(
 		return errWrongBlockchainID
 	}
 
+	for _, in := range utx.Ins {
+		if err := in.Verify(); err != nil {
+			return err
+		}
+	}
+	for _, out := range utx.ExportedOutputs {
+		if err := out.Verify(); err != nil {
+			return err
+		}
+	}
+	if !dione.IsSortedTransferableOutputs(utx.ExportedOutputs, Codec) {
+		return errOutputsNotSorted
+	}
+	return utx.verifyPhase(ctx, rules)
+}
+
+// CanBeIncludedInPhase returns true if the transaction satisfies the
+// restrictions of the network upgrades active under [rules], such as the
+// destination chains allowed before and after Apricot Phase 5 and the DIONE
+// only exports of Banff. A transaction that does not can never pass Verify
+// under [rules], regardless of its inputs and outputs being well-formed.
+func (utx *UnsignedExportTx) CanBeIncludedInPhase(ctx *snow.Context, rules params.Rules) bool {
+	return utx.verifyPhase(ctx, rules) == nil
+}
+
+// verifyPhase verifies the parts of the transaction whose validity depends on
+// [rules].
+func (utx *UnsignedExportTx) verifyPhase(ctx *snow.Context, rules params.Rules) error {
 	// Make sure that the tx has a valid peer chain ID
 	if rules.IsApricotPhase5 {
 		// Note that SameSubnet verifies that [tx.DestinationChain] isn't this
@@ -95,18 +124,11 @@ func (utx *UnsignedExportTx) Verify(
 	}
 
 	for _, in := range utx.Ins {
-		if err := in.Verify(); err != nil {
-			return err
-		}
 		if rules.IsBanff && in.AssetID != ctx.DIONEAssetID {
 			return errExportNonDIONEInputBanff
 		}
 	}
-
 	for _, out := range utx.ExportedOutputs {
-		if err := out.Verify(); err != nil {
-			return err
-		}
 		assetID := out.AssetID()
 		if assetID != ctx.DIONEAssetID && utx.DestinationChain == constants.OmegaChainID {
 			return errWrongChainID
@@ -115,13 +137,9 @@ func (utx *UnsignedExportTx) Verify(
 			return errExportNonDIONEOutputBanff
 		}
 	}
-	if !dione.IsSortedTransferableOutputs(utx.ExportedOutputs, Codec) {
-		return errOutputsNotSorted
-	}
 	if rules.IsApricotPhase1 && !utils.IsSortedAndUnique(utx.Ins) {
 		return errInputsNotSortedUnique
 	}
-
 	return nil
 }
 
@@ -146,6 +164,21 @@ func (utx *UnsignedExportTx) GasUsed(fixedFee bool) (uint64, error) {
 	return cost, nil
 }
 
+// MaxFee returns the fee, in nDIONE, that the tx would pay if it were issued
+// while the base fee is [maxBaseFee]. The gas used includes the fixed fee, so
+// the result is an upper bound on the fee paid at any base fee up to
+// [maxBaseFee], regardless of the rules in effect.
+func (utx *UnsignedExportTx) MaxFee(maxBaseFee *big.Int) (uint64, error) {
+	if maxBaseFee != nil && maxBaseFee.Sign() < 0 {
+		return 0, errMaxBaseFeeNegative
+	}
+	gasUsed, err := utx.GasUsed(true)
+	if err != nil {
+		return 0, err
+	}
+	return CalculateDynamicFee(gasUsed, maxBaseFee)
+}
+
 // Amount of [assetID] burned by this transaction
 func (utx *UnsignedExportTx) Burned(assetID ids.ID) (uint64, error) {
 	var (
@@ -284,7 +317,41 @@ func (vm *VM) newExportTx(
 	to ids.ShortID, // Address of chain recipient
 	baseFee *big.Int, // fee to use post-AP3
 	keys []*secp256k1.PrivateKey, // Pay the fee and provide the tokens
+	allowHighFee bool, // Skip the atomic tx fee limits
 ) (*Tx, error) {
+	// Fail before selecting any funds if no export of [assetID] to [chainID]
+	// could be accepted.
+	rules := vm.currentRules()
+	probe := &UnsignedExportTx{
+		DestinationChain: chainID,
+		ExportedOutputs:  []*dione.TransferableOutput{{Asset: dione.Asset{ID: assetID}}},
+	}
+	if !probe.CanBeIncludedInPhase(vm.ctx, rules) {
+		return nil, fmt.Errorf("%w: asset %s to chain %s", errExportNotIncludable, assetID, chainID)
+	}
+
+	utx, err := vm.newUnsignedExportTx(assetID, amount, chainID, to, keysToEthAddresses(keys), baseFee, allowHighFee)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{UnsignedAtomicTx: utx}
+	if err := tx.Sign(vm.codec, inputSigners(utx.Ins, keys)); err != nil {
+		return nil, err
+	}
+	return tx, utx.Verify(vm.ctx, rules)
+}
+
+// newUnsignedExportTx returns a new ExportTx, without credentials, that
+// spends the funds of [from] to export [amount] of [assetID] and pay the fee.
+func (vm *VM) newUnsignedExportTx(
+	assetID ids.ID, // AssetID of the tokens to export
+	amount uint64, // Amount of tokens to export
+	chainID ids.ID, // Chain to send the UTXOs to
+	to ids.ShortID, // Address of chain recipient
+	from []common.Address, // Pay the fee and provide the tokens
+	baseFee *big.Int, // fee to use post-AP3
+	allowHighFee bool, // Skip the atomic tx fee limits
+) (*UnsignedExportTx, error) {
 	outs := []*dione.TransferableOutput{{
 		Asset: dione.Asset{ID: assetID},
 		Out: &secp256k1fx.TransferOutput{
@@ -298,15 +365,14 @@ func (vm *VM) newExportTx(
 	}}
 
 	var (
-		dioneNeeded           uint64 = 0
-		ins, dioneIns         []DELTAInput
-		signers, dioneSigners [][]*secp256k1.PrivateKey
-		err                   error
+		dioneNeeded   uint64 = 0
+		ins, dioneIns []DELTAInput
+		err           error
 	)
 
 	// consume non-DIONE
 	if assetID != vm.ctx.DIONEAssetID {
-		ins, signers, err = vm.GetSpendableFunds(keys, assetID, amount)
+		ins, err = vm.getSpendableFunds(from, assetID, amount)
 		if err != nil {
 			return nil, fmt.Errorf("couldn't generate tx inputs/signers: %w", err)
 		}
@@ -335,23 +401,22 @@ func (vm *VM) newExportTx(
 			return nil, err
 		}
 
-		dioneIns, dioneSigners, err = vm.GetSpendableDIONEWithFee(keys, dioneNeeded, cost, baseFee)
+		dioneIns, err = vm.getSpendableDIONEWithFee(from, dioneNeeded, cost, baseFee)
 	default:
 		var newDioneNeeded uint64
 		newDioneNeeded, err = math.Add64(dioneNeeded, params.OdysseyAtomicTxFee)
 		if err != nil {
 			return nil, errOverflowExport
 		}
-		dioneIns, dioneSigners, err = vm.GetSpendableFunds(keys, vm.ctx.DIONEAssetID, newDioneNeeded)
+		dioneIns, err = vm.getSpendableFunds(from, vm.ctx.DIONEAssetID, newDioneNeeded)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("couldn't generate tx inputs/signers: %w", err)
 	}
 	ins = append(ins, dioneIns...)
-	signers = append(signers, dioneSigners...)
 
 	dione.SortTransferableOutputs(outs, vm.codec)
-	SortDELTAInputsAndSigners(ins, signers)
+	utils.Sort(ins)
 
 	// Create the transaction
 	utx := &UnsignedExportTx{
@@ -361,23 +426,135 @@ func (vm *VM) newExportTx(
 		Ins:              ins,
 		ExportedOutputs:  outs,
 	}
+	if !allowHighFee {
+		fee, err := utx.Burned(vm.ctx.DIONEAssetID)
+		if err != nil {
+			return nil, err
+		}
+		var dioneAmount uint64
+		if assetID == vm.ctx.DIONEAssetID {
+			dioneAmount = amount
+		}
+		if err := vm.verifyAtomicTxFee(fee, dioneAmount); err != nil {
+			return nil, err
+		}
+	}
+	return utx, nil
+}
+
+// ExportWouldLeaveDust reports whether exporting [amount] of [assetID] from
+// [addr] at [baseFee] would leave [addr] with a DIONE balance that is too small
+// to pay for any further export, and returns that balance in nDIONE. Since the
+// export fees are paid in DIONE, this applies to exports of any asset. An empty
+// balance is not dust, and the sub-nDIONE part of the balance, which can never
+// be exported, is not counted.
+func (vm *VM) ExportWouldLeaveDust(assetID ids.ID, amount uint64, baseFee *big.Int, addr common.Address) (bool, uint64, error) {
+	utx, err := vm.newUnsignedExportTx(assetID, amount, vm.ctx.AChainID, ids.ShortEmpty, []common.Address{addr}, baseFee, true)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Note: current state uses the state of the preferred block.
+	state, err := vm.blockChain.State()
+	if err != nil {
+		return false, 0, err
+	}
+	remaining := new(big.Int).Div(state.GetBalance(addr), x2cRate).Uint64()
+	for _, in := range utx.Ins {
+		if in.AssetID == vm.ctx.DIONEAssetID {
+			remaining -= in.Amount
+		}
+	}
+
+	minExportable, err := vm.minExportableDIONE(baseFee)
+	if err != nil {
+		return false, 0, err
+	}
+	if remaining == 0 || remaining >= minExportable {
+		return false, 0, nil
+	}
+	return true, remaining, nil
+}
+
+// minExportableDIONE returns the smallest DIONE balance, in nDIONE, that is
+// needed to export 1 nDIONE at [baseFee].
+func (vm *VM) minExportableDIONE(baseFee *big.Int) (uint64, error) {
+	return vm.MinBalanceForExport(vm.ctx.DIONEAssetID, 1, baseFee)
+}
+
+// MinBalanceForExport returns the smallest DIONE balance, in nDIONE, that an
+// account needs to export [amount] of [assetID] at [baseFee]. This is the fee
+// of an export that spends a single DIONE input of the account, plus [amount]
+// if [assetID] is DIONE. If [assetID] is not DIONE, the fee also pays for the
+// input spending [amount] of [assetID], which the account must hold in
+// addition to the returned balance.
+func (vm *VM) MinBalanceForExport(assetID ids.ID, amount uint64, baseFee *big.Int) (uint64, error) {
+	rules := vm.currentRules()
+	utx := &UnsignedExportTx{
+		NetworkID:        vm.ctx.NetworkID,
+		BlockchainID:     vm.ctx.ChainID,
+		DestinationChain: vm.ctx.AChainID,
+		ExportedOutputs: []*dione.TransferableOutput{{
+			Asset: dione.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{ids.ShortEmpty},
+				},
+			},
+		}},
+	}
+	if !utx.CanBeIncludedInPhase(vm.ctx, rules) {
+		return 0, fmt.Errorf("%w: asset %s", errExportNotIncludable, assetID)
+	}
+
+	var dioneNeeded uint64
+	if assetID == vm.ctx.DIONEAssetID {
+		dioneNeeded = amount
+	} else {
+		utx.Ins = append(utx.Ins, DELTAInput{Amount: amount, AssetID: assetID})
+	}
+	if !rules.IsApricotPhase3 {
+		return math.Add64(dioneNeeded, params.OdysseyAtomicTxFee)
+	}
+
+	// The size of an input does not depend on its address, amount or nonce.
+	utx.Ins = append(utx.Ins, DELTAInput{Amount: 1, AssetID: vm.ctx.DIONEAssetID})
 	tx := &Tx{UnsignedAtomicTx: utx}
-	if err := tx.Sign(vm.codec, signers); err != nil {
-		return nil, err
+	if err := tx.Sign(vm.codec, nil); err != nil {
+		return 0, err
+	}
+	cost, err := tx.GasUsed(rules.IsApricotPhase5)
+	if err != nil {
+		return 0, err
+	}
+	fee, err := CalculateDynamicFee(cost, baseFee)
+	if err != nil {
+		return 0, err
 	}
-	return tx, utx.Verify(vm.ctx, vm.currentRules())
+	return math.Add64(dioneNeeded, fee)
+}
+
+// DELTAStateTransfer executes the state update from the atomic export transaction.
+// If any input cannot be spent, none of the inputs are.
+func (utx *UnsignedExportTx) DELTAStateTransfer(ctx *snow.Context, state *state.StateDB, rules params.Rules) error {
+	return applyStateTransfer(state, func() error {
+		return utx.spendInputs(ctx, state, rules)
+	})
 }
 
-// DELTAStateTransfer executes the state update from the atomic export transaction
-func (utx *UnsignedExportTx) DELTAStateTransfer(ctx *snow.Context, state *state.StateDB) error {
+// spendInputs deducts the exported inputs from the balances of their addresses
+// and increments their nonces. It may return an error after some inputs have
+// been deducted.
+func (utx *UnsignedExportTx) spendInputs(ctx *snow.Context, state *state.StateDB, rules params.Rules) error {
 	addrs := map[[20]byte]uint64{}
 	for _, from := range utx.Ins {
 		if from.AssetID == ctx.DIONEAssetID {
 			log.Debug("crosschain", "dest", utx.DestinationChain, "addr", from.Address, "amount", from.Amount, "assetID", "DIONE")
-			// We multiply the input amount by x2cRate to convert DIONE back to the appropriate
+			// We multiply the input amount by the x2c rate of [rules] to convert DIONE back to the appropriate
 			// denomination before export.
-			amount := new(big.Int).Mul(
-				new(big.Int).SetUint64(from.Amount), x2cRate)
+			amount := params.NdioneToWei(rules, from.Amount)
 			if state.GetBalance(from.Address).Cmp(amount) < 0 {
 				return errInsufficientFunds
 			}
@@ -390,7 +567,9 @@ func (utx *UnsignedExportTx) DELTAStateTransfer(ctx *snow.Context, state *state.
 			}
 			state.SubBalanceMultiCoin(from.Address, common.Hash(from.AssetID), amount)
 		}
-		if state.GetNonce(from.Address) != from.Nonce {
+		if nonce := state.GetNonce(from.Address); nonce < from.Nonce {
+			return fmt.Errorf("%w: address %s current nonce (%d) < tx nonce (%d)", errNonceTooHigh, from.Address.Hex(), nonce, from.Nonce)
+		} else if nonce != from.Nonce {
 			return errInvalidNonce
 		}
 		addrs[from.Address] = from.Nonce
@@ -8,17 +8,29 @@ import (
 	"context"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/DioneProtocol/coreth/core/rawdb"
+	"github.com/DioneProtocol/coreth/core/state"
+	"github.com/DioneProtocol/coreth/core/txpool"
+	"github.com/DioneProtocol/coreth/core/types"
 	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/odysseygo/api"
 	"github.com/DioneProtocol/odysseygo/chains/atomic"
 	"github.com/DioneProtocol/odysseygo/ids"
 	engCommon "github.com/DioneProtocol/odysseygo/snow/engine/common"
 	"github.com/DioneProtocol/odysseygo/utils/constants"
 	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/DioneProtocol/odysseygo/utils/formatting"
+	"github.com/DioneProtocol/odysseygo/utils/hashing"
+	odysseyJSON "github.com/DioneProtocol/odysseygo/utils/json"
 	"github.com/DioneProtocol/odysseygo/utils/units"
+	"github.com/DioneProtocol/odysseygo/vms/components/chain"
 	"github.com/DioneProtocol/odysseygo/vms/components/dione"
 	"github.com/DioneProtocol/odysseygo/vms/secp256k1fx"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
 )
 
 // createExportTxOptions adds funds to shared memory, imports them, and returns a list of export transactions
@@ -54,7 +66,7 @@ func createExportTxOptions(t *testing.T, vm *VM, issuer chan engCommon.Message,
 	}
 
 	// Import the funds
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -85,7 +97,7 @@ func createExportTxOptions(t *testing.T, vm *VM, issuer chan engCommon.Message,
 	// Use the funds to create 3 conflicting export transactions sending the funds to each of the test addresses
 	exportTxs := make([]*Tx, 0, 3)
 	for _, addr := range testShortIDAddrs {
-		exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, uint64(5000000), vm.ctx.AChainID, addr, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+		exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, uint64(5000000), vm.ctx.AChainID, addr, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -366,7 +378,7 @@ func TestExportTxDELTAStateTransfer(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+			tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -403,7 +415,7 @@ func TestExportTxDELTAStateTransfer(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			err = newTx.DELTAStateTransfer(vm.ctx, stateDB)
+			err = newTx.DELTAStateTransfer(vm.ctx, stateDB, vm.currentRules())
 			if test.shouldErr {
 				if err == nil {
 					t.Fatal("expected DELTAStateTransfer to fail")
@@ -1363,6 +1375,97 @@ func TestExportTxVerify(t *testing.T) {
 	}
 }
 
+func TestExportTxCanBeIncludedInPhase(t *testing.T) {
+	ctx := NewContext()
+	nonDIONEAssetID := ids.GenerateTestID()
+	newExportTx := func(destinationChain ids.ID, assetID ids.ID, ins ...DELTAInput) *UnsignedExportTx {
+		return &UnsignedExportTx{
+			NetworkID:        testNetworkID,
+			BlockchainID:     testDChainID,
+			DestinationChain: destinationChain,
+			Ins:              ins,
+			ExportedOutputs: []*dione.TransferableOutput{{
+				Asset: dione.Asset{ID: assetID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: 1,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{testShortIDAddrs[0]},
+					},
+				},
+			}},
+		}
+	}
+	dioneIn := DELTAInput{Address: testEthAddrs[0], Amount: 1, AssetID: testDioneAssetID}
+	nonDIONEIn := DELTAInput{Address: testEthAddrs[0], Amount: 1, AssetID: nonDIONEAssetID}
+
+	tests := []struct {
+		name  string
+		tx    *UnsignedExportTx
+		rules params.Rules
+		want  bool
+	}{
+		{"DIONE to A-Chain apricot phase 0", newExportTx(testAChainID, testDioneAssetID, dioneIn), apricotRulesPhase0, true},
+		{"DIONE to A-Chain banff", newExportTx(testAChainID, testDioneAssetID, dioneIn), banffRules, true},
+		{"DIONE to O-Chain apricot phase 4", newExportTx(constants.OmegaChainID, testDioneAssetID, dioneIn), apricotRulesPhase4, false},
+		{"DIONE to O-Chain apricot phase 5", newExportTx(constants.OmegaChainID, testDioneAssetID, dioneIn), apricotRulesPhase5, true},
+		{"to own chain apricot phase 5", newExportTx(testDChainID, testDioneAssetID, dioneIn), apricotRulesPhase5, false},
+		{"non-DIONE to O-Chain apricot phase 5", newExportTx(constants.OmegaChainID, nonDIONEAssetID, nonDIONEIn), apricotRulesPhase5, false},
+		{"non-DIONE output apricot phase 6", newExportTx(testAChainID, nonDIONEAssetID, dioneIn), apricotRulesPhase6, true},
+		{"non-DIONE output banff", newExportTx(testAChainID, nonDIONEAssetID, dioneIn), banffRules, false},
+		{"non-DIONE input apricot phase 6", newExportTx(testAChainID, testDioneAssetID, nonDIONEIn), apricotRulesPhase6, true},
+		{"non-DIONE input banff", newExportTx(testAChainID, testDioneAssetID, nonDIONEIn), banffRules, false},
+		{"duplicate inputs apricot phase 0", newExportTx(testAChainID, testDioneAssetID, dioneIn, dioneIn), apricotRulesPhase0, true},
+		{"duplicate inputs apricot phase 1", newExportTx(testAChainID, testDioneAssetID, dioneIn, dioneIn), apricotRulesPhase1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			require.Equal(test.want, test.tx.CanBeIncludedInPhase(ctx, test.rules))
+			// A transaction that cannot be included never passes verification.
+			if err := test.tx.Verify(ctx, test.rules); test.want {
+				require.NoError(err)
+			} else {
+				require.Error(err)
+			}
+		})
+	}
+}
+
+func TestNewExportTxNotIncludable(t *testing.T) {
+	tests := []struct {
+		name    string
+		genesis string
+		assetID func(vm *VM) ids.ID
+		chainID ids.ID
+	}{
+		{
+			name:    "DIONE to O-Chain apricot phase 4",
+			genesis: genesisJSONApricotPhase4,
+			assetID: func(vm *VM) ids.ID { return vm.ctx.DIONEAssetID },
+			chainID: constants.OmegaChainID,
+		},
+		{
+			name:    "non-DIONE banff",
+			genesis: genesisJSONBanff,
+			assetID: func(*VM) ids.ID { return ids.GenerateTestID() },
+			chainID: testAChainID,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			_, vm, _, _, _ := GenesisVM(t, true, test.genesis, "", "")
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+
+			_, err := vm.newExportTx(test.assetID(vm), units.Dione, test.chainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+			require.ErrorIs(err, errExportNotIncludable)
+		})
+	}
+}
+
 // Note: this is a brittle test to ensure that the gas cost of a transaction does
 // not change
 func TestExportTxGasCost(t *testing.T) {
@@ -1628,6 +1731,69 @@ func TestExportTxGasCost(t *testing.T) {
 	}
 }
 
+func TestExportTxMaxFee(t *testing.T) {
+	require := require.New(t)
+
+	dioneAssetID := ids.GenerateTestID()
+	exportAmount := uint64(5000000)
+	utx := &UnsignedExportTx{
+		NetworkID:        uint32(5),
+		BlockchainID:     ids.GenerateTestID(),
+		DestinationChain: ids.GenerateTestID(),
+		Ins: []DELTAInput{
+			{
+				Address: testEthAddrs[0],
+				Amount:  exportAmount,
+				AssetID: dioneAssetID,
+			},
+			{
+				Address: testEthAddrs[1],
+				Amount:  exportAmount,
+				AssetID: dioneAssetID,
+			},
+		},
+		ExportedOutputs: []*dione.TransferableOutput{
+			{
+				Asset: dione.Asset{ID: dioneAssetID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: 2 * exportAmount,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{testShortIDAddrs[0]},
+					},
+				},
+			},
+		},
+	}
+	tx := &Tx{UnsignedAtomicTx: utx}
+	require.NoError(tx.Sign(Codec, [][]*secp256k1.PrivateKey{{testKeys[0]}, {testKeys[1]}}))
+
+	maxFee, err := utx.MaxFee(big.NewInt(params.ApricotPhase3MaxBaseFee))
+	require.NoError(err)
+
+	// The max fee bounds the fee paid at the initial base fee, with or
+	// without the fixed fee.
+	for _, fixedFee := range []bool{false, true} {
+		gasUsed, err := utx.GasUsed(fixedFee)
+		require.NoError(err)
+		actualFee, err := CalculateDynamicFee(gasUsed, big.NewInt(params.ApricotPhase3InitialBaseFee))
+		require.NoError(err)
+		require.GreaterOrEqual(maxFee, actualFee)
+	}
+
+	// The max fee applies the same arithmetic as the dynamic fee.
+	gasUsed, err := utx.GasUsed(true)
+	require.NoError(err)
+	expectedFee, err := CalculateDynamicFee(gasUsed, big.NewInt(params.ApricotPhase3MaxBaseFee))
+	require.NoError(err)
+	require.Equal(expectedFee, maxFee)
+
+	_, err = utx.MaxFee(big.NewInt(-1))
+	require.ErrorIs(err, errMaxBaseFeeNegative)
+	_, err = utx.MaxFee(nil)
+	require.ErrorIs(err, errNilBaseFee)
+}
+
 func TestNewExportTx(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -1721,7 +1887,7 @@ func TestNewExportTx(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+			tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1752,7 +1918,7 @@ func TestNewExportTx(t *testing.T) {
 			parent = vm.LastAcceptedBlockInternal().(*Block)
 			exportAmount := uint64(5000000)
 
-			tx, err = vm.newExportTx(vm.ctx.DIONEAssetID, exportAmount, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+			tx, err = vm.newExportTx(vm.ctx.DIONEAssetID, exportAmount, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1789,7 +1955,7 @@ func TestNewExportTx(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = exportTx.DELTAStateTransfer(vm.ctx, sdb)
+			err = exportTx.DELTAStateTransfer(vm.ctx, sdb, vm.currentRules())
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1911,7 +2077,7 @@ func TestNewExportTxMulticoin(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+			tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1948,7 +2114,7 @@ func TestNewExportTxMulticoin(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			tx, err = vm.newExportTx(tid, exportAmount, vm.ctx.AChainID, exportId, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+			tx, err = vm.newExportTx(tid, exportAmount, vm.ctx.AChainID, exportId, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1977,7 +2143,7 @@ func TestNewExportTxMulticoin(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = exportTx.DELTAStateTransfer(vm.ctx, stdb)
+			err = exportTx.DELTAStateTransfer(vm.ctx, stdb, vm.currentRules())
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1992,3 +2158,521 @@ func TestNewExportTxMulticoin(t *testing.T) {
 		})
 	}
 }
+
+func TestExportWouldLeaveDust(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// The fee of a single input DIONE export does not depend on the amount.
+	utx, err := vm.newUnsignedExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], []common.Address{testEthAddrs[0]}, initialBaseFee, true)
+	require.NoError(err)
+	fee, err := utx.Burned(vm.ctx.DIONEAssetID)
+	require.NoError(err)
+	balance := uint64(units.MegaDione)
+
+	tests := []struct {
+		name         string
+		amount       uint64
+		expectedDust uint64
+	}{
+		{
+			name:   "large remainder",
+			amount: units.Dione,
+		},
+		{
+			name:   "export max",
+			amount: balance - fee,
+		},
+		{
+			name:         "single nDIONE left",
+			amount:       balance - fee - 1,
+			expectedDust: 1,
+		},
+		{
+			name:         "remainder covers only the fee",
+			amount:       balance - 2*fee,
+			expectedDust: fee,
+		},
+		{
+			name:   "remainder covers the fee and 1 nDIONE",
+			amount: balance - 2*fee - 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dust, amount, err := vm.ExportWouldLeaveDust(vm.ctx.DIONEAssetID, test.amount, initialBaseFee, testEthAddrs[0])
+			require.NoError(err)
+			require.Equal(test.expectedDust != 0, dust)
+			require.Equal(test.expectedDust, amount)
+		})
+	}
+
+	_, _, err = vm.ExportWouldLeaveDust(vm.ctx.DIONEAssetID, balance, initialBaseFee, testEthAddrs[0])
+	require.ErrorIs(err, errInsufficientFunds)
+}
+
+func TestMinBalanceForExport(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	minBalance, err := vm.MinBalanceForExport(vm.ctx.DIONEAssetID, units.Dione, initialBaseFee)
+	require.NoError(err)
+	utx, err := vm.newUnsignedExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], []common.Address{testEthAddrs[0]}, initialBaseFee, true)
+	require.NoError(err)
+	fee, err := utx.Burned(vm.ctx.DIONEAssetID)
+	require.NoError(err)
+	require.Equal(units.Dione+fee, minBalance)
+
+	// An account can export exactly as much as its balance allows.
+	balance := uint64(units.MegaDione)
+	maxMinBalance, err := vm.MinBalanceForExport(vm.ctx.DIONEAssetID, balance-fee, initialBaseFee)
+	require.NoError(err)
+	require.Equal(balance, maxMinBalance)
+	_, err = vm.newUnsignedExportTx(vm.ctx.DIONEAssetID, balance-fee, vm.ctx.AChainID, testShortIDAddrs[0], []common.Address{testEthAddrs[0]}, initialBaseFee, true)
+	require.NoError(err)
+	_, err = vm.newUnsignedExportTx(vm.ctx.DIONEAssetID, balance-fee+1, vm.ctx.AChainID, testShortIDAddrs[0], []common.Address{testEthAddrs[0]}, initialBaseFee, true)
+	require.ErrorIs(err, errInsufficientFunds)
+
+	// Non-DIONE assets cannot be exported after Banff.
+	_, err = vm.MinBalanceForExport(ids.GenerateTestID(), units.Dione, initialBaseFee)
+	require.ErrorIs(err, errExportNotIncludable)
+}
+
+func TestMinBalanceForExportPhases(t *testing.T) {
+	tests := []struct {
+		name    string
+		genesis string
+		// multicoin is whether the minimum balance to export a non-DIONE asset
+		// is checked as well
+		multicoin bool
+	}{
+		{
+			name:      "apricot phase 2",
+			genesis:   genesisJSONApricotPhase2,
+			multicoin: true,
+		},
+		{
+			name:      "apricot phase 5",
+			genesis:   genesisJSONApricotPhase5,
+			multicoin: true,
+		},
+		{
+			name:    "banff",
+			genesis: genesisJSONBanff,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			_, vm, _, _, _ := GenesisVM(t, true, test.genesis, "", "")
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+			rules := vm.currentRules()
+
+			dioneMinBalance, err := vm.MinBalanceForExport(vm.ctx.DIONEAssetID, units.Dione, initialBaseFee)
+			require.NoError(err)
+			if !rules.IsApricotPhase3 {
+				require.Equal(units.Dione+params.OdysseyAtomicTxFee, dioneMinBalance)
+			} else {
+				require.Greater(dioneMinBalance, uint64(units.Dione))
+			}
+			if !test.multicoin {
+				return
+			}
+
+			// The DIONE needed to export another asset only pays the fee, which
+			// includes the input of the asset after Apricot Phase 3.
+			minBalance, err := vm.MinBalanceForExport(ids.GenerateTestID(), units.Dione, initialBaseFee)
+			require.NoError(err)
+			dioneFee := dioneMinBalance - units.Dione
+			if !rules.IsApricotPhase3 {
+				require.Equal(dioneFee, minBalance)
+			} else {
+				require.Greater(minBalance, dioneFee)
+			}
+		})
+	}
+}
+
+func TestExportTxNonceAfterPendingEthTx(t *testing.T) {
+	require := require.New(t)
+	issuer, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	ethTx := newTestEthTx(t, vm, 0)
+	errs := vm.txPool.AddRemotesSync([]*types.Transaction{ethTx})
+	require.Len(errs, 1)
+	require.NoError(errs[0])
+	<-issuer
+
+	// The export must be built on top of the pending EVM transaction.
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	ins := exportTx.UnsignedAtomicTx.(*UnsignedExportTx).Ins
+	require.Len(ins, 1)
+	require.Equal(uint64(1), ins[0].Nonce)
+
+	require.NoError(vm.issueTx(exportTx, true /*=local*/))
+	require.True(vm.txPool.Has(ethTx.Hash()))
+	require.Equal(uint64(2), vm.GetPendingNonce(testEthAddrs[0]))
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	ethBlk := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(ethBlk.Transactions(), 1)
+	require.Len(blk.(*chain.BlockWrapper).Block.(*Block).atomicTxs, 1)
+
+	nonce, err := vm.GetCurrentNonce(testEthAddrs[0])
+	require.NoError(err)
+	require.Equal(uint64(2), nonce)
+}
+
+func TestExportTxNonceRejectsEthTx(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.issueTx(exportTx, true /*=local*/))
+	require.Equal(uint64(1), vm.GetPendingNonce(testEthAddrs[0]))
+
+	// An EVM transaction re-using the nonce of the export is rejected.
+	errs := vm.txPool.AddRemotesSync([]*types.Transaction{newTestEthTx(t, vm, 0)})
+	require.Len(errs, 1)
+	require.ErrorIs(errs[0], txpool.ErrNonceReserved)
+
+	// The next nonce is still accepted by the pool.
+	errs = vm.txPool.AddRemotesSync([]*types.Transaction{newTestEthTx(t, vm, 1)})
+	require.Len(errs, 1)
+	require.NoError(errs[0])
+
+	// Once the export leaves the mempool its nonce is available again.
+	vm.mempool.RemoveTx(exportTx)
+	errs = vm.txPool.AddRemotesSync([]*types.Transaction{newTestEthTx(t, vm, 0)})
+	require.Len(errs, 1)
+	require.NoError(errs[0])
+}
+
+func TestExportTxNonceEvictsEthTx(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// Build the export before the EVM transaction reaches the pool so that
+	// both consume the same nonce.
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+
+	ethTxs := []*types.Transaction{
+		newTestEthTx(t, vm, 0),
+		newTestEthTx(t, vm, 1),
+	}
+	for _, err := range vm.txPool.AddRemotesSync(ethTxs) {
+		require.NoError(err)
+	}
+
+	require.NoError(vm.issueTx(exportTx, true /*=local*/))
+	require.False(vm.txPool.Has(ethTxs[0].Hash()))
+	require.True(vm.txPool.Has(ethTxs[1].Hash()))
+
+	pending, queued := vm.txPool.ContentFrom(testEthAddrs[0])
+	require.Empty(pending)
+	require.Len(queued, 1)
+	require.Equal(uint64(1), vm.GetPendingNonce(testEthAddrs[0]))
+}
+
+func TestExportTxNonceTooHigh(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+
+	// Bump the nonce past what is covered by the tx pool.
+	utx := exportTx.UnsignedAtomicTx.(*UnsignedExportTx)
+	utx.Ins[0].Nonce = 1
+	exportTx.Creds = nil
+	require.NoError(exportTx.Sign(vm.codec, [][]*secp256k1.PrivateKey{{testKeys[0]}}))
+
+	err = vm.issueTx(exportTx, true /*=local*/)
+	require.ErrorIs(err, errNonceTooHigh)
+}
+
+// newFeeCapTestExportArgs imports the first test key into the keystore user
+// and returns arguments exporting DIONE from the first test address.
+func newFeeCapTestExportArgs(t *testing.T, service *DioneAPI, maxBaseFee, priorityFee *big.Int) *ExportArgs {
+	userPass := api.UserPass{Username: username, Password: password}
+	require.NoError(t, service.ImportKey(nil, &ImportKeyArgs{
+		UserPass:   userPass,
+		PrivateKey: testKeys[0],
+	}, &api.JSONAddress{}))
+
+	return &ExportArgs{
+		ExportDIONEArgs: ExportDIONEArgs{
+			UserPass:    userPass,
+			MaxBaseFee:  (*hexutil.Big)(maxBaseFee),
+			PriorityFee: (*hexutil.Big)(priorityFee),
+			Amount:      odysseyJSON.Uint64(units.Dione),
+			TargetChain: "A",
+			To:          testShortIDAddrs[0].String(),
+		},
+		AssetID: service.vm.ctx.DIONEAssetID.String(),
+	}
+}
+
+func TestExportWithFeeCap(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	estimatedBaseFee, err := vm.estimateBaseFee(context.Background())
+	require.NoError(err)
+	maxBaseFee := new(big.Int).Sub(estimatedBaseFee, big.NewInt(1))
+	priorityFee := big.NewInt(params.GWei)
+
+	// The fee is priced at the capped base fee plus the priority fee.
+	expectedTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], new(big.Int).Add(maxBaseFee, priorityFee), []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+
+	reply := &api.JSONTxID{}
+	require.NoError(service.Export(nil, newFeeCapTestExportArgs(t, service, maxBaseFee, priorityFee), reply))
+	require.Equal(expectedTx.ID(), reply.TxID)
+	_, pending := vm.mempool.GetPendingTx(reply.TxID)
+	require.True(pending)
+
+	// The tx is kept as long as the base fee stays at or below the cap.
+	require.Empty(vm.mempool.DropFeeCapExceeded(maxBaseFee))
+	_, pending = vm.mempool.GetPendingTx(reply.TxID)
+	require.True(pending)
+
+	// The tx is dropped once the base fee rises above the cap.
+	dropped := vm.mempool.DropFeeCapExceeded(new(big.Int).Add(maxBaseFee, big.NewInt(1)))
+	require.Len(dropped, 1)
+	require.Equal(reply.TxID, dropped[0].ID())
+	_, isDropped, found := vm.mempool.GetTx(reply.TxID)
+	require.True(found)
+	require.True(isDropped)
+}
+
+func TestExportMaxBaseFeeTooLow(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	maxBaseFee := new(big.Int).Sub(vm.minBaseFee(), big.NewInt(1))
+	err := service.Export(nil, newFeeCapTestExportArgs(t, service, maxBaseFee, nil), &api.JSONTxID{})
+	require.ErrorIs(err, errMaxBaseFeeTooLow)
+	require.Zero(vm.mempool.Len())
+}
+
+func TestNewExportTxFeeLimit(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// A corrupt base fee makes the export burn far more than the default
+	// maximum fee.
+	hugeBaseFee := big.NewInt(10_000_000 * params.GWei)
+	keys := []*secp256k1.PrivateKey{testKeys[0]}
+
+	_, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], hugeBaseFee, keys, false)
+	require.ErrorIs(err, errAtomicTxFeeTooHigh)
+
+	tx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], hugeBaseFee, keys, true)
+	require.NoError(err)
+	burned, err := tx.Burned(vm.ctx.DIONEAssetID)
+	require.NoError(err)
+	require.Greater(burned, vm.config.AtomicTxMaxFee)
+
+	_, err = vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, keys, false)
+	require.NoError(err)
+}
+
+func TestVerifyAtomicTxFee(t *testing.T) {
+	tests := map[string]struct {
+		config      Config
+		fee, amount uint64
+		expectedErr error
+	}{
+		"no limits": {
+			fee:    100 * units.Dione,
+			amount: units.Dione,
+		},
+		"below max fee": {
+			config: Config{AtomicTxMaxFee: 10 * units.Dione},
+			fee:    10 * units.Dione,
+		},
+		"above max fee": {
+			config:      Config{AtomicTxMaxFee: 10 * units.Dione},
+			fee:         10*units.Dione + 1,
+			expectedErr: errAtomicTxFeeTooHigh,
+		},
+		"below max fee fraction": {
+			config: Config{AtomicTxMaxFeeFraction: 0.1},
+			fee:    units.Dione,
+			amount: 10 * units.Dione,
+		},
+		"above max fee fraction": {
+			config:      Config{AtomicTxMaxFeeFraction: 0.1},
+			fee:         units.Dione + 1,
+			amount:      10 * units.Dione,
+			expectedErr: errAtomicTxFeeTooHigh,
+		},
+		"max fee fraction without DIONE moved": {
+			config: Config{AtomicTxMaxFeeFraction: 0.1},
+			fee:    units.Dione,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			vm := &VM{config: test.config}
+			require.ErrorIs(t, vm.verifyAtomicTxFee(test.fee, test.amount), test.expectedErr)
+		})
+	}
+}
+
+func TestBuildExportTx(t *testing.T) {
+	require := require.New(t)
+	issuer, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	// The unsigned tx matches the tx the keystore based export would build.
+	expectedTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+
+	reply := &BuildExportTxReply{}
+	require.NoError(service.BuildExportTx(nil, &BuildExportTxArgs{
+		BaseFee:     (*hexutil.Big)(initialBaseFee),
+		Amount:      odysseyJSON.Uint64(units.Dione),
+		TargetChain: "A",
+		To:          testShortIDAddrs[0].String(),
+		From:        testEthAddrs[0],
+	}, reply))
+	require.Equal(odysseyJSON.Uint64(buildExportTxTTL/time.Second), reply.TTL)
+
+	unsignedBytes, err := formatting.Decode(reply.Encoding, reply.Tx)
+	require.NoError(err)
+	require.Equal(expectedTx.Bytes(), unsignedBytes)
+	require.Len(reply.Inputs, 1)
+	require.Equal(testEthAddrs[0], reply.Inputs[0].Address)
+	require.Equal(vm.ctx.DIONEAssetID, reply.Inputs[0].AssetID)
+	require.Zero(reply.Inputs[0].Nonce)
+
+	// Sign the digest of each input outside of the node.
+	tx := &Tx{}
+	_, err = vm.codec.Unmarshal(unsignedBytes, &tx.UnsignedAtomicTx)
+	require.NoError(err)
+	for _, in := range reply.Inputs {
+		require.Equal(hashing.ComputeHash256(unsignedBytes), []byte(in.Digest))
+		sig, err := testKeys[0].SignHash(in.Digest)
+		require.NoError(err)
+		cred := &secp256k1fx.Credential{Sigs: make([][secp256k1.SignatureLen]byte, 1)}
+		copy(cred.Sigs[0][:], sig)
+		tx.Creds = append(tx.Creds, cred)
+	}
+	signedBytes, err := vm.codec.Marshal(codecVersion, tx)
+	require.NoError(err)
+	encodedTx, err := formatting.Encode(formatting.Hex, signedBytes)
+	require.NoError(err)
+
+	issueReply := &api.JSONTxID{}
+	require.NoError(service.IssueTx(nil, &api.FormattedTx{
+		Tx:       encodedTx,
+		Encoding: formatting.Hex,
+	}, issueReply))
+	require.Equal(expectedTx.ID(), issueReply.TxID)
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	_, status, _, err := vm.getAtomicTx(issueReply.TxID)
+	require.NoError(err)
+	require.Equal(Accepted, status)
+	nonce, err := vm.GetCurrentNonce(testEthAddrs[0])
+	require.NoError(err)
+	require.Equal(uint64(1), nonce)
+}
+
+func TestBuildExportTxInsufficientFunds(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	err := service.BuildExportTx(nil, &BuildExportTxArgs{
+		BaseFee:     (*hexutil.Big)(initialBaseFee),
+		Amount:      odysseyJSON.Uint64(units.Dione),
+		TargetChain: "A",
+		To:          testShortIDAddrs[0].String(),
+		From:        testEthAddrs[1],
+	}, &BuildExportTxReply{})
+	require.ErrorIs(err, errInsufficientFunds)
+}
+
+// An export that cannot spend one of its inputs must not spend any of them.
+func TestExportTxDELTAStateTransferAllOrNothing(t *testing.T) {
+	require := require.New(t)
+
+	ctx := NewContext()
+	rules := params.TestChainConfig.OdysseyRules(common.Big0, 0)
+	otherAssetID := ids.GenerateTestID()
+	sdb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	sdb.AddBalance(testEthAddrs[0], params.NdioneToWei(rules, units.Dione))
+	sdb.AddBalanceMultiCoin(testEthAddrs[1], common.Hash(otherAssetID), new(big.Int).SetUint64(units.Dione))
+
+	exportTx := &UnsignedExportTx{
+		Ins: []DELTAInput{
+			{Address: testEthAddrs[0], Amount: units.Dione, AssetID: ctx.DIONEAssetID},
+			{Address: testEthAddrs[1], Amount: 2 * units.Dione, AssetID: otherAssetID},
+		},
+	}
+	require.ErrorIs(exportTx.DELTAStateTransfer(ctx, sdb, rules), errInsufficientFunds)
+	require.Equal(params.NdioneToWei(rules, units.Dione), sdb.GetBalance(testEthAddrs[0]))
+	require.Equal(new(big.Int).SetUint64(units.Dione), sdb.GetBalanceMultiCoin(testEthAddrs[1], common.Hash(otherAssetID)))
+	require.Zero(sdb.GetNonce(testEthAddrs[0]))
+
+	exportTx.Ins[1].Amount = units.Dione
+	require.NoError(exportTx.DELTAStateTransfer(ctx, sdb, rules))
+	require.Zero(sdb.GetBalance(testEthAddrs[0]).Sign())
+	require.Zero(sdb.GetBalanceMultiCoin(testEthAddrs[1], common.Hash(otherAssetID)).Sign())
+	require.Equal(uint64(1), sdb.GetNonce(testEthAddrs[0]))
+}
@@ -4,11 +4,17 @@
 package delta
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/DioneProtocol/coreth/params"
 )
 
+var errZeroAllocationDenominator = errors.New("fee allocation denominator is zero")
+
 type FeesDistribution struct {
 	BaseFee              *big.Int
 	PriorityFee          *big.Int
@@ -17,62 +23,151 @@ type FeesDistribution struct {
 	OrionFee             *big.Int
 }
 
-func calculateToGovernanceAndOrion(totalBaseFee, orionAmount *big.Int, rules *params.Rules) (*big.Int, *big.Int) {
-	governanceAndOrion := new(big.Int).Set(rules.GovernanceAllocation)
-	governanceAndOrion.Mul(governanceAndOrion, totalBaseFee)
-	governanceAndOrion.Div(governanceAndOrion, rules.AllocationDenominator)
+// String formats each fee of [f] in nDIONE, with comma separators.
+func (f *FeesDistribution) String() string {
+	if f == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf(
+		"BaseFee=%s PriorityFee=%s Lp=%s Governance=%s Orion=%s",
+		formatNDIONE(f.BaseFee),
+		formatNDIONE(f.PriorityFee),
+		formatNDIONE(f.LpAllocation),
+		formatNDIONE(f.GovernanceAllocation),
+		formatNDIONE(f.OrionFee),
+	)
+}
 
-	summaryOrionAllocation := new(big.Int).Set(rules.OrionAllocation)
+// MarshalJSON encodes each fee of [f] in wei as a decimal string, so that
+// large values are not rounded by JSON decoders.
+func (f *FeesDistribution) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		BaseFee              string `json:"baseFee"`
+		PriorityFee          string `json:"priorityFee"`
+		LpAllocation         string `json:"lpAllocation"`
+		GovernanceAllocation string `json:"governanceAllocation"`
+		OrionFee             string `json:"orionFee"`
+	}{
+		BaseFee:              bigOrZero(f.BaseFee).String(),
+		PriorityFee:          bigOrZero(f.PriorityFee).String(),
+		LpAllocation:         bigOrZero(f.LpAllocation).String(),
+		GovernanceAllocation: bigOrZero(f.GovernanceAllocation).String(),
+		OrionFee:             bigOrZero(f.OrionFee).String(),
+	})
+}
+
+// formatNDIONE formats [wei] in nDIONE with comma separators. Any sub-nDIONE
+// remainder is kept as a fractional part.
+func formatNDIONE(wei *big.Int) string {
+	wei = bigOrZero(wei)
+	nDione, remainder := new(big.Int).QuoRem(new(big.Int).Abs(wei), x2cRate, new(big.Int))
+
+	digits := nDione.String()
+	var sb strings.Builder
+	if wei.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+	if remainder.Sign() != 0 {
+		fraction := fmt.Sprintf("%09d", remainder)
+		sb.WriteByte('.')
+		sb.WriteString(strings.TrimRight(fraction, "0"))
+	}
+	return sb.String()
+}
+
+// bigOrZero returns [x], or zero if [x] is nil.
+func bigOrZero(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
+
+// allocate returns the share [allocation]/[denominator] of [amount].
+func allocate(amount, allocation *big.Int, denominator uint64) (*big.Int, error) {
+	if denominator == 0 {
+		return nil, errZeroAllocationDenominator
+	}
+	result := new(big.Int).Mul(allocation, amount)
+	return result.Div(result, params.AllocationBig(denominator)), nil
+}
+
+func calculateToGovernanceAndOrion(totalBaseFee, orionAmount *big.Int, rules *params.Rules) (*big.Int, *big.Int, error) {
+	governanceAndOrion, err := allocate(totalBaseFee, params.AllocationBig(rules.GovernanceAllocation), rules.AllocationDenominator)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summaryOrionAllocation := params.AllocationBig(rules.OrionAllocation)
 	summaryOrionAllocation.Mul(summaryOrionAllocation, orionAmount)
 
-	if summaryOrionAllocation.Cmp(rules.MaxOrionAllocation) > 0 {
-		summaryOrionAllocation.Set(rules.MaxOrionAllocation)
+	maxOrionAllocation := params.AllocationBig(rules.MaxOrionAllocation)
+	if summaryOrionAllocation.Cmp(maxOrionAllocation) > 0 {
+		summaryOrionAllocation.Set(maxOrionAllocation)
 	}
 
 	if orionAmount.Sign() == 0 {
-		return governanceAndOrion, new(big.Int)
+		return governanceAndOrion, new(big.Int), nil
 	}
 
-	summaryOrionAllocation.Mul(summaryOrionAllocation, totalBaseFee)
-	summaryOrionAllocation.Div(summaryOrionAllocation, rules.AllocationDenominator)
+	summaryOrionAllocation, err = allocate(totalBaseFee, summaryOrionAllocation, rules.AllocationDenominator)
+	if err != nil {
+		return nil, nil, err
+	}
 	orionAllocation := new(big.Int).Div(summaryOrionAllocation, orionAmount)
 
 	correctSummaryOrionAllocatoin := new(big.Int).Mul(orionAllocation, orionAmount)
 	governanceAllocation := new(big.Int).Sub(governanceAndOrion, correctSummaryOrionAllocatoin)
-	return governanceAllocation, correctSummaryOrionAllocatoin
+	return governanceAllocation, correctSummaryOrionAllocatoin, nil
 }
 
-func calculateToLp(totalBaseFee *big.Int, rules *params.Rules) *big.Int {
-	lpAllocation := new(big.Int).Set(rules.LpAllocation)
-	lpAllocation.Mul(lpAllocation, totalBaseFee)
-	lpAllocation.Div(lpAllocation, rules.AllocationDenominator)
-	return lpAllocation
+func calculateToLp(totalBaseFee *big.Int, rules *params.Rules) (*big.Int, error) {
+	return allocate(totalBaseFee, params.AllocationBig(rules.LpAllocation), rules.AllocationDenominator)
 }
 
-func calculatePriorityFeeAndOrion(totalPriorityFee, orionAmount *big.Int, rules *params.Rules) (*big.Int, *big.Int) {
-	summaryOrionAllocation := new(big.Int).Set(rules.PriorityFeeOrionAllocation)
-	summaryOrionAllocation.Mul(summaryOrionAllocation, totalPriorityFee)
-	summaryOrionAllocation.Div(summaryOrionAllocation, rules.AllocationDenominator)
+func calculatePriorityFeeAndOrion(totalPriorityFee, orionAmount *big.Int, rules *params.Rules) (*big.Int, *big.Int, error) {
+	summaryOrionAllocation, err := allocate(totalPriorityFee, params.AllocationBig(rules.PriorityFeeOrionAllocation), rules.AllocationDenominator)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if orionAmount.Sign() == 0 {
-		return totalPriorityFee, new(big.Int)
+		return totalPriorityFee, new(big.Int), nil
 	}
 
 	orionAllocation := new(big.Int).Div(summaryOrionAllocation, orionAmount)
 	correctSummaryOrionAllocation := new(big.Int).Mul(orionAllocation, orionAmount)
 
 	toPriorityFee := new(big.Int).Sub(totalPriorityFee, correctSummaryOrionAllocation)
-	return toPriorityFee, correctSummaryOrionAllocation
+	return toPriorityFee, correctSummaryOrionAllocation, nil
 }
 
-func CalculateFees(totalBaseFee *big.Int, totalPriorityFee *big.Int, orionAmount uint64, rules *params.Rules) *FeesDistribution {
+// CalculateFees returns the distribution of [totalBaseFee] and
+// [totalPriorityFee] to [orionAmount] orion nodes under the allocations of
+// [rules]. It errors if the allocation denominator of [rules] is zero.
+func CalculateFees(totalBaseFee *big.Int, totalPriorityFee *big.Int, orionAmount uint64, rules *params.Rules) (*FeesDistribution, error) {
 	totalBaseFee = new(big.Int).Set(totalBaseFee)
 	totalPriorityFee = new(big.Int).Set(totalPriorityFee)
 
 	orionAmountBigInt := new(big.Int).SetUint64(orionAmount)
-	lpAllocation := calculateToLp(totalBaseFee, rules)
-	governanceAllocation, orionFeeFromGovernance := calculateToGovernanceAndOrion(totalBaseFee, orionAmountBigInt, rules)
-	totalPriorityFee, orionFeeFromPriorityFee := calculatePriorityFeeAndOrion(totalPriorityFee, orionAmountBigInt, rules)
+	lpAllocation, err := calculateToLp(totalBaseFee, rules)
+	if err != nil {
+		return nil, err
+	}
+	governanceAllocation, orionFeeFromGovernance, err := calculateToGovernanceAndOrion(totalBaseFee, orionAmountBigInt, rules)
+	if err != nil {
+		return nil, err
+	}
+	totalPriorityFee, orionFeeFromPriorityFee, err := calculatePriorityFeeAndOrion(totalPriorityFee, orionAmountBigInt, rules)
+	if err != nil {
+		return nil, err
+	}
 
 	orionAllocation := new(big.Int).Set(orionFeeFromGovernance)
 	orionAllocation.Add(orionAllocation, orionFeeFromPriorityFee)
@@ -91,5 +186,5 @@ func CalculateFees(totalBaseFee *big.Int, totalPriorityFee *big.Int, orionAmount
 		LpAllocation:         lpAllocation,
 		GovernanceAllocation: governanceAllocation,
 		OrionFee:             orionAllocation,
-	}
+	}, nil
 }
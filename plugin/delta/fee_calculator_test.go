@@ -4,7 +4,9 @@
 package delta
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"testing"
 
@@ -244,15 +246,16 @@ func TestFeeCalculator(t *testing.T) {
 
 		t.Run(name, func(t *testing.T) {
 			rules := params.Rules{
-				LpAllocation:               new(big.Int).SetUint64(test.lpAllocation),
-				GovernanceAllocation:       new(big.Int).SetUint64(test.governanceAllocation),
-				PriorityFeeOrionAllocation: new(big.Int).SetUint64(test.priorityFeeOrionAllocation),
-				OrionAllocation:            new(big.Int).SetUint64(test.orionAllocation),
-				MaxOrionAllocation:         new(big.Int).SetUint64(test.maxOrionAllocation),
-				AllocationDenominator:      new(big.Int).SetUint64(100),
+				LpAllocation:               test.lpAllocation,
+				GovernanceAllocation:       test.governanceAllocation,
+				PriorityFeeOrionAllocation: test.priorityFeeOrionAllocation,
+				OrionAllocation:            test.orionAllocation,
+				MaxOrionAllocation:         test.maxOrionAllocation,
+				AllocationDenominator:      100,
 			}
 
-			fees := CalculateFees(new(big.Int).SetUint64(test.baseFee), new(big.Int).SetUint64(test.priorityFee), test.nodesAmount, &rules)
+			fees, err := CalculateFees(new(big.Int).SetUint64(test.baseFee), new(big.Int).SetUint64(test.priorityFee), test.nodesAmount, &rules)
+			require.NoError(t, err)
 			require.Equal(t, fees.PriorityFee.Uint64(), test.expectedPriorityFee, "Priority fee %d != %d", fees.PriorityFee, test.expectedPriorityFee)
 			require.Equal(t, fees.OrionFee.Uint64(), test.expectedOrionFee, "Orion fee %d != %d", fees.OrionFee, test.expectedOrionFee)
 			require.Equal(t, fees.LpAllocation.Uint64(), test.expectedLpAllocation, "Lp allocation %d != %d", fees.LpAllocation, test.expectedLpAllocation)
@@ -261,3 +264,68 @@ func TestFeeCalculator(t *testing.T) {
 		})
 	}
 }
+
+func TestFeeCalculatorNilAllocations(t *testing.T) {
+	require := require.New(t)
+
+	_, err := CalculateFees(big.NewInt(1_000_000), big.NewInt(1_000), 2, &params.Rules{})
+	require.ErrorIs(err, errZeroAllocationDenominator)
+
+	rules := params.Rules{
+		LpAllocation:          50,
+		AllocationDenominator: 100,
+	}
+	fees, err := CalculateFees(big.NewInt(1_000_000), big.NewInt(1_000), 2, &rules)
+	require.NoError(err)
+	require.Equal(big.NewInt(500_000), fees.BaseFee)
+	require.Equal(big.NewInt(500_000), fees.LpAllocation)
+	require.Equal(big.NewInt(1_000), fees.PriorityFee)
+	require.Zero(fees.OrionFee.Sign())
+	require.Zero(fees.GovernanceAllocation.Sign())
+}
+
+func TestFeesDistributionString(t *testing.T) {
+	require := require.New(t)
+
+	fees := &FeesDistribution{
+		BaseFee:              DIONEToWei(1_000_000),
+		PriorityFee:          DIONEToWei(500_000),
+		LpAllocation:         DIONEToWei(250_000),
+		GovernanceAllocation: DIONEToWei(200_000),
+		OrionFee:             DIONEToWei(50_000),
+	}
+	require.Equal("BaseFee=1,000,000 PriorityFee=500,000 Lp=250,000 Governance=200,000 Orion=50,000", fees.String())
+	require.Equal("BaseFee=1,000,000 PriorityFee=500,000 Lp=250,000 Governance=200,000 Orion=50,000", fmt.Sprint(fees))
+
+	// Sub-nDIONE amounts, nil and negative fields are still printed.
+	fees = &FeesDistribution{
+		BaseFee:     big.NewInt(1_234_000_000_500_000_000),
+		PriorityFee: big.NewInt(1),
+		OrionFee:    big.NewInt(-2_000_000_000),
+	}
+	require.Equal("BaseFee=1,234,000,000.5 PriorityFee=0.000000001 Lp=0 Governance=0 Orion=-2", fees.String())
+
+	require.Equal("<nil>", (*FeesDistribution)(nil).String())
+}
+
+func TestFeesDistributionMarshalJSON(t *testing.T) {
+	require := require.New(t)
+
+	// MaxUint64 nDIONE in wei does not fit in a float64 without losing precision.
+	large := DIONEToWei(math.MaxUint64)
+	fees := &FeesDistribution{
+		BaseFee:              large,
+		PriorityFee:          big.NewInt(1),
+		LpAllocation:         big.NewInt(250_000),
+		GovernanceAllocation: big.NewInt(200_000),
+	}
+	b, err := json.Marshal(fees)
+	require.NoError(err)
+	require.JSONEq(`{
+		"baseFee": "18446744073709551615000000000",
+		"priorityFee": "1",
+		"lpAllocation": "250000",
+		"governanceAllocation": "200000",
+		"orionFee": "0"
+	}`, string(b))
+}
@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/DioneProtocol/coreth/rpc"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// FeeHistoryResult is the fee market history of a range of blocks, extended
+// with the distribution of the base fee of each block.
+type FeeHistoryResult struct {
+	OldestBlock *hexutil.Big `json:"oldestBlock"`
+	// BaseFee is the base fee per gas of each block
+	BaseFee []*hexutil.Big `json:"baseFeePerGas"`
+	// OrionFee is the part of the base fee per gas paid to each orion node
+	OrionFee []*hexutil.Big `json:"orionFeePerGas"`
+	// GovernanceFee is the part of the base fee per gas paid to governance
+	GovernanceFee []*hexutil.Big `json:"governanceFeePerGas"`
+	// LpFee is the part of the base fee per gas paid to the LP address
+	LpFee []*hexutil.Big `json:"lpFeePerGas"`
+	// Reward is the requested percentiles of the effective priority fees per
+	// gas of the transactions in each block
+	Reward [][]*hexutil.Big `json:"reward,omitempty"`
+}
+
+// FeeHistory returns the fee market history of the [blockCount] blocks ending
+// at [newestBlock], along with the distribution of the base fee of each block
+// between orion nodes, governance and the LP address.
+// If the orion node list of a block is unavailable, the block is reported as
+// having no orion nodes.
+func (vm *VM) FeeHistory(ctx context.Context, blockCount uint64, newestBlock rpc.BlockNumber, rewardPercentiles []float64) (*FeeHistoryResult, error) {
+	oldest, reward, baseFees, _, err := vm.eth.APIBackend.FeeHistory(ctx, blockCount, newestBlock, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FeeHistoryResult{
+		OldestBlock:   (*hexutil.Big)(oldest),
+		BaseFee:       make([]*hexutil.Big, len(baseFees)),
+		OrionFee:      make([]*hexutil.Big, len(baseFees)),
+		GovernanceFee: make([]*hexutil.Big, len(baseFees)),
+		LpFee:         make([]*hexutil.Big, len(baseFees)),
+	}
+	for i, baseFee := range baseFees {
+		number := oldest.Uint64() + uint64(i)
+		header := vm.blockChain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("missing header for block %d", number)
+		}
+		if baseFee == nil {
+			baseFee = new(big.Int)
+		}
+
+		rules := vm.chainConfig.OdysseyRules(header.Number, header.Time)
+		orionNodes, err := vm.readOrionNodesCount(header)
+		if err != nil {
+			log.Debug("orion nodes unavailable for fee history", "block", number, "err", err)
+		}
+		fees, err := CalculateFees(baseFee, new(big.Int), orionNodes, &rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate fees of block %d: %w", number, err)
+		}
+
+		result.BaseFee[i] = (*hexutil.Big)(baseFee)
+		result.OrionFee[i] = (*hexutil.Big)(fees.OrionFee)
+		result.GovernanceFee[i] = (*hexutil.Big)(fees.GovernanceAllocation)
+		result.LpFee[i] = (*hexutil.Big)(fees.LpAllocation)
+	}
+	if reward != nil {
+		result.Reward = make([][]*hexutil.Big, len(reward))
+		for i, w := range reward {
+			result.Reward[i] = make([]*hexutil.Big, len(w))
+			for j, v := range w {
+				result.Reward[i][j] = (*hexutil.Big)(v)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,145 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/rpc"
+	"github.com/DioneProtocol/odysseygo/utils/units"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var feeHistoryTestOrionContract = common.HexToAddress("0x0710400000000000000000000000000000000000")
+
+// newFeeHistoryTestVM returns a VM with a funded first test address and
+// [orionNodes] orion nodes registered in the orion contract at genesis.
+func newFeeHistoryTestVM(t *testing.T, orionNodes uint64) *VM {
+	genesis := &core.Genesis{}
+	require.NoError(t, json.Unmarshal([]byte(genesisJSONLatest), genesis))
+	genesis.Alloc[testEthAddrs[0]] = core.GenesisAccount{
+		Balance: new(big.Int).Mul(new(big.Int).SetUint64(units.MegaDione), x2cRate),
+	}
+	if orionNodes > 0 {
+		sizeSlot := common.HexToHash("0x02")
+		var size common.Hash
+		binary.BigEndian.PutUint64(size[24:], orionNodes)
+		storage := map[common.Hash]common.Hash{sizeSlot: size}
+		listStart := crypto.Keccak256Hash(sizeSlot[:]).Big()
+		for i := uint64(0); i < orionNodes; i++ {
+			slot := common.BigToHash(new(big.Int).Add(listStart, new(big.Int).SetUint64(i)))
+			storage[slot] = common.BigToHash(new(big.Int).SetUint64(i + 1))
+		}
+		genesis.Alloc[feeHistoryTestOrionContract] = core.GenesisAccount{
+			Balance: big.NewInt(1),
+			Storage: storage,
+		}
+	}
+	genesisJSON, err := json.Marshal(genesis)
+	require.NoError(t, err)
+
+	issuer, vm, _, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+
+	// Accept a block containing a single eth tx.
+	tx := types.NewTransaction(0, testEthAddrs[1], big.NewInt(10), 21000, big.NewInt(params.ApricotPhase4MaxBaseFee), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainID), testKeys[0].ToECDSA())
+	require.NoError(t, err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{signedTx}) {
+		require.NoError(t, err)
+	}
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Verify(context.Background()))
+	require.NoError(t, vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(t, blk.Accept(context.Background()))
+	vm.blockChain.DrainAcceptorQueue()
+	return vm
+}
+
+func TestFeeHistoryEmptyRange(t *testing.T) {
+	require := require.New(t)
+	vm := newFeeHistoryTestVM(t, 0)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	result, err := vm.FeeHistory(context.Background(), 0, rpc.LatestBlockNumber, []float64{50})
+	require.NoError(err)
+	require.Zero(result.OldestBlock.ToInt().Sign())
+	require.Empty(result.BaseFee)
+	require.Empty(result.OrionFee)
+	require.Empty(result.GovernanceFee)
+	require.Empty(result.LpFee)
+	require.Empty(result.Reward)
+}
+
+func TestFeeHistory(t *testing.T) {
+	tests := map[string]uint64{
+		"missing orion nodes": 0,
+		"orion nodes":         4,
+	}
+	for name, orionNodes := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			vm := newFeeHistoryTestVM(t, orionNodes)
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+
+			service := &DioneAPI{vm: vm}
+			result := &FeeHistoryResult{}
+			require.NoError(service.FeeHistory(nil, &FeeHistoryArgs{
+				BlockCount:        1,
+				NewestBlock:       "latest",
+				RewardPercentiles: []float64{50},
+			}, result))
+
+			blk := vm.blockChain.CurrentBlock()
+			require.Equal(blk.Number, result.OldestBlock.ToInt())
+			require.Equal([]*hexutil.Big{(*hexutil.Big)(blk.BaseFee)}, result.BaseFee)
+
+			rules := vm.chainConfig.OdysseyRules(blk.Number, blk.Time)
+			fees, err := CalculateFees(blk.BaseFee, new(big.Int), orionNodes, &rules)
+			require.NoError(err)
+			require.Equal([]*hexutil.Big{(*hexutil.Big)(fees.OrionFee)}, result.OrionFee)
+			require.Equal([]*hexutil.Big{(*hexutil.Big)(fees.GovernanceAllocation)}, result.GovernanceFee)
+			require.Equal([]*hexutil.Big{(*hexutil.Big)(fees.LpAllocation)}, result.LpFee)
+			if orionNodes == 0 {
+				require.Zero(fees.OrionFee.Sign())
+			} else {
+				require.Positive(fees.OrionFee.Sign())
+			}
+
+			expectedTip := new(big.Int).Sub(big.NewInt(params.ApricotPhase4MaxBaseFee), blk.BaseFee)
+			require.Equal([][]*hexutil.Big{{(*hexutil.Big)(expectedTip)}}, result.Reward)
+		})
+	}
+}
+
+func TestFeeHistoryInvalidNewestBlock(t *testing.T) {
+	require := require.New(t)
+	vm := newFeeHistoryTestVM(t, 0)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	service := &DioneAPI{vm: vm}
+	err := service.FeeHistory(nil, &FeeHistoryArgs{
+		BlockCount:  1,
+		NewestBlock: "newest",
+	}, &FeeHistoryResult{})
+	require.Error(err)
+}
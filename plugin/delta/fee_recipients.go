@@ -0,0 +1,84 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+)
+
+// FeeRecipientByBlock returns the amount of the EVM tx fees of [blockID], in
+// wei, credited to each address by the fee distribution. The fees left after
+// the LP and governance allocations are collected for the validators and are
+// attributed to the coinbase of the block. The orion fee is paid to orion
+// nodes rather than to addresses, so it is not included.
+func (vm *VM) FeeRecipientByBlock(blockID ids.ID) (map[common.Address]*big.Int, error) {
+	ethBlock, fees, _, err := vm.feesDistribution(blockID)
+	if err != nil {
+		return nil, err
+	}
+	rules := vm.chainConfig.OdysseyRules(ethBlock.Number(), ethBlock.Time())
+	return feeRecipients(fees, ethBlock.Coinbase(), &rules), nil
+}
+
+// GetFeeDistribution returns the distribution of the EVM tx fees of [blockID],
+// in wei, along with the snapshot of the orion nodes list it was calculated
+// with.
+func (vm *VM) GetFeeDistribution(blockID ids.ID) (*FeesDistribution, OrionSnapshot, error) {
+	_, fees, orions, err := vm.feesDistribution(blockID)
+	if err != nil {
+		return nil, OrionSnapshot{}, err
+	}
+	return fees, orions.snapshot, nil
+}
+
+// feesDistribution recalculates the distribution of the EVM tx fees of
+// [blockID] with the orion nodes list they were distributed to.
+func (vm *VM) feesDistribution(blockID ids.ID) (*types.Block, *FeesDistribution, *orionNodes, error) {
+	ethBlock := vm.blockChain.GetBlockByHash(common.Hash(blockID))
+	if ethBlock == nil {
+		return nil, nil, nil, fmt.Errorf("%w: block %s", database.ErrNotFound, blockID)
+	}
+	txs := ethBlock.Transactions()
+	receipts := vm.blockChain.GetReceiptsByHash(ethBlock.Hash())
+	if len(receipts) != len(txs) {
+		return nil, nil, nil, fmt.Errorf("receipts of block %s are not available", blockID)
+	}
+	orions, err := vm.readOrionNodes(ethBlock.Header())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rules := vm.chainConfig.OdysseyRules(ethBlock.Number(), ethBlock.Time())
+	totalBaseFee, totalPriorityFee := vm.calculateTxFees(ethBlock.BaseFee(), txs, receipts, &rules)
+	fees, err := CalculateFees(totalBaseFee, totalPriorityFee, orions.snapshot.NodeCount, &rules)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ethBlock, fees, orions, nil
+}
+
+// feeRecipients returns the amount of [fees] credited to each address under
+// [rules], attributing the validators' share to [coinbase].
+func feeRecipients(fees *FeesDistribution, coinbase common.Address, rules *params.Rules) map[common.Address]*big.Int {
+	recipients := make(map[common.Address]*big.Int, 3)
+	credit := func(addr common.Address, amount *big.Int) {
+		if total, ok := recipients[addr]; ok {
+			total.Add(total, amount)
+			return
+		}
+		recipients[addr] = new(big.Int).Set(amount)
+	}
+	credit(rules.LpAddress, fees.LpAllocation)
+	credit(rules.GovernanceAddress, fees.GovernanceAllocation)
+	credit(coinbase, new(big.Int).Add(fees.BaseFee, fees.PriorityFee))
+	return recipients
+}
@@ -0,0 +1,160 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/DioneProtocol/odysseygo/vms/components/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+)
+
+func TestFeeRecipients(t *testing.T) {
+	var (
+		lpAddr         = common.Address{1}
+		governanceAddr = common.Address{2}
+		coinbase       = common.Address{3}
+	)
+	rules := params.Rules{
+		LpAddress:                  lpAddr,
+		GovernanceAddress:          governanceAddr,
+		LpAllocation:               25,
+		GovernanceAllocation:       50,
+		PriorityFeeOrionAllocation: 50,
+		OrionAllocation:            5,
+		MaxOrionAllocation:         100,
+		AllocationDenominator:      100,
+	}
+
+	tests := []struct {
+		name     string
+		rules    params.Rules
+		expected map[common.Address]*big.Int
+	}{
+		{
+			name:  "distinct recipients",
+			rules: rules,
+			expected: map[common.Address]*big.Int{
+				lpAddr:         big.NewInt(250_000),
+				governanceAddr: big.NewInt(250_000),
+				// base fee 250_000 + priority fee 500_000
+				coinbase: big.NewInt(750_000),
+			},
+		},
+		{
+			name: "shared lp and governance address",
+			rules: func() params.Rules {
+				r := rules
+				r.GovernanceAddress = lpAddr
+				return r
+			}(),
+			expected: map[common.Address]*big.Int{
+				lpAddr:   big.NewInt(500_000),
+				coinbase: big.NewInt(750_000),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fees, err := CalculateFees(big.NewInt(1_000_000), big.NewInt(1_000_000), 5, &test.rules)
+			require.NoError(t, err)
+			recipients := feeRecipients(fees, coinbase, &test.rules)
+			require.Equal(t, test.expected, recipients)
+
+			// The recipients must not alias the distribution.
+			recipients[coinbase].SetUint64(0)
+			require.Equal(t, big.NewInt(250_000), fees.BaseFee)
+		})
+	}
+}
+
+func TestFeeRecipientByBlock(t *testing.T) {
+	require := require.New(t)
+
+	importAmount := 100 * params.OdysseyAtomicTxFee
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
+	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
+
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, true)
+	require.NoError(err)
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+	<-issuer
+
+	blk1, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk1.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk1.ID()))
+	require.NoError(blk1.Accept(context.Background()))
+	<-newTxPoolHeadChan
+
+	txs := make([]*types.Transaction, 5)
+	for i := range txs {
+		tx := types.NewTransaction(uint64(i), testEthAddrs[1], big.NewInt(10), 21000, big.NewInt(5*params.LaunchMinGasPrice), nil)
+		signedTx, err := types.SignTx(tx, types.LatestSigner(vm.chainConfig), testKeys[0].ToECDSA())
+		require.NoError(err)
+		txs[i] = signedTx
+	}
+	for i, err := range vm.txPool.AddRemotesSync(txs) {
+		require.NoError(err, "failed to add tx at index %d", i)
+	}
+	<-issuer
+
+	blk2, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk2.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk2.ID()))
+	require.NoError(blk2.Accept(context.Background()))
+
+	ethBlk2 := blk2.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(ethBlk2.Transactions(), len(txs))
+
+	recipients, err := vm.FeeRecipientByBlock(blk2.ID())
+	require.NoError(err)
+
+	// The LP and governance allocations match the balances credited by the
+	// block.
+	rules := vm.chainConfig.OdysseyRules(ethBlk2.Number(), ethBlk2.Time())
+	parentState, err := vm.blockChain.StateAt(blk1.(*chain.BlockWrapper).Block.(*Block).ethBlock.Root())
+	require.NoError(err)
+	state, err := vm.blockChain.StateAt(ethBlk2.Root())
+	require.NoError(err)
+	for _, addr := range []common.Address{rules.LpAddress, rules.GovernanceAddress} {
+		credited := new(big.Int).Sub(state.GetBalance(addr), parentState.GetBalance(addr))
+		require.Equal(credited, recipients[addr], "address %s", addr)
+	}
+
+	// Every fee paid by the txs is accounted for, as there are no orion
+	// nodes.
+	receipts := vm.blockChain.GetReceiptsByHash(ethBlk2.Hash())
+	totalFees := new(big.Int)
+	for i, tx := range ethBlk2.Transactions() {
+		gasPrice := new(big.Int).Add(ethBlk2.BaseFee(), tx.EffectiveGasTipValue(ethBlk2.BaseFee()))
+		totalFees.Add(totalFees, new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipts[i].GasUsed)))
+	}
+	distributed := new(big.Int)
+	for _, amount := range recipients {
+		distributed.Add(distributed, amount)
+	}
+	require.Equal(totalFees, distributed)
+
+	_, err = vm.FeeRecipientByBlock(ids.GenerateTestID())
+	require.ErrorIs(err, database.ErrNotFound)
+}
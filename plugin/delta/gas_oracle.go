@@ -0,0 +1,78 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"math/big"
+
+	"github.com/DioneProtocol/coreth/consensus/dummy"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasOracle predicts the base fee of blocks that will be built in the future.
+// Blocks are assumed to be produced at the target block rate of the chain
+// config until the predicted block.
+type GasOracle struct {
+	config *params.ChainConfig
+}
+
+// NewGasOracle returns a GasOracle predicting base fees under [config].
+func NewGasOracle(config *params.ChainConfig) *GasOracle {
+	return &GasOracle{config: config}
+}
+
+// PredictBaseFee predicts the base fee of a block built at [futureTimestamp]
+// on top of [parent], assuming every block until then consumes the same amount
+// of gas as [parent].
+func (o *GasOracle) PredictBaseFee(parent *types.Header, futureTimestamp uint64) (*big.Int, error) {
+	return o.predictBaseFee(parent, futureTimestamp, parent.GasUsed, parent.ExtDataGasUsed)
+}
+
+// PredictBaseFeeRange returns bounds on the base fee of a block built at
+// [futureTimestamp] on top of [parent]. The optimistic bound assumes every
+// block until then is empty, and the pessimistic bound assumes every block
+// until then is full.
+func (o *GasOracle) PredictBaseFeeRange(parent *types.Header, futureTimestamp uint64) (optimistic, pessimistic *big.Int, err error) {
+	optimistic, err = o.predictBaseFee(parent, futureTimestamp, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules := o.config.OdysseyRules(parent.Number, futureTimestamp)
+	pessimistic, err = o.predictBaseFee(parent, futureTimestamp, parent.GasLimit, rules.AtomicGasLimit())
+	if err != nil {
+		return nil, nil, err
+	}
+	return optimistic, pessimistic, nil
+}
+
+// predictBaseFee builds the headers of the blocks produced between [parent]
+// and [futureTimestamp], each consuming [gasUsed] and [extDataGasUsed], and
+// returns the base fee of a block built at [futureTimestamp] on top of them.
+func (o *GasOracle) predictBaseFee(parent *types.Header, futureTimestamp uint64, gasUsed uint64, extDataGasUsed *big.Int) (*big.Int, error) {
+	var (
+		header          = parent
+		targetBlockRate = o.config.GetTargetBlockRate(futureTimestamp)
+	)
+	for header.Time+targetBlockRate < futureTimestamp {
+		timestamp := header.Time + targetBlockRate
+		extra, baseFee, err := dummy.CalcBaseFee(o.config, header, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		header = &types.Header{
+			Number:         new(big.Int).Add(header.Number, common.Big1),
+			GasLimit:       header.GasLimit,
+			GasUsed:        gasUsed,
+			Time:           timestamp,
+			Extra:          extra,
+			BaseFee:        baseFee,
+			ExtDataGasUsed: extDataGasUsed,
+			BlockGasCost:   header.BlockGasCost,
+		}
+	}
+	_, baseFee, err := dummy.EstimateNextBaseFee(o.config, header, futureTimestamp)
+	return baseFee, err
+}
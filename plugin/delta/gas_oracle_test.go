@@ -0,0 +1,105 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/coreth/consensus/dummy"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/stretchr/testify/require"
+)
+
+var gasOracleTestHorizons = []uint64{1, 5, 30}
+
+func newGasOracleTestParent() *types.Header {
+	return &types.Header{
+		Number:         big.NewInt(1),
+		GasLimit:       params.CortinaGasLimit,
+		GasUsed:        params.ApricotPhase5TargetGas,
+		Time:           1_000,
+		Extra:          make([]byte, params.ApricotPhase3ExtraDataSize),
+		BaseFee:        big.NewInt(params.ApricotPhase3InitialBaseFee),
+		ExtDataGasUsed: big.NewInt(0),
+		BlockGasCost:   big.NewInt(0),
+	}
+}
+
+func TestGasOraclePredictNextBaseFee(t *testing.T) {
+	require := require.New(t)
+
+	oracle := NewGasOracle(params.TestChainConfig)
+	parent := newGasOracleTestParent()
+	timestamp := parent.Time + dummy.ApricotPhase4TargetBlockRate
+
+	predicted, err := oracle.PredictBaseFee(parent, timestamp)
+	require.NoError(err)
+	_, expected, err := dummy.EstimateNextBaseFee(params.TestChainConfig, parent, timestamp)
+	require.NoError(err)
+	require.Equal(expected, predicted)
+}
+
+func TestGasOraclePredictBaseFeeRange(t *testing.T) {
+	for _, slots := range gasOracleTestHorizons {
+		t.Run(fmt.Sprintf("%d slots", slots), func(t *testing.T) {
+			require := require.New(t)
+
+			oracle := NewGasOracle(params.TestChainConfig)
+			parent := newGasOracleTestParent()
+			timestamp := parent.Time + slots*dummy.ApricotPhase4TargetBlockRate
+
+			predicted, err := oracle.PredictBaseFee(parent, timestamp)
+			require.NoError(err)
+			optimistic, pessimistic, err := oracle.PredictBaseFeeRange(parent, timestamp)
+			require.NoError(err)
+			require.LessOrEqual(optimistic.Cmp(predicted), 0)
+			require.GreaterOrEqual(pessimistic.Cmp(predicted), 0)
+			if slots > 1 {
+				require.Negative(optimistic.Cmp(pessimistic))
+			}
+		})
+	}
+}
+
+func TestGasOraclePredictBaseFeeInvalidParent(t *testing.T) {
+	oracle := NewGasOracle(params.TestChainConfig)
+	parent := newGasOracleTestParent()
+	parent.Extra = nil
+
+	_, err := oracle.PredictBaseFee(parent, parent.Time+5*dummy.ApricotPhase4TargetBlockRate)
+	require.Error(t, err)
+}
+
+func BenchmarkGasOraclePredictBaseFee(b *testing.B) {
+	oracle := NewGasOracle(params.TestChainConfig)
+	parent := newGasOracleTestParent()
+	for _, slots := range gasOracleTestHorizons {
+		timestamp := parent.Time + slots*dummy.ApricotPhase4TargetBlockRate
+		b.Run(fmt.Sprintf("%d slots", slots), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := oracle.PredictBaseFee(parent, timestamp); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGasOraclePredictBaseFeeRange(b *testing.B) {
+	oracle := NewGasOracle(params.TestChainConfig)
+	parent := newGasOracleTestParent()
+	for _, slots := range gasOracleTestHorizons {
+		timestamp := parent.Time + slots*dummy.ApricotPhase4TargetBlockRate
+		b.Run(fmt.Sprintf("%d slots", slots), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := oracle.PredictBaseFeeRange(parent, timestamp); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -25,10 +25,52 @@ type gasPriceSetter interface {
 	SetMinFee(price *big.Int)
 }
 
+// gasPriceStatus wraps a [gasPriceSetter] and records the last gas price and
+// minimum fee applied to it.
+type gasPriceStatus struct {
+	setter gasPriceSetter
+
+	lock          sync.RWMutex
+	price, minFee *big.Int
+}
+
+func (s *gasPriceStatus) SetGasPrice(price *big.Int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.setter.SetGasPrice(price)
+	s.price = price
+}
+
+func (s *gasPriceStatus) SetMinFee(minFee *big.Int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.setter.SetMinFee(minFee)
+	s.minFee = minFee
+}
+
+// Status returns copies of the last applied gas price and minimum fee. Either
+// value is nil if it has not been applied yet.
+func (s *gasPriceStatus) Status() (*big.Int, *big.Int) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var price, minFee *big.Int
+	if s.price != nil {
+		price = new(big.Int).Set(s.price)
+	}
+	if s.minFee != nil {
+		minFee = new(big.Int).Set(s.minFee)
+	}
+	return price, minFee
+}
+
 // handleGasPriceUpdates creates and runs an instance of
 func (vm *VM) handleGasPriceUpdates() {
+	vm.gasPriceStatus = &gasPriceStatus{setter: vm.txPool}
 	gpu := &gasPriceUpdater{
-		setter:       vm.txPool,
+		setter:       vm.gasPriceStatus,
 		chainConfig:  vm.chainConfig,
 		shutdownChan: vm.shutdownChan,
 		wg:           &vm.shutdownWg,
@@ -140,3 +140,46 @@ func TestUpdateGasPriceUpdatesPrice(t *testing.T) {
 		t.Fatalf("Expected min fee to match minimum fee for apricotPhase4, but found: %d", minFee)
 	}
 }
+
+func TestGasPriceStatusRecordsUpdates(t *testing.T) {
+	shutdownChan := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	config := *params.TestChainConfig
+	// Set ApricotPhase3BlockTime in the future so that the status reflects the
+	// ApricotPhase1 gas price until the update is applied.
+	config.ApricotPhase3BlockTimestamp = utils.TimeToNewUint64(time.Now().Add(2 * time.Second))
+	config.ApricotPhase4BlockTimestamp = utils.TimeToNewUint64(time.Now().Add(2 * time.Second))
+	setter := &mockGasPriceSetter{price: big.NewInt(1)}
+	status := &gasPriceStatus{setter: setter}
+	gpu := &gasPriceUpdater{
+		setter:       status,
+		chainConfig:  &config,
+		shutdownChan: shutdownChan,
+		wg:           wg,
+	}
+
+	gpu.start()
+
+	price, minFee := status.Status()
+	if price == nil || price.Cmp(big.NewInt(params.ApricotPhase1MinGasPrice)) != 0 {
+		t.Fatalf("Expected price to match minimum gas price for apricotPhase1, but found: %d", price)
+	}
+	if minFee != nil {
+		t.Fatalf("Expected min fee to be unset before apricotPhase3, but found: %d", minFee)
+	}
+
+	attemptAwait(t, wg, 5*time.Second)
+	price, minFee = status.Status()
+	if price == nil || price.Sign() != 0 {
+		t.Fatalf("Expected price to be zero as of apricotPhase3, but found: %d", price)
+	}
+	if minFee == nil || minFee.Cmp(big.NewInt(params.ApricotPhase4MinBaseFee)) != 0 {
+		t.Fatalf("Expected min fee to match minimum fee for apricotPhase4, but found: %d", minFee)
+	}
+
+	// The recorded values must match what was applied to the wrapped setter.
+	setterPrice, setterMinFee := setter.GetStatus()
+	if setterPrice.Cmp(price) != 0 || setterMinFee.Cmp(minFee) != 0 {
+		t.Fatalf("Expected status (%d, %d) to match applied values (%d, %d)", price, minFee, setterPrice, setterMinFee)
+	}
+}
@@ -99,15 +99,14 @@ func TestAtomicMempoolIterate(t *testing.T) {
 				require.NoError(m.Add(add))
 			}
 
+			// Iterate visits the txs in no particular order, so every tx is
+			// visited to collect the matches.
 			matches := make([]*GossipAtomicTx, 0)
 			f := func(tx *GossipAtomicTx) bool {
-				match := tt.f(tx)
-
-				if match {
+				if tt.f(tx) {
 					matches = append(matches, tx)
 				}
-
-				return match
+				return true
 			}
 
 			m.Iterate(f)
@@ -5,7 +5,11 @@ package delta
 
 import (
 	"container/heap"
+	"context"
+	"math"
 	"math/big"
+	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,6 +20,7 @@ import (
 	"github.com/DioneProtocol/odysseygo/cache"
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/snow"
+	"github.com/DioneProtocol/odysseygo/utils/set"
 	"github.com/DioneProtocol/odysseygo/utils/wrappers"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -74,6 +79,13 @@ type pushGossiper struct {
 	recentAtomicTxs *cache.LRU[ids.ID, interface{}]
 	recentEthTxs    *cache.LRU[common.Hash, interface{}]
 
+	// [validatorIDs] and [validatorWeights] cache the validator set that
+	// atomic txs are gossiped to, as of [validatorsUpdated].
+	validatorsLock    sync.Mutex
+	validatorIDs      []ids.NodeID
+	validatorWeights  []uint64
+	validatorsUpdated time.Time
+
 	codec codec.Manager
 	stats GossipSentStats
 }
@@ -98,6 +110,7 @@ func (vm *VM) createGossiper(stats GossipStats) Gossiper {
 		stats:              stats,
 	}
 	net.awaitEthTxGossip()
+	net.awaitAtomicTxRegossip()
 	return net
 }
 
@@ -267,15 +280,73 @@ func (n *pushGossiper) awaitEthTxGossip() {
 	})
 }
 
+// awaitAtomicTxRegossip periodically gossips the pending atomic txs to a fresh
+// sample of validators, so that they reach the network even if the validators
+// they were first gossiped to did not propagate them.
+func (n *pushGossiper) awaitAtomicTxRegossip() {
+	n.shutdownWg.Add(1)
+	go n.ctx.Log.RecoverAndPanic(func() {
+		regossipTicker := time.NewTicker(n.config.AtomicTxRegossipFrequency.Duration)
+		defer func() {
+			regossipTicker.Stop()
+			n.shutdownWg.Done()
+		}()
+
+		for {
+			select {
+			case <-regossipTicker.C:
+				if attempted, err := n.regossipAtomicTxs(); err != nil {
+					log.Warn(
+						"failed to regossip atomic transactions",
+						"len(txs)", attempted,
+						"err", err,
+					)
+				}
+			case <-n.shutdownChan:
+				return
+			}
+		}
+	})
+}
+
+// regossipAtomicTxs gossips every pending atomic tx to a fresh sample of
+// validators and returns the number of txs gossiped.
+func (n *pushGossiper) regossipAtomicTxs() (int, error) {
+	if n.config.DisableAtomicTxGossip {
+		return 0, nil
+	}
+	var txs []*Tx
+	n.atomicMempool.Iterate(func(tx *GossipAtomicTx) bool {
+		txs = append(txs, tx.Tx)
+		return true
+	})
+	if len(txs) == 0 {
+		return 0, nil
+	}
+
+	peers := n.atomicGossipPeers()
+	errs := wrappers.Errs{}
+	for _, tx := range txs {
+		errs.Add(n.sendAtomicTx(tx, peers))
+	}
+	return len(txs), errs.Err
+}
+
 func (n *pushGossiper) GossipAtomicTxs(txs []*Tx) error {
+	// Atomic txs are still accepted into the mempool, but are not announced
+	// to peers.
+	if n.config.DisableAtomicTxGossip {
+		return nil
+	}
+	peers := n.atomicGossipPeers()
 	errs := wrappers.Errs{}
 	for _, tx := range txs {
-		errs.Add(n.gossipAtomicTx(tx))
+		errs.Add(n.gossipAtomicTx(tx, peers))
 	}
 	return errs.Err
 }
 
-func (n *pushGossiper) gossipAtomicTx(tx *Tx) error {
+func (n *pushGossiper) gossipAtomicTx(tx *Tx, peers set.Set[ids.NodeID]) error {
 	txID := tx.ID()
 	// Don't gossip transaction if it has been recently gossiped.
 	if _, has := n.recentAtomicTxs.Get(txID); has {
@@ -287,7 +358,11 @@ func (n *pushGossiper) gossipAtomicTx(tx *Tx) error {
 		return nil
 	}
 	n.recentAtomicTxs.Put(txID, nil)
+	return n.sendAtomicTx(tx, peers)
+}
 
+// sendAtomicTx gossips [tx] to [peers], or to every peer if [peers] is empty.
+func (n *pushGossiper) sendAtomicTx(tx *Tx, peers set.Set[ids.NodeID]) error {
 	msg := message.AtomicTxGossip{
 		Tx: tx.SignedBytes(),
 	}
@@ -298,10 +373,102 @@ func (n *pushGossiper) gossipAtomicTx(tx *Tx) error {
 
 	log.Trace(
 		"gossiping atomic tx",
-		"txID", txID,
+		"txID", tx.ID(),
+		"peers", peers.Len(),
 	)
 	n.stats.IncAtomicGossipSent()
-	return n.client.Gossip(msgBytes)
+	if peers.Len() == 0 {
+		return n.client.Gossip(msgBytes)
+	}
+	return n.client.GossipSpecific(peers, msgBytes)
+}
+
+// atomicGossipPeers returns a stake-weighted sample of the validators, other
+// than this node, to gossip atomic txs to. The sample contains
+// [AtomicTxGossipFraction] of the validators, rounded up, but no more than
+// [AtomicTxGossipMaxPeers]. If the validator set is unavailable or has no other
+// validators, an empty set is returned so that atomic txs are gossiped to every
+// peer.
+func (n *pushGossiper) atomicGossipPeers() set.Set[ids.NodeID] {
+	nodeIDs, weights, err := n.getValidators()
+	if err != nil {
+		log.Debug("failed to get validator set, gossiping atomic txs to all peers", "err", err)
+		return nil
+	}
+	size := int(math.Ceil(n.config.AtomicTxGossipFraction * float64(len(nodeIDs))))
+	if size > n.config.AtomicTxGossipMaxPeers {
+		size = n.config.AtomicTxGossipMaxPeers
+	}
+	return sampleByWeight(nodeIDs, weights, size)
+}
+
+// getValidators returns the current validators other than this node and their
+// weights, refreshing the cached validator set once it is older than
+// [maxValidatorSetStaleness].
+func (n *pushGossiper) getValidators() ([]ids.NodeID, []uint64, error) {
+	n.validatorsLock.Lock()
+	defer n.validatorsLock.Unlock()
+
+	if time.Since(n.validatorsUpdated) < maxValidatorSetStaleness {
+		return n.validatorIDs, n.validatorWeights, nil
+	}
+
+	ctx := context.TODO()
+	height, err := n.ctx.ValidatorState.GetCurrentHeight(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	validatorSet, err := n.ctx.ValidatorState.GetValidatorSet(ctx, height, n.ctx.SubnetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n.validatorIDs = make([]ids.NodeID, 0, len(validatorSet))
+	n.validatorWeights = make([]uint64, 0, len(validatorSet))
+	for nodeID, validator := range validatorSet {
+		if nodeID == n.ctx.NodeID || validator == nil {
+			continue
+		}
+		n.validatorIDs = append(n.validatorIDs, nodeID)
+		n.validatorWeights = append(n.validatorWeights, validator.Weight)
+	}
+	n.validatorsUpdated = time.Now()
+	return n.validatorIDs, n.validatorWeights, nil
+}
+
+// sampleByWeight returns [size] distinct elements of [nodeIDs], sampled without
+// replacement with probability proportional to [weights]. Elements with no
+// weight are never sampled.
+func sampleByWeight(nodeIDs []ids.NodeID, weights []uint64, size int) set.Set[ids.NodeID] {
+	// Each element is assigned the key u^(1/weight) for a uniform random u in
+	// (0, 1], and the elements with the largest keys are sampled. The keys
+	// are compared as log(u)/weight to avoid underflow.
+	type keyedNodeID struct {
+		nodeID ids.NodeID
+		key    float64
+	}
+	keyed := make([]keyedNodeID, 0, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		if weights[i] == 0 {
+			continue
+		}
+		keyed = append(keyed, keyedNodeID{
+			nodeID: nodeID,
+			key:    math.Log(1-rand.Float64()) / float64(weights[i]),
+		})
+	}
+	sort.Slice(keyed, func(i, j int) bool {
+		return keyed[i].key > keyed[j].key
+	})
+	if size > len(keyed) {
+		size = len(keyed)
+	}
+
+	sampled := set.NewSet[ids.NodeID](size)
+	for _, k := range keyed[:size] {
+		sampled.Add(k.nodeID)
+	}
+	return sampled
 }
 
 func (n *pushGossiper) sendEthTxs(txs []*types.Transaction) error {
@@ -5,6 +5,8 @@ package delta
 
 import (
 	"context"
+	"encoding/json"
+	"math/big"
 	"os"
 	"sync"
 	"testing"
@@ -12,9 +14,16 @@ import (
 
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/snow"
+	engCommon "github.com/DioneProtocol/odysseygo/snow/engine/common"
+	"github.com/DioneProtocol/odysseygo/snow/validators"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
 	"github.com/DioneProtocol/odysseygo/utils/set"
+	"github.com/DioneProtocol/odysseygo/utils/units"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/params"
 	"github.com/DioneProtocol/coreth/plugin/delta/message"
 )
 
@@ -231,3 +240,262 @@ func TestMempoolAtmTxsAppGossipHandlingDiscardedTx(t *testing.T) {
 	assert.False(mempool.has(txID))
 	assert.True(mempool.has(conflictingTx.ID()))
 }
+
+// locally issued txs should not be gossiped if atomic tx gossip is disabled
+func TestMempoolAtmTxsIssueTxGossipDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	genesis := &core.Genesis{}
+	assert.NoError(json.Unmarshal([]byte(genesisJSONLatest), genesis))
+	genesis.Alloc[testEthAddrs[0]] = core.GenesisAccount{
+		Balance: new(big.Int).Mul(new(big.Int).SetUint64(units.MegaDione), x2cRate),
+	}
+	genesisJSON, err := json.Marshal(genesis)
+	assert.NoError(err)
+
+	_, vm, _, _, sender := GenesisVM(t, false, string(genesisJSON), `{"disable-atomic-tx-gossip":true}`, "")
+	defer func() {
+		assert.NoError(vm.Shutdown(context.Background()))
+	}()
+	assert.True(vm.config.DisableAtomicTxGossip)
+	assert.NoError(vm.Connected(context.Background(), ids.GenerateTestNodeID(), nil))
+
+	var gossiped int
+	var gossipedLock sync.Mutex // needed to prevent race
+	sender.CantSendAppGossip = false
+	sender.SendAppGossipF = func(_ context.Context, gossipedBytes []byte) error {
+		gossipedLock.Lock()
+		defer gossipedLock.Unlock()
+
+		notifyMsgIntf, err := message.ParseGossipMessage(vm.networkCodec, gossipedBytes)
+		assert.NoError(err)
+		if _, ok := notifyMsgIntf.(message.AtomicTxGossip); ok {
+			gossiped++
+		}
+		return nil
+	}
+
+	assert.NoError(vm.SetState(context.Background(), snow.Bootstrapping))
+	assert.NoError(vm.SetState(context.Background(), snow.NormalOp))
+
+	tx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	assert.NoError(err)
+
+	// The tx is accepted locally, but is not gossiped
+	assert.NoError(vm.issueTx(tx, true /*=local*/))
+	assert.True(vm.mempool.has(tx.ID()))
+	assert.NoError(vm.gossiper.GossipAtomicTxs([]*Tx{tx}))
+	time.Sleep(500 * time.Millisecond)
+	gossipedLock.Lock()
+	assert.Zero(gossiped)
+	gossipedLock.Unlock()
+}
+
+func TestSampleByWeight(t *testing.T) {
+	require := require.New(t)
+
+	nodeIDs := make([]ids.NodeID, 10)
+	weights := make([]uint64, len(nodeIDs))
+	for i := range nodeIDs {
+		nodeIDs[i] = ids.GenerateTestNodeID()
+		weights[i] = 1
+	}
+	// The first node holds almost all of the stake and the last none.
+	weights[0] = 1_000_000
+	weights[len(weights)-1] = 0
+
+	for _, size := range []int{0, 1, 5, 9, 20} {
+		sampled := sampleByWeight(nodeIDs, weights, size)
+		expectedSize := size
+		if expectedSize > len(nodeIDs)-1 {
+			expectedSize = len(nodeIDs) - 1
+		}
+		require.Equal(expectedSize, sampled.Len(), "size %d", size)
+		require.False(sampled.Contains(nodeIDs[len(nodeIDs)-1]))
+	}
+
+	heavySampled := 0
+	for i := 0; i < 100; i++ {
+		sampled := sampleByWeight(nodeIDs, weights, 1)
+		if sampled.Contains(nodeIDs[0]) {
+			heavySampled++
+		}
+	}
+	require.Greater(heavySampled, 95)
+}
+
+// newAtomicGossipTestVM returns a VM whose validator set holds [numValidators]
+// equally weighted validators in addition to the VM itself, along with the
+// validators and a pending import tx. The VM fails the test if it gossips to
+// every peer.
+func newAtomicGossipTestVM(t *testing.T, configJSON string, numValidators int) (*VM, *engCommon.SenderTest, []ids.NodeID, *Tx) {
+	require := require.New(t)
+
+	_, vm, _, _, sender := GenesisVMWithUTXOs(t, false, genesisJSONLatest, configJSON, "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+	})
+	t.Cleanup(func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	})
+
+	nodeIDs := make([]ids.NodeID, numValidators)
+	validatorSet := map[ids.NodeID]*validators.GetValidatorOutput{
+		vm.ctx.NodeID: {NodeID: vm.ctx.NodeID, Weight: 1},
+	}
+	for i := range nodeIDs {
+		nodeIDs[i] = ids.GenerateTestNodeID()
+		validatorSet[nodeIDs[i]] = &validators.GetValidatorOutput{NodeID: nodeIDs[i], Weight: 1}
+	}
+	validatorState := vm.ctx.ValidatorState.(*validators.TestState)
+	validatorState.GetCurrentHeightF = func(context.Context) (uint64, error) {
+		return 1, nil
+	}
+	validatorState.GetValidatorSetF = func(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+		return validatorSet, nil
+	}
+
+	sender.CantSendAppGossip = false
+	sender.SendAppGossipF = func(context.Context, []byte) error {
+		t.Error("unexpected gossip to every peer")
+		return nil
+	}
+	require.NoError(vm.SetState(context.Background(), snow.NormalOp))
+
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, true)
+	require.NoError(err)
+	return vm, sender, nodeIDs, importTx
+}
+
+func TestAtomicTxGossipStakeWeightedSubset(t *testing.T) {
+	tests := []struct {
+		name          string
+		configJSON    string
+		numValidators int
+		expectedPeers int
+	}{
+		{
+			name:          "default fraction",
+			numValidators: 40,
+			expectedPeers: 4,
+		},
+		{
+			name:          "fraction rounded up",
+			configJSON:    `{"atomic-tx-gossip-fraction": 0.25}`,
+			numValidators: 5,
+			expectedPeers: 2,
+		},
+		{
+			name:          "capped",
+			configJSON:    `{"atomic-tx-gossip-max-peers": 3}`,
+			numValidators: 100,
+			expectedPeers: 3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			vm, sender, nodeIDs, importTx := newAtomicGossipTestVM(t, test.configJSON, test.numValidators)
+			validatorIDs := set.Of(nodeIDs...)
+
+			var (
+				lock       sync.Mutex
+				recipients []set.Set[ids.NodeID]
+			)
+			sender.SendAppGossipSpecificF = func(_ context.Context, nodeIDs set.Set[ids.NodeID], _ []byte) error {
+				lock.Lock()
+				defer lock.Unlock()
+				recipients = append(recipients, nodeIDs)
+				return nil
+			}
+
+			require.NoError(vm.issueTx(importTx, true /*=local*/))
+			require.Eventually(func() bool {
+				lock.Lock()
+				defer lock.Unlock()
+				return len(recipients) == 1
+			}, 5*time.Second, 10*time.Millisecond)
+
+			// Every regossip cycle sends the pending tx to a fresh subset of
+			// the other validators, until every validator has been reached.
+			gossiper := vm.gossiper.(*pushGossiper)
+			covered := set.Set[ids.NodeID]{}
+			for i := 0; i < 1_000 && covered.Len() < len(nodeIDs); i++ {
+				attempted, err := gossiper.regossipAtomicTxs()
+				require.NoError(err)
+				require.Equal(1, attempted)
+
+				lock.Lock()
+				for _, peers := range recipients {
+					require.Equal(test.expectedPeers, peers.Len())
+					require.False(peers.Contains(vm.ctx.NodeID))
+					for nodeID := range peers {
+						require.True(validatorIDs.Contains(nodeID))
+					}
+					covered.Union(peers)
+				}
+				recipients = nil
+				lock.Unlock()
+			}
+			require.Equal(len(nodeIDs), covered.Len())
+		})
+	}
+}
+
+func TestAtomicTxRegossipSkipsRemovedTxs(t *testing.T) {
+	require := require.New(t)
+
+	vm, sender, _, importTx := newAtomicGossipTestVM(t, "", 10)
+	sender.SendAppGossipSpecificF = func(context.Context, set.Set[ids.NodeID], []byte) error {
+		return nil
+	}
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+
+	gossiper := vm.gossiper.(*pushGossiper)
+	attempted, err := gossiper.regossipAtomicTxs()
+	require.NoError(err)
+	require.Equal(1, attempted)
+
+	// Txs that are no longer pending are not regossiped.
+	vm.mempool.RemoveTx(importTx)
+	attempted, err = gossiper.regossipAtomicTxs()
+	require.NoError(err)
+	require.Zero(attempted)
+}
+
+func TestReissueAtomicTxWithoutGossip(t *testing.T) {
+	require := require.New(t)
+
+	vm, sender, _, importTx := newAtomicGossipTestVM(t, `{"reissue-atomic-txs-without-gossip": true}`, 10)
+	require.True(vm.config.ReissueAtomicTxsWithoutGossip)
+	sender.SendAppGossipSpecificF = func(context.Context, set.Set[ids.NodeID], []byte) error {
+		t.Error("unexpected gossip")
+		return nil
+	}
+	genesisBlkID, err := vm.LastAccepted(context.Background())
+	require.NoError(err)
+
+	require.NoError(vm.issueTxWithoutGossip(importTx))
+	require.True(vm.mempool.has(importTx.ID()))
+	require.Empty(vm.mempool.GetNewTxs())
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(vm.SetPreference(context.Background(), genesisBlkID))
+
+	// Rejecting [blk] re-issues [importTx] to the mempool without gossip.
+	require.NoError(blk.Reject(context.Background()))
+	require.True(vm.mempool.has(importTx.ID()))
+	require.Empty(vm.mempool.GetNewTxs())
+
+	gossiper := vm.gossiper.(*pushGossiper)
+	attempted, err := gossiper.regossipAtomicTxs()
+	require.NoError(err)
+	require.Zero(attempted)
+
+	// The tx is still issued into the next block.
+	_, ok := vm.mempool.NextTx()
+	require.True(ok)
+}
@@ -3,7 +3,14 @@
 
 package delta
 
-import "context"
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var errAtomicBackendNotInitialized = errors.New("atomic backend is not initialized")
 
 // Health returns nil if this chain is healthy.
 // Also returns details, which should be one of:
@@ -12,3 +19,45 @@ func (vm *VM) HealthCheck(context.Context) (interface{}, error) {
 	// TODO perform actual health check
 	return nil, nil
 }
+
+// AtomicHealth describes whether the atomic trie is consistent with the last
+// accepted block.
+type AtomicHealth struct {
+	AtomicBackendStatus
+
+	LastAcceptedHeight uint64
+	LastAcceptedBlock  common.Hash
+	// ExpectedCommittedHeight is the height at which the atomic trie must
+	// have last been committed, given the last accepted height.
+	ExpectedCommittedHeight uint64
+	// CommittedHeightMatches is true if the atomic trie was last committed at
+	// [ExpectedCommittedHeight].
+	CommittedHeightMatches bool
+	// LastAcceptedMatches is true if the last block accepted by the atomic
+	// backend is the last accepted block.
+	LastAcceptedMatches bool
+}
+
+// AtomicHealth returns the state of the atomic trie and of the atomic states
+// of the blocks that are verified but not yet decided, compared against the
+// last accepted block.
+func (vm *VM) AtomicHealth() (*AtomicHealth, error) {
+	if vm.atomicBackend == nil {
+		return nil, errAtomicBackendNotInitialized
+	}
+	var (
+		status       = vm.atomicBackend.Status()
+		lastAccepted = vm.blockChain.LastConsensusAcceptedBlock()
+		health       = &AtomicHealth{
+			AtomicBackendStatus: status,
+			LastAcceptedHeight:  lastAccepted.NumberU64(),
+			LastAcceptedBlock:   lastAccepted.Hash(),
+		}
+	)
+	if status.CommitInterval > 0 {
+		health.ExpectedCommittedHeight = nearestCommitHeight(health.LastAcceptedHeight, status.CommitInterval)
+	}
+	health.CommittedHeightMatches = status.LastCommittedHeight == health.ExpectedCommittedHeight
+	health.LastAcceptedMatches = status.LastAcceptedHash == health.LastAcceptedBlock
+	return health, nil
+}
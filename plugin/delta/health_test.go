@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/chains/atomic"
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/database/versiondb"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/params"
+)
+
+func TestAtomicHealth(t *testing.T) {
+	require := require.New(t)
+
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	reply := &AtomicHealthReply{}
+	require.NoError(service.AtomicHealth(nil, nil, reply))
+	require.True(reply.CommittedHeightMatches)
+	require.True(reply.LastAcceptedMatches)
+	require.Zero(reply.LastAcceptedHeight)
+	require.Empty(reply.Verified)
+
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+
+	// The atomic state of the verified block is pending until it is decided.
+	reply = &AtomicHealthReply{}
+	require.NoError(service.AtomicHealth(nil, nil, reply))
+	require.True(reply.CommittedHeightMatches)
+	require.True(reply.LastAcceptedMatches)
+	require.Len(reply.Verified, 1)
+	require.Equal(common.Hash(blk.ID()), reply.Verified[0].BlockHash)
+	require.Equal(uint64(1), uint64(reply.Verified[0].BlockHeight))
+	require.Equal(uint32(1), uint32(reply.Verified[0].NumTxs))
+
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	reply = &AtomicHealthReply{}
+	require.NoError(service.AtomicHealth(nil, nil, reply))
+	require.True(reply.CommittedHeightMatches)
+	require.True(reply.LastAcceptedMatches)
+	require.Equal(uint64(1), uint64(reply.LastAcceptedHeight))
+	require.Equal(common.Hash(blk.ID()), reply.LastAcceptedBlock)
+	require.Equal(reply.LastAcceptedBlock, reply.LastAcceptedAtomicBlock)
+	require.Equal(vm.atomicTrie.LastAcceptedRoot(), reply.LastAcceptedRoot)
+	require.Empty(reply.Verified)
+
+	// A divergence of the atomic backend from the chain is reported.
+	vm.atomicBackend.SetLastAccepted(common.Hash{1})
+	health, err := vm.AtomicHealth()
+	require.NoError(err)
+	require.False(health.LastAcceptedMatches)
+	require.True(health.CommittedHeightMatches)
+}
+
+func TestAtomicBackendStatus(t *testing.T) {
+	require := require.New(t)
+
+	lastAcceptedHeight := uint64(25)
+	db := versiondb.New(memdb.New())
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight, nil, nil, nil)
+	require.NoError(err)
+	writeTxs(t, repo, 1, lastAcceptedHeight+1, constTxsPerHeight(2), nil, make(map[uint64]map[ids.ID]*atomic.Requests))
+
+	lastAcceptedHash := common.Hash{1}
+	atomicBackend, err := NewAtomicBackend(db, testSharedMemory(), nil, repo, lastAcceptedHeight, lastAcceptedHash, 10)
+	require.NoError(err)
+
+	status := atomicBackend.Status()
+	require.Equal(uint64(20), status.LastCommittedHeight)
+	require.Equal(uint64(10), status.CommitInterval)
+	require.Equal(lastAcceptedHash, status.LastAcceptedHash)
+	require.Equal(atomicBackend.AtomicTrie().LastAcceptedRoot(), status.LastAcceptedRoot)
+	require.Empty(status.Verified)
+
+	// Verified blocks are reported in height order until they are decided.
+	blockHashes := []common.Hash{{2}, {3}}
+	parentHash := lastAcceptedHash
+	for i, blockHash := range blockHashes {
+		_, err := atomicBackend.InsertTxs(blockHash, lastAcceptedHeight+1+uint64(i), parentHash, newTestTxs(1))
+		require.NoError(err)
+		parentHash = blockHash
+	}
+	status = atomicBackend.Status()
+	require.Len(status.Verified, 2)
+	for i, blockHash := range blockHashes {
+		state, err := atomicBackend.GetVerifiedAtomicState(blockHash)
+		require.NoError(err)
+		require.Equal(VerifiedAtomicState{
+			BlockHash:   blockHash,
+			BlockHeight: lastAcceptedHeight + 1 + uint64(i),
+			Root:        state.Root(),
+			NumTxs:      1,
+		}, status.Verified[i])
+	}
+}
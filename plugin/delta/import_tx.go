@@ -33,6 +33,8 @@ var (
 	_                            secp256k1fx.UnsignedTx = &UnsignedImportTx{}
 	errImportNonDIONEInputBanff                         = errors.New("import input cannot contain non-DIONE in Banff")
 	errImportNonDIONEOutputBanff                        = errors.New("import output cannot contain non-DIONE in Banff")
+	errFetchImportUTXOs                                 = errors.New("failed to fetch import UTXOs")
+	errInsufficientFundsToSplit                         = errors.New("insufficient funds to split import tx by asset")
 )
 
 // UnsignedImportTx is an unsigned ImportTx
@@ -177,6 +179,52 @@ func (utx *UnsignedImportTx) Burned(assetID ids.ID) (uint64, error) {
 	return math.Sub(input, spent)
 }
 
+// SplitByAsset returns one UnsignedImportTx per unique asset ID in
+// [utx.ImportedInputs], in the order the assets first appear. Each returned tx
+// contains only the inputs and outputs of its asset, so the amount of each
+// asset burned by the returned txs sums to the amount burned by [utx].
+// The returned txs are not initialized.
+// Returns errInsufficientFundsToSplit if any returned tx would produce more of
+// its asset than it consumes, or if [utx] has outputs of an asset it does not
+// import.
+func (utx *UnsignedImportTx) SplitByAsset() ([]*UnsignedImportTx, error) {
+	var (
+		assetIDs []ids.ID
+		splits   = make(map[ids.ID]*UnsignedImportTx)
+	)
+	for _, in := range utx.ImportedInputs {
+		assetID := in.AssetID()
+		split, ok := splits[assetID]
+		if !ok {
+			split = &UnsignedImportTx{
+				NetworkID:    utx.NetworkID,
+				BlockchainID: utx.BlockchainID,
+				SourceChain:  utx.SourceChain,
+			}
+			splits[assetID] = split
+			assetIDs = append(assetIDs, assetID)
+		}
+		split.ImportedInputs = append(split.ImportedInputs, in)
+	}
+	for _, out := range utx.Outs {
+		split, ok := splits[out.AssetID]
+		if !ok {
+			return nil, fmt.Errorf("%w: output of asset %s is not imported", errInsufficientFundsToSplit, out.AssetID)
+		}
+		split.Outs = append(split.Outs, out)
+	}
+
+	result := make([]*UnsignedImportTx, len(assetIDs))
+	for i, assetID := range assetIDs {
+		split := splits[assetID]
+		if _, err := split.Burned(assetID); err != nil {
+			return nil, fmt.Errorf("%w: asset %s: %w", errInsufficientFundsToSplit, assetID, err)
+		}
+		result[i] = split
+	}
+	return result, nil
+}
+
 // SemanticVerify this transaction is valid.
 func (utx *UnsignedImportTx) SemanticVerify(
 	vm *VM,
@@ -236,7 +284,7 @@ func (utx *UnsignedImportTx) SemanticVerify(
 	// allUTXOBytes is guaranteed to be the same length as utxoIDs
 	allUTXOBytes, err := vm.ctx.SharedMemory.Get(utx.SourceChain, utxoIDs)
 	if err != nil {
-		return fmt.Errorf("failed to fetch import UTXOs from %s due to: %w", utx.SourceChain, err)
+		return fmt.Errorf("%w from %s due to: %w", errFetchImportUTXOs, utx.SourceChain, err)
 	}
 
 	for i, in := range utx.ImportedInputs {
@@ -283,6 +331,21 @@ func (vm *VM) newImportTx(
 	to common.Address, // Address of recipient
 	baseFee *big.Int, // fee to use post-AP3
 	keys []*secp256k1.PrivateKey, // Keys to import the funds
+	allowHighFee bool, // Skip the atomic tx fee limits
+) (*Tx, error) {
+	return vm.newImportTxToRecipients(chainID, to, nil, baseFee, keys, allowHighFee)
+}
+
+// newImportTxToRecipients returns a new ImportTx that credits each imported
+// asset to its address in [recipients], or to [to] if the asset has no
+// recipient. This allows a single tx to import funds into several accounts.
+func (vm *VM) newImportTxToRecipients(
+	chainID ids.ID, // chain to import from
+	to common.Address, // Address of recipient of assets not in [recipients]
+	recipients map[ids.ID]common.Address, // Address of recipient of each asset
+	baseFee *big.Int, // fee to use post-AP3
+	keys []*secp256k1.PrivateKey, // Keys to import the funds
+	allowHighFee bool, // Skip the atomic tx fee limits
 ) (*Tx, error) {
 	kc := secp256k1fx.NewKeychain()
 	for _, key := range keys {
@@ -294,7 +357,7 @@ func (vm *VM) newImportTx(
 		return nil, fmt.Errorf("problem retrieving atomic UTXOs: %w", err)
 	}
 
-	return vm.newImportTxWithUTXOs(chainID, to, baseFee, kc, atomicUTXOs)
+	return vm.newImportTxWithUTXOsToRecipients(chainID, to, recipients, baseFee, kc, atomicUTXOs, allowHighFee)
 }
 
 // newImportTx returns a new ImportTx
@@ -304,25 +367,333 @@ func (vm *VM) newImportTxWithUTXOs(
 	baseFee *big.Int, // fee to use post-AP3
 	kc *secp256k1fx.Keychain, // Keychain to use for signing the atomic UTXOs
 	atomicUTXOs []*dione.UTXO, // UTXOs to spend
+	allowHighFee bool, // Skip the atomic tx fee limits
+) (*Tx, error) {
+	return vm.newImportTxWithUTXOsToRecipients(chainID, to, nil, baseFee, kc, atomicUTXOs, allowHighFee)
+}
+
+// newImportTxWithUTXOsToRecipients returns a new ImportTx spending
+// [atomicUTXOs] that credits each imported asset to its address in
+// [recipients], or to [to] if the asset has no recipient.
+func (vm *VM) newImportTxWithUTXOsToRecipients(
+	chainID ids.ID, // chain to import from
+	to common.Address, // Address of recipient of assets not in [recipients]
+	recipients map[ids.ID]common.Address, // Address of recipient of each asset
+	baseFee *big.Int, // fee to use post-AP3
+	kc *secp256k1fx.Keychain, // Keychain to use for signing the atomic UTXOs
+	atomicUTXOs []*dione.UTXO, // UTXOs to spend
+	allowHighFee bool, // Skip the atomic tx fee limits
+) (*Tx, error) {
+	recipient := func(assetID ids.ID) common.Address {
+		if addr, ok := recipients[assetID]; ok {
+			return addr
+		}
+		return to
+	}
+
+	importedInputs, signers, importedAmount, err := vm.importedInputs(kc, atomicUTXOs)
+	if err != nil {
+		return nil, err
+	}
+	importedDIONEAmount := importedAmount[vm.ctx.DIONEAssetID]
+
+	outs := make([]DELTAOutput, 0, len(importedAmount))
+	// This will create unique outputs (in the context of sorting)
+	// since each output will have a unique assetID
+	for assetID, amount := range importedAmount {
+		// Skip the DIONE amount since it is included separately to account for
+		// the fee
+		if assetID == vm.ctx.DIONEAssetID || amount == 0 {
+			continue
+		}
+		outs = append(outs, DELTAOutput{
+			Address: recipient(assetID),
+			Amount:  amount,
+			AssetID: assetID,
+		})
+	}
+
+	txFeeWithoutChange, txFeeWithChange, err := vm.importTxFees(chainID, outs, importedInputs, baseFee)
+	if err != nil {
+		return nil, err
+	}
+
+	// DIONE output
+	if importedDIONEAmount < txFeeWithoutChange { // imported amount goes toward paying tx fee
+		return nil, errInsufficientFundsForFee
+	}
+
+	if importedDIONEAmount > txFeeWithChange {
+		outs = append(outs, DELTAOutput{
+			Address: recipient(vm.ctx.DIONEAssetID),
+			Amount:  importedDIONEAmount - txFeeWithChange,
+			AssetID: vm.ctx.DIONEAssetID,
+		})
+	}
+
+	// If no outputs are produced, return an error.
+	// Note: this can happen if there is exactly enough DIONE to pay the
+	// transaction fee, but no other funds to be imported.
+	if len(outs) == 0 {
+		return nil, errNoDELTAOutputs
+	}
+
+	SortDELTAOutputs(outs)
+
+	// Create the transaction
+	utx := &UnsignedImportTx{
+		NetworkID:      vm.ctx.NetworkID,
+		BlockchainID:   vm.ctx.ChainID,
+		Outs:           outs,
+		ImportedInputs: importedInputs,
+		SourceChain:    chainID,
+	}
+	if !allowHighFee {
+		fee, err := utx.Burned(vm.ctx.DIONEAssetID)
+		if err != nil {
+			return nil, err
+		}
+		if err := vm.verifyAtomicTxFee(fee, importedDIONEAmount); err != nil {
+			return nil, err
+		}
+	}
+	tx := &Tx{UnsignedAtomicTx: utx}
+	if err := tx.Sign(vm.codec, signers); err != nil {
+		return nil, err
+	}
+	return tx, utx.Verify(vm.ctx, vm.currentRules())
+}
+
+// EstimateImportCost returns the DIONE fee an import of [utxos] from [chainID]
+// to [to] would burn at [baseFee], without signing or issuing the tx. Each UTXO
+// is assumed to be spent with as few signatures as its owners require, and
+// UTXOs that cannot be imported now are skipped, as when the tx is built. If
+// the imported DIONE does not cover the fee, the returned error wraps
+// errInsufficientFundsForFee and names the shortfall.
+func (vm *VM) EstimateImportCost(chainID ids.ID, utxos []*dione.UTXO, to common.Address, baseFee *big.Int) (uint64, error) {
+	var (
+		importedInputs []*dione.TransferableInput
+		importedAmount = make(map[ids.ID]uint64)
+		now            = vm.clock.Unix()
+	)
+	for _, utxo := range utxos {
+		out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok || out.Locktime > now {
+			continue
+		}
+		sigIndices := make([]uint32, out.Threshold)
+		for i := range sigIndices {
+			sigIndices[i] = uint32(i)
+		}
+		aid := utxo.AssetID()
+		amount, err := math.Add64(importedAmount[aid], out.Amt)
+		if err != nil {
+			return 0, err
+		}
+		importedAmount[aid] = amount
+		importedInputs = append(importedInputs, &dione.TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  utxo.Asset,
+			In: &secp256k1fx.TransferInput{
+				Amt:   out.Amt,
+				Input: secp256k1fx.Input{SigIndices: sigIndices},
+			},
+		})
+	}
+
+	outs := make([]DELTAOutput, 0, len(importedAmount))
+	for assetID, amount := range importedAmount {
+		if assetID == vm.ctx.DIONEAssetID || amount == 0 {
+			continue
+		}
+		outs = append(outs, DELTAOutput{
+			Address: to,
+			Amount:  amount,
+			AssetID: assetID,
+		})
+	}
+	txFeeWithoutChange, txFeeWithChange, err := vm.importTxFees(chainID, outs, importedInputs, baseFee)
+	if err != nil {
+		return 0, err
+	}
+
+	importedDIONEAmount := importedAmount[vm.ctx.DIONEAssetID]
+	switch {
+	case importedDIONEAmount < txFeeWithoutChange:
+		return 0, fmt.Errorf("%w: fee %d nDIONE exceeds imported %d nDIONE by %d nDIONE",
+			errInsufficientFundsForFee, txFeeWithoutChange, importedDIONEAmount, txFeeWithoutChange-importedDIONEAmount)
+	case importedDIONEAmount > txFeeWithChange:
+		return txFeeWithChange, nil
+	default:
+		// The DIONE left once the fee is paid does not cover the gas of its
+		// output, so the tx burns all of it.
+		return importedDIONEAmount, nil
+	}
+}
+
+// newImportTxSplit returns a new ImportTx that credits each of [outputs] with
+// its amount of its asset, and credits what is left of each imported asset
+// once the fee is paid to [changeAddr]. [outputs] must be sorted and unique.
+func (vm *VM) newImportTxSplit(
+	chainID ids.ID, // chain to import from
+	outputs []DELTAOutput, // Outputs to credit
+	changeAddr common.Address, // Address of recipient of the leftover funds
+	baseFee *big.Int, // fee to use post-AP3
+	keys []*secp256k1.PrivateKey, // Keys to import the funds
+	allowHighFee bool, // Skip the atomic tx fee limits
+) (*Tx, error) {
+	kc := secp256k1fx.NewKeychain()
+	for _, key := range keys {
+		kc.Add(key)
+	}
+
+	atomicUTXOs, _, _, err := vm.GetAtomicUTXOs(chainID, kc.Addresses(), ids.ShortEmpty, ids.Empty, -1)
+	if err != nil {
+		return nil, fmt.Errorf("problem retrieving atomic UTXOs: %w", err)
+	}
+
+	return vm.newImportTxWithUTXOsSplit(chainID, outputs, changeAddr, baseFee, kc, atomicUTXOs, allowHighFee)
+}
+
+// newImportTxWithUTXOsSplit returns a new ImportTx spending [atomicUTXOs] that
+// credits each of [outputs] and credits the leftover funds to [changeAddr].
+func (vm *VM) newImportTxWithUTXOsSplit(
+	chainID ids.ID, // chain to import from
+	outputs []DELTAOutput, // Outputs to credit
+	changeAddr common.Address, // Address of recipient of the leftover funds
+	baseFee *big.Int, // fee to use post-AP3
+	kc *secp256k1fx.Keychain, // Keychain to use for signing the atomic UTXOs
+	atomicUTXOs []*dione.UTXO, // UTXOs to spend
+	allowHighFee bool, // Skip the atomic tx fee limits
 ) (*Tx, error) {
+	if len(outputs) == 0 {
+		return nil, errNoDELTAOutputs
+	}
+	if !utils.IsSortedAndUnique(outputs) {
+		return nil, errOutputsNotSortedUnique
+	}
+	requestedAmount := make(map[ids.ID]uint64)
+	for _, out := range outputs {
+		if err := out.Verify(); err != nil {
+			return nil, fmt.Errorf("DELTA Output failed verification: %w", err)
+		}
+		var err error
+		requestedAmount[out.AssetID], err = math.Add64(requestedAmount[out.AssetID], out.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	importedInputs, signers, importedAmount, err := vm.importedInputs(kc, atomicUTXOs)
+	if err != nil {
+		return nil, err
+	}
+	for assetID, amount := range requestedAmount {
+		if importedAmount[assetID] < amount {
+			return nil, fmt.Errorf("%w: requested %d of asset %s, but only %d can be imported", errInsufficientFunds, amount, assetID, importedAmount[assetID])
+		}
+	}
+
+	outs := append(make([]DELTAOutput, 0, len(outputs)+len(importedAmount)), outputs...)
+	// addChange credits [amount] of [assetID] to [changeAddr], merging it into
+	// the output already crediting it, if any, so that the outputs remain
+	// unique. It returns true if a new output was added.
+	addChange := func(assetID ids.ID, amount uint64) bool {
+		for i := range outs {
+			if outs[i].Address == changeAddr && outs[i].AssetID == assetID {
+				outs[i].Amount += amount
+				return false
+			}
+		}
+		outs = append(outs, DELTAOutput{
+			Address: changeAddr,
+			Amount:  amount,
+			AssetID: assetID,
+		})
+		return true
+	}
+	for assetID, amount := range importedAmount {
+		// The DIONE change is added separately to account for the fee
+		if assetID == vm.ctx.DIONEAssetID || amount == requestedAmount[assetID] {
+			continue
+		}
+		addChange(assetID, amount-requestedAmount[assetID])
+	}
+
+	txFeeWithoutChange, txFeeWithChange, err := vm.importTxFees(chainID, outs, importedInputs, baseFee)
+	if err != nil {
+		return nil, err
+	}
+	for _, out := range outs {
+		if out.Address == changeAddr && out.AssetID == vm.ctx.DIONEAssetID {
+			// The DIONE change is merged into an existing output.
+			txFeeWithChange = txFeeWithoutChange
+			break
+		}
+	}
+
+	// DIONE change
+	importedDIONEAmount := importedAmount[vm.ctx.DIONEAssetID]
+	dioneLeft := importedDIONEAmount - requestedAmount[vm.ctx.DIONEAssetID]
+	if dioneLeft < txFeeWithoutChange { // leftover amount goes toward paying tx fee
+		return nil, errInsufficientFundsForFee
+	}
+	if dioneLeft > txFeeWithChange {
+		addChange(vm.ctx.DIONEAssetID, dioneLeft-txFeeWithChange)
+	}
+	SortDELTAOutputs(outs)
+
+	// Create the transaction
+	utx := &UnsignedImportTx{
+		NetworkID:      vm.ctx.NetworkID,
+		BlockchainID:   vm.ctx.ChainID,
+		Outs:           outs,
+		ImportedInputs: importedInputs,
+		SourceChain:    chainID,
+	}
+	if !allowHighFee {
+		fee, err := utx.Burned(vm.ctx.DIONEAssetID)
+		if err != nil {
+			return nil, err
+		}
+		if err := vm.verifyAtomicTxFee(fee, importedDIONEAmount); err != nil {
+			return nil, err
+		}
+	}
+	tx := &Tx{UnsignedAtomicTx: utx}
+	if err := tx.Sign(vm.codec, signers); err != nil {
+		return nil, err
+	}
+	return tx, utx.Verify(vm.ctx, vm.currentRules())
+}
+
+// importedInputs returns the sorted inputs spending the [atomicUTXOs] that [kc]
+// can spend, the keys signing each of them and the amount of each asset they
+// import.
+func (vm *VM) importedInputs(kc *secp256k1fx.Keychain, atomicUTXOs []*dione.UTXO) ([]*dione.TransferableInput, [][]*secp256k1.PrivateKey, map[ids.ID]uint64, error) {
 	importedInputs := []*dione.TransferableInput{}
 	signers := [][]*secp256k1.PrivateKey{}
 
 	importedAmount := make(map[ids.ID]uint64)
 	now := vm.clock.Unix()
 	for _, utxo := range atomicUTXOs {
+		// [kc] signs with as many of its keys as the threshold of the UTXO's
+		// owners requires, so multisig UTXOs can be spent if enough of the
+		// owners' keys are held.
 		inputIntf, utxoSigners, err := kc.Spend(utxo.Out, now)
 		if err != nil {
+			log.Debug("skipping UTXO the keychain cannot spend", "utxoID", utxo.InputID(), "err", err)
 			continue
 		}
 		input, ok := inputIntf.(dione.TransferableIn)
 		if !ok {
+			log.Debug("skipping UTXO with an unexpected input type", "utxoID", utxo.InputID(), "type", fmt.Sprintf("%T", inputIntf))
 			continue
 		}
 		aid := utxo.AssetID()
 		importedAmount[aid], err = math.Add64(importedAmount[aid], input.Amount())
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 		importedInputs = append(importedInputs, &dione.TransferableInput{
 			UTXOID: utxo.UTXOID,
@@ -332,34 +703,18 @@ func (vm *VM) newImportTxWithUTXOs(
 		signers = append(signers, utxoSigners)
 	}
 	dione.SortTransferableInputsWithSigners(importedInputs, signers)
-	importedDIONEAmount := importedAmount[vm.ctx.DIONEAssetID]
-
-	outs := make([]DELTAOutput, 0, len(importedAmount))
-	// This will create unique outputs (in the context of sorting)
-	// since each output will have a unique assetID
-	for assetID, amount := range importedAmount {
-		// Skip the DIONE amount since it is included separately to account for
-		// the fee
-		if assetID == vm.ctx.DIONEAssetID || amount == 0 {
-			continue
-		}
-		outs = append(outs, DELTAOutput{
-			Address: to,
-			Amount:  amount,
-			AssetID: assetID,
-		})
-	}
+	return importedInputs, signers, importedAmount, nil
+}
 
+// importTxFees returns the DIONE fee of an import tx from [chainID] spending
+// [importedInputs] into [outs], without and with an additional DIONE change
+// output, under the current rules.
+func (vm *VM) importTxFees(chainID ids.ID, outs []DELTAOutput, importedInputs []*dione.TransferableInput, baseFee *big.Int) (txFeeWithoutChange, txFeeWithChange uint64, err error) {
 	rules := vm.currentRules()
-
-	var (
-		txFeeWithoutChange uint64
-		txFeeWithChange    uint64
-	)
 	switch {
 	case rules.IsApricotPhase3:
 		if baseFee == nil {
-			return nil, errNilBaseFeeApricotPhase3
+			return 0, 0, errNilBaseFeeApricotPhase3
 		}
 		utx := &UnsignedImportTx{
 			NetworkID:      vm.ctx.NetworkID,
@@ -370,81 +725,56 @@ func (vm *VM) newImportTxWithUTXOs(
 		}
 		tx := &Tx{UnsignedAtomicTx: utx}
 		if err := tx.Sign(vm.codec, nil); err != nil {
-			return nil, err
+			return 0, 0, err
 		}
 
 		gasUsedWithoutChange, err := tx.GasUsed(rules.IsApricotPhase5)
 		if err != nil {
-			return nil, err
+			return 0, 0, err
 		}
 		gasUsedWithChange := gasUsedWithoutChange + DELTAOutputGas
 
 		txFeeWithoutChange, err = CalculateDynamicFee(gasUsedWithoutChange, baseFee)
 		if err != nil {
-			return nil, err
+			return 0, 0, err
 		}
 		txFeeWithChange, err = CalculateDynamicFee(gasUsedWithChange, baseFee)
 		if err != nil {
-			return nil, err
+			return 0, 0, err
 		}
 	case rules.IsApricotPhase2:
 		txFeeWithoutChange = params.OdysseyAtomicTxFee
 		txFeeWithChange = params.OdysseyAtomicTxFee
 	}
-
-	// DIONE output
-	if importedDIONEAmount < txFeeWithoutChange { // imported amount goes toward paying tx fee
-		return nil, errInsufficientFundsForFee
-	}
-
-	if importedDIONEAmount > txFeeWithChange {
-		outs = append(outs, DELTAOutput{
-			Address: to,
-			Amount:  importedDIONEAmount - txFeeWithChange,
-			AssetID: vm.ctx.DIONEAssetID,
-		})
-	}
-
-	// If no outputs are produced, return an error.
-	// Note: this can happen if there is exactly enough DIONE to pay the
-	// transaction fee, but no other funds to be imported.
-	if len(outs) == 0 {
-		return nil, errNoDELTAOutputs
-	}
-
-	utils.Sort(outs)
-
-	// Create the transaction
-	utx := &UnsignedImportTx{
-		NetworkID:      vm.ctx.NetworkID,
-		BlockchainID:   vm.ctx.ChainID,
-		Outs:           outs,
-		ImportedInputs: importedInputs,
-		SourceChain:    chainID,
-	}
-	tx := &Tx{UnsignedAtomicTx: utx}
-	if err := tx.Sign(vm.codec, signers); err != nil {
-		return nil, err
-	}
-	return tx, utx.Verify(vm.ctx, vm.currentRules())
+	return txFeeWithoutChange, txFeeWithChange, nil
 }
 
 // DELTAStateTransfer performs the state transfer to increase the balances of
-// accounts accordingly with the imported DELTAOutputs
-func (utx *UnsignedImportTx) DELTAStateTransfer(ctx *snow.Context, state *state.StateDB) error {
-	for _, to := range utx.Outs {
-		if to.AssetID == ctx.DIONEAssetID {
-			log.Debug("crosschain", "src", utx.SourceChain, "addr", to.Address, "amount", to.Amount, "assetID", "DIONE")
-			// If the asset is DIONE, convert the input amount in nDIONE to gWei by
-			// multiplying by the x2c rate.
-			amount := new(big.Int).Mul(
-				new(big.Int).SetUint64(to.Amount), x2cRate)
-			state.AddBalance(to.Address, amount)
-		} else {
-			log.Debug("crosschain", "src", utx.SourceChain, "addr", to.Address, "amount", to.Amount, "assetID", to.AssetID)
-			amount := new(big.Int).SetUint64(to.Amount)
-			state.AddBalanceMultiCoin(to.Address, common.Hash(to.AssetID), amount)
+// accounts accordingly with the imported DELTAOutputs. Either every output is
+// credited or, if crediting any output fails, none of them are.
+func (utx *UnsignedImportTx) DELTAStateTransfer(ctx *snow.Context, state *state.StateDB, rules params.Rules) error {
+	return applyStateTransfer(state, func() error {
+		for _, to := range utx.Outs {
+			if err := utx.creditOutput(ctx, state, rules, to); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
+
+// creditOutput credits the imported output [to] to the balance of its address.
+func (utx *UnsignedImportTx) creditOutput(ctx *snow.Context, state *state.StateDB, rules params.Rules, to DELTAOutput) error {
+	if to.AssetID == ctx.DIONEAssetID {
+		log.Debug("crosschain", "src", utx.SourceChain, "addr", to.Address, "amount", to.Amount, "assetID", "DIONE")
+		// If the asset is DIONE, convert the input amount in nDIONE to gWei by
+		// multiplying by the x2c rate of [rules].
+		amount := params.NdioneToWei(rules, to.Amount)
+		state.AddBalance(to.Address, amount)
+	} else {
+		log.Debug("crosschain", "src", utx.SourceChain, "addr", to.Address, "amount", to.Amount, "assetID", to.AssetID)
+		amount := new(big.Int).SetUint64(to.Amount)
+		state.AddBalanceMultiCoin(to.Address, common.Hash(to.AssetID), amount)
 	}
 	return nil
 }
@@ -4,11 +4,16 @@
 package delta
 
 import (
+	"context"
+	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/DioneProtocol/coreth/params"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DioneProtocol/odysseygo/chains/atomic"
 	"github.com/DioneProtocol/odysseygo/ids"
@@ -53,7 +58,7 @@ func createImportTxOptions(t *testing.T, vm *VM, sharedMemory *atomic.Memory) []
 
 	importTxs := make([]*Tx, 0, 3)
 	for _, ethAddr := range testEthAddrs {
-		importTx, err := vm.newImportTx(vm.ctx.AChainID, ethAddr, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+		importTx, err := vm.newImportTx(vm.ctx.AChainID, ethAddr, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -432,7 +437,7 @@ func TestNewImportTx(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+		tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -530,8 +535,458 @@ func TestNewImportTx(t *testing.T) {
 	}
 }
 
+func TestNewImportTxToRecipients(t *testing.T) {
+	dioneAmount := uint64(10_000_000_000)
+	assetAmount := uint64(1_000)
+	assetID := ids.GenerateTestID()
+	var dioneBurned uint64
+
+	executeTxTest(t, atomicTxTest{
+		setup: func(t *testing.T, vm *VM, sharedMemory *atomic.Memory) *Tx {
+			if _, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, vm.ctx.DIONEAssetID, dioneAmount, testShortIDAddrs[0]); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, assetID, assetAmount, testShortIDAddrs[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			// DIONE goes to the default recipient and the custom asset to its own
+			// recipient.
+			recipients := map[ids.ID]common.Address{assetID: testEthAddrs[1]}
+			tx, err := vm.newImportTxToRecipients(vm.ctx.AChainID, testEthAddrs[0], recipients, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if numOuts := len(tx.UnsignedAtomicTx.(*UnsignedImportTx).Outs); numOuts != 2 {
+				t.Fatalf("Expected 2 outputs, found %d", numOuts)
+			}
+			dioneBurned, err = tx.Burned(vm.ctx.DIONEAssetID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return tx
+		},
+		checkState: func(t *testing.T, vm *VM) {
+			lastAcceptedBlock := vm.LastAcceptedBlockInternal().(*Block)
+
+			sdb, err := vm.blockChain.StateAt(lastAcceptedBlock.ethBlock.Root())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedDIONEBalance := new(big.Int).Mul(new(big.Int).SetUint64(dioneAmount-dioneBurned), x2cRate)
+			if dioneBalance := sdb.GetBalance(testEthAddrs[0]); dioneBalance.Cmp(expectedDIONEBalance) != 0 {
+				t.Fatalf("Expected DIONE balance of %s to be %d, found balance: %d", testEthAddrs[0], expectedDIONEBalance, dioneBalance)
+			}
+			if assetBalance := sdb.GetBalanceMultiCoin(testEthAddrs[0], common.Hash(assetID)); assetBalance.Sign() != 0 {
+				t.Fatalf("Expected asset balance of %s to be 0, found balance: %d", testEthAddrs[0], assetBalance)
+			}
+			if dioneBalance := sdb.GetBalance(testEthAddrs[1]); dioneBalance.Sign() != 0 {
+				t.Fatalf("Expected DIONE balance of %s to be 0, found balance: %d", testEthAddrs[1], dioneBalance)
+			}
+			if assetBalance := sdb.GetBalanceMultiCoin(testEthAddrs[1], common.Hash(assetID)); assetBalance.Uint64() != assetAmount {
+				t.Fatalf("Expected asset balance of %s to be %d, found balance: %d", testEthAddrs[1], assetAmount, assetBalance)
+			}
+		},
+		genesisJSON: genesisJSONApricotPhase5,
+	})
+}
+
+func TestNewImportTxSplit(t *testing.T) {
+	dioneAmount := uint64(10_000_000_000)
+	assetAmount := uint64(1_000)
+	dioneOut := uint64(3_000_000_000)
+	assetOut := uint64(400)
+	assetID := ids.GenerateTestID()
+	var dioneBurned uint64
+
+	executeTxTest(t, atomicTxTest{
+		setup: func(t *testing.T, vm *VM, sharedMemory *atomic.Memory) *Tx {
+			if _, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, vm.ctx.DIONEAssetID, dioneAmount, testShortIDAddrs[0]); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, assetID, assetAmount, testShortIDAddrs[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			// Part of each asset is credited to its own address, and the rest
+			// goes to the change address.
+			outputs := []DELTAOutput{
+				{Address: testEthAddrs[1], Amount: dioneOut, AssetID: vm.ctx.DIONEAssetID},
+				{Address: testEthAddrs[2], Amount: assetOut, AssetID: assetID},
+			}
+			SortDELTAOutputs(outputs)
+			tx, err := vm.newImportTxSplit(vm.ctx.AChainID, outputs, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if numOuts := len(tx.UnsignedAtomicTx.(*UnsignedImportTx).Outs); numOuts != 4 {
+				t.Fatalf("Expected 4 outputs, found %d", numOuts)
+			}
+			dioneBurned, err = tx.Burned(vm.ctx.DIONEAssetID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return tx
+		},
+		checkState: func(t *testing.T, vm *VM) {
+			lastAcceptedBlock := vm.LastAcceptedBlockInternal().(*Block)
+
+			sdb, err := vm.blockChain.StateAt(lastAcceptedBlock.ethBlock.Root())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedBalances := []struct {
+				addr         common.Address
+				dione, asset uint64
+			}{
+				{addr: testEthAddrs[0], dione: dioneAmount - dioneOut - dioneBurned, asset: assetAmount - assetOut},
+				{addr: testEthAddrs[1], dione: dioneOut},
+				{addr: testEthAddrs[2], asset: assetOut},
+			}
+			for _, expected := range expectedBalances {
+				expectedDIONEBalance := new(big.Int).Mul(new(big.Int).SetUint64(expected.dione), x2cRate)
+				if dioneBalance := sdb.GetBalance(expected.addr); dioneBalance.Cmp(expectedDIONEBalance) != 0 {
+					t.Fatalf("Expected DIONE balance of %s to be %d, found balance: %d", expected.addr, expectedDIONEBalance, dioneBalance)
+				}
+				if assetBalance := sdb.GetBalanceMultiCoin(expected.addr, common.Hash(assetID)); assetBalance.Uint64() != expected.asset {
+					t.Fatalf("Expected asset balance of %s to be %d, found balance: %d", expected.addr, expected.asset, assetBalance)
+				}
+			}
+		},
+		genesisJSON: genesisJSONApricotPhase5,
+	})
+}
+
+func TestNewImportTxSplitErrors(t *testing.T) {
+	_, vm, _, sharedMemory, _ := GenesisVM(t, true, genesisJSONApricotPhase5, "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	dioneAmount := uint64(10_000_000_000)
+	assetAmount := uint64(1_000)
+	assetID := ids.GenerateTestID()
+	dioneUTXO, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, vm.ctx.DIONEAssetID, dioneAmount, testShortIDAddrs[0])
+	require.NoError(t, err)
+	assetUTXO, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, assetID, assetAmount, testShortIDAddrs[0])
+	require.NoError(t, err)
+	utxos := []*dione.UTXO{dioneUTXO, assetUTXO}
+	kc := secp256k1fx.NewKeychain(testKeys[0])
+	changeAddr := testEthAddrs[0]
+
+	// The fee of a tx crediting a single DIONE output and the asset change,
+	// without and with the DIONE change.
+	inputs, _, _, err := vm.importedInputs(kc, utxos)
+	require.NoError(t, err)
+	feeWithoutChange, feeWithChange, err := vm.importTxFees(vm.ctx.AChainID, []DELTAOutput{
+		{Address: testEthAddrs[1], Amount: 1, AssetID: vm.ctx.DIONEAssetID},
+		{Address: changeAddr, Amount: assetAmount, AssetID: assetID},
+	}, inputs, initialBaseFee)
+	require.NoError(t, err)
+	require.Less(t, feeWithoutChange, feeWithChange)
+
+	dioneOutput := func(addr common.Address, amount uint64) DELTAOutput {
+		return DELTAOutput{Address: addr, Amount: amount, AssetID: vm.ctx.DIONEAssetID}
+	}
+	sorted := func(outs ...DELTAOutput) []DELTAOutput {
+		SortDELTAOutputs(outs)
+		return outs
+	}
+	unsorted := sorted(dioneOutput(testEthAddrs[1], 1), dioneOutput(testEthAddrs[2], 1))
+	unsorted[0], unsorted[1] = unsorted[1], unsorted[0]
+
+	tests := []struct {
+		name        string
+		outputs     []DELTAOutput
+		expectedErr error
+		// expectedOuts are the outputs of the tx if it is created
+		expectedOuts []DELTAOutput
+	}{
+		{
+			name:        "no outputs",
+			expectedErr: errNoDELTAOutputs,
+		},
+		{
+			name:        "unsorted outputs",
+			outputs:     unsorted,
+			expectedErr: errOutputsNotSortedUnique,
+		},
+		{
+			name:        "duplicate outputs",
+			outputs:     []DELTAOutput{dioneOutput(testEthAddrs[1], 1), dioneOutput(testEthAddrs[1], 1)},
+			expectedErr: errOutputsNotSortedUnique,
+		},
+		{
+			name:        "zero amount",
+			outputs:     []DELTAOutput{dioneOutput(testEthAddrs[1], 0)},
+			expectedErr: errNoValueOutput,
+		},
+		{
+			name:        "insufficient asset funds",
+			outputs:     []DELTAOutput{{Address: testEthAddrs[1], Amount: assetAmount + 1, AssetID: assetID}},
+			expectedErr: errInsufficientFunds,
+		},
+		{
+			name:        "insufficient DIONE funds",
+			outputs:     []DELTAOutput{dioneOutput(testEthAddrs[1], dioneAmount+1)},
+			expectedErr: errInsufficientFunds,
+		},
+		{
+			name:        "insufficient DIONE funds for fee",
+			outputs:     []DELTAOutput{dioneOutput(testEthAddrs[1], dioneAmount-feeWithoutChange+1)},
+			expectedErr: errInsufficientFundsForFee,
+		},
+		{
+			name:    "exact fee",
+			outputs: []DELTAOutput{dioneOutput(testEthAddrs[1], dioneAmount-feeWithoutChange)},
+			expectedOuts: sorted(
+				dioneOutput(testEthAddrs[1], dioneAmount-feeWithoutChange),
+				DELTAOutput{Address: changeAddr, Amount: assetAmount, AssetID: assetID},
+			),
+		},
+		{
+			// The leftover DIONE does not cover the fee of a change output,
+			// so it is burned.
+			name:    "leftover below change fee",
+			outputs: []DELTAOutput{dioneOutput(testEthAddrs[1], dioneAmount-feeWithChange)},
+			expectedOuts: sorted(
+				dioneOutput(testEthAddrs[1], dioneAmount-feeWithChange),
+				DELTAOutput{Address: changeAddr, Amount: assetAmount, AssetID: assetID},
+			),
+		},
+		{
+			name:    "change",
+			outputs: []DELTAOutput{dioneOutput(testEthAddrs[1], dioneAmount-feeWithChange-1)},
+			expectedOuts: sorted(
+				dioneOutput(testEthAddrs[1], dioneAmount-feeWithChange-1),
+				dioneOutput(changeAddr, 1),
+				DELTAOutput{Address: changeAddr, Amount: assetAmount, AssetID: assetID},
+			),
+		},
+		{
+			// The change is merged into the outputs already crediting the
+			// change address, so no change output is paid for.
+			name: "change merged into outputs",
+			outputs: sorted(
+				dioneOutput(changeAddr, 1),
+				DELTAOutput{Address: changeAddr, Amount: 1, AssetID: assetID},
+			),
+			expectedOuts: sorted(
+				dioneOutput(changeAddr, dioneAmount-feeWithoutChange),
+				DELTAOutput{Address: changeAddr, Amount: assetAmount, AssetID: assetID},
+			),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			tx, err := vm.newImportTxWithUTXOsSplit(vm.ctx.AChainID, test.outputs, changeAddr, initialBaseFee, kc, utxos, true)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+			require.Equal(test.expectedOuts, tx.UnsignedAtomicTx.(*UnsignedImportTx).Outs)
+		})
+	}
+}
+
 // Note: this is a brittle test to ensure that the gas cost of a transaction does
 // not change
+func TestNewImportTxMultisig(t *testing.T) {
+	require := require.New(t)
+
+	_, vm, _, sharedMemory, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// Lock the UTXO to a 2-of-3 multisig owner.
+	owners := []ids.ShortID{testShortIDAddrs[0], testShortIDAddrs[1], testShortIDAddrs[2]}
+	utils.Sort(owners)
+	utxo := &dione.UTXO{
+		UTXOID: dione.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  dione.Asset{ID: vm.ctx.DIONEAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 10 * params.OdysseyAtomicTxFee,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 2,
+				Addrs:     owners,
+			},
+		},
+	}
+	utxoBytes, err := Codec.Marshal(codecVersion, utxo)
+	require.NoError(err)
+	traits := make([][]byte, len(owners))
+	for i, owner := range owners {
+		traits[i] = owner.Bytes()
+	}
+	inputID := utxo.InputID()
+	require.NoError(sharedMemory.NewSharedMemory(vm.ctx.AChainID).Apply(map[ids.ID]*atomic.Requests{vm.ctx.ChainID: {PutRequests: []*atomic.Element{{
+		Key:    inputID[:],
+		Value:  utxoBytes,
+		Traits: traits,
+	}}}}))
+
+	// Capture the logs of the VM to check which UTXOs are skipped.
+	var (
+		logLock sync.Mutex
+		skipped []interface{}
+	)
+	handler := log.Root().GetHandler()
+	log.Root().SetHandler(log.FuncHandler(func(r *log.Record) error {
+		if r.Msg == "skipping UTXO the keychain cannot spend" {
+			logLock.Lock()
+			skipped = append(skipped, r.Ctx...)
+			logLock.Unlock()
+		}
+		return nil
+	}))
+	defer log.Root().SetHandler(handler)
+
+	// A single key of the owners cannot meet the threshold, so the UTXO is
+	// skipped and there is nothing to pay the fee with.
+	_, err = vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.ErrorIs(err, errInsufficientFundsForFee)
+	logLock.Lock()
+	require.Contains(skipped, inputID)
+	skipped = nil
+	logLock.Unlock()
+
+	// Two of the owners' keys meet the threshold.
+	tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0], testKeys[1]}, false)
+	require.NoError(err)
+	require.Empty(skipped)
+
+	utx := tx.UnsignedAtomicTx.(*UnsignedImportTx)
+	require.Len(utx.ImportedInputs, 1)
+	require.Len(utx.ImportedInputs[0].In.(*secp256k1fx.TransferInput).SigIndices, 2)
+	require.Len(tx.Creds, 1)
+	require.Len(tx.Creds[0].(*secp256k1fx.Credential).Sigs, 2)
+
+	// The fee pays for both signatures.
+	rules := vm.currentRules()
+	gasUsed, err := tx.GasUsed(rules.IsApricotPhase5)
+	require.NoError(err)
+	inputCost, err := utx.ImportedInputs[0].In.Cost()
+	require.NoError(err)
+	require.Equal(uint64(2*secp256k1fx.CostPerSignature), inputCost)
+	expectedFee, err := CalculateDynamicFee(gasUsed, initialBaseFee)
+	require.NoError(err)
+	burned, err := utx.Burned(vm.ctx.DIONEAssetID)
+	require.NoError(err)
+	require.Equal(expectedFee, burned)
+
+	require.NoError(vm.issueTx(tx, true /*=local*/))
+}
+
+func TestImportTxSplitByAsset(t *testing.T) {
+	dioneAssetID := ids.GenerateTestID()
+	antAssetID := ids.GenerateTestID()
+	otherAssetID := ids.GenerateTestID()
+	chainID := ids.GenerateTestID()
+	aChainID := ids.GenerateTestID()
+	networkID := uint32(5)
+
+	input := func(assetID ids.ID, amount uint64) *dione.TransferableInput {
+		return &dione.TransferableInput{
+			UTXOID: dione.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  dione.Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt:   amount,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}
+	}
+	output := func(assetID ids.ID, amount uint64) DELTAOutput {
+		return DELTAOutput{Address: testEthAddrs[0], Amount: amount, AssetID: assetID}
+	}
+
+	dioneIn1, dioneIn2 := input(dioneAssetID, 5000), input(dioneAssetID, 3000)
+	antIn1, antIn2 := input(antAssetID, 100), input(antAssetID, 200)
+	dioneOut, antOut1, antOut2 := output(dioneAssetID, 7000), output(antAssetID, 250), output(antAssetID, 50)
+
+	tests := map[string]struct {
+		inputs      []*dione.TransferableInput
+		outs        []DELTAOutput
+		expected    [][]*dione.TransferableInput
+		expectedOut [][]DELTAOutput
+		expectedErr error
+	}{
+		"no inputs": {},
+		"single asset": {
+			inputs:      []*dione.TransferableInput{dioneIn1, dioneIn2},
+			outs:        []DELTAOutput{dioneOut},
+			expected:    [][]*dione.TransferableInput{{dioneIn1, dioneIn2}},
+			expectedOut: [][]DELTAOutput{{dioneOut}},
+		},
+		"two assets": {
+			inputs:      []*dione.TransferableInput{dioneIn1, antIn1, dioneIn2, antIn2},
+			outs:        []DELTAOutput{antOut1, dioneOut, antOut2},
+			expected:    [][]*dione.TransferableInput{{dioneIn1, dioneIn2}, {antIn1, antIn2}},
+			expectedOut: [][]DELTAOutput{{dioneOut}, {antOut1, antOut2}},
+		},
+		"asset fully burned": {
+			inputs:      []*dione.TransferableInput{antIn1, dioneIn1},
+			outs:        []DELTAOutput{output(dioneAssetID, 4000)},
+			expected:    [][]*dione.TransferableInput{{antIn1}, {dioneIn1}},
+			expectedOut: [][]DELTAOutput{nil, {output(dioneAssetID, 4000)}},
+		},
+		"asset produces more than it consumes": {
+			inputs:      []*dione.TransferableInput{dioneIn1, antIn1},
+			outs:        []DELTAOutput{output(dioneAssetID, 1000), antOut1},
+			expectedErr: errInsufficientFundsToSplit,
+		},
+		"output of an asset that is not imported": {
+			inputs:      []*dione.TransferableInput{dioneIn1},
+			outs:        []DELTAOutput{output(dioneAssetID, 1000), output(otherAssetID, 1)},
+			expectedErr: errInsufficientFundsToSplit,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			utx := &UnsignedImportTx{
+				NetworkID:      networkID,
+				BlockchainID:   chainID,
+				SourceChain:    aChainID,
+				ImportedInputs: test.inputs,
+				Outs:           test.outs,
+			}
+			splits, err := utx.SplitByAsset()
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+
+			require.Len(splits, len(test.expected))
+			for i, split := range splits {
+				require.Equal(networkID, split.NetworkID)
+				require.Equal(chainID, split.BlockchainID)
+				require.Equal(aChainID, split.SourceChain)
+				require.Equal(test.expected[i], split.ImportedInputs)
+				require.Equal(test.expectedOut[i], split.Outs)
+			}
+
+			// The amount of each asset burned is preserved across the split.
+			for _, assetID := range []ids.ID{dioneAssetID, antAssetID} {
+				expectedBurned, err := utx.Burned(assetID)
+				require.NoError(err)
+				var burned uint64
+				for _, split := range splits {
+					splitBurned, err := split.Burned(assetID)
+					require.NoError(err)
+					burned += splitBurned
+				}
+				require.Equal(expectedBurned, burned)
+			}
+		})
+	}
+}
+
 func TestImportTxGasCost(t *testing.T) {
 	dioneAssetID := ids.GenerateTestID()
 	antAssetID := ids.GenerateTestID()
@@ -864,7 +1319,41 @@ func TestImportTxGasCost(t *testing.T) {
 	}
 }
 
+// newNonDIONEImportTx returns a signed tx importing a UTXO of [assetID]
+// produced by [utxoTxID], which is added to [sharedMemory].
+func newNonDIONEImportTx(t *testing.T, vm *VM, sharedMemory *atomic.Memory, utxoTxID ids.ID, assetID ids.ID) *Tx {
+	utxo, err := addUTXO(sharedMemory, vm.ctx, utxoTxID, 0, assetID, 1, testShortIDAddrs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := &Tx{UnsignedAtomicTx: &UnsignedImportTx{
+		NetworkID:    vm.ctx.NetworkID,
+		BlockchainID: vm.ctx.ChainID,
+		SourceChain:  vm.ctx.AChainID,
+		ImportedInputs: []*dione.TransferableInput{{
+			UTXOID: utxo.UTXOID,
+			Asset:  dione.Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt:   1,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}},
+		Outs: []DELTAOutput{{
+			Address: testEthAddrs[0],
+			Amount:  1,
+			AssetID: assetID,
+		}},
+	}}
+	if err := tx.Sign(vm.codec, [][]*secp256k1.PrivateKey{{testKeys[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
 func TestImportTxSemanticVerify(t *testing.T) {
+	allowedAssetID := ids.GenerateTestID()
+	allowlistConfigJSON := fmt.Sprintf(`{"atomic-asset-allowlist":[%q]}`, allowedAssetID)
 	tests := map[string]atomicTxTest{
 		"UTXO not present during bootstrapping": {
 			setup: func(t *testing.T, vm *VM, sharedMemory *atomic.Memory) *Tx {
@@ -1065,6 +1554,12 @@ func TestImportTxSemanticVerify(t *testing.T) {
 			},
 			semanticVerifyErr: "import tx flow check failed due to",
 		},
+		"non-DIONE asset in allowlist": {
+			setup: func(t *testing.T, vm *VM, sharedMemory *atomic.Memory) *Tx {
+				return newNonDIONEImportTx(t, vm, sharedMemory, ids.GenerateTestID(), allowedAssetID)
+			},
+			configJSON: allowlistConfigJSON,
+		},
 		"no signatures": {
 			setup: func(t *testing.T, vm *VM, sharedMemory *atomic.Memory) *Tx {
 				txID := ids.GenerateTestID()
@@ -1182,6 +1677,53 @@ func TestImportTxSemanticVerify(t *testing.T) {
 	}
 }
 
+func TestImportTxAtomicAssetAllowlist(t *testing.T) {
+	require := require.New(t)
+
+	allowlistConfigJSON := fmt.Sprintf(`{"atomic-asset-allowlist":[%q]}`, ids.GenerateTestID())
+	issuer, vm, _, sharedMemory, _ := GenesisVM(t, true, genesisJSONApricotPhase0, "", "")
+	_, restrictedVM, _, restrictedSharedMemory, _ := GenesisVM(t, true, genesisJSONApricotPhase0, allowlistConfigJSON, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+		require.NoError(restrictedVM.Shutdown(context.Background()))
+	}()
+
+	// The same UTXO of an asset missing from the allowlist is available to
+	// both nodes.
+	assetID, utxoTxID := ids.GenerateTestID(), ids.GenerateTestID()
+	tx := newNonDIONEImportTx(t, vm, sharedMemory, utxoTxID, assetID)
+	_ = newNonDIONEImportTx(t, restrictedVM, restrictedSharedMemory, utxoTxID, assetID)
+
+	// The allowlist does not affect the validity of the tx, but the restricted
+	// node refuses to issue it.
+	parent := restrictedVM.LastAcceptedBlockInternal().(*Block)
+	require.NoError(tx.UnsignedAtomicTx.SemanticVerify(restrictedVM, tx, parent, nil, restrictedVM.currentRules()))
+	require.ErrorIs(restrictedVM.issueTx(tx, true /*=local*/), errAtomicAssetNotAllowed)
+	require.ErrorIs(restrictedVM.ValidateAtomicTx(context.Background(), tx), errAtomicAssetNotAllowed)
+
+	// A block of a peer containing the tx is still accepted by the restricted
+	// node.
+	require.NoError(vm.issueTx(tx, true /*=local*/))
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	restrictedBlk, err := restrictedVM.ParseBlock(context.Background(), blk.Bytes())
+	require.NoError(err)
+	require.NoError(restrictedBlk.Verify(context.Background()))
+	require.NoError(restrictedVM.SetPreference(context.Background(), restrictedBlk.ID()))
+	require.NoError(restrictedBlk.Accept(context.Background()))
+	require.Equal(blk.ID(), restrictedVM.LastAcceptedBlock().ID())
+
+	sdb, err := restrictedVM.blockChain.State()
+	require.NoError(err)
+	require.Equal(uint64(1), sdb.GetBalanceMultiCoin(testEthAddrs[0], common.Hash(assetID)).Uint64())
+}
+
 func TestImportTxDELTAStateTransfer(t *testing.T) {
 	assetID := ids.GenerateTestID()
 	tests := map[string]atomicTxTest{
@@ -1287,3 +1829,92 @@ func TestImportTxDELTAStateTransfer(t *testing.T) {
 		})
 	}
 }
+
+func TestEstimateImportCost(t *testing.T) {
+	tests := map[string]struct {
+		genesisJSON     string
+		multiAsset      bool
+		expectedDynamic bool
+	}{
+		"apricot phase 2": {
+			genesisJSON: genesisJSONApricotPhase2,
+		},
+		"apricot phase 3": {
+			genesisJSON:     genesisJSONApricotPhase3,
+			expectedDynamic: true,
+		},
+		"apricot phase 5": {
+			genesisJSON:     genesisJSONApricotPhase5,
+			multiAsset:      true,
+			expectedDynamic: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			_, vm, _, sharedMemory, _ := GenesisVM(t, true, test.genesisJSON, "", "")
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+
+			utxo, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, vm.ctx.DIONEAssetID, 100*params.OdysseyAtomicTxFee, testShortIDAddrs[0])
+			require.NoError(err)
+			utxos := []*dione.UTXO{utxo}
+			if test.multiAsset {
+				utxo, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, ids.GenerateTestID(), 1, testShortIDAddrs[0])
+				require.NoError(err)
+				utxos = append(utxos, utxo)
+			}
+
+			fee, err := vm.EstimateImportCost(vm.ctx.AChainID, utxos, testEthAddrs[0], initialBaseFee)
+			require.NoError(err)
+			if !test.expectedDynamic {
+				require.Equal(params.OdysseyAtomicTxFee, fee)
+			}
+
+			// The estimate matches the fee burned by the signed tx.
+			tx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, true)
+			require.NoError(err)
+			burned, err := tx.UnsignedAtomicTx.Burned(vm.ctx.DIONEAssetID)
+			require.NoError(err)
+			require.Equal(burned, fee)
+
+			// Under the dynamic fee rules, the estimate is the fee of the gas used
+			// by the signed tx.
+			gasUsed, err := tx.GasUsed(vm.currentRules().IsApricotPhase5)
+			require.NoError(err)
+			if test.expectedDynamic {
+				expectedFee, err := CalculateDynamicFee(gasUsed, initialBaseFee)
+				require.NoError(err)
+				require.Equal(expectedFee, fee)
+			}
+		})
+	}
+}
+
+func TestEstimateImportCostInsufficientFunds(t *testing.T) {
+	for name, genesisJSON := range map[string]string{
+		"apricot phase 2": genesisJSONApricotPhase2,
+		"apricot phase 3": genesisJSONApricotPhase3,
+		"apricot phase 5": genesisJSONApricotPhase5,
+	} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			_, vm, _, sharedMemory, _ := GenesisVM(t, true, genesisJSON, "", "")
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+
+			utxo, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, vm.ctx.DIONEAssetID, 1, testShortIDAddrs[0])
+			require.NoError(err)
+			_, err = vm.EstimateImportCost(vm.ctx.AChainID, []*dione.UTXO{utxo}, testEthAddrs[0], initialBaseFee)
+			require.ErrorIs(err, errInsufficientFundsForFee)
+			require.ErrorContains(err, "exceeds imported 1 nDIONE")
+
+			_, err = vm.EstimateImportCost(vm.ctx.AChainID, nil, testEthAddrs[0], initialBaseFee)
+			require.ErrorIs(err, errInsufficientFundsForFee)
+		})
+	}
+}
@@ -5,34 +5,74 @@ package delta
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const (
 	errorKey   = "LOG15_ERROR"
 	timeFormat = "2006-01-02T15:04:05-0700"
+
+	// LogFormatTerminal and LogFormatJSON are the formats the logs of the
+	// plugin can be written in.
+	LogFormatTerminal = "terminal"
+	LogFormatJSON     = "json"
 )
 
+var errUnknownLogFormat = errors.New("unknown log format")
+
 type CorethLogger struct {
 	log.Handler
+
+	alias  string
+	format *switchableFormat
+	// closer closes the log file of the logger, if any
+	closer io.Closer
+}
+
+// LogFileConfig configures the file the logs of the plugin are written to, in
+// addition to the writer passed to InitLogger. The file is rotated once it
+// reaches [MaxSize] megabytes, keeping at most [MaxBackups] of the rotated
+// files, or all of them if [MaxBackups] is 0.
+type LogFileConfig struct {
+	Path       string
+	MaxSize    int
+	MaxBackups int
 }
 
 // InitLogger initializes logger with alias and sets the log level and format with the original [os.StdErr] interface
-// along with the context logger.
-func InitLogger(alias string, level string, jsonFormat bool, writer io.Writer) (CorethLogger, error) {
-	logFormat := CorethTermFormat(alias)
+// along with the context logger. If [file] has a path, the logs are also written to the rotated file it configures.
+func InitLogger(alias string, level string, jsonFormat bool, writer io.Writer, file LogFileConfig) (CorethLogger, error) {
+	logFormat := LogFormatTerminal
 	if jsonFormat {
-		logFormat = CorethJSONFormat(alias)
+		logFormat = LogFormatJSON
+	}
+	c := CorethLogger{
+		alias:  alias,
+		format: &switchableFormat{},
+	}
+	if err := c.SetLogFormat(logFormat); err != nil {
+		return CorethLogger{}, err
 	}
 
 	// Create handler
-	logHandler := log.StreamHandler(writer, logFormat)
-	c := CorethLogger{Handler: logHandler}
+	c.Handler = log.StreamHandler(writer, c.format)
+	if file.Path != "" {
+		fileWriter := &lumberjack.Logger{
+			Filename:   file.Path,
+			MaxSize:    file.MaxSize,
+			MaxBackups: file.MaxBackups,
+		}
+		c.Handler = log.MultiHandler(c.Handler, log.StreamHandler(fileWriter, c.format))
+		c.closer = fileWriter
+	}
 
 	if err := c.SetLogLevel(level); err != nil {
 		return CorethLogger{}, err
@@ -51,13 +91,55 @@ func (c *CorethLogger) SetLogLevel(level string) error {
 	return nil
 }
 
+// SetLogFormat switches the format of the records written by the initialized
+// log handler to [format], which is either LogFormatTerminal or LogFormatJSON.
+func (c *CorethLogger) SetLogFormat(format string) error {
+	switch format {
+	case LogFormatTerminal:
+		c.format.set(CorethTermFormat(c.alias))
+	case LogFormatJSON:
+		c.format.set(CorethJSONFormat(c.alias))
+	default:
+		return fmt.Errorf("%w: %q", errUnknownLogFormat, format)
+	}
+	return nil
+}
+
+// Close closes the log file of the logger, if any.
+func (c *CorethLogger) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+// switchableFormat is a log.Format whose underlying format can be switched
+// while records are being formatted with it.
+type switchableFormat struct {
+	format atomic.Value // of formatHolder
+}
+
+// formatHolder allows storing formats of different types in an atomic.Value.
+type formatHolder struct {
+	log.Format
+}
+
+func (f *switchableFormat) set(format log.Format) {
+	f.format.Store(formatHolder{Format: format})
+}
+
+func (f *switchableFormat) Format(r *log.Record) []byte {
+	return f.format.Load().(formatHolder).Format.Format(r)
+}
+
 func CorethTermFormat(alias string) log.Format {
 	prefix := fmt.Sprintf("<%s Chain>", alias)
 	return log.FormatFunc(func(r *log.Record) []byte {
 		location := fmt.Sprintf("%+v", r.Call)
-		newMsg := fmt.Sprintf("%s %s: %s", prefix, location, r.Msg)
-		r.Msg = newMsg
-		return log.TerminalFormat(false).Format(r)
+		// Copy the record, since it is shared by every handler that writes it
+		record := *r
+		record.Msg = fmt.Sprintf("%s %s: %s", prefix, location, r.Msg)
+		return log.TerminalFormat(false).Format(&record)
 	})
 }
 
@@ -0,0 +1,91 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/api"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLogFormat(t *testing.T) {
+	require := require.New(t)
+
+	logFile := filepath.Join(t.TempDir(), "delta.log")
+	configJSON := fmt.Sprintf(`{"log-file": %q}`, logFile)
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, configJSON, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	admin := NewAdminService(vm, t.TempDir())
+
+	log.Info("before switching the log format")
+	require.NoError(admin.SetLogFormat(nil, &SetLogFormatArgs{Format: LogFormatJSON}, &api.EmptyReply{}))
+	log.Info("after switching the log format", "key", "value")
+
+	err := admin.SetLogFormat(nil, &SetLogFormatArgs{Format: "xml"}, &api.EmptyReply{})
+	require.ErrorIs(err, errUnknownLogFormat)
+
+	logs, err := os.ReadFile(logFile)
+	require.NoError(err)
+	before, after, found := bytes.Cut(logs, []byte("DELTA: SetLogFormat called"))
+	require.True(found)
+	require.Contains(string(before), "before switching the log format")
+
+	// Every line logged after the switch, except the rest of the line
+	// announcing it, is JSON.
+	scanner := bufio.NewScanner(bytes.NewReader(after))
+	require.True(scanner.Scan())
+	var records []map[string]interface{}
+	for scanner.Scan() {
+		record := make(map[string]interface{})
+		require.NoError(json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+		records = append(records, record)
+	}
+	require.NoError(scanner.Err())
+
+	var switched map[string]interface{}
+	for _, record := range records {
+		if record["msg"] == "after switching the log format" {
+			switched = record
+		}
+	}
+	require.NotNil(switched)
+	require.Equal("value", switched["key"])
+	require.Equal("info", switched["level"])
+}
+
+func TestInitLoggerRotation(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	logger, err := InitLogger("D", "info", false, io.Discard, LogFileConfig{
+		Path:    filepath.Join(dir, "delta.log"),
+		MaxSize: 1,
+	})
+	require.NoError(err)
+	defer func() {
+		require.NoError(logger.Close())
+	}()
+
+	// Log more than the max size of the log file to rotate it.
+	line := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		log.Info(line)
+	}
+	entries, err := os.ReadDir(dir)
+	require.NoError(err)
+	require.GreaterOrEqual(len(entries), 2)
+}
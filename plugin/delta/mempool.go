@@ -6,13 +6,19 @@ package delta
 import (
 	"errors"
 	"fmt"
+	"math/big"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/DioneProtocol/odysseygo/cache"
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/network/p2p/gossip"
+	safemath "github.com/DioneProtocol/odysseygo/utils/math"
+	"github.com/DioneProtocol/odysseygo/utils/set"
 
 	"github.com/DioneProtocol/coreth/metrics"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -32,6 +38,9 @@ type mempoolMetrics struct {
 	discardedTxs metrics.Counter // Count of all discarded transactions
 
 	newTxsReturned metrics.Counter // Count of transactions returned from GetNewTxs
+
+	addressCapRejections metrics.Counter // Count of transactions rejected for exceeding the per-address limit
+	addressCapEvictions  metrics.Counter // Count of transactions evicted to make room under the per-address limit
 }
 
 // newMempoolMetrics constructs metrics for the atomic mempool
@@ -43,9 +52,20 @@ func newMempoolMetrics() *mempoolMetrics {
 		addedTxs:       metrics.GetOrRegisterCounter("atomic_mempool_added_txs", nil),
 		discardedTxs:   metrics.GetOrRegisterCounter("atomic_mempool_discarded_txs", nil),
 		newTxsReturned: metrics.GetOrRegisterCounter("atomic_mempool_new_txs_returned", nil),
+
+		addressCapRejections: metrics.GetOrRegisterCounter("atomic_mempool_address_cap_rejections", nil),
+		addressCapEvictions:  metrics.GetOrRegisterCounter("atomic_mempool_address_cap_evictions", nil),
 	}
 }
 
+// nonceReserver is notified of the account nonces consumed by export
+// transactions while they are held in the mempool, so that EVM transactions
+// using the same nonces can be evicted and rejected.
+type nonceReserver interface {
+	ReserveNonce(addr common.Address, nonce uint64)
+	ReleaseNonce(addr common.Address, nonce uint64)
+}
+
 // Mempool is a simple mempool for atomic transactions
 type Mempool struct {
 	lock sync.RWMutex
@@ -54,6 +74,9 @@ type Mempool struct {
 	DIONEAssetID ids.ID
 	// maxSize is the maximum number of transactions allowed to be kept in mempool
 	maxSize int
+	// maxTxsPerAddress is the maximum number of transactions of a single
+	// sender allowed to be kept in mempool (0 = no limit)
+	maxTxsPerAddress int
 	// currentTxs is the set of transactions about to be added to a block.
 	currentTxs map[ids.ID]*Tx
 	// issuedTxs is the set of transactions that have been issued into a new block
@@ -73,6 +96,16 @@ type Mempool struct {
 	utxoSpenders map[ids.ID]*Tx
 	// bloom is a bloom filter containing the txs in the mempool
 	bloom *gossip.BloomFilter
+	// nonces is notified of the nonces consumed by export txs in the mempool
+	nonces nonceReserver
+	// feeCaps maps txIDs to the maximum base fee their issuer is willing to pay
+	feeCaps map[ids.ID]*big.Int
+	// addedTimes maps txIDs to the time they were added to the mempool
+	addedTimes map[ids.ID]time.Time
+	// noGossipTxs is the set of txs that must never be gossiped to peers
+	noGossipTxs set.Set[ids.ID]
+	// senderTxs maps the sender of each tx in the mempool to its txIDs
+	senderTxs map[common.Address]set.Set[ids.ID]
 
 	metrics *mempoolMetrics
 }
@@ -93,11 +126,34 @@ func NewMempool(DIONEAssetID ids.ID, maxSize int) (*Mempool, error) {
 		txHeap:       newTxHeap(maxSize),
 		maxSize:      maxSize,
 		utxoSpenders: make(map[ids.ID]*Tx),
+		feeCaps:      make(map[ids.ID]*big.Int),
+		addedTimes:   make(map[ids.ID]time.Time),
+		noGossipTxs:  set.Set[ids.ID]{},
+		senderTxs:    make(map[common.Address]set.Set[ids.ID]),
 		bloom:        bloom,
 		metrics:      newMempoolMetrics(),
 	}, nil
 }
 
+// SetNonceReserver registers [nonces] to be notified of the account nonces
+// consumed by export transactions entering and leaving the mempool.
+func (m *Mempool) SetNonceReserver(nonces nonceReserver) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.nonces = nonces
+}
+
+// SetMaxTxsPerAddress limits the number of transactions of a single sender
+// kept in the mempool to [maxTxsPerAddress]. If [maxTxsPerAddress] is 0, the
+// number of transactions per sender is not limited.
+func (m *Mempool) SetMaxTxsPerAddress(maxTxsPerAddress int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.maxTxsPerAddress = maxTxsPerAddress
+}
+
 // Len returns the number of transactions in the mempool
 func (m *Mempool) Len() int {
 	m.lock.RLock()
@@ -145,7 +201,105 @@ func (m *Mempool) AddTx(tx *Tx) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	return m.addTx(tx, false)
+	return m.addTx(tx, false, false)
+}
+
+// AddTxWithoutGossip attempts to add [tx] to the mempool like AddTx, but [tx]
+// is never gossiped to peers, neither when it is added nor when the mempool is
+// regossiped or pulled by peers.
+func (m *Mempool) AddTxWithoutGossip(tx *Tx) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.addTx(tx, false, true)
+}
+
+// AddTxWithFeeCap attempts to add [tx] to the mempool like AddTx. If
+// [maxBaseFee] is non-nil, [tx] is dropped by DropFeeCapExceeded if the base
+// fee rises above [maxBaseFee] before [tx] is issued into a block.
+func (m *Mempool) AddTxWithFeeCap(tx *Tx, maxBaseFee *big.Int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.addTx(tx, false, false); err != nil {
+		return err
+	}
+	if maxBaseFee != nil {
+		m.feeCaps[tx.ID()] = new(big.Int).Set(maxBaseFee)
+	}
+	return nil
+}
+
+// DropFeeCapExceeded discards all pending transactions whose fee cap is below
+// [baseFee] and returns the discarded transactions.
+func (m *Mempool) DropFeeCapExceeded(baseFee *big.Int) []*Tx {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var dropped []*Tx
+	for _, item := range m.txHeap.sortedEntries() {
+		maxBaseFee, ok := m.feeCaps[item.id]
+		if !ok || maxBaseFee.Cmp(baseFee) >= 0 {
+			continue
+		}
+		tx := item.tx
+		log.Debug("dropping atomic tx from mempool",
+			"txID", item.id,
+			"maxBaseFee", maxBaseFee,
+			"baseFee", baseFee,
+			"reason", "fee cap exceeded",
+		)
+		m.removeTx(tx, true)
+		dropped = append(dropped, tx)
+	}
+	return dropped
+}
+
+// DiscardOlderThan discards all pending transactions added to the mempool
+// before [cutoff] and returns the discarded transactions.
+func (m *Mempool) DiscardOlderThan(cutoff time.Time) []mempoolTx {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var stale []mempoolTx
+	for _, item := range m.txHeap.sortedEntries() {
+		added, ok := m.addedTimes[item.id]
+		if !ok || !added.Before(cutoff) {
+			continue
+		}
+		stale = append(stale, mempoolTx{
+			tx:         item.tx,
+			gasPrice:   item.gasPrice,
+			added:      added,
+			maxBaseFee: m.feeCaps[item.id],
+		})
+	}
+	for _, tx := range stale {
+		m.removeTx(tx.tx, true)
+	}
+	return stale
+}
+
+// DiscardInvalidTxs discards all pending transactions for which [verify]
+// returns an error and returns the number of discarded transactions.
+func (m *Mempool) DiscardInvalidTxs(verify func(tx *Tx) error) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var invalidTxs []*Tx
+	for _, item := range m.txHeap.sortedEntries() {
+		if err := verify(item.tx); err != nil {
+			log.Debug("discarding invalid atomic tx from mempool",
+				"txID", item.tx.ID(),
+				"err", err,
+			)
+			invalidTxs = append(invalidTxs, item.tx)
+		}
+	}
+	for _, tx := range invalidTxs {
+		m.removeTx(tx, true)
+	}
+	return len(invalidTxs)
 }
 
 // forceAddTx forcibly adds a *Tx to the mempool and bypasses all verification.
@@ -153,7 +307,7 @@ func (m *Mempool) ForceAddTx(tx *Tx) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	return m.addTx(tx, true)
+	return m.addTx(tx, true, false)
 }
 
 // checkConflictTx checks for any transactions in the mempool that spend the same input UTXOs as [tx].
@@ -188,9 +342,83 @@ func (m *Mempool) checkConflictTx(tx *Tx) (uint64, ids.ID, []*Tx, error) {
 	return highestGasPrice, highestGasPriceConflictTxID, conflictingTxs, nil
 }
 
+// atomicTxSender returns the address [tx] is attributed to by the per-address
+// limit of the mempool: the first DELTA address spending funds for an export
+// tx, and the first DELTA address receiving funds for an import tx. Returns
+// false if [tx] has no such address.
+func atomicTxSender(tx *Tx) (common.Address, bool) {
+	switch utx := tx.UnsignedAtomicTx.(type) {
+	case *UnsignedExportTx:
+		if len(utx.Ins) > 0 {
+			return utx.Ins[0].Address, true
+		}
+	case *UnsignedImportTx:
+		if len(utx.Outs) > 0 {
+			return utx.Outs[0].Address, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// checkSenderLimit makes room for a tx of [sender] paying [gasPrice] if
+// [sender] already has [maxTxsPerAddress] transactions in the mempool, by
+// evicting the pending tx of [sender] with the lowest gas price. Returns an
+// error if no such tx pays less than [gasPrice].
+// Assumes the lock is held.
+func (m *Mempool) checkSenderLimit(sender common.Address, txID ids.ID, gasPrice uint64) error {
+	senderTxs := m.senderTxs[sender]
+	if m.maxTxsPerAddress == 0 || senderTxs.Len() < m.maxTxsPerAddress {
+		return nil
+	}
+
+	// Txs that are being issued cannot be evicted.
+	var minEntry *txEntry
+	for senderTxID := range senderTxs {
+		entry, ok := m.txHeap.maxHeap.Get(senderTxID)
+		if !ok {
+			continue
+		}
+		if minEntry == nil || entry.gasPrice < minEntry.gasPrice {
+			minEntry = entry
+		}
+	}
+	if minEntry == nil || minEntry.gasPrice >= gasPrice {
+		m.metrics.addressCapRejections.Inc(1)
+		if minEntry == nil {
+			return fmt.Errorf(
+				"%w: issued tx (%s) exceeds limit of %d txs for %s",
+				errTooManyAtomicTxsFromAddress,
+				txID,
+				m.maxTxsPerAddress,
+				sender,
+			)
+		}
+		return fmt.Errorf(
+			"%w: issued tx (%s) gas price %d <= lowest tx (%s) gas price %d of %s (limit %d)",
+			errTooManyAtomicTxsFromAddress,
+			txID,
+			gasPrice,
+			minEntry.id,
+			minEntry.gasPrice,
+			sender,
+			m.maxTxsPerAddress,
+		)
+	}
+
+	log.Debug("evicting atomic tx from mempool",
+		"txID", minEntry.id,
+		"sender", sender,
+		"reason", "per-address limit reached",
+	)
+	m.removeTx(minEntry.tx, true)
+	m.metrics.addressCapEvictions.Inc(1)
+	return nil
+}
+
 // addTx adds [tx] to the mempool. Assumes [m.lock] is held.
 // If [force], skips conflict checks within the mempool.
-func (m *Mempool) addTx(tx *Tx, force bool) error {
+// If [noGossip], [tx] is never gossiped to peers.
+func (m *Mempool) addTx(tx *Tx, force bool, noGossip bool) error {
 	txID := tx.ID()
 	// If [txID] has already been issued or is in the currentTxs map
 	// there's no need to add it.
@@ -229,6 +457,12 @@ func (m *Mempool) addTx(tx *Tx, force bool) error {
 			m.removeTx(conflictTx, true)
 		}
 	}
+	sender, hasSender := atomicTxSender(tx)
+	if hasSender && !force {
+		if err := m.checkSenderLimit(sender, txID, gasPrice); err != nil {
+			return err
+		}
+	}
 	// If adding this transaction would exceed the mempool's size, check if there is a lower priced
 	// transaction that can be evicted from the mempool
 	if m.length() >= m.maxSize {
@@ -273,6 +507,13 @@ func (m *Mempool) addTx(tx *Tx, force bool) error {
 	for utxoID := range utxoSet {
 		m.utxoSpenders[utxoID] = tx
 	}
+	m.addedTimes[txID] = time.Now()
+	if hasSender {
+		senderTxs := m.senderTxs[sender]
+		senderTxs.Add(txID)
+		m.senderTxs[sender] = senderTxs
+	}
+	m.reserveNonces(tx)
 
 	m.bloom.Add(&GossipAtomicTx{Tx: tx})
 	reset, err := gossip.ResetBloomFilterIfNeeded(m.bloom, txGossipMaxFalsePositiveRate)
@@ -293,17 +534,153 @@ func (m *Mempool) addTx(tx *Tx, force bool) error {
 	// been set to something other than [dontBuild], this will be ignored and won't be
 	// reset until the engine calls BuildBlock. This case is handled in IssueCurrentTx
 	// and CancelCurrentTx.
-	m.newTxs = append(m.newTxs, tx)
+	if noGossip {
+		m.noGossipTxs.Add(txID)
+	} else {
+		m.newTxs = append(m.newTxs, tx)
+	}
 	m.addPending()
 
 	return nil
 }
 
-func (m *Mempool) Iterate(f func(tx *GossipAtomicTx) bool) {
+// PendingGasUsed returns the total atomic gas consumed by the transactions
+// waiting in the mempool to be issued into a block.
+func (m *Mempool) PendingGasUsed() (uint64, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var total uint64
+	for _, item := range m.txHeap.maxHeap.items {
+		gasUsed, err := item.tx.GasUsed(true)
+		if err != nil {
+			return 0, err
+		}
+		total, err = safemath.Add64(total, gasUsed)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// PendingTx is a transaction waiting in the mempool to be issued into a block.
+type PendingTx struct {
+	Tx *Tx
+	// Added is the time Tx was added to the mempool.
+	Added time.Time
+	// Conflicting is true if another transaction in the mempool spends an
+	// input UTXO of Tx.
+	Conflicting bool
+}
+
+// PendingTxs returns the transactions waiting in the mempool to be issued
+// into a block, in the order they would be issued.
+func (m *Mempool) PendingTxs() []PendingTx {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	// Transactions that are being issued no longer wait in [txHeap], but are
+	// still in the mempool and may conflict with pending transactions.
+	numSpenders := make(map[ids.ID]int)
+	countSpenders := func(tx *Tx) {
+		for utxoID := range tx.InputUTXOs() {
+			numSpenders[utxoID]++
+		}
+	}
+	for _, item := range m.txHeap.maxHeap.items {
+		countSpenders(item.tx)
+	}
+	for _, tx := range m.currentTxs {
+		countSpenders(tx)
+	}
+	for _, tx := range m.issuedTxs {
+		countSpenders(tx)
+	}
+
+	items := m.txHeap.sortedEntries()
+	pending := make([]PendingTx, len(items))
+	for i, item := range items {
+		pending[i] = PendingTx{
+			Tx:    item.tx,
+			Added: m.addedTimes[item.id],
+		}
+		for utxoID := range item.tx.InputUTXOs() {
+			if numSpenders[utxoID] > 1 {
+				pending[i].Conflicting = true
+				break
+			}
+		}
+	}
+	return pending
+}
+
+// mempoolTx is a transaction in the mempool along with the metadata it was
+// added with.
+type mempoolTx struct {
+	tx       *Tx
+	gasPrice uint64
+	added    time.Time
+	// maxBaseFee is the fee cap of tx, or nil if it has none.
+	maxBaseFee *big.Int
+}
+
+// allTxs returns every transaction in the mempool, including the transactions
+// being issued and the ones issued into blocks that are not yet accepted,
+// ordered by descending gas price and then by ascending ID.
+func (m *Mempool) allTxs() []mempoolTx {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
 
+	txs := make([]mempoolTx, 0, m.length()+len(m.currentTxs))
+	add := func(tx *Tx, gasPrice uint64) {
+		txs = append(txs, mempoolTx{
+			tx:         tx,
+			gasPrice:   gasPrice,
+			added:      m.addedTimes[tx.ID()],
+			maxBaseFee: m.feeCaps[tx.ID()],
+		})
+	}
 	for _, item := range m.txHeap.maxHeap.items {
+		add(item.tx, item.gasPrice)
+	}
+	for _, issuing := range []map[ids.ID]*Tx{m.currentTxs, m.issuedTxs} {
+		for _, tx := range issuing {
+			// The gas price was already computed when [tx] was added.
+			gasPrice, _ := m.atomicTxGasPrice(tx)
+			add(tx, gasPrice)
+		}
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].gasPrice != txs[j].gasPrice {
+			return txs[i].gasPrice > txs[j].gasPrice
+		}
+		return txs[i].tx.ID().Less(txs[j].tx.ID())
+	})
+	return txs
+}
+
+// setAddedTime records that the transaction [txID] was added to the mempool
+// at [added], if it is in the mempool.
+func (m *Mempool) setAddedTime(txID ids.ID, added time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.addedTimes[txID]; ok {
+		m.addedTimes[txID] = added
+	}
+}
+
+// Iterate calls [f] on the pending transactions that may be gossiped, in the
+// order they would be issued, until [f] returns false.
+func (m *Mempool) Iterate(f func(tx *GossipAtomicTx) bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for _, item := range m.txHeap.sortedEntries() {
+		if m.noGossipTxs.Contains(item.id) {
+			continue
+		}
 		if !f(&GossipAtomicTx{Tx: item.tx}) {
 			return
 		}
@@ -513,6 +890,42 @@ func (m *Mempool) removeSpenders(tx *Tx) {
 	for utxoID := range tx.InputUTXOs() {
 		delete(m.utxoSpenders, utxoID)
 	}
+	delete(m.feeCaps, tx.ID())
+	delete(m.addedTimes, tx.ID())
+	m.noGossipTxs.Remove(tx.ID())
+	if sender, ok := atomicTxSender(tx); ok {
+		senderTxs := m.senderTxs[sender]
+		senderTxs.Remove(tx.ID())
+		if senderTxs.Len() == 0 {
+			delete(m.senderTxs, sender)
+		}
+	}
+	m.releaseNonces(tx)
+}
+
+// reserveNonces reserves the account nonces consumed by [tx] if it is an
+// export transaction.
+// Assumes the lock is held.
+func (m *Mempool) reserveNonces(tx *Tx) {
+	exportTx, ok := tx.UnsignedAtomicTx.(*UnsignedExportTx)
+	if !ok || m.nonces == nil {
+		return
+	}
+	for _, in := range exportTx.Ins {
+		m.nonces.ReserveNonce(in.Address, in.Nonce)
+	}
+}
+
+// releaseNonces releases the account nonces reserved by [tx].
+// Assumes the lock is held.
+func (m *Mempool) releaseNonces(tx *Tx) {
+	exportTx, ok := tx.UnsignedAtomicTx.(*UnsignedExportTx)
+	if !ok || m.nonces == nil {
+		return
+	}
+	for _, in := range exportTx.Ins {
+		m.nonces.ReleaseNonce(in.Address, in.Nonce)
+	}
 }
 
 // RemoveTx removes [txID] from the mempool completely.
@@ -12,6 +12,7 @@ import (
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/utils"
 	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/DioneProtocol/odysseygo/utils/set"
 	"github.com/DioneProtocol/odysseygo/vms/components/chain"
 	"github.com/DioneProtocol/odysseygo/vms/components/dione"
 	"github.com/DioneProtocol/odysseygo/vms/secp256k1fx"
@@ -118,6 +119,95 @@ func TestMempoolMaxMempoolSizeHandling(t *testing.T) {
 	assert.True(mempool.has(tx.ID()))
 }
 
+// mempool size should be configurable and evict the lowest priced txs when full
+func TestMempoolConfiguredMaxSize(t *testing.T) {
+	assert := assert.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, true, "", `{"atomic-mempool-max-size": 2}`, "")
+	defer func() {
+		err := vm.Shutdown(context.Background())
+		assert.NoError(err)
+	}()
+	mempool := vm.mempool
+	assert.Equal(2, vm.config.AtomicMempoolMaxSize)
+	assert.Equal(2, mempool.maxSize)
+
+	newTx := func(burned uint64) *Tx {
+		return &Tx{UnsignedAtomicTx: &TestUnsignedTx{
+			IDV:         ids.GenerateTestID(),
+			GasUsedV:    1,
+			BurnedV:     burned,
+			InputUTXOsV: set.Of(ids.GenerateTestID()),
+		}}
+	}
+	lowTx, midTx, highTx, lowestTx := newTx(2), newTx(3), newTx(4), newTx(1)
+	assert.NoError(mempool.AddTx(lowTx))
+	assert.NoError(mempool.AddTx(midTx))
+
+	// A higher priced tx evicts the lowest priced tx once the mempool is full
+	assert.NoError(mempool.AddTx(highTx))
+	assert.Equal(2, mempool.Len())
+	_, pending := mempool.GetPendingTx(lowTx.ID())
+	assert.False(pending)
+
+	// A tx priced below every tx in the full mempool is rejected
+	assert.ErrorIs(mempool.AddTx(lowestTx), errInsufficientAtomicTxFee)
+	assert.False(mempool.has(lowestTx.ID()))
+}
+
+// mempool should limit the pending txs of a single address and evict the
+// lowest priced one of them for a higher priced tx once the limit is reached
+func TestMempoolMaxTxsPerAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, true, "", "", "")
+	defer func() {
+		err := vm.Shutdown(context.Background())
+		assert.NoError(err)
+	}()
+	mempool := vm.mempool
+	assert.Equal(defaultAtomicMempoolMaxTxsPerAddress, mempool.maxTxsPerAddress)
+
+	// Every tx imports funds to the same address.
+	txs := make([]*Tx, 0, mempool.maxTxsPerAddress)
+	for i := 0; i < mempool.maxTxsPerAddress; i++ {
+		tx := createImportTx(t, vm, ids.GenerateTestID(), uint64(i+2)*100_000)
+		assert.NoError(mempool.AddTx(tx))
+		txs = append(txs, tx)
+	}
+
+	// A tx priced no higher than the lowest priced tx of the address is rejected
+	for _, feeAmount := range []uint64{100_000, 200_000} {
+		tx := createImportTx(t, vm, ids.GenerateTestID(), feeAmount)
+		assert.ErrorIs(mempool.AddTx(tx), errTooManyAtomicTxsFromAddress)
+		assert.False(mempool.has(tx.ID()))
+	}
+
+	// Txs without a sender are not limited
+	assert.NoError(mempool.AddTx(&Tx{UnsignedAtomicTx: &TestUnsignedTx{
+		IDV:         ids.GenerateTestID(),
+		GasUsedV:    1,
+		BurnedV:     1,
+		InputUTXOsV: set.Of(ids.GenerateTestID()),
+	}}))
+
+	// A higher priced tx evicts the lowest priced tx of the address
+	highTx := createImportTx(t, vm, ids.GenerateTestID(), 2_000_000)
+	assert.NoError(mempool.AddTx(highTx))
+	assert.True(mempool.has(highTx.ID()))
+	_, dropped, found := mempool.GetTx(txs[0].ID())
+	assert.True(found)
+	assert.True(dropped)
+	for _, tx := range txs[1:] {
+		assert.True(mempool.has(tx.ID()))
+	}
+	assert.Equal(mempool.maxTxsPerAddress+1, mempool.Len())
+
+	// Once a tx of the address leaves the mempool, another one may be added
+	mempool.RemoveTx(txs[1])
+	assert.NoError(mempool.AddTx(createImportTx(t, vm, ids.GenerateTestID(), 100_000)))
+}
+
 func createImportTx(t *testing.T, vm *VM, txID ids.ID, feeAmount uint64) *Tx {
 	var importAmount uint64 = 10000000
 	importTx := &UnsignedImportTx{
@@ -0,0 +1,143 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// persistedAtomicTx is an atomic mempool tx persisted across restarts, keyed
+// by its position in descending gas price order.
+type persistedAtomicTx struct {
+	// Tx is the signed bytes of the tx.
+	Tx []byte `serialize:"true"`
+	// AddedTime is the unix time the tx was added to the mempool.
+	AddedTime int64 `serialize:"true"`
+	// MaxBaseFee is the fee cap of the tx, or empty if it has none.
+	MaxBaseFee []byte `serialize:"true"`
+}
+
+// restoreAtomicMempool re-admits the atomic txs persisted by the last
+// shutdown that are still valid at the tip of the chain, and then deletes
+// them. Re-admitted txs are gossiped like newly issued txs. If persistence is
+// disabled, the persisted txs are deleted without being restored. Only the
+// first call has any effect.
+func (vm *VM) restoreAtomicMempool() error {
+	if vm.atomicMempoolRestored {
+		return nil
+	}
+
+	var (
+		keys             [][]byte
+		restored, failed int
+		it               = vm.atomicMempoolDB.NewIterator()
+	)
+	defer it.Release()
+
+	for it.Next() {
+		keys = append(keys, append([]byte(nil), it.Key()...))
+		if !vm.config.AtomicMempoolPersistenceEnabled {
+			continue
+		}
+		if err := vm.restoreAtomicTx(append([]byte(nil), it.Value()...)); err != nil {
+			failed++
+			continue
+		}
+		restored++
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		log.Info("restored persisted atomic mempool", "restored", restored, "dropped", failed)
+	}
+
+	for _, key := range keys {
+		if err := vm.atomicMempoolDB.Delete(key); err != nil {
+			return err
+		}
+	}
+	if err := vm.atomicMempoolDB.Commit(); err != nil {
+		return err
+	}
+	vm.atomicMempoolRestored = true
+	return nil
+}
+
+// restoreAtomicTx re-admits the persisted atomic tx [b] to the mempool if it is
+// still valid at the tip of the chain.
+func (vm *VM) restoreAtomicTx(b []byte) error {
+	persisted := persistedAtomicTx{}
+	if _, err := vm.codec.Unmarshal(b, &persisted); err != nil {
+		log.Warn("dropping unparsable persisted atomic tx", "err", err)
+		return err
+	}
+	tx, err := ExtractAtomicTx(persisted.Tx, vm.codec)
+	if err != nil {
+		log.Warn("dropping unparsable persisted atomic tx", "err", err)
+		return err
+	}
+
+	txID := tx.ID()
+	if err := vm.verifyTxAtTip(tx); err != nil {
+		log.Info("dropping persisted atomic tx that is no longer valid", "txID", txID, "err", err)
+		return err
+	}
+	var maxBaseFee *big.Int
+	if len(persisted.MaxBaseFee) > 0 {
+		maxBaseFee = new(big.Int).SetBytes(persisted.MaxBaseFee)
+	}
+	if err := vm.mempool.AddTxWithFeeCap(tx, maxBaseFee); err != nil {
+		log.Info("dropping persisted atomic tx rejected by the mempool", "txID", txID, "err", err)
+		return err
+	}
+	vm.mempool.setAddedTime(txID, time.Unix(persisted.AddedTime, 0))
+	return nil
+}
+
+// persistAtomicMempool persists the atomic txs in the mempool, in descending
+// gas price order, until [AtomicMempoolPersistenceMaxBytes] is reached. It does
+// nothing if persistence is disabled or the persisted txs were not restored.
+func (vm *VM) persistAtomicMempool() error {
+	if !vm.config.AtomicMempoolPersistenceEnabled || !vm.atomicMempoolRestored {
+		return nil
+	}
+
+	var (
+		txs       = vm.mempool.allTxs()
+		size      uint64
+		persisted int
+	)
+	for _, tx := range txs {
+		entry := persistedAtomicTx{
+			Tx:        tx.tx.SignedBytes(),
+			AddedTime: tx.added.Unix(),
+		}
+		if tx.maxBaseFee != nil {
+			entry.MaxBaseFee = tx.maxBaseFee.Bytes()
+		}
+		b, err := vm.codec.Marshal(codecVersion, &entry)
+		if err != nil {
+			return err
+		}
+		if size+uint64(len(b)) > vm.config.AtomicMempoolPersistenceMaxBytes {
+			break
+		}
+		size += uint64(len(b))
+
+		if err := vm.atomicMempoolDB.Put(database.PackUInt64(uint64(persisted)), b); err != nil {
+			return err
+		}
+		persisted++
+	}
+	if persisted < len(txs) {
+		log.Warn("atomic mempool exceeds the persistence limit", "persisted", persisted, "dropped", len(txs)-persisted)
+	}
+	log.Info("persisted atomic mempool", "txs", persisted, "size", size)
+	return vm.atomicMempoolDB.Commit()
+}
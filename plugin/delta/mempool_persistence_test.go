@@ -0,0 +1,184 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/chains/atomic"
+	"github.com/DioneProtocol/odysseygo/database/manager"
+	"github.com/DioneProtocol/odysseygo/database/prefixdb"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/snow"
+	engCommon "github.com/DioneProtocol/odysseygo/snow/engine/common"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/plugin/delta/message"
+)
+
+// restartVM initializes a new VM with [configJSON] over the database and the
+// shared memory of [vm], which must be shut down, and finishes bootstrapping
+// it. The new VM records the atomic txs it gossips in the returned channel.
+func restartVM(t *testing.T, vm *VM, dbManager manager.Manager, configJSON string) (*VM, chan ids.ID) {
+	require := require.New(t)
+
+	gossiped := make(chan ids.ID, 16)
+	appSender := &engCommon.SenderTest{T: t}
+	appSender.CantSendAppGossip = false
+	appSender.SendAppGossipF = func(_ context.Context, gossipBytes []byte) error {
+		msg, err := message.ParseGossipMessage(message.Codec, gossipBytes)
+		require.NoError(err)
+		if msg, ok := msg.(message.AtomicTxGossip); ok {
+			tx, err := ExtractAtomicTx(msg.Tx, Codec)
+			require.NoError(err)
+			gossiped <- tx.ID()
+		}
+		return nil
+	}
+
+	// The metrics of [vm] remain registered with its context.
+	ctx := NewContext()
+	ctx.SharedMemory = vm.ctx.SharedMemory
+	ctx.FeeCollector = vm.ctx.FeeCollector
+
+	restartedVM := &VM{}
+	require.NoError(restartedVM.Initialize(
+		context.Background(),
+		ctx,
+		dbManager,
+		BuildGenesisTest(t, genesisJSONLatest),
+		[]byte(""),
+		[]byte(configJSON),
+		make(chan engCommon.Message, 1),
+		[]*engCommon.Fx{},
+		appSender,
+	))
+	require.NoError(restartedVM.SetState(context.Background(), snow.Bootstrapping))
+	require.NoError(restartedVM.SetState(context.Background(), snow.NormalOp))
+	return restartedVM, gossiped
+}
+
+// newPersistenceTestVM returns a VM with a pending import tx from each of the
+// first two test keys. The first tx pays the higher fee and has a fee cap.
+func newPersistenceTestVM(t *testing.T, configJSON string) (*VM, manager.Manager, *Tx, *Tx) {
+	require := require.New(t)
+
+	_, vm, dbManager, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, configJSON, "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+		testShortIDAddrs[1]: 10 * params.OdysseyAtomicTxFee,
+	})
+
+	highFeeTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(initialBaseFee, big.NewInt(2)), []*secp256k1.PrivateKey{testKeys[0]}, true)
+	require.NoError(err)
+	require.NoError(vm.issueTxWithFeeCap(highFeeTx, true /*=local*/, big.NewInt(params.ApricotPhase3MaxBaseFee)))
+	lowFeeTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[1], initialBaseFee, []*secp256k1.PrivateKey{testKeys[1]}, true)
+	require.NoError(err)
+	require.NoError(vm.issueTx(lowFeeTx, true /*=local*/))
+	return vm, dbManager, highFeeTx, lowFeeTx
+}
+
+func TestAtomicMempoolPersistence(t *testing.T) {
+	require := require.New(t)
+
+	vm, dbManager, highFeeTx, lowFeeTx := newPersistenceTestVM(t, "")
+	added := vm.mempool.addedTimes[highFeeTx.ID()]
+
+	// Spend the UTXO imported by [lowFeeTx] while the node is down, so that
+	// it is no longer valid once the node restarts.
+	require.NoError(vm.Shutdown(context.Background()))
+	utxoID := lowFeeTx.UnsignedAtomicTx.(*UnsignedImportTx).ImportedInputs[0].InputID()
+	require.NoError(vm.ctx.SharedMemory.Apply(map[ids.ID]*atomic.Requests{
+		vm.ctx.AChainID: {RemoveRequests: [][]byte{utxoID[:]}},
+	}))
+
+	restartedVM, gossiped := restartVM(t, vm, dbManager, "")
+	defer func() {
+		require.NoError(restartedVM.Shutdown(context.Background()))
+	}()
+
+	require.True(restartedVM.mempool.has(highFeeTx.ID()))
+	require.False(restartedVM.mempool.has(lowFeeTx.ID()))
+	require.Equal(1, restartedVM.mempool.Len())
+	// The issue metadata of the tx is restored.
+	require.Equal(added.Unix(), restartedVM.mempool.addedTimes[highFeeTx.ID()].Unix())
+	require.Equal(big.NewInt(params.ApricotPhase3MaxBaseFee), restartedVM.mempool.feeCaps[highFeeTx.ID()])
+
+	// The restored tx is gossiped again.
+	select {
+	case txID := <-gossiped:
+		require.Equal(highFeeTx.ID(), txID)
+	case <-time.After(5 * time.Second):
+		require.FailNow("restored tx was not gossiped")
+	}
+
+	// The persisted txs are deleted once they are restored.
+	it := prefixdb.New(atomicMempoolPrefix, dbManager.Current().Database).NewIterator()
+	defer it.Release()
+	require.False(it.Next())
+	require.NoError(it.Error())
+}
+
+func TestAtomicMempoolPersistenceMaxBytes(t *testing.T) {
+	require := require.New(t)
+
+	vm, dbManager, highFeeTx, lowFeeTx := newPersistenceTestVM(t, "")
+	entry, err := vm.codec.Marshal(codecVersion, &persistedAtomicTx{
+		Tx:        lowFeeTx.SignedBytes(),
+		AddedTime: time.Now().Unix(),
+	})
+	require.NoError(err)
+
+	// Only the highest priced tx fits within the limit.
+	vm.config.AtomicMempoolPersistenceMaxBytes = uint64(len(entry)) * 3 / 2
+	require.NoError(vm.Shutdown(context.Background()))
+	restartedVM, _ := restartVM(t, vm, dbManager, "")
+	defer func() {
+		require.NoError(restartedVM.Shutdown(context.Background()))
+	}()
+
+	require.True(restartedVM.mempool.has(highFeeTx.ID()))
+	require.False(restartedVM.mempool.has(lowFeeTx.ID()))
+}
+
+func TestAtomicMempoolPersistenceDisabled(t *testing.T) {
+	require := require.New(t)
+
+	vm, dbManager, _, _ := newPersistenceTestVM(t, "")
+	require.NoError(vm.Shutdown(context.Background()))
+	restartedVM, _ := restartVM(t, vm, dbManager, `{"atomic-mempool-persistence-enabled": false}`)
+	require.Zero(restartedVM.mempool.Len())
+	require.NoError(restartedVM.Shutdown(context.Background()))
+
+	// The txs persisted before persistence was disabled are discarded rather
+	// than restored by a later restart.
+	restartedVM, _ = restartVM(t, restartedVM, dbManager, "")
+	defer func() {
+		require.NoError(restartedVM.Shutdown(context.Background()))
+	}()
+	require.Zero(restartedVM.mempool.Len())
+}
+
+// Txs issued into blocks that are not yet accepted are persisted, as the
+// blocks are lost on restart.
+func TestAtomicMempoolPersistenceIssuedTxs(t *testing.T) {
+	require := require.New(t)
+
+	vm, dbManager, highFeeTx, lowFeeTx := newPersistenceTestVM(t, "")
+	_, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.Empty(vm.mempool.PendingTxs())
+
+	require.NoError(vm.Shutdown(context.Background()))
+	restartedVM, _ := restartVM(t, vm, dbManager, "")
+	defer func() {
+		require.NoError(restartedVM.Shutdown(context.Background()))
+	}()
+	require.True(restartedVM.mempool.has(highFeeTx.ID()))
+	require.True(restartedVM.mempool.has(lowFeeTx.ID()))
+}
@@ -4,10 +4,20 @@
 package delta
 
 import (
+	"context"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/DioneProtocol/odysseygo/chains/atomic"
 	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/snow/consensus/snowman"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/DioneProtocol/odysseygo/utils/set"
+	"github.com/DioneProtocol/odysseygo/vms/components/chain"
 	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/params"
 )
 
 func TestMempoolAddTx(t *testing.T) {
@@ -33,3 +43,158 @@ func TestMempoolAddTx(t *testing.T) {
 		require.True(m.bloom.Has(tx))
 	}
 }
+
+func TestMempoolDropFeeCapExceeded(t *testing.T) {
+	require := require.New(t)
+	m, err := NewMempool(ids.Empty, 10)
+	require.NoError(err)
+
+	newTx := func() *Tx {
+		return &Tx{
+			UnsignedAtomicTx: &TestUnsignedTx{
+				IDV:         ids.GenerateTestID(),
+				GasUsedV:    1,
+				BurnedV:     1,
+				InputUTXOsV: set.Of(ids.GenerateTestID()),
+			},
+		}
+	}
+	lowCapTx, highCapTx, uncappedTx := newTx(), newTx(), newTx()
+	require.NoError(m.AddTxWithFeeCap(lowCapTx, big.NewInt(10)))
+	require.NoError(m.AddTxWithFeeCap(highCapTx, big.NewInt(20)))
+	require.NoError(m.AddTxWithFeeCap(uncappedTx, nil))
+
+	// Only the tx whose cap is below the base fee is dropped.
+	require.Equal([]*Tx{lowCapTx}, m.DropFeeCapExceeded(big.NewInt(15)))
+	_, dropped, found := m.GetTx(lowCapTx.ID())
+	require.True(found)
+	require.True(dropped)
+	require.Equal(2, m.Len())
+
+	// Txs that are being added to a block are not dropped.
+	for i := 0; i < 2; i++ {
+		_, ok := m.NextTx()
+		require.True(ok)
+	}
+	require.Empty(m.DropFeeCapExceeded(big.NewInt(25)))
+
+	// Once returned to the pending set the tx is dropped.
+	m.CancelCurrentTxs()
+	require.Equal([]*Tx{highCapTx}, m.DropFeeCapExceeded(big.NewInt(25)))
+	_, dropped, found = m.GetTx(uncappedTx.ID())
+	require.True(found)
+	require.False(dropped)
+}
+
+func TestMempoolAddTxWithoutGossip(t *testing.T) {
+	require := require.New(t)
+	m, err := NewMempool(ids.Empty, 10)
+	require.NoError(err)
+
+	newTx := func() *Tx {
+		return &Tx{
+			UnsignedAtomicTx: &TestUnsignedTx{
+				IDV:         ids.GenerateTestID(),
+				GasUsedV:    1,
+				BurnedV:     1,
+				InputUTXOsV: set.Of(ids.GenerateTestID()),
+			},
+		}
+	}
+	tx, silentTx := newTx(), newTx()
+	require.NoError(m.AddTx(tx))
+	require.NoError(m.AddTxWithoutGossip(silentTx))
+	require.Equal(2, m.Len())
+	require.Len(m.Pending, 1)
+
+	// Only [tx] is gossiped, pushed or pulled.
+	require.Equal([]*Tx{tx}, m.GetNewTxs())
+	var iterated []*Tx
+	m.Iterate(func(tx *GossipAtomicTx) bool {
+		iterated = append(iterated, tx.Tx)
+		return true
+	})
+	require.Equal([]*Tx{tx}, iterated)
+
+	// Once removed, the tx can be added again with gossip.
+	m.RemoveTx(silentTx)
+	require.NoError(m.AddTx(silentTx))
+	require.Equal([]*Tx{silentTx}, m.GetNewTxs())
+}
+
+// TestMempoolDeterministicOrder checks that two VMs whose mempools hold the
+// same atomic txs, added in different orders, list, gossip and build them in
+// the same order.
+func TestMempoolDeterministicOrder(t *testing.T) {
+	require := require.New(t)
+
+	_, vm0, _, sharedMemory0, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	_, vm1, _, sharedMemory1, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm0.Shutdown(context.Background()))
+		require.NoError(vm1.Shutdown(context.Background()))
+	}()
+
+	// Half of the txs pay a higher fee, and the txs of each half pay the same
+	// gas price.
+	const numTxs = 8
+	factory := secp256k1.Factory{}
+	txs := make([]*Tx, numTxs)
+	for i := range txs {
+		key, err := factory.NewPrivateKey()
+		require.NoError(err)
+		utxoTxID := ids.GenerateTestID()
+		for _, sharedMemory := range []*atomic.Memory{sharedMemory0, sharedMemory1} {
+			_, err := addUTXO(sharedMemory, vm0.ctx, utxoTxID, 0, vm0.ctx.DIONEAssetID, 10*params.OdysseyAtomicTxFee, key.PublicKey().Address())
+			require.NoError(err)
+		}
+		baseFee := initialBaseFee
+		if i%2 == 1 {
+			baseFee = new(big.Int).Mul(initialBaseFee, big.NewInt(2))
+		}
+		txs[i], err = vm0.newImportTx(vm0.ctx.AChainID, GetEthAddress(key), baseFee, []*secp256k1.PrivateKey{key}, true /*=allowHighFee*/)
+		require.NoError(err)
+	}
+	for i := range txs {
+		require.NoError(vm0.mempool.AddTx(txs[i]))
+		require.NoError(vm1.mempool.AddTx(txs[numTxs-1-i]))
+	}
+
+	pendingIDs := func(vm *VM) []ids.ID {
+		var txIDs []ids.ID
+		for _, pending := range vm.mempool.PendingTxs() {
+			txIDs = append(txIDs, pending.Tx.ID())
+		}
+		return txIDs
+	}
+	gossipIDs := func(vm *VM) []ids.ID {
+		var txIDs []ids.ID
+		vm.mempool.Iterate(func(tx *GossipAtomicTx) bool {
+			txIDs = append(txIDs, tx.Tx.ID())
+			return true
+		})
+		return txIDs
+	}
+	require.Len(pendingIDs(vm0), numTxs)
+	require.Equal(pendingIDs(vm0), pendingIDs(vm1))
+	require.Equal(gossipIDs(vm0), gossipIDs(vm1))
+
+	now := time.Now()
+	vm0.clock.Set(now)
+	vm1.clock.Set(now)
+	blk0, err := vm0.BuildBlock(context.Background())
+	require.NoError(err)
+	blk1, err := vm1.BuildBlock(context.Background())
+	require.NoError(err)
+
+	blockTxIDs := func(blk snowman.Block) []ids.ID {
+		var txIDs []ids.ID
+		for _, tx := range blk.(*chain.BlockWrapper).Block.(*Block).atomicTxs {
+			txIDs = append(txIDs, tx.ID())
+		}
+		return txIDs
+	}
+	require.NotEmpty(blockTxIDs(blk0))
+	require.Equal(blockTxIDs(blk0), blockTxIDs(blk1))
+	require.Equal(blk0.ID(), blk1.ID())
+}
@@ -0,0 +1,85 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/chains/atomic"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimAtomicMempool(t *testing.T) {
+	require := require.New(t)
+
+	vm, _, highFeeTx, lowFeeTx := newPersistenceTestVM(t, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// Consume the UTXO imported by [lowFeeTx] by a conflicting tx on the
+	// source chain, so that it can never be accepted.
+	utxoID := lowFeeTx.UnsignedAtomicTx.(*UnsignedImportTx).ImportedInputs[0].InputID()
+	require.NoError(vm.ctx.SharedMemory.Apply(map[ids.ID]*atomic.Requests{
+		vm.ctx.AChainID: {RemoveRequests: [][]byte{utxoID[:]}},
+	}))
+	vm.mempool.setAddedTime(lowFeeTx.ID(), time.Now().Add(-2*time.Hour))
+
+	// Neither tx is old enough to be evicted.
+	require.Zero(vm.TrimAtomicMempool(3 * time.Hour))
+	require.Equal(2, vm.mempool.Len())
+
+	require.Equal(1, vm.TrimAtomicMempool(time.Hour))
+	require.True(vm.mempool.has(highFeeTx.ID()))
+	require.False(vm.mempool.has(lowFeeTx.ID()))
+	_, dropped, found := vm.mempool.GetTx(lowFeeTx.ID())
+	require.True(found)
+	require.True(dropped)
+
+	require.Zero(vm.TrimAtomicMempool(time.Hour))
+	require.Equal(1, vm.mempool.Len())
+}
+
+// Txs issued into blocks that are not yet accepted are not evicted, as they
+// are no longer pending.
+func TestTrimAtomicMempoolIssuedTxs(t *testing.T) {
+	require := require.New(t)
+
+	vm, _, highFeeTx, lowFeeTx := newPersistenceTestVM(t, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	_, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	vm.mempool.setAddedTime(highFeeTx.ID(), time.Now().Add(-2*time.Hour))
+	vm.mempool.setAddedTime(lowFeeTx.ID(), time.Now().Add(-2*time.Hour))
+
+	require.Zero(vm.TrimAtomicMempool(time.Hour))
+	require.True(vm.mempool.has(highFeeTx.ID()))
+	require.True(vm.mempool.has(lowFeeTx.ID()))
+}
+
+func TestAtomicMempoolMaxAge(t *testing.T) {
+	require := require.New(t)
+
+	vm, _, highFeeTx, lowFeeTx := newPersistenceTestVM(t, `{"atomic-mempool-max-age": "100ms"}`)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	utxoID := lowFeeTx.UnsignedAtomicTx.(*UnsignedImportTx).ImportedInputs[0].InputID()
+	require.NoError(vm.ctx.SharedMemory.Apply(map[ids.ID]*atomic.Requests{
+		vm.ctx.AChainID: {RemoveRequests: [][]byte{utxoID[:]}},
+	}))
+
+	// Both txs are evicted in the background once they exceed the max age.
+	require.Eventually(func() bool {
+		return !vm.mempool.has(highFeeTx.ID()) && !vm.mempool.has(lowFeeTx.ID())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Zero(vm.mempool.Len())
+}
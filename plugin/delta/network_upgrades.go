@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"github.com/DioneProtocol/odysseygo/utils/json"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/DioneProtocol/coreth/utils"
+)
+
+// UpgradeEvent is a network upgrade of the chain config.
+type UpgradeEvent struct {
+	Name string `json:"name"`
+	// Timestamp is the activation timestamp of the upgrade, or nil if it is
+	// not scheduled.
+	Timestamp *uint64 `json:"timestamp"`
+	// Activated is true if the last accepted block was produced under the
+	// upgrade.
+	Activated bool `json:"activated"`
+}
+
+// GetNetworkUpgradeSchedule returns the past and scheduled network upgrades of
+// the chain config, in activation order, along with whether they have been
+// activated by the last accepted block.
+func (vm *VM) GetNetworkUpgradeSchedule() ([]UpgradeEvent, error) {
+	if vm.chainConfig == nil {
+		return nil, errMissingChainConfig
+	}
+	lastAccepted := vm.blockChain.LastConsensusAcceptedBlock()
+	upgrades := vm.chainConfig.NetworkUpgrades()
+	events := make([]UpgradeEvent, len(upgrades))
+	for i, upgrade := range upgrades {
+		events[i] = UpgradeEvent{
+			Name:      upgrade.Name,
+			Timestamp: upgrade.Timestamp,
+			Activated: utils.IsTimestampForked(upgrade.Timestamp, lastAccepted.Time()),
+		}
+	}
+	return events, nil
+}
+
+// ActiveRules are the rules of the chain config that a block was produced
+// under.
+type ActiveRules struct {
+	BlockHash common.Hash `json:"blockHash"`
+	Height    json.Uint64 `json:"height"`
+	Timestamp json.Uint64 `json:"timestamp"`
+	// Forks are the fork flags of params.Rules by field name, such as
+	// "IsBanff".
+	Forks map[string]bool `json:"forks"`
+	// Precompiles are the addresses of the enabled stateful precompiles, in
+	// address order.
+	Precompiles []common.Address `json:"precompiles"`
+}
+
+// GetActiveRules returns the rules of the chain config that the preferred
+// block was produced under.
+func (vm *VM) GetActiveRules() (*ActiveRules, error) {
+	if vm.chainConfig == nil {
+		return nil, errMissingChainConfig
+	}
+	preferred := vm.blockChain.CurrentBlock()
+	rules := vm.chainConfig.OdysseyRules(preferred.Number, preferred.Time)
+	return &ActiveRules{
+		BlockHash:   preferred.Hash(),
+		Height:      json.Uint64(preferred.Number.Uint64()),
+		Timestamp:   json.Uint64(preferred.Time),
+		Forks:       rules.ForkFlagsMap(),
+		Precompiles: rules.PrecompileAddresses(),
+	}, nil
+}
@@ -0,0 +1,119 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/precompile"
+	"github.com/DioneProtocol/coreth/utils"
+)
+
+func TestGetNetworkUpgradeSchedule(t *testing.T) {
+	require := require.New(t)
+
+	expectedSchedule := func(cortinaActivated bool) []UpgradeEvent {
+		return []UpgradeEvent{
+			{Name: "Apricot Phase 1", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Apricot Phase 2", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Apricot Phase 3", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Apricot Phase 4", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Apricot Phase 5", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Apricot Phase Pre-6", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Apricot Phase 6", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Apricot Phase Post-6", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Banff", Timestamp: utils.NewUint64(0), Activated: true},
+			{Name: "Cortina", Timestamp: utils.NewUint64(10), Activated: cortinaActivated},
+			{Name: "DUpgrade"},
+			{Name: "EUpgrade"},
+			{Name: "OdyPhase Extra Checksum"},
+			{Name: "OdyPhase Orion Snapshot"},
+			{Name: "Cancun"},
+		}
+	}
+
+	// Cortina is scheduled, but not activated by the genesis block.
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONCortinaAt("10"), "", "")
+	schedule, err := vm.GetNetworkUpgradeSchedule()
+	require.NoError(err)
+	require.Equal(expectedSchedule(false), schedule)
+	require.NoError(vm.Shutdown(context.Background()))
+
+	// Cortina is activated once a block past it is accepted.
+	vm, _, _ = newConfigCompatTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	reply := &GetNetworkUpgradeScheduleReply{}
+	require.NoError(NewAdminService(vm, t.TempDir()).GetNetworkUpgradeSchedule(nil, nil, reply))
+	require.Equal(expectedSchedule(true), reply.Upgrades)
+}
+
+type testPrecompileConfig struct {
+	addr      common.Address
+	timestamp *uint64
+}
+
+func (c *testPrecompileConfig) Address() common.Address { return c.addr }
+func (c *testPrecompileConfig) Timestamp() *uint64      { return c.timestamp }
+func (*testPrecompileConfig) Configure(precompile.ChainConfig, precompile.StateDB, precompile.BlockContext) error {
+	return nil
+}
+func (c *testPrecompileConfig) Contract() precompile.StatefulPrecompiledContract { return c }
+func (*testPrecompileConfig) Run(precompile.PrecompileAccessibleState, common.Address, common.Address, []byte, uint64, bool) ([]byte, uint64, error) {
+	return nil, 0, nil
+}
+
+func TestGetChainConfig(t *testing.T) {
+	require := require.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// The precompile is enabled two blocks after genesis.
+	targetBlockRate := vm.chainConfig.GetTargetBlockRate(vm.blockChain.Genesis().Time())
+	precompileAddr := common.HexToAddress("0x0100000000000000000000000000000000000010")
+	require.NoError(vm.chainConfig.RegisterPrecompile(&testPrecompileConfig{
+		addr:      precompileAddr,
+		timestamp: utils.NewUint64(vm.blockChain.Genesis().Time() + 2*targetBlockRate),
+	}))
+
+	// The chain config is served by the dione endpoint, which requires no
+	// credentials, rather than by the admin endpoint.
+	handlers, err := vm.CreateHandlers(context.Background())
+	require.NoError(err)
+	require.NotContains(handlers, adminEndpoint)
+	server := httptest.NewServer(handlers[dioneEndpoint].Handler)
+	defer server.Close()
+	c := &client{requester: rpc.NewEndpointRequester(server.URL)}
+
+	reply, err := c.GetChainConfig(context.Background())
+	require.NoError(err)
+	require.Equal(vm.chainConfig.ChainID, reply.ChainConfig.ChainID)
+	require.Equal(vm.chainConfig.CortinaBlockTimestamp, reply.ChainConfig.CortinaBlockTimestamp)
+	require.Equal(vm.blockChain.Genesis().Hash(), reply.ActiveRules.BlockHash)
+	require.True(reply.ActiveRules.Forks["IsBanff"])
+	require.False(reply.ActiveRules.Forks["IsEUpgrade"])
+	require.Empty(reply.ActiveRules.Precompiles)
+
+	buildEmptyBlocks(t, vm, 1)
+	reply, err = c.GetChainConfig(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(1), uint64(reply.ActiveRules.Height))
+	require.Empty(reply.ActiveRules.Precompiles)
+
+	blk := buildEmptyBlocks(t, vm, 1)
+	reply, err = c.GetChainConfig(context.Background())
+	require.NoError(err)
+	require.Equal(blk.ethBlock.Hash(), reply.ActiveRules.BlockHash)
+	require.Equal([]common.Address{precompileAddr}, reply.ActiveRules.Precompiles)
+}
@@ -0,0 +1,202 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/wrappers"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/DioneProtocol/coreth/consensus/dummy"
+	"github.com/DioneProtocol/coreth/core/rawdb"
+	"github.com/DioneProtocol/coreth/core/state"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+)
+
+// verifiedOrionNodesCacheSize is the number of blocks whose orion nodes are
+// kept between their processing by the blockchain and the end of Block.verify.
+const verifiedOrionNodesCacheSize = 32
+
+var (
+	errMissingOrionParent   = errors.New("missing parent of block")
+	errInvalidOrionSnapshot = errors.New("invalid orion snapshot")
+)
+
+// OrionSnapshot identifies the orion nodes list the fees of a block are
+// distributed to.
+//
+// As of OdyPhaseOrionSnapshot, the list is read from the committed state of
+// the parent of the block, before any of its txs are executed, so that the txs
+// of a block can not change the distribution of its own fees, and the snapshot
+// is encoded in the Extra field of the header after the rollup window. Before
+// it, the list is read from the state after the EVM txs of the block.
+type OrionSnapshot struct {
+	NodeCount uint64 `json:"nodeCount"`
+	// ListHash is the keccak256 hash of the concatenated node IDs of the list.
+	ListHash common.Hash `json:"listHash"`
+}
+
+func newOrionSnapshot(nodes []ids.NodeID) OrionSnapshot {
+	data := make([]byte, 0, len(nodes)*ids.NodeIDLen)
+	for _, nodeID := range nodes {
+		data = append(data, nodeID[:]...)
+	}
+	return OrionSnapshot{
+		NodeCount: uint64(len(nodes)),
+		ListHash:  crypto.Keccak256Hash(data),
+	}
+}
+
+// Bytes returns the encoding of [s] in the Extra field of a header.
+func (s OrionSnapshot) Bytes() []byte {
+	b := make([]byte, params.OrionSnapshotExtraDataSize)
+	binary.BigEndian.PutUint64(b, s.NodeCount)
+	copy(b[wrappers.LongLen:], s.ListHash[:])
+	return b
+}
+
+// headerOrionSnapshot decodes the orion snapshot of the block with [header],
+// which is from OdyPhaseOrionSnapshot.
+func headerOrionSnapshot(header *types.Header) (OrionSnapshot, error) {
+	if expected := params.ApricotPhase3ExtraDataSize + params.OrionSnapshotExtraDataSize; uint64(len(header.Extra)) != expected {
+		return OrionSnapshot{}, fmt.Errorf("%w: expected extra data of %d bytes, found %d", errInvalidOrionSnapshot, expected, len(header.Extra))
+	}
+	b := header.Extra[params.ApricotPhase3ExtraDataSize:]
+	return OrionSnapshot{
+		NodeCount: binary.BigEndian.Uint64(b),
+		ListHash:  common.BytesToHash(b[wrappers.LongLen:]),
+	}, nil
+}
+
+// putOrionSnapshot encodes [snapshot] in the Extra field of [header] after its
+// rollup window.
+func putOrionSnapshot(header *types.Header, snapshot OrionSnapshot) {
+	extra := make([]byte, 0, params.ApricotPhase3ExtraDataSize+params.OrionSnapshotExtraDataSize)
+	extra = append(extra, dummy.RollupWindowBytes(header)...)
+	header.Extra = append(extra, snapshot.Bytes()...)
+}
+
+// verifyOrionSnapshot verifies that the orion snapshot encoded in the Extra
+// field of [header] is [expected].
+func verifyOrionSnapshot(header *types.Header, expected OrionSnapshot) error {
+	snapshot, err := headerOrionSnapshot(header)
+	if err != nil {
+		return err
+	}
+	if snapshot != expected {
+		return fmt.Errorf("%w: have %d nodes with list hash %s, want %d nodes with list hash %s",
+			errInvalidOrionSnapshot, snapshot.NodeCount, snapshot.ListHash, expected.NodeCount, expected.ListHash)
+	}
+	return nil
+}
+
+// orionNodes is the orion nodes list the fees of a block are distributed to,
+// along with its snapshot.
+type orionNodes struct {
+	nodes    []ids.NodeID
+	snapshot OrionSnapshot
+}
+
+func newOrionNodes(nodes []ids.NodeID) *orionNodes {
+	return &orionNodes{
+		nodes:    nodes,
+		snapshot: newOrionSnapshot(nodes),
+	}
+}
+
+// processOrionNodes returns the orion nodes list of the block with [header]
+// while it is built or processed, where [statedb] is the state after the EVM
+// txs of the block.
+//
+// The fees of the block are distributed by the count of the returned list
+// rather than by GetNodesCount, since the list itself is paid on Accept and
+// identified by the snapshot of the block. Both decode the same size slot.
+//
+// The orion contract sets its last update timestamp to the timestamp of the
+// block whenever it changes the list, so before OdyPhaseOrionSnapshot the list
+// after the txs of a block is the list of its parent unless the last update
+// timestamp reached the timestamp of the block. Only such lists are cached
+// under the root of the parent, the others are read from [statedb].
+func (vm *VM) processOrionNodes(header *types.Header, statedb *state.StateDB, rules *params.Rules) (*orionNodes, error) {
+	parent := rawdb.ReadHeader(vm.chaindb, header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("%w %s: %s", errMissingOrionParent, header.Hash(), header.ParentHash)
+	}
+	var (
+		nodes []ids.NodeID
+		err   error
+	)
+	switch {
+	case rules.IsOdyPhaseOrionSnapshot:
+		var parentState *state.StateDB
+		parentState, err = state.New(parent.Root, statedb.Database(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open state of parent %s to read orion nodes: %w", header.ParentHash, err)
+		}
+		nodes, err = rules.OrionNodes.GetNodesListAtRoot(parent.Root, parentState)
+	case rules.OrionNodes.GetLastUpdateTimestamp(statedb) >= header.Time:
+		nodes, err = rules.OrionNodes.GetNodesList(statedb)
+	default:
+		nodes, err = rules.OrionNodes.GetNodesListAtRoot(parent.Root, statedb)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read orion nodes of block %s: %w", header.Hash(), err)
+	}
+	return newOrionNodes(nodes), nil
+}
+
+// readOrionNodes reads the orion nodes list of the processed block with
+// [header] from the state it was read from when the block was processed.
+func (vm *VM) readOrionNodes(header *types.Header) (*orionNodes, error) {
+	rules, root, statedb, err := vm.orionNodesStateOf(header)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := rules.OrionNodes.GetNodesListAtRoot(root, statedb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read orion nodes of block %s: %w", header.Hash(), err)
+	}
+	return newOrionNodes(nodes), nil
+}
+
+// readOrionNodesCount reads the number of orion nodes the fees of the processed
+// block with [header] were distributed to, without reading the nodes list.
+func (vm *VM) readOrionNodesCount(header *types.Header) (uint64, error) {
+	rules, _, statedb, err := vm.orionNodesStateOf(header)
+	if err != nil {
+		return 0, err
+	}
+	count, err := rules.OrionNodes.GetNodesCount(statedb)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read orion nodes count of block %s: %w", header.Hash(), err)
+	}
+	return count, nil
+}
+
+// orionNodesStateOf returns the rules of the processed block with [header] and
+// the root and state its orion nodes were read from.
+func (vm *VM) orionNodesStateOf(header *types.Header) (params.Rules, common.Hash, *state.StateDB, error) {
+	rules := vm.chainConfig.OdysseyRules(header.Number, header.Time)
+	root := header.Root
+	if rules.IsOdyPhaseOrionSnapshot {
+		parent := vm.blockChain.GetHeaderByHash(header.ParentHash)
+		if parent == nil {
+			return rules, common.Hash{}, nil, fmt.Errorf("%w %s: %s", errMissingOrionParent, header.Hash(), header.ParentHash)
+		}
+		root = parent.Root
+	}
+	// The atomic txs of a block do not change the orion contract, so the state
+	// after the EVM txs of a block has the same orion nodes as its root.
+	statedb, err := vm.blockChain.StateAt(root)
+	if err != nil {
+		return rules, common.Hash{}, nil, fmt.Errorf("failed to open state %s to read orion nodes of block %s: %w", root, header.Hash(), err)
+	}
+	return rules, root, statedb, nil
+}
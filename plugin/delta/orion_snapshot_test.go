@@ -0,0 +1,237 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/snow/consensus/snowman"
+	engCommon "github.com/DioneProtocol/odysseygo/snow/engine/common"
+	"github.com/DioneProtocol/odysseygo/utils/units"
+	"github.com/DioneProtocol/odysseygo/vms/components/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/core/types"
+	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/utils"
+)
+
+// orionSizeSetterCode stores its first word of calldata in the size slot of
+// the orion nodes list and the block timestamp in the last update slot, as the
+// orion contract does when it changes the list.
+var orionSizeSetterCode = hexutil.MustDecode("0x6000356002554260015500")
+
+// newOrionSnapshotTestGenesis returns a genesis activating OdyPhaseOrionSnapshot
+// at [forkTimestamp] with a funded first test address and [nodes] registered in
+// the orion contract, whose code sets the size of the list to the word it is
+// called with.
+func newOrionSnapshotTestGenesis(t *testing.T, nodes []ids.NodeID, forkTimestamp uint64) string {
+	genesis := &core.Genesis{}
+	require.NoError(t, json.Unmarshal([]byte(genesisJSONLatest), genesis))
+	genesis.Config.DUpgradeBlockTimestamp = utils.NewUint64(0)
+	genesis.Config.OdyPhaseOrionSnapshotTimestamp = &forkTimestamp
+	genesis.Alloc[testEthAddrs[0]] = core.GenesisAccount{
+		Balance: new(big.Int).Mul(new(big.Int).SetUint64(units.MegaDione), x2cRate),
+	}
+	sizeSlot := common.HexToHash("0x02")
+	var size common.Hash
+	binary.BigEndian.PutUint64(size[24:], uint64(len(nodes)))
+	storage := map[common.Hash]common.Hash{sizeSlot: size}
+	listStart := crypto.Keccak256Hash(sizeSlot[:]).Big()
+	for i, nodeID := range nodes {
+		slot := common.BigToHash(new(big.Int).Add(listStart, big.NewInt(int64(i))))
+		var value common.Hash
+		copy(value[:], nodeID[:])
+		storage[slot] = value
+	}
+	genesis.Alloc[feeHistoryTestOrionContract] = core.GenesisAccount{
+		Balance: big.NewInt(1),
+		Code:    orionSizeSetterCode,
+		Storage: storage,
+	}
+	genesisJSON, err := json.Marshal(genesis)
+	require.NoError(t, err)
+	return string(genesisJSON)
+}
+
+// orionSizeCalldata returns the calldata setting the size of the orion nodes
+// list to [size].
+func orionSizeCalldata(size uint64) []byte {
+	var word common.Hash
+	binary.BigEndian.PutUint64(word[24:], size)
+	return word[:]
+}
+
+// buildOrionSnapshotTestBlock builds and verifies a block containing a tx from
+// the first test address with [nonce] to [to] with [data].
+func buildOrionSnapshotTestBlock(t *testing.T, issuer chan engCommon.Message, vm *VM, nonce uint64, to common.Address, data []byte) snowman.Block {
+	require := require.New(t)
+
+	tx := types.NewTransaction(nonce, to, big.NewInt(10), 100_000, big.NewInt(params.ApricotPhase4MaxBaseFee), data)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainID), testKeys[0].ToECDSA())
+	require.NoError(err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{signedTx}) {
+		require.NoError(err)
+	}
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	return blk
+}
+
+func TestOrionSnapshotUsesParentState(t *testing.T) {
+	require := require.New(t)
+
+	nodes := []ids.NodeID{
+		ids.GenerateTestNodeID(),
+		ids.GenerateTestNodeID(),
+		ids.GenerateTestNodeID(),
+		ids.GenerateTestNodeID(),
+	}
+	issuer, vm, _, _, _ := GenesisVM(t, true, newOrionSnapshotTestGenesis(t, nodes, 0), "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// The tx of the first block removes all but the first node from the list.
+	blk1 := buildOrionSnapshotTestBlock(t, issuer, vm, 0, feeHistoryTestOrionContract, orionSizeCalldata(1))
+	ethBlock1 := blk1.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	rules := vm.chainConfig.OdysseyRules(ethBlock1.Number(), ethBlock1.Time())
+
+	statedb, err := vm.blockChain.StateAt(ethBlock1.Root())
+	require.NoError(err)
+	count, err := rules.OrionNodes.GetNodesCount(statedb)
+	require.NoError(err)
+	require.Equal(uint64(1), count)
+
+	// The fees of the first block are still distributed to the nodes of the
+	// genesis state.
+	fees, snapshot, err := vm.GetFeeDistribution(blk1.ID())
+	require.NoError(err)
+	require.Equal(newOrionSnapshot(nodes), snapshot)
+	require.Equal(uint64(4), snapshot.NodeCount)
+	require.Equal(ethBlock1.OrionNodeFee(), fees.OrionFee)
+	require.Equal(nodes, blk1.(*chain.BlockWrapper).Block.(*Block).orionNodes.nodes)
+
+	receipts := vm.blockChain.GetReceiptsByHash(ethBlock1.Hash())
+	require.Len(receipts, 1)
+	require.Equal(types.ReceiptStatusSuccessful, receipts[0].Status)
+	totalBaseFee, totalPriorityFee := vm.calculateTxFees(ethBlock1.BaseFee(), ethBlock1.Transactions(), receipts, &rules)
+	expected, err := CalculateFees(totalBaseFee, totalPriorityFee, 4, &rules)
+	require.NoError(err)
+	require.Equal(expected.OrionFee, fees.OrionFee)
+	unexpected, err := CalculateFees(totalBaseFee, totalPriorityFee, 1, &rules)
+	require.NoError(err)
+	require.NotEqual(unexpected.OrionFee, fees.OrionFee)
+
+	service := &DioneAPI{vm: vm}
+	reply := &GetFeeDistributionReply{}
+	require.NoError(service.GetFeeDistribution(nil, &GetFeeDistributionArgs{BlockID: blk1.ID()}, reply))
+	require.Equal(uint64(4), uint64(reply.OrionNodeCount))
+	require.Equal(snapshot.ListHash, reply.OrionListHash)
+	require.Equal(expected.OrionFee, reply.OrionFee.ToInt())
+
+	// Without the nodes recorded during verification, as after a restart, the
+	// orion fee is paid to the nodes read again from the genesis state.
+	blk1.(*chain.BlockWrapper).Block.(*Block).orionNodes = nil
+	require.NoError(vm.SetPreference(context.Background(), blk1.ID()))
+	require.NoError(blk1.Accept(context.Background()))
+	for _, nodeID := range nodes {
+		require.Positive(vm.ctx.FeeCollector.GetOrionValue(nodeID))
+	}
+
+	// The fees of the next block are distributed to the remaining node.
+	vm.clock.Set(blk1.Timestamp().Add(time.Duration(vm.chainConfig.GetTargetBlockRate(uint64(blk1.Timestamp().Unix()))) * time.Second))
+	blk2 := buildOrionSnapshotTestBlock(t, issuer, vm, 1, testEthAddrs[1], nil)
+	_, snapshot, err = vm.GetFeeDistribution(blk2.ID())
+	require.NoError(err)
+	require.Equal(newOrionSnapshot(nodes[:1]), snapshot)
+}
+
+func TestOrionSnapshotReexecution(t *testing.T) {
+	require := require.New(t)
+
+	nodes := []ids.NodeID{
+		ids.GenerateTestNodeID(),
+		ids.GenerateTestNodeID(),
+		ids.GenerateTestNodeID(),
+		ids.GenerateTestNodeID(),
+	}
+	forkTime := time.Now().Truncate(time.Second)
+	genesisJSON := newOrionSnapshotTestGenesis(t, nodes, uint64(forkTime.Unix()))
+	issuer, vm, _, _, _ := GenesisVM(t, true, genesisJSON, "", "")
+	_, reexecVM, _, _, _ := GenesisVM(t, true, genesisJSON, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+		require.NoError(reexecVM.Shutdown(context.Background()))
+	}()
+
+	// Before the fork, the fees of a block changing the list are distributed
+	// to the list after its txs.
+	vm.clock.Set(forkTime.Add(-10 * time.Second))
+	blk1 := buildOrionSnapshotTestBlock(t, issuer, vm, 0, feeHistoryTestOrionContract, orionSizeCalldata(2))
+	require.NoError(vm.SetPreference(context.Background(), blk1.ID()))
+	require.NoError(blk1.Accept(context.Background()))
+	_, snapshot, err := vm.GetFeeDistribution(blk1.ID())
+	require.NoError(err)
+	require.Equal(newOrionSnapshot(nodes[:2]), snapshot)
+
+	// After the fork, they are distributed to the list of the parent.
+	vm.clock.Set(forkTime)
+	blk2 := buildOrionSnapshotTestBlock(t, issuer, vm, 1, feeHistoryTestOrionContract, orionSizeCalldata(1))
+	require.NoError(vm.SetPreference(context.Background(), blk2.ID()))
+	require.NoError(blk2.Accept(context.Background()))
+	_, snapshot, err = vm.GetFeeDistribution(blk2.ID())
+	require.NoError(err)
+	require.Equal(newOrionSnapshot(nodes[:2]), snapshot)
+
+	// Re-executing both blocks reproduces their state roots and orion fees.
+	reexecVM.clock.Set(forkTime)
+	for _, blk := range []snowman.Block{blk1, blk2} {
+		reexecBlk, err := reexecVM.ParseBlock(context.Background(), blk.Bytes())
+		require.NoError(err)
+		require.NoError(reexecBlk.Verify(context.Background()))
+		require.NoError(reexecVM.SetPreference(context.Background(), reexecBlk.ID()))
+		require.NoError(reexecBlk.Accept(context.Background()))
+
+		ethBlock := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+		reexecEthBlock := reexecBlk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+		require.Equal(blk.ID(), reexecBlk.ID())
+		require.True(reexecVM.blockChain.HasState(ethBlock.Root()))
+		require.Equal(ethBlock.OrionNodeFee(), reexecEthBlock.OrionNodeFee())
+		require.Equal(
+			blk.(*chain.BlockWrapper).Block.(*Block).orionNodes.snapshot,
+			reexecBlk.(*chain.BlockWrapper).Block.(*Block).orionNodes.snapshot,
+		)
+	}
+	// The snapshot is only encoded in the headers of blocks from the fork.
+	ethBlock1 := blk1.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(ethBlock1.Extra(), int(params.ApricotPhase3ExtraDataSize))
+	ethBlock2 := blk2.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	snapshot, err = headerOrionSnapshot(ethBlock2.Header())
+	require.NoError(err)
+	require.Equal(newOrionSnapshot(nodes[:2]), snapshot)
+
+	// A block whose header encodes another snapshot fails verification.
+	header := ethBlock2.Header()
+	putOrionSnapshot(header, newOrionSnapshot(nodes[:1]))
+	tamperedBytes, err := rlp.EncodeToBytes(ethBlock2.WithSeal(header))
+	require.NoError(err)
+	tamperedBlk, err := reexecVM.ParseBlock(context.Background(), tamperedBytes)
+	require.NoError(err)
+	require.ErrorIs(tamperedBlk.Verify(context.Background()), errInvalidOrionSnapshot)
+}
@@ -9,12 +9,16 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/DioneProtocol/coreth/params"
+	"github.com/DioneProtocol/coreth/rpc"
 	"github.com/DioneProtocol/odysseygo/api"
 	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils"
 	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
 	"github.com/DioneProtocol/odysseygo/utils/formatting"
+	"github.com/DioneProtocol/odysseygo/utils/hashing"
 	"github.com/DioneProtocol/odysseygo/utils/json"
 	"github.com/DioneProtocol/odysseygo/utils/set"
 	"github.com/ethereum/go-ethereum/common"
@@ -29,13 +33,21 @@ const (
 
 	// Max number of addresses that can be passed in as argument to GetUTXOs
 	maxGetUTXOsAddrs = 1024
+
+	// Max number of txIDs that can be passed in as argument to GetAtomicTxStatuses
+	maxGetAtomicTxStatusesTxIDs = 1024
+
+	// Time a tx returned by BuildExportTx is expected to remain valid
+	buildExportTxTTL = time.Minute
 )
 
 var (
-	errNoAddresses       = errors.New("no addresses provided")
-	errNoSourceChain     = errors.New("no source chain provided")
-	errNilTxID           = errors.New("nil transaction ID")
-	errMissingPrivateKey = errors.New("argument 'privateKey' not given")
+	errNoAddresses         = errors.New("no addresses provided")
+	errNoSourceChain       = errors.New("no source chain provided")
+	errNilTxID             = errors.New("nil transaction ID")
+	errMissingPrivateKey   = errors.New("argument 'privateKey' not given")
+	errMaxBaseFeeTooLow    = errors.New("argument 'maxBaseFee' is below the minimum base fee")
+	errNegativePriorityFee = errors.New("argument 'priorityFee' must be >= 0")
 
 	initialBaseFee = big.NewInt(params.ApricotPhase3InitialBaseFee)
 )
@@ -170,11 +182,32 @@ type ImportArgs struct {
 	// Fee that should be used when creating the tx
 	BaseFee *hexutil.Big `json:"baseFee"`
 
+	// Maximum base fee the tx may pay. Optional. The tx is dropped from the
+	// mempool if the base fee rises above it before the tx is included.
+	MaxBaseFee *hexutil.Big `json:"maxBaseFee"`
+
+	// Fee paid on top of the base fee. Optional.
+	PriorityFee *hexutil.Big `json:"priorityFee"`
+
+	// Skip the atomic tx fee limits configured for this node. Optional.
+	AllowHighFee bool `json:"allowHighFee"`
+
 	// Chain the funds are coming from
 	SourceChain string `json:"sourceChain"`
 
-	// The address that will receive the imported funds
+	// The address that will receive the imported funds, or the funds left over
+	// once [Outputs] are credited, if [Outputs] are set
 	To common.Address `json:"to"`
+
+	// Outputs to credit with the imported funds. Optional.
+	Outputs []ImportOutput `json:"outputs"`
+}
+
+// ImportOutput is an amount of an asset to credit to an address by Import
+type ImportOutput struct {
+	Address common.Address `json:"address"`
+	Amount  json.Uint64    `json:"amount"`
+	AssetID string         `json:"assetID"`
 }
 
 // ImportDIONE is a deprecated name for Import.
@@ -208,24 +241,46 @@ func (service *DioneAPI) Import(_ *http.Request, args *ImportArgs, response *api
 		return fmt.Errorf("couldn't get keys controlled by the user: %w", err)
 	}
 
-	var baseFee *big.Int
-	if args.BaseFee == nil {
-		// Get the base fee to use
-		baseFee, err = service.vm.estimateBaseFee(context.Background())
+	baseFee, err := service.atomicTxBaseFee(args.BaseFee, args.MaxBaseFee, args.PriorityFee)
+	if err != nil {
+		return err
+	}
+
+	var tx *Tx
+	if len(args.Outputs) == 0 {
+		tx, err = service.vm.newImportTx(chainID, args.To, baseFee, privKeys, args.AllowHighFee)
+	} else {
+		var outputs []DELTAOutput
+		outputs, err = service.parseImportOutputs(args.Outputs)
 		if err != nil {
 			return err
 		}
-	} else {
-		baseFee = args.BaseFee.ToInt()
+		tx, err = service.vm.newImportTxSplit(chainID, outputs, args.To, baseFee, privKeys, args.AllowHighFee)
 	}
-
-	tx, err := service.vm.newImportTx(chainID, args.To, baseFee, privKeys)
 	if err != nil {
 		return err
 	}
 
 	response.TxID = tx.ID()
-	return service.vm.issueTx(tx, true /*=local*/)
+	return service.vm.issueTxWithFeeCap(tx, true /*=local*/, args.MaxBaseFee.ToInt())
+}
+
+// parseImportOutputs parses [outputs] into sorted DELTA outputs
+func (service *DioneAPI) parseImportOutputs(outputs []ImportOutput) ([]DELTAOutput, error) {
+	outs := make([]DELTAOutput, len(outputs))
+	for i, output := range outputs {
+		assetID, err := service.parseAssetID(output.AssetID)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse assetID of output %d: %w", i, err)
+		}
+		outs[i] = DELTAOutput{
+			Address: output.Address,
+			Amount:  uint64(output.Amount),
+			AssetID: assetID,
+		}
+	}
+	SortDELTAOutputs(outs)
+	return outs, nil
 }
 
 // ExportDIONEArgs are the arguments to ExportDIONE
@@ -235,6 +290,16 @@ type ExportDIONEArgs struct {
 	// Fee that should be used when creating the tx
 	BaseFee *hexutil.Big `json:"baseFee"`
 
+	// Maximum base fee the tx may pay. Optional. The tx is dropped from the
+	// mempool if the base fee rises above it before the tx is included.
+	MaxBaseFee *hexutil.Big `json:"maxBaseFee"`
+
+	// Fee paid on top of the base fee. Optional.
+	PriorityFee *hexutil.Big `json:"priorityFee"`
+
+	// Skip the atomic tx fee limits configured for this node. Optional.
+	AllowHighFee bool `json:"allowHighFee"`
+
 	// Amount of asset to send
 	Amount json.Uint64 `json:"amount"`
 
@@ -277,17 +342,9 @@ func (service *DioneAPI) Export(_ *http.Request, args *ExportArgs, response *api
 		return errors.New("argument 'amount' must be > 0")
 	}
 
-	// Get the chainID and parse the to address
-	chainID, to, err := service.vm.ParseAddress(args.To)
+	chainID, to, err := service.parseExportDestination(args.To, args.TargetChain)
 	if err != nil {
-		chainID, err = service.vm.ctx.BCLookup.Lookup(args.TargetChain)
-		if err != nil {
-			return err
-		}
-		to, err = ids.ShortFromString(args.To)
-		if err != nil {
-			return err
-		}
+		return err
 	}
 
 	// Get this user's data
@@ -306,15 +363,9 @@ func (service *DioneAPI) Export(_ *http.Request, args *ExportArgs, response *api
 		return fmt.Errorf("couldn't get addresses controlled by the user: %w", err)
 	}
 
-	var baseFee *big.Int
-	if args.BaseFee == nil {
-		// Get the base fee to use
-		baseFee, err = service.vm.estimateBaseFee(context.Background())
-		if err != nil {
-			return err
-		}
-	} else {
-		baseFee = args.BaseFee.ToInt()
+	baseFee, err := service.atomicTxBaseFee(args.BaseFee, args.MaxBaseFee, args.PriorityFee)
+	if err != nil {
+		return err
 	}
 
 	// Create the transaction
@@ -325,13 +376,198 @@ func (service *DioneAPI) Export(_ *http.Request, args *ExportArgs, response *api
 		to,                  // Address
 		baseFee,
 		privKeys, // Private keys
+		args.AllowHighFee,
 	)
 	if err != nil {
 		return fmt.Errorf("couldn't create tx: %w", err)
 	}
 
 	response.TxID = tx.ID()
-	return service.vm.issueTx(tx, true /*=local*/)
+	return service.vm.issueTxWithFeeCap(tx, true /*=local*/, args.MaxBaseFee.ToInt())
+}
+
+// BuildExportTxArgs are the arguments to BuildExportTx
+type BuildExportTxArgs struct {
+	// Fee that should be used when creating the tx
+	BaseFee *hexutil.Big `json:"baseFee"`
+
+	// Maximum base fee the tx may pay. Optional.
+	MaxBaseFee *hexutil.Big `json:"maxBaseFee"`
+
+	// Fee paid on top of the base fee. Optional.
+	PriorityFee *hexutil.Big `json:"priorityFee"`
+
+	// Skip the atomic tx fee limits configured for this node. Optional.
+	AllowHighFee bool `json:"allowHighFee"`
+
+	// AssetID of the tokens. Defaults to DIONE.
+	AssetID string `json:"assetID"`
+
+	// Amount of asset to send
+	Amount json.Uint64 `json:"amount"`
+
+	// Chain the funds are going to. Optional. Used if To address does not
+	// include the chainID.
+	TargetChain string `json:"targetChain"`
+
+	// ID of the address that will receive the funds. This address may include
+	// the chainID, which is used to determine what the destination chain is.
+	To string `json:"to"`
+
+	// The address that provides the exported funds and pays the fee
+	From common.Address `json:"from"`
+
+	// Encoding of the returned unsigned tx
+	Encoding formatting.Encoding `json:"encoding"`
+}
+
+// UnsignedExportInput is an input of an unsigned export tx together with the
+// digest its owner must sign.
+type UnsignedExportInput struct {
+	Address common.Address `json:"address"`
+	AssetID ids.ID         `json:"assetID"`
+	Amount  json.Uint64    `json:"amount"`
+	Nonce   json.Uint64    `json:"nonce"`
+	Digest  hexutil.Bytes  `json:"digest"`
+}
+
+// BuildExportTxReply is the response from BuildExportTx
+type BuildExportTxReply struct {
+	// The unsigned tx bytes
+	Tx       string              `json:"tx"`
+	Encoding formatting.Encoding `json:"encoding"`
+
+	// Inputs of the tx, in the order their credentials must be attached
+	Inputs []UnsignedExportInput `json:"inputs"`
+
+	// Number of seconds the nonces fixed in the tx are expected to remain
+	// valid. The tx must be signed and issued before then, and becomes
+	// invalid earlier if [From] issues any other tx.
+	TTL json.Uint64 `json:"ttl"`
+}
+
+// BuildExportTx builds an export tx that spends the funds of an address whose
+// key is not held by this node. The returned tx must be signed externally by
+// attaching one credential per input, each signing the input's digest, and
+// can then be issued with IssueTx.
+func (service *DioneAPI) BuildExportTx(_ *http.Request, args *BuildExportTxArgs, reply *BuildExportTxReply) error {
+	log.Info("DELTA: BuildExportTx called", "from", args.From)
+
+	assetID := service.vm.ctx.DIONEAssetID
+	if args.AssetID != "" {
+		var err error
+		assetID, err = service.parseAssetID(args.AssetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Amount == 0 {
+		return errors.New("argument 'amount' must be > 0")
+	}
+
+	chainID, to, err := service.parseExportDestination(args.To, args.TargetChain)
+	if err != nil {
+		return err
+	}
+
+	baseFee, err := service.atomicTxBaseFee(args.BaseFee, args.MaxBaseFee, args.PriorityFee)
+	if err != nil {
+		return err
+	}
+
+	utx, err := service.vm.newUnsignedExportTx(
+		assetID,
+		uint64(args.Amount),
+		chainID,
+		to,
+		[]common.Address{args.From},
+		baseFee,
+		args.AllowHighFee,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't create tx: %w", err)
+	}
+	if err := utx.Verify(service.vm.ctx, service.vm.currentRules()); err != nil {
+		return fmt.Errorf("couldn't create tx: %w", err)
+	}
+
+	var unsignedTx UnsignedAtomicTx = utx
+	unsignedBytes, err := service.vm.codec.Marshal(codecVersion, &unsignedTx)
+	if err != nil {
+		return fmt.Errorf("problem marshalling tx: %w", err)
+	}
+	reply.Tx, err = formatting.Encode(args.Encoding, unsignedBytes)
+	if err != nil {
+		return fmt.Errorf("problem encoding tx: %w", err)
+	}
+	reply.Encoding = args.Encoding
+
+	digest := hashing.ComputeHash256(unsignedBytes)
+	reply.Inputs = make([]UnsignedExportInput, len(utx.Ins))
+	for i, in := range utx.Ins {
+		reply.Inputs[i] = UnsignedExportInput{
+			Address: in.Address,
+			AssetID: in.AssetID,
+			Amount:  json.Uint64(in.Amount),
+			Nonce:   json.Uint64(in.Nonce),
+			Digest:  digest,
+		}
+	}
+	reply.TTL = json.Uint64(buildExportTxTTL / time.Second)
+	return nil
+}
+
+// parseExportDestination returns the chain and address that [to] refers to.
+// If [to] does not include the chainID, [targetChain] is used instead.
+func (service *DioneAPI) parseExportDestination(to, targetChain string) (ids.ID, ids.ShortID, error) {
+	chainID, addr, err := service.vm.ParseAddress(to)
+	if err == nil {
+		return chainID, addr, nil
+	}
+	chainID, err = service.vm.ctx.BCLookup.Lookup(targetChain)
+	if err != nil {
+		return ids.ID{}, ids.ShortID{}, err
+	}
+	addr, err = ids.ShortFromString(to)
+	if err != nil {
+		return ids.ID{}, ids.ShortID{}, err
+	}
+	return chainID, addr, nil
+}
+
+// atomicTxBaseFee returns the base fee to create an atomic tx with. If
+// [baseFee] is nil, the base fee is estimated. The result is capped at
+// [maxBaseFee] and [priorityFee] is added on top of it, if they are non-nil.
+func (service *DioneAPI) atomicTxBaseFee(baseFee, maxBaseFee, priorityFee *hexutil.Big) (*big.Int, error) {
+	var fee *big.Int
+	if baseFee == nil {
+		// Get the base fee to use
+		estimatedBaseFee, err := service.vm.estimateBaseFee(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		fee = new(big.Int).Set(estimatedBaseFee)
+	} else {
+		fee = new(big.Int).Set(baseFee.ToInt())
+	}
+
+	if maxBaseFee != nil {
+		minBaseFee := service.vm.minBaseFee()
+		if maxBaseFee.ToInt().Cmp(minBaseFee) < 0 {
+			return nil, fmt.Errorf("%w: %d < %d", errMaxBaseFeeTooLow, maxBaseFee.ToInt(), minBaseFee)
+		}
+		if fee.Cmp(maxBaseFee.ToInt()) > 0 {
+			fee.Set(maxBaseFee.ToInt())
+		}
+	}
+	if priorityFee != nil {
+		if priorityFee.ToInt().Sign() < 0 {
+			return nil, errNegativePriorityFee
+		}
+		fee.Add(fee, priorityFee.ToInt())
+	}
+	return fee, nil
 }
 
 // GetUTXOs gets all utxos for passed in addresses
@@ -417,21 +653,62 @@ func (service *DioneAPI) GetUTXOs(r *http.Request, args *api.GetUTXOsArgs, reply
 func (service *DioneAPI) IssueTx(r *http.Request, args *api.FormattedTx, response *api.JSONTxID) error {
 	log.Info("DELTA: IssueTx called")
 
+	tx, err := service.parseTx(args)
+	if err != nil {
+		return err
+	}
+
+	response.TxID = tx.ID()
+	return service.vm.issueTx(tx, true /*=local*/)
+}
+
+// parseTx decodes and initializes the atomic transaction in [args]
+func (service *DioneAPI) parseTx(args *api.FormattedTx) (*Tx, error) {
 	txBytes, err := formatting.Decode(args.Encoding, args.Tx)
 	if err != nil {
-		return fmt.Errorf("problem decoding transaction: %w", err)
+		return nil, fmt.Errorf("problem decoding transaction: %w", err)
 	}
 
 	tx := &Tx{}
 	if _, err := service.vm.codec.Unmarshal(txBytes, tx); err != nil {
-		return fmt.Errorf("problem parsing transaction: %w", err)
+		return nil, fmt.Errorf("problem parsing transaction: %w", err)
 	}
 	if err := tx.Sign(service.vm.codec, nil); err != nil {
-		return fmt.Errorf("problem initializing transaction: %w", err)
+		return nil, fmt.Errorf("problem initializing transaction: %w", err)
 	}
+	return tx, nil
+}
 
-	response.TxID = tx.ID()
-	return service.vm.issueTx(tx, true /*=local*/)
+// ValidateAtomicTxReply defines the ValidateAtomicTx replies returned from the API
+type ValidateAtomicTxReply struct {
+	Valid bool              `json:"valid"`
+	Kind  AtomicTxErrorKind `json:"kind,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+// ValidateAtomicTx verifies an atomic transaction against the last accepted
+// block without issuing it
+func (service *DioneAPI) ValidateAtomicTx(r *http.Request, args *api.FormattedTx, reply *ValidateAtomicTxReply) error {
+	log.Info("DELTA: ValidateAtomicTx called")
+
+	tx, err := service.parseTx(args)
+	if err != nil {
+		err = &AtomicTxValidationError{Kind: AtomicTxErrInvalidFormat, Err: err}
+	} else {
+		err = service.vm.ValidateAtomicTx(r.Context(), tx)
+	}
+
+	var validationErr *AtomicTxValidationError
+	switch {
+	case err == nil:
+		reply.Valid = true
+	case errors.As(err, &validationErr):
+		reply.Kind = validationErr.Kind
+		reply.Error = validationErr.Err.Error()
+	default:
+		return err
+	}
+	return nil
 }
 
 // GetAtomicTxStatusReply defines the GetAtomicTxStatus replies returned from the API
@@ -458,11 +735,484 @@ func (service *DioneAPI) GetAtomicTxStatus(r *http.Request, args *api.JSONTxID,
 	return nil
 }
 
+// GetAtomicTxStatusesArgs are the arguments to GetAtomicTxStatuses
+type GetAtomicTxStatusesArgs struct {
+	TxIDs []ids.ID `json:"txIDs"`
+}
+
+// AtomicTxStatus is the status of an atomic tx returned from
+// GetAtomicTxStatuses
+type AtomicTxStatus struct {
+	TxID        ids.ID       `json:"txID"`
+	Status      Status       `json:"status"`
+	BlockHeight *json.Uint64 `json:"blockHeight,omitempty"`
+}
+
+// GetAtomicTxStatusesReply defines the GetAtomicTxStatuses replies returned
+// from the API
+type GetAtomicTxStatusesReply struct {
+	Statuses []AtomicTxStatus `json:"statuses"`
+}
+
+// GetAtomicTxStatuses returns the status of each of the specified
+// transactions, in the order they are specified
+func (service *DioneAPI) GetAtomicTxStatuses(r *http.Request, args *GetAtomicTxStatusesArgs, reply *GetAtomicTxStatusesReply) error {
+	log.Info("DELTA: GetAtomicTxStatuses called", "numTxIDs", len(args.TxIDs))
+
+	if len(args.TxIDs) > maxGetAtomicTxStatusesTxIDs {
+		return fmt.Errorf("number of txIDs given, %d, exceeds maximum, %d", len(args.TxIDs), maxGetAtomicTxStatusesTxIDs)
+	}
+
+	reply.Statuses = make([]AtomicTxStatus, len(args.TxIDs))
+	for i, txID := range args.TxIDs {
+		if txID == ids.Empty {
+			return fmt.Errorf("%w at index %d", errNilTxID, i)
+		}
+
+		_, status, height, _ := service.vm.getAtomicTx(txID)
+
+		reply.Statuses[i] = AtomicTxStatus{
+			TxID:   txID,
+			Status: status,
+		}
+		if status == Accepted {
+			jsonHeight := json.Uint64(height)
+			reply.Statuses[i].BlockHeight = &jsonHeight
+		}
+	}
+	return nil
+}
+
+// VerifiedAtomicStateReply describes the atomic state of a block that has
+// been verified but not accepted or rejected yet
+type VerifiedAtomicStateReply struct {
+	BlockHash   common.Hash `json:"blockHash"`
+	BlockHeight json.Uint64 `json:"blockHeight"`
+	Root        common.Hash `json:"root"`
+	NumTxs      json.Uint32 `json:"numTxs"`
+}
+
+// AtomicHealthReply defines the state of the atomic trie returned from
+// AtomicHealth
+type AtomicHealthReply struct {
+	LastCommittedHeight     json.Uint64 `json:"lastCommittedHeight"`
+	LastCommittedRoot       common.Hash `json:"lastCommittedRoot"`
+	ExpectedCommittedHeight json.Uint64 `json:"expectedCommittedHeight"`
+	CommittedHeightMatches  bool        `json:"committedHeightMatches"`
+	LastAcceptedHeight      json.Uint64 `json:"lastAcceptedHeight"`
+	LastAcceptedBlock       common.Hash `json:"lastAcceptedBlock"`
+	// LastAcceptedAtomicBlock is the last block accepted by the atomic backend.
+	LastAcceptedAtomicBlock common.Hash                `json:"lastAcceptedAtomicBlock"`
+	LastAcceptedRoot        common.Hash                `json:"lastAcceptedRoot"`
+	LastAcceptedMatches     bool                       `json:"lastAcceptedMatches"`
+	Verified                []VerifiedAtomicStateReply `json:"verified"`
+}
+
+// AtomicHealth returns the last committed height of the atomic trie, whether
+// it is consistent with the last accepted block, and the atomic states of the
+// blocks that are verified but not yet accepted or rejected
+func (service *DioneAPI) AtomicHealth(_ *http.Request, _ *struct{}, reply *AtomicHealthReply) error {
+	log.Info("DELTA: AtomicHealth called")
+
+	health, err := service.vm.AtomicHealth()
+	if err != nil {
+		return err
+	}
+	reply.LastCommittedHeight = json.Uint64(health.LastCommittedHeight)
+	reply.LastCommittedRoot = health.LastCommittedRoot
+	reply.ExpectedCommittedHeight = json.Uint64(health.ExpectedCommittedHeight)
+	reply.CommittedHeightMatches = health.CommittedHeightMatches
+	reply.LastAcceptedHeight = json.Uint64(health.LastAcceptedHeight)
+	reply.LastAcceptedBlock = health.LastAcceptedBlock
+	reply.LastAcceptedAtomicBlock = health.LastAcceptedHash
+	reply.LastAcceptedRoot = health.LastAcceptedRoot
+	reply.LastAcceptedMatches = health.LastAcceptedMatches
+	reply.Verified = make([]VerifiedAtomicStateReply, len(health.Verified))
+	for i, verified := range health.Verified {
+		reply.Verified[i] = VerifiedAtomicStateReply{
+			BlockHash:   verified.BlockHash,
+			BlockHeight: json.Uint64(verified.BlockHeight),
+			Root:        verified.Root,
+			NumTxs:      json.Uint32(verified.NumTxs),
+		}
+	}
+	return nil
+}
+
+// GasPriceStatusReply defines the gas price and minimum fee enforced by the tx pool
+type GasPriceStatusReply struct {
+	GasPrice *hexutil.Big `json:"gasPrice"`
+	MinFee   *hexutil.Big `json:"minFee"`
+}
+
+// GetGasPriceStatus returns the gas price and minimum fee last applied to the tx pool
+func (service *DioneAPI) GetGasPriceStatus(r *http.Request, _ *struct{}, reply *GasPriceStatusReply) error {
+	log.Info("DELTA: GetGasPriceStatus called")
+
+	price, minFee := service.vm.gasPriceStatus.Status()
+	reply.GasPrice = (*hexutil.Big)(price)
+	reply.MinFee = (*hexutil.Big)(minFee)
+	return nil
+}
+
+// EstimateBaseFeeArgs are the arguments to EstimateBaseFee
+type EstimateBaseFeeArgs struct {
+	// IncludeMempool also estimates the base fee with the gas of the pending
+	// atomic and EVM txs added into the rollup window.
+	IncludeMempool bool `json:"includeMempool"`
+}
+
+// EstimateBaseFeeReply defines the base fee estimates returned from EstimateBaseFee
+type EstimateBaseFeeReply struct {
+	// BaseFee is the estimate from the gas consumed by the preferred block only.
+	BaseFee *hexutil.Big `json:"baseFee"`
+	// MempoolBaseFee is the estimate including the gas of the pending txs, if
+	// requested.
+	MempoolBaseFee *hexutil.Big `json:"mempoolBaseFee,omitempty"`
+}
+
+// EstimateBaseFee returns the estimated base fee of a block built now on top of
+// the preferred block
+func (service *DioneAPI) EstimateBaseFee(_ *http.Request, args *EstimateBaseFeeArgs, reply *EstimateBaseFeeReply) error {
+	log.Info("DELTA: EstimateBaseFee called", "includeMempool", args.IncludeMempool)
+
+	parentOnly, mempoolAware, err := service.vm.EstimateNextBaseFee(args.IncludeMempool)
+	if err != nil {
+		return err
+	}
+	reply.BaseFee = (*hexutil.Big)(parentOnly)
+	reply.MempoolBaseFee = (*hexutil.Big)(mempoolAware)
+	return nil
+}
+
+// GetAssetConversionRateReply defines the conversion rate returned from
+// GetAssetConversionRate
+type GetAssetConversionRateReply struct {
+	// Rate is the number of wei per nDIONE.
+	Rate *hexutil.Big `json:"rate"`
+}
+
+// GetAssetConversionRate returns the rate between nDIONE, the denomination of
+// DIONE on the A and O chains, and wei, the denomination of DIONE on the
+// D-Chain, under the rules of the current block
+func (service *DioneAPI) GetAssetConversionRate(_ *http.Request, _ *struct{}, reply *GetAssetConversionRateReply) error {
+	log.Info("DELTA: GetAssetConversionRate called")
+
+	rules := service.vm.currentRules()
+	reply.Rate = (*hexutil.Big)(rules.X2CRate())
+	return nil
+}
+
+// GetBaseFeeWindowArgs are the arguments to GetBaseFeeWindow
+type GetBaseFeeWindowArgs struct {
+	BlockID ids.ID `json:"blockID"`
+}
+
+// GetBaseFeeWindowReply defines the rollup window returned from
+// GetBaseFeeWindow
+type GetBaseFeeWindowReply struct {
+	// Window is the gas consumed in each second of the rollup window, oldest
+	// first.
+	Window  []json.Uint64 `json:"window"`
+	BaseFee *hexutil.Big  `json:"baseFee"`
+}
+
+// GetBaseFeeWindow returns the rollup window of the dynamic fees encoded in a
+// block and the base fee of the block
+func (service *DioneAPI) GetBaseFeeWindow(_ *http.Request, args *GetBaseFeeWindowArgs, reply *GetBaseFeeWindowReply) error {
+	log.Info("DELTA: GetBaseFeeWindow called", "blockID", args.BlockID)
+
+	window, baseFee, err := service.vm.GetBaseFeeWindow(args.BlockID)
+	if err != nil {
+		return err
+	}
+	reply.Window = make([]json.Uint64, len(window))
+	for i, gas := range window {
+		reply.Window[i] = json.Uint64(gas)
+	}
+	reply.BaseFee = (*hexutil.Big)(baseFee)
+	return nil
+}
+
+// GetChainConfigReply defines the chain config returned from GetChainConfig
+type GetChainConfigReply struct {
+	ChainConfig *params.ChainConfig `json:"chainConfig"`
+	ActiveRules *ActiveRules        `json:"activeRules"`
+}
+
+// GetChainConfig returns the chain config, including its fork schedule, and
+// the rules that the preferred block was produced under
+func (service *DioneAPI) GetChainConfig(_ *http.Request, _ *struct{}, reply *GetChainConfigReply) error {
+	log.Info("DELTA: GetChainConfig called")
+
+	rules, err := service.vm.GetActiveRules()
+	if err != nil {
+		return err
+	}
+	reply.ChainConfig = service.vm.chainConfig
+	reply.ActiveRules = rules
+	return nil
+}
+
+// GetCodecVersionReply defines the codec version returned from GetCodecVersion
+type GetCodecVersionReply struct {
+	CodecVersion json.Uint16 `json:"codecVersion"`
+}
+
+// GetCodecVersion returns the version of the codec that the node encodes and
+// decodes atomic txs with
+func (service *DioneAPI) GetCodecVersion(_ *http.Request, _ *struct{}, reply *GetCodecVersionReply) error {
+	log.Info("DELTA: GetCodecVersion called")
+
+	reply.CodecVersion = json.Uint16(codecVersion)
+	return nil
+}
+
+// GetOrionNodesArgs are the arguments to GetOrionNodes
+type GetOrionNodesArgs struct {
+	Timestamp json.Uint64 `json:"timestamp"`
+}
+
+// GetOrionNodesReply defines the orion nodes returned from GetOrionNodes
+type GetOrionNodesReply struct {
+	NodeIDs []ids.NodeID `json:"nodeIDs"`
+}
+
+// GetOrionNodes returns the orion nodes registered in the state of the last
+// accepted block at or before the requested timestamp
+func (service *DioneAPI) GetOrionNodes(r *http.Request, args *GetOrionNodesArgs, reply *GetOrionNodesReply) error {
+	log.Info("DELTA: GetOrionNodes called", "timestamp", args.Timestamp)
+
+	nodeIDs, err := service.vm.GetOrionNodes(context.Background(), uint64(args.Timestamp))
+	if err != nil {
+		return err
+	}
+	reply.NodeIDs = nodeIDs
+	return nil
+}
+
+// GetBurnedFeesArgs are the arguments to GetBurnedFees
+type GetBurnedFeesArgs struct {
+	StartHeight json.Uint64 `json:"startHeight"`
+	// EndHeight is the last height of the range. Defaults to StartHeight.
+	EndHeight *json.Uint64 `json:"endHeight,omitempty"`
+}
+
+// GetBurnedFeesReply defines the burned fees returned from GetBurnedFees
+type GetBurnedFeesReply struct {
+	Fees []BurnedFees `json:"fees"`
+}
+
+// GetBurnedFees returns the fees burned by atomic txs and EVM base fees in each
+// accepted block of the requested range, along with the cumulative totals over
+// the range
+func (service *DioneAPI) GetBurnedFees(_ *http.Request, args *GetBurnedFeesArgs, reply *GetBurnedFeesReply) error {
+	log.Info("DELTA: GetBurnedFees called", "startHeight", args.StartHeight, "endHeight", args.EndHeight)
+
+	end := args.StartHeight
+	if args.EndHeight != nil {
+		end = *args.EndHeight
+	}
+	fees, err := service.vm.GetBurnedFees(uint64(args.StartHeight), uint64(end))
+	if err != nil {
+		return err
+	}
+	reply.Fees = fees
+	return nil
+}
+
+// FeeRecipientByBlockArgs are the arguments to FeeRecipientByBlock
+type FeeRecipientByBlockArgs struct {
+	BlockID ids.ID `json:"blockID"`
+}
+
+// FeeRecipientByBlockReply defines the fee recipients returned from
+// FeeRecipientByBlock
+type FeeRecipientByBlockReply struct {
+	Recipients map[common.Address]*hexutil.Big `json:"recipients"`
+}
+
+// FeeRecipientByBlock returns the amount of the EVM tx fees of a block credited
+// to each address by the fee distribution
+func (service *DioneAPI) FeeRecipientByBlock(_ *http.Request, args *FeeRecipientByBlockArgs, reply *FeeRecipientByBlockReply) error {
+	log.Info("DELTA: FeeRecipientByBlock called", "blockID", args.BlockID)
+
+	recipients, err := service.vm.FeeRecipientByBlock(args.BlockID)
+	if err != nil {
+		return err
+	}
+	reply.Recipients = make(map[common.Address]*hexutil.Big, len(recipients))
+	for addr, amount := range recipients {
+		reply.Recipients[addr] = (*hexutil.Big)(amount)
+	}
+	return nil
+}
+
+// GetFeeDistributionArgs are the arguments to GetFeeDistribution
+type GetFeeDistributionArgs struct {
+	BlockID ids.ID `json:"blockID"`
+}
+
+// GetFeeDistributionReply defines the fee distribution returned from
+// GetFeeDistribution
+type GetFeeDistributionReply struct {
+	BaseFee              *hexutil.Big `json:"baseFee"`
+	PriorityFee          *hexutil.Big `json:"priorityFee"`
+	LpAllocation         *hexutil.Big `json:"lpAllocation"`
+	GovernanceAllocation *hexutil.Big `json:"governanceAllocation"`
+	OrionFee             *hexutil.Big `json:"orionFee"`
+	// OrionNodeCount and OrionListHash identify the orion nodes list that the
+	// fees were distributed to
+	OrionNodeCount json.Uint64 `json:"orionNodeCount"`
+	OrionListHash  common.Hash `json:"orionListHash"`
+}
+
+// GetFeeDistribution returns the distribution of the EVM tx fees of a block
+// and the orion nodes list it was calculated with
+func (service *DioneAPI) GetFeeDistribution(_ *http.Request, args *GetFeeDistributionArgs, reply *GetFeeDistributionReply) error {
+	log.Info("DELTA: GetFeeDistribution called", "blockID", args.BlockID)
+
+	fees, snapshot, err := service.vm.GetFeeDistribution(args.BlockID)
+	if err != nil {
+		return err
+	}
+	reply.BaseFee = (*hexutil.Big)(fees.BaseFee)
+	reply.PriorityFee = (*hexutil.Big)(fees.PriorityFee)
+	reply.LpAllocation = (*hexutil.Big)(fees.LpAllocation)
+	reply.GovernanceAllocation = (*hexutil.Big)(fees.GovernanceAllocation)
+	reply.OrionFee = (*hexutil.Big)(fees.OrionFee)
+	reply.OrionNodeCount = json.Uint64(snapshot.NodeCount)
+	reply.OrionListHash = snapshot.ListHash
+	return nil
+}
+
+// FeeHistoryArgs are the arguments to FeeHistory
+type FeeHistoryArgs struct {
+	BlockCount json.Uint64 `json:"blockCount"`
+	// NewestBlock is the number or tag of the newest block in the range.
+	// Defaults to "latest".
+	NewestBlock       string    `json:"newestBlock"`
+	RewardPercentiles []float64 `json:"rewardPercentiles"`
+}
+
+// FeeHistory returns the fee market history of the requested blocks, including
+// the distribution of the base fee of each block
+func (service *DioneAPI) FeeHistory(_ *http.Request, args *FeeHistoryArgs, reply *FeeHistoryResult) error {
+	log.Info("DELTA: FeeHistory called", "blockCount", args.BlockCount, "newestBlock", args.NewestBlock)
+
+	newestBlock := rpc.LatestBlockNumber
+	if args.NewestBlock != "" {
+		if err := newestBlock.UnmarshalJSON([]byte(args.NewestBlock)); err != nil {
+			return fmt.Errorf("problem parsing newestBlock %q: %w", args.NewestBlock, err)
+		}
+	}
+
+	result, err := service.vm.FeeHistory(context.Background(), uint64(args.BlockCount), newestBlock, args.RewardPercentiles)
+	if err != nil {
+		return err
+	}
+	*reply = *result
+	return nil
+}
+
+// GetPendingAtomicTxsArgs are the arguments to GetPendingAtomicTxs
+type GetPendingAtomicTxsArgs struct {
+	// Addresses restricts the reply to txs importing to or exporting from any
+	// of the addresses. Defaults to all txs.
+	Addresses []common.Address `json:"addresses"`
+	// Limit is the maximum number of txs returned. Defaults to all txs.
+	Limit json.Uint32 `json:"limit"`
+}
+
+// PendingAtomicTx describes an atomic tx waiting in the mempool
+type PendingAtomicTx struct {
+	TxID   ids.ID `json:"txID"`
+	TxType string `json:"txType"`
+	// IssueTime is the time the tx was added to the mempool.
+	IssueTime time.Time   `json:"issueTime"`
+	GasUsed   json.Uint64 `json:"gasUsed"`
+	// Burned is the DIONE burned by the tx, in nDIONE.
+	Burned     json.Uint64 `json:"burned"`
+	InputUTXOs []ids.ID    `json:"inputUTXOs"`
+	// Conflicting is true if another tx in the mempool spends an input UTXO
+	// of the tx.
+	Conflicting bool `json:"conflicting"`
+}
+
+// GetPendingAtomicTxsReply defines the txs returned from GetPendingAtomicTxs
+type GetPendingAtomicTxsReply struct {
+	Txs []PendingAtomicTx `json:"txs"`
+}
+
+// GetPendingAtomicTxs returns the atomic txs waiting in the mempool to be
+// issued into a block, ordered by descending gas price
+func (service *DioneAPI) GetPendingAtomicTxs(_ *http.Request, args *GetPendingAtomicTxsArgs, reply *GetPendingAtomicTxsReply) error {
+	log.Info("DELTA: GetPendingAtomicTxs called", "addresses", len(args.Addresses), "limit", args.Limit)
+
+	addrs := set.Of(args.Addresses...)
+	reply.Txs = []PendingAtomicTx{}
+	for _, pending := range service.vm.mempool.PendingTxs() {
+		if args.Limit != 0 && len(reply.Txs) == int(args.Limit) {
+			break
+		}
+
+		var (
+			txType     string
+			matches    = addrs.Len() == 0
+			unsignedTx = pending.Tx.UnsignedAtomicTx
+		)
+		switch utx := unsignedTx.(type) {
+		case *UnsignedImportTx:
+			txType = "import"
+			for _, out := range utx.Outs {
+				matches = matches || addrs.Contains(out.Address)
+			}
+		case *UnsignedExportTx:
+			txType = "export"
+			for _, in := range utx.Ins {
+				matches = matches || addrs.Contains(in.Address)
+			}
+		default:
+			return fmt.Errorf("unexpected atomic tx type %T", utx)
+		}
+		if !matches {
+			continue
+		}
+
+		gasUsed, err := unsignedTx.GasUsed(true)
+		if err != nil {
+			return err
+		}
+		burned, err := unsignedTx.Burned(service.vm.ctx.DIONEAssetID)
+		if err != nil {
+			return err
+		}
+		inputUTXOs := unsignedTx.InputUTXOs().List()
+		utils.Sort(inputUTXOs)
+		reply.Txs = append(reply.Txs, PendingAtomicTx{
+			TxID:        pending.Tx.ID(),
+			TxType:      txType,
+			IssueTime:   pending.Added,
+			GasUsed:     json.Uint64(gasUsed),
+			Burned:      json.Uint64(burned),
+			InputUTXOs:  inputUTXOs,
+			Conflicting: pending.Conflicting,
+		})
+	}
+	return nil
+}
+
 type FormattedTx struct {
-	api.FormattedTx
+	api.GetTxReply
 	BlockHeight *json.Uint64 `json:"blockHeight,omitempty"`
 }
 
+// JSONAtomicTx is the JSON view of an atomic tx returned by GetAtomicTx
+type JSONAtomicTx struct {
+	*Tx
+	TxType string `json:"txType"`
+}
+
 // GetAtomicTx returns the specified transaction
 func (service *DioneAPI) GetAtomicTx(r *http.Request, args *api.GetTxArgs, reply *FormattedTx) error {
 	log.Info("DELTA: GetAtomicTx called", "txID", args.TxID)
@@ -480,15 +1230,45 @@ func (service *DioneAPI) GetAtomicTx(r *http.Request, args *api.GetTxArgs, reply
 		return fmt.Errorf("could not find tx %s", args.TxID)
 	}
 
-	txBytes, err := formatting.Encode(args.Encoding, tx.SignedBytes())
-	if err != nil {
-		return err
-	}
-	reply.Tx = txBytes
 	reply.Encoding = args.Encoding
+	if args.Encoding == formatting.JSON {
+		reply.Tx, err = service.vm.atomicTxJSON(tx)
+		if err != nil {
+			return fmt.Errorf("couldn't format tx %s as json: %w", args.TxID, err)
+		}
+	} else {
+		reply.Tx, err = formatting.Encode(args.Encoding, tx.SignedBytes())
+		if err != nil {
+			return err
+		}
+	}
 	if status == Accepted {
 		jsonHeight := json.Uint64(height)
 		reply.BlockHeight = &jsonHeight
 	}
 	return nil
 }
+
+// atomicTxJSON returns the JSON view of [tx]. The tx is parsed again from its
+// signed bytes so that initializing the context of its outputs does not modify
+// a tx shared with the mempool.
+func (vm *VM) atomicTxJSON(tx *Tx) (*JSONAtomicTx, error) {
+	parsed, err := ExtractAtomicTx(tx.SignedBytes(), vm.codec)
+	if err != nil {
+		return nil, err
+	}
+
+	var txType string
+	switch utx := parsed.UnsignedAtomicTx.(type) {
+	case *UnsignedImportTx:
+		txType = "import"
+	case *UnsignedExportTx:
+		txType = "export"
+		for _, out := range utx.ExportedOutputs {
+			out.InitCtx(vm.ctx)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected atomic tx type %T", utx)
+	}
+	return &JSONAtomicTx{Tx: parsed, TxType: txType}, nil
+}
@@ -0,0 +1,74 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/DioneProtocol/odysseygo/utils/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/params"
+)
+
+func TestGetAtomicTxStatuses(t *testing.T) {
+	require := require.New(t)
+
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	handlers, err := vm.CreateHandlers(context.Background())
+	require.NoError(err)
+	server := httptest.NewServer(handlers[dioneEndpoint].Handler)
+	defer server.Close()
+	c := &client{requester: rpc.NewEndpointRequester(server.URL)}
+
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+	<-issuer
+
+	unknownTxID := ids.GenerateTestID()
+	statuses, err := c.GetAtomicTxStatuses(context.Background(), []ids.ID{importTx.ID(), unknownTxID})
+	require.NoError(err)
+	require.Equal(map[ids.ID]Status{
+		importTx.ID(): Processing,
+		unknownTxID:   Unknown,
+	}, statuses)
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	// The statuses are returned in order, with the height of accepted txs.
+	service := &DioneAPI{vm: vm}
+	reply := &GetAtomicTxStatusesReply{}
+	require.NoError(service.GetAtomicTxStatuses(nil, &GetAtomicTxStatusesArgs{
+		TxIDs: []ids.ID{unknownTxID, importTx.ID()},
+	}, reply))
+	require.Len(reply.Statuses, 2)
+	require.Equal(AtomicTxStatus{TxID: unknownTxID, Status: Unknown}, reply.Statuses[0])
+	require.Equal(importTx.ID(), reply.Statuses[1].TxID)
+	require.Equal(Accepted, reply.Statuses[1].Status)
+	require.NotNil(reply.Statuses[1].BlockHeight)
+	require.Equal(blk.Height(), uint64(*reply.Statuses[1].BlockHeight))
+
+	err = service.GetAtomicTxStatuses(nil, &GetAtomicTxStatusesArgs{
+		TxIDs: []ids.ID{importTx.ID(), ids.Empty},
+	}, &GetAtomicTxStatusesReply{})
+	require.ErrorIs(err, errNilTxID)
+	err = service.GetAtomicTxStatuses(nil, &GetAtomicTxStatusesArgs{
+		TxIDs: make([]ids.ID, maxGetAtomicTxStatusesTxIDs+1),
+	}, &GetAtomicTxStatusesReply{})
+	require.ErrorContains(err, "exceeds maximum")
+}
@@ -0,0 +1,61 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/DioneProtocol/coreth/core/state"
+)
+
+var errStatePruned = errors.New("state has been pruned")
+
+// StateUnavailableError is returned by GetStateAtBlock if the state of a known
+// block is not available, such as when it has been pruned.
+type StateUnavailableError struct {
+	BlockID ids.ID
+	Height  uint64
+	Root    common.Hash
+	Err     error
+}
+
+func (e *StateUnavailableError) Error() string {
+	return fmt.Sprintf("state of block %s at height %d with root %s is unavailable: %s", e.BlockID, e.Height, e.Root, e.Err)
+}
+
+func (e *StateUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// GetStateAtBlock returns the state after processing block [blockID], which
+// may be any block known to the VM, including blocks that are processing. If
+// the block is unknown, the returned error wraps [database.ErrNotFound]. If its
+// state is not available, the returned error is a *StateUnavailableError.
+func (vm *VM) GetStateAtBlock(_ context.Context, blockID ids.ID) (*state.StateDB, error) {
+	ethBlock := vm.blockChain.GetBlockByHash(common.Hash(blockID))
+	if ethBlock == nil {
+		return nil, fmt.Errorf("block %s: %w", blockID, database.ErrNotFound)
+	}
+	unavailable := &StateUnavailableError{
+		BlockID: blockID,
+		Height:  ethBlock.NumberU64(),
+		Root:    ethBlock.Root(),
+		Err:     errStatePruned,
+	}
+	if !vm.blockChain.HasState(ethBlock.Root()) {
+		return nil, unavailable
+	}
+	statedb, err := vm.blockChain.StateAt(ethBlock.Root())
+	if err != nil {
+		unavailable.Err = err
+		return nil, unavailable
+	}
+	return statedb, nil
+}
@@ -0,0 +1,83 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package delta
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DioneProtocol/coreth/params"
+)
+
+func TestGetStateAtBlock(t *testing.T) {
+	require := require.New(t)
+
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	admin := NewAdminService(vm, "")
+
+	_, err := vm.GetStateAtBlock(context.Background(), ids.GenerateTestID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	genesisID, err := vm.LastAccepted(context.Background())
+	require.NoError(err)
+	genesisState, err := vm.GetStateAtBlock(context.Background(), genesisID)
+	require.NoError(err)
+	require.Zero(genesisState.GetBalance(testEthAddrs[0]).Sign())
+
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+
+	// The state of a processing block is available.
+	statedb, err := vm.GetStateAtBlock(context.Background(), blk.ID())
+	require.NoError(err)
+	balance := statedb.GetBalance(testEthAddrs[0])
+	require.Positive(balance.Sign())
+
+	reply := &GetStateAtBlockReply{}
+	require.NoError(admin.GetStateAtBlock(nil, &GetStateAtBlockArgs{
+		BlockID:   blk.ID(),
+		Addresses: []common.Address{testEthAddrs[0], testEthAddrs[1]},
+	}, reply))
+	ethBlock := vm.blockChain.GetBlockByHash(common.Hash(blk.ID()))
+	require.Equal(blk.ID(), reply.BlockID)
+	require.Equal(uint64(1), uint64(reply.Height))
+	require.Equal(ethBlock.Root(), reply.Root)
+	require.Len(reply.Accounts, 2)
+	require.Equal(testEthAddrs[0], reply.Accounts[0].Address)
+	require.Equal(balance, (*big.Int)(reply.Accounts[0].Balance))
+	require.Zero(uint64(reply.Accounts[0].Nonce))
+	require.Zero(reply.Accounts[1].Balance.ToInt().Sign())
+
+	// The state of a rejected block is discarded.
+	require.NoError(blk.Reject(context.Background()))
+	_, err = vm.GetStateAtBlock(context.Background(), blk.ID())
+	var unavailable *StateUnavailableError
+	require.True(errors.As(err, &unavailable))
+	require.ErrorIs(err, errStatePruned)
+	require.Equal(blk.ID(), unavailable.BlockID)
+	require.Equal(uint64(1), unavailable.Height)
+	require.Equal(ethBlock.Root(), unavailable.Root)
+
+	err = admin.GetStateAtBlock(nil, &GetStateAtBlockArgs{BlockID: blk.ID()}, &GetStateAtBlockReply{})
+	require.True(errors.As(err, &unavailable))
+}
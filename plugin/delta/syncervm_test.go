@@ -285,7 +285,7 @@ func createSyncServerAndClientVMs(t *testing.T, test syncTest) *syncVMSetup {
 		switch i {
 		case 0:
 			// spend the UTXOs from shared memory
-			importTx, err = serverVM.newImportTx(serverVM.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+			importTx, err = serverVM.newImportTx(serverVM.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 			require.NoError(err)
 			require.NoError(serverVM.issueTx(importTx, true /*=local*/))
 		case 1:
@@ -297,6 +297,7 @@ func createSyncServerAndClientVMs(t *testing.T, test syncTest) *syncVMSetup {
 				testShortIDAddrs[0],
 				initialBaseFee,
 				[]*secp256k1.PrivateKey{testKeys[0]},
+				false,
 			)
 			require.NoError(err)
 			require.NoError(serverVM.issueTx(exportTx, true /*=local*/))
@@ -71,7 +71,7 @@ func (t *TestUnsignedTx) SemanticVerify(vm *VM, stx *Tx, parent *Block, baseFee
 }
 
 // DELTAStateTransfer implements the UnsignedAtomicTx interface
-func (t *TestUnsignedTx) DELTAStateTransfer(ctx *snow.Context, state *state.StateDB) error {
+func (t *TestUnsignedTx) DELTAStateTransfer(ctx *snow.Context, state *state.StateDB, rules params.Rules) error {
 	return t.DELTAStateTransferV
 }
 
@@ -21,6 +21,7 @@ import (
 	"github.com/DioneProtocol/odysseygo/codec"
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/snow"
+	"github.com/DioneProtocol/odysseygo/utils"
 	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
 	"github.com/DioneProtocol/odysseygo/utils/hashing"
 	"github.com/DioneProtocol/odysseygo/utils/set"
@@ -30,16 +31,19 @@ import (
 )
 
 var (
-	errWrongBlockchainID = errors.New("wrong blockchain ID provided")
-	errWrongNetworkID    = errors.New("tx was issued with a different network ID")
-	errNilTx             = errors.New("tx is nil")
-	errNoValueOutput     = errors.New("output has no value")
-	errNoValueInput      = errors.New("input has no value")
-	errNilOutput         = errors.New("nil output")
-	errNilInput          = errors.New("nil input")
-	errEmptyAssetID      = errors.New("empty asset ID is not valid")
-	errNilBaseFee        = errors.New("cannot calculate dynamic fee with nil baseFee")
-	errFeeOverflow       = errors.New("overflow occurred while calculating the fee")
+	errWrongBlockchainID  = errors.New("wrong blockchain ID provided")
+	errWrongNetworkID     = errors.New("tx was issued with a different network ID")
+	errNilTx              = errors.New("tx is nil")
+	errNoValueOutput      = errors.New("output has no value")
+	errNoValueInput       = errors.New("input has no value")
+	errNilOutput          = errors.New("nil output")
+	errNilInput           = errors.New("nil input")
+	errEmptyAssetID       = errors.New("empty asset ID is not valid")
+	errNilBaseFee         = errors.New("cannot calculate dynamic fee with nil baseFee")
+	errFeeOverflow        = errors.New("overflow occurred while calculating the fee")
+	errGasOverflow        = fmt.Errorf("%w: gas used is too large to pay for at the base fee", errFeeOverflow)
+	errBaseFeeOverflow    = fmt.Errorf("%w: base fee is too large to pay for any gas", errFeeOverflow)
+	errMaxBaseFeeNegative = errors.New("max base fee must not be negative")
 )
 
 // Constants for calculating the gas consumed by atomic transactions
@@ -131,7 +135,20 @@ type UnsignedAtomicTx interface {
 	// The set of atomic requests must be returned in a consistent order.
 	AtomicOps() (ids.ID, *atomic.Requests, error)
 
-	DELTAStateTransfer(ctx *snow.Context, state *state.StateDB) error
+	// DELTAStateTransfer applies the effects of this transaction to [state].
+	// If it returns an error, [state] must be left unmodified.
+	DELTAStateTransfer(ctx *snow.Context, state *state.StateDB, rules params.Rules) error
+}
+
+// applyStateTransfer runs [transfer] against [state], reverting every change it
+// made if it returns an error, so that a state transfer is all-or-nothing.
+func applyStateTransfer(state *state.StateDB, transfer func() error) error {
+	snapshot := state.Snapshot()
+	if err := transfer(); err != nil {
+		state.RevertToSnapshot(snapshot)
+		return err
+	}
+	return nil
 }
 
 // Tx is a signed transaction
@@ -238,6 +255,14 @@ func SortDELTAInputsAndSigners(inputs []DELTAInput, signers [][]*secp256k1.Priva
 	sort.Sort(&innerSortInputsAndSigners{inputs: inputs, signers: signers})
 }
 
+// SortDELTAOutputs sorts [outs] into the canonical order used by import
+// transactions: by address, then by assetID. As of ApricotPhase2, import
+// transactions also require their outputs to be unique by (address, assetID),
+// so outputs for the same address and asset must be merged before sorting.
+func SortDELTAOutputs(outs []DELTAOutput) {
+	utils.Sort(outs)
+}
+
 // calculates the amount of DIONE that must be burned by an atomic transaction
 // that consumes [cost] at [baseFee].
 func CalculateDynamicFee(cost uint64, baseFee *big.Int) (uint64, error) {
@@ -250,11 +275,26 @@ func CalculateDynamicFee(cost uint64, baseFee *big.Int) (uint64, error) {
 	feeInNDIONE := new(big.Int).Div(feeToRoundUp, x2cRate)
 	if !feeInNDIONE.IsUint64() {
 		// the fee is more than can fit in a uint64
-		return 0, errFeeOverflow
+		return 0, classifyFeeOverflow(baseFee)
 	}
 	return feeInNDIONE.Uint64(), nil
 }
 
+// classifyFeeOverflow returns the error reported by CalculateDynamicFee when
+// the fee at [baseFee] does not fit into a uint64. If even a single unit of gas
+// costs more than that, no transaction can be paid for until the base fee
+// drops, and errBaseFeeOverflow is returned. Otherwise, a transaction using
+// less gas could be paid for, and errGasOverflow is returned. Both wrap
+// errFeeOverflow.
+func classifyFeeOverflow(baseFee *big.Int) error {
+	feeToRoundUp := new(big.Int).Add(baseFee, x2cRateMinus1)
+	feePerGas := new(big.Int).Div(feeToRoundUp, x2cRate)
+	if !feePerGas.IsUint64() {
+		return errBaseFeeOverflow
+	}
+	return errGasOverflow
+}
+
 func calcBytesCost(len int) uint64 {
 	return uint64(len) * TxBytesGas
 }
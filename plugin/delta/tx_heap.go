@@ -5,6 +5,7 @@ package delta
 
 import (
 	"container/heap"
+	"sort"
 
 	"github.com/DioneProtocol/odysseygo/ids"
 )
@@ -35,11 +36,21 @@ func newInternalTxHeap(items int, isMinHeap bool) *internalTxHeap {
 
 func (th internalTxHeap) Len() int { return len(th.items) }
 
+// higherPriority returns true if [e] is issued before [other]: if it pays a
+// higher [gasPrice] or, to order equally priced transactions the same way
+// regardless of the order they were added in, if it has a smaller [id].
+func (e *txEntry) higherPriority(other *txEntry) bool {
+	if e.gasPrice != other.gasPrice {
+		return e.gasPrice > other.gasPrice
+	}
+	return e.id.Less(other.id)
+}
+
 func (th internalTxHeap) Less(i, j int) bool {
 	if th.isMinHeap {
-		return th.items[i].gasPrice < th.items[j].gasPrice
+		return th.items[j].higherPriority(th.items[i])
 	}
-	return th.items[i].gasPrice > th.items[j].gasPrice
+	return th.items[i].higherPriority(th.items[j])
 }
 
 func (th internalTxHeap) Swap(i, j int) {
@@ -146,6 +157,17 @@ func (th *txHeap) Remove(id ids.ID) *Tx {
 	return heap.Remove(th.minHeap, minEntry.index).(*txEntry).tx
 }
 
+// sortedEntries returns the entries of [th] in the order PopMax would remove
+// them, by descending gas price and then by ascending ID.
+func (th *txHeap) sortedEntries() []*txEntry {
+	entries := make([]*txEntry, len(th.maxHeap.items))
+	copy(entries, th.maxHeap.items)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].higherPriority(entries[j])
+	})
+	return entries
+}
+
 func (th *txHeap) Len() int {
 	return th.maxHeap.Len()
 }
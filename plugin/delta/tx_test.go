@@ -5,6 +5,8 @@ package delta
 
 import (
 	"context"
+	"errors"
+	"math"
 	"math/big"
 	"strings"
 	"testing"
@@ -13,11 +15,15 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/DioneProtocol/coreth/core/rawdb"
+	"github.com/DioneProtocol/coreth/core/state"
+	"github.com/DioneProtocol/coreth/core/types"
 	"github.com/DioneProtocol/coreth/params"
 
 	"github.com/DioneProtocol/odysseygo/chains/atomic"
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/DioneProtocol/odysseygo/snow"
+	"github.com/DioneProtocol/odysseygo/utils"
 )
 
 func TestCalculateDynamicFee(t *testing.T) {
@@ -38,6 +44,35 @@ func TestCalculateDynamicFee(t *testing.T) {
 			baseFee:       big.NewInt(25 * params.GWei),
 			expectedValue: 525000,
 		},
+		{
+			gas:           math.MaxUint64,
+			baseFee:       new(big.Int).Set(x2cRate),
+			expectedValue: math.MaxUint64,
+		},
+		{
+			gas:         math.MaxUint64,
+			baseFee:     new(big.Int).Add(x2cRate, common.Big1),
+			expectedErr: errGasOverflow,
+		},
+		{
+			gas:         2,
+			baseFee:     new(big.Int).Mul(new(big.Int).SetUint64(math.MaxUint64), x2cRate),
+			expectedErr: errGasOverflow,
+		},
+		{
+			gas:         1,
+			baseFee:     new(big.Int).Add(new(big.Int).Mul(new(big.Int).SetUint64(math.MaxUint64), x2cRate), common.Big1),
+			expectedErr: errBaseFeeOverflow,
+		},
+		{
+			gas:         21000,
+			baseFee:     new(big.Int).Lsh(common.Big1, 128),
+			expectedErr: errBaseFeeOverflow,
+		},
+		{
+			gas:         1,
+			expectedErr: errNilBaseFee,
+		},
 	}
 
 	for _, test := range tests {
@@ -53,6 +88,9 @@ func TestCalculateDynamicFee(t *testing.T) {
 			if err != test.expectedErr {
 				t.Fatalf("Expected error: %s, found error: %s", test.expectedErr, err)
 			}
+			if test.expectedErr != errNilBaseFee && !errors.Is(err, errFeeOverflow) {
+				t.Fatalf("Expected error %s to wrap %s", err, errFeeOverflow)
+			}
 		}
 	}
 }
@@ -133,7 +171,7 @@ func executeTxTest(t *testing.T, test atomicTxTest) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err := tx.UnsignedAtomicTx.DELTAStateTransfer(vm.ctx, sdb); len(test.deltaStateTransferErr) == 0 && err != nil {
+	if err := tx.UnsignedAtomicTx.DELTAStateTransfer(vm.ctx, sdb, rules); len(test.deltaStateTransferErr) == 0 && err != nil {
 		t.Fatalf("DELTAStateTransfer failed unexpectedly due to: %s", err)
 	} else if len(test.deltaStateTransferErr) != 0 {
 		if err == nil {
@@ -259,6 +297,29 @@ func TestDELTAOutputLess(t *testing.T) {
 	}
 }
 
+func TestSortDELTAOutputs(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		addr0 = common.BytesToAddress([]byte{0x01})
+		addr1 = common.BytesToAddress([]byte{0x02})
+	)
+	outs := []DELTAOutput{
+		{Address: addr1, AssetID: ids.ID{0}},
+		{Address: addr0, AssetID: ids.ID{1}},
+		{Address: addr1, AssetID: ids.ID{1}},
+		{Address: addr0, AssetID: ids.ID{0}},
+	}
+	SortDELTAOutputs(outs)
+	require.Equal([]DELTAOutput{
+		{Address: addr0, AssetID: ids.ID{0}},
+		{Address: addr0, AssetID: ids.ID{1}},
+		{Address: addr1, AssetID: ids.ID{0}},
+		{Address: addr1, AssetID: ids.ID{1}},
+	}, outs)
+	require.True(utils.IsSortedAndUnique(outs))
+}
+
 func TestDELTAInputLess(t *testing.T) {
 	type test struct {
 		name     string
@@ -341,3 +402,33 @@ func TestDELTAInputLess(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyStateTransfer(t *testing.T) {
+	require := require.New(t)
+
+	sdb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	assetID := common.Hash{1}
+	sdb.AddBalance(testEthAddrs[0], big.NewInt(10))
+
+	// A failing transfer leaves no trace of the changes it made before failing.
+	errTransfer := errors.New("transfer failed")
+	err = applyStateTransfer(sdb, func() error {
+		sdb.AddBalance(testEthAddrs[0], big.NewInt(5))
+		sdb.AddBalanceMultiCoin(testEthAddrs[1], assetID, big.NewInt(5))
+		sdb.SetNonce(testEthAddrs[0], 1)
+		return errTransfer
+	})
+	require.ErrorIs(err, errTransfer)
+	require.Equal(big.NewInt(10), sdb.GetBalance(testEthAddrs[0]))
+	require.Zero(sdb.GetBalanceMultiCoin(testEthAddrs[1], assetID).Sign())
+	require.Zero(sdb.GetNonce(testEthAddrs[0]))
+
+	require.NoError(applyStateTransfer(sdb, func() error {
+		sdb.AddBalance(testEthAddrs[0], big.NewInt(5))
+		sdb.AddBalanceMultiCoin(testEthAddrs[1], assetID, big.NewInt(5))
+		return nil
+	}))
+	require.Equal(big.NewInt(15), sdb.GetBalance(testEthAddrs[0]))
+	require.Equal(big.NewInt(5), sdb.GetBalanceMultiCoin(testEthAddrs[1], assetID))
+}
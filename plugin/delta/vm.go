@@ -12,10 +12,13 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	odysseygoMetrics "github.com/DioneProtocol/odysseygo/api/metrics"
 	"github.com/DioneProtocol/odysseygo/network/p2p"
 	"github.com/DioneProtocol/odysseygo/network/p2p/gossip"
@@ -85,6 +88,7 @@ import (
 	"github.com/DioneProtocol/odysseygo/utils/units"
 	"github.com/DioneProtocol/odysseygo/vms/components/chain"
 	"github.com/DioneProtocol/odysseygo/vms/components/dione"
+	"github.com/DioneProtocol/odysseygo/vms/components/verify"
 	"github.com/DioneProtocol/odysseygo/vms/secp256k1fx"
 
 	commonEng "github.com/DioneProtocol/odysseygo/snow/engine/common"
@@ -93,7 +97,7 @@ import (
 )
 
 const (
-	x2cRateInt64       int64 = 1_000_000_000
+	x2cRateInt64       int64 = params.X2CRate
 	x2cRateMinus1Int64 int64 = x2cRateInt64 - 1
 
 	// Prefixes for metrics gatherers
@@ -109,6 +113,10 @@ var (
 	x2cRate       = big.NewInt(x2cRateInt64)
 	x2cRateMinus1 = big.NewInt(x2cRateMinus1Int64)
 
+	// buildBlockDeadlineTruncated counts the blocks built with txs left out
+	// because the block building deadline passed.
+	buildBlockDeadlineTruncated = metrics.NewRegisteredCounter("build_block_deadline_truncated", nil)
+
 	_ block.ChainVM                  = &VM{}
 	_ block.StateSyncableVM          = &VM{}
 	_ statesyncclient.EthBlockParser = &VM{}
@@ -130,6 +138,10 @@ const (
 
 	targetAtomicTxsSize = 40 * units.KiB
 
+	// atomicMempoolTrimFrequency is the maximum interval at which stale atomic
+	// txs are evicted from the mempool.
+	atomicMempoolTrimFrequency = time.Minute
+
 	// p2p app protocols
 	ethTxGossipProtocol    = 0x0
 	atomicTxGossipProtocol = 0x1
@@ -179,6 +191,13 @@ var (
 	acceptedPrefix  = []byte("snowman_accepted")
 	metadataPrefix  = []byte("metadata")
 	ethDBPrefix     = []byte("ethdb")
+	// burnedFeesPrefix is kept outside of the versioned database so that the
+	// burned fees index can be backfilled without committing other changes.
+	burnedFeesPrefix = []byte("burnedFees")
+	// atomicMempoolPrefix stores the atomic mempool txs persisted on shutdown
+	// in a database of its own, so that persisting them does not commit other
+	// changes of the versioned database.
+	atomicMempoolPrefix = []byte("atomicMempool")
 
 	// Prefixes for atomic trie
 	atomicTrieDBPrefix     = []byte("atomicTrieDB")
@@ -189,8 +208,16 @@ var (
 	errEmptyBlock                     = errors.New("empty block")
 	errUnsupportedFXs                 = errors.New("unsupported feature extensions")
 	errInvalidBlock                   = errors.New("invalid block")
+	errNoBlockBeforeTimestamp         = errors.New("no accepted block at or before timestamp")
+	errMissingAcceptedHeader          = errors.New("missing header of accepted block")
+	errInvalidBlockHeight             = errors.New("block height is not one above its parent")
+	errInvalidParentHash              = errors.New("block parent hash does not match its parent")
+	errBlockTimestampBeforeParent     = errors.New("block timestamp is before its parent")
+	errBlockTimestampTooFarInFuture   = errors.New("block timestamp is too far in the future")
 	errInvalidAddr                    = errors.New("invalid hex address")
 	errInsufficientAtomicTxFee        = errors.New("atomic tx fee too low for atomic mempool")
+	errAtomicAssetNotAllowed          = errors.New("asset is not in the atomic asset allowlist")
+	errAtomicTxFeeTooHigh             = errors.New("atomic tx fee exceeds the configured limit")
 	errAssetIDMismatch                = errors.New("asset IDs in the input don't match the utxo")
 	errNoImportInputs                 = errors.New("tx has no imported inputs")
 	errInputsNotSortedUnique          = errors.New("inputs not sorted and unique")
@@ -202,17 +229,21 @@ var (
 	errOutputsNotSortedUnique         = errors.New("outputs not sorted and unique")
 	errOverflowExport                 = errors.New("overflow when computing export amount + txFee")
 	errInvalidNonce                   = errors.New("invalid nonce")
+	errNonceTooHigh                   = errors.New("nonce too high")
 	errConflictingAtomicInputs        = errors.New("invalid block due to conflicting atomic inputs")
 	errUnclesUnsupported              = errors.New("uncles unsupported")
 	errRejectedParent                 = errors.New("rejected parent")
 	errInsufficientFundsForFee        = errors.New("insufficient DIONE funds to pay transaction fee")
 	errNoDELTAOutputs                 = errors.New("tx has no DELTA outputs")
 	errNilBaseFeeApricotPhase3        = errors.New("nil base fee is invalid after apricotPhase3")
+	errBaseFeeOutOfBounds             = errors.New("base fee out of bounds")
 	errNilExtDataGasUsedApricotPhase4 = errors.New("nil extDataGasUsed is invalid after apricotPhase4")
 	errNilBlockGasCostApricotPhase4   = errors.New("nil blockGasCost is invalid after apricotPhase4")
 	errConflictingAtomicTx            = errors.New("conflicting atomic tx present")
 	errTooManyAtomicTx                = errors.New("too many atomic tx")
+	errTooManyAtomicTxsFromAddress    = errors.New("too many pending atomic txs from address")
 	errMissingAtomicTxs               = errors.New("cannot build a block with non-empty extra data and zero atomic transactions")
+	errAtomicTriePruningDisabled      = errors.New("cannot prune the atomic trie with pruning disabled")
 )
 
 var originalStderr *os.File
@@ -253,6 +284,9 @@ type VM struct {
 	*chain.State
 
 	config Config
+	// [initialConfig], if set by NewDeltaVMWithConfig, replaces the default
+	// config that the config bytes passed to Initialize are applied to.
+	initialConfig *Config
 
 	chainID     *big.Int
 	networkID   uint64
@@ -266,12 +300,29 @@ type VM struct {
 	blockChain *core.BlockChain
 	miner      *miner.Miner
 
+	// [gasPriceStatus] records the gas price and minimum fee last applied to [txPool]
+	gasPriceStatus *gasPriceStatus
+
 	// [db] is the VM's current database managed by ChainState
 	db *versiondb.Database
 
 	// metadataDB is used to store one off keys.
 	metadataDB database.Database
 
+	// burnedFeesDB indexes the fees burned by each accepted block by height.
+	burnedFeesDB database.Database
+
+	// atomicMempoolDB stores the atomic mempool txs persisted on shutdown.
+	atomicMempoolDB *versiondb.Database
+	// atomicMempoolRestored is set once the txs persisted by the last shutdown
+	// are restored, so that they are not overwritten by a VM that shuts down
+	// before reaching normal operation.
+	atomicMempoolRestored bool
+
+	// bonusBlocks are the blocks that are accepted without applying their
+	// atomic txs.
+	bonusBlocks *BonusBlockSet
+
 	// [chaindb] is the database supplied to the Ethereum backend
 	chaindb ethdb.Database
 
@@ -324,8 +375,16 @@ type VM struct {
 	bootstrapped bool
 	IsPlugin     bool
 
-	orionSyncTimestamp uint64
-	orionNodes         []ids.NodeID
+	// [verifiedOrionNodes] maps the hash of a block processed by the
+	// blockchain to the orion nodes list its fees were distributed to, until
+	// the list is handed to the block at the end of its verification.
+	verifiedOrionNodes *cache.LRU[common.Hash, *orionNodes]
+
+	// [buildDeadline] is the soft deadline of the block being built, after
+	// which no further atomic txs are packed. [buildTruncated] records
+	// whether any were left out because of it.
+	buildDeadline  time.Time
+	buildTruncated bool
 
 	logger CorethLogger
 	// State sync server and client
@@ -356,6 +415,20 @@ func (vm *VM) GetActivationTime() time.Time {
 	return utils.Uint64ToTime(vm.chainConfig.ApricotPhase4BlockTimestamp)
 }
 
+// NewDeltaVMWithConfig returns a VM to be embedded in the calling process,
+// rather than served as a plugin through rpcchainvm.Serve, that is initialized
+// with [cfg] instead of the default config. The VM must still be initialized
+// with Initialize, which applies any config bytes it is passed on top of [cfg].
+// A nil [cfg] is equivalent to the default config.
+func NewDeltaVMWithConfig(cfg *Config) *VM {
+	vm := &VM{IsPlugin: false}
+	if cfg != nil {
+		initialConfig := *cfg
+		vm.initialConfig = &initialConfig
+	}
+	return vm
+}
+
 // Initialize implements the snowman.ChainVM interface
 func (vm *VM) Initialize(
 	_ context.Context,
@@ -368,7 +441,11 @@ func (vm *VM) Initialize(
 	fxs []*commonEng.Fx,
 	appSender commonEng.AppSender,
 ) error {
-	vm.config.SetDefaults()
+	if vm.initialConfig != nil {
+		vm.config = *vm.initialConfig
+	} else {
+		vm.config.SetDefaults()
+	}
 	if len(configBytes) > 0 {
 		if err := json.Unmarshal(configBytes, &vm.config); err != nil {
 			return fmt.Errorf("failed to unmarshal config %s: %w", string(configBytes), err)
@@ -395,7 +472,11 @@ func (vm *VM) Initialize(
 		writer = originalStderr
 	}
 
-	corethLogger, err := InitLogger(alias, vm.config.LogLevel, vm.config.LogJSONFormat, writer)
+	corethLogger, err := InitLogger(alias, vm.config.LogLevel, vm.config.LogJSONFormat, writer, LogFileConfig{
+		Path:       vm.config.LogFile,
+		MaxSize:    vm.config.LogFileMaxSize,
+		MaxBackups: vm.config.LogFileMaxBackups,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger due to: %w ", err)
 	}
@@ -416,6 +497,7 @@ func (vm *VM) Initialize(
 
 	vm.toEngine = toEngine
 	vm.shutdownChan = make(chan struct{}, 1)
+	vm.verifiedOrionNodes = &cache.LRU[common.Hash, *orionNodes]{Size: verifiedOrionNodesCacheSize}
 	baseDB := dbManager.Current().Database
 	// Use NewNested rather than New so that the structure of the database
 	// remains the same regardless of the provided baseDB type.
@@ -423,6 +505,8 @@ func (vm *VM) Initialize(
 	vm.db = versiondb.New(baseDB)
 	vm.acceptedBlockDB = prefixdb.New(acceptedPrefix, vm.db)
 	vm.metadataDB = prefixdb.New(metadataPrefix, vm.db)
+	vm.burnedFeesDB = prefixdb.New(burnedFeesPrefix, baseDB)
+	vm.atomicMempoolDB = versiondb.New(prefixdb.New(atomicMempoolPrefix, baseDB))
 
 	if vm.config.InspectDatabase {
 		start := time.Now()
@@ -488,6 +572,7 @@ func (vm *VM) Initialize(
 	vm.ethConfig.RPCGasCap = vm.config.RPCGasCap
 	vm.ethConfig.RPCDELTATimeout = vm.config.APIMaxDuration.Duration
 	vm.ethConfig.RPCTxFeeCap = vm.config.RPCTxFeeCap
+	vm.ethConfig.RPCReturnDataLimit = vm.config.RPCReturnDataLimit
 
 	vm.ethConfig.TxPool.NoLocals = !vm.config.LocalTxsEnabled
 	vm.ethConfig.TxPool.Journal = vm.config.TxPoolJournal
@@ -522,8 +607,15 @@ func (vm *VM) Initialize(
 	vm.ethConfig.OfflinePruningDataDirectory = vm.config.OfflinePruningDataDirectory
 	vm.ethConfig.CommitInterval = vm.config.CommitInterval
 	vm.ethConfig.SkipUpgradeCheck = vm.config.SkipUpgradeCheck
+	if vm.config.SkipUpgradeCheckHeight != nil || vm.config.SkipUpgradeCheckTimestamp != nil {
+		vm.ethConfig.UpgradeCheckOverride = &params.UpgradeCheckOverride{
+			Height:    vm.config.SkipUpgradeCheckHeight,
+			Timestamp: vm.config.SkipUpgradeCheckTimestamp,
+		}
+	}
 	vm.ethConfig.AcceptedCacheSize = vm.config.AcceptedCacheSize
 	vm.ethConfig.TxLookupLimit = vm.config.TxLookupLimit
+	vm.ethConfig.OpcodeStatsBlocks = vm.config.OpcodeStatsBlocks
 
 	// Create directory for offline pruning
 	if len(vm.ethConfig.OfflinePruningDataDirectory) != 0 {
@@ -543,11 +635,11 @@ func (vm *VM) Initialize(
 
 	vm.codec = Codec
 
-	// TODO: read size from settings
-	vm.mempool, err = NewMempool(chainCtx.DIONEAssetID, defaultMempoolSize)
+	vm.mempool, err = NewMempool(chainCtx.DIONEAssetID, vm.config.AtomicMempoolMaxSize)
 	if err != nil {
 		return fmt.Errorf("failed to initialize mempool: %w", err)
 	}
+	vm.mempool.SetMaxTxsPerAddress(vm.config.AtomicMempoolMaxTxsPerAddress)
 
 	if err := vm.initializeMetrics(); err != nil {
 		return err
@@ -563,15 +655,12 @@ func (vm *VM) Initialize(
 	if err := vm.initializeChain(lastAcceptedHash); err != nil {
 		return err
 	}
-	// initialize bonus blocks on mainnet
+	// initialize bonus blocks
+	vm.bonusBlocks = vm.bonusBlockSet()
 	var (
-		bonusBlockHeights     map[uint64]ids.ID
-		canonicalBlockHeights []uint64
+		bonusBlockHeights     = vm.bonusBlocks.heights()
+		canonicalBlockHeights = vm.bonusBlocks.CanonicalBlocks
 	)
-	if vm.chainID.Cmp(params.OdysseyMainnetChainID) == 0 {
-		bonusBlockHeights = bonusBlockMainnetHeights
-		canonicalBlockHeights = canonicalBlockMainnetHeights
-	}
 
 	// initialize atomic repository
 	vm.atomicTxRepository, err = NewAtomicTxRepository(
@@ -647,11 +736,18 @@ func (vm *VM) initializeChain(lastAcceptedHash common.Hash) error {
 		&vm.clock,
 		vm.ctx.FeeCollector,
 	)
+	var compatErr *params.ConfigCompatError
+	if errors.As(err, &compatErr) {
+		err := newConfigCompatibilityError(compatErr)
+		log.Error("chain config is incompatible with the database", "err", err)
+		return err
+	}
 	if err != nil {
 		return err
 	}
 	vm.eth.SetEtherbase(corethConstants.BlackholeAddr)
 	vm.txPool = vm.eth.TxPool()
+	vm.mempool.SetNonceReserver(vm.txPool)
 	vm.blockChain = vm.eth.BlockChain()
 	vm.miner = vm.eth.Miner()
 
@@ -766,11 +862,32 @@ func (vm *VM) createConsensusCallbacks() *dummy.ConsensusCallbacks {
 
 func (vm *VM) preBatchOnFinalizeAndAssemble(header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts types.Receipts) ([]byte, *big.Int, *big.Int, error) {
 	rules := vm.chainConfig.OdysseyRules(header.Number, header.Time)
+	orions, err := vm.processOrionNodes(header, state, &rules)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if rules.IsOdyPhaseOrionSnapshot {
+		putOrionSnapshot(header, orions.snapshot)
+	}
 	totalBaseFee, totalPriorityFee := vm.calculateTxFees(header.BaseFee, txs, receipts, &rules)
-	vm.distributeFees(totalBaseFee, totalPriorityFee, state, &rules)
+	if _, _, _, err := vm.distributeFees(totalBaseFee, totalPriorityFee, orions.snapshot.NodeCount, state, &rules); err != nil {
+		return nil, nil, nil, err
+	}
 	vm.distributeUndistributedRewards(header.UndistributedReward, state, &rules)
 
+	// Exports waiting on pending EVM transactions are sent back to the mempool
+	// once the block has been assembled.
+	var postponedTxs []*Tx
+	defer func() {
+		for _, tx := range postponedTxs {
+			vm.mempool.CancelCurrentTx(tx.ID())
+		}
+	}()
+
 	for {
+		if vm.buildDeadlineExceeded(len(txs) > 0) {
+			break
+		}
 		tx, exists := vm.mempool.NextTx()
 		if !exists {
 			break
@@ -781,7 +898,12 @@ func (vm *VM) preBatchOnFinalizeAndAssemble(header *types.Header, state *state.S
 		// once.
 		snapshot := state.Snapshot()
 		rules := vm.chainConfig.OdysseyRules(header.Number, header.Time)
-		if err := vm.verifyTx(tx, header.ParentHash, header.BaseFee, state, rules); err != nil {
+		if err := vm.verifyTx(tx, header.ParentHash, header.BaseFee, state, rules); errors.Is(err, errNonceTooHigh) {
+			log.Debug("postponing tx with nonce ahead of state", "txID", tx.ID(), "err", err)
+			postponedTxs = append(postponedTxs, tx)
+			state.RevertToSnapshot(snapshot)
+			continue
+		} else if err != nil {
 			// Discard the transaction from the mempool on failed verification.
 			log.Debug("discarding tx from mempool on failed verification", "txID", tx.ID(), "err", err)
 			vm.mempool.DiscardCurrentTx(tx.ID())
@@ -824,13 +946,34 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 		batchGasUsed      *big.Int = new(big.Int).Set(common.Big0)
 		rules                      = vm.chainConfig.OdysseyRules(header.Number, header.Time)
 		size              int
+		postponedTxs      []*Tx
 	)
 
+	orions, err := vm.processOrionNodes(header, state, &rules)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if rules.IsOdyPhaseOrionSnapshot {
+		putOrionSnapshot(header, orions.snapshot)
+	}
 	totalBaseFee, totalPriorityFee := vm.calculateTxFees(header.BaseFee, txs, receipts, &rules)
-	vm.distributeFees(totalBaseFee, totalPriorityFee, state, &rules)
+	if _, _, _, err := vm.distributeFees(totalBaseFee, totalPriorityFee, orions.snapshot.NodeCount, state, &rules); err != nil {
+		return nil, nil, nil, err
+	}
 	vm.distributeUndistributedRewards(header.UndistributedReward, state, &rules)
 
+	// Exports waiting on pending EVM transactions are sent back to the mempool
+	// once the block has been assembled.
+	defer func() {
+		for _, tx := range postponedTxs {
+			vm.mempool.CancelCurrentTx(tx.ID())
+		}
+	}()
+
 	for {
+		if vm.buildDeadlineExceeded(len(txs) > 0 || len(batchAtomicTxs) > 0) {
+			break
+		}
 		tx, exists := vm.mempool.NextTx()
 		if !exists {
 			break
@@ -856,7 +999,7 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 			return nil, nil, nil, err
 		}
 		// ensure [gasUsed] + [batchGasUsed] doesnt exceed the [atomicGasLimit]
-		if totalGasUsed := new(big.Int).Add(batchGasUsed, txGasUsed); totalGasUsed.Cmp(params.AtomicGasLimit) > 0 {
+		if totalGasUsed := new(big.Int).Add(batchGasUsed, txGasUsed); totalGasUsed.Cmp(rules.AtomicGasLimit()) > 0 {
 			// Send [tx] back to the mempool's tx heap.
 			vm.mempool.CancelCurrentTx(tx.ID())
 			break
@@ -875,7 +1018,12 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 		}
 
 		snapshot := state.Snapshot()
-		if err := vm.verifyTx(tx, header.ParentHash, header.BaseFee, state, rules); err != nil {
+		if err := vm.verifyTx(tx, header.ParentHash, header.BaseFee, state, rules); errors.Is(err, errNonceTooHigh) {
+			log.Debug("postponing tx with nonce ahead of state", "txID", tx.ID(), "err", err)
+			postponedTxs = append(postponedTxs, tx)
+			state.RevertToSnapshot(snapshot)
+			continue
+		} else if err != nil {
 			// Discard the transaction from the mempool and reset the state to [snapshot]
 			// if it fails verification here.
 			// Note: prior to this point, we have not modified [state] so there is no need to
@@ -920,7 +1068,22 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 	return nil, nil, nil, nil
 }
 
+// buildDeadlineExceeded reports whether the soft deadline of the block being
+// built has passed, and records that the block was truncated if so. [packed]
+// indicates whether the block already contains a tx, since the deadline never
+// leaves the block empty.
+func (vm *VM) buildDeadlineExceeded(packed bool) bool {
+	if vm.buildDeadline.IsZero() || !packed || time.Now().Before(vm.buildDeadline) {
+		return false
+	}
+	vm.buildTruncated = true
+	return true
+}
+
 func (vm *VM) onFinalizeAndAssemble(header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts types.Receipts) ([]byte, *big.Int, *big.Int, error) {
+	if header.BaseFee != nil {
+		vm.mempool.DropFeeCapExceeded(header.BaseFee)
+	}
 	if !vm.chainConfig.IsApricotPhase5(header.Time) {
 		return vm.preBatchOnFinalizeAndAssemble(header, state, txs, receipts)
 	}
@@ -959,23 +1122,20 @@ func (vm *VM) distributeUndistributedRewards(rewards *big.Int, state *state.Stat
 	}
 }
 
-func (vm *VM) distributeFees(totalBaseFee *big.Int, totalPriorityFee *big.Int, state *state.StateDB, rules *params.Rules) (*big.Int, *big.Int, *big.Int) {
-	timestamp := rules.OrionNodes.GetLastUpdateTimestamp(state)
-	if vm.orionSyncTimestamp != timestamp {
-		nodes := rules.OrionNodes.GetNodesList(state)
-		vm.orionNodes = nodes
-		vm.orionSyncTimestamp = timestamp
+// distributeFees credits the LP and governance allocations of the fees of a
+// block with [orionNodesCount] orion nodes in the state of its parent.
+func (vm *VM) distributeFees(totalBaseFee *big.Int, totalPriorityFee *big.Int, orionNodesCount uint64, state *state.StateDB, rules *params.Rules) (*big.Int, *big.Int, *big.Int, error) {
+	fees, err := CalculateFees(totalBaseFee, totalPriorityFee, orionNodesCount, rules)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to calculate fees: %w", err)
 	}
 
-	orionNodesAmount := uint64(len(vm.orionNodes))
-	fees := CalculateFees(totalBaseFee, totalPriorityFee, orionNodesAmount, rules)
-
 	if state != nil {
 		state.AddBalance(rules.LpAddress, fees.LpAllocation)
 		state.AddBalance(rules.GovernanceAddress, fees.GovernanceAllocation)
 	}
 
-	return fees.BaseFee, fees.PriorityFee, fees.OrionFee
+	return fees.BaseFee, fees.PriorityFee, fees.OrionFee, nil
 }
 
 func (vm *VM) onExtraStateChange(block *types.Block, state *state.StateDB, receipts types.Receipts) (*big.Int, *big.Int, error) {
@@ -986,8 +1146,21 @@ func (vm *VM) onExtraStateChange(block *types.Block, state *state.StateDB, recei
 		rules                      = vm.chainConfig.OdysseyRules(header.Number, header.Time)
 	)
 
+	orions, err := vm.processOrionNodes(header, state, &rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rules.IsOdyPhaseOrionSnapshot {
+		if err := verifyOrionSnapshot(header, orions.snapshot); err != nil {
+			return nil, nil, err
+		}
+	}
+	vm.verifiedOrionNodes.Put(block.Hash(), orions)
 	totalBaseFee, totalPriorityFee := vm.calculateTxFees(block.BaseFee(), block.Transactions(), receipts, &rules)
-	totalBaseFee, totalPriorityFee, orionFee := vm.distributeFees(totalBaseFee, totalPriorityFee, state, &rules)
+	totalBaseFee, totalPriorityFee, orionFee, err := vm.distributeFees(totalBaseFee, totalPriorityFee, orions.snapshot.NodeCount, state, &rules)
+	if err != nil {
+		return nil, nil, err
+	}
 	vm.distributeUndistributedRewards(header.UndistributedReward, state, &rules)
 
 	block.SetTotalBaseFee(totalBaseFee)
@@ -1029,9 +1202,24 @@ func (vm *VM) onExtraStateChange(block *types.Block, state *state.StateDB, recei
 		}
 		totalBurned.Add(totalBurned, new(big.Int).SetUint64(burned))
 
-		if err := tx.UnsignedAtomicTx.DELTAStateTransfer(vm.ctx, state); err != nil {
+		var supplyCheck *atomicSupplyCheck
+		if vm.config.AtomicSupplyCheckEnabled {
+			supplyCheck, err = newAtomicSupplyCheck(tx, vm.ctx.DIONEAssetID, state)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := tx.UnsignedAtomicTx.DELTAStateTransfer(vm.ctx, state, rules); err != nil {
 			return nil, nil, err
 		}
+		if supplyCheck != nil {
+			if err := supplyCheck.Verify(state); err != nil {
+				log.Error("atomic supply check failed", "block", block.Hash(), "height", block.NumberU64(), "err", err)
+				if vm.config.AtomicSupplyCheckFatal {
+					return nil, nil, err
+				}
+			}
+		}
 		// If ApricotPhase4 is enabled, calculate the block fee contribution
 		if rules.IsApricotPhase4 {
 			contribution, gasUsed, err := tx.BlockFeeContribution(rules.IsApricotPhase5, vm.ctx.DIONEAssetID, block.BaseFee())
@@ -1047,8 +1235,8 @@ func (vm *VM) onExtraStateChange(block *types.Block, state *state.StateDB, recei
 		// atomic gas limit.
 		if rules.IsApricotPhase5 {
 			// Ensure that [tx] does not push [block] above the atomic gas limit.
-			if batchGasUsed.Cmp(params.AtomicGasLimit) == 1 {
-				return nil, nil, fmt.Errorf("atomic gas used (%d) by block (%s), exceeds atomic gas limit (%d)", batchGasUsed, block.Hash().Hex(), params.AtomicGasLimit)
+			if atomicGasLimit := rules.AtomicGasLimit(); batchGasUsed.Cmp(atomicGasLimit) == 1 {
+				return nil, nil, fmt.Errorf("atomic gas used (%d) by block (%s), exceeds atomic gas limit (%d)", batchGasUsed, block.Hash().Hex(), atomicGasLimit)
 			}
 		}
 	}
@@ -1075,7 +1263,15 @@ func (vm *VM) SetState(_ context.Context, state snow.State) error {
 			return fmt.Errorf("failed to initialize block building: %w", err)
 		}
 		vm.bootstrapped = true
-		return vm.fx.Bootstrapped()
+		if err := vm.fx.Bootstrapped(); err != nil {
+			return err
+		}
+		// The persisted atomic txs are verified against the bootstrapped state
+		// and gossiped once they are restored.
+		if err := vm.restoreAtomicMempool(); err != nil {
+			return fmt.Errorf("failed to restore atomic mempool: %w", err)
+		}
+		return nil
 	default:
 		return snow.ErrUnknownState
 	}
@@ -1091,6 +1287,7 @@ func (vm *VM) initBlockBuilding() error {
 	vm.gossiper = vm.createGossiper(gossipStats)
 	vm.builder = vm.NewBlockBuilder(vm.toEngine)
 	vm.builder.awaitSubmittedTxs()
+	vm.awaitAtomicMempoolTrim()
 	vm.Network.SetGossipHandler(NewGossipHandler(vm, gossipStats))
 
 	ethTxPool, err := NewGossipEthTxPool(vm.txPool)
@@ -1103,11 +1300,7 @@ func (vm *VM) initBlockBuilding() error {
 		vm.shutdownWg.Done()
 	}()
 
-	var (
-		ethTxGossipHandler    p2p.Handler
-		atomicTxGossipHandler p2p.Handler
-	)
-
+	var ethTxGossipHandler p2p.Handler
 	ethTxGossipHandler, err = gossip.NewHandler[*GossipEthTx](ethTxPool, ethTxGossipHandlerConfig, vm.sdkMetrics)
 	if err != nil {
 		return err
@@ -1124,28 +1317,7 @@ func (vm *VM) initBlockBuilding() error {
 		return err
 	}
 
-	atomicTxGossipHandler, err = gossip.NewHandler[*GossipAtomicTx](vm.mempool, atomicTxGossipHandlerConfig, vm.sdkMetrics)
-	if err != nil {
-		return err
-	}
-
-	atomicTxGossipHandler = &p2p.ValidatorHandler{
-		ValidatorSet: vm.validators,
-		Handler: &p2p.ThrottlerHandler{
-			Throttler: p2p.NewSlidingWindowThrottler(throttlingPeriod, throttlingLimit),
-			Handler:   atomicTxGossipHandler,
-		},
-	}
-
-	atomicTxGossipClient, err := vm.router.RegisterAppProtocol(atomicTxGossipProtocol, atomicTxGossipHandler, vm.validators)
-	if err != nil {
-		return err
-	}
-
-	var (
-		ethTxGossiper    gossip.Gossiper
-		atomicTxGossiper gossip.Gossiper
-	)
+	var ethTxGossiper gossip.Gossiper
 	ethTxGossiper, err = gossip.NewPullGossiper[GossipEthTx, *GossipEthTx](
 		ethTxGossipConfig,
 		vm.ctx.Log,
@@ -1168,6 +1340,32 @@ func (vm *VM) initBlockBuilding() error {
 		vm.shutdownWg.Done()
 	}()
 
+	// Atomic txs issued locally or gossiped to this node are still added to the
+	// mempool, but the mempool is neither served to nor pulled from peers.
+	if vm.config.DisableAtomicTxGossip {
+		return nil
+	}
+
+	var atomicTxGossipHandler p2p.Handler
+	atomicTxGossipHandler, err = gossip.NewHandler[*GossipAtomicTx](vm.mempool, atomicTxGossipHandlerConfig, vm.sdkMetrics)
+	if err != nil {
+		return err
+	}
+
+	atomicTxGossipHandler = &p2p.ValidatorHandler{
+		ValidatorSet: vm.validators,
+		Handler: &p2p.ThrottlerHandler{
+			Throttler: p2p.NewSlidingWindowThrottler(throttlingPeriod, throttlingLimit),
+			Handler:   atomicTxGossipHandler,
+		},
+	}
+
+	atomicTxGossipClient, err := vm.router.RegisterAppProtocol(atomicTxGossipProtocol, atomicTxGossipHandler, vm.validators)
+	if err != nil {
+		return err
+	}
+
+	var atomicTxGossiper gossip.Gossiper
 	atomicTxGossiper, err = gossip.NewPullGossiper[GossipAtomicTx, *GossipAtomicTx](
 		atomicTxGossipConfig,
 		vm.ctx.Log,
@@ -1223,11 +1421,31 @@ func (vm *VM) setCrossChainAppRequestHandler() {
 	vm.Network.SetCrossChainRequestHandler(crossChainRequestHandler)
 }
 
+// PruneAtomicTrie removes the atomic trie roots committed below [beforeHeight]
+// and the trie nodes only they reference. Historical roots below
+// [beforeHeight] are answered with ErrPruned afterwards, including across
+// restarts.
+// Assumes [vm.ctx.Lock] is held, as it is during block acceptance, since both
+// commit [vm.db].
+func (vm *VM) PruneAtomicTrie(beforeHeight uint64) error {
+	if !vm.config.Pruning {
+		return errAtomicTriePruningDisabled
+	}
+	if err := vm.atomicTrie.Prune(beforeHeight); err != nil {
+		vm.db.Abort()
+		return err
+	}
+	return vm.db.Commit()
+}
+
 // Shutdown implements the snowman.ChainVM interface
 func (vm *VM) Shutdown(context.Context) error {
 	if vm.ctx == nil {
 		return nil
 	}
+	if err := vm.persistAtomicMempool(); err != nil {
+		log.Error("failed to persist atomic mempool", "err", err)
+	}
 	if vm.cancel != nil {
 		vm.cancel()
 	}
@@ -1238,12 +1456,33 @@ func (vm *VM) Shutdown(context.Context) error {
 	close(vm.shutdownChan)
 	vm.eth.Stop()
 	vm.shutdownWg.Wait()
+	if err := vm.logger.Close(); err != nil {
+		log.Error("failed to close log file", "err", err)
+	}
 	return nil
 }
 
+// buildDeadlineFor returns the soft deadline for building a block with [ctx],
+// which is the earliest of the deadline of [ctx] and the configured
+// [BuildBlockDeadline]. Returns the zero time if neither is set.
+func (vm *VM) buildDeadlineFor(ctx context.Context) time.Time {
+	var deadline time.Time
+	if d := vm.config.BuildBlockDeadline.Duration; d > 0 {
+		deadline = time.Now().Add(d)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	return deadline
+}
+
 // buildBlock builds a block to be wrapped by ChainState
-func (vm *VM) buildBlock(_ context.Context) (snowman.Block, error) {
-	block, err := vm.miner.GenerateBlock()
+func (vm *VM) buildBlock(ctx context.Context) (snowman.Block, error) {
+	vm.buildDeadline = vm.buildDeadlineFor(ctx)
+	vm.buildTruncated = false
+	block, truncated, err := vm.miner.GenerateBlockWithDeadline(vm.buildDeadline)
+	truncated = truncated || vm.buildTruncated
+	vm.buildDeadline, vm.buildTruncated = time.Time{}, false
 	vm.builder.handleGenerateBlock()
 	if err != nil {
 		vm.mempool.CancelCurrentTxs()
@@ -1279,6 +1518,10 @@ func (vm *VM) buildBlock(_ context.Context) (snowman.Block, error) {
 	}
 
 	log.Debug(fmt.Sprintf("Built block %s", blk.ID()))
+	if truncated {
+		log.Debug("block building stopped at deadline", "blkID", blk.ID())
+		buildBlockDeadlineTruncated.Inc(1)
+	}
 	// Marks the current transactions from the mempool as being successfully issued
 	// into a block.
 	vm.mempool.IssueCurrentTxs()
@@ -1550,6 +1793,26 @@ func (vm *VM) ParseAddress(addrStr string) (ids.ID, ids.ShortID, error) {
 // issueTx verifies [tx] as valid to be issued on top of the currently preferred block
 // and then issues [tx] into the mempool if valid.
 func (vm *VM) issueTx(tx *Tx, local bool) error {
+	return vm.issueTxWithFeeCap(tx, local, nil)
+}
+
+// issueTxWithFeeCap is like issueTx, but if [maxBaseFee] is non-nil, [tx] is dropped from the
+// mempool if the base fee rises above [maxBaseFee] before it is included in a block.
+func (vm *VM) issueTxWithFeeCap(tx *Tx, local bool, maxBaseFee *big.Int) error {
+	return vm.issueTxToMempool(tx, local, func(tx *Tx) error {
+		return vm.mempool.AddTxWithFeeCap(tx, maxBaseFee)
+	})
+}
+
+// issueTxWithoutGossip is like issueTx for a remote tx, but [tx] is only added
+// to the local mempool and is never gossiped to peers.
+func (vm *VM) issueTxWithoutGossip(tx *Tx) error {
+	return vm.issueTxToMempool(tx, false, vm.mempool.AddTxWithoutGossip)
+}
+
+// issueTxToMempool verifies [tx] as valid to be issued on top of the currently
+// preferred block and then adds it to the mempool with [add] if valid.
+func (vm *VM) issueTxToMempool(tx *Tx, local bool, add func(*Tx) error) error {
 	if err := vm.verifyTxAtTip(tx); err != nil {
 		if !local {
 			// unlike local txs, invalid remote txs are recorded as discarded
@@ -1565,7 +1828,7 @@ func (vm *VM) issueTx(tx *Tx, local bool) error {
 		return err
 	}
 	// add to mempool and possibly re-gossip
-	if err := vm.mempool.AddTx(tx); err != nil {
+	if err := add(tx); err != nil {
 		if !local {
 			// unlike local txs, invalid remote txs are recorded as discarded
 			// so that they won't be requested again
@@ -1583,6 +1846,68 @@ func (vm *VM) issueTx(tx *Tx, local bool) error {
 	return nil
 }
 
+// RevalidateAtomicMempool verifies each pending atomic tx in the mempool against
+// the rules of the next block to be built, and discards the txs that are no
+// longer valid. This is safe to call at fork boundaries to purge txs that were
+// only valid under the previous rules. It returns the number of discarded txs.
+func (vm *VM) RevalidateAtomicMempool() int {
+	header := vm.blockChain.CurrentBlock()
+	rules := vm.chainConfig.OdysseyRules(new(big.Int).Add(header.Number, common.Big1), uint64(vm.clock.Time().Unix()))
+	removed := vm.mempool.DiscardInvalidTxs(func(tx *Tx) error {
+		return tx.UnsignedAtomicTx.Verify(vm.ctx, rules)
+	})
+	if removed > 0 {
+		log.Info("revalidated atomic mempool", "removed", removed)
+	}
+	return removed
+}
+
+// TrimAtomicMempool discards the pending atomic txs that were issued to the
+// mempool more than [maxAge] ago and returns the number of discarded txs.
+func (vm *VM) TrimAtomicMempool(maxAge time.Duration) int {
+	now := time.Now()
+	stale := vm.mempool.DiscardOlderThan(now.Add(-maxAge))
+	for _, tx := range stale {
+		log.Info("evicting stale atomic tx from mempool",
+			"txID", tx.tx.ID(),
+			"age", now.Sub(tx.added),
+			"maxAge", maxAge,
+		)
+	}
+	return len(stale)
+}
+
+// awaitAtomicMempoolTrim periodically trims the atomic mempool to
+// [MemPoolMaxAge], if it is set.
+func (vm *VM) awaitAtomicMempoolTrim() {
+	maxAge := vm.config.MemPoolMaxAge.Duration
+	if maxAge <= 0 {
+		return
+	}
+	frequency := atomicMempoolTrimFrequency
+	if maxAge < frequency {
+		frequency = maxAge
+	}
+
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		ticker := time.NewTicker(frequency)
+		defer func() {
+			ticker.Stop()
+			vm.shutdownWg.Done()
+		}()
+
+		for {
+			select {
+			case <-ticker.C:
+				vm.TrimAtomicMempool(maxAge)
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+}
+
 // verifyTxAtTip verifies that [tx] is valid to be issued on top of the currently preferred block
 func (vm *VM) verifyTxAtTip(tx *Tx) error {
 	// Note: we fetch the current block and then the state at that block instead of the current state directly
@@ -1604,15 +1929,38 @@ func (vm *VM) verifyTxAtTip(tx *Tx) error {
 		}
 	}
 
+	// Note: [preferredState] is a throwaway state, so it is safe to modify it here.
+	vm.advancePendingNonces(tx, preferredState)
 	return vm.verifyTx(tx, parentHeader.Hash(), nextBaseFee, preferredState, rules)
 }
 
+// advancePendingNonces sets the nonces in [state] of the accounts exported from
+// by [tx] to the nonces consumed by [tx]. An export may consume a nonce ahead of
+// [state] as long as the gap is filled by EVM transactions pending in the tx pool,
+// since those will be executed before the atomic transactions of the block
+// including them.
+func (vm *VM) advancePendingNonces(tx *Tx, state *state.StateDB) {
+	exportTx, ok := tx.UnsignedAtomicTx.(*UnsignedExportTx)
+	if !ok {
+		return
+	}
+	for _, in := range exportTx.Ins {
+		if nonce := state.GetNonce(in.Address); nonce < in.Nonce && in.Nonce <= vm.GetPendingNonce(in.Address) {
+			state.SetNonce(in.Address, in.Nonce)
+		}
+	}
+}
+
 // verifyTx verifies that [tx] is valid to be issued into a block with parent block [parentHash]
-// and validated at [state] using [rules] as the current rule set.
+// and validated at [state] using [rules] as the current rule set, and that it is allowed by the
+// local atomic asset allowlist.
 // Note: verifyTx may modify [state]. If [state] needs to be properly maintained, the caller is responsible
 // for reverting to the correct snapshot after calling this function. If this function is called with a
 // throwaway state, then this is not necessary.
 func (vm *VM) verifyTx(tx *Tx, parentHash common.Hash, baseFee *big.Int, state *state.StateDB, rules params.Rules) error {
+	if err := vm.verifyAtomicTxAssetsAllowed(tx); err != nil {
+		return err
+	}
 	parentIntf, err := vm.GetBlockInternal(context.TODO(), ids.ID(parentHash))
 	if err != nil {
 		return fmt.Errorf("failed to get parent block: %w", err)
@@ -1624,7 +1972,7 @@ func (vm *VM) verifyTx(tx *Tx, parentHash common.Hash, baseFee *big.Int, state *
 	if err := tx.UnsignedAtomicTx.SemanticVerify(vm, tx, parent, baseFee, rules); err != nil {
 		return err
 	}
-	return tx.UnsignedAtomicTx.DELTAStateTransfer(vm.ctx, state)
+	return tx.UnsignedAtomicTx.DELTAStateTransfer(vm.ctx, state, rules)
 }
 
 // verifyTxs verifies that [txs] are valid to be issued into a block with parent block [parentHash]
@@ -1728,20 +2076,33 @@ func (vm *VM) GetSpendableFunds(
 	assetID ids.ID,
 	amount uint64,
 ) ([]DELTAInput, [][]*secp256k1.PrivateKey, error) {
+	inputs, err := vm.getSpendableFunds(keysToEthAddresses(keys), assetID, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputs, inputSigners(inputs, keys), nil
+}
+
+// getSpendableFunds returns a list of DELTAInputs to total [amount] of
+// [assetID] owned by [addrs].
+func (vm *VM) getSpendableFunds(
+	addrs []common.Address,
+	assetID ids.ID,
+	amount uint64,
+) ([]DELTAInput, error) {
 	// Note: current state uses the state of the preferred block.
 	state, err := vm.blockChain.State()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	inputs := []DELTAInput{}
-	signers := [][]*secp256k1.PrivateKey{}
-	// Note: we assume that each key in [keys] is unique, so that iterating over
-	// the keys will not produce duplicated nonces in the returned DELTAInput slice.
-	for _, key := range keys {
+	// Note: we assume that each address in [addrs] is unique, so that iterating
+	// over the addresses will not produce duplicated nonces in the returned
+	// DELTAInput slice.
+	for _, addr := range addrs {
 		if amount == 0 {
 			break
 		}
-		addr := GetEthAddress(key)
 		var balance uint64
 		if assetID == vm.ctx.DIONEAssetID {
 			// If the asset is DIONE, we divide by the x2cRate to convert back to the correct
@@ -1756,25 +2117,21 @@ func (vm *VM) GetSpendableFunds(
 		if amount < balance {
 			balance = amount
 		}
-		nonce, err := vm.GetCurrentNonce(addr)
-		if err != nil {
-			return nil, nil, err
-		}
+		nonce := vm.GetPendingNonce(addr)
 		inputs = append(inputs, DELTAInput{
 			Address: addr,
 			Amount:  balance,
 			AssetID: assetID,
 			Nonce:   nonce,
 		})
-		signers = append(signers, []*secp256k1.PrivateKey{key})
 		amount -= balance
 	}
 
 	if amount > 0 {
-		return nil, nil, errInsufficientFunds
+		return nil, errInsufficientFunds
 	}
 
-	return inputs, signers, nil
+	return inputs, nil
 }
 
 // GetSpendableDIONEWithFee returns a list of DELTAInputs and keys (in corresponding
@@ -1791,46 +2148,61 @@ func (vm *VM) GetSpendableDIONEWithFee(
 	cost uint64,
 	baseFee *big.Int,
 ) ([]DELTAInput, [][]*secp256k1.PrivateKey, error) {
+	inputs, err := vm.getSpendableDIONEWithFee(keysToEthAddresses(keys), amount, cost, baseFee)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputs, inputSigners(inputs, keys), nil
+}
+
+// getSpendableDIONEWithFee returns a list of DELTAInputs to total [amount] +
+// [fee] of [DIONE] owned by [addrs]. Addresses with a balance that is
+// insufficient to cover the fee of the additional input are skipped.
+func (vm *VM) getSpendableDIONEWithFee(
+	addrs []common.Address,
+	amount uint64,
+	cost uint64,
+	baseFee *big.Int,
+) ([]DELTAInput, error) {
 	// Note: current state uses the state of the preferred block.
 	state, err := vm.blockChain.State()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	initialFee, err := CalculateDynamicFee(cost, baseFee)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	newAmount, err := math.Add64(amount, initialFee)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	amount = newAmount
 
 	inputs := []DELTAInput{}
-	signers := [][]*secp256k1.PrivateKey{}
-	// Note: we assume that each key in [keys] is unique, so that iterating over
-	// the keys will not produce duplicated nonces in the returned DELTAInput slice.
-	for _, key := range keys {
+	// Note: we assume that each address in [addrs] is unique, so that iterating
+	// over the addresses will not produce duplicated nonces in the returned
+	// DELTAInput slice.
+	for _, addr := range addrs {
 		if amount == 0 {
 			break
 		}
 
 		prevFee, err := CalculateDynamicFee(cost, baseFee)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		newCost := cost + DELTAInputGas
 		newFee, err := CalculateDynamicFee(newCost, baseFee)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		additionalFee := newFee - prevFee
 
-		addr := GetEthAddress(key)
 		// Since the asset is DIONE, we divide by the x2cRate to convert back to
 		// the correct denomination of DIONE that can be exported.
 		balance := new(big.Int).Div(state.GetBalance(addr), x2cRate).Uint64()
@@ -1845,7 +2217,7 @@ func (vm *VM) GetSpendableDIONEWithFee(
 
 		newAmount, err := math.Add64(amount, additionalFee)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		amount = newAmount
 
@@ -1856,25 +2228,44 @@ func (vm *VM) GetSpendableDIONEWithFee(
 		if amount < balance {
 			inputAmount = amount
 		}
-		nonce, err := vm.GetCurrentNonce(addr)
-		if err != nil {
-			return nil, nil, err
-		}
+		nonce := vm.GetPendingNonce(addr)
 		inputs = append(inputs, DELTAInput{
 			Address: addr,
 			Amount:  inputAmount,
 			AssetID: vm.ctx.DIONEAssetID,
 			Nonce:   nonce,
 		})
-		signers = append(signers, []*secp256k1.PrivateKey{key})
 		amount -= inputAmount
 	}
 
 	if amount > 0 {
-		return nil, nil, errInsufficientFunds
+		return nil, errInsufficientFunds
 	}
 
-	return inputs, signers, nil
+	return inputs, nil
+}
+
+// keysToEthAddresses returns the Ethereum address of each key in [keys]
+func keysToEthAddresses(keys []*secp256k1.PrivateKey) []common.Address {
+	addrs := make([]common.Address, len(keys))
+	for i, key := range keys {
+		addrs[i] = GetEthAddress(key)
+	}
+	return addrs
+}
+
+// inputSigners returns the key in [keys] that controls each of [inputs], in
+// corresponding order.
+func inputSigners(inputs []DELTAInput, keys []*secp256k1.PrivateKey) [][]*secp256k1.PrivateKey {
+	keysByAddr := make(map[common.Address]*secp256k1.PrivateKey, len(keys))
+	for _, key := range keys {
+		keysByAddr[GetEthAddress(key)] = key
+	}
+	signers := make([][]*secp256k1.PrivateKey, len(inputs))
+	for i, input := range inputs {
+		signers[i] = []*secp256k1.PrivateKey{keysByAddr[input.Address]}
+	}
+	return signers
 }
 
 // GetCurrentNonce returns the nonce associated with the address at the
@@ -1888,12 +2279,33 @@ func (vm *VM) GetCurrentNonce(address common.Address) (uint64, error) {
 	return state.GetNonce(address), nil
 }
 
+// GetPendingNonce returns the next nonce available to [address], accounting
+// for the EVM transactions pending in the tx pool and the nonces reserved by
+// export transactions in the atomic mempool.
+func (vm *VM) GetPendingNonce(address common.Address) uint64 {
+	return vm.txPool.Nonce(address)
+}
+
 // currentRules returns the chain rules for the current block.
 func (vm *VM) currentRules() params.Rules {
 	header := vm.eth.APIBackend.CurrentHeader()
 	return vm.chainConfig.OdysseyRules(header.Number, header.Time)
 }
 
+// WouldAtomicTxBeValid returns an error if [tx] would fail verification under
+// the rules of a block built on the current block at [atTimestamp]. Only the
+// stateless verification of [tx] is performed, so [tx] may still be rejected
+// at [atTimestamp], for example if its inputs have been spent.
+func (vm *VM) WouldAtomicTxBeValid(tx *Tx, atTimestamp uint64) error {
+	if tx == nil || tx.UnsignedAtomicTx == nil {
+		return errNilTx
+	}
+	header := vm.eth.APIBackend.CurrentHeader()
+	height := new(big.Int).Add(header.Number, common.Big1)
+	rules := vm.chainConfig.OdysseyRules(height, atTimestamp)
+	return tx.UnsignedAtomicTx.Verify(vm.ctx, rules)
+}
+
 func (vm *VM) startContinuousProfiler() {
 	// If the profiler directory is empty, return immediately
 	// without creating or starting a continuous profiler.
@@ -1937,6 +2349,173 @@ func (vm *VM) estimateBaseFee(ctx context.Context) (*big.Int, error) {
 	return baseFee, nil
 }
 
+// minBaseFee returns the lowest base fee a block built on the current chain
+// may have.
+func (vm *VM) minBaseFee() *big.Int {
+	if vm.chainConfig.IsApricotPhase4(uint64(vm.clock.Time().Unix())) {
+		return dummy.ApricotPhase4MinBaseFee
+	}
+	return dummy.ApricotPhase3MinBaseFee
+}
+
+// verifyAtomicAssetAllowed returns an error if the atomic asset allowlist is
+// set and [assetID] is neither DIONE nor in the allowlist.
+func (vm *VM) verifyAtomicAssetAllowed(assetID ids.ID) error {
+	allowlist := vm.config.AtomicAssetAllowlist
+	if len(allowlist) == 0 || assetID == vm.ctx.DIONEAssetID || slices.Contains(allowlist, assetID) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errAtomicAssetNotAllowed, assetID)
+}
+
+// verifyAtomicTxAssetsAllowed returns an error if [tx] imports or exports an
+// asset that is not allowed by the atomic asset allowlist.
+// Note: the allowlist is a local policy, so it is only applied to txs issued to
+// the mempool or packed into blocks built by this node, and never to the txs of
+// blocks being verified.
+func (vm *VM) verifyAtomicTxAssetsAllowed(tx *Tx) error {
+	switch utx := tx.UnsignedAtomicTx.(type) {
+	case *UnsignedImportTx:
+		for _, in := range utx.ImportedInputs {
+			if err := vm.verifyAtomicAssetAllowed(in.AssetID()); err != nil {
+				return err
+			}
+		}
+		for _, out := range utx.Outs {
+			if err := vm.verifyAtomicAssetAllowed(out.AssetID); err != nil {
+				return err
+			}
+		}
+	case *UnsignedExportTx:
+		for _, in := range utx.Ins {
+			if err := vm.verifyAtomicAssetAllowed(in.AssetID); err != nil {
+				return err
+			}
+		}
+		for _, out := range utx.ExportedOutputs {
+			if err := vm.verifyAtomicAssetAllowed(out.AssetID()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// verifyAtomicTxFee returns an error if [fee] exceeds the atomic tx fee limits
+// configured for this node, where [amount] is the amount of DIONE moved by the
+// tx. The limits are a local policy applied when creating txs and are not
+// enforced during verification.
+func (vm *VM) verifyAtomicTxFee(fee, amount uint64) error {
+	if maxFee := vm.config.AtomicTxMaxFee; maxFee != 0 && fee > maxFee {
+		return fmt.Errorf("%w: fee of %d nDIONE is above the maximum of %d nDIONE, set allowHighFee to proceed anyway",
+			errAtomicTxFeeTooHigh, fee, maxFee)
+	}
+	if fraction := vm.config.AtomicTxMaxFeeFraction; fraction != 0 && amount != 0 && float64(fee) > fraction*float64(amount) {
+		return fmt.Errorf("%w: fee of %d nDIONE is above %v of the %d nDIONE moved, set allowHighFee to proceed anyway",
+			errAtomicTxFeeTooHigh, fee, fraction, amount)
+	}
+	return nil
+}
+
+// SuggestAtomicTxGasPrice returns a gas price for an atomic transaction moving
+// funds to or from [chainID]. The estimated base fee is scaled up in proportion
+// to the share of the atomic gas limit already claimed by the transactions
+// pending in the mempool, reaching at most twice the base fee once the limit has
+// been exhausted.
+func (vm *VM) SuggestAtomicTxGasPrice(chainID ids.ID) (*big.Int, error) {
+	if err := verify.SameSubnet(context.TODO(), vm.ctx, chainID); err != nil {
+		return nil, err
+	}
+	baseFee, err := vm.estimateBaseFee(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	gasUsed, err := vm.mempool.PendingGasUsed()
+	if err != nil {
+		return nil, err
+	}
+	rules := vm.currentRules()
+	atomicGasLimit := rules.AtomicGasLimit()
+	gasLimit := atomicGasLimit.Uint64()
+	if gasUsed > gasLimit {
+		gasUsed = gasLimit
+	}
+	// price = baseFee * (gasLimit + gasUsed) / gasLimit
+	price := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(gasLimit+gasUsed))
+	return price.Div(price, atomicGasLimit), nil
+}
+
+// EstimateNextBaseFee returns the estimated base fee of a block built now on
+// top of the preferred block, computed from the gas consumed by the preferred
+// block only. If [includeMempool] is true, it also returns the estimate with
+// the gas of the txs pending in the atomic mempool and the tx pool added into
+// the rollup window, as they are expected to be included in the next block.
+// Otherwise, [mempoolAware] is nil. Both estimates are nil prior to
+// ApricotPhase3.
+func (vm *VM) EstimateNextBaseFee(includeMempool bool) (parentOnly, mempoolAware *big.Int, err error) {
+	parent := vm.blockChain.CurrentBlock()
+	timestamp := uint64(vm.clock.Time().Unix())
+	if !vm.chainConfig.IsApricotPhase3(timestamp) {
+		return nil, nil, nil
+	}
+	_, parentOnly, err = dummy.EstimateNextBaseFee(vm.chainConfig, parent, timestamp)
+	if err != nil || !includeMempool {
+		return parentOnly, nil, err
+	}
+
+	atomicGas, evmGas, err := vm.pendingGas(parent)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, mempoolAware, err = dummy.EstimateNextBaseFeeWithPendingGas(vm.chainConfig, parent, timestamp, atomicGas+evmGas)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parentOnly, mempoolAware, nil
+}
+
+// GetBaseFeeWindow returns the gas consumed in each second of the rollup window
+// encoded in [blockID], oldest first, and the base fee of the block. The rollup
+// window is only encoded from ApricotPhase3.
+func (vm *VM) GetBaseFeeWindow(blockID ids.ID) ([]uint64, *big.Int, error) {
+	header := vm.blockChain.GetHeaderByHash(common.Hash(blockID))
+	if header == nil {
+		return nil, nil, fmt.Errorf("%w: block %s", database.ErrNotFound, blockID)
+	}
+	window, err := dummy.DecodeRollupWindow(vm.chainConfig, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	return window, header.BaseFee, nil
+}
+
+// pendingGas returns the atomic gas of the txs pending in the atomic mempool,
+// capped at the atomic gas limit, and the gas of the txs pending in the tx
+// pool, capped at the gas limit of [parent]. The atomic gas is only included
+// from ApricotPhase4, when it starts counting towards the rollup window.
+func (vm *VM) pendingGas(parent *types.Header) (atomicGas, evmGas uint64, err error) {
+	if vm.chainConfig.IsApricotPhase4(parent.Time) {
+		atomicGas, err = vm.mempool.PendingGasUsed()
+		if err != nil {
+			return 0, 0, err
+		}
+		rules := vm.currentRules()
+		if atomicGasLimit := rules.AtomicGasLimit().Uint64(); atomicGas > atomicGasLimit {
+			atomicGas = atomicGasLimit
+		}
+	}
+
+	for _, txs := range vm.txPool.Pending(true) {
+		for _, tx := range txs {
+			evmGas += tx.Gas()
+			if evmGas >= parent.GasLimit {
+				return atomicGas, parent.GasLimit, nil
+			}
+		}
+	}
+	return atomicGas, evmGas, nil
+}
+
 func (vm *VM) getAtomicTxFromPreApricot5BlockByHeight(height uint64) (*Tx, error) {
 	blk := vm.blockChain.GetBlockByNumber(height)
 	if blk == nil {
@@ -2017,3 +2596,62 @@ func (vm *VM) stateSyncEnabled(lastAcceptedHeight uint64) bool {
 	// enable state sync by default if the chain is empty.
 	return lastAcceptedHeight == 0
 }
+
+// GetOrionNodes returns the orion nodes registered in the state of the accepted
+// block with the largest timestamp that is not after [timestamp].
+func (vm *VM) GetOrionNodes(ctx context.Context, timestamp uint64) ([]ids.NodeID, error) {
+	header, state, err := vm.orionNodesState(timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return vm.chainConfig.OrionNodesGetter(header.Time).GetNodesListAtRoot(header.Root, state)
+}
+
+// GetOrionNodesCount returns the number of orion nodes that GetOrionNodes
+// returns for [timestamp], without reading the nodes list.
+func (vm *VM) GetOrionNodesCount(ctx context.Context, timestamp uint64) (uint64, error) {
+	header, state, err := vm.orionNodesState(timestamp)
+	if err != nil {
+		return 0, err
+	}
+	return vm.chainConfig.OrionNodesGetter(header.Time).GetNodesCount(state)
+}
+
+// orionNodesState returns the accepted block with the largest timestamp that
+// is not after [timestamp] and its state.
+func (vm *VM) orionNodesState(timestamp uint64) (*types.Header, *state.StateDB, error) {
+	lastAccepted := vm.blockChain.LastAcceptedBlock().NumberU64()
+	// If the VM state synced, the headers between genesis and the synced block
+	// are missing, so the search starts at the lowest header after genesis.
+	lowest := uint64(sort.Search(int(lastAccepted), func(i int) bool {
+		return vm.blockChain.GetHeaderByNumber(uint64(i)+1) != nil
+	})) + 1
+	// Block timestamps do not decrease along the canonical chain, so the first
+	// block after [timestamp] follows the block to read the orion nodes from.
+	var errMissing error
+	next := lowest + uint64(sort.Search(int(lastAccepted-lowest+1), func(i int) bool {
+		number := lowest + uint64(i)
+		header := vm.blockChain.GetHeaderByNumber(number)
+		if header == nil {
+			errMissing = fmt.Errorf("%w %d", errMissingAcceptedHeader, number)
+			return true
+		}
+		return header.Time > timestamp
+	}))
+	if errMissing != nil {
+		return nil, nil, errMissing
+	}
+
+	header := vm.blockChain.GetHeaderByNumber(next - 1)
+	if header == nil {
+		return nil, nil, fmt.Errorf("%w %d before timestamp %d", errMissingAcceptedHeader, next-1, timestamp)
+	}
+	if header.Time > timestamp {
+		return nil, nil, fmt.Errorf("%w %d", errNoBlockBeforeTimestamp, timestamp)
+	}
+	state, err := vm.blockChain.StateAt(header.Root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get state of block %d: %w", header.Number, err)
+	}
+	return header, state, nil
+}
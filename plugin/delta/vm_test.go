@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -34,8 +35,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/DioneProtocol/odysseygo/api"
 	"github.com/DioneProtocol/odysseygo/api/keystore"
 	"github.com/DioneProtocol/odysseygo/chains/atomic"
+	"github.com/DioneProtocol/odysseygo/database"
 	"github.com/DioneProtocol/odysseygo/database/manager"
 	"github.com/DioneProtocol/odysseygo/database/prefixdb"
 	"github.com/DioneProtocol/odysseygo/ids"
@@ -47,6 +50,7 @@ import (
 	"github.com/DioneProtocol/odysseygo/utils/crypto/secp256k1"
 	"github.com/DioneProtocol/odysseygo/utils/formatting"
 	"github.com/DioneProtocol/odysseygo/utils/hashing"
+	odysseyJSON "github.com/DioneProtocol/odysseygo/utils/json"
 	"github.com/DioneProtocol/odysseygo/utils/logging"
 	"github.com/DioneProtocol/odysseygo/utils/set"
 	"github.com/DioneProtocol/odysseygo/utils/units"
@@ -54,12 +58,14 @@ import (
 	"github.com/DioneProtocol/odysseygo/vms/components/chain"
 	"github.com/DioneProtocol/odysseygo/vms/components/dione"
 	"github.com/DioneProtocol/odysseygo/vms/components/feecollector"
+	"github.com/DioneProtocol/odysseygo/vms/components/verify"
 	"github.com/DioneProtocol/odysseygo/vms/secp256k1fx"
 
 	engCommon "github.com/DioneProtocol/odysseygo/snow/engine/common"
 
 	"github.com/DioneProtocol/coreth/consensus/dummy"
 	"github.com/DioneProtocol/coreth/core"
+	"github.com/DioneProtocol/coreth/core/rawdb"
 	"github.com/DioneProtocol/coreth/core/types"
 	"github.com/DioneProtocol/coreth/eth"
 	"github.com/DioneProtocol/coreth/params"
@@ -126,7 +132,7 @@ func init() {
 }
 
 // BuildGenesisTest returns the genesis bytes for Coreth VM to be used in testing
-func BuildGenesisTest(t *testing.T, genesisJSON string) []byte {
+func BuildGenesisTest(t testing.TB, genesisJSON string) []byte {
 	ss := StaticService{}
 
 	genesis := &core.Genesis{}
@@ -175,7 +181,7 @@ func NewContext() *snow.Context {
 
 // setupGenesis sets up the genesis
 // If [genesisJSON] is empty, defaults to using [genesisJSONLatest]
-func setupGenesis(t *testing.T,
+func setupGenesis(t testing.TB,
 	genesisJSON string,
 ) (*snow.Context,
 	manager.Manager,
@@ -316,6 +322,30 @@ func GenesisVMWithUTXOs(t *testing.T, finishBootstrapping bool, genesisJSON stri
 	return issuer, vm, dbManager, sharedMemory, sender
 }
 
+// newLatestRulesTestVM returns a VM running the latest rules, where the
+// first test address is funded directly in the genesis.
+func newLatestRulesTestVM(t *testing.T) (chan engCommon.Message, *VM) {
+	genesis := &core.Genesis{}
+	require.NoError(t, json.Unmarshal([]byte(genesisJSONLatest), genesis))
+	genesis.Alloc[testEthAddrs[0]] = core.GenesisAccount{
+		Balance: new(big.Int).Mul(new(big.Int).SetUint64(units.MegaDione), x2cRate),
+	}
+	genesisJSON, err := json.Marshal(genesis)
+	require.NoError(t, err)
+
+	issuer, vm, _, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	return issuer, vm
+}
+
+// newTestEthTx returns a signed EVM transaction from the first test address
+// using [nonce].
+func newTestEthTx(t *testing.T, vm *VM, nonce uint64) *types.Transaction {
+	tx := types.NewTransaction(nonce, testEthAddrs[1], big.NewInt(10), 21000, big.NewInt(params.ApricotPhase4MaxBaseFee), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainID), testKeys[0].ToECDSA())
+	require.NoError(t, err)
+	return signedTx
+}
+
 func TestVMConfig(t *testing.T) {
 	txFeeCap := float64(11)
 	enabledEthAPIs := []string{"debug"}
@@ -326,6 +356,54 @@ func TestVMConfig(t *testing.T) {
 	assert.NoError(t, vm.Shutdown(context.Background()))
 }
 
+func TestNewDeltaVMWithConfig(t *testing.T) {
+	require := require.New(t)
+
+	var cfg Config
+	cfg.SetDefaults()
+	cfg.RPCTxFeeCap = 11
+	vm := NewDeltaVMWithConfig(&cfg)
+	require.False(vm.IsPlugin)
+	// The VM is not affected by later changes to the config it was created with.
+	cfg.RPCTxFeeCap = 12
+
+	ctx, dbManager, genesisBytes, issuer, sharedMemory := setupGenesis(t, genesisJSONLatest)
+	require.NoError(vm.Initialize(
+		context.Background(),
+		ctx,
+		dbManager,
+		genesisBytes,
+		nil,
+		nil,
+		issuer,
+		[]*engCommon.Fx{},
+		&engCommon.SenderTest{},
+	))
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	require.Equal(float64(11), vm.config.RPCTxFeeCap)
+	require.NoError(vm.SetState(context.Background(), snow.Bootstrapping))
+	require.NoError(vm.SetState(context.Background(), snow.NormalOp))
+
+	_, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, vm.ctx.DIONEAssetID, 10*params.OdysseyAtomicTxFee, testShortIDAddrs[0])
+	require.NoError(err)
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.issueTx(importTx, true /*=local*/))
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	lastAcceptedID, err := vm.LastAccepted(context.Background())
+	require.NoError(err)
+	require.Equal(blk.ID(), lastAcceptedID)
+}
+
 func TestCrossChainMessagestoVM(t *testing.T) {
 	crossChainCodec := message.CrossChainCodec
 	require := require.New(t)
@@ -380,7 +458,7 @@ func TestCrossChainMessagestoVM(t *testing.T) {
 	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
 	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	require.NoError(err)
 
 	err = vm.issueTx(importTx, true /*=local*/)
@@ -665,7 +743,7 @@ func TestImportMissingUTXOs(t *testing.T) {
 		require.NoError(t, err)
 	}()
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	require.NoError(t, err)
 	err = vm.issueTx(importTx, true /*=local*/)
 	require.NoError(t, err)
@@ -705,7 +783,7 @@ func TestIssueAtomicTxs(t *testing.T) {
 		}
 	}()
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -764,7 +842,7 @@ func TestIssueAtomicTxs(t *testing.T) {
 		t.Fatal("Expected logs to be non-nil")
 	}
 
-	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, importAmount-(2*params.OdysseyAtomicTxFee), vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, importAmount-(2*params.OdysseyAtomicTxFee), vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -831,7 +909,7 @@ func TestBuildEthTxBlock(t *testing.T) {
 	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
 	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -992,14 +1070,14 @@ func testConflictingImportTxs(t *testing.T, genesis string) {
 	importTxs := make([]*Tx, 0, 3)
 	conflictTxs := make([]*Tx, 0, 3)
 	for i, key := range testKeys {
-		importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[i], initialBaseFee, []*secp256k1.PrivateKey{key})
+		importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[i], initialBaseFee, []*secp256k1.PrivateKey{key}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
 		importTxs = append(importTxs, importTx)
 
 		conflictAddr := testEthAddrs[(i+1)%len(testEthAddrs)]
-		conflictTx, err := vm.newImportTx(vm.ctx.AChainID, conflictAddr, initialBaseFee, []*secp256k1.PrivateKey{key})
+		conflictTx, err := vm.newImportTx(vm.ctx.AChainID, conflictAddr, initialBaseFee, []*secp256k1.PrivateKey{key}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -1166,11 +1244,11 @@ func TestReissueAtomicTxHigherGasPrice(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			tx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo})
+			tx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
-			tx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(common.Big2, initialBaseFee), kc, []*dione.UTXO{utxo})
+			tx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(common.Big2, initialBaseFee), kc, []*dione.UTXO{utxo}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1193,11 +1271,11 @@ func TestReissueAtomicTxHigherGasPrice(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			tx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo1, utxo2})
+			tx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo1, utxo2}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
-			tx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(common.Big2, initialBaseFee), kc, []*dione.UTXO{utxo1})
+			tx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(common.Big2, initialBaseFee), kc, []*dione.UTXO{utxo1}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1221,17 +1299,17 @@ func TestReissueAtomicTxHigherGasPrice(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			importTx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo1})
+			importTx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo1}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			importTx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(big.NewInt(3), initialBaseFee), kc, []*dione.UTXO{utxo2})
+			importTx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(big.NewInt(3), initialBaseFee), kc, []*dione.UTXO{utxo2}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			reissuanceTx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(big.NewInt(2), initialBaseFee), kc, []*dione.UTXO{utxo1, utxo2})
+			reissuanceTx1, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(big.NewInt(2), initialBaseFee), kc, []*dione.UTXO{utxo1, utxo2}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1251,7 +1329,7 @@ func TestReissueAtomicTxHigherGasPrice(t *testing.T) {
 			assert.True(t, vm.mempool.has(importTx2.ID()))
 			assert.False(t, vm.mempool.has(reissuanceTx1.ID()))
 
-			reissuanceTx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(big.NewInt(4), initialBaseFee), kc, []*dione.UTXO{utxo1, utxo2})
+			reissuanceTx2, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(big.NewInt(4), initialBaseFee), kc, []*dione.UTXO{utxo1, utxo2}, false)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1330,7 +1408,7 @@ func TestSetPreferenceRace(t *testing.T) {
 	newTxPoolHeadChan2 := make(chan core.NewTxPoolReorgEvent, 1)
 	vm2.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan2)
 
-	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, testEthAddrs[1], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, testEthAddrs[1], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1574,12 +1652,12 @@ func TestConflictingTransitiveAncestryWithGap(t *testing.T) {
 	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
 	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
 
-	importTx0A, err := vm.newImportTx(vm.ctx.AChainID, key.Address, initialBaseFee, []*secp256k1.PrivateKey{key0})
+	importTx0A, err := vm.newImportTx(vm.ctx.AChainID, key.Address, initialBaseFee, []*secp256k1.PrivateKey{key0}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
 	// Create a conflicting transaction
-	importTx0B, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[2], initialBaseFee, []*secp256k1.PrivateKey{key0})
+	importTx0B, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[2], initialBaseFee, []*secp256k1.PrivateKey{key0}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1637,7 +1715,7 @@ func TestConflictingTransitiveAncestryWithGap(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	importTx1, err := vm.newImportTx(vm.ctx.AChainID, key.Address, initialBaseFee, []*secp256k1.PrivateKey{key1})
+	importTx1, err := vm.newImportTx(vm.ctx.AChainID, key.Address, initialBaseFee, []*secp256k1.PrivateKey{key1}, false)
 	if err != nil {
 		t.Fatalf("Failed to issue importTx1 due to: %s", err)
 	}
@@ -1716,7 +1794,7 @@ func TestBonusBlocksTxs(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1809,7 +1887,7 @@ func TestReorgProtection(t *testing.T) {
 	key := testKeys[0].ToECDSA()
 	address := testEthAddrs[0]
 
-	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1991,7 +2069,7 @@ func TestNonCanonicalAccept(t *testing.T) {
 	key := testKeys[0].ToECDSA()
 	address := testEthAddrs[0]
 
-	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2166,7 +2244,7 @@ func TestStickyPreference(t *testing.T) {
 	key := testKeys[0].ToECDSA()
 	address := testEthAddrs[0]
 
-	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2439,7 +2517,7 @@ func TestUncleBlock(t *testing.T) {
 	key := testKeys[0].ToECDSA()
 	address := testEthAddrs[0]
 
-	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2622,7 +2700,7 @@ func TestEmptyBlock(t *testing.T) {
 		}
 	}()
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2703,7 +2781,7 @@ func TestAcceptReorg(t *testing.T) {
 	key := testKeys[0].ToECDSA()
 	address := testEthAddrs[0]
 
-	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2898,7 +2976,7 @@ func TestFutureBlock(t *testing.T) {
 		}
 	}()
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2937,6 +3015,11 @@ func TestFutureBlock(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// The future skew depends on the wall clock, so it is only checked when
+	// the block is verified and not when it is parsed.
+	if err := futureBlock.syntacticVerify(); err != nil {
+		t.Fatalf("Future block should have passed syntactic verification but found %s", err)
+	}
 	if err := futureBlock.Verify(context.Background()); err == nil {
 		t.Fatal("Future block should have failed verification due to block timestamp too far in the future")
 	} else if !strings.Contains(err.Error(), "block timestamp is too far in the future") {
@@ -2963,7 +3046,7 @@ func TestBuildApricotPhase1Block(t *testing.T) {
 	key := testKeys[0].ToECDSA()
 	address := testEthAddrs[0]
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3079,7 +3162,7 @@ func TestLastAcceptedBlockNumberAllow(t *testing.T) {
 		}
 	}()
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3157,7 +3240,7 @@ func TestReissueAtomicTx(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3486,7 +3569,7 @@ func TestBuildApricotPhase4Block(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3668,7 +3751,7 @@ func TestBuildApricotPhase5Block(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, address, initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3875,7 +3958,7 @@ func TestAtomicTxBuildBlockDropsConflicts(t *testing.T) {
 	// Create a conflict set for each pair of transactions
 	conflictSets := make([]set.Set[ids.ID], len(testKeys))
 	for index, key := range testKeys {
-		importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[index], initialBaseFee, []*secp256k1.PrivateKey{key})
+		importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[index], initialBaseFee, []*secp256k1.PrivateKey{key}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -3883,7 +3966,7 @@ func TestAtomicTxBuildBlockDropsConflicts(t *testing.T) {
 			t.Fatal(err)
 		}
 		conflictSets[index].Add(importTx.ID())
-		conflictTx, err := vm.newImportTx(vm.ctx.AChainID, conflictKey.Address, initialBaseFee, []*secp256k1.PrivateKey{key})
+		conflictTx, err := vm.newImportTx(vm.ctx.AChainID, conflictKey.Address, initialBaseFee, []*secp256k1.PrivateKey{key}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -3944,7 +4027,7 @@ func TestBuildBlockDoesNotExceedAtomicGasLimit(t *testing.T) {
 		utxo, err := addUTXO(sharedMemory, vm.ctx, txID, uint32(i), vm.ctx.DIONEAssetID, importAmount, testShortIDAddrs[0])
 		assert.NoError(t, err)
 
-		importTx, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo})
+		importTx, err := vm.newImportTxWithUTXOs(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, kc, []*dione.UTXO{utxo}, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -4003,7 +4086,7 @@ func TestExtraStateChangeAtomicGasLimitExceeded(t *testing.T) {
 
 	// Double the initial base fee used when estimating the cost of this transaction to ensure that when it is
 	// used in ApricotPhase5 it still pays a sufficient fee with the fixed fee per atomic transaction.
-	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(common.Big2, initialBaseFee), []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm1.newImportTx(vm1.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(common.Big2, initialBaseFee), []*secp256k1.PrivateKey{testKeys[0]}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -4049,8 +4132,71 @@ func TestExtraStateChangeAtomicGasLimitExceeded(t *testing.T) {
 	}
 }
 
+func TestExtraStateChangeAtomicGasLimitEUpgrade(t *testing.T) {
+	importAmount := params.OdysseyAtomicTxFee
+	eUpgradeTime := uint64(10)
+	genesisJSON := strings.Replace(genesisJSONLatest, `"cortinaBlockTimestamp":0`, fmt.Sprintf(`"cortinaBlockTimestamp":0,"dUpgradeBlockTimestamp":0,"eUpgradeBlockTimestamp":%d`, eUpgradeTime), 1)
+	_, vm, _, sharedMemory, _ := GenesisVM(t, true, genesisJSON, "", "")
+
+	defer func() {
+		if err := vm.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	txID, err := ids.ToID(hashing.ComputeHash256(testShortIDAddrs[0][:]))
+	assert.NoError(t, err)
+
+	// Add enough UTXOs, such that the created import transaction will consume
+	// more gas than the atomic gas limit before EUpgrade, but less than the
+	// atomic gas limit after it.
+	for i := 0; i < 100; i++ {
+		_, err := addUTXO(sharedMemory, vm.ctx, txID, uint32(i), vm.ctx.DIONEAssetID, importAmount, testShortIDAddrs[0])
+		assert.NoError(t, err)
+	}
+
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], new(big.Int).Mul(common.Big2, initialBaseFee), []*secp256k1.PrivateKey{testKeys[0]}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gasUsed, err := importTx.GasUsed(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gasUsed <= params.AtomicGasLimit.Uint64() || gasUsed > params.EUpgradeAtomicGasLimit.Uint64() {
+		t.Fatalf("expected import tx gas (%d) to be in (%d, %d]", gasUsed, params.AtomicGasLimit, params.EUpgradeAtomicGasLimit)
+	}
+
+	extraData, err := vm.codec.Marshal(codecVersion, []*Tx{importTx})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesisBlock := vm.blockChain.Genesis()
+	newBlock := func(timestamp uint64) *types.Block {
+		header := types.CopyHeader(genesisBlock.Header())
+		header.ParentHash = genesisBlock.Hash()
+		header.Number = common.Big1
+		header.Time = timestamp
+		header.BaseFee = initialBaseFee
+		return types.NewBlock(header, nil, nil, nil, new(trie.Trie), extraData, true)
+	}
+
+	state, err := vm.blockChain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Hack: test [onExtraStateChange] directly on either side of the fork boundary.
+	if _, _, err := vm.onExtraStateChange(newBlock(eUpgradeTime-1), state.Copy(), nil); err == nil || !strings.Contains(err.Error(), "exceeds atomic gas limit") {
+		t.Fatalf("Expected block before EUpgrade to fail verification due to exceeded atomic gas limit, but found error: %v", err)
+	}
+	if _, _, err := vm.onExtraStateChange(newBlock(eUpgradeTime), state.Copy(), nil); err != nil {
+		t.Fatalf("Expected block at EUpgrade to pass the atomic gas limit, but found error: %v", err)
+	}
+}
+
 func TestGetAtomicRepositoryRepairHeights(t *testing.T) {
-	mainnetHeights := getAtomicRepositoryRepairHeights(bonusBlockMainnetHeights, canonicalBlockMainnetHeights)
+	mainnetHeights := getAtomicRepositoryRepairHeights(mainnetBonusBlocks.heights(), mainnetBonusBlocks.CanonicalBlocks)
 	assert.Len(t, mainnetHeights, 76)
 	assert.True(t, slices.IsSorted(mainnetHeights))
 }
@@ -4068,7 +4214,7 @@ func TestSkipChainConfigCheckCompatible(t *testing.T) {
 
 	// Since rewinding is permitted for last accepted height of 0, we must
 	// accept one block to test the SkipUpgradeCheck functionality.
-	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
 	require.NoError(t, err)
 	require.NoError(t, vm.issueTx(importTx, true /*=local*/))
 	<-issuer
@@ -4098,3 +4244,687 @@ func TestSkipChainConfigCheckCompatible(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, reinitVM.Shutdown(context.Background()))
 }
+
+func TestSuggestAtomicTxGasPrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		pendingGas  []uint64
+		numerator   int64
+		denominator int64
+	}{
+		{
+			name:        "0% atomic gas utilization",
+			numerator:   1,
+			denominator: 1,
+		},
+		{
+			name:        "50% atomic gas utilization",
+			pendingGas:  []uint64{25_000, 25_000},
+			numerator:   150,
+			denominator: 100,
+		},
+		{
+			name:        "99% atomic gas utilization",
+			pendingGas:  []uint64{99_000},
+			numerator:   199,
+			denominator: 100,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+
+			for _, gasUsed := range test.pendingGas {
+				require.NoError(vm.mempool.ForceAddTx(&Tx{
+					UnsignedAtomicTx: &TestUnsignedTx{
+						IDV:      ids.GenerateTestID(),
+						GasUsedV: gasUsed,
+						BurnedV:  gasUsed * 25,
+					},
+				}))
+			}
+
+			baseFee, err := vm.estimateBaseFee(context.Background())
+			require.NoError(err)
+			expected := new(big.Int).Mul(baseFee, big.NewInt(test.numerator))
+			expected.Div(expected, big.NewInt(test.denominator))
+
+			price, err := vm.SuggestAtomicTxGasPrice(vm.ctx.AChainID)
+			require.NoError(err)
+			require.Equal(expected, price)
+		})
+	}
+}
+
+func TestSuggestAtomicTxGasPriceInvalidChain(t *testing.T) {
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	_, err := vm.SuggestAtomicTxGasPrice(vm.ctx.ChainID)
+	require.ErrorIs(t, err, verify.ErrSameChainID)
+}
+
+// The conversion rate is part of consensus, so it must not change for any
+// existing fork.
+func TestGetAssetConversionRate(t *testing.T) {
+	for _, genesisJSON := range []string{genesisJSONApricotPhase0, genesisJSONApricotPhase5, genesisJSONBanff, genesisJSONLatest} {
+		_, vm, _, _, _ := GenesisVM(t, true, genesisJSON, "", "")
+		service := &DioneAPI{vm: vm}
+
+		reply := &GetAssetConversionRateReply{}
+		require.NoError(t, service.GetAssetConversionRate(nil, nil, reply))
+		require.Equal(t, big.NewInt(1_000_000_000), reply.Rate.ToInt())
+		require.Equal(t, big.NewInt(x2cRateInt64), reply.Rate.ToInt())
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}
+}
+
+func TestGetBaseFeeWindow(t *testing.T) {
+	require := require.New(t)
+	vm := newFeeHistoryTestVM(t, 0)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	blk := vm.blockChain.CurrentBlock()
+	expectedWindow, err := dummy.DecodeRollupWindow(vm.chainConfig, blk)
+	require.NoError(err)
+	reply := &GetBaseFeeWindowReply{}
+	require.NoError(service.GetBaseFeeWindow(nil, &GetBaseFeeWindowArgs{BlockID: ids.ID(blk.Hash())}, reply))
+	require.Len(reply.Window, len(expectedWindow))
+	for i, gas := range expectedWindow {
+		require.Equal(gas, uint64(reply.Window[i]))
+	}
+	require.Equal(blk.BaseFee, reply.BaseFee.ToInt())
+
+	// The base fee of the block is computed from the window it encodes.
+	parent := vm.blockChain.GetHeaderByHash(blk.ParentHash)
+	expectedExtra, expectedBaseFee, err := dummy.CalcBaseFee(vm.chainConfig, parent, blk.Time)
+	require.NoError(err)
+	require.Equal(expectedExtra, blk.Extra)
+	require.Equal(expectedBaseFee, reply.BaseFee.ToInt())
+
+	err = service.GetBaseFeeWindow(nil, &GetBaseFeeWindowArgs{BlockID: ids.GenerateTestID()}, &GetBaseFeeWindowReply{})
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestGetBaseFeeWindowPreApricotPhase3(t *testing.T) {
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONApricotPhase2, "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	_, _, err := vm.GetBaseFeeWindow(vm.LastAcceptedBlock().ID())
+	require.Error(t, err)
+}
+
+func TestEstimateNextBaseFeeWithMempool(t *testing.T) {
+	require := require.New(t)
+	vm := newFeeHistoryTestVM(t, 0)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	parent := vm.blockChain.CurrentBlock()
+	vm.clock.Set(time.Unix(int64(parent.Time+1), 0))
+	estimate := func() (*big.Int, *big.Int) {
+		reply := &EstimateBaseFeeReply{}
+		require.NoError(service.EstimateBaseFee(nil, &EstimateBaseFeeArgs{IncludeMempool: true}, reply))
+		return reply.BaseFee.ToInt(), reply.MempoolBaseFee.ToInt()
+	}
+
+	// With an empty mempool, both estimates match the parent-only estimate.
+	_, expectedBaseFee, err := dummy.EstimateNextBaseFee(vm.chainConfig, parent, parent.Time+1)
+	require.NoError(err)
+	parentOnly, mempoolAware := estimate()
+	require.Equal(expectedBaseFee, parentOnly)
+	require.Equal(expectedBaseFee, mempoolAware)
+
+	reply := &EstimateBaseFeeReply{}
+	require.NoError(service.EstimateBaseFee(nil, &EstimateBaseFeeArgs{}, reply))
+	require.Equal(expectedBaseFee, reply.BaseFee.ToInt())
+	require.Nil(reply.MempoolBaseFee)
+
+	// Stuff the atomic mempool beyond the atomic gas limit and the tx pool
+	// beyond the block gas limit.
+	rules := vm.currentRules()
+	atomicGasLimit := rules.AtomicGasLimit().Uint64()
+	for i := 0; i < 2; i++ {
+		require.NoError(vm.mempool.ForceAddTx(&Tx{
+			UnsignedAtomicTx: &TestUnsignedTx{
+				IDV:      ids.GenerateTestID(),
+				GasUsedV: atomicGasLimit,
+				BurnedV:  atomicGasLimit * uint64(params.ApricotPhase4MaxBaseFee),
+			},
+		}))
+	}
+	gasLimit := parent.GasLimit / 2
+	txs := make([]*types.Transaction, 3)
+	for i := range txs {
+		tx := types.NewTransaction(uint64(i+1), testEthAddrs[1], big.NewInt(10), gasLimit, big.NewInt(params.ApricotPhase4MaxBaseFee), nil)
+		txs[i], err = types.SignTx(tx, types.NewEIP155Signer(vm.chainID), testKeys[0].ToECDSA())
+		require.NoError(err)
+	}
+	for _, err := range vm.txPool.AddRemotesSync(txs) {
+		require.NoError(err)
+	}
+
+	atomicGas, evmGas, err := vm.pendingGas(parent)
+	require.NoError(err)
+	require.Equal(atomicGasLimit, atomicGas)
+	require.Equal(parent.GasLimit, evmGas)
+
+	_, expectedMempoolBaseFee, err := dummy.EstimateNextBaseFeeWithPendingGas(vm.chainConfig, parent, parent.Time+1, atomicGasLimit+parent.GasLimit)
+	require.NoError(err)
+	parentOnly, mempoolAware = estimate()
+	require.Equal(expectedBaseFee, parentOnly)
+	require.Equal(expectedMempoolBaseFee, mempoolAware)
+	require.Positive(mempoolAware.Cmp(parentOnly))
+}
+
+func TestRevalidateAtomicMempool(t *testing.T) {
+	require := require.New(t)
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSONLatest, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	newTx := func(verifyErr error) *Tx {
+		return &Tx{UnsignedAtomicTx: &TestUnsignedTx{
+			IDV:         ids.GenerateTestID(),
+			GasUsedV:    1,
+			BurnedV:     1,
+			InputUTXOsV: set.Of(ids.GenerateTestID()),
+			VerifyV:     verifyErr,
+		}}
+	}
+	validTx, invalidTx := newTx(nil), newTx(errors.New("invalid under current rules"))
+	require.NoError(vm.mempool.ForceAddTx(validTx))
+	require.NoError(vm.mempool.ForceAddTx(invalidTx))
+
+	require.Equal(1, vm.RevalidateAtomicMempool())
+
+	_, dropped, found := vm.mempool.GetTx(invalidTx.ID())
+	require.True(found)
+	require.True(dropped)
+	require.True(vm.mempool.has(validTx.ID()))
+
+	require.Zero(vm.RevalidateAtomicMempool())
+}
+
+func TestGetAtomicTxJSON(t *testing.T) {
+	require := require.New(t)
+	_, vm := newLatestRulesTestVM(t)
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	getAtomicTxJSON := func(txID ids.ID) (map[string]interface{}, *odysseyJSON.Uint64) {
+		reply := &FormattedTx{}
+		require.NoError(service.GetAtomicTx(nil, &api.GetTxArgs{TxID: txID, Encoding: formatting.JSON}, reply))
+		require.Equal(formatting.JSON, reply.Encoding)
+		txJSON, err := json.Marshal(reply.Tx)
+		require.NoError(err)
+		decoded := map[string]interface{}{}
+		require.NoError(json.Unmarshal(txJSON, &decoded))
+		return decoded, reply.BlockHeight
+	}
+
+	exportTx, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	require.NoError(vm.mempool.AddTx(exportTx))
+
+	// A pending tx is returned without a block height.
+	decoded, height := getAtomicTxJSON(exportTx.ID())
+	require.Nil(height)
+	require.Equal("export", decoded["txType"])
+	require.Len(decoded["credentials"], 1)
+	unsignedTx := decoded["unsignedTx"].(map[string]interface{})
+	require.Equal(vm.ctx.AChainID.String(), unsignedTx["destinationChain"])
+	require.Len(unsignedTx["inputs"], 1)
+	require.Len(unsignedTx["exportedOutputs"], 1)
+
+	importTx := &Tx{UnsignedAtomicTx: &UnsignedImportTx{
+		NetworkID:    vm.ctx.NetworkID,
+		BlockchainID: vm.ctx.ChainID,
+		SourceChain:  vm.ctx.AChainID,
+		ImportedInputs: []*dione.TransferableInput{{
+			UTXOID: dione.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  dione.Asset{ID: vm.ctx.DIONEAssetID},
+			In: &secp256k1fx.TransferInput{
+				Amt:   units.Dione,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}},
+		Outs: []DELTAOutput{{
+			Address: testEthAddrs[0],
+			Amount:  units.Dione,
+			AssetID: vm.ctx.DIONEAssetID,
+		}},
+	}}
+	require.NoError(importTx.Sign(vm.codec, [][]*secp256k1.PrivateKey{{testKeys[0]}}))
+	require.NoError(vm.atomicTxRepository.Write(5, []*Tx{importTx, exportTx}))
+
+	// Accepted txs include the height of the block that accepted them.
+	for _, tx := range []*Tx{importTx, exportTx} {
+		_, height = getAtomicTxJSON(tx.ID())
+		require.NotNil(height)
+		require.Equal(odysseyJSON.Uint64(5), *height)
+	}
+
+	decoded, _ = getAtomicTxJSON(importTx.ID())
+	require.Equal("import", decoded["txType"])
+	require.Len(decoded["credentials"], 1)
+	unsignedTx = decoded["unsignedTx"].(map[string]interface{})
+	require.Equal(vm.ctx.AChainID.String(), unsignedTx["sourceChain"])
+	require.Len(unsignedTx["importedInputs"], 1)
+	outputs := unsignedTx["outputs"].([]interface{})
+	require.Len(outputs, 1)
+	require.Equal(strings.ToLower(testEthAddrs[0].Hex()), outputs[0].(map[string]interface{})["address"])
+
+	// The hex encoding is unchanged.
+	reply := &FormattedTx{}
+	require.NoError(service.GetAtomicTx(nil, &api.GetTxArgs{TxID: importTx.ID(), Encoding: formatting.Hex}, reply))
+	expected, err := formatting.Encode(formatting.Hex, importTx.SignedBytes())
+	require.NoError(err)
+	require.Equal(expected, reply.Tx)
+}
+
+func TestGetOrionNodes(t *testing.T) {
+	require := require.New(t)
+
+	// The orion contract stores whatever number of nodes it is called with.
+	orionContract := common.HexToAddress("0x0710400000000000000000000000000000000000")
+	orionsListSlot := common.HexToHash("0x02")
+	nodeIDs := []ids.NodeID{ids.GenerateTestNodeID(), ids.GenerateTestNodeID()}
+	storage := map[common.Hash]common.Hash{orionsListSlot: common.BigToHash(big.NewInt(int64(len(nodeIDs))))}
+	listStart := crypto.Keccak256Hash(orionsListSlot[:]).Big()
+	for i, nodeID := range nodeIDs {
+		var value common.Hash
+		copy(value[:], nodeID[:])
+		storage[common.BigToHash(new(big.Int).Add(listStart, big.NewInt(int64(i))))] = value
+	}
+
+	genesis := &core.Genesis{}
+	require.NoError(json.Unmarshal([]byte(genesisJSONLatest), genesis))
+	genesis.Alloc[testEthAddrs[0]] = core.GenesisAccount{
+		Balance: new(big.Int).Mul(new(big.Int).SetUint64(units.MegaDione), x2cRate),
+	}
+	genesis.Alloc[orionContract] = core.GenesisAccount{
+		Balance: big.NewInt(1),
+		// PUSH1 0x00 CALLDATALOAD PUSH1 0x02 SSTORE TIMESTAMP PUSH1 0x01 SSTORE STOP
+		Code:    common.Hex2Bytes("6000356002554260015500"),
+		Storage: storage,
+	}
+	genesisJSON, err := json.Marshal(genesis)
+	require.NoError(err)
+
+	issuer, vm, _, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	// Accept a block removing the last orion node.
+	tx := types.NewTransaction(0, orionContract, new(big.Int), 100_000, big.NewInt(params.ApricotPhase4MaxBaseFee), common.BigToHash(common.Big1).Bytes())
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainID), testKeys[0].ToECDSA())
+	require.NoError(err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{signedTx}) {
+		require.NoError(err)
+	}
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+	vm.blockChain.DrainAcceptorQueue()
+
+	blkTime := vm.blockChain.GetHeaderByNumber(1).Time
+	require.Positive(blkTime)
+
+	tests := map[string]struct {
+		timestamp uint64
+		expected  []ids.NodeID
+	}{
+		"genesis": {
+			timestamp: 0,
+			expected:  nodeIDs,
+		},
+		"before first block": {
+			timestamp: blkTime - 1,
+			expected:  nodeIDs,
+		},
+		"first block": {
+			timestamp: blkTime,
+			expected:  nodeIDs[:1],
+		},
+		"after last accepted block": {
+			timestamp: blkTime + 1_000,
+			expected:  nodeIDs[:1],
+		},
+	}
+	for name, test := range tests {
+		orionNodes, err := vm.GetOrionNodes(context.Background(), test.timestamp)
+		require.NoError(err, name)
+		require.Equal(test.expected, orionNodes, name)
+
+		reply := &GetOrionNodesReply{}
+		require.NoError(service.GetOrionNodes(nil, &GetOrionNodesArgs{Timestamp: odysseyJSON.Uint64(test.timestamp)}, reply), name)
+		require.Equal(test.expected, reply.NodeIDs, name)
+	}
+}
+
+func TestGetOrionNodesMissingHeaders(t *testing.T) {
+	require := require.New(t)
+
+	// Headers are not cached once accepted, so that they can be removed.
+	genesisJSON := newOrionSnapshotTestGenesis(t, nil, math.MaxUint64)
+	issuer, vm, _, _, _ := GenesisVM(t, true, genesisJSON, `{"accepted-cache-size": 0}`, "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	var blkTimes []uint64
+	start := time.Now().Add(-time.Minute)
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		vm.clock.Set(start.Add(time.Duration(nonce) * 10 * time.Second))
+		blk := buildOrionSnapshotTestBlock(t, issuer, vm, nonce, testEthAddrs[1], nil)
+		require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+		require.NoError(blk.Accept(context.Background()))
+		blkTimes = append(blkTimes, uint64(blk.Timestamp().Unix()))
+	}
+	vm.blockChain.DrainAcceptorQueue()
+
+	// Remove the header of the first block, as if the VM state synced to the
+	// second block.
+	rawdb.DeleteCanonicalHash(vm.chaindb, 1)
+	require.Nil(vm.blockChain.GetHeaderByNumber(1))
+
+	for _, timestamp := range []uint64{blkTimes[1], blkTimes[2], blkTimes[2] + 1_000} {
+		_, err := vm.GetOrionNodes(context.Background(), timestamp)
+		require.NoError(err)
+	}
+	for _, timestamp := range []uint64{0, blkTimes[0], blkTimes[1] - 1} {
+		_, err := vm.GetOrionNodes(context.Background(), timestamp)
+		require.ErrorIs(err, errMissingAcceptedHeader)
+		_, err = vm.GetOrionNodesCount(context.Background(), timestamp)
+		require.ErrorIs(err, errMissingAcceptedHeader)
+	}
+}
+
+// slowSharedMemory delays every read of shared memory by [delay], slowing down
+// the verification of import txs.
+type slowSharedMemory struct {
+	atomic.SharedMemory
+	delay time.Duration
+}
+
+func (s *slowSharedMemory) Get(peerChainID ids.ID, keys [][]byte) ([][]byte, error) {
+	time.Sleep(s.delay)
+	return s.SharedMemory.Get(peerChainID, keys)
+}
+
+func TestBuildBlockDeadlineTruncatesAtomicTxs(t *testing.T) {
+	require := require.New(t)
+
+	importAmount := 10 * params.OdysseyAtomicTxFee
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, `{"build-block-deadline": "50ms"}`, "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+		testShortIDAddrs[1]: importAmount,
+		testShortIDAddrs[2]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	importTxs := make([]*Tx, 0, 3)
+	for i := 0; i < 3; i++ {
+		importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[i], initialBaseFee, []*secp256k1.PrivateKey{testKeys[i]}, false)
+		require.NoError(err)
+		require.NoError(vm.issueTx(importTx, true /*=local*/))
+		importTxs = append(importTxs, importTx)
+	}
+	<-issuer
+
+	// Verifying each import tx now takes longer than the deadline, so only
+	// the first one is packed.
+	vm.ctx.SharedMemory = &slowSharedMemory{SharedMemory: vm.ctx.SharedMemory, delay: 100 * time.Millisecond}
+	truncated := buildBlockDeadlineTruncated.Count()
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(err)
+	atomicTxs := blk.(*chain.BlockWrapper).Block.(*Block).atomicTxs
+	require.Len(atomicTxs, 1)
+	require.Equal(truncated+1, buildBlockDeadlineTruncated.Count())
+
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(blk.Accept(context.Background()))
+
+	// The txs left out remain in the mempool.
+	require.Equal(2, vm.mempool.Len())
+	for _, tx := range importTxs {
+		require.Equal(tx.ID() != atomicTxs[0].ID(), vm.mempool.has(tx.ID()))
+	}
+}
+
+func TestBuildDeadlineFor(t *testing.T) {
+	require := require.New(t)
+
+	vm := &VM{}
+	require.True(vm.buildDeadlineFor(context.Background()).IsZero())
+
+	ctxDeadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.Background(), ctxDeadline)
+	defer cancel()
+	require.Equal(ctxDeadline, vm.buildDeadlineFor(ctx))
+
+	vm.config.BuildBlockDeadline.Duration = time.Minute
+	deadline := vm.buildDeadlineFor(ctx)
+	require.True(deadline.Before(ctxDeadline))
+	require.WithinDuration(time.Now().Add(time.Minute), deadline, time.Second)
+
+	vm.config.BuildBlockDeadline.Duration = 2 * time.Hour
+	require.Equal(ctxDeadline, vm.buildDeadlineFor(ctx))
+}
+
+func TestSyntacticVerifyBaseFeeBounds(t *testing.T) {
+	tests := map[string]struct {
+		genesisJSON string
+		baseFee     *big.Int
+		expectedErr error
+	}{
+		"minimum": {
+			genesisJSON: genesisJSONLatest,
+			baseFee:     dummy.ApricotPhase4MinBaseFee,
+		},
+		"below minimum": {
+			genesisJSON: genesisJSONLatest,
+			baseFee:     new(big.Int).Sub(dummy.ApricotPhase4MinBaseFee, common.Big1),
+			expectedErr: errBaseFeeOutOfBounds,
+		},
+		"negative": {
+			genesisJSON: genesisJSONLatest,
+			baseFee:     big.NewInt(-1),
+			expectedErr: errBaseFeeOutOfBounds,
+		},
+		"above apricot phase 4 maximum": {
+			genesisJSON: genesisJSONApricotPhase4,
+			baseFee:     new(big.Int).Add(dummy.ApricotPhase4MaxBaseFee, common.Big1),
+			expectedErr: errBaseFeeOutOfBounds,
+		},
+		"unbounded as of apricot phase 5": {
+			genesisJSON: genesisJSONLatest,
+			baseFee:     new(big.Int).Add(dummy.ApricotPhase4MaxBaseFee, common.Big1),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			importAmount := 10 * params.OdysseyAtomicTxFee
+			issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, test.genesisJSON, "", "", map[ids.ShortID]uint64{
+				testShortIDAddrs[0]: importAmount,
+			})
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+
+			importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+			require.NoError(err)
+			require.NoError(vm.issueTx(importTx, true /*=local*/))
+			<-issuer
+
+			blk, err := vm.BuildBlock(context.Background())
+			require.NoError(err)
+			validEthBlock := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+			require.NoError(blk.(*chain.BlockWrapper).Block.(*Block).syntacticVerify())
+
+			header := types.CopyHeader(validEthBlock.Header())
+			header.BaseFee = new(big.Int).Set(test.baseFee)
+			ethBlk := types.NewBlock(header, nil, nil, nil, new(trie.Trie), validEthBlock.ExtData(), true)
+			modifiedBlk, err := vm.newBlock(ethBlk)
+			require.NoError(err)
+
+			err = modifiedBlk.syntacticVerify()
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+}
+
+func TestWouldAtomicTxBeValid(t *testing.T) {
+	require := require.New(t)
+
+	banffTime := uint64(time.Now().Add(time.Hour).Unix())
+	genesisJSON := strings.Replace(genesisJSONApricotPhasePost6, `"apricotPhasePost6BlockTimestamp":0`, fmt.Sprintf(`"apricotPhasePost6BlockTimestamp":0,"banffBlockTimestamp":%d`, banffTime), 1)
+	_, vm, _, _, _ := GenesisVM(t, true, genesisJSON, "", "")
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// Export a non-DIONE asset, which is no longer allowed as of Banff.
+	assetID := ids.GenerateTestID()
+	tx := &Tx{UnsignedAtomicTx: &UnsignedExportTx{
+		NetworkID:        vm.ctx.NetworkID,
+		BlockchainID:     vm.ctx.ChainID,
+		DestinationChain: vm.ctx.AChainID,
+		Ins: []DELTAInput{{
+			Address: testEthAddrs[0],
+			Amount:  1,
+			AssetID: assetID,
+		}},
+		ExportedOutputs: []*dione.TransferableOutput{{
+			Asset: dione.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{testShortIDAddrs[0]},
+				},
+			},
+		}},
+	}}
+
+	require.NoError(vm.WouldAtomicTxBeValid(tx, banffTime-1))
+	require.ErrorIs(vm.WouldAtomicTxBeValid(tx, banffTime), errExportNonDIONEInputBanff)
+	require.ErrorIs(vm.WouldAtomicTxBeValid(nil, banffTime), errNilTx)
+}
+
+func TestGetPendingAtomicTxs(t *testing.T) {
+	require := require.New(t)
+
+	genesis := &core.Genesis{}
+	require.NoError(json.Unmarshal([]byte(genesisJSONLatest), genesis))
+	genesis.Alloc[testEthAddrs[0]] = core.GenesisAccount{
+		Balance: new(big.Int).Mul(new(big.Int).SetUint64(units.MegaDione), x2cRate),
+	}
+	genesisJSON, err := json.Marshal(genesis)
+	require.NoError(err)
+
+	_, vm, _, _, _ := GenesisVMWithUTXOs(t, true, string(genesisJSON), "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: 10 * params.OdysseyAtomicTxFee,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+	service := &DioneAPI{vm: vm}
+
+	// Both exports spend the nonce 0 of the first test address.
+	export1, err := vm.newExportTx(vm.ctx.DIONEAssetID, units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	export2, err := vm.newExportTx(vm.ctx.DIONEAssetID, 2*units.Dione, vm.ctx.AChainID, testShortIDAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, false)
+	require.NoError(err)
+	importTx, err := vm.newImportTx(vm.ctx.AChainID, testEthAddrs[1], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]}, true)
+	require.NoError(err)
+
+	require.NoError(vm.mempool.AddTx(export1))
+	require.NoError(vm.mempool.ForceAddTx(export2))
+	require.NoError(vm.mempool.AddTx(importTx))
+
+	reply := &GetPendingAtomicTxsReply{}
+	require.NoError(service.GetPendingAtomicTxs(nil, &GetPendingAtomicTxsArgs{}, reply))
+	require.Len(reply.Txs, 3)
+	pending := make(map[ids.ID]PendingAtomicTx)
+	for _, tx := range reply.Txs {
+		pending[tx.TxID] = tx
+	}
+
+	for _, tx := range []*Tx{export1, export2} {
+		got, ok := pending[tx.ID()]
+		require.True(ok)
+		require.Equal("export", got.TxType)
+		require.True(got.Conflicting)
+		require.Equal(tx.InputUTXOs().List(), got.InputUTXOs)
+	}
+	got, ok := pending[importTx.ID()]
+	require.True(ok)
+	require.Equal("import", got.TxType)
+	require.False(got.Conflicting)
+	require.False(got.IssueTime.IsZero())
+	gasUsed, err := importTx.GasUsed(true)
+	require.NoError(err)
+	require.Equal(odysseyJSON.Uint64(gasUsed), got.GasUsed)
+	burned, err := importTx.Burned(vm.ctx.DIONEAssetID)
+	require.NoError(err)
+	require.Equal(odysseyJSON.Uint64(burned), got.Burned)
+	require.Len(got.InputUTXOs, 1)
+
+	// The import is the only tx paying to the second test address.
+	reply = &GetPendingAtomicTxsReply{}
+	require.NoError(service.GetPendingAtomicTxs(nil, &GetPendingAtomicTxsArgs{
+		Addresses: []common.Address{testEthAddrs[1]},
+	}, reply))
+	require.Len(reply.Txs, 1)
+	require.Equal(importTx.ID(), reply.Txs[0].TxID)
+
+	// The exports are the only txs spending from the first test address.
+	reply = &GetPendingAtomicTxsReply{}
+	require.NoError(service.GetPendingAtomicTxs(nil, &GetPendingAtomicTxsArgs{
+		Addresses: []common.Address{testEthAddrs[0]},
+	}, reply))
+	require.Len(reply.Txs, 2)
+	for _, tx := range reply.Txs {
+		require.Equal("export", tx.TxType)
+	}
+
+	reply = &GetPendingAtomicTxsReply{}
+	require.NoError(service.GetPendingAtomicTxs(nil, &GetPendingAtomicTxsArgs{Limit: 1}, reply))
+	require.Len(reply.Txs, 1)
+
+	reply = &GetPendingAtomicTxsReply{}
+	require.NoError(service.GetPendingAtomicTxs(nil, &GetPendingAtomicTxsArgs{
+		Addresses: []common.Address{testEthAddrs[2]},
+	}, reply))
+	require.Empty(reply.Txs)
+}
@@ -35,6 +35,15 @@ var (
 		// precompile contract addresses can be added here
 	}
 
+	// NativeAddresses contains the addresses within [ReservedRanges] that are
+	// taken by the blackhole address and the native precompiles in core/vm, and
+	// so cannot be used by a stateful precompile.
+	NativeAddresses = []common.Address{
+		common.HexToAddress("0x0100000000000000000000000000000000000000"),
+		common.HexToAddress("0x0100000000000000000000000000000000000001"),
+		common.HexToAddress("0x0100000000000000000000000000000000000002"),
+	}
+
 	// ReservedRanges contains addresses ranges that are reserved
 	// for precompiles and cannot be used as EOA or deployed contracts.
 	ReservedRanges = []AddressRange{
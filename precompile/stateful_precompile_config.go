@@ -4,6 +4,8 @@
 package precompile
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/DioneProtocol/coreth/utils"
@@ -26,8 +28,8 @@ type StatefulPrecompileConfig interface {
 	//
 	// Configure is called on the first block where the stateful precompile should be enabled. This
 	// provides the config the ability to set its initial state and should only modify the state within
-	// its own address space.
-	Configure(ChainConfig, StateDB, BlockContext)
+	// its own address space. An error must be returned if the state could not be configured.
+	Configure(ChainConfig, StateDB, BlockContext) error
 	// Contract returns a thread-safe singleton that can be used as the StatefulPrecompiledContract when
 	// this config is enabled.
 	Contract() StatefulPrecompiledContract
@@ -40,8 +42,9 @@ type StatefulPrecompileConfig interface {
 // configured at genesis, or happens during block processing to update the state before processing the given block.
 // TODO: add ability to call Configure at different timestamps, so that developers can easily re-configure by updating the
 // stateful precompile config.
+// Returns an error if Configure fails, in which case [state] must not be used further.
 // Assumes that [config] is non-nil.
-func CheckConfigure(chainConfig ChainConfig, parentTimestamp *uint64, blockContext BlockContext, precompileConfig StatefulPrecompileConfig, state StateDB) {
+func CheckConfigure(chainConfig ChainConfig, parentTimestamp *uint64, blockContext BlockContext, precompileConfig StatefulPrecompileConfig, state StateDB) error {
 	// If the network upgrade goes into effect within this transition, configure the stateful precompile
 	if utils.IsForkTransition(precompileConfig.Timestamp(), parentTimestamp, blockContext.Timestamp()) {
 		// Set the nonce of the precompile's address (as is done when a contract is created) to ensure
@@ -51,6 +54,9 @@ func CheckConfigure(chainConfig ChainConfig, parentTimestamp *uint64, blockConte
 		// can be called from within Solidity contracts. Solidity adds a check before invoking a contract to ensure
 		// that it does not attempt to invoke a non-existent contract.
 		state.SetCode(precompileConfig.Address(), []byte{0x1})
-		precompileConfig.Configure(chainConfig, state, blockContext)
+		if err := precompileConfig.Configure(chainConfig, state, blockContext); err != nil {
+			return fmt.Errorf("failed to configure precompile at %s: %w", precompileConfig.Address(), err)
+		}
 	}
+	return nil
 }
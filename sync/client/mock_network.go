@@ -8,6 +8,7 @@ import (
 
 	"github.com/DioneProtocol/coreth/peer"
 	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/set"
 
 	"github.com/DioneProtocol/odysseygo/version"
 )
@@ -77,6 +78,10 @@ func (t *mockNetwork) Gossip([]byte) error {
 	panic("not implemented") // we don't care about this function for this test
 }
 
+func (t *mockNetwork) GossipSpecific(set.Set[ids.NodeID], []byte) error {
+	panic("not implemented") // we don't care about this function for this test
+}
+
 func (t *mockNetwork) SendCrossChainRequest(chainID ids.ID, request []byte) ([]byte, error) {
 	panic("not implemented") // we don't care about this function for this test
 }
@@ -15,6 +15,8 @@ import (
 	"github.com/DioneProtocol/odysseygo/codec"
 	"github.com/DioneProtocol/odysseygo/ids"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/time/rate"
 )
 
 var _ message.RequestHandler = &syncHandler{}
@@ -37,6 +39,20 @@ type syncHandler struct {
 	atomicTrieLeafsRequestHandler *LeafsRequestHandler
 	blockRequestHandler           *BlockRequestHandler
 	codeRequestHandler            *CodeRequestHandler
+	limiter                       *rate.Limiter
+}
+
+// SyncHandlerOption configures the handler returned by NewSyncHandler.
+type SyncHandlerOption func(*syncHandler)
+
+// WithRateLimiter throttles the requests served by the handler with [limiter],
+// which is shared by all request types. A request that cannot be admitted
+// before its deadline is dropped without a response. By default, requests are
+// not throttled.
+func WithRateLimiter(limiter *rate.Limiter) SyncHandlerOption {
+	return func(s *syncHandler) {
+		s.limiter = limiter
+	}
 }
 
 // NewSyncHandler constructs the handler for serving state sync.
@@ -47,27 +63,57 @@ func NewSyncHandler(
 	atomicTrieDB *trie.Database,
 	networkCodec codec.Manager,
 	stats stats.HandlerStats,
+	opts ...SyncHandlerOption,
 ) message.RequestHandler {
-	return &syncHandler{
+	s := &syncHandler{
 		stateTrieLeafsRequestHandler:  NewLeafsRequestHandler(deltaTrieDB, provider, networkCodec, stats),
 		atomicTrieLeafsRequestHandler: NewLeafsRequestHandler(atomicTrieDB, nil, networkCodec, stats),
 		blockRequestHandler:           NewBlockRequestHandler(provider, networkCodec, stats),
 		codeRequestHandler:            NewCodeRequestHandler(diskDB, networkCodec, stats),
+		limiter:                       rate.NewLimiter(rate.Inf, 0),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// wait blocks until the rate limiter admits a request from [nodeID]. If the
+// request cannot be admitted before [ctx] is done, it returns
+// context.DeadlineExceeded, which the network drops the request on rather than
+// treating as a fatal error.
+func (s *syncHandler) wait(ctx context.Context, nodeID ids.NodeID, requestID uint32) error {
+	if err := s.limiter.Wait(ctx); err != nil {
+		log.Debug("dropping rate limited sync request", "nodeID", nodeID, "requestID", requestID, "err", err)
+		return context.DeadlineExceeded
 	}
+	return nil
 }
 
 func (s *syncHandler) HandleStateTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest) ([]byte, error) {
+	if err := s.wait(ctx, nodeID, requestID); err != nil {
+		return nil, err
+	}
 	return s.stateTrieLeafsRequestHandler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
 }
 
 func (s *syncHandler) HandleAtomicTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest) ([]byte, error) {
+	if err := s.wait(ctx, nodeID, requestID); err != nil {
+		return nil, err
+	}
 	return s.atomicTrieLeafsRequestHandler.OnLeafsRequest(ctx, nodeID, requestID, leafsRequest)
 }
 
 func (s *syncHandler) HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, blockRequest message.BlockRequest) ([]byte, error) {
+	if err := s.wait(ctx, nodeID, requestID); err != nil {
+		return nil, err
+	}
 	return s.blockRequestHandler.OnBlockRequest(ctx, nodeID, requestID, blockRequest)
 }
 
 func (s *syncHandler) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest message.CodeRequest) ([]byte, error) {
+	if err := s.wait(ctx, nodeID, requestID); err != nil {
+		return nil, err
+	}
 	return s.codeRequestHandler.OnCodeRequest(ctx, nodeID, requestID, codeRequest)
 }
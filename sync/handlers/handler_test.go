@@ -0,0 +1,77 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/coreth/core/rawdb"
+	"github.com/DioneProtocol/coreth/ethdb/memorydb"
+	"github.com/DioneProtocol/coreth/plugin/delta/message"
+	"github.com/DioneProtocol/coreth/sync/handlers/stats"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func newCodeSyncHandler(mockHandlerStats *stats.MockHandlerStats, opts ...SyncHandlerOption) (message.RequestHandler, message.CodeRequest) {
+	database := memorydb.New()
+	codeBytes := []byte("some code goes here")
+	codeHash := crypto.Keccak256Hash(codeBytes)
+	rawdb.WriteCode(database, codeHash, codeBytes)
+
+	handler := NewSyncHandler(nil, database, nil, nil, message.Codec, mockHandlerStats, opts...)
+	return handler, message.CodeRequest{Hashes: []common.Hash{codeHash}}
+}
+
+func TestSyncHandlerDefaultRateLimiter(t *testing.T) {
+	mockHandlerStats := &stats.MockHandlerStats{}
+	handler, request := newCodeSyncHandler(mockHandlerStats)
+
+	for i := 0; i < 100; i++ {
+		responseBytes, err := handler.HandleCodeRequest(context.Background(), ids.GenerateTestNodeID(), uint32(i), request)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, responseBytes)
+	}
+	assert.EqualValues(t, 100, mockHandlerStats.CodeRequestCount)
+}
+
+func TestSyncHandlerRateLimiter(t *testing.T) {
+	mockHandlerStats := &stats.MockHandlerStats{}
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	handler, request := newCodeSyncHandler(mockHandlerStats, WithRateLimiter(limiter))
+	nodeID := ids.GenerateTestNodeID()
+
+	// The first request consumes the only token.
+	responseBytes, err := handler.HandleCodeRequest(context.Background(), nodeID, 1, request)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, responseBytes)
+
+	// Later requests are dropped, as no token becomes available before their
+	// deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	responseBytes, err = handler.HandleCodeRequest(ctx, nodeID, 2, request)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, responseBytes)
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	responseBytes, err = handler.HandleBlockRequest(cancelledCtx, nodeID, 3, message.BlockRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, responseBytes)
+	assert.EqualValues(t, 1, mockHandlerStats.CodeRequestCount)
+	assert.Zero(t, mockHandlerStats.BlockRequestCount)
+
+	// Once a token is available, requests are served again.
+	limiter.SetLimit(rate.Inf)
+	responseBytes, err = handler.HandleCodeRequest(context.Background(), nodeID, 4, request)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, responseBytes)
+	assert.EqualValues(t, 2, mockHandlerStats.CodeRequestCount)
+}